@@ -53,6 +53,9 @@ func TestCliPluginExitCode(t *testing.T) {
 			// and also see plugin_test.go for how a plugin env can be set up.
 			// This mimics the "exitwith" test case in TestLoadPlugins using envvars
 			"HELM_PLUGINS=../../pkg/cmd/testdata/helmhome/helm/plugins",
+			// Grant the plugin its permissions, as there is no terminal to ask in.
+			"HELM_ACCEPT_PLUGIN_PERMISSIONS=1",
+			"HELM_CONFIG_HOME="+t.TempDir(),
 		)
 		stdout := &bytes.Buffer{}
 		stderr := &bytes.Buffer{}
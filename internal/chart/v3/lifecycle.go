@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// DateFormat is the format of the dates in Chart.yaml.
+const DateFormat = "2006-01-02"
+
+// Date is a calendar date in Chart.yaml, such as 2025-06-30.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a date in DateFormat.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateFormat, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected the format YYYY-MM-DD", s)
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateFormat)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid date %s, expected the format YYYY-MM-DD", data)
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// SupportTier is the level of support of a chart.
+type SupportTier string
+
+// Support tiers.
+const (
+	// SupportTierStable charts are supported for production use.
+	SupportTierStable SupportTier = "stable"
+	// SupportTierBeta charts are feature complete, but may still change.
+	SupportTierBeta SupportTier = "beta"
+	// SupportTierAlpha charts are experimental and may change at any time.
+	SupportTierAlpha SupportTier = "alpha"
+	// SupportTierCommunity charts are supported on a best effort basis.
+	SupportTierCommunity SupportTier = "community"
+)
+
+// MaintenancePolicy describes which changes a chart still receives.
+type MaintenancePolicy string
+
+// Maintenance policies.
+const (
+	// MaintenanceActive charts receive new features and fixes.
+	MaintenanceActive MaintenancePolicy = "active"
+	// MaintenanceSecurityOnly charts only receive security fixes.
+	MaintenanceSecurityOnly MaintenancePolicy = "security-only"
+	// MaintenanceEndOfLife charts receive no more changes.
+	MaintenanceEndOfLife MaintenancePolicy = "end-of-life"
+)
+
+// Lifecycle describes the support of a chart over time.
+type Lifecycle struct {
+	// DeprecationDate is the date from which the chart is deprecated.
+	DeprecationDate *Date `json:"deprecationDate,omitempty"`
+	// SupportTier is the level of support of the chart.
+	SupportTier SupportTier `json:"supportTier,omitempty"`
+	// MaintenancePolicy describes which changes the chart still receives.
+	MaintenancePolicy MaintenancePolicy `json:"maintenancePolicy,omitempty"`
+}
+
+// Validate checks the lifecycle for known issues.
+func (l *Lifecycle) Validate() error {
+	if l == nil {
+		return nil
+	}
+	switch l.SupportTier {
+	case "", SupportTierStable, SupportTierBeta, SupportTierAlpha, SupportTierCommunity:
+	default:
+		return ValidationErrorf("chart.metadata.lifecycle.supportTier %q must be one of stable, beta, alpha or community", l.SupportTier)
+	}
+	switch l.MaintenancePolicy {
+	case "", MaintenanceActive, MaintenanceSecurityOnly, MaintenanceEndOfLife:
+	default:
+		return ValidationErrorf("chart.metadata.lifecycle.maintenancePolicy %q must be one of active, security-only or end-of-life", l.MaintenancePolicy)
+	}
+	return nil
+}
+
+// ValidateLifecycle checks the lifecycle and the structured annotations of
+// the metadata.
+func (md *Metadata) ValidateLifecycle() error {
+	if err := md.Lifecycle.Validate(); err != nil {
+		return err
+	}
+	return validateStructuredAnnotations(md.StructuredAnnotations)
+}
+
+// IsDeprecated reports whether the chart is deprecated at the given time,
+// either explicitly or because its deprecation date has passed.
+func (md *Metadata) IsDeprecated(at time.Time) bool {
+	if md.Deprecated {
+		return true
+	}
+	if md.Lifecycle == nil || md.Lifecycle.DeprecationDate == nil {
+		return false
+	}
+	return !at.Before(md.Lifecycle.DeprecationDate.Time)
+}
+
+// structuredAnnotationKey matches the keys of structured annotations, which
+// follow the format of Kubernetes annotation keys: an optional DNS prefix
+// and a name.
+var structuredAnnotationKey = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+func validateStructuredAnnotations(annotations map[string]interface{}) error {
+	for key := range annotations {
+		if len(key) > 316 || !structuredAnnotationKey.MatchString(key) {
+			return ValidationErrorf("chart.metadata.structuredAnnotations key %q is invalid, it must be a name with an optional DNS prefix, such as example.com/support", key)
+		}
+	}
+	return nil
+}
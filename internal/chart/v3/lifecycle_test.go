@@ -0,0 +1,114 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestLifecycleYAML(t *testing.T) {
+	data := `apiVersion: v3
+name: lifecycle
+version: 1.0.0
+lifecycle:
+  deprecationDate: 2025-06-30
+  supportTier: stable
+  maintenancePolicy: security-only
+structuredAnnotations:
+  example.com/support:
+    contacts: [team@example.com]
+    hours: 24x7
+`
+	var md Metadata
+	if err := yaml.Unmarshal([]byte(data), &md); err != nil {
+		t.Fatal(err)
+	}
+	if err := md.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if md.Lifecycle.DeprecationDate.String() != "2025-06-30" || md.Lifecycle.SupportTier != SupportTierStable || md.Lifecycle.MaintenancePolicy != MaintenanceSecurityOnly {
+		t.Errorf("Unexpected lifecycle %+v", md.Lifecycle)
+	}
+	support, ok := md.StructuredAnnotations["example.com/support"].(map[string]interface{})
+	if !ok || support["hours"] != "24x7" {
+		t.Errorf("Unexpected structured annotations %v", md.StructuredAnnotations)
+	}
+
+	out, err := yaml.Marshal(md.Lifecycle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "deprecationDate: \"2025-06-30\"\nmaintenancePolicy: security-only\nsupportTier: stable\n"
+	if string(out) != expect {
+		t.Errorf("Expected %q, got %q", expect, out)
+	}
+
+	if err := yaml.Unmarshal([]byte("lifecycle:\n  deprecationDate: June 30\n"), &md); err == nil || !strings.Contains(err.Error(), `invalid date "June 30"`) {
+		t.Errorf("Expected an invalid date error, got %v", err)
+	}
+}
+
+func TestValidateLifecycle(t *testing.T) {
+	tests := []struct {
+		md  *Metadata
+		err string
+	}{
+		{md: &Metadata{}},
+		{md: &Metadata{Lifecycle: &Lifecycle{SupportTier: SupportTierCommunity, MaintenancePolicy: MaintenanceEndOfLife}}},
+		{md: &Metadata{Lifecycle: &Lifecycle{SupportTier: "gold"}}, err: `validation: chart.metadata.lifecycle.supportTier "gold" must be one of stable, beta, alpha or community`},
+		{md: &Metadata{Lifecycle: &Lifecycle{MaintenancePolicy: "lts"}}, err: `validation: chart.metadata.lifecycle.maintenancePolicy "lts" must be one of active, security-only or end-of-life`},
+		{md: &Metadata{StructuredAnnotations: map[string]interface{}{"owner": "x", "example.com/team.name": 1}}},
+		{md: &Metadata{StructuredAnnotations: map[string]interface{}{"Example.com/x": "x"}}, err: `key "Example.com/x" is invalid`},
+		{md: &Metadata{StructuredAnnotations: map[string]interface{}{"": "x"}}, err: `key "" is invalid`},
+	}
+	for _, tt := range tests {
+		err := tt.md.ValidateLifecycle()
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("Unexpected error %s", err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Expected error %q, got %v", tt.err, err)
+		}
+	}
+}
+
+func TestIsDeprecated(t *testing.T) {
+	date, err := ParseDate("2025-06-30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := &Metadata{Lifecycle: &Lifecycle{DeprecationDate: &date}}
+	if md.IsDeprecated(time.Date(2025, 6, 29, 23, 59, 0, 0, time.UTC)) {
+		t.Error("Expected the chart not to be deprecated before its deprecation date")
+	}
+	if !md.IsDeprecated(time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected the chart to be deprecated on its deprecation date")
+	}
+	if (&Metadata{}).IsDeprecated(time.Now()) {
+		t.Error("Expected a chart without a lifecycle not to be deprecated")
+	}
+	if !(&Metadata{Deprecated: true}).IsDeprecated(time.Time{}) {
+		t.Error("Expected a chart marked as deprecated to be deprecated")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/asaskevich/govalidator"
@@ -67,6 +68,8 @@ func Chartfile(linter *support.Linter) {
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartType(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
+	linter.RunLinterRule(support.ErrorSev, chartFileName, chartFile.ValidateLifecycle())
+	linter.RunLinterRule(support.WarningSev, chartFileName, validateChartDeprecationDate(chartFile, time.Now()))
 }
 
 func validateChartVersionType(data map[string]interface{}) error {
@@ -223,3 +226,12 @@ func loadChartFileForTypeCheck(filename string) (map[string]interface{}, error)
 	err = yaml.Unmarshal(b, &y)
 	return y, err
 }
+
+// validateChartDeprecationDate checks that a chart past its deprecation date
+// is marked as deprecated, so that users are warned when installing it.
+func validateChartDeprecationDate(cf *chart.Metadata, now time.Time) error {
+	if cf.Deprecated || !cf.IsDeprecated(now) {
+		return nil
+	}
+	return fmt.Errorf("chart is past its deprecation date of %s, set 'deprecated: true' to warn its users", cf.Lifecycle.DeprecationDate)
+}
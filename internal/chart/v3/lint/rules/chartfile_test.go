@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	chart "helm.sh/helm/v4/internal/chart/v3"
 	"helm.sh/helm/v4/internal/chart/v3/lint/support"
@@ -223,6 +224,30 @@ func TestValidateChartIconURL(t *testing.T) {
 	}
 }
 
+func TestValidateChartDeprecationDate(t *testing.T) {
+	date, err := chart.ParseDate("2025-06-30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf := &chart.Metadata{Lifecycle: &chart.Lifecycle{DeprecationDate: &date}}
+
+	before := time.Date(2025, 6, 29, 12, 0, 0, 0, time.UTC)
+	if err := validateChartDeprecationDate(cf, before); err != nil {
+		t.Errorf("Unexpected error before the deprecation date: %s", err)
+	}
+
+	after := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	err = validateChartDeprecationDate(cf, after)
+	if err == nil || !strings.Contains(err.Error(), "past its deprecation date of 2025-06-30") {
+		t.Errorf("Expected a deprecation date error, got %v", err)
+	}
+
+	cf.Deprecated = true
+	if err := validateChartDeprecationDate(cf, after); err != nil {
+		t.Errorf("Unexpected error for a deprecated chart: %s", err)
+	}
+}
+
 func TestV3Chartfile(t *testing.T) {
 	t.Run("Chart.yaml basic validity issues", func(t *testing.T) {
 		linter := support.Linter{ChartDir: badChartDir}
@@ -50,14 +50,9 @@ func LoadDir(dir string) (*chart.Chart, error) {
 	// Just used for errors.
 	c := &chart.Chart{}
 
-	rules := ignore.Empty()
-	ifile := filepath.Join(topdir, ignore.HelmIgnore)
-	if _, err := os.Stat(ifile); err == nil {
-		r, err := ignore.ParseFile(ifile)
-		if err != nil {
-			return c, err
-		}
-		rules = r
+	rules, err := ignore.ParseDir(topdir)
+	if err != nil {
+		return c, err
 	}
 	rules.AddDefaults()
 
@@ -202,17 +202,36 @@ func LoadValues(data io.Reader) (map[string]interface{}, error) {
 
 // MergeMaps merges two maps. If a key exists in both maps, the value from b will be used.
 // If the value is a map, the maps will be merged recursively.
+//
+// The patch directives of the values of b, such as a map to replace instead of
+// merging or a list to append to, are followed and kept in the result.
 func MergeMaps(a, b map[string]interface{}) map[string]interface{} {
 	out := make(map[string]interface{}, len(a))
 	maps.Copy(out, a)
 	for k, v := range b {
-		if v, ok := v.(map[string]interface{}); ok {
-			if bv, ok := out[k]; ok {
-				if bv, ok := bv.(map[string]interface{}); ok {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if bv, ok := out[k].(map[string]interface{}); ok {
+				switch common.TablePatch(v) {
+				case common.PatchReplace, common.PatchDelete:
+				case common.PatchMerge:
+					// b merges into a, which keeps its own directive.
+					merged := MergeMaps(bv, v)
+					if d, ok := bv[common.PatchKey]; ok {
+						merged[common.PatchKey] = d
+					}
+					out[k] = merged
+					continue
+				default:
 					out[k] = MergeMaps(bv, v)
 					continue
 				}
 			}
+		case []interface{}:
+			if bv, ok := out[k].([]interface{}); ok {
+				out[k] = common.PatchList(v, bv)
+				continue
+			}
 		}
 		out[k] = v
 	}
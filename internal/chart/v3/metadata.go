@@ -75,6 +75,11 @@ type Metadata struct {
 	// Annotations are additional mappings uninterpreted by Helm,
 	// made available for inspection by other applications.
 	Annotations map[string]string `json:"annotations,omitempty"`
+	// StructuredAnnotations are like Annotations, but their values can be
+	// any YAML value, such as lists and maps.
+	StructuredAnnotations map[string]interface{} `json:"structuredAnnotations,omitempty"`
+	// Lifecycle describes the support of the chart over time.
+	Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
 	// KubeVersion is a SemVer constraint specifying the version of Kubernetes required.
 	KubeVersion string `json:"kubeVersion,omitempty"`
 	// Dependencies are a list of dependencies for a chart.
@@ -132,6 +137,10 @@ func (md *Metadata) Validate() error {
 		}
 	}
 
+	if err := md.ValidateLifecycle(); err != nil {
+		return err
+	}
+
 	// Aliases need to be validated here to make sure that the alias name does
 	// not contain any illegal characters.
 	dependencies := map[string]*Dependency{}
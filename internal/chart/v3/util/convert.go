@@ -0,0 +1,164 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"maps"
+
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/internal/chart/v3"
+	"helm.sh/helm/v4/pkg/chart/common"
+	v2chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// Annotations of apiVersion v1 and v2 charts that are converted to the
+// lifecycle fields of apiVersion v3 charts.
+const (
+	AnnotationDeprecationDate   = "helm.sh/deprecation-date"
+	AnnotationSupportTier       = "helm.sh/support-tier"
+	AnnotationMaintenancePolicy = "helm.sh/maintenance-policy"
+)
+
+// ConvertMetadata converts the metadata of an apiVersion v1 or v2 chart to
+// apiVersion v3. The lifecycle annotations are converted to the lifecycle
+// fields, and the other annotations are kept.
+func ConvertMetadata(md *v2chart.Metadata) (*chart.Metadata, error) {
+	if md == nil {
+		return nil, chart.ValidationError("chart.metadata is required")
+	}
+	out := &chart.Metadata{
+		Name:        md.Name,
+		Home:        md.Home,
+		Sources:     md.Sources,
+		Version:     md.Version,
+		Description: md.Description,
+		Keywords:    md.Keywords,
+		Icon:        md.Icon,
+		APIVersion:  chart.APIVersionV3,
+		Condition:   md.Condition,
+		Tags:        md.Tags,
+		AppVersion:  md.AppVersion,
+		Deprecated:  md.Deprecated,
+		KubeVersion: md.KubeVersion,
+		Type:        md.Type,
+	}
+	for _, m := range md.Maintainers {
+		if m != nil {
+			out.Maintainers = append(out.Maintainers, &chart.Maintainer{Name: m.Name, Email: m.Email, URL: m.URL})
+		}
+	}
+	for _, d := range md.Dependencies {
+		out.Dependencies = append(out.Dependencies, convertDependency(d))
+	}
+
+	lifecycle := &chart.Lifecycle{}
+	annotations := maps.Clone(md.Annotations)
+	if v, ok := annotations[AnnotationDeprecationDate]; ok {
+		date, err := chart.ParseDate(v)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %s: %w", AnnotationDeprecationDate, err)
+		}
+		lifecycle.DeprecationDate = &date
+		delete(annotations, AnnotationDeprecationDate)
+	}
+	if v, ok := annotations[AnnotationSupportTier]; ok {
+		lifecycle.SupportTier = chart.SupportTier(v)
+		delete(annotations, AnnotationSupportTier)
+	}
+	if v, ok := annotations[AnnotationMaintenancePolicy]; ok {
+		lifecycle.MaintenancePolicy = chart.MaintenancePolicy(v)
+		delete(annotations, AnnotationMaintenancePolicy)
+	}
+	if *lifecycle != (chart.Lifecycle{}) {
+		out.Lifecycle = lifecycle
+	}
+	if len(annotations) > 0 {
+		out.Annotations = annotations
+	}
+
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ConvertChart converts an apiVersion v1 or v2 chart and its dependencies to
+// apiVersion v3.
+func ConvertChart(c *v2chart.Chart) (*chart.Chart, error) {
+	md, err := ConvertMetadata(c.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	chartfile, err := yaml.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+	out := &chart.Chart{
+		Metadata:  md,
+		Templates: c.Templates,
+		Values:    c.Values,
+		Schema:    c.Schema,
+	}
+	// The Chart.yaml is replaced with the converted one, and the dependency
+	// files of apiVersion v1 charts are part of it now.
+	for _, f := range c.Raw {
+		switch f.Name {
+		case ChartfileName:
+			out.Raw = append(out.Raw, &common.File{Name: ChartfileName, Data: chartfile})
+		case "requirements.yaml", "requirements.lock":
+		default:
+			out.Raw = append(out.Raw, f)
+		}
+	}
+	for _, f := range c.Files {
+		if f.Name != "requirements.yaml" && f.Name != "requirements.lock" {
+			out.Files = append(out.Files, f)
+		}
+	}
+	if c.Lock != nil {
+		out.Lock = &chart.Lock{Generated: c.Lock.Generated, Digest: c.Lock.Digest}
+		for _, d := range c.Lock.Dependencies {
+			out.Lock.Dependencies = append(out.Lock.Dependencies, convertDependency(d))
+		}
+	}
+	for _, dep := range c.Dependencies() {
+		sub, err := ConvertChart(dep)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", dep.Name(), err)
+		}
+		out.AddDependency(sub)
+	}
+	return out, nil
+}
+
+func convertDependency(d *v2chart.Dependency) *chart.Dependency {
+	if d == nil {
+		return nil
+	}
+	return &chart.Dependency{
+		Name:         d.Name,
+		Version:      d.Version,
+		Repository:   d.Repository,
+		Condition:    d.Condition,
+		Tags:         d.Tags,
+		Enabled:      d.Enabled,
+		ImportValues: d.ImportValues,
+		Alias:        d.Alias,
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	chart "helm.sh/helm/v4/internal/chart/v3"
+	v2chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+func TestConvertMetadata(t *testing.T) {
+	md, err := ConvertMetadata(&v2chart.Metadata{
+		APIVersion:  v2chart.APIVersionV2,
+		Name:        "mychart",
+		Version:     "1.2.3",
+		Maintainers: []*v2chart.Maintainer{{Name: "ops", Email: "ops@example.com"}},
+		Annotations: map[string]string{
+			AnnotationDeprecationDate:   "2030-01-31",
+			AnnotationSupportTier:       "beta",
+			AnnotationMaintenancePolicy: "active",
+			"example.com/owner":         "ops",
+		},
+		Dependencies: []*v2chart.Dependency{{Name: "sub", Version: "0.1.0", Alias: "other"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.APIVersion != chart.APIVersionV3 || md.Name != "mychart" || md.Maintainers[0].Email != "ops@example.com" || md.Dependencies[0].Alias != "other" {
+		t.Errorf("Unexpected metadata %+v", md)
+	}
+	if l := md.Lifecycle; l == nil || l.DeprecationDate.String() != "2030-01-31" || l.SupportTier != chart.SupportTierBeta || l.MaintenancePolicy != chart.MaintenanceActive {
+		t.Errorf("Unexpected lifecycle %+v", md.Lifecycle)
+	}
+	if len(md.Annotations) != 1 || md.Annotations["example.com/owner"] != "ops" {
+		t.Errorf("Expected only the other annotations to be kept, got %v", md.Annotations)
+	}
+
+	md, err = ConvertMetadata(&v2chart.Metadata{APIVersion: v2chart.APIVersionV1, Name: "old", Version: "0.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Lifecycle != nil || md.Annotations != nil {
+		t.Errorf("Expected no lifecycle or annotations, got %+v", md)
+	}
+
+	for annotation, expect := range map[string]string{
+		AnnotationDeprecationDate: `annotation helm.sh/deprecation-date: invalid date "soon"`,
+		AnnotationSupportTier:     `chart.metadata.lifecycle.supportTier "soon" must be one of`,
+	} {
+		_, err := ConvertMetadata(&v2chart.Metadata{Name: "bad", Version: "0.1.0", Annotations: map[string]string{annotation: "soon"}})
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("Expected error %q, got %v", expect, err)
+		}
+	}
+}
+
+func TestConvertChart(t *testing.T) {
+	for _, name := range []string{"../../../../pkg/chart/v2/loader/testdata/frobnitz", "../../../../pkg/chart/v2/loader/testdata/frobnitz.v1"} {
+		v2, err := loader.Load(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := ConvertChart(v2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Metadata.APIVersion != chart.APIVersionV3 || c.Name() != "frobnitz" {
+			t.Errorf("%s: unexpected metadata %+v", name, c.Metadata)
+		}
+		if len(c.Templates) != len(v2.Templates) || len(c.Dependencies()) != len(v2.Dependencies()) || c.Lock == nil {
+			t.Errorf("%s: expected the templates, dependencies and lock to be converted", name)
+		}
+		for _, dep := range c.Dependencies() {
+			if dep.Metadata.APIVersion != chart.APIVersionV3 || dep.Parent() != c {
+				t.Errorf("%s: unexpected dependency %+v", name, dep.Metadata)
+			}
+		}
+		for _, f := range append(c.Files, c.Raw...) {
+			if f.Name == "requirements.yaml" || f.Name == "requirements.lock" {
+				t.Errorf("%s: unexpected file %s", name, f.Name)
+			}
+			if f.Name == ChartfileName && !strings.Contains(string(f.Data), "apiVersion: v3") {
+				t.Errorf("%s: expected the raw Chart.yaml to be converted, got %s", name, f.Data)
+			}
+		}
+	}
+}
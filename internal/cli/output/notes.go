@@ -0,0 +1,297 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// The notes of a chart may use a small subset of markdown: ATX headings,
+// fenced code blocks, list items, inline code, bold text and links. Unlike
+// markdown, line breaks are kept as they are, as most notes are written as
+// plain text, and indented lines are never rewrapped, as they usually are
+// commands to copy.
+
+type notesLineKind int
+
+const (
+	notesText notesLineKind = iota
+	notesBlank
+	notesHeading
+	notesItem
+	notesVerbatim
+	notesCode
+)
+
+type notesLine struct {
+	kind notesLineKind
+	// level is the level of a heading
+	level int
+	// marker is the marker of a list item, such as "-" or "1."
+	marker string
+	text   string
+}
+
+var (
+	notesHeadingRe = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?\s*$`)
+	notesItemRe    = regexp.MustCompile(`^([-*+]|\d+[.)])\s+(.*)$`)
+	notesInlineRe  = regexp.MustCompile("`([^`]+)`|\\*\\*([^*]+)\\*\\*|\\[([^\\]]+)\\]\\(([^)\\s]+)\\)")
+	ansiRe         = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// parseNotes splits notes into lines annotated with their markdown kind.
+func parseNotes(notes string) []notesLine {
+	var lines []notesLine
+	var fence string
+	for _, l := range strings.Split(strings.TrimRight(notes, "\n"), "\n") {
+		l = strings.TrimRight(l, " \t\r")
+		trimmed := strings.TrimLeft(l, " \t")
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				continue
+			}
+			lines = append(lines, notesLine{kind: notesCode, text: l})
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			fence = trimmed[:3]
+		case l == "":
+			lines = append(lines, notesLine{kind: notesBlank})
+		case trimmed != l:
+			lines = append(lines, notesLine{kind: notesVerbatim, text: l})
+		default:
+			if m := notesHeadingRe.FindStringSubmatch(l); m != nil {
+				lines = append(lines, notesLine{kind: notesHeading, level: len(m[1]), text: m[2]})
+			} else if m := notesItemRe.FindStringSubmatch(l); m != nil {
+				lines = append(lines, notesLine{kind: notesItem, marker: m[1], text: m[2]})
+			} else {
+				lines = append(lines, notesLine{kind: notesText, text: l})
+			}
+		}
+	}
+	return lines
+}
+
+// renderInline replaces the inline code, bold text and links of s using the
+// given functions, passing the rest of the text through text.
+func renderInline(s string, text, code, bold func(string) string, link func(text, url string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range notesInlineRe.FindAllStringSubmatchIndex(s, -1) {
+		b.WriteString(text(s[last:m[0]]))
+		switch {
+		case m[2] >= 0:
+			b.WriteString(code(s[m[2]:m[3]]))
+		case m[4] >= 0:
+			b.WriteString(bold(s[m[4]:m[5]]))
+		default:
+			b.WriteString(link(s[m[6]:m[7]], s[m[8]:m[9]]))
+		}
+		last = m[1]
+	}
+	b.WriteString(text(s[last:]))
+	return b.String()
+}
+
+// RenderNotes renders the markdown of release notes for a terminal of the
+// given width. Headings and bold text are highlighted, code blocks are
+// indented, links are followed by their URL and text is wrapped to the width.
+// A width of zero or less disables wrapping.
+func RenderNotes(notes string, width int, noColor bool) string {
+	// Headings are underlined with characters when colors are disabled
+	noColor = noColor || color.NoColor
+	style := func(attrs ...color.Attribute) func(string) string {
+		if noColor {
+			return func(s string) string { return s }
+		}
+		c := color.New(attrs...)
+		return func(s string) string { return c.Sprint(s) }
+	}
+	heading, underlined, code := style(color.Bold), style(color.Bold, color.Underline), style(color.FgCyan)
+	inline := func(s string) string {
+		return renderInline(s, func(s string) string { return s }, code, heading, func(text, url string) string {
+			if text == url {
+				return url
+			}
+			return text + " (" + url + ")"
+		})
+	}
+
+	var b strings.Builder
+	for _, l := range parseNotes(notes) {
+		switch l.kind {
+		case notesBlank:
+		case notesHeading:
+			text := inline(l.text)
+			switch {
+			case !noColor && l.level == 1:
+				b.WriteString(underlined(text))
+			case !noColor:
+				b.WriteString(heading(text))
+			case l.level <= 2:
+				underline := "="
+				if l.level == 2 {
+					underline = "-"
+				}
+				b.WriteString(text + "\n" + strings.Repeat(underline, visibleLen(text)))
+			default:
+				b.WriteString(text)
+			}
+		case notesCode:
+			if l.text != "" {
+				b.WriteString(code("    " + l.text))
+			}
+		case notesVerbatim:
+			b.WriteString(l.text)
+		case notesItem:
+			b.WriteString(wrapLine(l.marker+" "+inline(l.text), width, utf8.RuneCountInString(l.marker)+1))
+		default:
+			b.WriteString(wrapLine(inline(l.text), width, 0))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// RenderNotesHTML renders the markdown of release notes as an HTML fragment.
+// Only links to http, https and mailto URLs are kept.
+func RenderNotesHTML(notes string) string {
+	inline := func(s string) string {
+		return renderInline(s, html.EscapeString,
+			func(s string) string { return "<code>" + html.EscapeString(s) + "</code>" },
+			func(s string) string { return "<strong>" + html.EscapeString(s) + "</strong>" },
+			func(text, href string) string {
+				u, err := url.Parse(href)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "mailto") {
+					return html.EscapeString(text)
+				}
+				return `<a href="` + html.EscapeString(href) + `">` + html.EscapeString(text) + "</a>"
+			})
+	}
+
+	var b strings.Builder
+	// open is the element of the current block, closed by the next one of
+	// another kind
+	open := ""
+	block := func(tag string) {
+		if open == tag {
+			return
+		}
+		if open != "" {
+			b.WriteString(closeTag(open) + "\n")
+		}
+		open = tag
+		if tag != "" {
+			b.WriteString(openTag(tag))
+		}
+	}
+
+	lines := parseNotes(notes)
+	for i, l := range lines {
+		switch l.kind {
+		case notesBlank:
+			block("")
+		case notesHeading:
+			block("")
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", l.level, inline(l.text), l.level)
+		case notesCode, notesVerbatim:
+			tag := "pre code"
+			if l.kind == notesVerbatim {
+				tag = "pre"
+			}
+			if open == tag {
+				b.WriteString("\n")
+			}
+			block(tag)
+			b.WriteString(html.EscapeString(l.text))
+		case notesItem:
+			tag := "ol"
+			if strings.ContainsAny(l.marker, "-*+") {
+				tag = "ul"
+			}
+			block(tag)
+			b.WriteString("\n<li>" + inline(l.text) + "</li>")
+		default:
+			if open == "p" {
+				b.WriteString("<br>\n")
+			}
+			block("p")
+			b.WriteString(inline(l.text))
+		}
+		if i == len(lines)-1 {
+			block("")
+		}
+	}
+	return b.String()
+}
+
+func openTag(tag string) string {
+	var b strings.Builder
+	for _, t := range strings.Fields(tag) {
+		b.WriteString("<" + t + ">")
+	}
+	return b.String()
+}
+
+func closeTag(tag string) string {
+	var b strings.Builder
+	ts := strings.Fields(tag)
+	for i := len(ts) - 1; i >= 0; i-- {
+		b.WriteString("</" + ts[i] + ">")
+	}
+	if tag == "ul" || tag == "ol" {
+		return "\n" + b.String()
+	}
+	return b.String()
+}
+
+// visibleLen returns the number of characters of s shown by a terminal.
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiRe.ReplaceAllString(s, ""))
+}
+
+// wrapLine wraps s to the width, indenting the continuation lines. Words
+// longer than the width are not broken.
+func wrapLine(s string, width, indent int) string {
+	if width <= 0 || visibleLen(s) <= width {
+		return s
+	}
+	var b strings.Builder
+	n := 0
+	for i, w := range strings.Fields(s) {
+		wl := visibleLen(w)
+		switch {
+		case i == 0:
+		case n+1+wl > width:
+			b.WriteString("\n" + strings.Repeat(" ", indent))
+			n = indent
+		default:
+			b.WriteString(" ")
+			n++
+		}
+		b.WriteString(w)
+		n += wl
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestRenderNotes(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		width int
+		want  string
+	}{
+		{
+			name:  "plain text is kept",
+			notes: "Some notes.\nOn two lines.\n",
+			width: 80,
+			want:  "Some notes.\nOn two lines.\n",
+		},
+		{
+			name:  "headings are underlined",
+			notes: "# Title\n## Section ##\n### Details",
+			width: 80,
+			want:  "Title\n=====\nSection\n-------\nDetails\n",
+		},
+		{
+			name:  "long lines are wrapped",
+			notes: "one two three four five",
+			width: 10,
+			want:  "one two\nthree four\nfive\n",
+		},
+		{
+			name:  "list items are wrapped with a hanging indent",
+			notes: "10. one two three four",
+			width: 12,
+			want:  "10. one two\n    three\n    four\n",
+		},
+		{
+			name:  "zero width does not wrap",
+			notes: "one two three four five",
+			want:  "one two three four five\n",
+		},
+		{
+			name:  "indented lines are not wrapped",
+			notes: "  kubectl get pods --namespace default",
+			width: 10,
+			want:  "  kubectl get pods --namespace default\n",
+		},
+		{
+			name:  "code blocks are indented as they are",
+			notes: "Run:\n```bash\nhelm test **x**\n\n[a](b)\n```\nDone",
+			width: 80,
+			want:  "Run:\n    helm test **x**\n\n    [a](b)\nDone\n",
+		},
+		{
+			name:  "inline markup",
+			notes: "Run `helm list` **now**, see [the docs](https://helm.sh) or https://helm.sh and [https://helm.sh](https://helm.sh)",
+			want:  "Run helm list now, see the docs (https://helm.sh) or https://helm.sh and https://helm.sh\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderNotes(tt.notes, tt.width, true); got != tt.want {
+				t.Errorf("RenderNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderNotesColor(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	got := RenderNotes("# Title\nA **bold** word in a line to wrap", 12, false)
+	if strings.Contains(got, "=====") {
+		t.Errorf("RenderNotes() underlined a highlighted heading: %q", got)
+	}
+	if !strings.Contains(got, "\033[1;4mTitle") {
+		t.Errorf("RenderNotes() = %q, want a bold and underlined heading", got)
+	}
+	// Escape codes do not count in the width of the lines
+	for _, l := range strings.Split(strings.TrimSuffix(got, "\n"), "\n")[1:] {
+		if n := visibleLen(l); n > 12 {
+			t.Errorf("line %q is %d characters wide, want at most 12", l, n)
+		}
+	}
+}
+
+func TestRenderNotesHTML(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{
+			name:  "paragraphs keep their line breaks",
+			notes: "one <two>\nthree\n\nfour",
+			want:  "<p>one &lt;two&gt;<br>\nthree</p>\n<p>four</p>\n",
+		},
+		{
+			name:  "headings and lists",
+			notes: "# Title\n- one\n- `two`\n1. three",
+			want:  "<h1>Title</h1>\n<ul>\n<li>one</li>\n<li><code>two</code></li>\n</ul>\n<ol>\n<li>three</li>\n</ol>\n",
+		},
+		{
+			name:  "code blocks and indented lines",
+			notes: "```\na < b\n\nc\n```\n  export X=1\n  echo $X",
+			want:  "<pre><code>a &lt; b\n\nc</code></pre>\n<pre>  export X=1\n  echo $X</pre>\n",
+		},
+		{
+			name:  "links",
+			notes: `[docs](https://helm.sh/?a=1&b="2") [mail](mailto:a@example.com) [bad](javascript:alert)`,
+			want:  `<p><a href="https://helm.sh/?a=1&amp;b=&#34;2&#34;">docs</a> <a href="mailto:a@example.com">mail</a> bad</p>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderNotesHTML(tt.notes); got != tt.want {
+				t.Errorf("RenderNotesHTML() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
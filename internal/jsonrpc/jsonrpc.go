@@ -0,0 +1,240 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonrpc implements JSON-RPC 2.0 connections framed with
+// Content-Length headers, as used by the Language Server Protocol.
+package jsonrpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// Error codes defined by JSON-RPC 2.0.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// ErrStop is returned by a Handler to stop Serve without an error.
+var ErrStop = errors.New("stop serving")
+
+// Message is a JSON-RPC 2.0 request, notification or response.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// IsNotification reports whether the message is a notification, which gets
+// no response.
+func (m *Message) IsNotification() bool {
+	return m.ID == nil
+}
+
+// Error is a JSON-RPC error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errorf creates an error with the given code.
+func Errorf(code int, format string, a ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// UnmarshalParams decodes the params of a request into v. It returns an
+// *Error with CodeInvalidParams if they are missing or cannot be decoded.
+func UnmarshalParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return Errorf(CodeInvalidParams, "missing params")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return Errorf(CodeInvalidParams, "invalid params: %s", err)
+	}
+	return nil
+}
+
+// Conn reads and writes JSON-RPC messages. Writes are safe for concurrent use.
+type Conn struct {
+	r *bufio.Reader
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewConn creates a connection reading from r and writing to w.
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{r: bufio.NewReader(r), w: w}
+}
+
+// Read reads the next message. It returns io.EOF when r is exhausted, and an
+// *Error with CodeParseError if the message is not valid JSON.
+func (c *Conn) Read() (*Message, error) {
+	header, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading message header: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return nil, fmt.Errorf("reading message body: %w", err)
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, Errorf(CodeParseError, "%s", err)
+	}
+	return &msg, nil
+}
+
+// Reply responds to a request with a result, or with an error if err is not
+// nil. Replies to notifications are dropped, unless they report an error
+// without a request ID, such as a parse error.
+func (c *Conn) Reply(req *Message, result interface{}, err error) error {
+	var id json.RawMessage
+	if req != nil {
+		id = req.ID
+	}
+	if id == nil && err == nil {
+		return nil
+	}
+
+	resp := Message{JSONRPC: "2.0", ID: id}
+	if id == nil {
+		resp.ID = json.RawMessage("null")
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+		return c.write(resp)
+	}
+
+	data, merr := json.Marshal(result)
+	if merr != nil {
+		return merr
+	}
+	resp.Result = data
+	return c.write(resp)
+}
+
+// Notify sends a notification.
+func (c *Conn) Notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(Message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+func (c *Conn) write(msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(data)
+	return err
+}
+
+// Handler handles a single message. Errors it returns stop Serve; errors of a
+// request should be sent to the client with Reply instead.
+type Handler func(msg *Message) error
+
+// Serve reads messages from the connection and passes them to handle one at a
+// time, until the input is exhausted, handle returns an error or ctx is
+// cancelled. Messages that are not valid JSON get a parse error response.
+//
+// Serve returns nil when the input is exhausted or handle returns ErrStop.
+func Serve(ctx context.Context, c *Conn, handle Handler) error {
+	type result struct {
+		msg *Message
+		err error
+	}
+	msgs := make(chan result)
+	go func() {
+		for {
+			msg, err := c.Read()
+			select {
+			case msgs <- result{msg, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				var rpcErr *Error
+				if !errors.As(err, &rpcErr) {
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case r := <-msgs:
+			var rpcErr *Error
+			switch {
+			case errors.As(r.err, &rpcErr):
+				if err := c.Reply(nil, nil, rpcErr); err != nil {
+					return err
+				}
+				continue
+			case errors.Is(r.err, io.EOF):
+				return nil
+			case r.err != nil:
+				return r.err
+			}
+			if err := handle(r.msg); err != nil {
+				if errors.Is(err, ErrStop) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
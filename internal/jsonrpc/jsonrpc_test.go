@@ -0,0 +1,91 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestServe(t *testing.T) {
+	frame := func(body string) string {
+		return "Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+	}
+	in := strings.NewReader(frame(`{"jsonrpc":"2.0","id":1,"method":"echo","params":"hi"}`) +
+		frame(`{not json}`) +
+		frame(`{"jsonrpc":"2.0","method":"note"}`) +
+		frame(`{"jsonrpc":"2.0","id":"a","method":"fail"}`) +
+		frame(`{"jsonrpc":"2.0","id":2,"method":"stop"}`) +
+		frame(`{"jsonrpc":"2.0","id":3,"method":"echo"}`))
+	var out bytes.Buffer
+	c := NewConn(in, &out)
+
+	var notes int
+	err := Serve(context.Background(), c, func(msg *Message) error {
+		switch msg.Method {
+		case "echo":
+			return c.Reply(msg, msg.Params, nil)
+		case "note":
+			notes++
+			return c.Reply(msg, "dropped", nil)
+		case "fail":
+			return c.Reply(msg, nil, errors.New("boom"))
+		}
+		return ErrStop
+	})
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	if notes != 1 {
+		t.Errorf("expected 1 notification, got %d", notes)
+	}
+
+	var got []string
+	r := NewConn(&out, nil)
+	for {
+		msg, err := r.Read()
+		if err != nil {
+			break
+		}
+		s := string(msg.ID) + " " + string(msg.Result)
+		if msg.Error != nil {
+			s += " " + strconv.Itoa(msg.Error.Code)
+		}
+		got = append(got, s)
+	}
+	want := []string{`1 "hi"`, "null  -32700", `"a"  -32603`}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected responses:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUnmarshalParams(t *testing.T) {
+	var v struct{ Name string }
+	if err := UnmarshalParams([]byte(`{"name":"a"}`), &v); err != nil || v.Name != "a" {
+		t.Errorf("unexpected result %+v, %v", v, err)
+	}
+	for _, params := range []string{"", `"a"`} {
+		var rpcErr *Error
+		if err := UnmarshalParams([]byte(params), &v); !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+			t.Errorf("expected an invalid params error for %q, got %v", params, err)
+		}
+	}
+}
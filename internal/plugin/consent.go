@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	consentMu    sync.Mutex
+	consentCheck func(Metadata) error
+)
+
+// SetConsentCheck sets the function called before a plugin runs, of any
+// type, returning an error if it must not run because the user did not
+// consent to its permissions. The calls are serialized, so check may prompt
+// the user.
+//
+// By default plugins run without a check, as programs using the SDK choose
+// the plugins they run. The helm CLI sets a check.
+func SetConsentCheck(check func(Metadata) error) {
+	consentMu.Lock()
+	defer consentMu.Unlock()
+	consentCheck = check
+}
+
+// checkConsent runs the consent check for the plugin, if one is set.
+func checkConsent(m Metadata) error {
+	consentMu.Lock()
+	defer consentMu.Unlock()
+	if consentCheck == nil {
+		return nil
+	}
+	return consentCheck(m)
+}
+
+// ConsentStore records the permissions users granted to plugins, so they are
+// asked for their consent again only when the permissions of a plugin change.
+type ConsentStore struct {
+	path string
+}
+
+// NewConsentStore returns the consent store saved in the file at path.
+func NewConsentStore(path string) *ConsentStore {
+	return &ConsentStore{path: path}
+}
+
+// Granted reports whether the permissions of the plugin were granted.
+func (s *ConsentStore) Granted(m Metadata) (bool, error) {
+	consents, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	digest, ok := consents[m.Name]
+	return ok && digest == m.Permissions.Digest(), nil
+}
+
+// Grant records the consent to the permissions of the plugin.
+func (s *ConsentStore) Grant(m Metadata) error {
+	consents, err := s.load()
+	if err != nil {
+		return err
+	}
+	consents[m.Name] = m.Permissions.Digest()
+	return s.save(consents)
+}
+
+// Revoke forgets the consent to the permissions of the plugin with the name.
+func (s *ConsentStore) Revoke(name string) error {
+	consents, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := consents[name]; !ok {
+		return nil
+	}
+	delete(consents, name)
+	return s.save(consents)
+}
+
+// load returns the digests of the granted permissions by plugin name.
+func (s *ConsentStore) load() (map[string]string, error) {
+	consents := map[string]string{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return consents, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &consents); err != nil {
+		return nil, err
+	}
+	if consents == nil {
+		consents = map[string]string{}
+	}
+	return consents, nil
+}
+
+func (s *ConsentStore) save(consents map[string]string) error {
+	data, err := yaml.Marshal(consents)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
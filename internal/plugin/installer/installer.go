@@ -124,6 +124,11 @@ func InstallWithOptions(i Installer, opts Options) (*VerificationResult, error)
 		return nil, err
 	}
 
+	if err := installPlatformDownload(i.Path()); err != nil {
+		os.RemoveAll(i.Path())
+		return nil, fmt.Errorf("failed to install plugin artifact: %w", err)
+	}
+
 	return result, nil
 }
 
@@ -132,7 +137,13 @@ func Update(i Installer) error {
 	if _, pathErr := os.Stat(i.Path()); os.IsNotExist(pathErr) {
 		return errors.New("plugin does not exist")
 	}
-	return i.Update()
+	if err := i.Update(); err != nil {
+		return err
+	}
+	if err := installPlatformDownload(i.Path()); err != nil {
+		return fmt.Errorf("failed to install plugin artifact: %w", err)
+	}
+	return nil
 }
 
 // NewForSource determines the correct Installer for the given source.
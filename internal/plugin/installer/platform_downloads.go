@@ -0,0 +1,85 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// installPlatformDownload downloads the artifact of the plugin installed in
+// pluginDir for the current platform into the plugin directory, after
+// verifying its checksum.
+//
+// Plugins installed as a symlink to a local directory are left untouched, as
+// their artifacts are expected to be built in place.
+func installPlatformDownload(pluginDir string) error {
+	if fi, err := os.Lstat(pluginDir); err != nil || fi.Mode()&os.ModeSymlink != 0 {
+		return err
+	}
+
+	p, err := plugin.LoadDir(pluginDir)
+	if err != nil {
+		// Unusable plugins are reported when they are loaded after installation.
+		slog.Debug("skipping plugin artifact download", "path", pluginDir, "error", err)
+		return nil
+	}
+	d, err := plugin.SelectPlatformDownload(p.Metadata().Downloads)
+	if err != nil || d == nil {
+		return err
+	}
+
+	slog.Debug("downloading plugin artifact", "platform", d.Platform(), "url", d.URL)
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return fmt.Errorf("invalid download url %q: %w", d.URL, err)
+	}
+	g, err := getter.All(new(cli.EnvSettings)).ByScheme(u.Scheme)
+	if err != nil {
+		return err
+	}
+	data, err := g.Get(d.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", d.URL, err)
+	}
+
+	sum := sha256.Sum256(data.Bytes())
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, d.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", d.URL, d.SHA256, got)
+	}
+
+	if extractor, err := NewExtractor(u.Path); err == nil {
+		return extractor.Extract(data, pluginDir)
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return fmt.Errorf("cannot determine file name of download %s", d.URL)
+	}
+	return os.WriteFile(filepath.Join(pluginDir, name), data.Bytes(), 0755)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeNativePlugin(t *testing.T, url string, data []byte) string {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	metadata := fmt.Sprintf(`name: native
+version: 1.0.0
+command: ${HELM_PLUGIN_DIR}/native
+downloads:
+- url: %s
+  sha256: %s
+`, url, hex.EncodeToString(sum[:]))
+
+	dir := filepath.Join(t.TempDir(), "native")
+	require.NoError(t, os.Mkdir(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plugin.yaml"), []byte(metadata), 0644))
+	return dir
+}
+
+func nativeArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	content := []byte("#!/bin/sh\necho native\n")
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "native", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestInstallPlatformDownload(t *testing.T) {
+	archive := nativeArchive(t)
+	binary := []byte("binary")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/native.tgz":
+			w.Write(archive)
+		case "/native.exe":
+			w.Write(binary)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("archive", func(t *testing.T) {
+		dir := writeNativePlugin(t, srv.URL+"/native.tgz", archive)
+		require.NoError(t, installPlatformDownload(dir))
+		data, err := os.ReadFile(filepath.Join(dir, "native"))
+		require.NoError(t, err)
+		assert.Equal(t, "#!/bin/sh\necho native\n", string(data))
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		dir := writeNativePlugin(t, srv.URL+"/native.exe", binary)
+		require.NoError(t, installPlatformDownload(dir))
+		data, err := os.ReadFile(filepath.Join(dir, "native.exe"))
+		require.NoError(t, err)
+		assert.Equal(t, binary, data)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		dir := writeNativePlugin(t, srv.URL+"/native.exe", []byte("other"))
+		err := installPlatformDownload(dir)
+		assert.ErrorContains(t, err, "checksum mismatch for "+srv.URL+"/native.exe")
+		assert.NoFileExists(t, filepath.Join(dir, "native.exe"))
+	})
+
+	t.Run("symlink", func(t *testing.T) {
+		dir := writeNativePlugin(t, srv.URL+"/native.exe", binary)
+		link := filepath.Join(t.TempDir(), "native")
+		require.NoError(t, os.Symlink(dir, link))
+		require.NoError(t, installPlatformDownload(link))
+		assert.NoFileExists(t, filepath.Join(dir, "native.exe"))
+	})
+}
@@ -233,6 +233,16 @@ func makeDescriptorFilter(descriptor Descriptor) filterFunc {
 	}
 }
 
+// NotFoundError is returned by FindPlugin when no plugin matches the
+// descriptor.
+type NotFoundError struct {
+	Descriptor Descriptor
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("plugin: %+v not found", e.Descriptor)
+}
+
 // FindPlugin returns a single plugin that matches the descriptor
 func FindPlugin(dirs []string, descriptor Descriptor) (Plugin, error) {
 	plugins, err := FindPlugins(dirs, descriptor)
@@ -244,7 +254,7 @@ func FindPlugin(dirs []string, descriptor Descriptor) (Plugin, error) {
 		return plugins[0], nil
 	}
 
-	return nil, fmt.Errorf("plugin: %+v not found", descriptor)
+	return nil, NotFoundError{Descriptor: descriptor}
 }
 
 func detectDuplicates(plugs []Plugin) error {
@@ -31,7 +31,7 @@ type Metadata struct {
 	// Name is the name of the plugin
 	Name string
 
-	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1)
+	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1, lint/v1)
 	Type string
 
 	// Runtime specifies the runtime type (subprocess, wasm)
@@ -48,6 +48,12 @@ type Metadata struct {
 
 	// RuntimeConfig contains the runtime-specific configuration
 	RuntimeConfig RuntimeConfig
+
+	// Downloads are the artifacts of the plugin for each supported platform
+	Downloads []PlatformDownload
+
+	// Permissions are the privileges the plugin needs, or nil if undeclared
+	Permissions *Permissions
 }
 
 func (m Metadata) Validate() error {
@@ -91,6 +97,14 @@ func (m Metadata) Validate() error {
 		}
 	}
 
+	if err := validatePlatformDownloads(m.Downloads); err != nil {
+		errs = append(errs, fmt.Errorf("invalid downloads: %w", err))
+	}
+
+	if err := m.Permissions.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid permissions: %w", err))
+	}
+
 	if len(errs) > 0 {
 		return errors.Join(errs...)
 	}
@@ -113,6 +127,8 @@ func fromMetadataLegacy(m MetadataLegacy) *Metadata {
 		Runtime:       "subprocess",
 		Config:        buildLegacyConfig(m, pluginType),
 		RuntimeConfig: buildLegacyRuntimeConfig(m),
+		Downloads:     m.Downloads,
+		Permissions:   m.Permissions,
 	}
 }
 
@@ -194,6 +210,8 @@ func fromMetadataV1(mv1 MetadataV1) (*Metadata, error) {
 		SourceURL:     mv1.SourceURL,
 		Config:        config,
 		RuntimeConfig: runtimeConfig,
+		Downloads:     mv1.Downloads,
+		Permissions:   mv1.Permissions,
 	}, nil
 }
 
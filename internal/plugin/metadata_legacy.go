@@ -65,6 +65,12 @@ type MetadataLegacy struct {
 	// Downloaders field is used if the plugin supply downloader mechanism
 	// for special protocols.
 	Downloaders []Downloaders `yaml:"downloaders"`
+
+	// Downloads are the artifacts of the plugin for each supported platform
+	Downloads []PlatformDownload `yaml:"downloads"`
+
+	// Permissions are the privileges the plugin needs, or nil if undeclared
+	Permissions *Permissions `yaml:"permissions"`
 }
 
 func (m *MetadataLegacy) Validate() error {
@@ -27,7 +27,7 @@ type MetadataV1 struct {
 	// Name is the name of the plugin
 	Name string `yaml:"name"`
 
-	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1)
+	// Type of plugin (eg, cli/v1, getter/v1, postrenderer/v1, lint/v1)
 	Type string `yaml:"type"`
 
 	// Runtime specifies the runtime type (subprocess, wasm)
@@ -44,6 +44,12 @@ type MetadataV1 struct {
 
 	// RuntimeConfig contains the runtime-specific configuration
 	RuntimeConfig map[string]any `yaml:"runtimeConfig"`
+
+	// Downloads are the artifacts of the plugin for each supported platform
+	Downloads []PlatformDownload `yaml:"downloads,omitempty"`
+
+	// Permissions are the privileges the plugin needs, or nil if undeclared
+	Permissions *Permissions `yaml:"permissions,omitempty"`
 }
 
 func (m *MetadataV1) Validate() error {
@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Permissions are the privileges a plugin declares it needs in plugin.yaml.
+//
+// Plugins declaring permissions run in a restricted environment where the
+// runtime can enforce it: subprocess plugins without the kubeconfig
+// permission do not see the Kubernetes connection settings of Helm, and
+// Wasm plugins can only reach the network and the filesystem as declared.
+// Plugins not declaring permissions run with the full privileges of the user.
+type Permissions struct {
+	// Network allows the plugin to make network requests.
+	Network bool `yaml:"network,omitempty" json:"network,omitempty"`
+	// Kubeconfig allows the plugin to access the Kubernetes cluster
+	// configuration and credentials of Helm.
+	Kubeconfig bool `yaml:"kubeconfig,omitempty" json:"kubeconfig,omitempty"`
+	// Filesystem are the paths outside of its own directory the plugin may
+	// access. Paths may start with "~" for the home directory and reference
+	// environment variables.
+	Filesystem []string `yaml:"filesystem,omitempty" json:"filesystem,omitempty"`
+}
+
+// kubeEnvPrefix prefixes the environment variables of the Kubernetes
+// connection settings of Helm, such as HELM_KUBECONTEXT and HELM_KUBETOKEN.
+const kubeEnvPrefix = "HELM_KUBE"
+
+// Validate checks that the filesystem scopes are not empty.
+func (p *Permissions) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for i, path := range p.Filesystem {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("filesystem scope %d is empty", i)
+		}
+	}
+	return nil
+}
+
+// Describe returns the privileges granted by the permissions, one per line,
+// to be shown to users before they consent to them.
+func (p *Permissions) Describe() []string {
+	if p == nil {
+		return []string{"full access to your user account (no permissions declared)"}
+	}
+
+	var lines []string
+	if p.Network {
+		lines = append(lines, "network access")
+	}
+	if p.Kubeconfig {
+		lines = append(lines, "access to your Kubernetes configuration and credentials")
+	}
+	for _, path := range p.Filesystem {
+		lines = append(lines, "filesystem access to "+path)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "no privileges")
+	}
+	return lines
+}
+
+// Digest identifies the permissions, so a change of permissions can be
+// told apart from an update of the plugin leaving them untouched.
+func (p *Permissions) Digest() string {
+	if p == nil {
+		return "unrestricted"
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		// Permissions only consist of JSON types.
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// FilesystemPaths returns the filesystem scopes with "~" and the environment
+// variables expanded.
+func (p *Permissions) FilesystemPaths() ([]string, error) {
+	if p == nil {
+		return nil, nil
+	}
+	paths := make([]string, 0, len(p.Filesystem))
+	for _, path := range p.Filesystem {
+		path = os.ExpandEnv(path)
+		if path == "~" || strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(home, path[1:])
+		}
+		if !filepath.IsAbs(path) {
+			return nil, errors.New("filesystem scope is not an absolute path: " + path)
+		}
+		paths = append(paths, filepath.Clean(path))
+	}
+	return paths, nil
+}
+
+// restrictEnv removes from env the variables the plugin is not allowed to
+// see. Without the kubeconfig permission, KUBECONFIG points to an empty file
+// so Kubernetes clients do not fall back to the default configuration.
+func (p *Permissions) restrictEnv(env map[string]string) {
+	if p == nil || p.Kubeconfig {
+		return
+	}
+	for k := range env {
+		if strings.HasPrefix(k, kubeEnvPrefix) {
+			delete(env, k)
+		}
+	}
+	env["KUBECONFIG"] = os.DevNull
+}
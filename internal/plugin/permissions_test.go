@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionsDescribe(t *testing.T) {
+	var undeclared *Permissions
+	assert.Equal(t, []string{"full access to your user account (no permissions declared)"}, undeclared.Describe())
+	assert.Equal(t, []string{"no privileges"}, (&Permissions{}).Describe())
+
+	p := &Permissions{Network: true, Kubeconfig: true, Filesystem: []string{"~/.cache/foo"}}
+	assert.Equal(t, []string{
+		"network access",
+		"access to your Kubernetes configuration and credentials",
+		"filesystem access to ~/.cache/foo",
+	}, p.Describe())
+}
+
+func TestPermissionsDigest(t *testing.T) {
+	var undeclared *Permissions
+	assert.Equal(t, "unrestricted", undeclared.Digest())
+
+	a := &Permissions{Network: true}
+	assert.Equal(t, a.Digest(), (&Permissions{Network: true}).Digest())
+	assert.NotEqual(t, a.Digest(), (&Permissions{}).Digest())
+	assert.NotEqual(t, a.Digest(), (&Permissions{Network: true, Kubeconfig: true}).Digest())
+}
+
+func TestPermissionsFilesystemPaths(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+	t.Setenv("PLUGIN_DATA", "/data")
+
+	p := &Permissions{Filesystem: []string{"~/.cache/foo", "$PLUGIN_DATA/bar/"}}
+	paths, err := p.FilesystemPaths()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(home, ".cache/foo"), filepath.Clean("/data/bar")}, paths)
+}
+
+func TestPermissionsRestrictEnv(t *testing.T) {
+	env := func() map[string]string {
+		return map[string]string{
+			"HELM_KUBECONTEXT": "prod",
+			"HELM_KUBETOKEN":   "secret",
+			"KUBECONFIG":       "/home/user/.kube/config",
+			"HELM_NAMESPACE":   "default",
+		}
+	}
+
+	var undeclared *Permissions
+	e := env()
+	undeclared.restrictEnv(e)
+	assert.Equal(t, env(), e)
+
+	e = env()
+	(&Permissions{Kubeconfig: true}).restrictEnv(e)
+	assert.Equal(t, env(), e)
+
+	e = env()
+	(&Permissions{Network: true}).restrictEnv(e)
+	assert.Equal(t, map[string]string{
+		"KUBECONFIG":     os.DevNull,
+		"HELM_NAMESPACE": "default",
+	}, e)
+}
+
+func TestLoadMetadataPermissions(t *testing.T) {
+	m, err := loadMetadata([]byte(`name: restricted
+version: 1.0.0
+command: echo
+permissions:
+  network: true
+  filesystem:
+  - ~/.cache/restricted
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &Permissions{Network: true, Filesystem: []string{"~/.cache/restricted"}}, m.Permissions)
+
+	_, err = loadMetadata([]byte("name: restricted\nversion: 1.0.0\ncommand: echo\npermissions:\n  filesystem: ['']\n"))
+	assert.ErrorContains(t, err, "invalid permissions: filesystem scope 0 is empty")
+}
+
+func TestConsentStore(t *testing.T) {
+	s := NewConsentStore(filepath.Join(t.TempDir(), "plugins", "consent.yaml"))
+	m := Metadata{Name: "foo", Permissions: &Permissions{Network: true}}
+
+	granted, err := s.Granted(m)
+	require.NoError(t, err)
+	assert.False(t, granted)
+
+	require.NoError(t, s.Grant(m))
+	granted, err = s.Granted(m)
+	require.NoError(t, err)
+	assert.True(t, granted)
+
+	m.Permissions.Kubeconfig = true
+	granted, err = s.Granted(m)
+	require.NoError(t, err)
+	assert.False(t, granted, "changed permissions require a new consent")
+
+	require.NoError(t, s.Grant(m))
+	require.NoError(t, s.Revoke("foo"))
+	granted, err = s.Granted(m)
+	require.NoError(t, err)
+	assert.False(t, granted)
+	require.NoError(t, s.Revoke("bar"))
+}
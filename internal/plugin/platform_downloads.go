@@ -0,0 +1,164 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// PlatformDownload is an artifact of a plugin built for a particular operating
+// system, architecture and architecture variant, such as a native binary.
+//
+// The artifact is downloaded into the plugin directory when the plugin is
+// installed. Archives (.tgz, .tar.gz) are extracted, other files are saved as
+// executables named after the last element of the URL.
+type PlatformDownload struct {
+	// OperatingSystem is the GOOS the artifact is built for, or empty for any.
+	OperatingSystem string `yaml:"os"`
+	// Architecture is the GOARCH the artifact is built for, or empty for any.
+	Architecture string `yaml:"arch"`
+	// Variant is the architecture variant the artifact is built for, for
+	// example "v7" for arm or "v3" for amd64, or empty for any.
+	Variant string `yaml:"variant,omitempty"`
+	// URL is the location of the artifact.
+	URL string `yaml:"url"`
+	// SHA256 is the hex encoded SHA-256 checksum of the artifact.
+	SHA256 string `yaml:"sha256"`
+}
+
+// Validate checks that the download has a URL and a well-formed checksum.
+func (d PlatformDownload) Validate() error {
+	if d.URL == "" {
+		return errors.New("empty url")
+	}
+	if d.Architecture == "" && d.Variant != "" {
+		return errors.New("variant is set without arch")
+	}
+	if sum, err := hex.DecodeString(d.SHA256); err != nil || len(sum) != 32 {
+		return fmt.Errorf("invalid sha256 checksum %q", d.SHA256)
+	}
+	return nil
+}
+
+// Platform returns the os/arch/variant the download is built for.
+func (d PlatformDownload) Platform() string {
+	return formatPlatform(d.OperatingSystem, d.Architecture, d.Variant)
+}
+
+func validatePlatformDownloads(downloads []PlatformDownload) error {
+	for i, d := range downloads {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("download %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SelectPlatformDownload returns the download for the current platform.
+//
+// It returns nil if the plugin has no downloads, and an error if none of them
+// are built for the current platform.
+func SelectPlatformDownload(downloads []PlatformDownload) (*PlatformDownload, error) {
+	return selectPlatformDownload(downloads, runtime.GOOS, runtime.GOARCH, PlatformVariant())
+}
+
+// selectPlatformDownload returns the download based on the following rules in
+// priority order:
+// - The download where OS, Arch and Variant match the platform
+// - The download where OS and Arch match the platform and Variant is empty/unspecified
+// - The download where OS matches the platform and Arch is empty/unspecified
+// - The download where OS is empty/unspecified and Arch and Variant match the platform
+// - The download where OS is empty/unspecified and Arch matches the platform and Variant is empty/unspecified
+// - The download where OS and Arch are both empty/unspecified
+func selectPlatformDownload(downloads []PlatformDownload, goos, goarch, variant string) (*PlatformDownload, error) {
+	if len(downloads) == 0 {
+		return nil, nil
+	}
+
+	eq := strings.EqualFold
+	score := func(d PlatformDownload) int {
+		if d.OperatingSystem != "" && !eq(d.OperatingSystem, goos) {
+			return 0
+		}
+		if d.Architecture != "" && !eq(d.Architecture, goarch) {
+			return 0
+		}
+		if d.Variant != "" && !eq(d.Variant, variant) {
+			return 0
+		}
+		s := 1
+		if d.Architecture != "" {
+			s += 2
+		}
+		if d.Variant != "" {
+			s++
+		}
+		if d.OperatingSystem != "" {
+			s += 4
+		}
+		return s
+	}
+
+	var best *PlatformDownload
+	bestScore := 0
+	for i := range downloads {
+		if s := score(downloads[i]); s > bestScore {
+			best, bestScore = &downloads[i], s
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no download is applicable to platform %s", formatPlatform(goos, goarch, variant))
+	}
+	return best, nil
+}
+
+// PlatformVariant returns the architecture variant Helm is built for, as set
+// by GOARM, GOAMD64, GOARM64 and the like, or an empty string if unknown.
+//
+// Numeric GOARM values are prefixed with "v", so GOARM=7 is variant "v7".
+func PlatformVariant() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	key := "GO" + strings.ToUpper(runtime.GOARCH)
+	for _, s := range info.Settings {
+		if s.Key != key {
+			continue
+		}
+		// Drop options such as ",softfloat" from GOARM and GOARM64.
+		v, _, _ := strings.Cut(s.Value, ",")
+		if v != "" && v[0] >= '0' && v[0] <= '9' {
+			v = "v" + v
+		}
+		return v
+	}
+	return ""
+}
+
+func formatPlatform(goos, goarch, variant string) string {
+	p := goos + "/" + goarch
+	if variant != "" {
+		p += "/" + variant
+	}
+	return p
+}
@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestSelectPlatformDownload(t *testing.T) {
+	downloads := []PlatformDownload{
+		{URL: "any"},
+		{Architecture: "arm", URL: "arm"},
+		{Architecture: "arm", Variant: "v7", URL: "arm-v7"},
+		{OperatingSystem: "linux", URL: "linux"},
+		{OperatingSystem: "linux", Architecture: "amd64", URL: "linux-amd64"},
+		{OperatingSystem: "linux", Architecture: "arm", Variant: "v6", URL: "linux-arm-v6"},
+		{OperatingSystem: "windows", Architecture: "arm64", URL: "windows-arm64"},
+		{OperatingSystem: "darwin", Architecture: "arm64", URL: "darwin-arm64"},
+	}
+
+	tests := []struct {
+		goos, goarch, variant string
+		url                   string
+	}{
+		{"linux", "amd64", "v1", "linux-amd64"},
+		{"linux", "arm", "v6", "linux-arm-v6"},
+		{"linux", "arm", "v7", "linux"},
+		{"freebsd", "arm", "v7", "arm-v7"},
+		{"freebsd", "arm", "v5", "arm"},
+		{"WINDOWS", "ARM64", "", "windows-arm64"},
+		{"windows", "amd64", "", "any"},
+		{"darwin", "arm64", "", "darwin-arm64"},
+	}
+	for _, tt := range tests {
+		t.Run(formatPlatform(tt.goos, tt.goarch, tt.variant), func(t *testing.T) {
+			d, err := selectPlatformDownload(downloads, tt.goos, tt.goarch, tt.variant)
+			require.NoError(t, err)
+			assert.Equal(t, tt.url, d.URL)
+		})
+	}
+}
+
+func TestSelectPlatformDownloadNoMatch(t *testing.T) {
+	d, err := selectPlatformDownload(nil, "linux", "amd64", "")
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	downloads := []PlatformDownload{
+		{OperatingSystem: "linux", Architecture: "amd64", URL: "linux-amd64"},
+		{OperatingSystem: "windows", URL: "windows"},
+	}
+	_, err = selectPlatformDownload(downloads, "darwin", "arm64", "")
+	assert.EqualError(t, err, "no download is applicable to platform darwin/arm64")
+}
+
+func TestPlatformDownloadValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		download PlatformDownload
+		err      string
+	}{
+		{
+			name:     "valid",
+			download: PlatformDownload{OperatingSystem: "linux", Architecture: "arm", Variant: "v7", URL: "https://example.com/a.tgz", SHA256: testSHA256},
+		},
+		{
+			name:     "uppercase checksum",
+			download: PlatformDownload{URL: "https://example.com/a.tgz", SHA256: strings.ToUpper(testSHA256)},
+		},
+		{
+			name:     "missing url",
+			download: PlatformDownload{SHA256: testSHA256},
+			err:      "empty url",
+		},
+		{
+			name:     "missing checksum",
+			download: PlatformDownload{URL: "https://example.com/a.tgz"},
+			err:      `invalid sha256 checksum ""`,
+		},
+		{
+			name:     "short checksum",
+			download: PlatformDownload{URL: "https://example.com/a.tgz", SHA256: "e3b0c442"},
+			err:      `invalid sha256 checksum "e3b0c442"`,
+		},
+		{
+			name:     "variant without arch",
+			download: PlatformDownload{OperatingSystem: "linux", Variant: "v7", URL: "https://example.com/a.tgz", SHA256: testSHA256},
+			err:      "variant is set without arch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.download.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestLoadMetadataDownloads(t *testing.T) {
+	data := `apiVersion: v1
+name: native
+type: cli/v1
+runtime: subprocess
+version: 1.0.0
+config:
+  shortHelp: native plugin
+runtimeConfig:
+  platformCommand:
+  - command: ${HELM_PLUGIN_DIR}/native
+downloads:
+- os: linux
+  arch: arm
+  variant: v7
+  url: https://example.com/native-linux-armv7.tgz
+  sha256: ` + testSHA256 + `
+`
+	m, err := loadMetadata([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, []PlatformDownload{{
+		OperatingSystem: "linux",
+		Architecture:    "arm",
+		Variant:         "v7",
+		URL:             "https://example.com/native-linux-armv7.tgz",
+		SHA256:          testSHA256,
+	}}, m.Downloads)
+
+	_, err = loadMetadata([]byte(strings.Replace(data, testSHA256, "abc", 1)))
+	assert.ErrorContains(t, err, `invalid downloads: download 0: invalid sha256 checksum "abc"`)
+
+	legacy := `name: native
+version: 1.0.0
+command: ${HELM_PLUGIN_DIR}/native
+downloads:
+- os: windows
+  arch: arm64
+  url: https://example.com/native.exe
+  sha256: ` + testSHA256 + `
+`
+	m, err = loadMetadata([]byte(legacy))
+	require.NoError(t, err)
+	require.Len(t, m.Downloads, 1)
+	assert.Equal(t, "windows/arm64", m.Downloads[0].Platform())
+}
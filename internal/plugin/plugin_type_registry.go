@@ -95,6 +95,12 @@ var pluginTypes = []pluginTypeMeta{
 		outputType: reflect.TypeOf(schema.OutputMessagePostRendererV1{}),
 		configType: reflect.TypeOf(schema.ConfigPostRendererV1{}),
 	},
+	{
+		pluginType: "lint/v1",
+		inputType:  reflect.TypeOf(schema.InputMessageLintV1{}),
+		outputType: reflect.TypeOf(schema.OutputMessageLintV1{}),
+		configType: reflect.TypeOf(schema.ConfigLintV1{}),
+	},
 }
 
 var pluginTypesIndex = func() map[string]*pluginTypeMeta {
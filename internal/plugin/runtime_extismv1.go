@@ -139,6 +139,9 @@ func (p *ExtismV1PluginRuntime) Dir() string {
 }
 
 func (p *ExtismV1PluginRuntime) Invoke(ctx context.Context, input *Input) (*Output, error) {
+	if err := checkConsent(p.metadata); err != nil {
+		return nil, err
+	}
 
 	var tmpDir string
 	if p.rc.FileSystem.CreateTempDir {
@@ -156,12 +159,12 @@ func (p *ExtismV1PluginRuntime) Invoke(ctx context.Context, input *Input) (*Outp
 		tmpDir = tmpDirInner
 	}
 
-	manifest, err := buildManifest(p.dir, tmpDir, p.rc)
+	manifest, err := buildManifest(p.dir, tmpDir, p.rc, p.metadata.Permissions)
 	if err != nil {
 		return nil, err
 	}
 
-	config := buildPluginConfig(input, p.r)
+	config := buildPluginConfig(input, p.r, p.metadata.Permissions)
 
 	hostFunctions, err := buildHostFunctions(p.r.HostFunctions, p.rc)
 	if err != nil {
@@ -214,15 +217,26 @@ func (p *ExtismV1PluginRuntime) Invoke(ctx context.Context, input *Input) (*Outp
 	return output, nil
 }
 
-func buildManifest(pluginDir string, tmpDir string, rc *RuntimeConfigExtismV1) (extism.Manifest, error) {
+// buildManifest builds the manifest of the plugin. If the plugin declares its
+// permissions, it can only reach the allowed hosts with the network
+// permission, and the paths of its filesystem permissions are mounted at the
+// same location.
+func buildManifest(pluginDir string, tmpDir string, rc *RuntimeConfigExtismV1, perms *Permissions) (extism.Manifest, error) {
 	wasmFile := filepath.Join(pluginDir, ExtismV1WasmBinaryFilename)
 
 	allowedHosts := rc.AllowedHosts
-	if allowedHosts == nil {
+	if allowedHosts == nil || (perms != nil && !perms.Network) {
 		allowedHosts = []string{}
 	}
 
 	allowedPaths := map[string]string{}
+	paths, err := perms.FilesystemPaths()
+	if err != nil {
+		return extism.Manifest{}, fmt.Errorf("invalid filesystem permission: %w", err)
+	}
+	for _, path := range paths {
+		allowedPaths[path] = filepath.ToSlash(path)
+	}
 	if tmpDir != "" {
 		allowedPaths[tmpDir] = "/tmp"
 	}
@@ -246,7 +260,7 @@ func buildManifest(pluginDir string, tmpDir string, rc *RuntimeConfigExtismV1) (
 	}, nil
 }
 
-func buildPluginConfig(input *Input, r *RuntimeExtismV1) extism.PluginConfig {
+func buildPluginConfig(input *Input, r *RuntimeExtismV1, perms *Permissions) extism.PluginConfig {
 	mc := wazero.NewModuleConfig().
 		WithSysWalltime()
 	if input.Stdin != nil {
@@ -260,6 +274,7 @@ func buildPluginConfig(input *Input, r *RuntimeExtismV1) extism.PluginConfig {
 	}
 	if len(input.Env) > 0 {
 		env := parseEnv(input.Env)
+		perms.restrictEnv(env)
 		for k, v := range env {
 			mc = mc.WithEnv(k, v)
 		}
@@ -118,7 +118,26 @@ func TestBuildManifest(t *testing.T) {
 		Timeout:      5000,
 	}
 
-	manifest, err := buildManifest("/path/to/plugin", "/tmp/foo", rc)
+	manifest, err := buildManifest("/path/to/plugin", "/tmp/foo", rc, nil)
 	require.NoError(t, err)
 	assert.Equal(t, expected, manifest)
 }
+
+func TestBuildManifestPermissions(t *testing.T) {
+	rc := &RuntimeConfigExtismV1{
+		AllowedHosts: []string{"example.com"},
+	}
+
+	t.Setenv("PLUGIN_DATA", "/data")
+	manifest, err := buildManifest("/path/to/plugin", "", rc, &Permissions{Filesystem: []string{"$PLUGIN_DATA/cache"}})
+	require.NoError(t, err)
+	assert.Empty(t, manifest.AllowedHosts, "hosts require the network permission")
+	assert.Equal(t, map[string]string{"/data/cache": "/data/cache"}, manifest.AllowedPaths)
+
+	manifest, err = buildManifest("/path/to/plugin", "", rc, &Permissions{Network: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, manifest.AllowedHosts)
+
+	_, err = buildManifest("/path/to/plugin", "", rc, &Permissions{Filesystem: []string{"relative/path"}})
+	assert.ErrorContains(t, err, "filesystem scope is not an absolute path: relative/path")
+}
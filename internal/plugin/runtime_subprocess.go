@@ -24,7 +24,6 @@ import (
 	"maps"
 	"os"
 	"os/exec"
-	"slices"
 
 	"helm.sh/helm/v4/internal/plugin/schema"
 )
@@ -97,6 +96,9 @@ func (r *SubprocessPluginRuntime) Metadata() Metadata {
 }
 
 func (r *SubprocessPluginRuntime) Invoke(_ context.Context, input *Input) (*Output, error) {
+	if err := checkConsent(r.metadata); err != nil {
+		return nil, err
+	}
 	switch input.Message.(type) {
 	case schema.InputMessageCLIV1:
 		return r.runCLI(input)
@@ -104,6 +106,8 @@ func (r *SubprocessPluginRuntime) Invoke(_ context.Context, input *Input) (*Outp
 		return r.runGetter(input)
 	case schema.InputMessagePostRendererV1:
 		return r.runPostrenderer(input)
+	case schema.InputMessageLintV1:
+		return r.runLint(input)
 	default:
 		return nil, fmt.Errorf("unsupported subprocess plugin type %q", r.metadata.Type)
 	}
@@ -114,12 +118,12 @@ func (r *SubprocessPluginRuntime) Invoke(_ context.Context, input *Input) (*Outp
 func (r *SubprocessPluginRuntime) InvokeWithEnv(main string, argv []string, env []string, stdin io.Reader, stdout, stderr io.Writer) error {
 	mainCmdExp := os.ExpandEnv(main)
 	cmd := exec.Command(mainCmdExp, argv...)
-	cmd.Env = slices.Clone(os.Environ())
-	cmd.Env = append(
-		cmd.Env,
-		fmt.Sprintf("HELM_PLUGIN_NAME=%s", r.metadata.Name),
-		fmt.Sprintf("HELM_PLUGIN_DIR=%s", r.pluginDir))
-	cmd.Env = append(cmd.Env, env...)
+	cmdEnv := parseEnv(os.Environ())
+	cmdEnv["HELM_PLUGIN_NAME"] = r.metadata.Name
+	cmdEnv["HELM_PLUGIN_DIR"] = r.pluginDir
+	maps.Insert(cmdEnv, maps.All(parseEnv(env)))
+	r.metadata.Permissions.restrictEnv(cmdEnv)
+	cmd.Env = formatEnv(cmdEnv)
 
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
@@ -138,11 +142,15 @@ func (r *SubprocessPluginRuntime) InvokeHook(event string) error {
 	if len(cmds) == 0 {
 		return nil
 	}
+	if err := checkConsent(r.metadata); err != nil {
+		return err
+	}
 
 	env := parseEnv(os.Environ())
 	maps.Insert(env, maps.All(r.EnvVars))
 	env["HELM_PLUGIN_NAME"] = r.metadata.Name
 	env["HELM_PLUGIN_DIR"] = r.pluginDir
+	r.metadata.Permissions.restrictEnv(env)
 
 	main, argv, err := PrepareCommands(cmds, r.RuntimeConfig.expandHookArgs, []string{}, env)
 	if err != nil {
@@ -203,6 +211,7 @@ func (r *SubprocessPluginRuntime) runCLI(input *Input) (*Output, error) {
 	maps.Insert(env, maps.All(parseEnv(input.Env)))
 	env["HELM_PLUGIN_NAME"] = r.metadata.Name
 	env["HELM_PLUGIN_DIR"] = r.pluginDir
+	r.metadata.Permissions.restrictEnv(env)
 
 	command, args, err := PrepareCommands(cmds, true, extraArgs, env)
 	if err != nil {
@@ -236,6 +245,7 @@ func (r *SubprocessPluginRuntime) runPostrenderer(input *Input) (*Output, error)
 	maps.Insert(env, maps.All(parseEnv(input.Env)))
 	env["HELM_PLUGIN_NAME"] = r.metadata.Name
 	env["HELM_PLUGIN_DIR"] = r.pluginDir
+	r.metadata.Permissions.restrictEnv(env)
 
 	msg := input.Message.(schema.InputMessagePostRendererV1)
 	cmds := r.RuntimeConfig.PlatformCommand
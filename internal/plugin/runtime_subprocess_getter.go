@@ -61,6 +61,7 @@ func (r *SubprocessPluginRuntime) runGetter(input *Input) (*Output, error) {
 	maps.Insert(env, maps.All(parseEnv(input.Env)))
 	env["HELM_PLUGIN_NAME"] = r.metadata.Name
 	env["HELM_PLUGIN_DIR"] = r.pluginDir
+	r.metadata.Permissions.restrictEnv(env)
 	env["HELM_PLUGIN_USERNAME"] = msg.Options.Username
 	env["HELM_PLUGIN_PASSWORD"] = msg.Options.Password
 	env["HELM_PLUGIN_PASS_CREDENTIALS_ALL"] = fmt.Sprintf("%t", msg.Options.PassCredentialsAll)
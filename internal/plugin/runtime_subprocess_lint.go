@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+
+	"helm.sh/helm/v4/internal/plugin/schema"
+)
+
+// runLint runs a lint rule plugin, which reads the input message as JSON on
+// its standard input and writes the output message as JSON to its standard
+// output, as Wasm plugins do.
+func (r *SubprocessPluginRuntime) runLint(input *Input) (*Output, error) {
+	msg, ok := input.Message.(schema.InputMessageLintV1)
+	if !ok {
+		return nil, fmt.Errorf("plugin %q input message does not implement InputMessageLintV1", r.metadata.Name)
+	}
+
+	env := parseEnv(os.Environ())
+	maps.Insert(env, maps.All(r.EnvVars))
+	maps.Insert(env, maps.All(parseEnv(input.Env)))
+	env["HELM_PLUGIN_NAME"] = r.metadata.Name
+	env["HELM_PLUGIN_DIR"] = r.pluginDir
+	r.metadata.Permissions.restrictEnv(env)
+
+	command, args, err := PrepareCommands(r.RuntimeConfig.PlatformCommand, true, msg.ExtraArgs, env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare plugin command: %w", err)
+	}
+
+	inputData, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to json marshal plugin input message: %w", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	cmd := exec.Command(command, args...)
+	cmd.Env = formatEnv(env)
+	cmd.Stdin = bytes.NewReader(inputData)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	slog.Debug("executing plugin command", slog.String("pluginName", r.metadata.Name), slog.String("command", cmd.String()))
+	if err := executeCmd(cmd, r.metadata.Name); err != nil {
+		return nil, err
+	}
+
+	var output schema.OutputMessageLintV1
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("plugin %q returned invalid output: %w", r.metadata.Name, err)
+	}
+	return &Output{Message: output}, nil
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package plugin
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -82,3 +83,24 @@ func TestSubprocessPluginRuntime(t *testing.T) {
 
 	assert.Nil(t, output)
 }
+
+func TestSubprocessPluginRuntimeConsent(t *testing.T) {
+	p := mockSubprocessCLIPluginErrorExit(t, "foo", 0)
+	errDenied := errors.New("denied")
+	var checked []string
+	SetConsentCheck(func(m Metadata) error {
+		checked = append(checked, m.Name)
+		return errDenied
+	})
+	t.Cleanup(func() { SetConsentCheck(nil) })
+
+	_, err := p.Invoke(t.Context(), &Input{Message: schema.InputMessageCLIV1{}})
+	require.ErrorIs(t, err, errDenied)
+	_, err = p.Invoke(t.Context(), &Input{Message: schema.InputMessageGetterV1{}})
+	require.ErrorIs(t, err, errDenied)
+	assert.Equal(t, []string{"foo", "foo"}, checked)
+
+	SetConsentCheck(nil)
+	_, err = p.Invoke(t.Context(), &Input{Message: schema.InputMessageCLIV1{}})
+	require.NoError(t, err)
+}
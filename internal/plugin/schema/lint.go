@@ -0,0 +1,54 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+// InputMessageLintV1 implements Input.Message for lint rule plugins. It holds
+// everything the rule may check, as plugins are not given access to the chart
+// directory.
+type InputMessageLintV1 struct {
+	// Chart is the name of the linted chart.
+	Chart string `json:"chart"`
+	// Version is the version of the linted chart.
+	Version string `json:"version"`
+	// Values are the values the chart was rendered with, merged with the
+	// defaults of the chart.
+	Values map[string]interface{} `json:"values"`
+	// Manifests are the rendered templates, by path relative to the chart,
+	// such as "templates/deployment.yaml".
+	Manifests map[string]string `json:"manifests"`
+	// ExtraArgs are the arguments the rule was configured with.
+	ExtraArgs []string `json:"extraArgs"`
+}
+
+// LintMessageV1 is a finding of a lint rule plugin.
+type LintMessageV1 struct {
+	// Severity is one of "INFO", "WARNING" and "ERROR".
+	Severity string `json:"severity"`
+	// Path is the file the finding is about, relative to the chart.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type OutputMessageLintV1 struct {
+	Messages []LintMessageV1 `json:"messages"`
+}
+
+type ConfigLintV1 struct{}
+
+func (c *ConfigLintV1) Validate() error {
+	return nil
+}
@@ -18,19 +18,68 @@ package schema
 
 import (
 	"bytes"
+	"encoding/json"
 )
 
 // InputMessagePostRendererV1 implements Input.Message
+//
+// It is encoded to JSON, as given to Wasm plugins, with the manifests as a
+// string.
 type InputMessagePostRendererV1 struct {
 	Manifests *bytes.Buffer `json:"manifests"`
 	// from CLI --post-renderer-args
 	ExtraArgs []string `json:"extraArgs"`
 }
 
+type postRendererMessageJSON struct {
+	Manifests string   `json:"manifests"`
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m InputMessagePostRendererV1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(postRendererMessageJSON{Manifests: bufferString(m.Manifests), ExtraArgs: m.ExtraArgs})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *InputMessagePostRendererV1) UnmarshalJSON(data []byte) error {
+	var in postRendererMessageJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*m = InputMessagePostRendererV1{Manifests: bytes.NewBufferString(in.Manifests), ExtraArgs: in.ExtraArgs}
+	return nil
+}
+
+// OutputMessagePostRendererV1 is the output of postrenderer plugins. It is
+// decoded from JSON, as returned by Wasm plugins, with the manifests as a
+// string.
 type OutputMessagePostRendererV1 struct {
 	Manifests *bytes.Buffer `json:"manifests"`
 }
 
+// MarshalJSON implements json.Marshaler.
+func (m OutputMessagePostRendererV1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(postRendererMessageJSON{Manifests: bufferString(m.Manifests)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *OutputMessagePostRendererV1) UnmarshalJSON(data []byte) error {
+	var out postRendererMessageJSON
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*m = OutputMessagePostRendererV1{Manifests: bytes.NewBufferString(out.Manifests)}
+	return nil
+}
+
+func bufferString(b *bytes.Buffer) string {
+	if b == nil {
+		return ""
+	}
+	return b.String()
+}
+
 type ConfigPostRendererV1 struct{}
 
 func (c *ConfigPostRendererV1) Validate() error {
@@ -0,0 +1,45 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostRendererMessageJSON(t *testing.T) {
+	input := InputMessagePostRendererV1{
+		Manifests: bytes.NewBufferString("kind: ConfigMap\n"),
+		ExtraArgs: []string{"prod"},
+	}
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"manifests":"kind: ConfigMap\n","extraArgs":["prod"]}`, string(data))
+
+	var decodedInput InputMessagePostRendererV1
+	require.NoError(t, json.Unmarshal(data, &decodedInput))
+	assert.Equal(t, "kind: ConfigMap\n", decodedInput.Manifests.String())
+	assert.Equal(t, []string{"prod"}, decodedInput.ExtraArgs)
+
+	var output OutputMessagePostRendererV1
+	require.NoError(t, json.Unmarshal([]byte(`{"manifests":"kind: Secret\n"}`), &output))
+	assert.Equal(t, "kind: Secret\n", output.Manifests.String())
+}
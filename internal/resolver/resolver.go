@@ -41,8 +41,17 @@ type Resolver struct {
 	chartpath      string
 	cachepath      string
 	registryClient *registry.Client
+	index          IndexFunc
+	refresh        RefreshFunc
 }
 
+// IndexFunc returns the index of the charts available from a repository.
+type IndexFunc func(repository string) (*repo.IndexFile, error)
+
+// RefreshFunc refreshes the cached index of a repository, given its name and
+// URL. It reports whether the index changed.
+type RefreshFunc func(repoName, repoURL string) (bool, error)
+
 // New creates a new resolver for a given chart, helm home and registry client.
 func New(chartpath, cachepath string, registryClient *registry.Client) *Resolver {
 	return &Resolver{
@@ -52,12 +61,31 @@ func New(chartpath, cachepath string, registryClient *registry.Client) *Resolver
 	}
 }
 
+// NewWithIndex creates a new resolver for a given chart which resolves the
+// dependencies from repositories and registries only with the charts listed by
+// index, without using the repository cache or the network.
+func NewWithIndex(chartpath string, index IndexFunc) *Resolver {
+	return &Resolver{
+		chartpath: chartpath,
+		index:     index,
+	}
+}
+
+// WithRefresh makes the resolver refresh the cached index of a repository,
+// once, when it does not list a version satisfying a dependency, instead of
+// failing right away.
+func (r *Resolver) WithRefresh(refresh RefreshFunc) *Resolver {
+	r.refresh = refresh
+	return r
+}
+
 // Resolve resolves dependencies and returns a lock file with the resolution.
 func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string) (*chart.Lock, error) {
 
 	// Now we clone the dependencies, locking as we go.
 	locked := make([]*chart.Dependency, len(reqs))
 	missing := []string{}
+	refreshed := map[string]bool{}
 	for i, d := range reqs {
 		constraint, err := semver.NewConstraint(d.Version)
 		if err != nil {
@@ -107,6 +135,24 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 			continue
 		}
 
+		if r.index != nil {
+			idx, err := r.index(d.Repository)
+			if err != nil {
+				return nil, err
+			}
+			locked[i] = &chart.Dependency{
+				Name:       d.Name,
+				Repository: d.Repository,
+				Version:    d.Version,
+			}
+			if v, ok := matchVersion(idx.Entries[d.Name], constraint, false); ok {
+				locked[i].Version = v
+			} else {
+				missing = append(missing, fmt.Sprintf("%q (repository %q, version %q)", d.Name, d.Repository, d.Version))
+			}
+			continue
+		}
+
 		repoName := repoNames[d.Name]
 		// if the repository was not defined, but the dependency defines a repository url, bypass the cache
 		if repoName == "" && d.Repository != "" {
@@ -120,17 +166,18 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 
 		var vs repo.ChartVersions
 		var version string
-		var ok bool
 		found := true
 		if !registry.IsOCI(d.Repository) {
-			repoIndex, err := repo.LoadIndexFile(filepath.Join(r.cachepath, helmpath.CacheIndexFile(repoName)))
-			if err != nil {
-				return nil, fmt.Errorf("no cached repository for %s found. (try 'helm repo update'): %w", repoName, err)
+			vs, err = r.indexVersions(repoName, d)
+			// The cached index may predate the chart version, refresh it once
+			if _, ok := matchVersion(vs, constraint, true); !ok && r.refresh != nil && !refreshed[repoName] {
+				refreshed[repoName] = true
+				if updated, rerr := r.refresh(repoName, d.Repository); rerr == nil && updated {
+					vs, err = r.indexVersions(repoName, d)
+				}
 			}
-
-			vs, ok = repoIndex.Entries[d.Name]
-			if !ok {
-				return nil, fmt.Errorf("%s chart not found in repo %s", d.Name, d.Repository)
+			if err != nil {
+				return nil, err
 			}
 			found = false
 		} else {
@@ -173,19 +220,10 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 			Repository: d.Repository,
 			Version:    version,
 		}
-		// The versions are already sorted and hence the first one to satisfy the constraint is used
-		for _, ver := range vs {
-			v, err := semver.NewVersion(ver.Version)
-			// OCI does not need URLs
-			if err != nil || (!registry.IsOCI(d.Repository) && len(ver.URLs) == 0) {
-				// Not a legit entry.
-				continue
-			}
-			if constraint.Check(v) {
-				found = true
-				locked[i].Version = v.Original()
-				break
-			}
+		// OCI does not need URLs
+		if v, ok := matchVersion(vs, constraint, !registry.IsOCI(d.Repository)); ok {
+			found = true
+			locked[i].Version = v
 		}
 
 		if !found {
@@ -208,6 +246,38 @@ func (r *Resolver) Resolve(reqs []*chart.Dependency, repoNames map[string]string
 	}, nil
 }
 
+// indexVersions returns the versions of the chart of a dependency listed by
+// the cached index of its repository.
+func (r *Resolver) indexVersions(repoName string, d *chart.Dependency) (repo.ChartVersions, error) {
+	repoIndex, err := repo.LoadIndexFile(filepath.Join(r.cachepath, helmpath.CacheIndexFile(repoName)))
+	if err != nil {
+		return nil, fmt.Errorf("no cached repository for %s found. (try 'helm repo update'): %w", repoName, err)
+	}
+
+	vs, ok := repoIndex.Entries[d.Name]
+	if !ok {
+		return nil, fmt.Errorf("%s chart not found in repo %s", d.Name, d.Repository)
+	}
+	return vs, nil
+}
+
+// matchVersion returns the newest of the versions satisfying the constraint.
+// Versions without URLs are skipped if requireURLs is set.
+func matchVersion(vs repo.ChartVersions, constraint *semver.Constraints, requireURLs bool) (string, bool) {
+	// The versions are already sorted and hence the first one to satisfy the constraint is used
+	for _, ver := range vs {
+		v, err := semver.NewVersion(ver.Version)
+		if err != nil || (requireURLs && len(ver.URLs) == 0) {
+			// Not a legit entry.
+			continue
+		}
+		if constraint.Check(v) {
+			return v.Original(), true
+		}
+	}
+	return "", false
+}
+
 // HashReq generates a hash of the dependencies.
 //
 // This should be used only to compare against another hash generated by this
@@ -16,11 +16,14 @@ limitations under the License.
 package resolver
 
 import (
+	"path/filepath"
 	"runtime"
 	"testing"
 
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/repo/v1"
 )
 
 func TestResolve(t *testing.T) {
@@ -308,3 +311,57 @@ func TestGetLocalPath(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveRefresh(t *testing.T) {
+	cache := t.TempDir()
+	writeIndex := func(versions ...string) {
+		t.Helper()
+		idx := repo.NewIndexFile()
+		for _, v := range versions {
+			md := &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "alpine", Version: v}
+			if err := idx.MustAdd(md, "alpine-"+v+".tgz", "http://example.com/charts", ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		idx.SortEntries()
+		if err := idx.WriteFile(filepath.Join(cache, helmpath.CacheIndexFile("example")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeIndex("0.1.0")
+
+	var calls []string
+	refresh := func(repoName, repoURL string) (bool, error) {
+		calls = append(calls, repoName+" "+repoURL)
+		writeIndex("0.1.0", "0.2.0")
+		return true, nil
+	}
+	repoNames := map[string]string{"alpine": "example"}
+	req := []*chart.Dependency{{Name: "alpine", Repository: "http://example.com/charts", Version: "^0.2.0"}}
+
+	lock, err := New("testdata/chartpath", cache, nil).WithRefresh(refresh).Resolve(req, repoNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := lock.Dependencies[0].Version; v != "0.2.0" {
+		t.Errorf("expected version 0.2.0, got %s", v)
+	}
+	if len(calls) != 1 || calls[0] != "example http://example.com/charts" {
+		t.Errorf("expected one refresh of the example repository, got %v", calls)
+	}
+
+	// A repository is refreshed once, and not when the cache satisfies the
+	// constraint.
+	calls = nil
+	req = []*chart.Dependency{
+		{Name: "alpine", Repository: "http://example.com/charts", Version: "^0.1.0"},
+		{Name: "alpine", Repository: "http://example.com/charts", Version: "^0.3.0"},
+		{Name: "alpine", Repository: "http://example.com/charts", Version: "^0.4.0"},
+	}
+	if _, err := New("testdata/chartpath", cache, nil).WithRefresh(refresh).Resolve(req, repoNames); err == nil {
+		t.Error("expected an error for the missing versions")
+	}
+	if len(calls) != 1 {
+		t.Errorf("expected one refresh, got %v", calls)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"net/url"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
 // URLJoin joins a base URL to one or more path components.
@@ -71,3 +72,28 @@ func ExtractHostname(addr string) (string, error) {
 	}
 	return u.Hostname(), nil
 }
+
+// FileURI returns the file URI of an absolute path.
+func FileURI(p string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(p)}
+	if !strings.HasPrefix(u.Path, "/") {
+		// Windows paths such as C:/charts/a.
+		u.Path = "/" + u.Path
+	}
+	return u.String()
+}
+
+// FilePath returns the path of a file URI, or s itself if it is not a file
+// URI.
+func FilePath(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "file" {
+		return s
+	}
+	p := u.Path
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		// Windows paths such as /C:/charts/a.
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
@@ -79,3 +79,20 @@ func TestExtractHostname(t *testing.T) {
 		}
 	}
 }
+
+func TestFileURI(t *testing.T) {
+	for path, uri := range map[string]string{
+		"/charts/my chart/Chart.yaml": "file:///charts/my%20chart/Chart.yaml",
+		"/charts/a":                   "file:///charts/a",
+	} {
+		if got := FileURI(path); got != uri {
+			t.Errorf("FileURI(%q) = %q, expected %q", path, got, uri)
+		}
+		if got := FilePath(uri); got != path {
+			t.Errorf("FilePath(%q) = %q, expected %q", uri, got, path)
+		}
+	}
+	if got := FilePath("charts/a"); got != "charts/a" {
+		t.Errorf("FilePath of a plain path = %q, expected it unchanged", got)
+	}
+}
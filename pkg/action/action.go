@@ -68,6 +68,13 @@ var (
 	errInvalidRevision = errors.New("invalid release revision")
 	// errPending indicates that another instance of Helm is already applying an operation on a release.
 	errPending = errors.New("another operation (install/upgrade/rollback) is in progress")
+	// ErrNoChange indicates that an upgrade was skipped because it would not
+	// change the deployed release: the chart and values, or the rendered
+	// manifest and values, are identical to the deployed release.
+	ErrNoChange = errors.New("release is unchanged from the deployed revision")
+	// ErrReleasePaused indicates that a release was paused and must be resumed
+	// before it can be upgraded or rolled back.
+	ErrReleasePaused = errors.New("release is paused")
 )
 
 // Configuration injects the dependencies that all actions share.
@@ -90,9 +97,35 @@ type Configuration struct {
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
 
+	// FuncPolicy, if set, restricts the template functions available to the
+	// charts rendered, such as lookup, so that untrusted charts can be
+	// rendered safely
+	FuncPolicy *engine.FuncPolicy
+
+	// RenderTrace, if set, records how the templates of the charts were
+	// rendered, as with the Trace of engine.Engine
+	RenderTrace *engine.RenderTrace
+
+	// RenderContext holds additional top-level objects, such as .Platform,
+	// passed to the templates of the charts rendered, as with the Context of
+	// engine.Engine. Platforms embedding Helm use it to give their charts
+	// data such as the tenant they are installed for.
+	RenderContext map[string]interface{}
+
+	// FieldManager is the name of the field manager resources are created and
+	// updated with, which owns the fields applied server-side. It defaults to
+	// kube.ManagedFieldsManager, or the name of the program if that is not set.
+	// Controllers that co-own fields of the resources can tell Helm's fields
+	// apart by it.
+	FieldManager string
+
 	// HookOutputFunc called with container name and returns and expects writer that will receive the log output.
 	HookOutputFunc func(namespace, pod, container string) io.Writer
 
+	// Instrumentation, if set, receives a record of each install, upgrade,
+	// rollback, uninstall and test. It is disabled by default.
+	Instrumentation Instrumentation
+
 	mutex sync.Mutex
 }
 
@@ -171,13 +204,29 @@ func splitAndDeannotate(postrendered string) (map[string]string, error) {
 	return reconstructed, nil
 }
 
+// renderOptions are the options of renderResources.
+type renderOptions struct {
+	releaseName    string
+	outputDir      string
+	subNotes       bool
+	useReleaseName bool
+	includeCrds    bool
+	postRenderer   postrenderer.PostRenderer
+	transformers   []ManifestTransformer
+	// interactWithRemote renders with a client of the cluster, for lookups.
+	interactWithRemote bool
+	enableDNS          bool
+	strictValues       bool
+	hideSecret         bool
+}
+
 // renderResources renders the templates in a chart
 //
 // TODO: This function is badly in need of a refactor.
 // TODO: As part of the refactor the duplicate code in cmd/helm/template.go should be removed
 //
 //	This code has to do with writing files to disk.
-func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values, releaseName, outputDir string, subNotes, useReleaseName, includeCrds bool, pr postrenderer.PostRenderer, interactWithRemote, enableDNS, hideSecret bool) ([]*release.Hook, *bytes.Buffer, string, error) {
+func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values, opts renderOptions) ([]*release.Hook, *bytes.Buffer, string, error) {
 	var hs []*release.Hook
 	b := bytes.NewBuffer(nil)
 
@@ -198,20 +247,28 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values,
 	// A `helm template` should not talk to the remote cluster. However, commands with the flag
 	// `--dry-run` with the value of `false`, `none`, or `server` should try to interact with the cluster.
 	// It may break in interesting and exotic ways because other data (e.g. discovery) is mocked.
-	if interactWithRemote && cfg.RESTClientGetter != nil {
+	if opts.interactWithRemote && cfg.RESTClientGetter != nil {
 		restConfig, err := cfg.RESTClientGetter.ToRESTConfig()
 		if err != nil {
 			return hs, b, "", err
 		}
 		e := engine.New(restConfig)
-		e.EnableDNS = enableDNS
+		e.EnableDNS = opts.enableDNS
+		e.StrictValues = opts.strictValues
 		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
+		e.FuncPolicy = cfg.FuncPolicy
+		e.Trace = cfg.RenderTrace
+		e.Context = cfg.RenderContext
 
 		files, err2 = e.Render(ch, values)
 	} else {
 		var e engine.Engine
-		e.EnableDNS = enableDNS
+		e.EnableDNS = opts.enableDNS
+		e.StrictValues = opts.strictValues
 		e.CustomTemplateFuncs = cfg.CustomTemplateFuncs
+		e.FuncPolicy = cfg.FuncPolicy
+		e.Trace = cfg.RenderTrace
+		e.Context = cfg.RenderContext
 
 		files, err2 = e.Render(ch, values)
 	}
@@ -228,7 +285,7 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values,
 	var notesBuffer bytes.Buffer
 	for k, v := range files {
 		if strings.HasSuffix(k, notesFileSuffix) {
-			if subNotes || (k == path.Join(ch.Name(), "templates", notesFileSuffix)) {
+			if opts.subNotes || (k == path.Join(ch.Name(), "templates", notesFileSuffix)) {
 				// If buffer contains data, add newline before adding more
 				if notesBuffer.Len() > 0 {
 					notesBuffer.WriteString("\n")
@@ -240,7 +297,7 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values,
 	}
 	notes := notesBuffer.String()
 
-	if pr != nil {
+	if opts.postRenderer != nil {
 		// We need to send files to the post-renderer before sorting and splitting
 		// hooks from manifests. The post-renderer interface expects a stream of
 		// manifests (similar to what tools like Kustomize and kubectl expect), whereas
@@ -255,7 +312,7 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values,
 		}
 
 		// Run the post renderer
-		postRendered, err := pr.Run(bytes.NewBufferString(merged))
+		postRendered, err := opts.postRenderer.Run(bytes.NewBufferString(merged))
 		if err != nil {
 			return hs, b, notes, fmt.Errorf("error while running post render on files: %w", err)
 		}
@@ -267,6 +324,11 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values,
 		}
 	}
 
+	files, err = transformManifests(files, opts.transformers)
+	if err != nil {
+		return hs, b, notes, err
+	}
+
 	// Sort hooks, manifests, and partials. Only hooks and manifests are returned,
 	// as partials are not used after renderer.Render. Empty manifests are also
 	// removed here.
@@ -289,12 +351,12 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values,
 	// Aggregate all valid manifests into one big doc.
 	fileWritten := make(map[string]bool)
 
-	if includeCrds {
+	if opts.includeCrds {
 		for _, crd := range ch.CRDObjects() {
-			if outputDir == "" {
+			if opts.outputDir == "" {
 				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", crd.Filename, string(crd.File.Data[:]))
 			} else {
-				err = writeToFile(outputDir, crd.Filename, string(crd.File.Data[:]), fileWritten[crd.Filename])
+				err = writeToFile(opts.outputDir, crd.Filename, string(crd.File.Data[:]), fileWritten[crd.Filename])
 				if err != nil {
 					return hs, b, "", err
 				}
@@ -304,16 +366,16 @@ func (cfg *Configuration) renderResources(ch *chart.Chart, values common.Values,
 	}
 
 	for _, m := range manifests {
-		if outputDir == "" {
-			if hideSecret && m.Head.Kind == "Secret" && m.Head.Version == "v1" {
+		if opts.outputDir == "" {
+			if opts.hideSecret && m.Head.Kind == "Secret" && m.Head.Version == "v1" {
 				fmt.Fprintf(b, "---\n# Source: %s\n# HIDDEN: The Secret output has been suppressed\n", m.Name)
 			} else {
 				fmt.Fprintf(b, "---\n# Source: %s\n%s\n", m.Name, m.Content)
 			}
 		} else {
-			newDir := outputDir
-			if useReleaseName {
-				newDir = filepath.Join(outputDir, releaseName)
+			newDir := opts.outputDir
+			if opts.useReleaseName {
+				newDir = filepath.Join(opts.outputDir, opts.releaseName)
 			}
 			// NOTE: We do not have to worry about the post-renderer because
 			// output dir is only used by `helm template`. In the next major
@@ -389,6 +451,15 @@ func (cfg *Configuration) KubernetesClientSet() (kubernetes.Interface, error) {
 	return kubernetes.NewForConfig(conf)
 }
 
+// getWaiter returns the waiter for the strategy. The rollout progress is
+// reported to the given function when the Kubernetes client supports it.
+func (cfg *Configuration) getWaiter(strategy kube.WaitStrategy, progress kube.RolloutProgressFunc) (kube.Waiter, error) {
+	if kubeClient, ok := cfg.KubeClient.(kube.InterfaceWaitProgress); ok && progress != nil {
+		return kubeClient.GetWaiterWithProgress(strategy, progress)
+	}
+	return cfg.KubeClient.GetWaiter(strategy)
+}
+
 // Now generates a timestamp
 //
 // If the configuration has a Timestamper on it, that will be used.
@@ -787,8 +787,7 @@ func TestRenderResources_PostRenderer_Success(t *testing.T) {
 	values := map[string]interface{}{}
 
 	hooks, buf, notes, err := cfg.renderResources(
-		ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false,
+		ch, values, renderOptions{releaseName: "test-release", postRenderer: mockPR},
 	)
 
 	assert.NoError(t, err)
@@ -830,8 +829,7 @@ func TestRenderResources_PostRenderer_Error(t *testing.T) {
 	values := map[string]interface{}{}
 
 	_, _, _, err := cfg.renderResources(
-		ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false,
+		ch, values, renderOptions{releaseName: "test-release", postRenderer: mockPR},
 	)
 
 	assert.Error(t, err)
@@ -858,8 +856,7 @@ func TestRenderResources_PostRenderer_MergeError(t *testing.T) {
 	values := map[string]interface{}{}
 
 	_, _, _, err := cfg.renderResources(
-		ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false,
+		ch, values, renderOptions{releaseName: "test-release", postRenderer: mockPR},
 	)
 
 	assert.Error(t, err)
@@ -880,8 +877,7 @@ func TestRenderResources_PostRenderer_SplitError(t *testing.T) {
 	values := map[string]interface{}{}
 
 	_, _, _, err := cfg.renderResources(
-		ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false,
+		ch, values, renderOptions{releaseName: "test-release", postRenderer: mockPR},
 	)
 
 	assert.Error(t, err)
@@ -901,8 +897,7 @@ func TestRenderResources_PostRenderer_Integration(t *testing.T) {
 	values := map[string]interface{}{}
 
 	hooks, buf, notes, err := cfg.renderResources(
-		ch, values, "test-release", "", false, false, false,
-		mockPR, false, false, false,
+		ch, values, renderOptions{releaseName: "test-release", postRenderer: mockPR},
 	)
 
 	assert.NoError(t, err)
@@ -937,8 +932,7 @@ func TestRenderResources_NoPostRenderer(t *testing.T) {
 	values := map[string]interface{}{}
 
 	hooks, buf, notes, err := cfg.renderResources(
-		ch, values, "test-release", "", false, false, false,
-		nil, false, false, false,
+		ch, values, renderOptions{releaseName: "test-release"},
 	)
 
 	assert.NoError(t, err)
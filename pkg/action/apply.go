@@ -0,0 +1,272 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"sigs.k8s.io/yaml"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// Apply operation names reported in ApplyResult.Operation.
+const (
+	ApplyOperationInstall = "install"
+	ApplyOperationUpgrade = "upgrade"
+	ApplyOperationNone    = "none"
+)
+
+// Apply is the action for installing a release, or upgrading it if it already
+// exists.
+//
+// Apply is idempotent: applying the same chart and values as the deployed
+// release does not create a new revision, unless the ChartRefreshPolicy of
+// Upgrade is set to ChartRefreshAlways. Install and Upgrade carry the options
+// of the underlying actions; the release name and namespace are set by Apply.
+type Apply struct {
+	cfg *Configuration
+
+	Install *Install
+	Upgrade *Upgrade
+
+	// Namespace is the namespace in which this operation should be performed.
+	Namespace string
+	// DryRun reports the pending changes without creating a release or
+	// modifying any cluster resources. Unlike a client-side dry run, the
+	// cluster is still consulted for lookups and validation.
+	DryRun bool
+}
+
+// ResourceChanges counts the changes to resources of a single kind.
+type ResourceChanges struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Deleted   int `json:"deleted"`
+}
+
+// ChangeSummary maps each resource kind to the changes made to it.
+type ChangeSummary map[string]*ResourceChanges
+
+// Changed reports whether the summary contains any created, updated or deleted resource.
+func (s ChangeSummary) Changed() bool {
+	for _, c := range s {
+		if c.Created+c.Updated+c.Deleted > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Kinds returns the kinds in the summary in sorted order.
+func (s ChangeSummary) Kinds() []string {
+	kinds := make([]string, 0, len(s))
+	for k := range s {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func (s ChangeSummary) get(kind string) *ResourceChanges {
+	c, ok := s[kind]
+	if !ok {
+		c = &ResourceChanges{}
+		s[kind] = c
+	}
+	return c
+}
+
+// ApplyResult is the result of Apply.
+type ApplyResult struct {
+	// Release is the release after the operation. When nothing changed this
+	// is the deployed release.
+	Release *release.Release `json:"release"`
+	// Operation is one of ApplyOperationInstall, ApplyOperationUpgrade or
+	// ApplyOperationNone.
+	Operation string `json:"operation"`
+	// DryRun is true if no changes were made.
+	DryRun bool `json:"dry_run"`
+	// Changes summarizes the resource changes per kind, computed by comparing
+	// the rendered manifests of the previous and the new release.
+	Changes ChangeSummary `json:"changes"`
+}
+
+// NewApply creates a new Apply object with the given configuration.
+func NewApply(cfg *Configuration) *Apply {
+	return &Apply{
+		cfg:     cfg,
+		Install: NewInstall(cfg),
+		Upgrade: NewUpgrade(cfg),
+	}
+}
+
+// Run installs or upgrades the named release.
+func (a *Apply) Run(name string, chrt *chart.Chart, vals map[string]interface{}) (*ApplyResult, error) {
+	return a.RunWithContext(context.Background(), name, chrt, vals)
+}
+
+// RunWithContext installs or upgrades the named release with context.
+func (a *Apply) RunWithContext(ctx context.Context, name string, chrt *chart.Chart, vals map[string]interface{}) (*ApplyResult, error) {
+	if name == "" {
+		return nil, errMissingRelease
+	}
+
+	versions, err := a.cfg.Releases.History(name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, err
+	}
+	releaseutil.SortByRevision(versions)
+	if len(versions) == 0 || versions[len(versions)-1].Info.Status == release.StatusUninstalled {
+		return a.install(ctx, name, chrt, vals, len(versions) > 0)
+	}
+	return a.upgrade(ctx, name, chrt, vals)
+}
+
+func (a *Apply) install(ctx context.Context, name string, chrt *chart.Chart, vals map[string]interface{}, replace bool) (*ApplyResult, error) {
+	slog.Debug("release does not exist, installing it", "name", name)
+
+	a.Install.ReleaseName = name
+	a.Install.Namespace = a.Namespace
+	a.Install.Replace = replace
+	if a.DryRun {
+		a.Install.DryRunOption = "server"
+	}
+
+	rel, err := a.Install.RunWithContext(ctx, chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	return &ApplyResult{
+		Release:   rel,
+		Operation: ApplyOperationInstall,
+		DryRun:    a.DryRun,
+		Changes:   summarizeChanges("", rel.Manifest),
+	}, nil
+}
+
+func (a *Apply) upgrade(ctx context.Context, name string, chrt *chart.Chart, vals map[string]interface{}) (*ApplyResult, error) {
+	a.Upgrade.Namespace = a.Namespace
+	if a.Upgrade.ChartRefreshPolicy == "" {
+		a.Upgrade.ChartRefreshPolicy = ChartRefreshIfChanged
+	}
+	if a.DryRun {
+		a.Upgrade.DryRunOption = "server"
+	}
+
+	previous, err := a.cfg.Releases.Deployed(name)
+	if err != nil && !errors.Is(err, driver.ErrNoDeployedReleases) {
+		return nil, err
+	}
+
+	rel, err := a.Upgrade.RunWithContext(ctx, name, chrt, vals)
+	if errors.Is(err, ErrNoChange) {
+		return &ApplyResult{
+			Release:   rel,
+			Operation: ApplyOperationNone,
+			DryRun:    a.DryRun,
+			Changes:   summarizeChanges(rel.Manifest, rel.Manifest),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var previousManifest string
+	if previous != nil {
+		previousManifest = previous.Manifest
+	}
+	return &ApplyResult{
+		Release:   rel,
+		Operation: ApplyOperationUpgrade,
+		DryRun:    a.DryRun,
+		Changes:   summarizeChanges(previousManifest, rel.Manifest),
+	}, nil
+}
+
+// summarizeChanges compares the objects of two rendered manifests.
+//
+// Objects are matched by apiVersion, kind, namespace and name. An object
+// present in both manifests counts as updated if its rendered content differs.
+func summarizeChanges(previous, current string) ChangeSummary {
+	summary := ChangeSummary{}
+	before := manifestObjects(previous)
+	after := manifestObjects(current)
+
+	for key, obj := range after {
+		c := summary.get(obj.kind)
+		old, ok := before[key]
+		switch {
+		case !ok:
+			c.Created++
+		case old.content != obj.content:
+			c.Updated++
+		default:
+			c.Unchanged++
+		}
+	}
+	for key, obj := range before {
+		if _, ok := after[key]; !ok {
+			summary.get(obj.kind).Deleted++
+		}
+	}
+	return summary
+}
+
+type manifestObject struct {
+	kind    string
+	content string
+}
+
+func manifestObjects(manifest string) map[string]manifestObject {
+	objects := make(map[string]manifestObject)
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || obj == nil {
+			continue
+		}
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil || head.Kind == "" {
+			continue
+		}
+		// Re-encoding normalizes formatting, key order and comments such as
+		// the "# Source" header, so only semantic changes are counted.
+		content, err := json.Marshal(obj)
+		if err != nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s/%s", head.APIVersion, head.Kind, head.Metadata.Namespace, head.Metadata.Name)
+		objects[key] = manifestObject{kind: head.Kind, content: string(content)}
+	}
+	return objects
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func applyChart(replicas string) *chart.Chart {
+	return buildChartWithTemplates([]*common.File{
+		{Name: "templates/cm.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  replicas: \"" + replicas + "\"\n")},
+		{Name: "templates/svc.yaml", Data: []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: svc\n")},
+	})
+}
+
+func TestApply(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	config := actionConfigFixture(t)
+	newApply := func() *Apply {
+		a := NewApply(config)
+		a.Namespace = "spaced"
+		return a
+	}
+
+	// The release does not exist yet, so it is installed.
+	res, err := newApply().Run("apply", applyChart("1"), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(ApplyOperationInstall, res.Operation)
+	is.Equal(1, res.Release.Version)
+	is.Equal(ResourceChanges{Created: 1}, *res.Changes["ConfigMap"])
+	is.Equal(ResourceChanges{Created: 1}, *res.Changes["Service"])
+
+	// Applying the same chart and values again is a no-op.
+	res, err = newApply().Run("apply", applyChart("1"), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(ApplyOperationNone, res.Operation)
+	is.Equal(1, res.Release.Version)
+	is.False(res.Changes.Changed())
+	is.Equal(ResourceChanges{Unchanged: 1}, *res.Changes["ConfigMap"])
+
+	// A dry run reports the pending changes but stores nothing.
+	a := newApply()
+	a.DryRun = true
+	res, err = a.Run("apply", applyChart("2"), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(ApplyOperationUpgrade, res.Operation)
+	is.True(res.DryRun)
+	is.Equal(ResourceChanges{Updated: 1}, *res.Changes["ConfigMap"])
+	is.Equal(ResourceChanges{Unchanged: 1}, *res.Changes["Service"])
+	last, err := config.Releases.Last("apply")
+	req.NoError(err)
+	is.Equal(1, last.Version)
+
+	// A real upgrade creates a new revision.
+	res, err = newApply().Run("apply", applyChart("2"), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(ApplyOperationUpgrade, res.Operation)
+	is.Equal(2, res.Release.Version)
+	is.Equal(release.StatusDeployed, res.Release.Info.Status)
+	is.True(res.Changes.Changed())
+
+	// An explicit "always" policy forces an upgrade of an unchanged release.
+	a = newApply()
+	a.Upgrade.ChartRefreshPolicy = ChartRefreshAlways
+	res, err = a.Run("apply", applyChart("2"), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(ApplyOperationUpgrade, res.Operation)
+	is.Equal(3, res.Release.Version)
+}
+
+func TestSummarizeChanges(t *testing.T) {
+	previous := `---
+# Source: c/templates/a.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data: {k: v}
+---
+# Source: c/templates/b.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+`
+	current := `---
+# Source: c/templates/renamed.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+data:
+  k: v
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: d
+`
+	summary := summarizeChanges(previous, current)
+	assert.Equal(t, []string{"ConfigMap", "Deployment"}, summary.Kinds())
+	assert.Equal(t, ResourceChanges{Unchanged: 1, Deleted: 1}, *summary["ConfigMap"])
+	assert.Equal(t, ResourceChanges{Created: 1}, *summary["Deployment"])
+}
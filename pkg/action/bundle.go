@@ -0,0 +1,222 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/registry"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// BundleAPIVersion identifies the format of rollback bundles.
+const BundleAPIVersion = "helm.sh/rollback-bundle/v1"
+
+// Bundle is a rollback bundle: everything needed to roll a release back to a
+// revision without the release history stored in the cluster.
+//
+// Bundles are exported after a release is deployed, to a directory or an OCI
+// registry, and used by 'helm rollback --from-bundle'.
+type Bundle struct {
+	APIVersion string `json:"apiVersion"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+	// Revision is the revision of the release the bundle was exported from.
+	Revision int `json:"revision"`
+	// Chart is the chart the release was rendered from, and ChartDigest the
+	// SHA-256 digest of its JSON encoding, which is checked when the bundle
+	// is loaded.
+	Chart       *chart.Chart `json:"chart"`
+	ChartDigest string       `json:"chart_digest"`
+	// Values are the values the release was rendered with, without the
+	// chart's defaults.
+	Values      map[string]interface{} `json:"values,omitempty"`
+	Manifest    string                 `json:"manifest"`
+	Hooks       []*release.Hook        `json:"hooks,omitempty"`
+	Notes       string                 `json:"notes,omitempty"`
+	Labels      map[string]string      `json:"labels,omitempty"`
+	ApplyMethod string                 `json:"apply_method,omitempty"`
+}
+
+// NewBundle creates the rollback bundle of a release revision.
+func NewBundle(rel *release.Release) (*Bundle, error) {
+	if rel == nil || rel.Chart == nil {
+		return nil, errors.New("release has no chart")
+	}
+	digest, err := jsonDigest(rel.Chart)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{
+		APIVersion:  BundleAPIVersion,
+		Name:        rel.Name,
+		Namespace:   rel.Namespace,
+		Revision:    rel.Version,
+		Chart:       rel.Chart,
+		ChartDigest: "sha256:" + digest,
+		Values:      rel.Config,
+		Manifest:    rel.Manifest,
+		Hooks:       rel.Hooks,
+		Labels:      rel.Labels,
+		ApplyMethod: rel.ApplyMethod,
+	}
+	if rel.Info != nil {
+		b.Notes = rel.Info.Notes
+	}
+	return b, nil
+}
+
+// Verify checks that the bundle is complete and that its chart matches the
+// chart digest.
+func (b *Bundle) Verify() error {
+	if b.APIVersion != BundleAPIVersion {
+		return fmt.Errorf("unsupported rollback bundle apiVersion %q", b.APIVersion)
+	}
+	if b.Name == "" || b.Revision <= 0 {
+		return errors.New("rollback bundle has no release name or revision")
+	}
+	if b.Chart == nil || b.Chart.Metadata == nil {
+		return errors.New("rollback bundle has no chart")
+	}
+	digest, err := jsonDigest(b.Chart)
+	if err != nil {
+		return err
+	}
+	if "sha256:"+digest != b.ChartDigest {
+		return fmt.Errorf("rollback bundle chart digest mismatch: expected %s, got sha256:%s", b.ChartDigest, digest)
+	}
+	return nil
+}
+
+// Encode writes the bundle as gzip compressed JSON.
+func (b *Bundle) Encode(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(b); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// DecodeBundle reads a bundle written by Encode and verifies it. Bundles that
+// are plain JSON are accepted too.
+func DecodeBundle(r io.Reader) (*Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("unable to decompress rollback bundle: %w", err)
+		}
+	}
+
+	b := &Bundle{}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, fmt.Errorf("unable to decode rollback bundle: %w", err)
+	}
+	if err := b.Verify(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// bundleFileName returns the name of the file a bundle is exported to in a
+// directory, after the name of the release record in storage.
+func bundleFileName(name string, revision int) string {
+	return fmt.Sprintf("%s.v%d.bundle", name, revision)
+}
+
+// ExportBundle exports the rollback bundle of a release revision to dest,
+// either a directory or an OCI repository given as "oci://host/path". In a
+// directory, the bundle is written to NAME.vREVISION.bundle; in a registry,
+// it is pushed to host/path/NAME:vREVISION. It returns the location of the
+// bundle.
+func ExportBundle(cfg *Configuration, rel *release.Release, dest string) (string, error) {
+	b, err := NewBundle(rel)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := b.Encode(&buf); err != nil {
+		return "", err
+	}
+
+	if registry.IsOCI(dest) {
+		if cfg.RegistryClient == nil {
+			return "", errors.New("a registry client is required to export rollback bundles to a registry")
+		}
+		ref := fmt.Sprintf("%s/%s:v%d", strings.TrimSuffix(strings.TrimPrefix(dest, fmt.Sprintf("%s://", registry.OCIScheme)), "/"), b.Name, b.Revision)
+		config, err := json.Marshal(map[string]interface{}{
+			"name":         b.Name,
+			"namespace":    b.Namespace,
+			"revision":     b.Revision,
+			"chart_digest": b.ChartDigest,
+		})
+		if err != nil {
+			return "", err
+		}
+		if _, err := cfg.RegistryClient.PushRollbackBundle(buf.Bytes(), config, ref); err != nil {
+			return "", fmt.Errorf("unable to push rollback bundle to %s: %w", ref, err)
+		}
+		return fmt.Sprintf("%s://%s", registry.OCIScheme, ref), nil
+	}
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dest, bundleFileName(b.Name, b.Revision))
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// LoadBundle loads a rollback bundle from a file or from a registry, given
+// as "oci://host/path/NAME:vREVISION".
+func LoadBundle(cfg *Configuration, src string) (*Bundle, error) {
+	if registry.IsOCI(src) {
+		if cfg.RegistryClient == nil {
+			return nil, errors.New("a registry client is required to load rollback bundles from a registry")
+		}
+		data, err := cfg.RegistryClient.PullRollbackBundle(strings.TrimPrefix(src, fmt.Sprintf("%s://", registry.OCIScheme)))
+		if err != nil {
+			return nil, fmt.Errorf("unable to pull rollback bundle %s: %w", src, err)
+		}
+		return DecodeBundle(bytes.NewReader(data))
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeBundle(f)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	rel := releaseStub()
+	rel.Namespace = "spaced"
+	rel.Manifest = "kind: ConfigMap\n"
+	rel.Info.Notes = "some notes"
+
+	b, err := NewBundle(rel)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(b.ChartDigest, "sha256:"))
+
+	var buf bytes.Buffer
+	require.NoError(t, b.Encode(&buf))
+	decoded, err := DecodeBundle(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "angry-panda", decoded.Name)
+	assert.Equal(t, "spaced", decoded.Namespace)
+	assert.Equal(t, 1, decoded.Revision)
+	assert.Equal(t, rel.Manifest, decoded.Manifest)
+	assert.Equal(t, rel.Config, decoded.Values)
+	assert.Equal(t, "some notes", decoded.Notes)
+	assert.Len(t, decoded.Hooks, 2)
+	assert.Equal(t, rel.Chart.Metadata.Name, decoded.Chart.Metadata.Name)
+}
+
+func TestDecodeBundleChartDigestMismatch(t *testing.T) {
+	b, err := NewBundle(releaseStub())
+	require.NoError(t, err)
+	b.Chart.Metadata.Version = "9.9.9"
+
+	var buf bytes.Buffer
+	require.NoError(t, b.Encode(&buf))
+	_, err = DecodeBundle(&buf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chart digest mismatch")
+}
+
+func TestExportAndLoadBundle(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	dir := t.TempDir()
+
+	path, err := ExportBundle(cfg, releaseStub(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "angry-panda.v1.bundle"), path)
+
+	b, err := LoadBundle(cfg, path)
+	require.NoError(t, err)
+	assert.Equal(t, "angry-panda", b.Name)
+}
+
+func TestRollbackFromBundle(t *testing.T) {
+	t.Run("without release history", func(t *testing.T) {
+		cfg := actionConfigFixture(t)
+		b, err := NewBundle(releaseStub())
+		require.NoError(t, err)
+
+		rollback := NewRollback(cfg)
+		rollback.Bundle = b
+		rollback.ServerSideApply = "auto"
+		require.NoError(t, rollback.Run("angry-panda"))
+
+		rel, err := cfg.Releases.Last("angry-panda")
+		require.NoError(t, err)
+		assert.Equal(t, 1, rel.Version)
+		assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+		assert.Equal(t, "Rollback to 1 from bundle", rel.Info.Description)
+		assert.Equal(t, b.Values, rel.Config)
+	})
+
+	t.Run("with release history", func(t *testing.T) {
+		cfg := actionConfigFixture(t)
+		first := releaseStub()
+		first.Info.Status = release.StatusSuperseded
+		second := releaseStub()
+		second.Version = 2
+		second.Config = map[string]interface{}{"name": "other"}
+		require.NoError(t, cfg.Releases.Create(first))
+		require.NoError(t, cfg.Releases.Create(second))
+
+		b, err := NewBundle(first)
+		require.NoError(t, err)
+		// The history of revision 1 is lost, but its bundle was kept.
+		_, err = cfg.Releases.Delete("angry-panda", 1)
+		require.NoError(t, err)
+
+		rollback := NewRollback(cfg)
+		rollback.Bundle = b
+		rollback.ServerSideApply = "auto"
+		rollback.ExportBundle = t.TempDir()
+		require.NoError(t, rollback.Run("angry-panda"))
+
+		rel, err := cfg.Releases.Last("angry-panda")
+		require.NoError(t, err)
+		assert.Equal(t, 3, rel.Version)
+		assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+		assert.Equal(t, first.Config, rel.Config)
+		previous, err := cfg.Releases.Get("angry-panda", 2)
+		require.NoError(t, err)
+		assert.Equal(t, release.StatusSuperseded, previous.Info.Status)
+
+		exported, err := LoadBundle(cfg, filepath.Join(rollback.ExportBundle, "angry-panda.v3.bundle"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, exported.Revision)
+	})
+
+	t.Run("for another release", func(t *testing.T) {
+		b, err := NewBundle(releaseStub())
+		require.NoError(t, err)
+		rollback := NewRollback(actionConfigFixture(t))
+		rollback.Bundle = b
+		rollback.ServerSideApply = "auto"
+		assert.ErrorContains(t, rollback.Run("other"), "rollback bundle is for release \"angry-panda\"")
+	})
+}
@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/registry"
+)
+
+// ChartDiff is the action for comparing two charts.
+//
+// It provides the implementation of 'helm chart diff'.
+type ChartDiff struct {
+	// ChartPathOptions locate both charts. Its Version constrains the
+	// version of the first chart.
+	ChartPathOptions
+	// ToVersion constrains the version of the second chart.
+	ToVersion string
+}
+
+// NewChartDiff creates a new ChartDiff object.
+func NewChartDiff() *ChartDiff {
+	return &ChartDiff{}
+}
+
+// SetRegistryClient sets the registry client to use when pulling a chart from a registry.
+func (d *ChartDiff) SetRegistryClient(client *registry.Client) {
+	d.registryClient = client
+}
+
+// Locate returns the paths of the two charts, downloading them if needed.
+func (d *ChartDiff) Locate(from, to string, settings *cli.EnvSettings) (string, string, error) {
+	fromPath, err := d.LocateChart(from, settings)
+	if err != nil {
+		return "", "", err
+	}
+	toOptions := d.ChartPathOptions
+	toOptions.Version = d.ToVersion
+	toPath, err := toOptions.LocateChart(to, settings)
+	if err != nil {
+		return "", "", err
+	}
+	return fromPath, toPath, nil
+}
+
+// Run compares the charts at the paths from and to.
+func (d *ChartDiff) Run(from, to string) (*chartutil.ChartDiff, error) {
+	a, err := loader.Load(from)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", from, err)
+	}
+	b, err := loader.Load(to)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", to, err)
+	}
+	return chartutil.DiffCharts(a, b)
+}
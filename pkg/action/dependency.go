@@ -28,6 +28,8 @@ import (
 
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/downloader"
 )
 
 // Dependency is the action for building a given chart's dependency tree.
@@ -37,6 +39,9 @@ type Dependency struct {
 	Verify                bool
 	Keyring               string
 	SkipRefresh           bool
+	Offline               bool
+	Parallelism           int
+	Retries               int
 	ColumnWidth           uint
 	Username              string
 	Password              string
@@ -72,6 +77,56 @@ func (d *Dependency) List(chartpath string, out io.Writer) error {
 	return nil
 }
 
+// DependencyListElement is a dependency of a chart, as listed by 'helm dependency list'.
+type DependencyListElement struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository"`
+	Status     string `json:"status"`
+}
+
+// ListDependencies returns the dependencies declared by the chart and their
+// status. It implements the structured output of 'helm dependency list'.
+func (d *Dependency) ListDependencies(chartpath string) ([]DependencyListElement, error) {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the array so no dependencies returns an empty array instead of null
+	deps := make([]DependencyListElement, 0, len(c.Metadata.Dependencies))
+	for _, dep := range c.Metadata.Dependencies {
+		deps = append(deps, DependencyListElement{
+			Name:       dep.Name,
+			Version:    dep.Version,
+			Repository: dep.Repository,
+			Status:     d.dependencyStatus(chartpath, dep, c),
+		})
+	}
+	return deps, nil
+}
+
+// Status reports, for the given values, which dependencies of the chart and
+// of its enabled dependencies would be enabled, and why. It implements
+// 'helm dependency status'.
+func (d *Dependency) Status(chartpath string, vals map[string]interface{}) ([]chartutil.DependencyStatus, error) {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.DependencyStatuses(c, vals)
+}
+
+// Graph returns the graph of the dependencies of the chart and of its
+// dependencies. It implements 'helm dependency graph'.
+func (d *Dependency) Graph(chartpath string) (*chartutil.DependencyGraph, error) {
+	c, err := loader.Load(chartpath)
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.BuildDependencyGraph(c), nil
+}
+
 // dependencyStatus returns a string describing the status of a dependency viz a viz the parent chart.
 func (d *Dependency) dependencyStatus(chartpath string, dep *chart.Dependency, parent *chart.Chart) string {
 	filename := fmt.Sprintf("%s-%s.tgz", dep.Name, "*")
@@ -151,6 +206,16 @@ func (d *Dependency) dependencyStatus(chartpath string, dep *chart.Dependency, p
 		}
 	}
 
+	// Dependencies unpacked by 'helm dependency vendor' are tracked in the vendor manifest.
+	if manifest, err := downloader.LoadVendorManifest(chartpath); err == nil {
+		if vendored := manifest.Get(dep.Name); vendored != nil {
+			if modified, err := vendored.Modified(chartpath); err == nil && modified {
+				return "vendored, modified"
+			}
+			return "vendored"
+		}
+	}
+
 	return "unpacked"
 }
 
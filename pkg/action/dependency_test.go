@@ -26,7 +26,9 @@ import (
 
 	"helm.sh/helm/v4/internal/test"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/downloader"
 )
 
 func TestList(t *testing.T) {
@@ -150,3 +152,31 @@ func TestStatArchiveForStatus(t *testing.T) {
 	}
 	is.Equal("ok", statArchiveForStatus(where, dep))
 }
+
+func TestDependencyStatus_Vendored(t *testing.T) {
+	tmp := t.TempDir()
+	if err := chartutil.SaveDir(buildChart(withName("parent")), tmp); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tmp, "parent")
+	chartsDir := filepath.Join(dir, "charts")
+	if err := os.MkdirAll(chartsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := chartutil.SaveDir(buildChart(withName("dep")), chartsDir); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := loader.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dep := &chart.Dependency{Name: "dep", Version: "0.1.0", Repository: "https://example.com/charts"}
+	assert.Equal(t, "unpacked", NewDependency().dependencyStatus(dir, dep, c))
+
+	manifest := "dependencies:\n- name: dep\n  version: 0.1.0\n  repository: https://example.com/charts\n  files: sha256:0000\n"
+	if err := os.WriteFile(filepath.Join(chartsDir, downloader.VendorManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "vendored, modified", NewDependency().dependencyStatus(dir, dep, c))
+}
@@ -0,0 +1,248 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"helm.sh/helm/v4/pkg/kube"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	helmtime "helm.sh/helm/v4/pkg/time"
+)
+
+// Leftover kinds reported by GC that are not Kubernetes kinds.
+const (
+	// LeftoverPendingRelease is a release revision stuck in a pending status,
+	// which blocks further operations on the release.
+	LeftoverPendingRelease = "PendingRelease"
+)
+
+// GC is the action for finding, and optionally removing, what interrupted or
+// misbehaving operations leave behind:
+//
+//   - hook resources that still exist although their delete policy says they
+//     should have been deleted after the hook ran, and
+//   - release revisions stuck in a pending status for longer than
+//     PendingTimeout, which make Helm refuse further operations.
+//
+// Removing a stale pending revision marks it as failed.
+type GC struct {
+	cfg *Configuration
+
+	// All inspects every release in the namespace instead of a single release.
+	All bool
+	// Delete removes the leftovers that were found. Otherwise they are only
+	// reported.
+	Delete bool
+	// PendingTimeout is how long a revision may be pending before it is
+	// considered stale.
+	PendingTimeout time.Duration
+}
+
+// Leftover describes something GC found.
+type Leftover struct {
+	Release  string `json:"release"`
+	Revision int    `json:"revision"`
+	// Kind is the Kubernetes kind of a hook resource, or
+	// LeftoverPendingRelease.
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Reason    string `json:"reason"`
+	// Deleted is true if the leftover was removed.
+	Deleted bool `json:"deleted"`
+}
+
+// NewGC creates a new GC object with the given configuration.
+func NewGC(cfg *Configuration) *GC {
+	return &GC{
+		cfg:            cfg,
+		PendingTimeout: time.Hour,
+	}
+}
+
+// Run inspects the named release, or all releases if All is set.
+func (g *GC) Run(name string) ([]*Leftover, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if !g.All && name == "" {
+		return nil, errMissingRelease
+	}
+
+	var rels []*release.Release
+	var err error
+	if g.All {
+		rels, err = g.cfg.Releases.ListReleases()
+	} else {
+		rels, err = g.cfg.Releases.History(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string][]*release.Release)
+	for _, rel := range rels {
+		byName[rel.Name] = append(byName[rel.Name], rel)
+	}
+	names := make([]string, 0, len(byName))
+	for n := range byName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var leftovers []*Leftover
+	var errs []error
+	for _, n := range names {
+		history := byName[n]
+		releaseutil.Reverse(history, releaseutil.SortByRevision)
+
+		found, err := g.staleHooks(history)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("release %q: %w", n, err))
+		}
+		leftovers = append(leftovers, found...)
+		if l := g.stalePending(history[0]); l != nil {
+			leftovers = append(leftovers, l)
+		}
+	}
+	return leftovers, errors.Join(errs...)
+}
+
+// staleHooks finds the hook resources of a release that should have been
+// deleted by their delete policy. history is sorted from newest to oldest, and
+// only the latest run of every hook is considered.
+func (g *GC) staleHooks(history []*release.Release) ([]*Leftover, error) {
+	kubeClient, ok := g.cfg.KubeClient.(kube.InterfaceResources)
+	if !ok {
+		return nil, errors.New("unable to get kubeClient with interface InterfaceResources")
+	}
+
+	var leftovers []*Leftover
+	seen := make(map[string]bool)
+	for _, rel := range history {
+		for _, h := range rel.Hooks {
+			// Never delete CustomResourceDefinitions; this could cause lots of
+			// cascading garbage collection.
+			if h.Kind == "CustomResourceDefinition" || h.LastRun.StartedAt.IsZero() {
+				continue
+			}
+			namespace, err := g.cfg.deriveNamespace(h, rel.Namespace)
+			if err != nil {
+				return leftovers, err
+			}
+			key := h.Kind + "/" + namespace + "/" + h.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			policy, ok := hookDeletePolicyFor(h.LastRun.Phase)
+			if !ok || !slices.Contains(h.DeletePolicies, policy) {
+				continue
+			}
+
+			resources, err := g.cfg.KubeClient.Build(bytes.NewBufferString(h.Manifest), false)
+			if err != nil {
+				return leftovers, fmt.Errorf("unable to build kubernetes object for hook %s: %w", h.Path, err)
+			}
+			existing, err := kubeClient.Get(resources, false)
+			if err != nil {
+				return leftovers, err
+			}
+			if len(existing) == 0 {
+				continue
+			}
+
+			l := &Leftover{
+				Release:   rel.Name,
+				Revision:  rel.Version,
+				Kind:      h.Kind,
+				Name:      h.Name,
+				Namespace: namespace,
+				Reason:    fmt.Sprintf("hook %s but was not deleted despite the %q delete policy", hookPhaseVerb(h.LastRun.Phase), policy),
+			}
+			if g.Delete {
+				slog.Debug("deleting leftover hook resource", "release", rel.Name, "kind", h.Kind, "name", h.Name)
+				if _, errs := g.cfg.KubeClient.Delete(resources); len(errs) > 0 {
+					return append(leftovers, l), joinErrors(errs, "; ")
+				}
+				l.Deleted = true
+			}
+			leftovers = append(leftovers, l)
+		}
+	}
+	return leftovers, nil
+}
+
+// stalePending reports the latest revision of a release if it has been
+// pending for longer than PendingTimeout.
+func (g *GC) stalePending(last *release.Release) *Leftover {
+	if !last.Info.Status.IsPending() {
+		return nil
+	}
+	since := last.Info.LastDeployed
+	if since.IsZero() || helmtime.Now().Sub(since) < g.PendingTimeout {
+		return nil
+	}
+
+	l := &Leftover{
+		Release:   last.Name,
+		Revision:  last.Version,
+		Kind:      LeftoverPendingRelease,
+		Name:      last.Name,
+		Namespace: last.Namespace,
+		Reason:    fmt.Sprintf("%s since %s", last.Info.Status, since.Format(time.RFC3339)),
+	}
+	if g.Delete {
+		slog.Debug("marking stale pending release as failed", "release", last.Name, "revision", last.Version)
+		last.SetStatus(release.StatusFailed, fmt.Sprintf("Operation did not finish: %s since %s", last.Info.Status, since.Format(time.RFC3339)))
+		if err := g.cfg.Releases.Update(last); err != nil {
+			slog.Warn("failed to update stale pending release", "release", last.Name, slog.Any("error", err))
+			return l
+		}
+		l.Deleted = true
+	}
+	return l
+}
+
+// hookDeletePolicyFor returns the delete policy that removes a hook after it
+// finished in the given phase.
+func hookDeletePolicyFor(phase release.HookPhase) (release.HookDeletePolicy, bool) {
+	switch phase {
+	case release.HookPhaseSucceeded:
+		return release.HookSucceeded, true
+	case release.HookPhaseFailed:
+		return release.HookFailed, true
+	default:
+		return "", false
+	}
+}
+
+func hookPhaseVerb(phase release.HookPhase) string {
+	if phase == release.HookPhaseFailed {
+		return "failed"
+	}
+	return "succeeded"
+}
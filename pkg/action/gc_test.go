@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"helm.sh/helm/v4/pkg/kube"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	helmtime "helm.sh/helm/v4/pkg/time"
+)
+
+// gcKubeClient reports every resource as existing until it is deleted.
+type gcKubeClient struct {
+	kubefake.FailingKubeClient
+	deleted int
+}
+
+func (c *gcKubeClient) Get(_ kube.ResourceList, _ bool) (map[string][]runtime.Object, error) {
+	if c.deleted > 0 {
+		return map[string][]runtime.Object{}, nil
+	}
+	return map[string][]runtime.Object{"v1/Job": {&unstructured.Unstructured{}}}, nil
+}
+
+func (c *gcKubeClient) Delete(resources kube.ResourceList) (*kube.Result, []error) {
+	c.deleted++
+	return &kube.Result{Deleted: resources}, nil
+}
+
+func gcHook(name string, phase release.HookPhase, policies ...release.HookDeletePolicy) *release.Hook {
+	return &release.Hook{
+		Name:           name,
+		Kind:           "Job",
+		Path:           "templates/" + name + ".yaml",
+		Manifest:       "kind: Job\nmetadata:\n  name: " + name,
+		Events:         []release.HookEvent{release.HookPostInstall},
+		DeletePolicies: policies,
+		LastRun: release.HookExecution{
+			StartedAt:   helmtime.Now(),
+			CompletedAt: helmtime.Now(),
+			Phase:       phase,
+		},
+	}
+}
+
+func TestGCHooks(t *testing.T) {
+	config := actionConfigFixture(t)
+	kubeClient := &gcKubeClient{FailingKubeClient: kubefake.FailingKubeClient{PrintingKubeClient: kubefake.PrintingKubeClient{Out: io.Discard}}}
+	config.KubeClient = kubeClient
+
+	rel := releaseStub()
+	rel.Hooks = []*release.Hook{
+		gcHook("migrate", release.HookPhaseSucceeded, release.HookSucceeded),
+		gcHook("smoke", release.HookPhaseFailed, release.HookSucceeded),
+		gcHook("keep", release.HookPhaseSucceeded, release.HookBeforeHookCreation),
+	}
+	require.NoError(t, config.Releases.Create(rel))
+
+	gc := NewGC(config)
+	leftovers, err := gc.Run(rel.Name)
+	require.NoError(t, err)
+	require.Len(t, leftovers, 1)
+	assert.Equal(t, "migrate", leftovers[0].Name)
+	assert.Equal(t, "Job", leftovers[0].Kind)
+	assert.False(t, leftovers[0].Deleted)
+	assert.Equal(t, 0, kubeClient.deleted, "leftovers must only be reported unless Delete is set")
+
+	gc.Delete = true
+	leftovers, err = gc.Run(rel.Name)
+	require.NoError(t, err)
+	require.Len(t, leftovers, 1)
+	assert.True(t, leftovers[0].Deleted)
+	assert.Equal(t, 1, kubeClient.deleted)
+
+	leftovers, err = gc.Run(rel.Name)
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestGCPendingRelease(t *testing.T) {
+	config := actionConfigFixture(t)
+
+	stale := releaseStub()
+	stale.Name = "stale"
+	stale.Info.Status = release.StatusPendingUpgrade
+	stale.Info.LastDeployed = helmtime.Now().Add(-2 * time.Hour)
+	require.NoError(t, config.Releases.Create(stale))
+
+	recent := releaseStub()
+	recent.Name = "recent"
+	recent.Info.Status = release.StatusPendingInstall
+	recent.Info.LastDeployed = helmtime.Now()
+	require.NoError(t, config.Releases.Create(recent))
+
+	gc := NewGC(config)
+	gc.All = true
+	leftovers, err := gc.Run("")
+	require.NoError(t, err)
+	require.Len(t, leftovers, 1)
+	assert.Equal(t, LeftoverPendingRelease, leftovers[0].Kind)
+	assert.Equal(t, "stale", leftovers[0].Release)
+
+	gc.Delete = true
+	_, err = gc.Run("")
+	require.NoError(t, err)
+	rel, err := config.Releases.Get("stale", stale.Version)
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusFailed, rel.Info.Status)
+
+	leftovers, err = gc.Run("")
+	require.NoError(t, err)
+	assert.Empty(t, leftovers)
+}
+
+func TestGCMissingRelease(t *testing.T) {
+	_, err := NewGC(actionConfigFixture(t)).Run("")
+	assert.ErrorIs(t, err, errMissingRelease)
+}
@@ -17,6 +17,11 @@ limitations under the License.
 package action
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	release "helm.sh/helm/v4/pkg/release/v1"
 )
 
@@ -28,6 +33,8 @@ type Get struct {
 
 	// Initializing Version to 0 will get the latest revision of the release.
 	Version int
+	// At, if set, gets the revision that was deployed at that time instead.
+	At time.Time
 }
 
 // NewGet creates a new Get object with the given configuration.
@@ -43,5 +50,63 @@ func (g *Get) Run(name string) (*release.Release, error) {
 		return nil, err
 	}
 
-	return g.cfg.releaseContent(name, g.Version)
+	return g.cfg.releaseContentAt(name, g.Version, g.At)
+}
+
+// ReleaseAt returns the revision of the named release that was deployed at
+// the given time, which is the last revision deployed at or before it that
+// did not fail.
+//
+// It returns an error if the release was not installed yet, or had been
+// uninstalled, at that time.
+func (cfg *Configuration) ReleaseAt(name string, at time.Time) (*release.Release, error) {
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("releaseAt: Release name is invalid: %s", name)
+	}
+
+	history, err := cfg.Releases.History(name)
+	if err != nil {
+		return nil, err
+	}
+	rel := revisionAt(history, at)
+	if rel == nil {
+		return nil, fmt.Errorf("release %q was not deployed at %s", name, at.Format(time.RFC3339))
+	}
+	if rel.Info.Status == release.StatusUninstalled && !rel.Info.Deleted.IsZero() && !rel.Info.Deleted.Time.After(at) {
+		return nil, fmt.Errorf("release %q was uninstalled at %s", name, rel.Info.Deleted.Time.Format(time.RFC3339))
+	}
+	return rel, nil
+}
+
+// revisionAt returns the last revision in history deployed at or before at,
+// skipping revisions that failed or never finished deploying. It returns nil
+// if there is none.
+func revisionAt(history []*release.Release, at time.Time) *release.Release {
+	var found *release.Release
+	for _, rel := range history {
+		if rel.Info == nil || rel.Info.LastDeployed.Time.After(at) {
+			continue
+		}
+		switch rel.Info.Status {
+		case release.StatusFailed, release.StatusPendingInstall, release.StatusPendingUpgrade, release.StatusPendingRollback:
+			continue
+		}
+		if found == nil || rel.Info.LastDeployed.After(found.Info.LastDeployed) ||
+			(rel.Info.LastDeployed.Equal(found.Info.LastDeployed) && rel.Version > found.Version) {
+			found = rel
+		}
+	}
+	return found
+}
+
+// releaseContentAt gets the release revision deployed at the time at if it is
+// set, or the given version otherwise.
+func (cfg *Configuration) releaseContentAt(name string, version int, at time.Time) (*release.Release, error) {
+	if at.IsZero() {
+		return cfg.releaseContent(name, version)
+	}
+	if version > 0 {
+		return nil, errors.New("a revision and a time cannot both be given")
+	}
+	return cfg.ReleaseAt(name, at)
 }
@@ -31,6 +31,8 @@ type GetMetadata struct {
 	cfg *Configuration
 
 	Version int
+	// At, if set, gets the revision that was deployed at that time instead.
+	At time.Time
 }
 
 type Metadata struct {
@@ -63,7 +65,7 @@ func (g *GetMetadata) Run(name string) (*Metadata, error) {
 		return nil, err
 	}
 
-	rel, err := g.cfg.releaseContent(name, g.Version)
+	rel, err := g.cfg.releaseContentAt(name, g.Version, g.At)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+	helmtime "helm.sh/helm/v4/pkg/time"
+)
+
+func TestReleaseAt(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 12, 0, 0, 0, time.UTC) }
+	revision := func(version int, status release.Status, deployed time.Time) *release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "myrel", Version: version, Status: status})
+		rel.Info.LastDeployed = helmtime.Time{Time: deployed}
+		return rel
+	}
+
+	cfg := actionConfigFixture(t)
+	for _, rel := range []*release.Release{
+		revision(1, release.StatusSuperseded, day(1)),
+		revision(2, release.StatusFailed, day(3)),
+		revision(3, release.StatusSuperseded, day(5)),
+		revision(4, release.StatusUninstalled, day(7)),
+	} {
+		require.NoError(t, cfg.Releases.Create(rel))
+	}
+	uninstalled, err := cfg.Releases.Get("myrel", 4)
+	require.NoError(t, err)
+	uninstalled.Info.Deleted = helmtime.Time{Time: day(9)}
+	require.NoError(t, cfg.Releases.Update(uninstalled))
+
+	tests := []struct {
+		at      time.Time
+		version int
+		err     string
+	}{
+		{at: day(1).Add(-time.Second), err: `release "myrel" was not deployed at 2024-06-01T11:59:59Z`},
+		{at: day(1), version: 1},
+		// The failed revision 2 was never live.
+		{at: day(4), version: 1},
+		{at: day(5).Add(time.Hour), version: 3},
+		{at: day(8), version: 4},
+		{at: day(10), err: `release "myrel" was uninstalled at 2024-06-09T12:00:00Z`},
+	}
+	for _, tt := range tests {
+		rel, err := cfg.ReleaseAt("myrel", tt.at)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err, "at %s", tt.at)
+			continue
+		}
+		require.NoError(t, err, "at %s", tt.at)
+		assert.Equal(t, tt.version, rel.Version, "at %s", tt.at)
+	}
+
+	_, err = cfg.ReleaseAt("missing", day(1))
+	assert.Error(t, err)
+}
+
+func TestGet_RunAt(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	for v, d := range []int{1, 3} {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "myrel", Version: v + 1, Status: release.StatusSuperseded})
+		rel.Info.LastDeployed = helmtime.Date(2024, 6, d, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, cfg.Releases.Create(rel))
+	}
+
+	client := NewGet(cfg)
+	client.At = time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	rel, err := client.Run("myrel")
+	require.NoError(t, err)
+	assert.Equal(t, 1, rel.Version)
+
+	client.Version = 2
+	_, err = client.Run("myrel")
+	assert.EqualError(t, err, "a revision and a time cannot both be given")
+}
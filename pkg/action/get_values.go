@@ -16,7 +16,12 @@ limitations under the License.
 
 package action
 
-import "helm.sh/helm/v4/pkg/chart/common/util"
+import (
+	"time"
+
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
 
 // GetValues is the action for checking a given release's values.
 //
@@ -26,6 +31,8 @@ type GetValues struct {
 
 	Version   int
 	AllValues bool
+	// At, if set, gets the revision that was deployed at that time instead.
+	At time.Time
 }
 
 // NewGetValues creates a new GetValues object with the given configuration.
@@ -41,7 +48,7 @@ func (g *GetValues) Run(name string) (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	rel, err := g.cfg.releaseContent(name, g.Version)
+	rel, err := g.cfg.releaseContentAt(name, g.Version, g.At)
 	if err != nil {
 		return nil, err
 	}
@@ -56,3 +63,22 @@ func (g *GetValues) Run(name string) (map[string]interface{}, error) {
 	}
 	return rel.Config, nil
 }
+
+// RunExplain executes 'helm get values --explain' against the given release,
+// returning its values along with the source that set each of them: the
+// user-supplied values, or all of the computed values if AllValues is set.
+func (g *GetValues) RunExplain(name string) ([]chartutil.ValueOrigin, error) {
+	if err := g.cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+
+	rel, err := g.cfg.releaseContentAt(name, g.Version, g.At)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.AllValues {
+		return chartutil.ExplainValues(rel.Chart, rel.Config, rel.ConfigSources)
+	}
+	return chartutil.ExplainUserValues(rel.Config, rel.ConfigSources), nil
+}
@@ -75,7 +75,8 @@ func (cfg *Configuration) execHook(rl *release.Release, hook release.HookEvent,
 		// Create hook resources
 		if _, err := cfg.KubeClient.Create(
 			resources,
-			kube.ClientCreateOptionServerSideApply(serverSideApply, false)); err != nil {
+			kube.ClientCreateOptionServerSideApply(serverSideApply, false),
+			kube.ClientCreateOptionFieldManager(cfg.FieldManager)); err != nil {
 			h.LastRun.CompletedAt = helmtime.Now()
 			h.LastRun.Phase = release.HookPhaseFailed
 			return fmt.Errorf("warning: Hook %s %s failed: %w", hook, h.Path, err)
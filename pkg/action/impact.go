@@ -0,0 +1,421 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// ImpactCategory classifies the consequence of an upgrade on a resource.
+type ImpactCategory string
+
+const (
+	// ImpactPodRestart is a change to the pod template of a workload, which
+	// replaces its pods.
+	ImpactPodRestart ImpactCategory = "pod-restart"
+	// ImpactPVC is a change to, or the removal of, a PersistentVolumeClaim
+	// or the volume claim templates of a StatefulSet.
+	ImpactPVC ImpactCategory = "pvc"
+	// ImpactServiceIP is a change that assigns a Service a new cluster or
+	// external IP, or releases the ones it has.
+	ImpactServiceIP ImpactCategory = "service-ip"
+	// ImpactCRD is the creation, change or removal of a
+	// CustomResourceDefinition.
+	ImpactCRD ImpactCategory = "crd"
+	// ImpactRBACExpansion is a Role or binding granting more permissions,
+	// or to more subjects, than before.
+	ImpactRBACExpansion ImpactCategory = "rbac-expansion"
+)
+
+// impactCategoryOrder is the order impacts are listed in, most disruptive
+// first.
+var impactCategoryOrder = map[ImpactCategory]int{
+	ImpactCRD:           0,
+	ImpactPVC:           1,
+	ImpactRBACExpansion: 2,
+	ImpactServiceIP:     3,
+	ImpactPodRestart:    4,
+}
+
+// Impact is a consequence of an upgrade on one of the resources of a release.
+type Impact struct {
+	Category  ImpactCategory `json:"category"`
+	Kind      string         `json:"kind"`
+	Namespace string         `json:"namespace,omitempty"`
+	Name      string         `json:"name"`
+	// Reason describes the change that has the impact.
+	Reason string `json:"reason"`
+}
+
+// impactObject is a resource of a release manifest.
+type impactObject struct {
+	group, kind, namespace, name string
+	obj                          map[string]interface{}
+}
+
+// AnalyzeImpact compares the manifests of a deployed and an upgraded release
+// and returns the impacts of the upgrade, ordered by category, kind,
+// namespace and name.
+//
+// Resources without a namespace are assumed to be in namespace. Resources are
+// matched by group, kind, namespace and name, so a new API version of a
+// resource is seen as a change rather than a replacement.
+func AnalyzeImpact(current, target, namespace string) []Impact {
+	before := impactObjects(current, namespace)
+	after := impactObjects(target, namespace)
+
+	var impacts []Impact
+	add := func(o impactObject, category ImpactCategory, format string, args ...interface{}) {
+		impacts = append(impacts, Impact{
+			Category:  category,
+			Kind:      o.kind,
+			Namespace: o.namespace,
+			Name:      o.name,
+			Reason:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	for key, o := range after {
+		old, ok := before[key]
+		switch {
+		case o.kind == "CustomResourceDefinition" && o.group == "apiextensions.k8s.io":
+			if !ok {
+				add(o, ImpactCRD, "created")
+			} else if paths := changedPaths(old.obj["spec"], o.obj["spec"], "spec"); len(paths) > 0 {
+				add(o, ImpactCRD, "changed: %s", summarizePaths(paths))
+			}
+		case o.kind == "PersistentVolumeClaim" && o.group == "":
+			if paths := changedPaths(old.obj["spec"], o.obj["spec"], "spec"); ok && len(paths) > 0 {
+				add(o, ImpactPVC, "changed: %s; only the storage request of a bound claim can be changed, and only increased", summarizePaths(paths))
+			}
+		case o.kind == "Service" && o.group == "":
+			if ok {
+				if reason := serviceIPChange(old.obj, o.obj); reason != "" {
+					add(o, ImpactServiceIP, "%s", reason)
+				}
+			}
+		case (o.kind == "Role" || o.kind == "ClusterRole") && o.group == "rbac.authorization.k8s.io":
+			if rules := newRules(old.obj, o.obj); len(rules) > 0 {
+				add(o, ImpactRBACExpansion, "grants %s", strings.Join(rules, "; "))
+			}
+		case (o.kind == "RoleBinding" || o.kind == "ClusterRoleBinding") && o.group == "rbac.authorization.k8s.io":
+			if reason := bindingExpansion(old.obj, o.obj, ok); reason != "" {
+				add(o, ImpactRBACExpansion, "%s", reason)
+			}
+		}
+
+		if !ok || !isWorkload(o) {
+			continue
+		}
+		if paths := changedPaths(nestedValue(old.obj, "spec", "template"), nestedValue(o.obj, "spec", "template"), "spec.template"); len(paths) > 0 {
+			add(o, ImpactPodRestart, "pod template changed: %s", summarizePaths(paths))
+		}
+		if o.kind == "StatefulSet" {
+			if paths := changedPaths(nestedValue(old.obj, "spec", "volumeClaimTemplates"), nestedValue(o.obj, "spec", "volumeClaimTemplates"), "spec.volumeClaimTemplates"); len(paths) > 0 {
+				add(o, ImpactPVC, "volume claim templates changed: %s; they cannot be updated, so the StatefulSet must be recreated", summarizePaths(paths))
+			}
+		}
+	}
+
+	for key, o := range before {
+		if _, ok := after[key]; ok {
+			continue
+		}
+		switch {
+		case o.kind == "CustomResourceDefinition" && o.group == "apiextensions.k8s.io":
+			add(o, ImpactCRD, "removed, with all of its custom resources")
+		case o.kind == "PersistentVolumeClaim" && o.group == "":
+			add(o, ImpactPVC, "removed; its volume is deleted if its reclaim policy is Delete")
+		case o.kind == "Service" && o.group == "":
+			add(o, ImpactServiceIP, "removed; its IPs are released")
+		}
+	}
+
+	sort.Slice(impacts, func(i, j int) bool {
+		a, b := impacts[i], impacts[j]
+		if a.Category != b.Category {
+			return impactCategoryOrder[a.Category] < impactCategoryOrder[b.Category]
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Reason < b.Reason
+	})
+	return impacts
+}
+
+// impactObjects decodes the resources of a manifest, keyed by group, kind,
+// namespace and name. Documents that are not resources are skipped.
+func impactObjects(manifest, namespace string) map[string]impactObject {
+	objects := make(map[string]impactObject)
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || obj == nil {
+			continue
+		}
+		apiVersion, _ := obj["apiVersion"].(string)
+		kind, _ := obj["kind"].(string)
+		name, _ := nestedValue(obj, "metadata", "name").(string)
+		if kind == "" || name == "" {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			continue
+		}
+		ns, _ := nestedValue(obj, "metadata", "namespace").(string)
+		if ns == "" && !clusterScopedKinds[kind] {
+			ns = namespace
+		}
+		o := impactObject{group: gv.Group, kind: kind, namespace: ns, name: name, obj: obj}
+		objects[fmt.Sprintf("%s/%s/%s/%s", o.group, o.kind, o.namespace, o.name)] = o
+	}
+	return objects
+}
+
+// clusterScopedKinds are the kinds the impact analysis looks at that have no
+// namespace.
+var clusterScopedKinds = map[string]bool{
+	"CustomResourceDefinition": true,
+	"ClusterRole":              true,
+	"ClusterRoleBinding":       true,
+}
+
+// isWorkload reports whether a resource manages pods from a pod template.
+func isWorkload(o impactObject) bool {
+	switch o.group {
+	case "apps":
+		return o.kind == "Deployment" || o.kind == "StatefulSet" || o.kind == "DaemonSet" || o.kind == "ReplicaSet"
+	case "":
+		return o.kind == "ReplicationController"
+	}
+	return false
+}
+
+// nestedValue returns the value at the path of fields in obj, or nil.
+func nestedValue(obj map[string]interface{}, fields ...string) interface{} {
+	var v interface{} = obj
+	for _, f := range fields {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[f]
+	}
+	return v
+}
+
+// changedPaths returns the paths of the fields that differ between a and b,
+// sorted. Lists that differ are reported as a whole, except lists of named
+// items such as containers, which are compared item by item.
+func changedPaths(a, b interface{}, prefix string) []string {
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if aok && bok {
+		var paths []string
+		for k := range am {
+			paths = append(paths, changedPaths(am[k], bm[k], prefix+"."+k)...)
+		}
+		for k := range bm {
+			if _, ok := am[k]; !ok {
+				paths = append(paths, prefix+"."+k)
+			}
+		}
+		sort.Strings(paths)
+		return paths
+	}
+	al, aok := namedItems(a)
+	bl, bok := namedItems(b)
+	if aok && bok {
+		var paths []string
+		for name, item := range al {
+			paths = append(paths, changedPaths(item, bl[name], fmt.Sprintf("%s[%s]", prefix, name))...)
+		}
+		for name := range bl {
+			if _, ok := al[name]; !ok {
+				paths = append(paths, fmt.Sprintf("%s[%s]", prefix, name))
+			}
+		}
+		sort.Strings(paths)
+		return paths
+	}
+	return []string{prefix}
+}
+
+// namedItems returns the items of a list of tables with unique names, keyed by
+// name.
+func namedItems(v interface{}) (map[string]interface{}, bool) {
+	l, ok := v.([]interface{})
+	if !ok || len(l) == 0 {
+		return nil, false
+	}
+	items := make(map[string]interface{}, len(l))
+	for _, item := range l {
+		m, _ := item.(map[string]interface{})
+		name, _ := m["name"].(string)
+		if _, dup := items[name]; name == "" || dup {
+			return nil, false
+		}
+		items[name] = item
+	}
+	return items, true
+}
+
+// summarizePaths lists the first few paths, and how many more there are.
+func summarizePaths(paths []string) string {
+	const limit = 3
+	if len(paths) <= limit {
+		return strings.Join(paths, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(paths[:limit], ", "), len(paths)-limit)
+}
+
+// serviceIPChange describes how an update of a Service changes its IPs, or
+// returns "".
+func serviceIPChange(old, cur map[string]interface{}) string {
+	oldType, _ := nestedValue(old, "spec", "type").(string)
+	curType, _ := nestedValue(cur, "spec", "type").(string)
+	if oldType == "" {
+		oldType = "ClusterIP"
+	}
+	if curType == "" {
+		curType = "ClusterIP"
+	}
+	if oldType != curType {
+		switch {
+		case oldType == "LoadBalancer":
+			return fmt.Sprintf("type changes from %s to %s; its load balancer IP is released", oldType, curType)
+		case oldType == "ExternalName" || curType == "ExternalName":
+			return fmt.Sprintf("type changes from %s to %s; its cluster IP changes", oldType, curType)
+		case curType == "LoadBalancer":
+			return fmt.Sprintf("type changes from %s to %s; a load balancer IP is assigned", oldType, curType)
+		}
+	}
+	for _, field := range []string{"clusterIP", "loadBalancerIP"} {
+		oldIP, _ := nestedValue(old, "spec", field).(string)
+		curIP, _ := nestedValue(cur, "spec", field).(string)
+		if oldIP != curIP && !(field == "clusterIP" && curIP == "") {
+			return fmt.Sprintf("spec.%s changes from %q to %q", field, oldIP, curIP)
+		}
+	}
+	return ""
+}
+
+// newRules describes the rules of a Role or ClusterRole that were not granted
+// before.
+func newRules(old, cur map[string]interface{}) []string {
+	oldRules, _ := nestedValue(old, "rules").([]interface{})
+	curRules, _ := nestedValue(cur, "rules").([]interface{})
+	var added []string
+	for _, r := range curRules {
+		if containsValue(oldRules, r) {
+			continue
+		}
+		rule, _ := r.(map[string]interface{})
+		added = append(added, describeRule(rule))
+	}
+	return added
+}
+
+// describeRule formats a policy rule as its verbs and resources.
+func describeRule(rule map[string]interface{}) string {
+	list := func(field string) string {
+		l, _ := rule[field].([]interface{})
+		s := make([]string, 0, len(l))
+		for _, v := range l {
+			s = append(s, fmt.Sprint(v))
+		}
+		return strings.Join(s, ",")
+	}
+	if urls := list("nonResourceURLs"); urls != "" {
+		return fmt.Sprintf("%s on %s", list("verbs"), urls)
+	}
+	resources := list("resources")
+	if groups := list("apiGroups"); groups != "" {
+		resources = fmt.Sprintf("%s in API groups %q", resources, groups)
+	}
+	return fmt.Sprintf("%s on %s", list("verbs"), resources)
+}
+
+// bindingExpansion describes how a RoleBinding or ClusterRoleBinding grants
+// permissions to more subjects, or returns "".
+func bindingExpansion(old, cur map[string]interface{}, existed bool) string {
+	roleKind, _ := nestedValue(cur, "roleRef", "kind").(string)
+	roleName, _ := nestedValue(cur, "roleRef", "name").(string)
+	role := roleKind + "/" + roleName
+	if !existed {
+		return fmt.Sprintf("binds %s to %s", role, describeSubjects(nestedValue(cur, "subjects")))
+	}
+	if !reflect.DeepEqual(nestedValue(old, "roleRef"), nestedValue(cur, "roleRef")) {
+		oldKind, _ := nestedValue(old, "roleRef", "kind").(string)
+		oldName, _ := nestedValue(old, "roleRef", "name").(string)
+		return fmt.Sprintf("binds %s instead of %s/%s", role, oldKind, oldName)
+	}
+	oldSubjects, _ := nestedValue(old, "subjects").([]interface{})
+	curSubjects, _ := nestedValue(cur, "subjects").([]interface{})
+	var added []interface{}
+	for _, s := range curSubjects {
+		if !containsValue(oldSubjects, s) {
+			added = append(added, s)
+		}
+	}
+	if len(added) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("binds %s to %s", role, describeSubjects(added))
+}
+
+func describeSubjects(v interface{}) string {
+	l, _ := v.([]interface{})
+	s := make([]string, 0, len(l))
+	for _, subject := range l {
+		m, _ := subject.(map[string]interface{})
+		kind, _ := m["kind"].(string)
+		name, _ := m["name"].(string)
+		s = append(s, kind+"/"+name)
+	}
+	if len(s) == 0 {
+		return "no subjects"
+	}
+	return strings.Join(s, ", ")
+}
+
+func containsValue(l []interface{}, v interface{}) bool {
+	for _, item := range l {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
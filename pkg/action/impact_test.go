@@ -0,0 +1,224 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const impactCurrentManifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:1.0
+      - name: sidecar
+        image: proxy:1.0
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: db
+        image: db:1.0
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      resources:
+        requests:
+          storage: 1Gi
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: cache
+spec:
+  resources:
+    requests:
+      storage: 1Gi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: LoadBalancer
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: legacy
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: reader
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: reader
+roleRef:
+  kind: Role
+  name: reader
+subjects:
+- kind: ServiceAccount
+  name: web
+`
+
+const impactTargetManifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: web:2.0
+      - name: sidecar
+        image: proxy:1.0
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: db
+        image: db:1.0
+  volumeClaimTemplates:
+  - metadata:
+      name: data
+    spec:
+      resources:
+        requests:
+          storage: 2Gi
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: cache
+spec:
+  resources:
+    requests:
+      storage: 2Gi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: ClusterIP
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: gadgets.example.com
+spec:
+  group: example.com
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: reader
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["get"]
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["get", "list"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: reader
+roleRef:
+  kind: Role
+  name: reader
+subjects:
+- kind: ServiceAccount
+  name: web
+- kind: Group
+  name: developers
+`
+
+func TestAnalyzeImpact(t *testing.T) {
+	impacts := AnalyzeImpact(impactCurrentManifest, impactTargetManifest, "default")
+
+	assert.Equal(t, []Impact{
+		{ImpactCRD, "CustomResourceDefinition", "", "gadgets.example.com", "created"},
+		{ImpactCRD, "CustomResourceDefinition", "", "widgets.example.com", "removed, with all of its custom resources"},
+		{ImpactPVC, "PersistentVolumeClaim", "default", "cache", "changed: spec.resources.requests.storage; only the storage request of a bound claim can be changed, and only increased"},
+		{ImpactPVC, "StatefulSet", "default", "db", "volume claim templates changed: spec.volumeClaimTemplates; they cannot be updated, so the StatefulSet must be recreated"},
+		{ImpactRBACExpansion, "Role", "default", "reader", `grants get,list on secrets`},
+		{ImpactRBACExpansion, "RoleBinding", "default", "reader", "binds Role/reader to Group/developers"},
+		{ImpactServiceIP, "Service", "default", "legacy", "removed; its IPs are released"},
+		{ImpactServiceIP, "Service", "default", "web", "type changes from LoadBalancer to ClusterIP; its load balancer IP is released"},
+		{ImpactPodRestart, "Deployment", "default", "web", "pod template changed: spec.template.spec.containers[web].image"},
+	}, impacts)
+
+	assert.Empty(t, AnalyzeImpact(impactCurrentManifest, impactCurrentManifest, "default"))
+}
+
+func TestAnalyzeImpactNewRBAC(t *testing.T) {
+	target := `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: admin
+rules:
+- apiGroups: ["apps"]
+  resources: ["deployments"]
+  verbs: ["*"]
+- nonResourceURLs: ["/metrics"]
+  verbs: ["get"]
+`
+	assert.Equal(t, []Impact{
+		{ImpactRBACExpansion, "ClusterRole", "", "admin", `grants * on deployments in API groups "apps"; get on /metrics`},
+	}, AnalyzeImpact("", target, "default"))
+}
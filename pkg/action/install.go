@@ -45,6 +45,7 @@ import (
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/common/util"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/downloader"
@@ -52,6 +53,7 @@ import (
 	"helm.sh/helm/v4/pkg/kube"
 	kubefake "helm.sh/helm/v4/pkg/kube/fake"
 	"helm.sh/helm/v4/pkg/postrenderer"
+	"helm.sh/helm/v4/pkg/provenance"
 	"helm.sh/helm/v4/pkg/registry"
 	release "helm.sh/helm/v4/pkg/release/v1"
 	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
@@ -122,12 +124,40 @@ type Install struct {
 	IsUpgrade bool
 	// Enable DNS lookups when rendering templates
 	EnableDNS bool
+	// Fail rendering on references to undefined values
+	StrictValues bool
+	// ValuesProfiles names the values profiles of the chart, in its values/
+	// directory, layered under the supplied values in order
+	ValuesProfiles []string
+	// GlobValues are strvals lines whose keys may hold wildcards, expanded
+	// against the values of the chart and set over the supplied values
+	GlobValues []string
+	// ValuesPatches are RFC 6902 JSON Patch documents applied in order to the
+	// values coalesced with those of the chart. They are not stored with the
+	// release.
+	ValuesPatches [][]byte
+	// ValuesSources maps the paths of the supplied values to the values file
+	// or flag that set them. It is stored with the release, for
+	// 'helm get values --explain'.
+	ValuesSources map[string]string
+	// SkipPrerequisites disables checking the prerequisites declared in the
+	// requires section of Chart.yaml against the cluster.
+	SkipPrerequisites bool
 	// Used by helm template to add the release as part of OutputDir path
 	// OutputDir/<ReleaseName>
 	UseReleaseName bool
 	// TakeOwnership will ignore the check for helm annotations and take ownership of the resources.
 	TakeOwnership bool
 	PostRenderer  postrenderer.PostRenderer
+	// Transformers mutate or filter the rendered resources in order, after
+	// the post-renderer. The CRDs of the crds/ directory are not transformed.
+	Transformers []ManifestTransformer
+	// ExportBundle, if set, is the directory or OCI repository the rollback
+	// bundle of the installed release is exported to. See ExportBundle.
+	ExportBundle string
+	// WaitProgress, if set, is called with the rollout progress of the
+	// Deployments and StatefulSets while waiting for them.
+	WaitProgress kube.RolloutProgressFunc
 	// Lock to control raceconditions when the process receives a SIGTERM
 	Lock           sync.Mutex
 	goroutineCount atomic.Int32
@@ -148,9 +178,20 @@ type ChartPathOptions struct {
 	Verify                bool   // --verify
 	Version               string // --version
 
+	// Filter selects the files of the chart loaded by LoadChart.
+	Filter loader.Filter
+	// GitRef, if set, is the git revision of the chart directory loaded by
+	// LoadChart, instead of the working tree.
+	GitRef string
+
 	// registryClient provides a registry client but is not added with
 	// options from a flag
 	registryClient *registry.Client
+
+	// chartSource and chartArchiveDigest are the reference and archive digest
+	// of the last chart LocateChart pulled from a repository or registry.
+	chartSource        string
+	chartArchiveDigest string
 }
 
 // NewInstall creates a new Install object with the given configuration.
@@ -187,7 +228,8 @@ func (i *Install) installCRDs(crds []chart.CRD) error {
 		// Send them to Kube
 		if _, err := i.cfg.KubeClient.Create(
 			res,
-			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts)); err != nil {
+			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts),
+			kube.ClientCreateOptionFieldManager(i.cfg.FieldManager)); err != nil {
 			// If the error is CRD already exists, continue.
 			if apierrors.IsAlreadyExists(err) {
 				crdName := res[0].Name
@@ -253,6 +295,19 @@ func (i *Install) Run(chrt *chart.Chart, vals map[string]interface{}) (*release.
 // When the task is cancelled through ctx, the function returns and the install
 // proceeds in the background.
 func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
+	record := OperationRecord{
+		Operation: OperationInstall,
+		Release:   i.ReleaseName,
+		Namespace: i.Namespace,
+		DryRun:    i.isDryRun(),
+		Start:     time.Now(),
+	}
+	rel, err := i.run(ctx, chrt, vals)
+	i.cfg.recordOperation(record, chrt, rel, err)
+	return rel, err
+}
+
+func (i *Install) run(ctx context.Context, chrt *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
 	// Check reachability of cluster unless in client-only mode (e.g. `helm template` without `--validate`)
 	if !i.ClientOnly {
 		if err := i.cfg.KubeClient.IsReachable(); err != nil {
@@ -272,6 +327,21 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		return nil, fmt.Errorf("release name check failed: %w", err)
 	}
 
+	// The chart tree is digested before its dependencies are processed, as
+	// an upgrade compares it with the chart before processing.
+	treeDigest, err := chartTreeDigest(chrt)
+	if err != nil {
+		return nil, err
+	}
+
+	vals, err = i.applyValuesProfiles(chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	if vals, err = chartutil.ApplyGlobValues(chrt, vals, i.GlobValues); err != nil {
+		return nil, err
+	}
+
 	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
 		slog.Error("chart dependencies processing failed", slog.Any("error", err))
 		return nil, fmt.Errorf("chart dependencies processing failed: %w", err)
@@ -282,6 +352,20 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		interactWithRemote = true
 	}
 
+	// Check the prerequisites of the chart before any of its resources,
+	// including its CRDs, are applied.
+	if !i.ClientOnly && !i.SkipPrerequisites && interactWithRemote {
+		gathered := i.cfg.Capabilities != nil
+		if err := i.cfg.checkPrerequisites(ctx, chrt); err != nil {
+			return nil, err
+		}
+		if !gathered {
+			// Gather the capabilities again below, so that they include the
+			// CRDs installed from the chart.
+			i.cfg.Capabilities = nil
+		}
+	}
+
 	// Pre-install anything in the crd/ directory. We do this before Helm
 	// contacts the upstream server and builds the capabilities object.
 	if crds := chrt.CRDObjects(); !i.ClientOnly && !i.SkipCRDs && len(crds) > 0 {
@@ -330,7 +414,7 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		IsInstall: !isUpgrade,
 		IsUpgrade: isUpgrade,
 	}
-	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chrt, vals, options, caps, i.SkipSchemaValidation)
+	valuesToRender, err := toRenderValues(chrt, vals, options, caps, i.SkipSchemaValidation, i.ValuesPatches, i.ValuesSources)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +426,19 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 	rel := i.createRelease(chrt, vals, i.Labels)
 
 	var manifestDoc *bytes.Buffer
-	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, i.ReleaseName, i.OutputDir, i.SubNotes, i.UseReleaseName, i.IncludeCRDs, i.PostRenderer, interactWithRemote, i.EnableDNS, i.HideSecret)
+	rel.Hooks, manifestDoc, rel.Info.Notes, err = i.cfg.renderResources(chrt, valuesToRender, renderOptions{
+		releaseName:        i.ReleaseName,
+		outputDir:          i.OutputDir,
+		subNotes:           i.SubNotes,
+		useReleaseName:     i.UseReleaseName,
+		includeCrds:        i.IncludeCRDs,
+		postRenderer:       i.PostRenderer,
+		transformers:       i.Transformers,
+		interactWithRemote: interactWithRemote,
+		enableDNS:          i.EnableDNS,
+		strictValues:       i.StrictValues,
+		hideSecret:         i.HideSecret,
+	})
 	// Even for errors, attach this if available
 	if manifestDoc != nil {
 		rel.Manifest = manifestDoc.String()
@@ -415,7 +511,8 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		}
 		if _, err := i.cfg.KubeClient.Create(
 			resourceList,
-			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, false)); err != nil && !apierrors.IsAlreadyExists(err) {
+			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, false),
+			kube.ClientCreateOptionFieldManager(i.cfg.FieldManager)); err != nil && !apierrors.IsAlreadyExists(err) {
 			return nil, err
 		}
 	}
@@ -427,6 +524,10 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 		}
 	}
 
+	if err := recordIntegrity(rel, i.chartSource, i.chartArchiveDigest, treeDigest); err != nil {
+		return rel, err
+	}
+
 	// Store the release in history before continuing. We always know that this is a create operation
 	if err := i.cfg.Releases.Create(rel); err != nil {
 		// We could try to recover gracefully here, but since nothing has been installed
@@ -437,9 +538,14 @@ func (i *Install) RunWithContext(ctx context.Context, chrt *chart.Chart, vals ma
 
 	rel, err = i.performInstallCtx(ctx, rel, toBeAdopted, resources)
 	if err != nil {
-		rel, err = i.failRelease(rel, err)
+		return i.failRelease(rel, err)
 	}
-	return rel, err
+	if i.ExportBundle != "" {
+		if _, err := ExportBundle(i.cfg, rel, i.ExportBundle); err != nil {
+			return rel, fmt.Errorf("release %s was installed, but exporting its rollback bundle failed: %w", rel.Name, err)
+		}
+	}
+	return rel, nil
 }
 
 func (i *Install) performInstallCtx(ctx context.Context, rel *release.Release, toBeAdopted kube.ResourceList, resources kube.ResourceList) (*release.Release, error) {
@@ -492,7 +598,8 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 	if len(toBeAdopted) == 0 && len(resources) > 0 {
 		_, err = i.cfg.KubeClient.Create(
 			resources,
-			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, false))
+			kube.ClientCreateOptionServerSideApply(i.ServerSideApply, false),
+			kube.ClientCreateOptionFieldManager(i.cfg.FieldManager))
 	} else if len(resources) > 0 {
 		updateThreeWayMergeForUnstructured := i.TakeOwnership && !i.ServerSideApply // Use three-way merge when taking ownership (and not using server-side apply)
 		_, err = i.cfg.KubeClient.Update(
@@ -501,13 +608,14 @@ func (i *Install) performInstall(rel *release.Release, toBeAdopted kube.Resource
 			kube.ClientUpdateOptionForceReplace(i.ForceReplace),
 			kube.ClientUpdateOptionServerSideApply(i.ServerSideApply, i.ForceConflicts),
 			kube.ClientUpdateOptionThreeWayMergeForUnstructured(updateThreeWayMergeForUnstructured),
-			kube.ClientUpdateOptionUpgradeClientSideFieldManager(true))
+			kube.ClientUpdateOptionUpgradeClientSideFieldManager(true),
+			kube.ClientUpdateOptionFieldManager(i.cfg.FieldManager))
 	}
 	if err != nil {
 		return rel, err
 	}
 
-	waiter, err := i.cfg.KubeClient.GetWaiter(i.WaitStrategy)
+	waiter, err := i.cfg.getWaiter(i.WaitStrategy, i.WaitProgress)
 	if err != nil {
 		return rel, fmt.Errorf("failed to get waiter: %w", err)
 	}
@@ -614,6 +722,9 @@ func (i *Install) createRelease(chrt *chart.Chart, rawVals map[string]interface{
 		Labels:      labels,
 		ApplyMethod: string(determineReleaseSSApplyMethod(i.ServerSideApply)),
 	}
+	if i.ValuesSources != nil {
+		r.ConfigSources = chartutil.MergeValuesSources(rawVals, i.ValuesSources)
+	}
 
 	return r
 }
@@ -799,6 +910,19 @@ func urlEqual(u1, u2 *url.URL) bool {
 	return u1.Scheme == u2.Scheme && u1.Hostname() == u2.Hostname() && portOrDefault(u1) == portOrDefault(u2)
 }
 
+// LoadChart loads the chart at the path returned by LocateChart, leaving out
+// the files not selected by Filter. If GitRef is set, the chart directory is
+// loaded as of that git revision.
+func (c *ChartPathOptions) LoadChart(path string) (*chart.Chart, error) {
+	if c.GitRef != "" {
+		if !c.Filter.IsZero() {
+			return nil, errors.New("file filters cannot be used with a git revision")
+		}
+		return loader.LoadGitRef(path, c.GitRef)
+	}
+	return loader.LoadFiltered(path, c.Filter)
+}
+
 // LocateChart looks for a chart directory in known places, and returns either the full path or an error.
 //
 // This does not ensure that the chart is well-formed; only that the requested filename exists.
@@ -816,6 +940,12 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 
 	name = strings.TrimSpace(name)
 	version := strings.TrimSpace(c.Version)
+	c.chartSource, c.chartArchiveDigest = "", ""
+
+	// The chart directory of a git revision may not exist in the working tree.
+	if c.GitRef != "" {
+		return filepath.Abs(name)
+	}
 
 	if _, err := os.Stat(name); err == nil {
 		abs, err := filepath.Abs(name)
@@ -833,30 +963,7 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 		return name, fmt.Errorf("path %q not found", name)
 	}
 
-	dl := downloader.ChartDownloader{
-		Out:     os.Stdout,
-		Keyring: c.Keyring,
-		Getters: getter.All(settings),
-		Options: []getter.Option{
-			getter.WithPassCredentialsAll(c.PassCredentialsAll),
-			getter.WithTLSClientConfig(c.CertFile, c.KeyFile, c.CaFile),
-			getter.WithInsecureSkipVerifyTLS(c.InsecureSkipTLSverify),
-			getter.WithPlainHTTP(c.PlainHTTP),
-			getter.WithBasicAuth(c.Username, c.Password),
-		},
-		RepositoryConfig: settings.RepositoryConfig,
-		RepositoryCache:  settings.RepositoryCache,
-		ContentCache:     settings.ContentCache,
-		RegistryClient:   c.registryClient,
-	}
-
-	if registry.IsOCI(name) {
-		dl.Options = append(dl.Options, getter.WithRegistryClient(c.registryClient))
-	}
-
-	if c.Verify {
-		dl.Verify = downloader.VerifyAlways
-	}
+	dl := c.chartDownloader(name, settings)
 	if c.RepoURL != "" {
 		chartURL, err := repo.FindChartInRepoURL(
 			c.RepoURL,
@@ -909,5 +1016,79 @@ func (c *ChartPathOptions) LocateChart(name string, settings *cli.EnvSettings) (
 	if err != nil {
 		return filename, err
 	}
+	digest, err := provenance.DigestFile(lname)
+	if err != nil {
+		return lname, err
+	}
+	c.chartSource = name
+	c.chartArchiveDigest = "sha256:" + digest
 	return lname, nil
 }
+
+// chartDownloader returns a downloader for the chart with the given reference,
+// set up from the options.
+func (c *ChartPathOptions) chartDownloader(name string, settings *cli.EnvSettings) downloader.ChartDownloader {
+	dl := downloader.ChartDownloader{
+		Out:     os.Stdout,
+		Keyring: c.Keyring,
+		Getters: getter.All(settings),
+		Options: []getter.Option{
+			getter.WithPassCredentialsAll(c.PassCredentialsAll),
+			getter.WithTLSClientConfig(c.CertFile, c.KeyFile, c.CaFile),
+			getter.WithInsecureSkipVerifyTLS(c.InsecureSkipTLSverify),
+			getter.WithPlainHTTP(c.PlainHTTP),
+			getter.WithBasicAuth(c.Username, c.Password),
+		},
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+		ContentCache:     settings.ContentCache,
+		RegistryClient:   c.registryClient,
+	}
+
+	if registry.IsOCI(name) {
+		dl.Options = append(dl.Options, getter.WithRegistryClient(c.registryClient))
+	}
+
+	if c.Verify {
+		dl.Verify = downloader.VerifyAlways
+	}
+	return dl
+}
+
+// applyValuesProfiles layers the selected values profiles of the chart under
+// vals, and validates the result against the schemas of the profiles.
+func (i *Install) applyValuesProfiles(chrt *chart.Chart, vals map[string]interface{}) (map[string]interface{}, error) {
+	vals, err := chartutil.ApplyValuesProfiles(chrt, vals, i.ValuesProfiles)
+	if err != nil {
+		return nil, err
+	}
+	if !i.SkipSchemaValidation {
+		if err := chartutil.ValidateValuesProfiles(chrt, vals, i.ValuesProfiles); err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+// toRenderValues composes the values to render the templates of a chart with,
+// as util.ToRenderValuesWithSchemaValidation does, applying the values patches
+// to the coalesced values before validating them against the schemas. Schema
+// violations name the values file or flag that set the offending value, as
+// recorded in sources.
+func toRenderValues(chrt *chart.Chart, vals map[string]interface{}, options common.ReleaseOptions, caps *common.Capabilities, skipSchemaValidation bool, patches [][]byte, sources map[string]string) (common.Values, error) {
+	top, err := util.ToRenderValuesWithSchemaValidation(chrt, vals, options, caps, true)
+	if err != nil {
+		return top, err
+	}
+	values, err := chartutil.ApplyValuesPatches(top["Values"].(common.Values), patches)
+	if err != nil {
+		return top, err
+	}
+	if !skipSchemaValidation {
+		if err := util.ValidateAgainstSchemaWithSources(chrt, values, sources); err != nil {
+			return top, fmt.Errorf("values don't meet the specifications of the schema(s) in the following chart(s):\n%w", err)
+		}
+	}
+	top["Values"] = common.Values(values)
+	return top, nil
+}
@@ -994,3 +994,31 @@ func TestUrlEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestInstallRelease_ValuesSources(t *testing.T) {
+	req := require.New(t)
+
+	instAction := installAction(t)
+	instAction.ValuesSources = map[string]string{"name": "--set name=value", "stale": "--set stale=1"}
+	res, err := instAction.Run(buildChart(), map[string]interface{}{"name": "value", "other": 1})
+	req.NoError(err)
+
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	req.NoError(err)
+	assert.Equal(t, map[string]string{"name": "--set name=value"}, rel.ConfigSources)
+}
+
+func TestInstallRelease_RenderContext(t *testing.T) {
+	req := require.New(t)
+
+	instAction := installAction(t)
+	instAction.cfg.RenderContext = map[string]interface{}{
+		"Platform": map[string]interface{}{"tenant": "acme"},
+	}
+	chrt := buildChartWithTemplates([]*common.File{
+		{Name: "templates/tenant", Data: []byte("tenant: {{ .Platform.tenant }}")},
+	})
+	res, err := instAction.Run(chrt, map[string]interface{}{})
+	req.NoError(err)
+	req.Contains(res.Manifest, "tenant: acme")
+}
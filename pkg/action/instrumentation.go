@@ -0,0 +1,115 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"time"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// Operation is the kind of operation an OperationRecord describes.
+type Operation string
+
+// The operations recorded for the Instrumentation of a Configuration.
+const (
+	OperationInstall   Operation = "install"
+	OperationUpgrade   Operation = "upgrade"
+	OperationRollback  Operation = "rollback"
+	OperationUninstall Operation = "uninstall"
+	OperationTest      Operation = "test"
+)
+
+// Outcome is how an operation ended.
+type Outcome string
+
+// The outcomes of operations.
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	OutcomeFailed    Outcome = "failed"
+	// OutcomeUnchanged is the outcome of upgrades skipped as they would not
+	// change the release.
+	OutcomeUnchanged Outcome = "unchanged"
+)
+
+// OperationRecord is a normalized record of an operation performed on a
+// release. It identifies the release and chart but never holds the content of
+// the chart, the values or the manifests.
+type OperationRecord struct {
+	Operation Operation
+	// Release and Namespace are the name and namespace of the release
+	Release   string
+	Namespace string
+	// Revision is the revision of the release created or acted upon, or zero
+	// when the operation failed before reaching one
+	Revision int
+	// Chart and ChartVersion are the name and version of the chart
+	Chart        string
+	ChartVersion string
+	// DryRun is set when the operation did not change the cluster
+	DryRun   bool
+	Start    time.Time
+	Duration time.Duration
+	Outcome  Outcome
+	// Err is the error the operation failed with
+	Err error
+}
+
+// Instrumentation receives a record of each operation the actions perform, so
+// that platforms embedding Helm can feed their own analytics. It is called
+// synchronously once an operation ends, and should return quickly.
+type Instrumentation interface {
+	RecordOperation(OperationRecord)
+}
+
+// InstrumentationFunc is a function used as an Instrumentation.
+type InstrumentationFunc func(OperationRecord)
+
+// RecordOperation calls f(r).
+func (f InstrumentationFunc) RecordOperation(r OperationRecord) {
+	f(r)
+}
+
+// recordOperation completes a record with the release and the error an
+// operation ended with, and passes it to the instrumentation, if any.
+func (cfg *Configuration) recordOperation(r OperationRecord, chrt *chart.Chart, rel *release.Release, err error) {
+	if cfg.Instrumentation == nil {
+		return
+	}
+	r.Duration = time.Since(r.Start)
+	if rel != nil {
+		r.Release, r.Namespace, r.Revision = rel.Name, rel.Namespace, rel.Version
+		if rel.Chart != nil {
+			chrt = rel.Chart
+		}
+	}
+	if chrt != nil && chrt.Metadata != nil {
+		r.Chart, r.ChartVersion = chrt.Metadata.Name, chrt.Metadata.Version
+	}
+	switch {
+	case err == nil:
+		r.Outcome = OutcomeSucceeded
+	case errors.Is(err, ErrNoChange):
+		r.Outcome = OutcomeUnchanged
+	default:
+		r.Outcome = OutcomeFailed
+	}
+	r.Err = err
+	cfg.Instrumentation.RecordOperation(r)
+}
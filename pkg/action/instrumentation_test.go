@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+)
+
+func TestInstrumentation(t *testing.T) {
+	var records []OperationRecord
+	cfg := actionConfigFixture(t)
+	cfg.Instrumentation = InstrumentationFunc(func(r OperationRecord) {
+		records = append(records, r)
+	})
+
+	instAction := installActionWithConfig(cfg)
+	_, err := instAction.Run(buildChart(), nil)
+	require.NoError(t, err)
+
+	upAction := NewUpgrade(cfg)
+	upAction.Namespace = "spaced"
+	_, err = upAction.Run(instAction.ReleaseName, buildChart(withName("hello")), nil)
+	require.NoError(t, err)
+
+	rbAction := NewRollback(cfg)
+	rbAction.ServerSideApply = "auto"
+	require.NoError(t, rbAction.Run(instAction.ReleaseName))
+
+	_, err = NewUninstall(cfg).Run(instAction.ReleaseName)
+	require.NoError(t, err)
+
+	require.Len(t, records, 4)
+	for i, op := range []Operation{OperationInstall, OperationUpgrade, OperationRollback, OperationUninstall} {
+		r := records[i]
+		assert.Equal(t, op, r.Operation)
+		assert.Equal(t, OutcomeSucceeded, r.Outcome, op)
+		assert.NoError(t, r.Err)
+		assert.Equal(t, "test-install-release", r.Release)
+		assert.Equal(t, "spaced", r.Namespace)
+		assert.Equal(t, "hello", r.Chart)
+		assert.Equal(t, "0.1.0", r.ChartVersion)
+		assert.False(t, r.Start.IsZero())
+	}
+	assert.Equal(t, []int{1, 2, 3, 3}, []int{records[0].Revision, records[1].Revision, records[2].Revision, records[3].Revision})
+}
+
+func TestInstrumentationFailure(t *testing.T) {
+	var records []OperationRecord
+	cfg := actionConfigFixture(t)
+	cfg.Instrumentation = InstrumentationFunc(func(r OperationRecord) {
+		records = append(records, r)
+	})
+	failer := cfg.KubeClient.(*kubefake.FailingKubeClient)
+	failer.CreateError = errors.New("create failed")
+
+	instAction := installActionWithConfig(cfg)
+	instAction.ReleaseName = "failed"
+	_, err := instAction.Run(buildChart(), nil)
+	require.Error(t, err)
+
+	_, err = NewReleaseTesting(cfg).Run("missing")
+	require.Error(t, err)
+
+	require.Len(t, records, 2)
+	assert.Equal(t, OperationInstall, records[0].Operation)
+	assert.Equal(t, OutcomeFailed, records[0].Outcome)
+	assert.ErrorContains(t, records[0].Err, "create failed")
+	assert.Equal(t, "failed", records[0].Release)
+	assert.Equal(t, 1, records[0].Revision)
+
+	// Failures before a release is found keep the names from the request
+	assert.Equal(t, OperationTest, records[1].Operation)
+	assert.Equal(t, OutcomeFailed, records[1].Outcome)
+	assert.Equal(t, "missing", records[1].Release)
+	assert.Zero(t, records[1].Revision)
+	assert.Empty(t, records[1].Chart)
+}
@@ -17,15 +17,20 @@ limitations under the License.
 package action
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/v2/lint"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 // Lint is the action for checking that the semantics of a chart are well-formed.
@@ -38,13 +43,119 @@ type Lint struct {
 	Quiet                bool
 	SkipSchemaValidation bool
 	KubeVersion          *common.KubeVersion
+	// RequiredLabels enables a rule checking that every rendered resource
+	// carries these labels.
+	RequiredLabels []string
+	// RequiredAnnotations enables a rule checking that every rendered resource
+	// carries these annotations.
+	RequiredAnnotations []string
+	// AllowedMaintainers enables a rule checking that every maintainer in
+	// Chart.yaml has an email in this list of emails and domains.
+	AllowedMaintainers []string
+	// MinMaintainers enables a rule checking that Chart.yaml lists at least
+	// this many maintainers.
+	MinMaintainers int
+	// RequirePinnedImages enables a rule checking that every container image
+	// has a digest or a tag other than "latest".
+	RequirePinnedImages bool
+	// RequireImageDigests enables a rule checking that every container image
+	// has a digest.
+	RequireImageDigests bool
+	// AllowedRegistries enables a rule checking that every container image is
+	// from one of these registries or registry paths.
+	AllowedRegistries []string
+	// VerifyImages enables a rule checking that every container image exists,
+	// by looking it up in its registry.
+	VerifyImages bool
+	// CheckEmptyOutput enables a rule warning about templates rendering no
+	// content, and failing when the chart renders no resources.
+	CheckEmptyOutput bool
+	// CheckSelectors enables a rule warning about workload selectors using
+	// labels that change between releases, and Services selecting none of
+	// the pods rendered by the chart.
+	CheckSelectors bool
+	// CheckIngress enables a rule checking the backends, TLS secrets and
+	// classes of the Ingress and Gateway API resources of the chart.
+	CheckIngress bool
+	// CheckAvailability enables a rule warning about PodDisruptionBudgets
+	// allowing no disruption and HorizontalPodAutoscalers conflicting with
+	// the replica count of the workload they scale.
+	CheckAvailability bool
+	// Cluster enables a rule checking the prerequisites in the requires
+	// section of Chart.yaml against this description of the target cluster.
+	Cluster *chartutil.Cluster
+	// RenderContext holds additional top-level objects passed to the
+	// templates of the chart, as with the RenderContext of Configuration.
+	RenderContext map[string]interface{}
+	// CustomRules are run after the built-in rules, such as the rules of
+	// lint rule plugins created with lint.NewRulePlugin.
+	CustomRules []rules.CustomRule
+	// Linter, if set, runs the lint rules instead of lint.RunAll and caches
+	// work between runs against the same chart.
+	Linter *lint.Linter
+
+	registryClient *registry.Client
 }
 
 // LintResult is the result of Lint
 type LintResult struct {
 	TotalChartsLinted int
 	Messages          []support.Message
-	Errors            []error
+	// MessageRules has the name of the rule that reported each of Messages,
+	// or an empty string.
+	MessageRules []string
+	Errors       []error
+	// Summary has statistics about the run, for tracking lint health over
+	// time.
+	Summary LintSummary
+}
+
+// LintSummary is a machine-readable summary of a lint run.
+type LintSummary struct {
+	// ChartsScanned is the number of charts that were linted.
+	ChartsScanned int `json:"charts_scanned"`
+	// ChartsFailed is the number of charts that could not be linted or had
+	// messages at or above the failing severity.
+	ChartsFailed int `json:"charts_failed"`
+	// Messages counts the messages by severity name, such as "WARNING".
+	Messages map[string]int `json:"messages"`
+	// Rules has the statistics of every rule, summed over all charts, in the
+	// order the rules ran.
+	Rules []support.RuleStats `json:"rules"`
+	// Duration is the time the run took, encoded in nanoseconds.
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Add adds the statistics of other to s.
+func (s *LintSummary) Add(other LintSummary) {
+	s.ChartsScanned += other.ChartsScanned
+	s.ChartsFailed += other.ChartsFailed
+	for name, n := range other.Messages {
+		if s.Messages == nil {
+			s.Messages = make(map[string]int)
+		}
+		s.Messages[name] += n
+	}
+	for _, stats := range other.Rules {
+		s.addRule(stats)
+	}
+	s.Duration += other.Duration
+}
+
+func (s *LintSummary) addRule(stats support.RuleStats) {
+	for i := range s.Rules {
+		if s.Rules[i].Rule == stats.Rule {
+			s.Rules[i].Add(stats)
+			return
+		}
+	}
+	s.Rules = append(s.Rules, support.RuleStats{Rule: stats.Rule})
+	s.Rules[len(s.Rules)-1].Add(stats)
+}
+
+// NewLintSummary creates an empty LintSummary.
+func NewLintSummary() LintSummary {
+	return LintSummary{Messages: make(map[string]int), Rules: []support.RuleStats{}}
 }
 
 // NewLint creates a new Lint object with the given configuration.
@@ -52,31 +163,125 @@ func NewLint() *Lint {
 	return &Lint{}
 }
 
+// SetRegistryClient sets the registry client used to look up images when
+// VerifyImages is set.
+func (l *Lint) SetRegistryClient(client *registry.Client) {
+	l.registryClient = client
+}
+
 // Run executes 'helm Lint' against the given chart.
 func (l *Lint) Run(paths []string, vals map[string]interface{}) *LintResult {
 	lowestTolerance := support.ErrorSev
 	if l.Strict {
 		lowestTolerance = support.WarningSev
 	}
-	result := &LintResult{}
+	start := time.Now()
+	result := &LintResult{Summary: NewLintSummary()}
 	for _, path := range paths {
-		linter, err := lintChart(path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation)
+		linter, err := lintChart(l.Linter, path, vals, l.Namespace, l.KubeVersion, l.SkipSchemaValidation, l.linterOptions()...)
 		if err != nil {
 			result.Errors = append(result.Errors, err)
+			result.Summary.ChartsFailed++
 			continue
 		}
 
 		result.Messages = append(result.Messages, linter.Messages...)
+		for i := range linter.Messages {
+			result.MessageRules = append(result.MessageRules, linter.MessageRule(i))
+		}
 		result.TotalChartsLinted++
+		failed := false
 		for _, msg := range linter.Messages {
+			result.Summary.Messages[support.SeverityName(msg.Severity)]++
 			if msg.Severity >= lowestTolerance {
 				result.Errors = append(result.Errors, msg.Err)
+				failed = true
 			}
 		}
+		if failed {
+			result.Summary.ChartsFailed++
+		}
+		for _, stats := range linter.Rules {
+			result.Summary.addRule(stats)
+		}
 	}
+	result.Summary.ChartsScanned = result.TotalChartsLinted
+	result.Summary.Duration = time.Since(start)
 	return result
 }
 
+// Serve runs a lint server for editor integrations, reading JSON-RPC requests
+// from in and writing responses and diagnostics to out until the client shuts
+// it down. Charts the client opens without values are linted with vals.
+//
+// See lint.Server for the protocol.
+func (l *Lint) Serve(ctx context.Context, in io.Reader, out io.Writer, vals map[string]interface{}) error {
+	options := append([]lint.LinterOption{
+		lint.WithKubeVersion(l.KubeVersion),
+		lint.WithSkipSchemaValidation(l.SkipSchemaValidation),
+	}, l.linterOptions()...)
+	server := lint.NewServer(lint.NewLinter(options...))
+	server.Values = vals
+	server.Namespace = l.Namespace
+	return server.Serve(ctx, in, out)
+}
+
+// linterOptions returns the options for the opt-in lint rules configured on l.
+func (l *Lint) linterOptions() []lint.LinterOption {
+	var options []lint.LinterOption
+	if len(l.RequiredLabels) > 0 || len(l.RequiredAnnotations) > 0 {
+		options = append(options, lint.WithLabelConventions(l.RequiredLabels, l.RequiredAnnotations))
+	}
+	if policy := (rules.MaintainerPolicy{Allowlist: l.AllowedMaintainers, MinMaintainers: l.MinMaintainers}); !policy.IsZero() {
+		options = append(options, lint.WithMaintainerPolicy(policy))
+	}
+	images := rules.ImagePolicy{
+		RequirePinned:     l.RequirePinnedImages,
+		RequireDigest:     l.RequireImageDigests,
+		AllowedRegistries: l.AllowedRegistries,
+	}
+	if l.VerifyImages && l.registryClient != nil {
+		images.Verify = l.verifyImage
+	}
+	if !images.IsZero() {
+		options = append(options, lint.WithImagePolicy(images))
+	}
+	if l.CheckEmptyOutput {
+		options = append(options, lint.WithEmptyOutputChecks(true))
+	}
+	if l.CheckSelectors {
+		options = append(options, lint.WithSelectorChecks(true))
+	}
+	if l.CheckIngress {
+		options = append(options, lint.WithIngressChecks(true))
+	}
+	if l.CheckAvailability {
+		options = append(options, lint.WithAvailabilityChecks(true))
+	}
+	if l.Cluster != nil {
+		options = append(options, lint.WithCluster(l.Cluster))
+	}
+	if len(l.RenderContext) > 0 {
+		options = append(options, lint.WithRenderContext(l.RenderContext))
+	}
+	if len(l.CustomRules) > 0 {
+		options = append(options, lint.WithCustomRules(l.CustomRules...))
+	}
+	return options
+}
+
+// verifyImage checks that the fully qualified image reference exists in its
+// registry.
+func (l *Lint) verifyImage(image string) error {
+	// Docker Hub serves its registry API from a different host than the
+	// one in image references.
+	if rest, ok := strings.CutPrefix(image, "docker.io/"); ok {
+		image = "registry-1.docker.io/" + rest
+	}
+	_, err := l.registryClient.Resolve(image)
+	return err
+}
+
 // HasWarningsOrErrors checks is LintResult has any warnings or errors
 func HasWarningsOrErrors(result *LintResult) bool {
 	for _, msg := range result.Messages {
@@ -87,33 +292,33 @@ func HasWarningsOrErrors(result *LintResult) bool {
 	return len(result.Errors) > 0
 }
 
-func lintChart(path string, vals map[string]interface{}, namespace string, kubeVersion *common.KubeVersion, skipSchemaValidation bool) (support.Linter, error) {
+func lintChart(linter *lint.Linter, path string, vals map[string]interface{}, namespace string, kubeVersion *common.KubeVersion, skipSchemaValidation bool, options ...lint.LinterOption) (support.Linter, error) {
 	var chartPath string
-	linter := support.Linter{}
+	result := support.Linter{}
 
 	if strings.HasSuffix(path, ".tgz") || strings.HasSuffix(path, ".tar.gz") {
 		tempDir, err := os.MkdirTemp("", "helm-lint")
 		if err != nil {
-			return linter, fmt.Errorf("unable to create temp dir to extract tarball: %w", err)
+			return result, fmt.Errorf("unable to create temp dir to extract tarball: %w", err)
 		}
 		defer os.RemoveAll(tempDir)
 
 		file, err := os.Open(path)
 		if err != nil {
-			return linter, fmt.Errorf("unable to open tarball: %w", err)
+			return result, fmt.Errorf("unable to open tarball: %w", err)
 		}
 		defer file.Close()
 
 		if err = chartutil.Expand(tempDir, file); err != nil {
-			return linter, fmt.Errorf("unable to extract tarball: %w", err)
+			return result, fmt.Errorf("unable to extract tarball: %w", err)
 		}
 
 		files, err := os.ReadDir(tempDir)
 		if err != nil {
-			return linter, fmt.Errorf("unable to read temporary output directory %s: %w", tempDir, err)
+			return result, fmt.Errorf("unable to read temporary output directory %s: %w", tempDir, err)
 		}
 		if !files[0].IsDir() {
-			return linter, fmt.Errorf("unexpected file %s in temporary output directory %s", files[0].Name(), tempDir)
+			return result, fmt.Errorf("unexpected file %s in temporary output directory %s", files[0].Name(), tempDir)
 		}
 
 		chartPath = filepath.Join(tempDir, files[0].Name())
@@ -123,14 +328,16 @@ func lintChart(path string, vals map[string]interface{}, namespace string, kubeV
 
 	// Guard: Error out if this is not a chart.
 	if _, err := os.Stat(filepath.Join(chartPath, "Chart.yaml")); err != nil {
-		return linter, fmt.Errorf("unable to check Chart.yaml file in chart: %w", err)
+		return result, fmt.Errorf("unable to check Chart.yaml file in chart: %w", err)
 	}
 
-	return lint.RunAll(
-		chartPath,
-		vals,
-		namespace,
+	options = append([]lint.LinterOption{
 		lint.WithKubeVersion(kubeVersion),
 		lint.WithSkipSchemaValidation(skipSchemaValidation),
-	), nil
+	}, options...)
+
+	if linter != nil {
+		return linter.Lint(chartPath, vals, namespace, options...), nil
+	}
+	return lint.RunAll(chartPath, vals, namespace, options...), nil
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -83,7 +84,7 @@ func TestLintChart(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := lintChart(tt.chartPath, map[string]interface{}{}, namespace, nil, tt.skipSchemaValidation)
+			_, err := lintChart(nil, tt.chartPath, map[string]interface{}{}, namespace, nil, tt.skipSchemaValidation)
 			switch {
 			case err != nil && !tt.err:
 				t.Errorf("%s", err)
@@ -163,3 +164,48 @@ func TestLint_ChartWithWarnings(t *testing.T) {
 		}
 	})
 }
+
+func TestLint_Summary(t *testing.T) {
+	testCharts := []string{chartWithNoTemplatesDir, chart1MultipleChartLint, "testdata/charts/does-not-exist"}
+	testLint := NewLint()
+	testLint.Strict = true
+	summary := testLint.Run(testCharts, values).Summary
+
+	if summary.ChartsScanned != 2 {
+		t.Errorf("expected 2 charts scanned, got %d", summary.ChartsScanned)
+	}
+	// Both charts have warnings, and the last one does not exist.
+	if summary.ChartsFailed != 3 {
+		t.Errorf("expected 3 charts failed, got %d", summary.ChartsFailed)
+	}
+	if summary.Messages["WARNING"] != 2 || summary.Messages["INFO"] != 2 {
+		t.Errorf("expected 2 warnings and 2 infos, got %v", summary.Messages)
+	}
+
+	var rules []string
+	for _, stats := range summary.Rules {
+		rules = append(rules, stats.Rule)
+		if stats.Rule == "templates" && stats.Messages["WARNING"] != 1 {
+			t.Errorf("expected the templates rule to report 1 warning, got %v", stats.Messages)
+		}
+	}
+	if got := strings.Join(rules, ","); got != "chartfile,values,templates,dependencies,lockfile,crds,legacy" {
+		t.Errorf("unexpected rules %s", got)
+	}
+}
+
+func TestLint_OptInRenderedRules(t *testing.T) {
+	testLint := NewLint()
+	testLint.CheckSelectors = true
+	testLint.CheckIngress = true
+	testLint.CheckAvailability = true
+	summary := testLint.Run([]string{chart1MultipleChartLint}, values).Summary
+
+	var rules []string
+	for _, stats := range summary.Rules {
+		rules = append(rules, stats.Rule)
+	}
+	if got := strings.Join(rules, ","); got != "chartfile,values,templates,dependencies,lockfile,crds,legacy,selectors,ingress,availability" {
+		t.Errorf("unexpected rules %s", got)
+	}
+}
@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"golang.org/x/term"
@@ -46,6 +48,13 @@ type Package struct {
 	AppVersion       string
 	Destination      string
 	DependencyUpdate bool
+	// Reproducible makes identical charts package to byte-identical
+	// archives. The files in the archive get the modification time set by
+	// the SOURCE_DATE_EPOCH environment variable, or the Unix epoch.
+	Reproducible bool
+	// Format is the format of the chart archive. The default is a gzip
+	// compressed archive.
+	Format chartutil.ArchiveFormat
 
 	RepositoryConfig      string
 	RepositoryCache       string
@@ -105,7 +114,20 @@ func (p *Package) Run(path string, _ map[string]interface{}) (string, error) {
 		dest = p.Destination
 	}
 
-	name, err := chartutil.Save(ch, dest)
+	var options []chartutil.SaveOption
+	if p.Reproducible {
+		modTime, err := sourceDateEpoch()
+		if err != nil {
+			return "", err
+		}
+		options = append(options, chartutil.WithReproducible(modTime))
+	}
+
+	if p.Format != "" {
+		options = append(options, chartutil.WithFormat(p.Format))
+	}
+
+	name, err := chartutil.Save(ch, dest, options...)
 	if err != nil {
 		return "", fmt.Errorf("failed to save: %w", err)
 	}
@@ -117,6 +139,21 @@ func (p *Package) Run(path string, _ map[string]interface{}) (string, error) {
 	return name, err
 }
 
+// sourceDateEpoch returns the time set by the SOURCE_DATE_EPOCH environment
+// variable, as specified by https://reproducible-builds.org/specs/source-date-epoch/,
+// or the Unix epoch if it is not set.
+func sourceDateEpoch() (time.Time, error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		return time.Unix(0, 0), nil
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: must be a non-negative number of seconds", epoch)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
 // validateVersion Verify that version is a Version, and error out if it is not.
 func validateVersion(ver string) error {
 	if _, err := semver.NewVersion(ver); err != nil {
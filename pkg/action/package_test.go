@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
 	"os"
 	"path"
 	"testing"
@@ -110,6 +111,48 @@ func TestPassphraseFileFetcher_WithStdinAndMultipleFetches(t *testing.T) {
 	}
 }
 
+func TestSourceDateEpoch(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	if modTime, err := sourceDateEpoch(); err != nil || modTime.Unix() != 0 {
+		t.Errorf("Expected the Unix epoch, got %s, %v", modTime, err)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	if modTime, err := sourceDateEpoch(); err != nil || modTime.Unix() != 1700000000 {
+		t.Errorf("Expected the time of SOURCE_DATE_EPOCH, got %s, %v", modTime, err)
+	}
+
+	for _, epoch := range []string{"yesterday", "-1"} {
+		t.Setenv("SOURCE_DATE_EPOCH", epoch)
+		if _, err := sourceDateEpoch(); err == nil {
+			t.Errorf("Expected an error for SOURCE_DATE_EPOCH %q", epoch)
+		}
+	}
+}
+
+func TestPackageReproducible(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	var archives [][]byte
+	for range 2 {
+		client := NewPackage()
+		client.Destination = t.TempDir()
+		client.Reproducible = true
+		name, err := client.Run("testdata/charts/chart-with-uncompressed-dependencies", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archives = append(archives, data)
+	}
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("Expected packaging a chart twice to produce identical archives")
+	}
+}
+
 func TestValidateVersion(t *testing.T) {
 	type args struct {
 		ver string
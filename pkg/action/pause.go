@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// StatusPaused is the custom status of a paused release.
+const StatusPaused release.Status = "paused"
+
+// pausableStatuses are the statuses a release may be paused in.
+var pausableStatuses = []release.Status{
+	release.StatusUnknown,
+	release.StatusDeployed,
+	release.StatusFailed,
+	release.StatusPendingInstall,
+	release.StatusPendingUpgrade,
+	release.StatusPendingRollback,
+}
+
+// Pause is the action for pausing a release.
+//
+// A paused release cannot be upgraded or rolled back until it is resumed;
+// those actions fail with ErrReleasePaused. Pausing records a custom status
+// on the latest revision of the release and does not touch the cluster.
+//
+// Pausing is advisory: the paused state is checked when an upgrade or rollback
+// starts, so it does not stop one that is already running.
+type Pause struct {
+	cfg *Configuration
+}
+
+// NewPause creates a new Pause object with the given configuration.
+func NewPause(cfg *Configuration) *Pause {
+	return &Pause{cfg: cfg}
+}
+
+// Run pauses the named release. Pausing a paused release has no effect.
+func (p *Pause) Run(name string) (*release.Release, error) {
+	return setPaused(p.cfg, name, StatusPaused)
+}
+
+// Resume is the action for resuming a paused release.
+type Resume struct {
+	cfg *Configuration
+}
+
+// NewResume creates a new Resume object with the given configuration.
+func NewResume(cfg *Configuration) *Resume {
+	return &Resume{cfg: cfg}
+}
+
+// Run resumes the named release. Resuming a release that is not paused has no
+// effect.
+func (r *Resume) Run(name string) (*release.Release, error) {
+	return setPaused(r.cfg, name, "")
+}
+
+func setPaused(cfg *Configuration, name string, status release.Status) (*release.Release, error) {
+	if err := cfg.KubeClient.IsReachable(); err != nil {
+		return nil, err
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return nil, fmt.Errorf("release name is invalid: %s", name)
+	}
+
+	rel, err := cfg.Releases.Last(name)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.releaseStateMachine().CustomStatus(rel) == status {
+		return rel, nil
+	}
+	if err := cfg.Releases.SetCustomStatus(rel.Name, rel.Version, status); err != nil {
+		return nil, fmt.Errorf("unable to update release %q: %w", name, err)
+	}
+	return cfg.Releases.Get(rel.Name, rel.Version)
+}
+
+// checkNotPaused returns ErrReleasePaused if rel, the latest revision of a
+// release, is paused.
+func (cfg *Configuration) checkNotPaused(rel *release.Release) error {
+	if cfg.releaseStateMachine().CustomStatus(rel) == StatusPaused {
+		return fmt.Errorf("release %q: %w", rel.Name, ErrReleasePaused)
+	}
+	return nil
+}
+
+// releaseStateMachine returns the state machine of the release storage, with
+// the statuses used by Helm's actions registered.
+func (cfg *Configuration) releaseStateMachine() *release.StateMachine {
+	if cfg.Releases.StateMachine == nil {
+		cfg.Releases.StateMachine = release.NewStateMachine()
+	}
+	if !cfg.Releases.StateMachine.IsCustom(StatusPaused) {
+		// Registering cannot fail for a valid custom status.
+		_ = cfg.Releases.StateMachine.RegisterStatus(StatusPaused, pausableStatuses...)
+	}
+	return cfg.Releases.StateMachine
+}
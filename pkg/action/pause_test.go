@@ -0,0 +1,71 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestPauseResume(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	rel.Name = "paused-release"
+	req.NoError(config.Releases.Create(rel))
+
+	paused, err := NewPause(config).Run(rel.Name)
+	req.NoError(err)
+	is.Equal(StatusPaused, paused.Info.CustomStatus)
+	is.Equal(release.StatusDeployed, paused.Info.Status, "pausing must not change the built-in status")
+
+	// Pausing twice has no effect.
+	_, err = NewPause(config).Run(rel.Name)
+	req.NoError(err)
+
+	upAction := NewUpgrade(config)
+	_, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), map[string]interface{}{})
+	req.ErrorIs(err, ErrReleasePaused)
+
+	rbAction := NewRollback(config)
+	err = rbAction.Run(rel.Name)
+	req.ErrorIs(err, ErrReleasePaused)
+
+	history, err := config.Releases.History(rel.Name)
+	req.NoError(err)
+	is.Len(history, 1, "a paused release must not get new revisions")
+
+	resumed, err := NewResume(config).Run(rel.Name)
+	req.NoError(err)
+	is.Empty(resumed.Info.CustomStatus)
+
+	res, err := upAction.Run(rel.Name, buildChart(withSampleTemplates()), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(2, res.Version)
+	is.Empty(res.Info.CustomStatus)
+}
+
+func TestPauseMissingRelease(t *testing.T) {
+	_, err := NewPause(actionConfigFixture(t)).Run("no-such-release")
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// checkPrerequisites checks the prerequisites declared in the requires section
+// of the Chart.yaml of the chart and its dependencies against the cluster.
+//
+// The nodes and StorageClasses of the cluster are only listed when a chart
+// requires them.
+func (cfg *Configuration) checkPrerequisites(ctx context.Context, chrt *chart.Chart) error {
+	required := chartutil.ChartPrerequisites(chrt)
+	if required == nil {
+		return nil
+	}
+
+	caps, err := cfg.getCapabilities()
+	if err != nil {
+		return err
+	}
+	cluster := chartutil.Cluster{APIVersions: caps.APIVersions}
+	if required.MinNodes > 0 || len(required.StorageClasses) > 0 {
+		client, err := cfg.KubernetesClientSet()
+		if err != nil {
+			return fmt.Errorf("unable to check the chart prerequisites: %w", err)
+		}
+		if err := describeCluster(ctx, client, required, &cluster); err != nil {
+			return fmt.Errorf("unable to check the chart prerequisites: %w", err)
+		}
+	}
+	return chartutil.CheckChartPrerequisites(chrt, cluster)
+}
+
+// describeCluster fills in the node count and StorageClasses of the cluster,
+// when the prerequisites need them.
+func describeCluster(ctx context.Context, client kubernetes.Interface, required *chart.Prerequisites, cluster *chartutil.Cluster) error {
+	if required.MinNodes > 0 {
+		nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("could not list nodes: %w", err)
+		}
+		count := len(nodes.Items)
+		cluster.Nodes = &count
+	}
+	if len(required.StorageClasses) > 0 {
+		classes, err := client.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("could not list StorageClasses: %w", err)
+		}
+		cluster.StorageClasses = make([]string, 0, len(classes.Items))
+		for _, sc := range classes.Items {
+			cluster.StorageClasses = append(cluster.StorageClasses, sc.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func withRequires(p *chart.Prerequisites) chartOption {
+	return func(opts *chartOptions) {
+		opts.Metadata.Requires = p
+	}
+}
+
+func TestInstallRelease_Prerequisites(t *testing.T) {
+	req := require.New(t)
+
+	instAction := installAction(t)
+	chrt := buildChart(withRequires(&chart.Prerequisites{
+		APIVersions: []string{"v1", "monitoring.coreos.com/v1/ServiceMonitor"},
+	}))
+	_, err := instAction.Run(chrt, map[string]interface{}{})
+	req.Error(err)
+	req.Contains(err.Error(), `chart "hello" requires API resource "monitoring.coreos.com/v1/ServiceMonitor", which the cluster does not serve`)
+	_, err = instAction.cfg.Releases.Get(instAction.ReleaseName, 1)
+	req.Error(err, "expected no release to be recorded when the prerequisites fail")
+
+	instAction = installAction(t)
+	instAction.SkipPrerequisites = true
+	res, err := instAction.Run(chrt, map[string]interface{}{})
+	req.NoError(err)
+	assert.Equal(t, release.StatusDeployed, res.Info.Status)
+
+	instAction = installAction(t)
+	instAction.DryRun = true
+	_, err = instAction.Run(chrt, map[string]interface{}{})
+	req.NoError(err, "expected a client-side dry run not to check the prerequisites")
+}
+
+func TestUpgradeRelease_Prerequisites(t *testing.T) {
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	chrt := buildChart(withRequires(&chart.Prerequisites{APIVersions: []string{"postgresql.cnpg.io/v1"}}))
+	_, err := upAction.Run(rel.Name, chrt, map[string]interface{}{})
+	req.Error(err)
+	req.Contains(err.Error(), `chart "hello" requires API version "postgresql.cnpg.io/v1"`)
+	last, err := upAction.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	assert.Equal(t, 1, last.Version)
+
+	upAction.SkipPrerequisites = true
+	_, err = upAction.Run(rel.Name, chrt, map[string]interface{}{})
+	req.NoError(err)
+}
+
+func TestDescribeCluster(t *testing.T) {
+	client := fakeclientset.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}},
+	)
+
+	var cluster chartutil.Cluster
+	err := describeCluster(t.Context(), client, &chart.Prerequisites{MinNodes: 3, StorageClasses: []string{"fast"}}, &cluster)
+	require.NoError(t, err)
+	require.NotNil(t, cluster.Nodes)
+	assert.Equal(t, 2, *cluster.Nodes)
+	assert.Equal(t, []string{"standard"}, cluster.StorageClasses)
+
+	cluster = chartutil.Cluster{}
+	require.NoError(t, describeCluster(t.Context(), client, &chart.Prerequisites{StorageClasses: []string{"fast"}}, &cluster))
+	assert.Nil(t, cluster.Nodes, "expected the nodes not to be listed when no node count is required")
+}
@@ -38,6 +38,7 @@ type Push struct {
 	insecureSkipTLSverify bool
 	plainHTTP             bool
 	out                   io.Writer
+	progress              registry.PushProgressFunc
 }
 
 // PushOpt is a type of function that sets options for a push action.
@@ -80,6 +81,14 @@ func WithPushOptWriter(out io.Writer) PushOpt {
 	}
 }
 
+// WithPushProgress sets the function called as the content of a chart is
+// uploaded to a registry.
+func WithPushProgress(progress registry.PushProgressFunc) PushOpt {
+	return func(p *Push) {
+		p.progress = progress
+	}
+}
+
 // NewPushWithOpts creates a new push, with configuration options.
 func NewPushWithOpts(opts ...PushOpt) *Push {
 	p := &Push{}
@@ -100,6 +109,7 @@ func (p *Push) Run(chartRef string, remote string) (string, error) {
 			pusher.WithTLSClientConfig(p.certFile, p.keyFile, p.caFile),
 			pusher.WithInsecureSkipTLSVerify(p.insecureSkipTLSverify),
 			pusher.WithPlainHTTP(p.plainHTTP),
+			pusher.WithProgress(p.progress),
 		},
 	}
 
@@ -58,6 +58,17 @@ func NewReleaseTesting(cfg *Configuration) *ReleaseTesting {
 
 // Run executes 'helm test' against the given release.
 func (r *ReleaseTesting) Run(name string) (*release.Release, error) {
+	record := OperationRecord{
+		Operation: OperationTest,
+		Release:   name,
+		Start:     time.Now(),
+	}
+	rel, err := r.run(name)
+	r.cfg.recordOperation(record, nil, rel, err)
+	return rel, err
+}
+
+func (r *ReleaseTesting) run(name string) (*release.Release, error) {
 	if err := r.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
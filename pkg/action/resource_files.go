@@ -0,0 +1,182 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// ManifestIndexFile is the name of the index written by WriteResourceFiles.
+const ManifestIndexFile = "manifest-index.json"
+
+// ManifestIndex describes the files written by WriteResourceFiles, for
+// tooling that consumes them.
+type ManifestIndex struct {
+	Release string `json:"release"`
+	// Chart is the name and version of the chart, as in "mychart-0.1.0".
+	Chart string `json:"chart"`
+	// ValuesDigest is the SHA-256 digest of the JSON encoding of the values
+	// supplied to the chart.
+	ValuesDigest string          `json:"values_digest"`
+	Resources    []*ResourceFile `json:"resources"`
+}
+
+// ResourceFile is a resource written to its own file by WriteResourceFiles.
+type ResourceFile struct {
+	// File is the path of the file, relative to the output directory, with
+	// forward slashes.
+	File       string `json:"file"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	// Source is the template the resource was rendered from.
+	Source string `json:"source"`
+	// Hook is true if the resource is a hook.
+	Hook bool `json:"hook,omitempty"`
+}
+
+// WriteResourceFiles writes each resource of the manifest of a release, and
+// each of its hooks accepted by includeHook, to its own file in dir, and
+// writes a ManifestIndexFile describing them.
+//
+// Files are named by the kind and name of their resource, as in
+// "deployment/web.yaml", and start with a comment recording the template the
+// resource was rendered from and the digest of the values. Resources of the
+// same kind and name in different namespaces are numbered, as in
+// "deployment/web-2.yaml". Documents that are not resources are skipped.
+func WriteResourceFiles(dir string, rel *release.Release, includeHook func(*release.Hook) bool) (*ManifestIndex, error) {
+	valuesDigest, err := jsonDigest(rel.Config)
+	if err != nil {
+		return nil, err
+	}
+	index := &ManifestIndex{
+		Release:      rel.Name,
+		ValuesDigest: "sha256:" + valuesDigest,
+		Resources:    []*ResourceFile{},
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		index.Chart = fmt.Sprintf("%s-%s", rel.Chart.Metadata.Name, rel.Chart.Metadata.Version)
+	}
+
+	type document struct {
+		source, content string
+		hook            bool
+	}
+	var docs []document
+	split := releaseutil.SplitManifests(rel.Manifest)
+	keys := make([]string, 0, len(split))
+	for k := range split {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	for _, k := range keys {
+		source, content := splitSourceComment(split[k])
+		docs = append(docs, document{source: source, content: content})
+	}
+	for _, h := range rel.Hooks {
+		if includeHook == nil || includeHook(h) {
+			docs = append(docs, document{source: h.Path, content: h.Manifest, hook: true})
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, d := range docs {
+		var head struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(d.content), &head); err != nil || head.Kind == "" || head.Metadata.Name == "" {
+			continue
+		}
+
+		base := path.Join(sanitizeFileName(strings.ToLower(head.Kind)), sanitizeFileName(head.Metadata.Name))
+		file := base + ".yaml"
+		for n := 2; used[file]; n++ {
+			file = fmt.Sprintf("%s-%d.yaml", base, n)
+		}
+		used[file] = true
+
+		data := fmt.Sprintf("# Source: %s\n# Values digest: %s\n%s\n", d.source, index.ValuesDigest, strings.TrimSpace(d.content))
+		if err := writeResourceFile(filepath.Join(dir, filepath.FromSlash(file)), []byte(data)); err != nil {
+			return nil, err
+		}
+		index.Resources = append(index.Resources, &ResourceFile{
+			File:       file,
+			APIVersion: head.APIVersion,
+			Kind:       head.Kind,
+			Name:       head.Metadata.Name,
+			Namespace:  head.Metadata.Namespace,
+			Source:     d.source,
+			Hook:       d.hook,
+		})
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeResourceFile(filepath.Join(dir, ManifestIndexFile), append(data, '\n')); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// splitSourceComment separates the "# Source:" comment that precedes a
+// document of a release manifest from the document.
+func splitSourceComment(doc string) (string, string) {
+	const prefix = "# Source: "
+	doc = strings.TrimLeft(doc, "\n")
+	if !strings.HasPrefix(doc, prefix) {
+		return "", doc
+	}
+	line, rest, _ := strings.Cut(doc, "\n")
+	return strings.TrimPrefix(line, prefix), rest
+}
+
+// sanitizeFileName replaces the characters of s that are not safe in a file
+// name.
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func writeResourceFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
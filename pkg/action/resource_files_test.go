@@ -0,0 +1,90 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestWriteResourceFiles(t *testing.T) {
+	rel := &release.Release{
+		Name:   "web",
+		Chart:  &chart.Chart{Metadata: &chart.Metadata{Name: "mychart", Version: "1.2.3"}},
+		Config: map[string]interface{}{"replicas": 2},
+		Manifest: `---
+# Source: mychart/templates/app.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: a
+---
+# Source: mychart/templates/app.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: b
+---
+# Source: mychart/templates/notes.yaml
+# only a comment
+`,
+		Hooks: []*release.Hook{{
+			Path:     "mychart/templates/job.yaml",
+			Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n",
+		}},
+	}
+
+	dir := t.TempDir()
+	index, err := WriteResourceFiles(dir, rel, nil)
+	require.NoError(t, err)
+
+	digest, err := jsonDigest(rel.Config)
+	require.NoError(t, err)
+	assert.Equal(t, "web", index.Release)
+	assert.Equal(t, "mychart-1.2.3", index.Chart)
+	assert.Equal(t, "sha256:"+digest, index.ValuesDigest)
+	assert.Equal(t, []*ResourceFile{
+		{File: "deployment/web.yaml", APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "a", Source: "mychart/templates/app.yaml"},
+		{File: "deployment/web-2.yaml", APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "b", Source: "mychart/templates/app.yaml"},
+		{File: "job/migrate.yaml", APIVersion: "batch/v1", Kind: "Job", Name: "migrate", Source: "mychart/templates/job.yaml", Hook: true},
+	}, index.Resources)
+
+	data, err := os.ReadFile(filepath.Join(dir, "deployment", "web-2.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Source: mychart/templates/app.yaml\n# Values digest: sha256:"+digest+"\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n  namespace: b\n", string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, ManifestIndexFile))
+	require.NoError(t, err)
+	var written ManifestIndex
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, index, &written)
+
+	// Hooks can be left out.
+	index, err = WriteResourceFiles(t.TempDir(), rel, func(*release.Hook) bool { return false })
+	require.NoError(t, err)
+	assert.Len(t, index.Resources, 2)
+}
@@ -18,6 +18,7 @@ package action
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -26,6 +27,8 @@ import (
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/kube"
 	release "helm.sh/helm/v4/pkg/release/v1"
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+	"helm.sh/helm/v4/pkg/storage/driver"
 	helmtime "helm.sh/helm/v4/pkg/time"
 )
 
@@ -39,12 +42,18 @@ type Rollback struct {
 	Timeout      time.Duration
 	WaitStrategy kube.WaitStrategy
 	WaitForJobs  bool
+	// WaitProgress, if set, is called with the rollout progress of the
+	// Deployments and StatefulSets while waiting for them.
+	WaitProgress kube.RolloutProgressFunc
 	DisableHooks bool
 	DryRun       bool
 	// ForceReplace will, if set to `true`, ignore certain warnings and perform the rollback anyway.
 	//
 	// This should be used with caution.
 	ForceReplace bool
+	// ForceRecreate lists resources, as "kind/name", which are deleted and
+	// recreated when their update is rejected because of immutable fields.
+	ForceRecreate []string
 	// ForceConflicts causes server-side apply to force conflicts ("Overwrite value, become sole manager")
 	// see: https://kubernetes.io/docs/reference/using-api/server-side-apply/#conflicts
 	ForceConflicts bool
@@ -55,6 +64,15 @@ type Rollback struct {
 	ServerSideApply string
 	CleanupOnFail   bool
 	MaxHistory      int // MaxHistory limits the maximum number of revisions saved per release
+	// MaxFailedHistory limits the number of failed and pending-rollback
+	// revisions saved per release, pruned before the others
+	MaxFailedHistory int
+	// Bundle, if set, is rolled back to instead of a revision from the
+	// release history. The release history may be missing.
+	Bundle *Bundle
+	// ExportBundle, if set, is the directory or OCI repository the rollback
+	// bundle of the new revision is exported to. See ExportBundle.
+	ExportBundle string
 }
 
 // NewRollback creates a new Rollback object with the given configuration.
@@ -66,37 +84,68 @@ func NewRollback(cfg *Configuration) *Rollback {
 
 // Run executes 'helm rollback' against the given release.
 func (r *Rollback) Run(name string) error {
+	record := OperationRecord{
+		Operation: OperationRollback,
+		Release:   name,
+		DryRun:    r.DryRun,
+		Start:     time.Now(),
+	}
+	rel, err := r.run(name)
+	r.cfg.recordOperation(record, nil, rel, err)
+	return err
+}
+
+// run performs the rollback, returning the release rolled back to once it
+// is prepared.
+func (r *Rollback) run(name string) (*release.Release, error) {
 	if err := r.cfg.KubeClient.IsReachable(); err != nil {
-		return err
+		return nil, err
 	}
 
 	r.cfg.Releases.MaxHistory = r.MaxHistory
+	r.cfg.Releases.MaxFailedHistory = r.MaxFailedHistory
 
 	slog.Debug("preparing rollback", "name", name)
 	currentRelease, targetRelease, serverSideApply, err := r.prepareRollback(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !r.DryRun {
+		// The chart of the target release is that of the release rolled back
+		// to, and so are its source and chart tree.
+		var source, archive, tree string
+		if targetRelease.Integrity != nil {
+			source, archive = targetRelease.Integrity.ChartSource, targetRelease.Integrity.ChartArchive
+			tree = targetRelease.Integrity.ChartTree
+		}
+		if err := recordIntegrity(targetRelease, source, archive, tree); err != nil {
+			return targetRelease, err
+		}
+
 		slog.Debug("creating rolled back release", "name", name)
 		if err := r.cfg.Releases.Create(targetRelease); err != nil {
-			return err
+			return targetRelease, err
 		}
 	}
 
 	slog.Debug("performing rollback", "name", name)
 	if _, err := r.performRollback(currentRelease, targetRelease, serverSideApply); err != nil {
-		return err
+		return targetRelease, err
 	}
 
 	if !r.DryRun {
 		slog.Debug("updating status for rolled back release", "name", name)
 		if err := r.cfg.Releases.Update(targetRelease); err != nil {
-			return err
+			return targetRelease, err
+		}
+		if r.ExportBundle != "" {
+			if _, err := ExportBundle(r.cfg, targetRelease, r.ExportBundle); err != nil {
+				return targetRelease, fmt.Errorf("release %s was rolled back, but exporting its rollback bundle failed: %w", name, err)
+			}
 		}
 	}
-	return nil
+	return targetRelease, nil
 }
 
 // prepareRollback finds the previous release and prepares a new release object with
@@ -110,10 +159,17 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 		return nil, nil, false, errInvalidRevision
 	}
 
+	if r.Bundle != nil {
+		return r.prepareBundleRollback(name)
+	}
+
 	currentRelease, err := r.cfg.Releases.Last(name)
 	if err != nil {
 		return nil, nil, false, err
 	}
+	if err := r.cfg.checkNotPaused(currentRelease); err != nil {
+		return nil, nil, false, err
+	}
 
 	previousVersion := r.Version
 	if r.Version == 0 {
@@ -152,10 +208,11 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 
 	// Store a new release object with previous release's configuration
 	targetRelease := &release.Release{
-		Name:      name,
-		Namespace: currentRelease.Namespace,
-		Chart:     previousRelease.Chart,
-		Config:    previousRelease.Config,
+		Name:          name,
+		Namespace:     currentRelease.Namespace,
+		Chart:         previousRelease.Chart,
+		Config:        previousRelease.Config,
+		ConfigSources: previousRelease.ConfigSources,
 		Info: &release.Info{
 			FirstDeployed: currentRelease.Info.FirstDeployed,
 			LastDeployed:  helmtime.Now(),
@@ -170,25 +227,90 @@ func (r *Rollback) prepareRollback(name string) (*release.Release, *release.Rele
 		Manifest:    previousRelease.Manifest,
 		Hooks:       previousRelease.Hooks,
 		ApplyMethod: string(determineReleaseSSApplyMethod(serverSideApply)),
+		Integrity:   previousRelease.Integrity,
 	}
 
 	return currentRelease, targetRelease, serverSideApply, nil
 }
 
+// prepareBundleRollback prepares a new release object from the rollback
+// bundle. The release history is used if there is one, but is not required.
+func (r *Rollback) prepareBundleRollback(name string) (*release.Release, *release.Release, bool, error) {
+	b := r.Bundle
+	if b.Name != name {
+		return nil, nil, false, fmt.Errorf("rollback bundle is for release %q, not %q", b.Name, name)
+	}
+
+	history, err := r.cfg.Releases.History(name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, nil, false, err
+	}
+	var currentRelease *release.Release
+	if len(history) > 0 {
+		releaseutil.Reverse(history, releaseutil.SortByRevision)
+		currentRelease = history[0]
+	}
+
+	targetRelease := &release.Release{
+		Name:      name,
+		Namespace: b.Namespace,
+		Chart:     b.Chart,
+		Config:    b.Values,
+		Info: &release.Info{
+			FirstDeployed: helmtime.Now(),
+			LastDeployed:  helmtime.Now(),
+			Status:        release.StatusPendingRollback,
+			Notes:         b.Notes,
+			Description:   fmt.Sprintf("Rollback to %d from bundle", b.Revision),
+		},
+		Version:  1,
+		Labels:   b.Labels,
+		Manifest: b.Manifest,
+		Hooks:    b.Hooks,
+	}
+	if currentRelease != nil {
+		if err := r.cfg.checkNotPaused(currentRelease); err != nil {
+			return nil, nil, false, err
+		}
+		targetRelease.Namespace = currentRelease.Namespace
+		targetRelease.Info.FirstDeployed = currentRelease.Info.FirstDeployed
+		targetRelease.Version = currentRelease.Version + 1
+	} else {
+		slog.Debug("release has no history, rolling back from bundle only", "name", name)
+	}
+
+	serverSideApply, err := getUpgradeServerSideValue(r.ServerSideApply, b.ApplyMethod)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	targetRelease.ApplyMethod = string(determineReleaseSSApplyMethod(serverSideApply))
+
+	slog.Debug("rolling back from bundle", "name", name, "bundleRevision", b.Revision, "targetVersion", targetRelease.Version)
+	return currentRelease, targetRelease, serverSideApply, nil
+}
+
+// performRollback applies the target release. currentRelease is nil when
+// rolling back from a bundle without release history.
 func (r *Rollback) performRollback(currentRelease, targetRelease *release.Release, serverSideApply bool) (*release.Release, error) {
 	if r.DryRun {
 		slog.Debug("dry run", "name", targetRelease.Name)
 		return targetRelease, nil
 	}
 
-	current, err := r.cfg.KubeClient.Build(bytes.NewBufferString(currentRelease.Manifest), false)
-	if err != nil {
-		return targetRelease, fmt.Errorf("unable to build kubernetes objects from current release manifest: %w", err)
-	}
 	target, err := r.cfg.KubeClient.Build(bytes.NewBufferString(targetRelease.Manifest), false)
 	if err != nil {
 		return targetRelease, fmt.Errorf("unable to build kubernetes objects from new release manifest: %w", err)
 	}
+	// Without a current release, the target resources stand in for the
+	// current ones: resources that still exist are updated in place and
+	// nothing is deleted.
+	current := target
+	if currentRelease != nil {
+		current, err = r.cfg.KubeClient.Build(bytes.NewBufferString(currentRelease.Manifest), false)
+		if err != nil {
+			return targetRelease, fmt.Errorf("unable to build kubernetes objects from current release manifest: %w", err)
+		}
+	}
 
 	// pre-rollback hooks
 
@@ -209,17 +331,21 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		current,
 		target,
 		kube.ClientUpdateOptionForceReplace(r.ForceReplace),
+		kube.ClientUpdateOptionForceRecreate(r.ForceRecreate),
 		kube.ClientUpdateOptionServerSideApply(serverSideApply, r.ForceConflicts),
 		kube.ClientUpdateOptionThreeWayMergeForUnstructured(false),
-		kube.ClientUpdateOptionUpgradeClientSideFieldManager(true))
+		kube.ClientUpdateOptionUpgradeClientSideFieldManager(true),
+		kube.ClientUpdateOptionFieldManager(r.cfg.FieldManager))
 
 	if err != nil {
 		msg := fmt.Sprintf("Rollback %q failed: %s", targetRelease.Name, err)
 		slog.Warn(msg)
-		currentRelease.Info.Status = release.StatusSuperseded
 		targetRelease.Info.Status = release.StatusFailed
 		targetRelease.Info.Description = msg
-		r.cfg.recordRelease(currentRelease)
+		if currentRelease != nil {
+			currentRelease.Info.Status = release.StatusSuperseded
+			r.cfg.recordRelease(currentRelease)
+		}
 		r.cfg.recordRelease(targetRelease)
 		if r.CleanupOnFail {
 			slog.Debug("cleanup on fail set, cleaning up resources", "count", len(results.Created))
@@ -234,21 +360,21 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		return targetRelease, err
 	}
 
-	waiter, err := r.cfg.KubeClient.GetWaiter(r.WaitStrategy)
+	waiter, err := r.cfg.getWaiter(r.WaitStrategy, r.WaitProgress)
 	if err != nil {
 		return nil, fmt.Errorf("unable to set metadata visitor from target release: %w", err)
 	}
 	if r.WaitForJobs {
 		if err := waiter.WaitWithJobs(target, r.Timeout); err != nil {
 			targetRelease.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
-			r.cfg.recordRelease(currentRelease)
+			r.recordCurrent(currentRelease)
 			r.cfg.recordRelease(targetRelease)
 			return targetRelease, fmt.Errorf("release %s failed: %w", targetRelease.Name, err)
 		}
 	} else {
 		if err := waiter.Wait(target, r.Timeout); err != nil {
 			targetRelease.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", targetRelease.Name, err.Error()))
-			r.cfg.recordRelease(currentRelease)
+			r.recordCurrent(currentRelease)
 			r.cfg.recordRelease(targetRelease)
 			return targetRelease, fmt.Errorf("release %s failed: %w", targetRelease.Name, err)
 		}
@@ -261,7 +387,7 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 		}
 	}
 
-	deployed, err := r.cfg.Releases.DeployedAll(currentRelease.Name)
+	deployed, err := r.cfg.Releases.DeployedAll(targetRelease.Name)
 	if err != nil && !strings.Contains(err.Error(), "has no deployed releases") {
 		return nil, err
 	}
@@ -276,3 +402,10 @@ func (r *Rollback) performRollback(currentRelease, targetRelease *release.Releas
 
 	return targetRelease, nil
 }
+
+// recordCurrent records the current release, if there is one.
+func (r *Rollback) recordCurrent(currentRelease *release.Release) {
+	if currentRelease != nil {
+		r.cfg.recordRelease(currentRelease)
+	}
+}
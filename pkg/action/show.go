@@ -19,8 +19,10 @@ package action
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strings"
 
+	"github.com/gosuri/uitable"
 	"k8s.io/cli-runtime/pkg/printers"
 	"sigs.k8s.io/yaml"
 
@@ -45,6 +47,8 @@ const (
 	ShowReadme ShowOutputFormat = "readme"
 	// ShowCRDs is the format which only shows the chart's CRDs
 	ShowCRDs ShowOutputFormat = "crds"
+	// ShowFiles is the format which only lists the chart's files
+	ShowFiles ShowOutputFormat = "files"
 )
 
 var readmeFileNames = []string{"readme.md", "readme.txt", "readme"}
@@ -81,8 +85,11 @@ func (s *Show) SetRegistryClient(client *registry.Client) {
 
 // Run executes 'helm show' against the given release.
 func (s *Show) Run(chartpath string) (string, error) {
+	if s.OutputFormat == ShowFiles {
+		return s.listFiles(chartpath)
+	}
 	if s.chart == nil {
-		chrt, err := loader.Load(chartpath)
+		chrt, err := s.load(chartpath)
 		if err != nil {
 			return "", err
 		}
@@ -141,6 +148,62 @@ func (s *Show) Run(chartpath string) (string, error) {
 	return out.String(), nil
 }
 
+// load loads the chart. Showing the chart metadata or the values only needs
+// the files describing the chart, so the other files are not loaded.
+func (s *Show) load(chartpath string) (*chart.Chart, error) {
+	if s.OutputFormat != ShowChart && s.OutputFormat != ShowValues {
+		return loader.Load(chartpath)
+	}
+	lc, err := loader.LoadLazy(chartpath)
+	if err != nil {
+		return nil, err
+	}
+	return lc.Chart, nil
+}
+
+// listFiles lists the files of the chart and their sizes, including those of
+// its subcharts, without loading them.
+func (s *Show) listFiles(chartpath string) (string, error) {
+	lc, err := loader.LoadLazy(chartpath)
+	if err != nil {
+		return "", err
+	}
+	table := uitable.New()
+	table.AddRow("NAME", "SIZE")
+	for _, f := range lc.Files() {
+		table.AddRow(f.Name, f.Size)
+	}
+	return table.String() + "\n", nil
+}
+
+// WriteFiles streams the named files of the chart to out, without loading
+// or extracting the rest of the chart. When several files are written, each
+// is preceded by a "==> name <==" header.
+func (s *Show) WriteFiles(chartpath string, names []string, out io.Writer) error {
+	lc, err := loader.LoadLazy(chartpath)
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		r, err := lc.Open(name)
+		if err != nil {
+			return err
+		}
+		if len(names) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "==> %s <==\n", name)
+		}
+		_, err = io.Copy(out, r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func findReadme(files []*common.File) (file *common.File) {
 	for _, file := range files {
 		for _, n := range readmeFileNames {
@@ -17,6 +17,8 @@ limitations under the License.
 package action
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v4/pkg/chart/common"
@@ -164,3 +166,42 @@ bar
 		t.Errorf("Expected\n%q\nGot\n%q\n", expect, output)
 	}
 }
+
+func TestShowFiles(t *testing.T) {
+	config := actionConfigFixture(t)
+	client := NewShow(ShowFiles, config)
+
+	for _, chartpath := range []string{"testdata/charts/decompressedchart", "testdata/charts/compressedchart-0.1.0.tgz"} {
+		output, err := client.Run(chartpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(output), "\n")
+		if !strings.HasPrefix(lines[0], "NAME") || !strings.HasSuffix(lines[0], "SIZE") {
+			t.Errorf("Expected a header, got %q", lines[0])
+		}
+		if !strings.Contains(output, "values.yaml") || !strings.Contains(output, "Chart.yaml") {
+			t.Errorf("Expected the files of %s to be listed, got:\n%s", chartpath, output)
+		}
+
+		var buf bytes.Buffer
+		if err := client.WriteFiles(chartpath, []string{"values.yaml"}, &buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "name: my-") {
+			t.Errorf("Unexpected values.yaml of %s: %q", chartpath, buf.String())
+		}
+
+		buf.Reset()
+		if err := client.WriteFiles(chartpath, []string{"values.yaml", "Chart.yaml"}, &buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(buf.String(), "==> values.yaml <==\n") || !strings.Contains(buf.String(), "\n\n==> Chart.yaml <==\napiVersion: v1\n") {
+			t.Errorf("Expected headers for each file of %s, got %q", chartpath, buf.String())
+		}
+
+		if err := client.WriteFiles(chartpath, []string{"missing.txt"}, &buf); err == nil || !strings.Contains(err.Error(), `file "missing.txt" not found`) {
+			t.Errorf("Expected a missing file error, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// ManifestTransformer mutates or filters the resources rendered from a chart
+// before they are applied, such as to inject labels, rewrite namespaces or
+// drop resources a policy forbids.
+type ManifestTransformer interface {
+	// Transform is called with every rendered resource, hooks included, and
+	// the template it was rendered from, after the post-renderer has run. It
+	// may modify obj in place. The resource is removed if keep is false.
+	Transform(source string, obj *unstructured.Unstructured) (keep bool, err error)
+}
+
+// ManifestTransformerFunc is a function implementing ManifestTransformer.
+type ManifestTransformerFunc func(source string, obj *unstructured.Unstructured) (bool, error)
+
+// Transform implements ManifestTransformer.
+func (f ManifestTransformerFunc) Transform(source string, obj *unstructured.Unstructured) (bool, error) {
+	return f(source, obj)
+}
+
+// transformManifests runs the transformers in order over every resource of
+// the rendered files, and returns the files with the transformed resources.
+// Files left without resources are removed.
+func transformManifests(files map[string]string, transformers []ManifestTransformer) (map[string]string, error) {
+	if len(transformers) == 0 {
+		return files, nil
+	}
+
+	transformed := make(map[string]string, len(files))
+	for _, fname := range slices.Sorted(maps.Keys(files)) {
+		content := files[fname]
+		// Partials and empty files are not resources.
+		if strings.HasPrefix(path.Base(fname), "_") || strings.TrimSpace(content) == "" {
+			transformed[fname] = content
+			continue
+		}
+
+		var docs []string
+		decoder := k8syaml.NewYAMLOrJSONDecoder(strings.NewReader(content), 4096)
+	resources:
+		for {
+			var m map[string]interface{}
+			if err := decoder.Decode(&m); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", fname, err)
+			}
+			if m == nil {
+				continue
+			}
+			obj := &unstructured.Unstructured{Object: m}
+			for _, t := range transformers {
+				keep, err := t.Transform(fname, obj)
+				if err != nil {
+					return nil, fmt.Errorf("transforming %s %q from %s: %w", obj.GetKind(), obj.GetName(), fname, err)
+				}
+				if !keep {
+					continue resources
+				}
+			}
+			doc, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return nil, fmt.Errorf("encoding %s %q from %s: %w", obj.GetKind(), obj.GetName(), fname, err)
+			}
+			docs = append(docs, string(doc))
+		}
+		if len(docs) > 0 {
+			transformed[fname] = strings.Join(docs, "---\n")
+		}
+	}
+	return transformed, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+func TestRenderResourcesTransformers(t *testing.T) {
+	cfg := actionConfigFixture(t)
+	ch := buildChartWithTemplates([]*common.File{
+		{Name: "templates/settings.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\ndata:\n  mode: prod\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: token\n")},
+	})
+
+	var sources []string
+	labels := ManifestTransformerFunc(func(source string, obj *unstructured.Unstructured) (bool, error) {
+		sources = append(sources, source)
+		obj.SetLabels(map[string]string{"team": "payments"})
+		obj.SetNamespace("payments")
+		return true, nil
+	})
+	noBindings := ManifestTransformerFunc(func(_ string, obj *unstructured.Unstructured) (bool, error) {
+		return obj.GetKind() != "Secret", nil
+	})
+
+	_, buf, _, err := cfg.renderResources(
+		ch, map[string]interface{}{}, renderOptions{
+			releaseName:  "test-release",
+			transformers: []ManifestTransformer{labels, noBindings},
+		},
+	)
+	require.NoError(t, err)
+
+	expected := `---
+# Source: hello/templates/settings.yaml
+apiVersion: v1
+data:
+  mode: prod
+kind: ConfigMap
+metadata:
+  labels:
+    team: payments
+  name: settings
+  namespace: payments
+`
+	assert.Equal(t, expected, buf.String())
+	assert.Equal(t, []string{"hello/templates/settings.yaml", "hello/templates/settings.yaml"}, sources)
+}
+
+func TestTransformManifestsError(t *testing.T) {
+	files := map[string]string{"hello/templates/cm.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n"}
+	failing := ManifestTransformerFunc(func(string, *unstructured.Unstructured) (bool, error) {
+		return false, errors.New("forbidden by policy")
+	})
+	_, err := transformManifests(files, []ManifestTransformer{failing})
+	assert.EqualError(t, err, `transforming ConfigMap "settings" from hello/templates/cm.yaml: forbidden by policy`)
+
+	// Without transformers, the files are returned as they are.
+	got, err := transformManifests(files, nil)
+	require.NoError(t, err)
+	assert.Equal(t, files, got)
+}
@@ -58,6 +58,22 @@ func NewUninstall(cfg *Configuration) *Uninstall {
 
 // Run uninstalls the given release.
 func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error) {
+	record := OperationRecord{
+		Operation: OperationUninstall,
+		Release:   name,
+		DryRun:    u.DryRun,
+		Start:     time.Now(),
+	}
+	res, err := u.run(name)
+	var rel *release.Release
+	if res != nil {
+		rel = res.Release
+	}
+	u.cfg.recordOperation(record, nil, rel, err)
+	return res, err
+}
+
+func (u *Uninstall) run(name string) (*release.UninstallReleaseResponse, error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
@@ -185,7 +201,7 @@ func (u *Uninstall) Run(name string) (*release.UninstallReleaseResponse, error)
 
 func (u *Uninstall) purgeReleases(rels ...*release.Release) error {
 	for _, rel := range rels {
-		if _, err := u.cfg.Releases.Delete(rel.Name, rel.Version); err != nil {
+		if _, err := u.cfg.Releases.DeleteFromHistory(rel.Name, rel.Version, rels); err != nil {
 			return err
 		}
 	}
@@ -19,8 +19,11 @@ package action
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"log/slog"
 	"strings"
 	"sync"
@@ -70,6 +73,9 @@ type Upgrade struct {
 	WaitStrategy kube.WaitStrategy
 	// WaitForJobs determines whether the wait operation for the Jobs should be performed after the upgrade is requested.
 	WaitForJobs bool
+	// WaitProgress, if set, is called with the rollout progress of the
+	// Deployments and StatefulSets while waiting for them.
+	WaitProgress kube.RolloutProgressFunc
 	// DisableHooks disables hook processing if set to true.
 	DisableHooks bool
 	// DryRun controls whether the operation is prepared, but not executed.
@@ -83,6 +89,9 @@ type Upgrade struct {
 	//
 	// This should be used with caution.
 	ForceReplace bool
+	// ForceRecreate lists resources, as "kind/name", which are deleted and
+	// recreated when their update is rejected because of immutable fields.
+	ForceRecreate []string
 	// ForceConflicts causes server-side apply to force conflicts ("Overwrite value, become sole manager")
 	// see: https://kubernetes.io/docs/reference/using-api/server-side-apply/#conflicts
 	ForceConflicts bool
@@ -99,6 +108,9 @@ type Upgrade struct {
 	ResetThenReuseValues bool
 	// MaxHistory limits the maximum number of revisions saved per release
 	MaxHistory int
+	// MaxFailedHistory limits the number of failed and pending-rollback
+	// revisions saved per release, pruned before the others
+	MaxFailedHistory int
 	// RollbackOnFailure enables rolling back the upgraded release on failure
 	RollbackOnFailure bool
 	// CleanupOnFail will, if true, cause the upgrade to delete newly-created resources on a failed update.
@@ -117,6 +129,9 @@ type Upgrade struct {
 	// If this is non-nil, then after templates are rendered, they will be sent to the
 	// post renderer before sending to the Kubernetes API server.
 	PostRenderer postrenderer.PostRenderer
+	// Transformers mutate or filter the rendered resources in order, after
+	// the post-renderer.
+	Transformers []ManifestTransformer
 	// DisableOpenAPIValidation controls whether OpenAPI validation is enforced.
 	DisableOpenAPIValidation bool
 	// Get missing dependencies
@@ -125,8 +140,71 @@ type Upgrade struct {
 	Lock sync.Mutex
 	// Enable DNS lookups when rendering templates
 	EnableDNS bool
+	// Fail rendering on references to undefined values
+	StrictValues bool
+	// ValuesProfiles names the values profiles of the chart, in its values/
+	// directory, layered under the supplied values in order
+	ValuesProfiles []string
+	// GlobValues are strvals lines whose keys may hold wildcards, expanded
+	// against the values of the chart and set over the supplied values
+	GlobValues []string
+	// ValuesPatches are RFC 6902 JSON Patch documents applied in order to the
+	// values coalesced with those of the chart. They are not stored with the
+	// release.
+	ValuesPatches [][]byte
+	// ValuesSources maps the paths of the supplied values to the values file
+	// or flag that set them. It is stored with the release, along with the
+	// sources of the values reused from the current release.
+	ValuesSources map[string]string
+	// SkipPrerequisites disables checking the prerequisites declared in the
+	// requires section of Chart.yaml against the cluster.
+	SkipPrerequisites bool
 	// TakeOwnership will skip the check for helm annotations and adopt all existing resources.
 	TakeOwnership bool
+	// ChartRefreshPolicy controls whether the upgrade proceeds when the chart
+	// and values match the currently deployed release.
+	ChartRefreshPolicy ChartRefreshPolicy
+	// HistoryCompaction controls how an upgrade that renders the same
+	// manifest as the deployed release is recorded in the release history.
+	//
+	// When empty, HistoryCompactionNone is assumed.
+	HistoryCompaction HistoryCompaction
+	// ExportBundle, if set, is the directory or OCI repository the rollback
+	// bundle of the upgraded release is exported to. See ExportBundle.
+	ExportBundle string
+	// Approve, if set, is called with the impacts of the upgrade, analyzed
+	// from the manifests of the deployed and the upgraded release, before any
+	// change is made. The upgrade is aborted with the error it returns.
+	Approve func(impacts []Impact) error
+}
+
+// ChartRefreshPolicy determines how an upgrade treats a chart that is
+// identical to the one already deployed.
+//
+// The empty policy is the default of the action it is given to: Upgrade
+// assumes ChartRefreshAlways, and Apply assumes ChartRefreshIfChanged.
+type ChartRefreshPolicy string
+
+const (
+	// ChartRefreshAlways always performs the upgrade, even if nothing changed.
+	ChartRefreshAlways ChartRefreshPolicy = "always"
+	// ChartRefreshIfChanged skips the upgrade with ErrNoChange when both the
+	// chart and the values are identical to the deployed release.
+	ChartRefreshIfChanged ChartRefreshPolicy = "if-changed"
+	// ChartRefreshNever refuses to replace the deployed chart. Only the values
+	// may change; an identical chart and values results in ErrNoChange.
+	ChartRefreshNever ChartRefreshPolicy = "never"
+)
+
+// ParseChartRefreshPolicy converts a string into a ChartRefreshPolicy. The
+// empty string is rejected, as its meaning depends on the action.
+func ParseChartRefreshPolicy(s string) (ChartRefreshPolicy, error) {
+	switch p := ChartRefreshPolicy(s); p {
+	case ChartRefreshAlways, ChartRefreshIfChanged, ChartRefreshNever:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid chart refresh policy %q: must be one of %q, %q or %q", s, ChartRefreshAlways, ChartRefreshIfChanged, ChartRefreshNever)
+	}
 }
 
 type resultMessage struct {
@@ -145,6 +223,37 @@ func NewUpgrade(cfg *Configuration) *Upgrade {
 	return up
 }
 
+// HistoryCompaction determines how an upgrade that renders the same manifest
+// as the deployed release is recorded. Automation that upgrades releases on a
+// schedule would otherwise add a full copy of the release to the history on
+// every run.
+type HistoryCompaction string
+
+const (
+	// HistoryCompactionNone records every upgrade as a complete revision.
+	HistoryCompactionNone HistoryCompaction = "none"
+	// HistoryCompactionSkip skips the upgrade with ErrNoChange when the
+	// rendered manifest, hooks and values are identical to the deployed
+	// release, even if the chart differs. No revision is created.
+	HistoryCompactionSkip HistoryCompaction = "skip"
+	// HistoryCompactionReference creates a revision that references the
+	// chart and manifest of the deployed release instead of storing a copy
+	// when the chart, values, manifest and hooks are all identical.
+	HistoryCompactionReference HistoryCompaction = "reference"
+)
+
+// ParseHistoryCompaction converts a string into a HistoryCompaction.
+func ParseHistoryCompaction(s string) (HistoryCompaction, error) {
+	switch c := HistoryCompaction(s); c {
+	case "":
+		return HistoryCompactionNone, nil
+	case HistoryCompactionNone, HistoryCompactionSkip, HistoryCompactionReference:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid history compaction %q: must be one of %q, %q or %q", s, HistoryCompactionNone, HistoryCompactionSkip, HistoryCompactionReference)
+	}
+}
+
 // SetRegistryClient sets the registry client to use when fetching charts.
 func (u *Upgrade) SetRegistryClient(client *registry.Client) {
 	u.registryClient = client
@@ -157,7 +266,23 @@ func (u *Upgrade) Run(name string, chart *chart.Chart, vals map[string]interface
 }
 
 // RunWithContext executes the upgrade on the given release with context.
+//
+// If the ChartRefreshPolicy allows skipping the upgrade and nothing changed,
+// the deployed release is returned together with ErrNoChange.
 func (u *Upgrade) RunWithContext(ctx context.Context, name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
+	record := OperationRecord{
+		Operation: OperationUpgrade,
+		Release:   name,
+		Namespace: u.Namespace,
+		DryRun:    u.isDryRun(),
+		Start:     time.Now(),
+	}
+	rel, err := u.run(ctx, name, chart, vals)
+	u.cfg.recordOperation(record, chart, rel, err)
+	return rel, err
+}
+
+func (u *Upgrade) run(ctx context.Context, name string, chart *chart.Chart, vals map[string]interface{}) (*release.Release, error) {
 	if err := u.cfg.KubeClient.IsReachable(); err != nil {
 		return nil, err
 	}
@@ -174,11 +299,23 @@ func (u *Upgrade) RunWithContext(ctx context.Context, name string, chart *chart.
 
 	slog.Debug("preparing upgrade", "name", name)
 	currentRelease, upgradedRelease, serverSideApply, err := u.prepareUpgrade(name, chart, vals)
+	if errors.Is(err, ErrNoChange) {
+		slog.Debug("release unchanged, skipping upgrade", "name", name, "revision", currentRelease.Version)
+		return currentRelease, err
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if u.Approve != nil {
+		impacts := AnalyzeImpact(currentRelease.Manifest, upgradedRelease.Manifest, upgradedRelease.Namespace)
+		if err := u.Approve(impacts); err != nil {
+			return nil, err
+		}
+	}
+
 	u.cfg.Releases.MaxHistory = u.MaxHistory
+	u.cfg.Releases.MaxFailedHistory = u.MaxFailedHistory
 
 	slog.Debug("performing update", "name", name)
 	res, err := u.performUpgrade(ctx, currentRelease, upgradedRelease, serverSideApply)
@@ -192,6 +329,11 @@ func (u *Upgrade) RunWithContext(ctx context.Context, name string, chart *chart.
 		if err := u.cfg.Releases.Update(upgradedRelease); err != nil {
 			return res, err
 		}
+		if u.ExportBundle != "" {
+			if _, err := ExportBundle(u.cfg, res, u.ExportBundle); err != nil {
+				return res, fmt.Errorf("release %s was upgraded, but exporting its rollback bundle failed: %w", name, err)
+			}
+		}
 	}
 
 	return res, nil
@@ -226,6 +368,10 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		return nil, nil, false, err
 	}
 
+	if err := u.cfg.checkNotPaused(lastRelease); err != nil {
+		return nil, nil, false, err
+	}
+
 	// Concurrent `helm upgrade`s will either fail here with `errPending` or when creating the release with "already exists". This should act as a pessimistic lock.
 	if lastRelease.Info.Status.IsPending() {
 		return nil, nil, false, errPending
@@ -248,12 +394,67 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		}
 	}
 
+	policy := ChartRefreshAlways
+	if u.ChartRefreshPolicy != "" {
+		if policy, err = ParseChartRefreshPolicy(string(u.ChartRefreshPolicy)); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	compaction, err := ParseHistoryCompaction(string(u.HistoryCompaction))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	// The chart digest must be computed before reuseValues, which may replace
+	// the chart's default values with the ones from the current release.
+	treeDigest, err := chartTreeDigest(chart)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var chartChanged bool
+	if policy != ChartRefreshAlways || compaction == HistoryCompactionReference {
+		chartChanged, err = chartDiffers(chart, treeDigest, currentRelease)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if chartChanged && policy == ChartRefreshNever {
+			return nil, nil, false, fmt.Errorf("chart %s-%s differs from the deployed chart and the chart refresh policy is %q",
+				chart.Name(), chart.Metadata.Version, policy)
+		}
+	}
+
+	vals, err = chartutil.ApplyValuesProfiles(chart, vals, u.ValuesProfiles)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
 	// determine if values will be reused
+	reused := !u.ResetValues && (u.ReuseValues || u.ResetThenReuseValues || len(vals) == 0)
 	vals, err = u.reuseValues(chart, currentRelease, vals)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
+	vals, err = chartutil.ApplyGlobValues(chart, vals, u.GlobValues)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if policy != ChartRefreshAlways && !chartChanged {
+		valuesChanged, err := digestChanged(vals, currentRelease.Config)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !valuesChanged && currentRelease.Info.Status == release.StatusDeployed {
+			return currentRelease, nil, false, ErrNoChange
+		}
+	}
+
+	if !u.SkipSchemaValidation {
+		if err := chartutil.ValidateValuesProfiles(chart, vals, u.ValuesProfiles); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
 	if err := chartutil.ProcessDependencies(chart, vals); err != nil {
 		return nil, nil, false, err
 	}
@@ -269,22 +470,36 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		IsUpgrade: true,
 	}
 
+	// Determine whether or not to interact with remote
+	var interactWithRemote bool
+	if !u.isDryRun() || u.DryRunOption == "server" || u.DryRunOption == "none" || u.DryRunOption == "false" {
+		interactWithRemote = true
+	}
+
+	if !u.SkipPrerequisites && interactWithRemote {
+		if err := u.cfg.checkPrerequisites(context.Background(), chart); err != nil {
+			return nil, nil, false, err
+		}
+	}
+
 	caps, err := u.cfg.getCapabilities()
 	if err != nil {
 		return nil, nil, false, err
 	}
-	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chart, vals, options, caps, u.SkipSchemaValidation)
+	valuesToRender, err := toRenderValues(chart, vals, options, caps, u.SkipSchemaValidation, u.ValuesPatches, u.ValuesSources)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
-	// Determine whether or not to interact with remote
-	var interactWithRemote bool
-	if !u.isDryRun() || u.DryRunOption == "server" || u.DryRunOption == "none" || u.DryRunOption == "false" {
-		interactWithRemote = true
-	}
-
-	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, "", "", u.SubNotes, false, false, u.PostRenderer, interactWithRemote, u.EnableDNS, u.HideSecret)
+	hooks, manifestDoc, notesTxt, err := u.cfg.renderResources(chart, valuesToRender, renderOptions{
+		subNotes:           u.SubNotes,
+		postRenderer:       u.PostRenderer,
+		transformers:       u.Transformers,
+		interactWithRemote: interactWithRemote,
+		enableDNS:          u.EnableDNS,
+		strictValues:       u.StrictValues,
+		hideSecret:         u.HideSecret,
+	})
 	if err != nil {
 		return nil, nil, false, err
 	}
@@ -317,11 +532,38 @@ func (u *Upgrade) prepareUpgrade(name string, chart *chart.Chart, vals map[strin
 		Hooks:       hooks,
 		Labels:      mergeCustomLabels(lastRelease.Labels, u.Labels),
 		ApplyMethod: string(determineReleaseSSApplyMethod(serverSideApply)),
+		// The remaining digests are recorded before the release is stored.
+		Integrity: &release.Integrity{ChartTree: treeDigest},
+	}
+	if (reused && currentRelease.ConfigSources != nil) || u.ValuesSources != nil {
+		var previous map[string]string
+		if reused {
+			previous = currentRelease.ConfigSources
+		}
+		upgradedRelease.ConfigSources = chartutil.MergeValuesSources(vals, previous, u.ValuesSources)
 	}
 
 	if len(notesTxt) > 0 {
 		upgradedRelease.Info.Notes = notesTxt
 	}
+
+	if compaction != HistoryCompactionNone && currentRelease.Info.Status == release.StatusDeployed && sameRendering(currentRelease, upgradedRelease) {
+		valuesChanged, err := digestChanged(vals, currentRelease.Config)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		switch {
+		case valuesChanged:
+		case compaction == HistoryCompactionSkip:
+			return currentRelease, nil, false, ErrNoChange
+		case !chartChanged:
+			upgradedRelease.SharedRevision = currentRelease.Version
+			if currentRelease.SharedRevision != 0 {
+				upgradedRelease.SharedRevision = currentRelease.SharedRevision
+			}
+		}
+	}
+
 	err = validateManifest(u.cfg.KubeClient, manifestDoc.Bytes(), !u.DisableOpenAPIValidation)
 	return currentRelease, upgradedRelease, serverSideApply, err
 }
@@ -391,6 +633,14 @@ func (u *Upgrade) performUpgrade(ctx context.Context, originalRelease, upgradedR
 		return upgradedRelease, nil
 	}
 
+	var treeDigest string
+	if upgradedRelease.Integrity != nil {
+		treeDigest = upgradedRelease.Integrity.ChartTree
+	}
+	if err := recordIntegrity(upgradedRelease, u.chartSource, u.chartArchiveDigest, treeDigest); err != nil {
+		return nil, err
+	}
+
 	slog.Debug("creating upgraded release", "name", upgradedRelease.Name)
 	if err := u.cfg.Releases.Create(upgradedRelease); err != nil {
 		return nil, err
@@ -456,15 +706,17 @@ func (u *Upgrade) releasingUpgrade(c chan<- resultMessage, upgradedRelease *rele
 		current,
 		target,
 		kube.ClientUpdateOptionForceReplace(u.ForceReplace),
+		kube.ClientUpdateOptionForceRecreate(u.ForceRecreate),
 		kube.ClientUpdateOptionServerSideApply(serverSideApply, u.ForceConflicts),
-		kube.ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManager))
+		kube.ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManager),
+		kube.ClientUpdateOptionFieldManager(u.cfg.FieldManager))
 	if err != nil {
 		u.cfg.recordRelease(originalRelease)
 		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
 		return
 	}
 
-	waiter, err := u.cfg.KubeClient.GetWaiter(u.WaitStrategy)
+	waiter, err := u.cfg.getWaiter(u.WaitStrategy, u.WaitProgress)
 	if err != nil {
 		u.cfg.recordRelease(originalRelease)
 		u.reportToPerformUpgrade(c, upgradedRelease, results.Created, err)
@@ -556,8 +808,10 @@ func (u *Upgrade) failRelease(rel *release.Release, created kube.ResourceList, e
 			rollin.WaitStrategy = kube.StatusWatcherStrategy
 		}
 		rollin.WaitForJobs = u.WaitForJobs
+		rollin.WaitProgress = u.WaitProgress
 		rollin.DisableHooks = u.DisableHooks
 		rollin.ForceReplace = u.ForceReplace
+		rollin.ForceRecreate = u.ForceRecreate
 		rollin.ForceConflicts = u.ForceConflicts
 		rollin.ServerSideApply = u.ServerSideApply
 		rollin.Timeout = u.Timeout
@@ -618,6 +872,85 @@ func (u *Upgrade) reuseValues(chart *chart.Chart, current *release.Release, newV
 	return newVals, nil
 }
 
+// sameRendering reports whether two releases have the same manifest and hooks.
+func sameRendering(a, b *release.Release) bool {
+	if a.Manifest != b.Manifest || len(a.Hooks) != len(b.Hooks) {
+		return false
+	}
+	for i := range a.Hooks {
+		if a.Hooks[i].Path != b.Hooks[i].Path || a.Hooks[i].Manifest != b.Hooks[i].Manifest {
+			return false
+		}
+	}
+	return true
+}
+
+// chartDiffers reports whether a chart, whose tree has the given digest,
+// differs from the chart of a stored release. Dependencies are not stored
+// with a release, so the digest of the chart tree recorded with the release
+// is compared when there is one. Otherwise a chart with dependencies is
+// assumed to differ, as a change to a subchart could not be detected.
+func chartDiffers(ch *chart.Chart, treeDigest string, rel *release.Release) (bool, error) {
+	if rel.Integrity != nil && rel.Integrity.ChartTree != "" {
+		return treeDigest != rel.Integrity.ChartTree, nil
+	}
+	if len(ch.Dependencies()) > 0 {
+		return true, nil
+	}
+	return digestChanged(ch, rel.Chart)
+}
+
+// digestChanged reports whether the JSON encodings of a and b have different
+// SHA-256 digests. JSON is used because it is the form in which releases are
+// persisted, so a chart read back from storage digests the same as the chart
+// it was created from.
+func digestChanged(a, b interface{}) (bool, error) {
+	da, err := jsonDigest(a)
+	if err != nil {
+		return false, err
+	}
+	db, err := jsonDigest(b)
+	if err != nil {
+		return false, err
+	}
+	return da != db, nil
+}
+
+func jsonDigest(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("unable to compute digest: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+// chartTreeDigest returns the SHA-256 digest, prefixed with "sha256:", of the
+// JSON encodings of a chart and, recursively, of its dependencies, which are
+// not part of the JSON encoding of a chart.
+func chartTreeDigest(ch *chart.Chart) (string, error) {
+	h := sha256.New()
+	if err := writeChartTree(h, ch); err != nil {
+		return "", fmt.Errorf("unable to compute digest: %w", err)
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+func writeChartTree(h hash.Hash, ch *chart.Chart) error {
+	b, err := json.Marshal(ch)
+	if err != nil {
+		return err
+	}
+	h.Write(b)
+	h.Write([]byte{0})
+	for _, dep := range ch.Dependencies() {
+		if err := writeChartTree(h, dep); err != nil {
+			return err
+		}
+	}
+	h.Write([]byte{1})
+	return nil
+}
+
 func validateManifest(c kube.Interface, manifest []byte, openAPIValidation bool) error {
 	_, err := c.Build(bytes.NewReader(manifest), openAPIValidation)
 	return err
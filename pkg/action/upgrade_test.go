@@ -18,6 +18,7 @@ package action
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -585,6 +586,169 @@ func TestUpgradeRelease_DryRun(t *testing.T) {
 	req.Error(err)
 }
 
+func TestUpgradeRelease_ChartRefreshPolicy(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "previous-release"
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	upAction.ChartRefreshPolicy = ChartRefreshIfChanged
+
+	// Identical chart and values skip the upgrade.
+	res, err := upAction.Run(rel.Name, buildChart(withSampleTemplates()), map[string]interface{}{})
+	req.ErrorIs(err, ErrNoChange)
+	is.Equal(1, res.Version)
+
+	lastRelease, err := upAction.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	is.Equal(1, lastRelease.Version)
+
+	// The chart is identical but the values are not.
+	res, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), map[string]interface{}{"name": "other"})
+	req.NoError(err)
+	is.Equal(2, res.Version)
+	is.Equal(release.StatusDeployed, res.Info.Status)
+
+	// A different chart is refused by the "never" policy.
+	upAction.ChartRefreshPolicy = ChartRefreshNever
+	_, err = upAction.Run(rel.Name, buildChart(withSampleTemplates(), withName("other")), map[string]interface{}{})
+	req.Error(err)
+	is.Contains(err.Error(), "differs from the deployed chart")
+
+	// The empty policy upgrades even when nothing changed.
+	upAction.ChartRefreshPolicy = ""
+	res, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), map[string]interface{}{"name": "other"})
+	req.NoError(err)
+	is.Equal(3, res.Version)
+
+	upAction.ChartRefreshPolicy = "sometimes"
+	_, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), map[string]interface{}{})
+	req.Error(err)
+	is.Contains(err.Error(), "invalid chart refresh policy")
+
+	_, err = ParseChartRefreshPolicy("")
+	req.Error(err)
+}
+
+func TestUpgradeRelease_ChartRefreshPolicyDependencyChange(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "previous-release"
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	upAction.ChartRefreshPolicy = ChartRefreshIfChanged
+	withSubchart := func(image string) *chart.Chart {
+		return buildChart(withSampleTemplates(), withDependency(withName("sub"), withValues(map[string]interface{}{"image": image})))
+	}
+
+	// The stored release has no recorded chart tree, so a chart with
+	// dependencies is assumed to have changed.
+	res, err := upAction.Run(rel.Name, withSubchart("a"), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(2, res.Version)
+	is.NotEmpty(res.Integrity.ChartTree)
+
+	res, err = upAction.Run(rel.Name, withSubchart("a"), map[string]interface{}{})
+	req.ErrorIs(err, ErrNoChange)
+	is.Equal(2, res.Version)
+
+	// Only the subchart changed.
+	res, err = upAction.Run(rel.Name, withSubchart("b"), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(3, res.Version)
+}
+
+func TestUpgradeRelease_Approve(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "previous-release"
+	rel.Namespace = "spaced"
+	rel.Manifest = "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	var approved []Impact
+	upAction.Approve = func(impacts []Impact) error {
+		approved = impacts
+		return errors.New("declined")
+	}
+	_, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.EqualError(err, "declined")
+	is.Equal([]Impact{{ImpactServiceIP, "Service", "spaced", "web", "removed; its IPs are released"}}, approved)
+
+	lastRelease, err := upAction.cfg.Releases.Last(rel.Name)
+	req.NoError(err)
+	is.Equal(1, lastRelease.Version)
+
+	upAction.Approve = func([]Impact) error { return nil }
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{})
+	req.NoError(err)
+	is.Equal(2, res.Version)
+}
+
+func TestUpgradeRelease_HistoryCompaction(t *testing.T) {
+	is := assert.New(t)
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Name = "previous-release"
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	vals := map[string]interface{}{"name": "value"}
+	res, err := upAction.Run(rel.Name, buildChart(withSampleTemplates()), vals)
+	req.NoError(err)
+	is.Equal(2, res.Version)
+
+	// An identical rendering creates a revision sharing the chart and
+	// manifest of the deployed one.
+	upAction.HistoryCompaction = HistoryCompactionReference
+	res, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), vals)
+	req.NoError(err)
+	is.Equal(3, res.Version)
+	is.Equal(2, res.SharedRevision)
+	res, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), vals)
+	req.NoError(err)
+	is.Equal(4, res.Version)
+	is.Equal(2, res.SharedRevision)
+
+	stored, err := upAction.cfg.Releases.Get(rel.Name, 4)
+	req.NoError(err)
+	is.Equal(release.StatusDeployed, stored.Info.Status)
+	is.NotEmpty(stored.Manifest)
+	is.Equal("hello", stored.Chart.Name())
+
+	// A different chart with the same rendering is stored in full.
+	res, err = upAction.Run(rel.Name, buildChart(withSampleTemplates(), withNotes("second")), vals)
+	req.NoError(err)
+	is.Equal(5, res.Version)
+	is.Zero(res.SharedRevision)
+
+	// An identical rendering does not create a revision.
+	upAction.HistoryCompaction = HistoryCompactionSkip
+	res, err = upAction.Run(rel.Name, buildChart(withSampleTemplates(), withNotes("third")), vals)
+	req.ErrorIs(err, ErrNoChange)
+	is.Equal(5, res.Version)
+
+	// Different values are always upgraded.
+	res, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), map[string]interface{}{"name": "other"})
+	req.NoError(err)
+	is.Equal(6, res.Version)
+
+	upAction.HistoryCompaction = "sometimes"
+	_, err = upAction.Run(rel.Name, buildChart(withSampleTemplates()), vals)
+	req.Error(err)
+	is.Contains(err.Error(), "invalid history compaction")
+}
+
 func TestGetUpgradeServerSideValue(t *testing.T) {
 	tests := []struct {
 		name                    string
@@ -690,3 +854,26 @@ func TestGetUpgradeServerSideValue(t *testing.T) {
 	}
 
 }
+
+func TestUpgradeRelease_ValuesSources(t *testing.T) {
+	req := require.New(t)
+
+	upAction := upgradeAction(t)
+	rel := releaseStub()
+	rel.Config = map[string]interface{}{"name": "value", "replicas": 2}
+	rel.ConfigSources = map[string]string{"name": "-f old.yaml (file 1)", "replicas": "--set replicas=2"}
+	req.NoError(upAction.cfg.Releases.Create(rel))
+
+	upAction.ReuseValues = true
+	upAction.ValuesSources = map[string]string{"replicas": "--set replicas=3"}
+	res, err := upAction.Run(rel.Name, buildChart(), map[string]interface{}{"replicas": 3})
+	req.NoError(err)
+	assert.Equal(t, map[string]string{"name": "-f old.yaml (file 1)", "replicas": "--set replicas=3"}, res.ConfigSources)
+
+	upAction = upgradeAction(t)
+	req.NoError(upAction.cfg.Releases.Create(rel))
+	upAction.ValuesSources = map[string]string{"replicas": "--set replicas=3"}
+	res, err = upAction.Run(rel.Name, buildChart(), map[string]interface{}{"replicas": 3})
+	req.NoError(err)
+	assert.Equal(t, map[string]string{"replicas": "--set replicas=3"}, res.ConfigSources, "expected the sources of values not reused to be dropped")
+}
@@ -0,0 +1,46 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// BuildValues returns the values an installation of the chart with the
+// user-supplied values vals would render the templates with: the values are
+// coalesced with those of the chart and its enabled dependencies, and
+// validated against the schemas of the charts unless skipSchemaValidation is
+// set.
+//
+// The dependencies of the chart are processed in place, as by Install.
+func BuildValues(chrt *chart.Chart, vals map[string]interface{}, skipSchemaValidation bool) (common.Values, error) {
+	if err := chartutil.ProcessDependencies(chrt, vals); err != nil {
+		return nil, fmt.Errorf("chart dependencies processing failed: %w", err)
+	}
+
+	options := common.ReleaseOptions{Revision: 1, IsInstall: true}
+	top, err := util.ToRenderValuesWithSchemaValidation(chrt, vals, options, nil, skipSchemaValidation)
+	if err != nil {
+		return nil, err
+	}
+	return top["Values"].(common.Values), nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestBuildValues(t *testing.T) {
+	schema := []byte(`{"type": "object", "properties": {"replicas": {"type": "integer", "minimum": 1}}}`)
+	withSchema := func(opts *chartOptions) {
+		opts.Schema = schema
+	}
+
+	chrt := buildChart(
+		withValues(map[string]interface{}{"replicas": 1, "image": map[string]interface{}{"tag": "1.0"}}),
+		withSchema,
+		withDependency(withName("sub"), withValues(map[string]interface{}{"enabled": true})),
+		withMetadataDependency(chart.Dependency{Name: "sub", Condition: "sub.enabled"}),
+	)
+	vals, err := BuildValues(chrt, map[string]interface{}{"replicas": 3}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, vals["replicas"])
+	assert.Equal(t, map[string]interface{}{"tag": "1.0"}, vals["image"])
+	assert.Equal(t, true, vals["sub"].(map[string]interface{})["enabled"])
+
+	// Disabled dependencies do not contribute values.
+	chrt = buildChart(
+		withDependency(withName("sub"), withValues(map[string]interface{}{"enabled": true})),
+		withMetadataDependency(chart.Dependency{Name: "sub", Condition: "sub.enabled"}),
+	)
+	vals, err = BuildValues(chrt, map[string]interface{}{"sub": map[string]interface{}{"enabled": false}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, false, vals["sub"].(map[string]interface{})["enabled"])
+	assert.Empty(t, chrt.Dependencies())
+
+	chrt = buildChart(withValues(map[string]interface{}{"replicas": 1}), withSchema)
+	_, err = BuildValues(chrt, map[string]interface{}{"replicas": 0}, false)
+	assert.ErrorContains(t, err, "values don't meet the specifications of the schema(s)")
+
+	vals, err = BuildValues(chrt, map[string]interface{}{"replicas": 0}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, vals["replicas"])
+}
@@ -0,0 +1,304 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/provenance"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// IntegrityStatus is the outcome of an integrity check.
+type IntegrityStatus string
+
+const (
+	// IntegrityOK is a check that passed.
+	IntegrityOK IntegrityStatus = "ok"
+	// IntegrityFailed is a check that found a mismatch, or could not be run
+	// although it applies to the release.
+	IntegrityFailed IntegrityStatus = "failed"
+	// IntegritySkipped is a check that does not apply to the release, or
+	// that was disabled.
+	IntegritySkipped IntegrityStatus = "skipped"
+)
+
+// Integrity checks run by VerifyRelease.
+const (
+	IntegrityCheckChart       = "chart"
+	IntegrityCheckValues      = "values"
+	IntegrityCheckManifest    = "manifest"
+	IntegrityCheckHooks       = "hooks"
+	IntegrityCheckChartSource = "chart-source"
+	IntegrityCheckResource    = "resource"
+)
+
+// IntegrityCheck is the result of one of the checks of VerifyRelease.
+type IntegrityCheck struct {
+	// Check is one of the IntegrityCheck constants.
+	Check string `json:"check"`
+	// Subject is what was checked when it is not the release itself, such as
+	// a resource of the release.
+	Subject string          `json:"subject,omitempty"`
+	Status  IntegrityStatus `json:"status"`
+	Message string          `json:"message,omitempty"`
+}
+
+// VerifyRelease is the action for checking that a stored release was not
+// corrupted or tampered with.
+//
+// It provides the implementation of 'helm verify-release'. It recomputes the
+// digests of the chart, values, manifest and hooks of the release and
+// compares them with the ones recorded when the release was created, pulls
+// the chart again from the repository or registry it came from and compares
+// the digests of the archives, and checks that the resources of the release
+// in the cluster carry its ownership metadata.
+type VerifyRelease struct {
+	ChartPathOptions
+
+	cfg *Configuration
+
+	// Version is the revision to verify. The latest revision is verified if
+	// it is 0.
+	Version int
+	// SkipChartSource disables pulling the chart from its source.
+	SkipChartSource bool
+	// SkipLive disables checking the resources in the cluster.
+	SkipLive bool
+}
+
+// NewVerifyRelease creates a new VerifyRelease object with the given
+// configuration.
+func NewVerifyRelease(cfg *Configuration) *VerifyRelease {
+	v := &VerifyRelease{cfg: cfg}
+	v.registryClient = cfg.RegistryClient
+	return v
+}
+
+// Run verifies the named release. The settings are used to pull the chart of
+// the release from its source.
+//
+// The checks are returned whether they pass or not; an error is returned only
+// if the release cannot be read.
+func (v *VerifyRelease) Run(name string, settings *cli.EnvSettings) ([]*IntegrityCheck, error) {
+	if name == "" {
+		return nil, errMissingRelease
+	}
+	if !v.SkipLive {
+		if err := v.cfg.KubeClient.IsReachable(); err != nil {
+			return nil, err
+		}
+	}
+	rel, err := v.cfg.releaseContent(name, v.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := storedDigestChecks(rel)
+	checks = append(checks, v.chartSourceCheck(rel, settings))
+	if !v.SkipLive {
+		checks = append(checks, v.resourceChecks(rel)...)
+	}
+	return checks, nil
+}
+
+// storedDigestChecks compares the digests of the parts of a release with the
+// ones recorded when it was created.
+func storedDigestChecks(rel *release.Release) []*IntegrityCheck {
+	recorded := rel.Integrity
+	if recorded == nil {
+		recorded = &release.Integrity{}
+	}
+	current, err := releaseIntegrity(rel)
+	if err != nil {
+		current = &release.Integrity{}
+	}
+	digests := []struct {
+		check              string
+		recorded, computed string
+	}{
+		{IntegrityCheckChart, recorded.Chart, current.Chart},
+		{IntegrityCheckValues, recorded.Values, current.Values},
+		{IntegrityCheckManifest, recorded.Manifest, current.Manifest},
+		{IntegrityCheckHooks, recorded.Hooks, current.Hooks},
+	}
+
+	checks := make([]*IntegrityCheck, 0, len(digests))
+	for _, d := range digests {
+		c := &IntegrityCheck{Check: d.check}
+		switch {
+		case d.recorded == "":
+			c.Status, c.Message = IntegritySkipped, "no digest was recorded for this revision"
+		case err != nil:
+			c.Status, c.Message = IntegrityFailed, err.Error()
+		case d.recorded != d.computed:
+			c.Status = IntegrityFailed
+			c.Message = fmt.Sprintf("digest mismatch: recorded %s, computed %s", d.recorded, d.computed)
+		default:
+			c.Status, c.Message = IntegrityOK, d.recorded
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// chartSourceCheck pulls the chart of a release from the repository or
+// registry it came from, and compares the digest of the archive with the one
+// recorded when the release was created.
+func (v *VerifyRelease) chartSourceCheck(rel *release.Release, settings *cli.EnvSettings) *IntegrityCheck {
+	c := &IntegrityCheck{Check: IntegrityCheckChartSource}
+	switch {
+	case rel.Integrity == nil || rel.Integrity.ChartSource == "":
+		c.Status, c.Message = IntegritySkipped, "the chart was not pulled from a repository or registry"
+		return c
+	case v.SkipChartSource:
+		c.Status, c.Message = IntegritySkipped, "disabled"
+		return c
+	}
+	c.Subject = rel.Integrity.ChartSource
+
+	var version string
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		version = rel.Chart.Metadata.Version
+	}
+	digest, err := v.pullChartDigest(rel.Integrity.ChartSource, version, settings)
+	switch {
+	case err != nil:
+		c.Status, c.Message = IntegrityFailed, fmt.Sprintf("unable to pull the chart: %s", err)
+	case digest != rel.Integrity.ChartArchive:
+		c.Status = IntegrityFailed
+		c.Message = fmt.Sprintf("digest mismatch: recorded %s, source has %s", rel.Integrity.ChartArchive, digest)
+	default:
+		c.Status, c.Message = IntegrityOK, digest
+	}
+	return c
+}
+
+// pullChartDigest pulls a chart into an empty cache, so that it is fetched
+// from its source, and returns the digest of the archive.
+func (v *VerifyRelease) pullChartDigest(ref, version string, settings *cli.EnvSettings) (string, error) {
+	cache, err := os.MkdirTemp("", "helm-verify-release-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(cache)
+
+	dl := v.chartDownloader(ref, settings)
+	dl.Cache = &downloader.DiskCache{Root: cache}
+	filename, _, err := dl.DownloadToCache(ref, version)
+	if err != nil {
+		return "", err
+	}
+	digest, err := provenance.DigestFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + digest, nil
+}
+
+// resourceChecks checks that the resources of the manifest of a release exist
+// in the cluster and carry the ownership metadata of the release.
+func (v *VerifyRelease) resourceChecks(rel *release.Release) []*IntegrityCheck {
+	resources, err := v.cfg.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return []*IntegrityCheck{{
+			Check:   IntegrityCheckResource,
+			Status:  IntegrityFailed,
+			Message: fmt.Sprintf("unable to build kubernetes objects from release manifest: %s", err),
+		}}
+	}
+
+	var checks []*IntegrityCheck
+	_ = resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		c := &IntegrityCheck{Check: IntegrityCheckResource, Subject: resourceString(info), Status: IntegrityOK}
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		live, err := helper.Get(info.Namespace, info.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			c.Status, c.Message = IntegrityFailed, "not found in the cluster"
+		case err != nil:
+			c.Status, c.Message = IntegrityFailed, err.Error()
+		default:
+			if err := checkOwnership(live, rel.Name, rel.Namespace); err != nil {
+				c.Status, c.Message = IntegrityFailed, err.Error()
+			}
+		}
+		checks = append(checks, c)
+		return nil
+	})
+	return checks
+}
+
+// releaseIntegrity computes the digests of the stored parts of a release.
+func releaseIntegrity(rel *release.Release) (*release.Integrity, error) {
+	chartDigest, err := jsonDigest(rel.Chart)
+	if err != nil {
+		return nil, err
+	}
+	valuesDigest, err := jsonDigest(rel.Config)
+	if err != nil {
+		return nil, err
+	}
+	// Only the paths and manifests of the hooks are digested, as the rest
+	// changes when they run.
+	type hookContent struct {
+		Path     string `json:"path"`
+		Manifest string `json:"manifest"`
+	}
+	hooks := make([]hookContent, 0, len(rel.Hooks))
+	for _, h := range rel.Hooks {
+		hooks = append(hooks, hookContent{Path: h.Path, Manifest: h.Manifest})
+	}
+	hooksDigest, err := jsonDigest(hooks)
+	if err != nil {
+		return nil, err
+	}
+	manifest := sha256.Sum256([]byte(rel.Manifest))
+	return &release.Integrity{
+		Chart:    "sha256:" + chartDigest,
+		Values:   "sha256:" + valuesDigest,
+		Manifest: "sha256:" + hex.EncodeToString(manifest[:]),
+		Hooks:    "sha256:" + hooksDigest,
+	}, nil
+}
+
+// recordIntegrity records the digests of a release before it is stored, along
+// with the source of its chart, if the chart was pulled by LocateChart, and
+// the digest of the chart tree computed by chartTreeDigest.
+func recordIntegrity(rel *release.Release, source, archiveDigest, treeDigest string) error {
+	integrity, err := releaseIntegrity(rel)
+	if err != nil {
+		return err
+	}
+	integrity.ChartTree = treeDigest
+	integrity.ChartSource = source
+	integrity.ChartArchive = archiveDigest
+	rel.Integrity = integrity
+	return nil
+}
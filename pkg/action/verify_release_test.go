@@ -0,0 +1,117 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func checkStatuses(checks []*IntegrityCheck) map[string]IntegrityStatus {
+	statuses := make(map[string]IntegrityStatus, len(checks))
+	for _, c := range checks {
+		statuses[c.Check] = c.Status
+	}
+	return statuses
+}
+
+func TestVerifyRelease(t *testing.T) {
+	instAction := installAction(t)
+	vals := map[string]interface{}{"name": "value"}
+	res, err := instAction.Run(buildChart(withSampleTemplates()), vals)
+	require.NoError(t, err)
+	require.NotNil(t, res.Integrity)
+	assert.Empty(t, res.Integrity.ChartSource, "a chart not pulled by LocateChart has no source")
+
+	verify := NewVerifyRelease(instAction.cfg)
+	checks, err := verify.Run(res.Name, cli.New())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]IntegrityStatus{
+		IntegrityCheckChart:       IntegrityOK,
+		IntegrityCheckValues:      IntegrityOK,
+		IntegrityCheckManifest:    IntegrityOK,
+		IntegrityCheckHooks:       IntegrityOK,
+		IntegrityCheckChartSource: IntegritySkipped,
+	}, checkStatuses(checks))
+
+	// Tamper with the stored release.
+	rel, err := instAction.cfg.Releases.Get(res.Name, res.Version)
+	require.NoError(t, err)
+	rel.Manifest += "\n---\nkind: Secret\n"
+	rel.Config["name"] = "tampered"
+	require.NoError(t, instAction.cfg.Releases.Update(rel))
+
+	checks, err = verify.Run(res.Name, cli.New())
+	require.NoError(t, err)
+	statuses := checkStatuses(checks)
+	assert.Equal(t, IntegrityOK, statuses[IntegrityCheckChart])
+	assert.Equal(t, IntegrityFailed, statuses[IntegrityCheckValues])
+	assert.Equal(t, IntegrityFailed, statuses[IntegrityCheckManifest])
+	assert.Equal(t, IntegrityOK, statuses[IntegrityCheckHooks])
+}
+
+func TestVerifyReleaseWithoutDigests(t *testing.T) {
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	require.NoError(t, config.Releases.Create(rel))
+
+	checks, err := NewVerifyRelease(config).Run(rel.Name, cli.New())
+	require.NoError(t, err)
+	for _, c := range checks {
+		assert.Equal(t, IntegritySkipped, c.Status, c.Check)
+	}
+}
+
+func TestVerifyReleaseChartSource(t *testing.T) {
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	require.NoError(t, recordIntegrity(rel, "https://charts.example.com/hello-0.1.0.tgz", "sha256:abc", ""))
+	require.NoError(t, config.Releases.Create(rel))
+
+	verify := NewVerifyRelease(config)
+	verify.SkipChartSource = true
+	checks, err := verify.Run(rel.Name, cli.New())
+	require.NoError(t, err)
+	assert.Equal(t, IntegritySkipped, checkStatuses(checks)[IntegrityCheckChartSource])
+}
+
+func TestRollbackRecordsIntegrity(t *testing.T) {
+	config := actionConfigFixture(t)
+	rel := releaseStub()
+	rel.Info.Status = release.StatusSuperseded
+	require.NoError(t, recordIntegrity(rel, "oci://example.com/charts/hello", "sha256:abc", ""))
+	require.NoError(t, config.Releases.Create(rel))
+	cur := releaseStub()
+	cur.Version = 2
+	require.NoError(t, config.Releases.Create(cur))
+
+	rb := NewRollback(config)
+	rb.Version = 1
+	rb.ServerSideApply = "auto"
+	require.NoError(t, rb.Run(rel.Name))
+
+	rolledBack, err := config.Releases.Get(rel.Name, 3)
+	require.NoError(t, err)
+	require.NotNil(t, rolledBack.Integrity)
+	assert.Equal(t, "oci://example.com/charts/hello", rolledBack.Integrity.ChartSource)
+	assert.Equal(t, rel.Integrity.Manifest, rolledBack.Integrity.Manifest)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package bench measures the performance of the render and install pipeline.
+
+It generates representative charts (see Fixtures), runs the stages of the
+pipeline on them as Cases, and checks the measured Results against
+Thresholds, so that performance work on the engine, the Kubernetes client
+and the storage has a baseline and regressions can be caught in CI.
+*/
+package bench // import "helm.sh/helm/v4/pkg/bench"
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Case is a measured operation.
+type Case struct {
+	// Name identifies the case, such as "render/large".
+	Name string
+	// Setup prepares the case and returns the operation to measure. The
+	// work done by Setup itself is not measured.
+	Setup func() (func() error, error)
+}
+
+// Result is the measurement of a case.
+type Result struct {
+	Name        string `json:"name"`
+	Iterations  int    `json:"iterations"`
+	NsPerOp     int64  `json:"nsPerOp"`
+	AllocsPerOp int64  `json:"allocsPerOp"`
+	BytesPerOp  int64  `json:"bytesPerOp"`
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("%s\t%d\t%s/op\t%d allocs/op\t%d B/op", r.Name, r.Iterations, time.Duration(r.NsPerOp), r.AllocsPerOp, r.BytesPerOp)
+}
+
+// Measure runs the operation of the case the given number of times and
+// returns the average time and allocations per run.
+func Measure(c Case, iterations int) (Result, error) {
+	if iterations < 1 {
+		return Result{}, fmt.Errorf("%s: iterations must be at least 1, got %d", c.Name, iterations)
+	}
+	op, err := c.Setup()
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: setup failed: %w", c.Name, err)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		if err := op(); err != nil {
+			return Result{}, fmt.Errorf("%s: %w", c.Name, err)
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	n := int64(iterations)
+	return Result{
+		Name:        c.Name,
+		Iterations:  iterations,
+		NsPerOp:     elapsed.Nanoseconds() / n,
+		AllocsPerOp: int64(after.Mallocs-before.Mallocs) / n,
+		BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / n,
+	}, nil
+}
+
+// Run measures each of the cases.
+func Run(cases []Case, iterations int) ([]Result, error) {
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		r, err := Measure(c, iterations)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bench
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+var thresholdsFile = flag.String("thresholds", "", "check the suite against the thresholds in this JSON file")
+
+func BenchmarkSuite(b *testing.B) {
+	for _, c := range Suite() {
+		b.Run(c.Name, func(b *testing.B) {
+			op, err := c.Setup()
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := op(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestSuite runs every case once, so that the cases keep working as the
+// pipeline changes.
+func TestSuite(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the benchmark suite in short mode")
+	}
+	results, err := Run(Suite(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Iterations != 1 || r.NsPerOp <= 0 || r.AllocsPerOp <= 0 || r.BytesPerOp <= 0 {
+			t.Errorf("Unexpected result %s", r)
+		}
+	}
+}
+
+// TestThresholds checks the suite against the thresholds given with
+// -thresholds, such as testdata/thresholds.json in CI.
+func TestThresholds(t *testing.T) {
+	if *thresholdsFile == "" {
+		t.Skip("no thresholds given with -thresholds")
+	}
+	thresholds, err := LoadThresholds(*thresholdsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Run(Suite(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		t.Log(r)
+	}
+	if err := thresholds.Check(results); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	var runs int
+	r, err := Measure(Case{
+		Name: "count",
+		Setup: func() (func() error, error) {
+			return func() error {
+				runs++
+				_ = make([]byte, 1024)
+				return nil
+			}, nil
+		},
+	}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs != 10 || r.Name != "count" || r.Iterations != 10 {
+		t.Errorf("Unexpected result %s after %d runs", r, runs)
+	}
+
+	if _, err := Measure(Case{Name: "count"}, 0); err == nil {
+		t.Error("Expected an error for no iterations")
+	}
+	_, err = Measure(Case{
+		Name:  "failing",
+		Setup: func() (func() error, error) { return nil, errors.New("boom") },
+	}, 1)
+	if err == nil || err.Error() != "failing: setup failed: boom" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestThresholdsCheck(t *testing.T) {
+	thresholds := Thresholds{
+		"render/small": {MaxNsPerOp: 1000, MaxAllocsPerOp: 10, MaxBytesPerOp: 100},
+		"render/large": {MaxAllocsPerOp: 100},
+	}
+	results := []Result{
+		{Name: "render/small", NsPerOp: 2000, AllocsPerOp: 5, BytesPerOp: 200},
+		{Name: "render/large", NsPerOp: 1e9, AllocsPerOp: 100, BytesPerOp: 1e9},
+		{Name: "unchecked", NsPerOp: 1e9},
+	}
+	err := thresholds.Check(results)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	expect := "render/small: 2µs/op exceeds the threshold of 1µs/op\nrender/small: 200 B/op exceeds the threshold of 100 B/op"
+	if err.Error() != expect {
+		t.Errorf("Expected %q, got %q", expect, err)
+	}
+
+	if err := thresholds.Check(results[1:]); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestLookupFixture(t *testing.T) {
+	f, err := LookupFixture("large")
+	if err != nil || f.Components != 100 {
+		t.Errorf("Unexpected fixture %+v, %v", f, err)
+	}
+	_, err = LookupFixture("huge")
+	if err == nil || !strings.Contains(err.Error(), "valid fixtures are: small, large, umbrella, large-values") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
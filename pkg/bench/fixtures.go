@@ -0,0 +1,183 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bench
+
+import (
+	"fmt"
+	"strings"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// Fixture describes a generated chart. Fixtures are generated instead of kept
+// as files so that their size can be tuned without bloating the repository.
+type Fixture struct {
+	// Name identifies the fixture in case names.
+	Name string
+	// Components is the number of workloads in each chart. Every component
+	// renders a Deployment, a Service and a ConfigMap.
+	Components int
+	// Subcharts is the number of subcharts of the chart, each with the same
+	// number of components.
+	Subcharts int
+	// EnvVars is the number of environment variables of every container,
+	// which sets the size of the values.
+	EnvVars int
+}
+
+// Fixtures are representative charts, from a typical application chart to
+// large umbrella charts.
+var Fixtures = []Fixture{
+	{Name: "small", Components: 1, EnvVars: 5},
+	{Name: "large", Components: 100, EnvVars: 20},
+	{Name: "umbrella", Components: 5, Subcharts: 20, EnvVars: 10},
+	{Name: "large-values", Components: 10, EnvVars: 500},
+}
+
+// LookupFixture returns the fixture with the given name.
+func LookupFixture(name string) (Fixture, error) {
+	names := make([]string, 0, len(Fixtures))
+	for _, f := range Fixtures {
+		if f.Name == name {
+			return f, nil
+		}
+		names = append(names, f.Name)
+	}
+	return Fixture{}, fmt.Errorf("unknown fixture %q, valid fixtures are: %s", name, strings.Join(names, ", "))
+}
+
+// Chart generates the chart of the fixture.
+func (f Fixture) Chart() (*chart.Chart, error) {
+	c, err := f.chart(f.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < f.Subcharts; i++ {
+		sub, err := f.chart(fmt.Sprintf("sub%d", i))
+		if err != nil {
+			return nil, err
+		}
+		c.AddDependency(sub)
+	}
+	return c, nil
+}
+
+func (f Fixture) chart(name string) (*chart.Chart, error) {
+	b := ci.NewChartBuilder().
+		SetMetadata(&chart.Metadata{Name: name, Version: "1.0.0", AppVersion: "1.0.0"}).
+		AddTemplate("_helpers.tpl", []byte(strings.ReplaceAll(fixtureHelpers, "<CHARTNAME>", name))).
+		AddTemplate("NOTES.txt", []byte("{{ .Chart.Name }} has {{ len .Values.components }} components.\n"))
+
+	components := make(map[string]interface{}, f.Components)
+	for i := 0; i < f.Components; i++ {
+		key := fmt.Sprintf("component%d", i)
+		env := make(map[string]interface{}, f.EnvVars)
+		for j := 0; j < f.EnvVars; j++ {
+			env[fmt.Sprintf("VAR_%d", j)] = fmt.Sprintf("value-%d-%d", i, j)
+		}
+		components[key] = map[string]interface{}{
+			"enabled":      true,
+			"replicaCount": 2,
+			"image":        map[string]interface{}{"repository": "registry.example.com/" + key, "tag": "1.0.0"},
+			"port":         8080 + i,
+			"env":          env,
+			"resources": map[string]interface{}{
+				"limits":   map[string]interface{}{"cpu": "500m", "memory": "256Mi"},
+				"requests": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+			},
+		}
+		tpl := strings.ReplaceAll(fixtureComponent, "<CHARTNAME>", name)
+		b.AddTemplate(key+".yaml", []byte(strings.ReplaceAll(tpl, "<COMPONENT>", key)))
+	}
+	return b.AddValues(map[string]interface{}{
+		"components": components,
+		"podLabels":  map[string]interface{}{"team": "platform", "tier": "backend"},
+	}).Build()
+}
+
+const fixtureHelpers = `{{- define "<CHARTNAME>.fullname" -}}
+{{- printf "%s-%s" .Release.Name .Chart.Name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{- define "<CHARTNAME>.labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- with .Values.podLabels }}
+{{ toYaml . }}
+{{- end }}
+{{- end }}
+`
+
+const fixtureComponent = `{{- $c := index .Values.components "<COMPONENT>" }}
+{{- if $c.enabled }}
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}-<COMPONENT>
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  replicas: {{ $c.replicaCount }}
+  selector:
+    matchLabels:
+      app.kubernetes.io/component: <COMPONENT>
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/component: <COMPONENT>
+        {{- include "<CHARTNAME>.labels" . | nindent 8 }}
+      annotations:
+        checksum/config: {{ toYaml $c.env | sha256sum }}
+    spec:
+      containers:
+        - name: <COMPONENT>
+          image: "{{ $c.image.repository }}:{{ $c.image.tag | default .Chart.AppVersion }}"
+          ports:
+            - containerPort: {{ $c.port }}
+          envFrom:
+            - configMapRef:
+                name: {{ include "<CHARTNAME>.fullname" . }}-<COMPONENT>
+          resources:
+            {{- toYaml $c.resources | nindent 12 }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}-<COMPONENT>
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  ports:
+    - port: 80
+      targetPort: {{ $c.port }}
+  selector:
+    app.kubernetes.io/component: <COMPONENT>
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}-<COMPONENT>
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+data:
+  {{- range $key, $value := $c.env }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+{{- end }}
+`
@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/engine"
+	kubefake "helm.sh/helm/v4/pkg/kube/fake"
+	"helm.sh/helm/v4/pkg/storage"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// HistoryRevisions is the number of revisions stored by the storage cases.
+const HistoryRevisions = 20
+
+// Suite returns the standard cases: rendering and installing every fixture,
+// and reading the history of a small and a large release from storage.
+func Suite() []Case {
+	var cases []Case
+	for _, f := range Fixtures {
+		cases = append(cases, RenderCase(f), InstallCase(f))
+	}
+	for _, f := range Fixtures[:2] {
+		cases = append(cases, HistoryCase(f))
+	}
+	return cases
+}
+
+// RenderCase renders the templates of the fixture with the engine. The values
+// are prepared once, so only the engine is measured.
+func RenderCase(f Fixture) Case {
+	return Case{
+		Name: "render/" + f.Name,
+		Setup: func() (func() error, error) {
+			c, err := f.Chart()
+			if err != nil {
+				return nil, err
+			}
+			vals, err := util.ToRenderValues(c, nil, common.ReleaseOptions{Name: "bench", Namespace: "default", IsInstall: true}, nil)
+			if err != nil {
+				return nil, err
+			}
+			return func() error {
+				_, err := engine.Render(c, vals)
+				return err
+			}, nil
+		},
+	}
+}
+
+// InstallCase installs the fixture with a fake Kubernetes client and an
+// in-memory storage, which measures the whole pipeline from the values to
+// the stored release without a cluster.
+func InstallCase(f Fixture) Case {
+	return Case{
+		Name: "install/" + f.Name,
+		Setup: func() (func() error, error) {
+			c, err := f.Chart()
+			if err != nil {
+				return nil, err
+			}
+			return func() error {
+				install := action.NewInstall(newConfiguration(storage.Init(driver.NewMemory())))
+				install.ReleaseName = "bench"
+				install.Namespace = "default"
+				_, err := install.RunWithContext(context.Background(), c, nil)
+				return err
+			}, nil
+		},
+	}
+}
+
+// HistoryCase reads the history of a release of the fixture with
+// HistoryRevisions revisions from Secrets, the default storage driver, which
+// measures decoding the stored releases.
+func HistoryCase(f Fixture) Case {
+	return Case{
+		Name: "history/" + f.Name,
+		Setup: func() (func() error, error) {
+			c, err := f.Chart()
+			if err != nil {
+				return nil, err
+			}
+			secrets := driver.NewSecrets(fake.NewClientset().CoreV1().Secrets("default"))
+			cfg := newConfiguration(storage.Init(secrets))
+			install := action.NewInstall(cfg)
+			install.ReleaseName = "bench"
+			install.Namespace = "default"
+			if _, err := install.RunWithContext(context.Background(), c, nil); err != nil {
+				return nil, err
+			}
+			for i := 1; i < HistoryRevisions; i++ {
+				upgrade := action.NewUpgrade(cfg)
+				upgrade.Namespace = "default"
+				if _, err := upgrade.RunWithContext(context.Background(), "bench", c, nil); err != nil {
+					return nil, err
+				}
+			}
+			return func() error {
+				history, err := cfg.Releases.History("bench")
+				if err != nil {
+					return err
+				}
+				if len(history) != HistoryRevisions {
+					return fmt.Errorf("expected %d revisions, got %d", HistoryRevisions, len(history))
+				}
+				return nil
+			}, nil
+		},
+	}
+}
+
+func newConfiguration(releases *storage.Storage) *action.Configuration {
+	return &action.Configuration{
+		Releases:     releases,
+		KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+		Capabilities: common.DefaultCapabilities,
+	}
+}
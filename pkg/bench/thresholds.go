@@ -0,0 +1,74 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Threshold is the maximum cost of a case. Zero fields are not checked.
+//
+// Allocations are mostly independent of the machine, so they make the most
+// reliable thresholds. Times vary between machines and should leave ample
+// headroom.
+type Threshold struct {
+	MaxNsPerOp     int64 `json:"maxNsPerOp,omitempty"`
+	MaxAllocsPerOp int64 `json:"maxAllocsPerOp,omitempty"`
+	MaxBytesPerOp  int64 `json:"maxBytesPerOp,omitempty"`
+}
+
+// Thresholds are the thresholds of cases by name.
+type Thresholds map[string]Threshold
+
+// LoadThresholds reads thresholds from a JSON file.
+func LoadThresholds(path string) (Thresholds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Thresholds
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("cannot load thresholds from %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Check returns an error for every result exceeding its threshold. Results
+// without a threshold are not checked.
+func (t Thresholds) Check(results []Result) error {
+	var errs []error
+	for _, r := range results {
+		th, ok := t[r.Name]
+		if !ok {
+			continue
+		}
+		if th.MaxNsPerOp > 0 && r.NsPerOp > th.MaxNsPerOp {
+			errs = append(errs, fmt.Errorf("%s: %s/op exceeds the threshold of %s/op", r.Name, time.Duration(r.NsPerOp), time.Duration(th.MaxNsPerOp)))
+		}
+		if th.MaxAllocsPerOp > 0 && r.AllocsPerOp > th.MaxAllocsPerOp {
+			errs = append(errs, fmt.Errorf("%s: %d allocs/op exceeds the threshold of %d allocs/op", r.Name, r.AllocsPerOp, th.MaxAllocsPerOp))
+		}
+		if th.MaxBytesPerOp > 0 && r.BytesPerOp > th.MaxBytesPerOp {
+			errs = append(errs, fmt.Errorf("%s: %d B/op exceeds the threshold of %d B/op", r.Name, r.BytesPerOp, th.MaxBytesPerOp))
+		}
+	}
+	return errors.Join(errs...)
+}
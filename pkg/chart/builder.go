@@ -0,0 +1,189 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	v2chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+// ChartBuilder assembles a chart in code, for programs that generate charts
+// instead of loading them from disk. Its methods can be chained:
+//
+//	c, err := chart.NewChartBuilder().
+//		SetMetadata(&v2chart.Metadata{Name: "mychart", Version: "0.1.0"}).
+//		AddTemplate("configmap.yaml", data).
+//		AddValuesFile(values).
+//		Build()
+//
+// Errors from any step are collected and returned by Build. The chart is
+// built from the files the same way a chart is loaded from an archive, so it
+// has the same values, raw files and validation as a loaded chart.
+type ChartBuilder struct { //nolint:revive
+	metadata     *v2chart.Metadata
+	values       [][]byte
+	schema       []byte
+	files        []*loader.BufferedFile
+	names        map[string]bool
+	dependencies []*v2chart.Chart
+	errs         []error
+}
+
+// NewChartBuilder creates an empty ChartBuilder.
+func NewChartBuilder() *ChartBuilder {
+	return &ChartBuilder{names: make(map[string]bool)}
+}
+
+// SetMetadata sets the contents of the Chart.yaml of the chart. The API
+// version defaults to v2.
+func (b *ChartBuilder) SetMetadata(md *v2chart.Metadata) *ChartBuilder {
+	b.metadata = md
+	return b
+}
+
+// AddTemplate adds a template. The name is relative to the templates/
+// directory, such as "deployment.yaml" or "_helpers.tpl".
+func (b *ChartBuilder) AddTemplate(name string, data []byte) *ChartBuilder {
+	return b.add("template", path.Join("templates", name), name, data)
+}
+
+// AddValuesFile adds the default values of the chart as YAML. Values files
+// added later override the values of earlier ones, as the documents of a
+// values.yaml do.
+func (b *ChartBuilder) AddValuesFile(data []byte) *ChartBuilder {
+	b.values = append(b.values, data)
+	return b
+}
+
+// AddValues adds default values of the chart, as AddValuesFile does.
+func (b *ChartBuilder) AddValues(values map[string]interface{}) *ChartBuilder {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("cannot encode values: %w", err))
+		return b
+	}
+	return b.AddValuesFile(data)
+}
+
+// SetSchema sets the JSON schema the values of the chart are validated with.
+func (b *ChartBuilder) SetSchema(data []byte) *ChartBuilder {
+	b.schema = data
+	return b
+}
+
+// AddFile adds a file that is not a template, such as a README or a CRD in
+// crds/. The chart metadata, values, schema and templates have their own
+// methods.
+func (b *ChartBuilder) AddFile(name string, data []byte) *ChartBuilder {
+	switch {
+	case name == "Chart.yaml" || name == "values.yaml" || name == "values.schema.json":
+		b.errs = append(b.errs, fmt.Errorf("file %q is built from the chart, use the builder methods to set it", name))
+		return b
+	case strings.HasPrefix(name, "templates/"):
+		b.errs = append(b.errs, fmt.Errorf("file %q is a template, use AddTemplate to add it", name))
+		return b
+	}
+	return b.add("file", name, name, data)
+}
+
+// AddDependency adds charts as subcharts of the chart.
+func (b *ChartBuilder) AddDependency(charts ...*v2chart.Chart) *ChartBuilder {
+	for _, c := range charts {
+		if c == nil {
+			b.errs = append(b.errs, errors.New("dependency must not be nil"))
+			continue
+		}
+		b.dependencies = append(b.dependencies, c)
+	}
+	return b
+}
+
+func (b *ChartBuilder) add(kind, name, given string, data []byte) *ChartBuilder {
+	if given == "" || path.IsAbs(given) || path.Clean(given) != given || given == ".." || strings.HasPrefix(given, "../") {
+		b.errs = append(b.errs, fmt.Errorf("invalid %s name %q, it must be a clean relative path", kind, given))
+		return b
+	}
+	if b.names[name] {
+		b.errs = append(b.errs, fmt.Errorf("duplicate %s %q", kind, name))
+		return b
+	}
+	b.names[name] = true
+	b.files = append(b.files, &loader.BufferedFile{Name: name, Data: data})
+	return b
+}
+
+// Build creates the chart. It returns the errors of all the steps, or an
+// error if the chart is not valid.
+func (b *ChartBuilder) Build() (*v2chart.Chart, error) {
+	errs := b.errs
+	if b.metadata == nil {
+		errs = append(errs, errors.New("chart metadata is required"))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	md := *b.metadata
+	if md.APIVersion == "" {
+		md.APIVersion = v2chart.APIVersionV2
+	}
+	chartfile, err := yaml.Marshal(&md)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode chart metadata: %w", err)
+	}
+
+	files := []*loader.BufferedFile{{Name: "Chart.yaml", Data: chartfile}}
+	if len(b.values) > 0 {
+		files = append(files, &loader.BufferedFile{Name: "values.yaml", Data: joinDocuments(b.values)})
+	}
+	if b.schema != nil {
+		files = append(files, &loader.BufferedFile{Name: "values.schema.json", Data: b.schema})
+	}
+	files = append(files, b.files...)
+
+	c, err := loader.LoadFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	for _, dep := range b.dependencies {
+		c.AddDependency(dep)
+	}
+	return c, nil
+}
+
+// joinDocuments joins YAML files into a single stream of documents.
+func joinDocuments(docs [][]byte) []byte {
+	var buf bytes.Buffer
+	for i, doc := range docs {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(doc)
+		if len(doc) > 0 && doc[len(doc)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
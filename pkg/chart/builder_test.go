@@ -0,0 +1,142 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chart
+
+import (
+	"strings"
+	"testing"
+
+	v2chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestChartBuilder(t *testing.T) {
+	sub, err := NewChartBuilder().
+		SetMetadata(&v2chart.Metadata{Name: "sub", Version: "1.0.0"}).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := &v2chart.Metadata{Name: "mychart", Version: "0.1.0", AppVersion: "1.16.0"}
+	c, err := NewChartBuilder().
+		SetMetadata(md).
+		AddTemplate("configmap.yaml", []byte("kind: ConfigMap\n")).
+		AddTemplate("_helpers.tpl", []byte(`{{ define "mychart.name" }}mychart{{ end }}`)).
+		AddValuesFile([]byte("replicaCount: 1\nimage:\n  repository: nginx\n  tag: stable")).
+		AddValues(map[string]interface{}{"image": map[string]interface{}{"tag": "1.25"}}).
+		SetSchema([]byte(`{"type": "object"}`)).
+		AddFile("README.md", []byte("# mychart\n")).
+		AddFile("crds/crd.yaml", []byte("kind: CustomResourceDefinition\n")).
+		AddDependency(sub).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Metadata.APIVersion != v2chart.APIVersionV2 {
+		t.Errorf("Expected the API version to default to v2, got %q", c.Metadata.APIVersion)
+	}
+	if md.APIVersion != "" {
+		t.Error("Expected the given metadata to be left unchanged")
+	}
+	if c.Name() != "mychart" || c.AppVersion() != "1.16.0" {
+		t.Errorf("Unexpected metadata %+v", c.Metadata)
+	}
+	if len(c.Templates) != 2 || c.Templates[0].Name != "templates/configmap.yaml" || c.Templates[1].Name != "templates/_helpers.tpl" {
+		t.Errorf("Unexpected templates %v", c.Templates)
+	}
+	image := c.Values["image"].(map[string]interface{})
+	if c.Values["replicaCount"] != 1.0 || image["repository"] != "nginx" || image["tag"] != "1.25" {
+		t.Errorf("Unexpected values %v", c.Values)
+	}
+	if string(c.Schema) != `{"type": "object"}` {
+		t.Errorf("Unexpected schema %s", c.Schema)
+	}
+	if len(c.Files) != 2 || len(c.CRDObjects()) != 1 {
+		t.Errorf("Unexpected files %v", c.Files)
+	}
+	if len(c.Dependencies()) != 1 || c.Dependencies()[0].Parent() != c {
+		t.Errorf("Unexpected dependencies %v", c.Dependencies())
+	}
+
+	var raw []string
+	for _, f := range c.Raw {
+		raw = append(raw, f.Name)
+	}
+	if got := strings.Join(raw, ","); got != "Chart.yaml,values.yaml,values.schema.json,templates/configmap.yaml,templates/_helpers.tpl,README.md,crds/crd.yaml" {
+		t.Errorf("Unexpected raw files %s", got)
+	}
+}
+
+func TestChartBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *ChartBuilder
+		errs    []string
+	}{
+		{
+			name:    "no metadata",
+			builder: NewChartBuilder(),
+			errs:    []string{"chart metadata is required"},
+		},
+		{
+			name:    "invalid metadata",
+			builder: NewChartBuilder().SetMetadata(&v2chart.Metadata{Name: "mychart"}),
+			errs:    []string{"chart.metadata.version is required"},
+		},
+		{
+			name: "invalid files",
+			builder: NewChartBuilder().
+				SetMetadata(&v2chart.Metadata{Name: "mychart", Version: "0.1.0"}).
+				AddTemplate("a.yaml", nil).
+				AddTemplate("a.yaml", nil).
+				AddTemplate("../escape.yaml", nil).
+				AddFile("/etc/passwd", nil).
+				AddFile("values.yaml", nil).
+				AddFile("templates/b.yaml", nil).
+				AddDependency(nil),
+			errs: []string{
+				`duplicate template "templates/a.yaml"`,
+				`invalid template name "../escape.yaml"`,
+				`invalid file name "/etc/passwd"`,
+				`file "values.yaml" is built from the chart`,
+				`file "templates/b.yaml" is a template`,
+				"dependency must not be nil",
+			},
+		},
+		{
+			name: "invalid values",
+			builder: NewChartBuilder().
+				SetMetadata(&v2chart.Metadata{Name: "mychart", Version: "0.1.0"}).
+				AddValuesFile([]byte("- not\n- a map\n")),
+			errs: []string{"cannot load values.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			for _, want := range tt.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected %q in error %q", want, err)
+				}
+			}
+		})
+	}
+}
@@ -34,6 +34,20 @@ type ErrNoValue struct {
 
 func (e ErrNoValue) Error() string { return fmt.Sprintf("%q is not a value", e.Key) }
 
+// ErrValueType indicates that a value of Values does not have the expected type.
+type ErrValueType struct {
+	Path     string
+	Expected string
+	Value    interface{}
+}
+
+func (e ErrValueType) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("%q: expected %s, got null", e.Path, e.Expected)
+	}
+	return fmt.Sprintf("%q: expected %s, got %T", e.Path, e.Expected, e.Value)
+}
+
 type ErrInvalidChartName struct {
 	Name string
 }
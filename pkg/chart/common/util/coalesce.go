@@ -43,12 +43,19 @@ func concatPrefix(a, b string) string {
 //   - Scalar values and arrays are replaced, maps are merged
 //   - A chart has access to all of the variables for it, as well as all of
 //     the values destined for its dependencies.
+//   - A table or list marked with a common.PatchKey directive is merged as
+//     the directive says, and the directives are removed from the result.
 func CoalesceValues(chrt chart.Charter, vals map[string]interface{}) (common.Values, error) {
 	valsCopy, err := copyValues(vals)
 	if err != nil {
 		return vals, err
 	}
-	return coalesce(log.Printf, chrt, valsCopy, "", false)
+	coalesced, err := coalesce(log.Printf, chrt, valsCopy, "", false)
+	if err != nil {
+		return coalesced, err
+	}
+	common.StripPatches(coalesced)
+	return coalesced, nil
 }
 
 // MergeValues is used to merge the values in a chart and its subcharts. This
@@ -65,6 +72,7 @@ func CoalesceValues(chrt chart.Charter, vals map[string]interface{}) (common.Val
 // Retaining Nils is useful when processes early in a Helm action or business
 // logic need to retain them for when Coalescing will happen again later in the
 // business logic.
+// Patch directives are retained for the same reason.
 func MergeValues(chrt chart.Charter, vals map[string]interface{}) (common.Values, error) {
 	valsCopy, err := copyValues(vals)
 	if err != nil {
@@ -233,13 +241,16 @@ func coalesceValues(printf printFn, c chart.Charter, v map[string]interface{}, p
 
 	for key, val := range vc {
 		if value, ok := v[key]; ok {
-			if value == nil && !merge {
+			if (value == nil || common.IsPatchDelete(value)) && !merge {
 				// When the YAML value is null and we are coalescing instead of
 				// merging, we remove the value's key.
 				// This allows Helm's various sources of values (value files or --set) to
 				// remove incompatible keys from any previous chart, file, or set values.
 				delete(v, key)
-			} else if dest, ok := value.(map[string]interface{}); ok {
+			} else if list, ok := value.([]interface{}); ok {
+				src, _ := val.([]interface{})
+				v[key] = common.PatchList(list, src)
+			} else if dest, ok := value.(map[string]interface{}); ok && !replacesTable(dest) {
 				// if v[key] is a table, merge nv's val table into v[key].
 				src, ok := val.(map[string]interface{})
 				if !ok {
@@ -312,10 +323,15 @@ func coalesceTablesFullKey(printf printFn, dst, src map[string]interface{}, pref
 	// values.
 	for key, val := range src {
 		fullkey := concatPrefix(prefix, key)
-		if dv, ok := dst[key]; ok && !merge && dv == nil {
+		if dv, ok := dst[key]; ok && !merge && (dv == nil || common.IsPatchDelete(dv)) {
 			delete(dst, key)
 		} else if !ok {
 			dst[key] = val
+		} else if list, ok := dv.([]interface{}); ok {
+			src, _ := val.([]interface{})
+			dst[key] = common.PatchList(list, src)
+		} else if dt, ok := dv.(map[string]interface{}); ok && replacesTable(dt) {
+			continue
 		} else if istable(val) {
 			if istable(dv) {
 				coalesceTablesFullKey(printf, dv.(map[string]interface{}), val.(map[string]interface{}), fullkey, merge)
@@ -329,6 +345,13 @@ func coalesceTablesFullKey(printf printFn, dst, src map[string]interface{}, pref
 	return dst
 }
 
+// replacesTable reports whether a table replaces the table it overrides, instead
+// of being merged with it, as set by its patch directive.
+func replacesTable(t map[string]interface{}) bool {
+	d := common.TablePatch(t)
+	return d == common.PatchReplace || d == common.PatchDelete
+}
+
 // istable is a special-purpose function to see if the present thing matches the definition of a YAML table.
 func istable(v interface{}) bool {
 	_, ok := v.(map[string]interface{})
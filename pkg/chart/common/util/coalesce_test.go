@@ -731,3 +731,72 @@ func TestConcatPrefix(t *testing.T) {
 	assert.Equal(t, "b", concatPrefix("", "b"))
 	assert.Equal(t, "a.b", concatPrefix("a", "b"))
 }
+
+func TestCoalesceValuesPatches(t *testing.T) {
+	is := assert.New(t)
+
+	c := withDeps(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "patches"},
+		Values: map[string]interface{}{
+			"resources": map[string]interface{}{
+				"limits":   map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+				"requests": map[string]interface{}{"cpu": "100m"},
+			},
+			"args":        []interface{}{"--port=80"},
+			"env":         []interface{}{"A=1"},
+			"tolerations": []interface{}{"default"},
+			"annotations": map[string]interface{}{"a": "b"},
+		},
+	}, &chart.Chart{
+		Metadata: &chart.Metadata{Name: "sub"},
+		Values: map[string]interface{}{
+			"labels": map[string]interface{}{"team": "core", "tier": "web"},
+			"hosts":  []interface{}{"a.example.com"},
+		},
+	})
+
+	vals := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{
+				common.PatchKey: "replace",
+				"memory":        "2Gi",
+			},
+		},
+		"args":        []interface{}{map[string]interface{}{common.PatchKey: "merge"}, "--verbose"},
+		"env":         []interface{}{"B=2"},
+		"tolerations": []interface{}{map[string]interface{}{common.PatchKey: "delete"}},
+		"annotations": map[string]interface{}{common.PatchKey: "delete"},
+		"extra":       map[string]interface{}{common.PatchKey: "merge", "key": "value"},
+		"sub": map[string]interface{}{
+			"labels": map[string]interface{}{common.PatchKey: "replace", "team": "edge"},
+			"hosts":  []interface{}{map[string]interface{}{common.PatchKey: "merge"}, "b.example.com"},
+		},
+	}
+
+	v, err := CoalesceValues(c, vals)
+	is.NoError(err)
+
+	is.Equal(map[string]interface{}{
+		"limits":   map[string]interface{}{"memory": "2Gi"},
+		"requests": map[string]interface{}{"cpu": "100m"},
+	}, v["resources"])
+	is.Equal([]interface{}{"--port=80", "--verbose"}, v["args"])
+	is.Equal([]interface{}{"B=2"}, v["env"])
+	is.NotContains(v, "tolerations")
+	is.NotContains(v, "annotations")
+	is.Equal(map[string]interface{}{"key": "value"}, v["extra"])
+
+	sub := v["sub"].(map[string]interface{})
+	is.Equal(map[string]interface{}{"team": "edge"}, sub["labels"])
+	is.Equal([]interface{}{"a.example.com", "b.example.com"}, sub["hosts"])
+
+	// Merging keeps the directives, for the values to be coalesced later.
+	m, err := MergeValues(c, vals)
+	is.NoError(err)
+	is.Equal(map[string]interface{}{
+		common.PatchKey: "replace",
+		"memory":        "2Gi",
+	}, m["resources"].(map[string]interface{})["limits"])
+	is.Equal(map[string]interface{}{common.PatchKey: "delete"}, m["annotations"])
+	is.Equal([]interface{}{map[string]interface{}{common.PatchKey: "merge"}, "--port=80", "--verbose"}, m["args"])
+}
@@ -18,15 +18,21 @@ package util
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 
 	"helm.sh/helm/v4/internal/version"
 
@@ -73,19 +79,91 @@ func newHTTPURLLoader() *HTTPURLLoader {
 
 // ValidateAgainstSchema checks that values does not violate the structure laid out in schema
 func ValidateAgainstSchema(ch chart.Charter, values map[string]interface{}) error {
+	var c *SchemaCache
+	return c.ValidateAgainstSchema(ch, values)
+}
+
+// ValidateAgainstSchemaWithSources checks that values does not violate the
+// structure laid out in schema, as ValidateAgainstSchema does, naming in each
+// violation the values file or flag that set the offending value.
+//
+// sources maps the dot-separated paths of the user-supplied values, such as
+// "image.tag", to where they came from.
+func ValidateAgainstSchemaWithSources(ch chart.Charter, values map[string]interface{}, sources map[string]string) error {
+	var c *SchemaCache
+	return c.ValidateAgainstSchemaWithSources(ch, values, sources)
+}
+
+// ValidateAgainstSingleSchema checks that values does not violate the structure laid out in this schema
+func ValidateAgainstSingleSchema(values common.Values, schemaJSON []byte) error {
+	var c *SchemaCache
+	return c.ValidateAgainstSingleSchema(values, schemaJSON)
+}
+
+// SchemaCache keeps compiled JSON schemas, keyed by their content, so that
+// validating against the same schema repeatedly only compiles it once.
+//
+// It is safe for concurrent use. The zero value is ready to use, and a nil
+// *SchemaCache compiles the schema on every validation.
+type SchemaCache struct {
+	mu      sync.Mutex
+	schemas map[[sha256.Size]byte]*jsonschema.Schema
+}
+
+// ValidateAgainstSchema checks that values does not violate the structure laid out in schema
+//
+// The values are validated against the schemas of the chart and all of its
+// subcharts, and by the registered ValuesValidators, and every violation found
+// is reported in a *SchemaViolationsError.
+func (c *SchemaCache) ValidateAgainstSchema(ch chart.Charter, values map[string]interface{}) error {
+	return c.ValidateAgainstSchemaWithSources(ch, values, nil)
+}
+
+// ValidateAgainstSchemaWithSources checks that values does not violate the
+// structure laid out in schema, as ValidateAgainstSchema does, naming in each
+// violation the values file or flag that set the offending value.
+func (c *SchemaCache) ValidateAgainstSchemaWithSources(ch chart.Charter, values map[string]interface{}, sources map[string]string) error {
+	var violations []SchemaViolation
+	if err := c.validateChart(ch, values, nil, sources, &violations); err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &SchemaViolationsError{Violations: violations}
+	}
+	return nil
+}
+
+// validateChart collects the violations of the schemas of a chart and its
+// subcharts, prefix being the location of the values of the chart in the
+// values of the top-level chart.
+func (c *SchemaCache) validateChart(ch chart.Charter, values map[string]interface{}, prefix []string, sources map[string]string, violations *[]SchemaViolation) error {
 	chrt, err := chart.NewAccessor(ch)
 	if err != nil {
 		return err
 	}
-	var sb strings.Builder
+	var found []SchemaViolation
 	if chrt.Schema() != nil {
 		slog.Debug("chart name", "chart-name", chrt.Name())
-		err := ValidateAgainstSingleSchema(values, chrt.Schema())
-		if err != nil {
-			sb.WriteString(fmt.Sprintf("%s:\n", chrt.Name()))
-			sb.WriteString(err.Error())
+		err := c.ValidateAgainstSingleSchema(values, chrt.Schema())
+		var verr JSONSchemaValidationError
+		switch {
+		case errors.As(err, &verr) && len(verr.violations) > 0:
+			found = append(found, verr.violations...)
+		case err != nil:
+			return fmt.Errorf("%s:\n%w", chrt.Name(), err)
 		}
 	}
+	custom, err := runValuesValidators(ch, values)
+	if err != nil {
+		return fmt.Errorf("%s:\n%w", chrt.Name(), err)
+	}
+	for _, v := range append(found, custom...) {
+		tokens := append(append([]string{}, prefix...), v.location...)
+		v.Chart = chrt.Name()
+		v.Pointer = jsonPointer(tokens)
+		v.Source = sourceOf(sources, tokens)
+		*violations = append(*violations, v)
+	}
 	slog.Debug("number of dependencies in the chart", "dependencies", len(chrt.Dependencies()))
 	// For each dependency, recursively call this function with the coalesced values
 	for _, subchart := range chrt.Dependencies() {
@@ -94,31 +172,72 @@ func ValidateAgainstSchema(ch chart.Charter, values map[string]interface{}) erro
 			return err
 		}
 		subchartValues := values[sub.Name()].(map[string]interface{})
-		if err := ValidateAgainstSchema(subchart, subchartValues); err != nil {
-			sb.WriteString(err.Error())
+		subPrefix := append(append([]string{}, prefix...), sub.Name())
+		if err := c.validateChart(subchart, subchartValues, subPrefix, sources, violations); err != nil {
+			return err
 		}
 	}
-
-	if sb.Len() > 0 {
-		return errors.New(sb.String())
-	}
-
 	return nil
 }
 
 // ValidateAgainstSingleSchema checks that values does not violate the structure laid out in this schema
-func ValidateAgainstSingleSchema(values common.Values, schemaJSON []byte) (reterr error) {
+func (c *SchemaCache) ValidateAgainstSingleSchema(values common.Values, schemaJSON []byte) (reterr error) {
 	defer func() {
 		if r := recover(); r != nil {
 			reterr = fmt.Errorf("unable to validate schema: %s", r)
 		}
 	}()
 
+	validator, err := c.compile(schemaJSON)
+	if err != nil {
+		return err
+	}
+
+	err = validator.Validate(values.AsMap())
+	if err != nil {
+		verr := JSONSchemaValidationError{embeddedErr: err}
+		var ve *jsonschema.ValidationError
+		if errors.As(err, &ve) {
+			collectViolations(ve, &verr.violations)
+			// The validator walks objects in no particular order.
+			sort.SliceStable(verr.violations, func(i, j int) bool {
+				return verr.violations[i].Pointer < verr.violations[j].Pointer
+			})
+		}
+		return verr
+	}
+
+	return nil
+}
+
+func (c *SchemaCache) compile(schemaJSON []byte) (*jsonschema.Schema, error) {
+	if c == nil {
+		return compileSchema(schemaJSON)
+	}
+
+	key := sha256.Sum256(schemaJSON)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if validator, ok := c.schemas[key]; ok {
+		return validator, nil
+	}
+	validator, err := compileSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	if c.schemas == nil {
+		c.schemas = make(map[[sha256.Size]byte]*jsonschema.Schema)
+	}
+	c.schemas[key] = validator
+	return validator, nil
+}
+
+func compileSchema(schemaJSON []byte) (*jsonschema.Schema, error) {
 	// This unmarshal function leverages UseNumber() for number precision. The parser
 	// used for values does this as well.
 	schema, err := jsonschema.UnmarshalJSON(bytes.NewReader(schemaJSON))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	slog.Debug("unmarshalled JSON schema", "schema", schemaJSON)
 
@@ -133,20 +252,64 @@ func ValidateAgainstSingleSchema(values common.Values, schemaJSON []byte) (reter
 	compiler.UseLoader(loader)
 	err = compiler.AddResource("file:///values.schema.json", schema)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	validator, err := compiler.Compile("file:///values.schema.json")
-	if err != nil {
-		return err
-	}
+	return compiler.Compile("file:///values.schema.json")
+}
 
-	err = validator.Validate(values.AsMap())
-	if err != nil {
-		return JSONSchemaValidationError{err}
+// SchemaViolation is a single violation of the values schema of a chart.
+type SchemaViolation struct {
+	// Chart is the name of the chart whose schema is violated. It is empty
+	// when a single schema was validated against.
+	Chart string
+	// Pointer is the JSON Pointer of the offending value, such as
+	// "/image/tag", from the top of the values that were validated.
+	Pointer string
+	// Keyword is the schema keyword violated, such as "type" or "required",
+	// or the rule violated for validators other than JSON Schema.
+	Keyword string
+	// Message describes the violation.
+	Message string
+	// Source is the values file or flag that set the offending value, if
+	// known.
+	Source string
+
+	location []string
+}
+
+// String prints the violation as a line of a validation error.
+func (v SchemaViolation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "- at '%s'", v.Pointer)
+	if v.Keyword != "" {
+		fmt.Fprintf(&sb, " [%s]", v.Keyword)
+	}
+	fmt.Fprintf(&sb, ": %s", v.Message)
+	if v.Source != "" {
+		fmt.Fprintf(&sb, " (set by %s)", v.Source)
 	}
+	return sb.String()
+}
 
-	return nil
+// SchemaViolationsError is the error returned when the values of a chart
+// violate the schemas of the chart or of its subcharts. It holds all of the
+// violations found.
+type SchemaViolationsError struct {
+	Violations []SchemaViolation
+}
+
+// Error prints the violations, grouped by the chart whose schema they violate.
+func (e *SchemaViolationsError) Error() string {
+	var sb strings.Builder
+	for i, v := range e.Violations {
+		if i == 0 || v.Chart != e.Violations[i-1].Chart {
+			fmt.Fprintf(&sb, "%s:\n", v.Chart)
+		}
+		sb.WriteString(v.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
 }
 
 // Note, JSONSchemaValidationError is used to wrap the error from the underlying
@@ -157,10 +320,26 @@ func ValidateAgainstSingleSchema(values common.Values, schemaJSON []byte) (reter
 // error.
 type JSONSchemaValidationError struct {
 	embeddedErr error
+	violations  []SchemaViolation
+}
+
+// Violations returns the violations of the schema, the pointers being from the
+// top of the values validated.
+func (e JSONSchemaValidationError) Violations() []SchemaViolation {
+	return e.violations
 }
 
 // Error prints the error message
 func (e JSONSchemaValidationError) Error() string {
+	if len(e.violations) > 0 {
+		var sb strings.Builder
+		for _, v := range e.violations {
+			sb.WriteString(v.String())
+			sb.WriteString("\n")
+		}
+		return sb.String()
+	}
+
 	errStr := e.embeddedErr.Error()
 
 	// This string prefixes all of our error details. Further up the stack of helm error message
@@ -170,3 +349,85 @@ func (e JSONSchemaValidationError) Error() string {
 	// The extra new line is needed for when there are sub-charts.
 	return errStr + "\n"
 }
+
+var violationPrinter = message.NewPrinter(language.English)
+
+// collectViolations flattens the tree of errors of the validator into the
+// violations at its leaves. The alternatives of "anyOf" and "oneOf", and the
+// items tried against "contains", are reported as a single violation, as none
+// of them is required on its own.
+func collectViolations(e *jsonschema.ValidationError, violations *[]SchemaViolation) {
+	switch e.ErrorKind.(type) {
+	case *kind.AnyOf, *kind.OneOf, *kind.Contains:
+		msg := e.ErrorKind.LocalizedString(violationPrinter)
+		var alternatives []SchemaViolation
+		for _, cause := range e.Causes {
+			collectViolations(cause, &alternatives)
+		}
+		if len(alternatives) > 0 {
+			details := make([]string, 0, len(alternatives))
+			for _, a := range alternatives {
+				details = append(details, a.Message)
+			}
+			msg = fmt.Sprintf("%s: %s", msg, strings.Join(details, "; "))
+		}
+		*violations = append(*violations, newViolation(e, msg))
+		return
+	}
+	if len(e.Causes) == 0 {
+		*violations = append(*violations, newViolation(e, e.ErrorKind.LocalizedString(violationPrinter)))
+		return
+	}
+	for _, cause := range e.Causes {
+		collectViolations(cause, violations)
+	}
+}
+
+func newViolation(e *jsonschema.ValidationError, msg string) SchemaViolation {
+	var keyword string
+	if path := e.ErrorKind.KeywordPath(); len(path) > 0 {
+		keyword = path[0]
+	}
+	// Some messages already start with the keyword, which is printed apart.
+	msg = strings.TrimPrefix(msg, keyword+": ")
+	return SchemaViolation{
+		Pointer:  jsonPointer(e.InstanceLocation),
+		Keyword:  keyword,
+		Message:  msg,
+		location: e.InstanceLocation,
+	}
+}
+
+// jsonPointer builds the JSON Pointer of a location in the values.
+func jsonPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		tok = strings.ReplaceAll(tok, "~", "~0")
+		sb.WriteString(strings.ReplaceAll(tok, "/", "~1"))
+	}
+	return sb.String()
+}
+
+// sourceOf returns the source of the value at a location in the values, or of
+// the values under it when they all came from the same source.
+func sourceOf(sources map[string]string, tokens []string) string {
+	if len(sources) == 0 || len(tokens) == 0 {
+		return ""
+	}
+	path := strings.Join(tokens, ".")
+	if source, ok := sources[path]; ok {
+		return source
+	}
+	var found string
+	for p, source := range sources {
+		if !strings.HasPrefix(p, path+".") {
+			continue
+		}
+		if found != "" && found != source {
+			return ""
+		}
+		found = source
+	}
+	return found
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package util
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -83,8 +85,8 @@ func TestValidateAgainstSingleSchemaNegative(t *testing.T) {
 		errString = err.Error()
 	}
 
-	expectedErrString := `- at '': missing property 'employmentInfo'
-- at '/age': minimum: got -5, want 0
+	expectedErrString := `- at '' [required]: missing property 'employmentInfo'
+- at '/age' [minimum]: got -5, want 0
 `
 	if errString != expectedErrString {
 		t.Errorf("Error string :\n`%s`\ndoes not match expected\n`%s`", errString, expectedErrString)
@@ -178,7 +180,7 @@ func TestValidateAgainstSchemaNegative(t *testing.T) {
 	}
 
 	expectedErrString := `subchart:
-- at '': missing property 'age'
+- at '/subchart' [required]: missing property 'age'
 `
 	if errString != expectedErrString {
 		t.Errorf("Error string :\n`%s`\ndoes not match expected\n`%s`", errString, expectedErrString)
@@ -242,14 +244,84 @@ func TestValidateAgainstSchema2020Negative(t *testing.T) {
 	}
 
 	expectedErrString := `subchart:
-- at '/data': no items match contains schema
-  - at '/data/0': got number, want string
+- at '/subchart/data' [contains]: no items match contains schema: got number, want string
 `
 	if errString != expectedErrString {
 		t.Errorf("Error string :\n`%s`\ndoes not match expected\n`%s`", errString, expectedErrString)
 	}
 }
 
+func TestValidateAgainstSchemaWithSources(t *testing.T) {
+	subchart := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "subchart",
+		},
+		Schema: []byte(subchartSchema),
+	}
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name: "chrt",
+		},
+		Schema: []byte(`{
+  "type": "object",
+  "properties": {
+    "image": {
+      "type": "object",
+      "properties": {
+        "tag": {"type": "string"},
+        "pullPolicy": {"enum": ["Always", "IfNotPresent"]}
+      }
+    }
+  }
+}`),
+	}
+	chrt.AddDependency(subchart)
+
+	vals := map[string]interface{}{
+		"image": map[string]interface{}{
+			"tag":        1.2,
+			"pullPolicy": "Sometimes",
+		},
+		"subchart": map[string]interface{}{
+			"age": -1,
+		},
+	}
+	sources := map[string]string{
+		"image.tag":    "--set image.tag=1.2",
+		"subchart.age": "-f prod.yaml (file 1)",
+	}
+
+	err := ValidateAgainstSchemaWithSources(chrt, vals, sources)
+	var verr *SchemaViolationsError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a *SchemaViolationsError, got %v", err)
+	}
+
+	expected := []SchemaViolation{
+		{Chart: "chrt", Pointer: "/image/pullPolicy", Keyword: "enum", Message: "value must be one of 'Always', 'IfNotPresent'"},
+		{Chart: "chrt", Pointer: "/image/tag", Keyword: "type", Message: "got number, want string", Source: "--set image.tag=1.2"},
+		{Chart: "subchart", Pointer: "/subchart/age", Keyword: "minimum", Message: "got -1, want 0", Source: "-f prod.yaml (file 1)"},
+	}
+	if len(verr.Violations) != len(expected) {
+		t.Fatalf("Expected %d violations, got %d: %s", len(expected), len(verr.Violations), err)
+	}
+	for i, v := range verr.Violations {
+		v.location = nil
+		if !reflect.DeepEqual(v, expected[i]) {
+			t.Errorf("Violation %d: expected %+v, got %+v", i, expected[i], v)
+		}
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	if got := jsonPointer([]string{"a/b", "c~d", "0"}); got != "/a~1b/c~0d/0" {
+		t.Errorf("Expected '/a~1b/c~0d/0', got %q", got)
+	}
+	if got := jsonPointer(nil); got != "" {
+		t.Errorf("Expected an empty pointer, got %q", got)
+	}
+}
+
 func TestHTTPURLLoader_Load(t *testing.T) {
 	// Test successful JSON schema loading
 	t.Run("successful load", func(t *testing.T) {
@@ -0,0 +1,131 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	chart "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// ValuesValidator checks the values of a chart by some means other than its
+// values.schema.json, such as an OpenAPI v3 schema, a CUE definition or Go
+// code. Registered validators are run wherever values.schema.json is checked,
+// once for the chart and once for each of its subcharts.
+type ValuesValidator interface {
+	// Name identifies the validator, as in the errors it reports.
+	Name() string
+	// Validate checks the values of a chart, returning the violations found.
+	// The pointers of the violations are relative to the values of the chart.
+	// Validators that find nothing to check in the chart, such as a chart
+	// without a values.cue file, return no violations.
+	Validate(ch chart.Charter, values common.Values) ([]SchemaViolation, error)
+}
+
+// ValuesValidatorFunc adapts a function to a ValuesValidator, for validators
+// written in Go.
+type ValuesValidatorFunc struct {
+	// ValidatorName is returned by Name.
+	ValidatorName string
+	// Func is called by Validate.
+	Func func(ch chart.Charter, values common.Values) ([]SchemaViolation, error)
+}
+
+// Name returns the name of the validator.
+func (f ValuesValidatorFunc) Name() string {
+	return f.ValidatorName
+}
+
+// Validate calls the function of the validator.
+func (f ValuesValidatorFunc) Validate(ch chart.Charter, values common.Values) ([]SchemaViolation, error) {
+	return f.Func(ch, values)
+}
+
+var (
+	valuesValidatorsMu sync.RWMutex
+	valuesValidators   = map[string]ValuesValidator{}
+)
+
+// RegisterValuesValidator makes a validator run along with the values schemas
+// of charts. It returns an error if the validator has no name or one with the
+// same name is registered.
+func RegisterValuesValidator(v ValuesValidator) error {
+	if v == nil || v.Name() == "" {
+		return fmt.Errorf("values validator must have a name")
+	}
+	valuesValidatorsMu.Lock()
+	defer valuesValidatorsMu.Unlock()
+	if _, ok := valuesValidators[v.Name()]; ok {
+		return fmt.Errorf("values validator %q is already registered", v.Name())
+	}
+	valuesValidators[v.Name()] = v
+	return nil
+}
+
+// UnregisterValuesValidator removes the validator with the given name, if any.
+func UnregisterValuesValidator(name string) {
+	valuesValidatorsMu.Lock()
+	defer valuesValidatorsMu.Unlock()
+	delete(valuesValidators, name)
+}
+
+// ValuesValidators returns the registered validators, sorted by name.
+func ValuesValidators() []ValuesValidator {
+	valuesValidatorsMu.RLock()
+	defer valuesValidatorsMu.RUnlock()
+	out := make([]ValuesValidator, 0, len(valuesValidators))
+	for _, v := range valuesValidators {
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// runValuesValidators runs the registered validators against the values of a
+// chart, returning their violations with the location of each in the values
+// of the chart.
+func runValuesValidators(ch chart.Charter, values common.Values) ([]SchemaViolation, error) {
+	var violations []SchemaViolation
+	for _, v := range ValuesValidators() {
+		found, err := v.Validate(ch, values)
+		if err != nil {
+			return nil, fmt.Errorf("values validator %q: %w", v.Name(), err)
+		}
+		for _, f := range found {
+			f.location = parsePointer(f.Pointer)
+			violations = append(violations, f)
+		}
+	}
+	return violations, nil
+}
+
+// parsePointer splits a JSON Pointer into the locations it is made of.
+func parsePointer(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, tok := range tokens {
+		tok = strings.ReplaceAll(tok, "~1", "/")
+		tokens[i] = strings.ReplaceAll(tok, "~0", "~")
+	}
+	return tokens
+}
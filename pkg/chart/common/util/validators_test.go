@@ -0,0 +1,134 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+	chartv2 "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestRegisterValuesValidator(t *testing.T) {
+	v := ValuesValidatorFunc{ValidatorName: "test-register", Func: func(chart.Charter, common.Values) ([]SchemaViolation, error) {
+		return nil, nil
+	}}
+	if err := RegisterValuesValidator(v); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterValuesValidator(v.Name())
+
+	if err := RegisterValuesValidator(v); err == nil {
+		t.Error("expected an error registering a validator twice")
+	}
+	if err := RegisterValuesValidator(ValuesValidatorFunc{}); err == nil {
+		t.Error("expected an error registering a validator without a name")
+	}
+
+	var found bool
+	for _, r := range ValuesValidators() {
+		if r.Name() == v.Name() {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected %q to be registered", v.Name())
+	}
+}
+
+func TestValidateAgainstSchemaWithValuesValidator(t *testing.T) {
+	// replicasValidator requires an even number of replicas from charts that
+	// have a replicas value.
+	replicasValidator := ValuesValidatorFunc{ValidatorName: "test-replicas", Func: func(_ chart.Charter, values common.Values) ([]SchemaViolation, error) {
+		n, ok := values["replicas"].(int)
+		if !ok || n%2 == 0 {
+			return nil, nil
+		}
+		return []SchemaViolation{{Pointer: "/replicas", Keyword: "even", Message: "replicas must be even"}}, nil
+	}}
+	if err := RegisterValuesValidator(replicasValidator); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterValuesValidator(replicasValidator.Name())
+
+	subchart := &chartv2.Chart{
+		Metadata: &chartv2.Metadata{Name: "subchart"},
+		Schema:   []byte(subchartSchema),
+	}
+	chrt := &chartv2.Chart{
+		Metadata: &chartv2.Metadata{Name: "chrt"},
+	}
+	chrt.AddDependency(subchart)
+
+	vals := map[string]interface{}{
+		"replicas": 2,
+		"subchart": map[string]interface{}{
+			"age":      -1,
+			"replicas": 3,
+		},
+	}
+	sources := map[string]string{"subchart.replicas": "--set subchart.replicas=3"}
+
+	err := ValidateAgainstSchemaWithSources(chrt, vals, sources)
+	var verr *SchemaViolationsError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *SchemaViolationsError, got %v", err)
+	}
+
+	expected := `subchart:
+- at '/subchart/age' [minimum]: got -1, want 0
+- at '/subchart/replicas' [even]: replicas must be even (set by --set subchart.replicas=3)
+`
+	if err.Error() != expected {
+		t.Errorf("Error string :\n`%s`\ndoes not match expected\n`%s`", err.Error(), expected)
+	}
+}
+
+func TestValuesValidatorError(t *testing.T) {
+	broken := ValuesValidatorFunc{ValidatorName: "test-broken", Func: func(chart.Charter, common.Values) ([]SchemaViolation, error) {
+		return nil, errors.New("cannot load values.cue")
+	}}
+	if err := RegisterValuesValidator(broken); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterValuesValidator(broken.Name())
+
+	chrt := &chartv2.Chart{Metadata: &chartv2.Metadata{Name: "chrt"}}
+	err := ValidateAgainstSchema(chrt, map[string]interface{}{})
+	expected := "chrt:\nvalues validator \"test-broken\": cannot load values.cue"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tokens := []string{"a/b", "c~d", "0"}
+	got := parsePointer(jsonPointer(tokens))
+	if len(got) != len(tokens) {
+		t.Fatalf("expected %v, got %v", tokens, got)
+	}
+	for i := range tokens {
+		if got[i] != tokens[i] {
+			t.Errorf("expected %v, got %v", tokens, got)
+		}
+	}
+	if got := parsePointer(""); got != nil {
+		t.Errorf("expected no tokens, got %v", got)
+	}
+}
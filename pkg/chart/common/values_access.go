@@ -0,0 +1,385 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The accessors below take paths of keys separated by periods. Elements of
+// lists are selected with their index, either as a key or in brackets, and
+// keys containing periods can be quoted in brackets:
+//
+//	image.tag
+//	containers.0.name
+//	containers[0].name
+//	podAnnotations["example.com/owner"]
+
+// pathElement is an element of a values path.
+type pathElement struct {
+	key string
+	// index is set when the element was given in brackets as a list index.
+	index bool
+}
+
+// parseValuesPath splits a values path into its elements.
+func parseValuesPath(path string) ([]pathElement, error) {
+	if path == "" {
+		return nil, errors.New("values path cannot be empty")
+	}
+	var elems []pathElement
+	for i := 0; i < len(path); {
+		var elem pathElement
+		if path[i] == '[' {
+			var err error
+			var n int
+			if elem, n, err = parseBracket(path[i:]); err != nil {
+				return nil, fmt.Errorf("invalid values path %q: %w", path, err)
+			}
+			i += n
+		} else {
+			n := strings.IndexAny(path[i:], ".[")
+			if n < 0 {
+				n = len(path) - i
+			}
+			if n == 0 {
+				return nil, fmt.Errorf("invalid values path %q: empty key", path)
+			}
+			elem.key = path[i : i+n]
+			i += n
+		}
+		elems = append(elems, elem)
+
+		if i < len(path) && path[i] == '.' {
+			i++
+			if i == len(path) {
+				return nil, fmt.Errorf("invalid values path %q: empty key", path)
+			}
+		}
+	}
+	return elems, nil
+}
+
+// parseBracket parses a bracketed element at the start of s and returns it
+// along with its length.
+func parseBracket(s string) (pathElement, int, error) {
+	if len(s) > 1 && (s[1] == '"' || s[1] == '\'') {
+		quote := s[1]
+		end := strings.IndexByte(s[2:], quote)
+		if end < 0 || len(s) < end+4 || s[end+3] != ']' {
+			return pathElement{}, 0, errors.New("unterminated quoted key")
+		}
+		return pathElement{key: s[2 : end+2]}, end + 4, nil
+	}
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return pathElement{}, 0, errors.New("unterminated bracket")
+	}
+	if _, err := strconv.Atoi(s[1:end]); err != nil {
+		return pathElement{}, 0, fmt.Errorf("invalid list index %q", s[1:end])
+	}
+	return pathElement{key: s[1:end], index: true}, end + 1, nil
+}
+
+// child returns the element of a table or list.
+func child(v interface{}, elem pathElement) (interface{}, bool) {
+	if t, ok := asTable(v); ok {
+		if elem.index {
+			return nil, false
+		}
+		val, ok := t[elem.key]
+		return val, ok
+	}
+	if l, ok := v.([]interface{}); ok {
+		i, err := strconv.Atoi(elem.key)
+		if err != nil || i < 0 || i >= len(l) {
+			return nil, false
+		}
+		return l[i], true
+	}
+	return nil, false
+}
+
+func asTable(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, true
+	case Values:
+		return t, true
+	}
+	return nil, false
+}
+
+// Get returns the value at the given path, which can be a table or a list.
+//
+// An ErrNoValue is returned if there is no value at the path.
+func (v Values) Get(path string) (interface{}, error) {
+	elems, err := parseValuesPath(path)
+	if err != nil {
+		return nil, err
+	}
+	var cur interface{} = v
+	for _, elem := range elems {
+		next, ok := child(cur, elem)
+		if !ok {
+			return nil, ErrNoValue{path}
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+// PathExists reports whether there is a value at the given path, even if it
+// is null.
+func (v Values) PathExists(path string) bool {
+	_, err := v.Get(path)
+	return err == nil
+}
+
+// Set sets the value at the given path, creating the missing tables on the
+// way. Lists are not extended: the index of a list element must exist.
+func (v Values) Set(path string, value interface{}) error {
+	elems, err := parseValuesPath(path)
+	if err != nil {
+		return err
+	}
+	var cur interface{} = v
+	for i, elem := range elems {
+		last := i == len(elems)-1
+		if t, ok := asTable(cur); ok && !elem.index {
+			if last {
+				t[elem.key] = value
+				return nil
+			}
+			next, ok := t[elem.key]
+			if !ok || next == nil {
+				next = map[string]interface{}{}
+				t[elem.key] = next
+			}
+			cur = next
+			continue
+		}
+		if l, ok := cur.([]interface{}); ok {
+			idx, err := strconv.Atoi(elem.key)
+			if err != nil || idx < 0 || idx >= len(l) {
+				return fmt.Errorf("cannot set %q: no element %s in list %q", path, elem.key, joinElements(elems[:i]))
+			}
+			if last {
+				l[idx] = value
+				return nil
+			}
+			cur = l[idx]
+			continue
+		}
+		expected := "table or list"
+		if elem.index {
+			expected = "list"
+		}
+		return fmt.Errorf("cannot set %q: %w", path, ErrValueType{Path: joinElements(elems[:i]), Expected: expected, Value: cur})
+	}
+	return nil
+}
+
+// GetString returns the string at the given path.
+//
+// An ErrNoValue is returned if there is no value at the path, and an
+// ErrValueType if it is not a string.
+func (v Values) GetString(path string) (string, error) {
+	val, err := v.Get(path)
+	if err != nil {
+		return "", err
+	}
+	s, ok := val.(string)
+	if !ok {
+		return "", ErrValueType{Path: path, Expected: "string", Value: val}
+	}
+	return s, nil
+}
+
+// GetInt returns the integer at the given path. Floating point numbers without
+// a fractional part, as decoded from YAML and JSON, are accepted.
+//
+// An ErrNoValue is returned if there is no value at the path, and an
+// ErrValueType if it is not an integer.
+func (v Values) GetInt(path string) (int, error) {
+	val, err := v.Get(path)
+	if err != nil {
+		return 0, err
+	}
+	i, ok := toInt(val)
+	if !ok {
+		return 0, ErrValueType{Path: path, Expected: "integer", Value: val}
+	}
+	return i, nil
+}
+
+// GetBool returns the boolean at the given path.
+//
+// An ErrNoValue is returned if there is no value at the path, and an
+// ErrValueType if it is not a boolean.
+func (v Values) GetBool(path string) (bool, error) {
+	val, err := v.Get(path)
+	if err != nil {
+		return false, err
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, ErrValueType{Path: path, Expected: "boolean", Value: val}
+	}
+	return b, nil
+}
+
+// GetStringSlice returns the list of strings at the given path.
+//
+// An ErrNoValue is returned if there is no value at the path, and an
+// ErrValueType if it is not a list of strings.
+func (v Values) GetStringSlice(path string) ([]string, error) {
+	val, err := v.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	switch l := val.(type) {
+	case []string:
+		return l, nil
+	case []interface{}:
+		s := make([]string, len(l))
+		for i, item := range l {
+			str, ok := item.(string)
+			if !ok {
+				return nil, ErrValueType{Path: fmt.Sprintf("%s[%d]", path, i), Expected: "string", Value: item}
+			}
+			s[i] = str
+		}
+		return s, nil
+	}
+	return nil, ErrValueType{Path: path, Expected: "list", Value: val}
+}
+
+// GetStringOr returns the string at the given path, or def if there is none
+// or the value is not a string.
+func (v Values) GetStringOr(path, def string) string {
+	if s, err := v.GetString(path); err == nil {
+		return s
+	}
+	return def
+}
+
+// GetIntOr returns the integer at the given path, or def if there is none or
+// the value is not an integer.
+func (v Values) GetIntOr(path string, def int) int {
+	if i, err := v.GetInt(path); err == nil {
+		return i
+	}
+	return def
+}
+
+// GetBoolOr returns the boolean at the given path, or def if there is none or
+// the value is not a boolean.
+func (v Values) GetBoolOr(path string, def bool) bool {
+	if b, err := v.GetBool(path); err == nil {
+		return b
+	}
+	return def
+}
+
+// GetStringSliceOr returns the list of strings at the given path, or def if
+// there is none or the value is not a list of strings.
+func (v Values) GetStringSliceOr(path string, def []string) []string {
+	if s, err := v.GetStringSlice(path); err == nil {
+		return s
+	}
+	return def
+}
+
+func toInt(val interface{}) (int, bool) {
+	switch n := val.(type) {
+	case int:
+		return n, true
+	case int8:
+		return int(n), true
+	case int16:
+		return int(n), true
+	case int32:
+		return int(n), true
+	case int64:
+		if n < math.MinInt || n > math.MaxInt {
+			return 0, false
+		}
+		return int(n), true
+	case uint8:
+		return int(n), true
+	case uint16:
+		return int(n), true
+	case uint32:
+		if uint64(n) > math.MaxInt {
+			return 0, false
+		}
+		return int(n), true
+	case uint:
+		if uint64(n) > math.MaxInt {
+			return 0, false
+		}
+		return int(n), true
+	case uint64:
+		if n > math.MaxInt {
+			return 0, false
+		}
+		return int(n), true
+	case float32:
+		return floatToInt(float64(n))
+	case float64:
+		return floatToInt(n)
+	case json.Number:
+		i, err := n.Int64()
+		if err != nil {
+			return 0, false
+		}
+		return toInt(i)
+	}
+	return 0, false
+}
+
+func floatToInt(f float64) (int, bool) {
+	if f != math.Trunc(f) || f < math.MinInt || f >= math.MaxInt {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// joinElements formats path elements back into a path.
+func joinElements(elems []pathElement) string {
+	var b strings.Builder
+	for i, elem := range elems {
+		switch {
+		case elem.index:
+			fmt.Fprintf(&b, "[%s]", elem.key)
+		case strings.ContainsAny(elem.key, ".[]"):
+			fmt.Fprintf(&b, "[%q]", elem.key)
+		default:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(elem.key)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,198 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const accessDoc = `
+image:
+  repository: nginx
+  tag: "1.27"
+replicas: 3
+ratio: 0.5
+debug: true
+empty: null
+args: ["--verbose", "--port=80"]
+mixed: ["a", 1]
+containers:
+  - name: web
+    ports: [80, 443]
+podAnnotations:
+  example.com/owner: team-a
+`
+
+func readAccessDoc(t *testing.T) Values {
+	t.Helper()
+	v, err := ReadValues([]byte(accessDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestValuesGet(t *testing.T) {
+	v := readAccessDoc(t)
+
+	tests := []struct {
+		path   string
+		expect interface{}
+	}{
+		{"image.tag", "1.27"},
+		{"containers.0.name", "web"},
+		{"containers[0].name", "web"},
+		{"containers[0].ports[1]", float64(443)},
+		{"containers.0.ports.1", float64(443)},
+		{`podAnnotations["example.com/owner"]`, "team-a"},
+		{`podAnnotations['example.com/owner']`, "team-a"},
+		{"image", map[string]interface{}{"repository": "nginx", "tag": "1.27"}},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		got, err := v.Get(tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.expect, got)
+		}
+	}
+
+	for _, path := range []string{"image.digest", "containers.1.name", "containers[name]", "image[0]", "replicas.value"} {
+		if _, err := v.Get(path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+	var noValue ErrNoValue
+	if _, err := v.Get("image.digest"); !errors.As(err, &noValue) || noValue.Key != "image.digest" {
+		t.Errorf("expected ErrNoValue for image.digest, got %v", err)
+	}
+
+	for _, path := range []string{"", "image.", ".image", "image..tag", "args[0", `podAnnotations["x]`} {
+		if _, err := v.Get(path); err == nil {
+			t.Errorf("%q: expected an invalid path error", path)
+		}
+	}
+}
+
+func TestValuesPathExists(t *testing.T) {
+	v := readAccessDoc(t)
+	for path, expect := range map[string]bool{
+		"image.repository": true,
+		"empty":            true,
+		"args[1]":          true,
+		"args[2]":          false,
+		"image.digest":     false,
+	} {
+		if got := v.PathExists(path); got != expect {
+			t.Errorf("%s: expected %t, got %t", path, expect, got)
+		}
+	}
+}
+
+func TestValuesTypedGetters(t *testing.T) {
+	v := readAccessDoc(t)
+
+	if s, err := v.GetString("image.tag"); err != nil || s != "1.27" {
+		t.Errorf("GetString: got %q, %v", s, err)
+	}
+	if i, err := v.GetInt("replicas"); err != nil || i != 3 {
+		t.Errorf("GetInt: got %d, %v", i, err)
+	}
+	if b, err := v.GetBool("debug"); err != nil || !b {
+		t.Errorf("GetBool: got %t, %v", b, err)
+	}
+	if s, err := v.GetStringSlice("args"); err != nil || !reflect.DeepEqual(s, []string{"--verbose", "--port=80"}) {
+		t.Errorf("GetStringSlice: got %v, %v", s, err)
+	}
+
+	typeErrors := []struct {
+		get    func() error
+		expect string
+	}{
+		{func() error { _, err := v.GetString("replicas"); return err }, `"replicas": expected string, got float64`},
+		{func() error { _, err := v.GetInt("ratio"); return err }, `"ratio": expected integer, got float64`},
+		{func() error { _, err := v.GetBool("empty"); return err }, `"empty": expected boolean, got null`},
+		{func() error { _, err := v.GetStringSlice("image"); return err }, `"image": expected list, got map[string]interface {}`},
+		{func() error { _, err := v.GetStringSlice("mixed"); return err }, `"mixed[1]": expected string, got float64`},
+	}
+	for _, tt := range typeErrors {
+		err := tt.get()
+		var typeErr ErrValueType
+		if !errors.As(err, &typeErr) {
+			t.Errorf("expected ErrValueType, got %v", err)
+			continue
+		}
+		if err.Error() != tt.expect {
+			t.Errorf("expected %q, got %q", tt.expect, err.Error())
+		}
+	}
+
+	if got := v.GetStringOr("image.digest", "sha256:0"); got != "sha256:0" {
+		t.Errorf("GetStringOr: got %q", got)
+	}
+	if got := v.GetIntOr("ratio", 1); got != 1 {
+		t.Errorf("GetIntOr: got %d", got)
+	}
+	if got := v.GetBoolOr("debug", false); !got {
+		t.Errorf("GetBoolOr: got %t", got)
+	}
+	if got := v.GetStringSliceOr("missing", []string{"x"}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("GetStringSliceOr: got %v", got)
+	}
+}
+
+func TestValuesSet(t *testing.T) {
+	v := readAccessDoc(t)
+
+	sets := map[string]interface{}{
+		"image.tag":                "1.28",
+		"resources.limits.cpu":     "100m",
+		"containers[0].ports[0]":   8080,
+		"containers.0.name":        "app",
+		`podAnnotations["a.b/c"]`:  "d",
+		"empty.created":            true,
+		"image.pullPolicy":         "Always",
+		"args.1":                   "--port=8080",
+		"resources.requests.extra": []interface{}{"x"},
+	}
+	for path, value := range sets {
+		if err := v.Set(path, value); err != nil {
+			t.Fatalf("%s: unexpected error: %s", path, err)
+		}
+		got, err := v.Get(path)
+		if err != nil || !reflect.DeepEqual(got, value) {
+			t.Errorf("%s: expected %v, got %v (%v)", path, value, got, err)
+		}
+	}
+
+	for path, expect := range map[string]string{
+		"args[5]":             `cannot set "args[5]": no element 5 in list "args"`,
+		"replicas.value":      `cannot set "replicas.value": "replicas": expected table or list, got float64`,
+		"image[0]":            `cannot set "image[0]": "image": expected list, got map[string]interface {}`,
+		"containers.web.name": `cannot set "containers.web.name": no element web in list "containers"`,
+	} {
+		err := v.Set(path, "x")
+		if err == nil || err.Error() != expect {
+			t.Errorf("%s: expected error %q, got %v", path, expect, err)
+		}
+	}
+}
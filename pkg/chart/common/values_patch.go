@@ -0,0 +1,136 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+// PatchKey is the key of the directive controlling how a table or a list of
+// values is merged with the values it overrides.
+//
+// A table sets the directive as one of its keys:
+//
+//	resources:
+//	  $patch: replace
+//	  limits:
+//	    memory: 1Gi
+//
+// A list sets it as an item of its own:
+//
+//	extraArgs:
+//	- $patch: merge
+//	- --verbose
+const PatchKey = "$patch"
+
+// PatchDirective is the value of a PatchKey directive.
+type PatchDirective string
+
+const (
+	// PatchMerge merges a table with the table it overrides, which is the
+	// default for tables, or appends the items of a list to the items of the
+	// list it overrides.
+	PatchMerge PatchDirective = "merge"
+	// PatchReplace replaces the table or list it overrides, which is the
+	// default for lists.
+	PatchReplace PatchDirective = "replace"
+	// PatchDelete removes the key, as a null value does.
+	PatchDelete PatchDirective = "delete"
+)
+
+// TablePatch returns the directive of a table, or "" if it has none.
+func TablePatch(t map[string]interface{}) PatchDirective {
+	d, _ := t[PatchKey].(string)
+	return PatchDirective(d)
+}
+
+// ListPatch returns the directive of a list, or "" if it has none, and the
+// items of the list without it.
+func ListPatch(l []interface{}) (PatchDirective, []interface{}) {
+	for i, item := range l {
+		if d, ok := patchItem(item); ok {
+			items := make([]interface{}, 0, len(l)-1)
+			items = append(items, l[:i]...)
+			return d, append(items, l[i+1:]...)
+		}
+	}
+	return "", l
+}
+
+// patchItem reports whether a list item is a directive, a table holding
+// nothing but the PatchKey.
+func patchItem(item interface{}) (PatchDirective, bool) {
+	t, ok := item.(map[string]interface{})
+	if !ok || len(t) != 1 {
+		return "", false
+	}
+	d, ok := t[PatchKey].(string)
+	return PatchDirective(d), ok
+}
+
+// IsPatchDelete reports whether a value is a table or a list with the
+// PatchDelete directive.
+func IsPatchDelete(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return TablePatch(v) == PatchDelete
+	case []interface{}:
+		d, _ := ListPatch(v)
+		return d == PatchDelete
+	}
+	return false
+}
+
+// PatchList overrides the list src with the list dst, following the
+// directive of dst.
+//
+// The directive is kept in the result, as the first item, so that it applies
+// again when the result overrides other values.
+func PatchList(dst, src []interface{}) []interface{} {
+	d, items := ListPatch(dst)
+	if d != PatchMerge {
+		return dst
+	}
+	_, srcItems := ListPatch(src)
+	out := make([]interface{}, 0, len(srcItems)+len(items)+1)
+	out = append(out, map[string]interface{}{PatchKey: string(PatchMerge)})
+	out = append(out, srcItems...)
+	return append(out, items...)
+}
+
+// StripPatches removes the directives from values, in place, deleting the
+// keys of the tables and lists marked with PatchDelete.
+func StripPatches(v map[string]interface{}) {
+	delete(v, PatchKey)
+	for key, val := range v {
+		if IsPatchDelete(val) {
+			delete(v, key)
+			continue
+		}
+		v[key] = stripPatches(val)
+	}
+}
+
+func stripPatches(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		StripPatches(v)
+	case []interface{}:
+		_, items := ListPatch(v)
+		for i, item := range items {
+			items[i] = stripPatches(item)
+		}
+		return items
+	}
+	return v
+}
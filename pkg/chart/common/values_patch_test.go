@@ -0,0 +1,57 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListPatch(t *testing.T) {
+	d, items := ListPatch([]interface{}{"a", map[string]interface{}{PatchKey: "merge"}, "b"})
+	if d != PatchMerge || !reflect.DeepEqual(items, []interface{}{"a", "b"}) {
+		t.Errorf("expected the merge directive and the other items, got %q %v", d, items)
+	}
+
+	// A table with other keys is an item, not a directive.
+	l := []interface{}{map[string]interface{}{PatchKey: "merge", "name": "a"}}
+	if d, items := ListPatch(l); d != "" || !reflect.DeepEqual(items, l) {
+		t.Errorf("expected no directive, got %q %v", d, items)
+	}
+}
+
+func TestStripPatches(t *testing.T) {
+	v := map[string]interface{}{
+		PatchKey: "merge",
+		"table":  map[string]interface{}{PatchKey: "replace", "a": 1},
+		"gone":   map[string]interface{}{PatchKey: "delete"},
+		"list": []interface{}{
+			map[string]interface{}{PatchKey: "merge"},
+			map[string]interface{}{PatchKey: "replace", "b": 2},
+		},
+		"goneList": []interface{}{map[string]interface{}{PatchKey: "delete"}},
+	}
+	StripPatches(v)
+
+	expected := map[string]interface{}{
+		"table": map[string]interface{}{"a": 1},
+		"list":  []interface{}{map[string]interface{}{"b": 2}},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("expected %v, got %v", expected, v)
+	}
+}
@@ -0,0 +1,411 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package test tests the manifests rendered from charts against an ephemeral
+Kubernetes control plane.
+
+It is the integration tier of chart testing: unlike the offline schema checks
+of the linter, the manifests are validated by a real kube-apiserver, with its
+admission chain and defaulting, without needing a full cluster. The control
+plane is started with envtest, and only runs etcd and kube-apiserver: no
+controller acts on the applied objects.
+*/
+package test // import "helm.sh/helm/v4/pkg/chart/test"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/yaml"
+
+	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
+)
+
+// AssetsEnvVar is the environment variable naming the directory of the etcd
+// and kube-apiserver binaries, as installed by setup-envtest.
+const AssetsEnvVar = "KUBEBUILDER_ASSETS"
+
+// fieldManager is the field manager of the objects applied by Accept.
+const fieldManager = "helm"
+
+// Environment is an ephemeral control plane the rendered manifests of charts
+// are applied to.
+type Environment struct {
+	// BinaryAssetsDirectory is the directory of the etcd and kube-apiserver
+	// binaries. If empty, the directory named by AssetsEnvVar is used.
+	BinaryAssetsDirectory string
+	// CRDTimeout is how long Accept waits for the CRDs it applies to be
+	// served. Defaults to 30 seconds.
+	CRDTimeout time.Duration
+
+	env    *envtest.Environment
+	config *rest.Config
+}
+
+// Start starts the control plane.
+func (e *Environment) Start() error {
+	dir := e.BinaryAssetsDirectory
+	if dir == "" {
+		dir = os.Getenv(AssetsEnvVar)
+	}
+	if dir == "" {
+		return fmt.Errorf("no etcd and kube-apiserver binaries: set %s to the directory of the binaries installed by setup-envtest", AssetsEnvVar)
+	}
+	e.env = &envtest.Environment{BinaryAssetsDirectory: dir}
+	config, err := e.env.Start()
+	if err != nil {
+		return fmt.Errorf("failed to start the control plane: %w", err)
+	}
+	e.config = config
+	return nil
+}
+
+// Stop stops the control plane.
+func (e *Environment) Stop() error {
+	if e.env == nil {
+		return nil
+	}
+	return e.env.Stop()
+}
+
+// Config returns the configuration of the clients of the started control
+// plane.
+func (e *Environment) Config() *rest.Config {
+	return e.config
+}
+
+// Result is the outcome of applying a rendered object.
+type Result struct {
+	// Path is the template the object was rendered from.
+	Path       string `json:"path"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Accepted is true if the control plane accepted the object.
+	Accepted bool `json:"accepted"`
+	// Error is the reason the object was rejected.
+	Error string `json:"error,omitempty"`
+	// Defaulted lists the fields set by the control plane when accepting the
+	// object, such as "spec.strategy.type".
+	Defaulted []string `json:"defaulted,omitempty"`
+}
+
+// Report is the outcome of applying the rendered manifests of a chart.
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Rejected returns the number of rejected objects.
+func (r *Report) Rejected() int {
+	n := 0
+	for _, res := range r.Results {
+		if !res.Accepted {
+			n++
+		}
+	}
+	return n
+}
+
+// object is a rendered object with the template it was rendered from.
+type object struct {
+	path string
+	obj  *unstructured.Unstructured
+}
+
+// Accept applies the CRDs of the stream of YAML documents crds, then the
+// objects of manifest, to the started control plane with server-side apply and strict field validation, and
+// reports whether each of them was accepted. Objects without a namespace are
+// applied to namespace, which is created if needed.
+//
+// The returned error is only set if the objects could not be applied at
+// all: rejected objects are reported in the Report.
+func (e *Environment) Accept(ctx context.Context, crds, manifest, namespace string) (*Report, error) {
+	if e.config == nil {
+		return nil, errors.New("the control plane is not started")
+	}
+	client, err := dynamic.NewForConfig(e.config)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(e.config)
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
+
+	crdObjects, err := decodeManifests(splitManifest(crds))
+	if err != nil {
+		return nil, err
+	}
+	objects, err := decodeManifests(splitManifest(manifest))
+	if err != nil {
+		return nil, err
+	}
+	// CRDs rendered by templates are applied with those of the chart
+	var others []object
+	for _, o := range objects {
+		if o.obj.GroupVersionKind().GroupKind() == crdGroupKind {
+			crdObjects = append(crdObjects, o)
+		} else {
+			others = append(others, o)
+		}
+	}
+
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	if err := ensureNamespace(ctx, client, namespace); err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	var served []schema.GroupKind
+	for _, o := range crdObjects {
+		res := apply(ctx, client, mapper, o, namespace)
+		report.Results = append(report.Results, res)
+		if res.Accepted {
+			group, _, _ := unstructured.NestedString(o.obj.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(o.obj.Object, "spec", "names", "kind")
+			served = append(served, schema.GroupKind{Group: group, Kind: kind})
+		}
+	}
+	if len(served) > 0 {
+		if err := e.waitForKinds(ctx, mapper, served); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range sortObjects(others) {
+		report.Results = append(report.Results, apply(ctx, client, mapper, o, namespace))
+	}
+	return report, nil
+}
+
+// crdGroupKind is the kind of CustomResourceDefinitions.
+var crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+// waitForKinds waits for kinds, served by the CRDs just applied, to be
+// discoverable.
+func (e *Environment) waitForKinds(ctx context.Context, mapper meta.ResettableRESTMapper, kinds []schema.GroupKind) error {
+	timeout := e.CRDTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	err := wait.PollUntilContextTimeout(ctx, 200*time.Millisecond, timeout, true, func(context.Context) (bool, error) {
+		mapper.Reset()
+		for _, gk := range kinds {
+			if _, err := mapper.RESTMapping(gk); err != nil {
+				if meta.IsNoMatchError(err) {
+					return false, nil
+				}
+				return false, err
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for the CRDs to be served: %w", err)
+	}
+	return nil
+}
+
+func ensureNamespace(ctx context.Context, client dynamic.Interface, namespace string) error {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(namespace)
+	_, err := client.Resource(corev1.SchemeGroupVersion.WithResource("namespaces")).Create(ctx, ns, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %q: %w", namespace, err)
+	}
+	return nil
+}
+
+// apply applies o with server-side apply and strict field validation.
+func apply(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, o object, namespace string) Result {
+	obj := o.obj
+	res := Result{
+		Path:       o.path,
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+	}
+
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		res.Error = fmt.Sprintf("kind %s is not served: %v", gvk, err)
+		return res
+	}
+	var resource dynamic.ResourceInterface = client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		res.Namespace = obj.GetNamespace()
+		if res.Namespace != namespace {
+			if err := ensureNamespace(ctx, client, res.Namespace); err != nil {
+				res.Error = err.Error()
+				return res
+			}
+		}
+		resource = client.Resource(mapping.Resource).Namespace(res.Namespace)
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	force := true
+	applied, err := resource.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager:    fieldManager,
+		FieldValidation: metav1.FieldValidationStrict,
+		Force:           &force,
+	})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Accepted = true
+	res.Defaulted = defaultedFields(obj.Object, applied.Object)
+	return res
+}
+
+// defaultedFields returns the paths of the fields of applied missing from
+// submitted, outside of the metadata and the status of the object.
+func defaultedFields(submitted, applied map[string]interface{}) []string {
+	var fields []string
+	var walk func(sub, app interface{}, path string)
+	walk = func(sub, app interface{}, path string) {
+		switch app := app.(type) {
+		case map[string]interface{}:
+			s, _ := sub.(map[string]interface{})
+			for key, value := range app {
+				p := key
+				if path != "" {
+					p = path + "." + key
+				}
+				if path == "" && (key == "metadata" || key == "status") {
+					continue
+				}
+				sv, ok := s[key]
+				if !ok {
+					fields = append(fields, p)
+					continue
+				}
+				walk(sv, value, p)
+			}
+		case []interface{}:
+			s, _ := sub.([]interface{})
+			for i, value := range app {
+				if i < len(s) {
+					walk(s[i], value, path+"[]")
+				}
+			}
+		}
+	}
+	walk(submitted, applied, "")
+	sort.Strings(fields)
+	// Fields defaulted in several items of a list are reported once.
+	return compact(fields)
+}
+
+func compact(s []string) []string {
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// splitManifest splits a stream of YAML documents, in order.
+func splitManifest(manifest string) []string {
+	split := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(split))
+	for key := range split {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+	docs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		docs = append(docs, split[key])
+	}
+	return docs
+}
+
+// decodeManifests decodes the objects of docs, recording the template they
+// were rendered from from their "# Source:" comment.
+func decodeManifests(docs []string) ([]object, error) {
+	var objects []object
+	for _, doc := range docs {
+		var path string
+		for _, line := range strings.Split(doc, "\n") {
+			if p, ok := strings.CutPrefix(line, "# Source: "); ok {
+				path = strings.TrimSpace(p)
+				break
+			}
+		}
+		var m map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &m); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if len(m) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: m}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("object in %s has no kind or apiVersion", path)
+		}
+		objects = append(objects, object{path: path, obj: obj})
+	}
+	return objects, nil
+}
+
+// sortObjects moves the Namespaces first, as the other objects may be
+// applied to them, keeping the order of the others.
+func sortObjects(objects []object) []object {
+	isNamespace := func(o object) bool {
+		return o.obj.GroupVersionKind().GroupKind() == schema.GroupKind{Kind: "Namespace"}
+	}
+	sort.SliceStable(objects, func(i, j int) bool { return isNamespace(objects[i]) && !isNamespace(objects[j]) })
+	return objects
+}
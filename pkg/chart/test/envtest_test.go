@@ -0,0 +1,159 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `---
+# Source: mychart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx
+---
+# Source: mychart/templates/namespace.yaml
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: extra
+---
+# Source: mychart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+  - port: 80
+    unknownField: true
+`
+
+func TestDecodeManifests(t *testing.T) {
+	objects, err := decodeManifests(splitManifest(testManifest))
+	require.NoError(t, err)
+	objects = sortObjects(objects)
+
+	var got []string
+	for _, o := range objects {
+		got = append(got, o.path+" "+o.obj.GetKind())
+	}
+	assert.Equal(t, []string{
+		"mychart/templates/namespace.yaml Namespace",
+		"mychart/templates/deployment.yaml Deployment",
+		"mychart/templates/service.yaml Service",
+	}, got)
+
+	_, err = decodeManifests([]string{"# Source: mychart/templates/bad.yaml\nmetadata:\n  name: bad\n"})
+	assert.EqualError(t, err, "object in mychart/templates/bad.yaml has no kind or apiVersion")
+}
+
+func TestDefaultedFields(t *testing.T) {
+	submitted := map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "a"},
+						map[string]interface{}{"name": "b"},
+					},
+				},
+			},
+		},
+	}
+	applied := map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "uid": "1234"},
+		"status":   map[string]interface{}{},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "a", "imagePullPolicy": "Always"},
+						map[string]interface{}{"name": "b", "imagePullPolicy": "Always"},
+					},
+					"restartPolicy": "Always",
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{
+		"spec.replicas",
+		"spec.template.spec.containers[].imagePullPolicy",
+		"spec.template.spec.restartPolicy",
+	}, defaultedFields(submitted, applied))
+	assert.Nil(t, defaultedFields(submitted, submitted))
+}
+
+func TestStartWithoutAssets(t *testing.T) {
+	t.Setenv(AssetsEnvVar, "")
+	err := (&Environment{}).Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), AssetsEnvVar)
+}
+
+// TestAccept runs against a control plane, when the envtest binaries are
+// installed.
+func TestAccept(t *testing.T) {
+	if os.Getenv(AssetsEnvVar) == "" {
+		t.Skipf("%s is not set", AssetsEnvVar)
+	}
+	env := &Environment{}
+	require.NoError(t, env.Start())
+	defer env.Stop()
+
+	report, err := env.Accept(context.Background(), "", testManifest, "test")
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, 1, report.Rejected())
+
+	for _, r := range report.Results {
+		switch r.Kind {
+		case "Deployment":
+			assert.True(t, r.Accepted, r.Error)
+			assert.Equal(t, "test", r.Namespace)
+			assert.Contains(t, r.Defaulted, "spec.replicas")
+		case "Service":
+			assert.False(t, r.Accepted)
+			assert.True(t, strings.Contains(r.Error, "unknownField"), r.Error)
+		}
+	}
+}
@@ -34,6 +34,9 @@ var aliasNameFormat = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
 
 // Chart is a helm package that contains metadata, a default config, zero or more
 // optionally parameterizable templates, and zero or more charts (dependencies).
+//
+// Charts are stored as JSON inside every release, so the JSON field names are
+// stable. Dependencies are not part of the JSON representation.
 type Chart struct {
 	// Raw contains the raw contents of the files originally contained in the chart archive.
 	//
@@ -20,7 +20,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"helm.sh/helm/v4/internal/test"
 	"helm.sh/helm/v4/pkg/chart/common"
 )
 
@@ -211,3 +213,53 @@ func TestCRDObjects(t *testing.T) {
 	crds := chrt.CRDObjects()
 	is.Equal(expected, crds)
 }
+
+// TestChartJSON guards the JSON encoding of charts, which is stored inside
+// every release.
+func TestChartJSON(t *testing.T) {
+	chrt := &Chart{
+		Metadata: &Metadata{
+			APIVersion:  APIVersionV2,
+			Name:        "hello",
+			Version:     "0.1.0",
+			AppVersion:  "1.0",
+			Description: "A chart",
+			Type:        "application",
+			Maintainers: []*Maintainer{{Name: "jane", Email: "jane@example.com"}},
+			Annotations: map[string]string{"category": "demo"},
+			Dependencies: []*Dependency{{
+				Name:       "sub",
+				Version:    "^1.0.0",
+				Repository: "https://example.com/charts",
+				Alias:      "other",
+			}},
+		},
+		Lock: &Lock{
+			Digest:       "sha256:abc",
+			Dependencies: []*Dependency{{Name: "sub", Version: "1.0.1", Repository: "https://example.com/charts"}},
+		},
+		Templates: []*common.File{{Name: "templates/cm.yaml", Data: []byte("kind: ConfigMap")}},
+		Values:    map[string]interface{}{"replicas": float64(1)},
+		Schema:    []byte(`{"type":"object"}`),
+		Files:     []*common.File{{Name: "README.md", Data: []byte("# hello")}},
+	}
+	chrt.SetDependencies(&Chart{Metadata: &Metadata{Name: "sub", Version: "1.0.1"}})
+
+	data, err := json.MarshalIndent(chrt, "", "  ")
+	require.NoError(t, err)
+	test.AssertGoldenString(t, string(data)+"\n", "chart.json")
+
+	var decoded Chart
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	chrt.SetDependencies()
+	assert.Equal(t, chrt, &decoded)
+}
+
+// TestCRDJSON guards the JSON encoding of CRDs, which keeps the Go field
+// names.
+func TestCRDJSON(t *testing.T) {
+	crd := CRD{Name: "crds/foo.yaml", Filename: "hello/crds/foo.yaml", File: &common.File{Name: "crds/foo.yaml"}}
+	data, err := json.Marshal(crd)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Name":"crds/foo.yaml","Filename":"hello/crds/foo.yaml","File":{"name":"crds/foo.yaml","data":null}}`, string(data))
+}
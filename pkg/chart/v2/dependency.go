@@ -47,6 +47,10 @@ type Dependency struct {
 	ImportValues []interface{} `json:"import-values,omitempty" yaml:"import-values,omitempty"`
 	// Alias usable alias to be used for the chart
 	Alias string `json:"alias,omitempty" yaml:"alias,omitempty"`
+	// Digest is the digest of the locked chart. It is only set in lock files:
+	// for charts from repositories and registries it is the digest of the
+	// chart archive, and for charts from directories that of their files.
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
 }
 
 // Validate checks for common problems with the dependency datastructure in
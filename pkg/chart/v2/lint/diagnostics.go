@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v4/internal/urlutil"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// DiagnosticSeverity is the severity of a Diagnostic, as defined by the
+// Language Server Protocol.
+type DiagnosticSeverity int
+
+const (
+	SeverityError       DiagnosticSeverity = 1
+	SeverityWarning     DiagnosticSeverity = 2
+	SeverityInformation DiagnosticSeverity = 3
+	SeverityHint        DiagnosticSeverity = 4
+)
+
+// Position is a zero-based line and character offset in a file.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a file.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic is a lint message located in a file. It has the shape of a
+// Language Server Protocol diagnostic, so editors can display it directly.
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Source   string             `json:"source"`
+	Message  string             `json:"message"`
+}
+
+// PublishDiagnosticsParams holds the diagnostics of a single file, as sent
+// in a Language Server Protocol "textDocument/publishDiagnostics"
+// notification. An empty list clears the diagnostics of the file.
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// diagnosticSource is the source of all diagnostics created from lint messages.
+const diagnosticSource = "helm-lint"
+
+var (
+	// templateLocation matches the locations in template errors, such as
+	// "parse error at (mychart/templates/a.yaml:3)" or
+	// "template: mychart/templates/a.yaml:3:5: executing ...".
+	templateLocation = regexp.MustCompile(`(?:\(|template: )([^\s():]+):(\d+)(?::(\d+))?`)
+	// yamlLocation matches the line in YAML parse errors.
+	yamlLocation = regexp.MustCompile(`yaml: line (\d+)`)
+)
+
+// Diagnostics converts the lint messages of the chart in chartDir to
+// diagnostics, grouped by file URI and sorted by URI.
+//
+// Messages are placed at the template or YAML location they mention, if any.
+// Messages about a directory, or about no file at all, are placed at the start
+// of the chart's Chart.yaml.
+func Diagnostics(chartDir string, messages []support.Message) []PublishDiagnosticsParams {
+	byURI := make(map[string][]Diagnostic)
+	for _, msg := range messages {
+		path, pos := messageLocation(chartDir, msg)
+		uri := urlutil.FileURI(path)
+		byURI[uri] = append(byURI[uri], Diagnostic{
+			Range:    Range{Start: pos, End: pos},
+			Severity: diagnosticSeverity(msg.Severity),
+			Source:   diagnosticSource,
+			Message:  msg.Err.Error(),
+		})
+	}
+
+	out := make([]PublishDiagnosticsParams, 0, len(byURI))
+	for uri, diags := range byURI {
+		out = append(out, PublishDiagnosticsParams{URI: uri, Diagnostics: diags})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].URI < out[j].URI })
+	return out
+}
+
+func messageLocation(chartDir string, msg support.Message) (string, Position) {
+	text := msg.Err.Error()
+	if m := templateLocation.FindStringSubmatch(text); m != nil {
+		// Template names start with the name of the chart.
+		if _, rel, ok := strings.Cut(m[1], "/"); ok {
+			return filepath.Join(chartDir, filepath.FromSlash(rel)), position(m[2], m[3])
+		}
+	}
+
+	path := msg.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(chartDir, filepath.FromSlash(path))
+	}
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return filepath.Join(chartDir, "Chart.yaml"), Position{}
+	}
+	if m := yamlLocation.FindStringSubmatch(text); m != nil {
+		return path, position(m[1], "")
+	}
+	return path, Position{}
+}
+
+// position converts a one-based line and column to a Position.
+func position(line, column string) Position {
+	var pos Position
+	if l, err := strconv.Atoi(line); err == nil && l > 0 {
+		pos.Line = l - 1
+	}
+	if c, err := strconv.Atoi(column); err == nil && c > 0 {
+		pos.Character = c - 1
+	}
+	return pos
+}
+
+func diagnosticSeverity(severity int) DiagnosticSeverity {
+	switch severity {
+	case support.ErrorSev:
+		return SeverityError
+	case support.WarningSev:
+		return SeverityWarning
+	case support.InfoSev:
+		return SeverityInformation
+	default:
+		return SeverityHint
+	}
+}
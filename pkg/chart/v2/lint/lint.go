@@ -22,11 +22,23 @@ import (
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 )
 
 type linterOptions struct {
 	KubeVersion          *common.KubeVersion
 	SkipSchemaValidation bool
+	RequiredLabels       []string
+	RequiredAnnotations  []string
+	MaintainerPolicy     rules.MaintainerPolicy
+	ImagePolicy          rules.ImagePolicy
+	CheckEmptyOutput     bool
+	CheckSelectors       bool
+	CheckIngress         bool
+	CheckAvailability    bool
+	Cluster              *chartutil.Cluster
+	RenderContext        map[string]interface{}
+	CustomRules          []rules.CustomRule
 }
 
 type LinterOption func(lo *linterOptions)
@@ -43,24 +55,165 @@ func WithSkipSchemaValidation(skipSchemaValidation bool) LinterOption {
 	}
 }
 
-func RunAll(baseDir string, values map[string]interface{}, namespace string, options ...LinterOption) support.Linter {
+// WithLabelConventions enables the opt-in rule requiring every rendered
+// resource to carry the given labels and annotations.
+func WithLabelConventions(labels, annotations []string) LinterOption {
+	return func(lo *linterOptions) {
+		lo.RequiredLabels = labels
+		lo.RequiredAnnotations = annotations
+	}
+}
+
+// WithMaintainerPolicy enables the opt-in rule checking the maintainers in
+// Chart.yaml against the policy.
+func WithMaintainerPolicy(policy rules.MaintainerPolicy) LinterOption {
+	return func(lo *linterOptions) {
+		lo.MaintainerPolicy = policy
+	}
+}
+
+// WithImagePolicy enables the opt-in rule checking the container images of
+// rendered resources against the policy.
+func WithImagePolicy(policy rules.ImagePolicy) LinterOption {
+	return func(lo *linterOptions) {
+		lo.ImagePolicy = policy
+	}
+}
+
+// WithEmptyOutputChecks enables the opt-in rule warning about templates
+// rendering no content, and failing when the chart renders no resources.
+func WithEmptyOutputChecks(check bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.CheckEmptyOutput = check
+	}
+}
+
+// WithSelectorChecks enables the opt-in rule warning about workload selectors
+// using labels that change between releases, and Services selecting none of
+// the pods rendered by the chart.
+func WithSelectorChecks(check bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.CheckSelectors = check
+	}
+}
 
+// WithIngressChecks enables the opt-in rule checking the backends, TLS
+// secrets and classes of the Ingress and Gateway API resources rendered by
+// the chart.
+func WithIngressChecks(check bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.CheckIngress = check
+	}
+}
+
+// WithAvailabilityChecks enables the opt-in rule warning about
+// PodDisruptionBudgets allowing no disruption and HorizontalPodAutoscalers
+// conflicting with the replica count of the workload they scale.
+func WithAvailabilityChecks(check bool) LinterOption {
+	return func(lo *linterOptions) {
+		lo.CheckAvailability = check
+	}
+}
+
+// WithCluster enables the opt-in rule checking the prerequisites in the
+// requires section of Chart.yaml against the cluster.
+func WithCluster(cluster *chartutil.Cluster) LinterOption {
+	return func(lo *linterOptions) {
+		lo.Cluster = cluster
+	}
+}
+
+// WithRenderContext passes additional top-level objects, such as .Platform,
+// to the templates rendered by the rules, as embedding platforms do with the
+// Context of engine.Engine, so that references to them are not reported.
+func WithRenderContext(context map[string]interface{}) LinterOption {
+	return func(lo *linterOptions) {
+		lo.RenderContext = context
+	}
+}
+
+// WithCustomRules runs the given rules, such as those of lint rule plugins,
+// after the built-in rules.
+func WithCustomRules(customRules ...rules.CustomRule) LinterOption {
+	return func(lo *linterOptions) {
+		lo.CustomRules = append(lo.CustomRules, customRules...)
+	}
+}
+
+func RunAll(baseDir string, values map[string]interface{}, namespace string, options ...LinterOption) support.Linter {
 	chartDir, _ := filepath.Abs(baseDir)
+	return runAll(chartDir, values, namespace, nil, options)
+}
 
+func runAll(chartDir string, values map[string]interface{}, namespace string, cache *support.Cache, options []LinterOption) support.Linter {
 	lo := linterOptions{}
 	for _, option := range options {
 		option(&lo)
 	}
 
 	result := support.Linter{
-		ChartDir: chartDir,
+		ChartDir:      chartDir,
+		Cache:         cache,
+		RenderContext: lo.RenderContext,
 	}
 
-	rules.Chartfile(&result)
-	rules.ValuesWithOverrides(&result, values)
-	rules.TemplatesWithSkipSchemaValidation(&result, values, namespace, lo.KubeVersion, lo.SkipSchemaValidation)
-	rules.Dependencies(&result)
-	rules.Crds(&result)
+	result.RunRule("chartfile", rules.Chartfile)
+	result.RunRule("values", func(l *support.Linter) {
+		rules.ValuesWithOverrides(l, values)
+	})
+	result.RunRule("templates", func(l *support.Linter) {
+		rules.TemplatesWithSkipSchemaValidation(l, values, namespace, lo.KubeVersion, lo.SkipSchemaValidation)
+	})
+	result.RunRule("dependencies", rules.Dependencies)
+	result.RunRule("lockfile", rules.LockFile)
+	result.RunRule("crds", rules.Crds)
+	result.RunRule("legacy", rules.Legacy)
+
+	if len(lo.RequiredLabels) > 0 || len(lo.RequiredAnnotations) > 0 {
+		result.RunRule("labels", func(l *support.Linter) {
+			rules.LabelConventions(l, values, namespace, lo.KubeVersion, lo.RequiredLabels, lo.RequiredAnnotations)
+		})
+	}
+	if !lo.MaintainerPolicy.IsZero() {
+		result.RunRule("maintainers", func(l *support.Linter) {
+			rules.Maintainers(l, lo.MaintainerPolicy)
+		})
+	}
+	if !lo.ImagePolicy.IsZero() {
+		result.RunRule("images", func(l *support.Linter) {
+			rules.Images(l, values, namespace, lo.KubeVersion, lo.ImagePolicy)
+		})
+	}
+	if lo.CheckEmptyOutput {
+		result.RunRule("empty", func(l *support.Linter) {
+			rules.EmptyOutput(l, values, namespace, lo.KubeVersion)
+		})
+	}
+	if lo.CheckSelectors {
+		result.RunRule("selectors", func(l *support.Linter) {
+			rules.Selectors(l, values, namespace, lo.KubeVersion)
+		})
+	}
+	if lo.CheckIngress {
+		result.RunRule("ingress", func(l *support.Linter) {
+			rules.Ingress(l, values, namespace, lo.KubeVersion)
+		})
+	}
+	if lo.CheckAvailability {
+		result.RunRule("availability", func(l *support.Linter) {
+			rules.Availability(l, values, namespace, lo.KubeVersion)
+		})
+	}
+	if lo.Cluster != nil {
+		result.RunRule("requires", func(l *support.Linter) {
+			rules.Prerequisites(l, *lo.Cluster)
+		})
+	}
+	for _, rule := range lo.CustomRules {
+		result.RunRule(rule.Name, func(l *support.Linter) {
+			rules.Custom(l, values, namespace, lo.KubeVersion, rule)
+		})
+	}
 
 	return result
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package lint
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -167,6 +169,29 @@ func TestHelmCreateChart(t *testing.T) {
 	}
 }
 
+// TestHelmCreateChartProfiles tests that the charts created from every
+// `helm create --starter-profile` pass a `helm lint` test.
+func TestHelmCreateChartProfiles(t *testing.T) {
+	for _, profile := range chartutil.Profiles() {
+		t.Run(profile.Name, func(t *testing.T) {
+			createdChart, err := chartutil.CreateWithProfile("testprofile", t.TempDir(), profile.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := RunAll(createdChart, values, namespace, WithSkipSchemaValidation(true)).Messages
+			if ll := len(m); ll != 1 {
+				t.Errorf("All should have had exactly 1 error. Got %d", ll)
+				for i, msg := range m {
+					t.Logf("Message %d: %s", i, msg.Error())
+				}
+			} else if msg := m[0].Err.Error(); !strings.Contains(msg, "icon is recommended") {
+				t.Errorf("Unexpected lint error: %s", msg)
+			}
+		})
+	}
+}
+
 // TestHelmCreateChart_CheckDeprecatedWarnings checks if any default template created by `helm create` throws
 // deprecated warnings in the linter check against the current Kubernetes version (provided using ldflags).
 //
@@ -248,3 +273,27 @@ func TestMalformedTemplate(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderContext(t *testing.T) {
+	dir := t.TempDir()
+	createdChart, err := chartutil.Create("platformchart", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tpl := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}-platform\ndata:\n  tenant: {{ .Platform.tenant.name | quote }}\n"
+	if err := os.WriteFile(filepath.Join(createdChart, "templates", "platform.yaml"), []byte(tpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := RunAll(createdChart, values, namespace, WithSkipSchemaValidation(true)).Messages
+	if !hasMessage(m, "nil pointer evaluating interface {}.tenant") {
+		t.Errorf("expected the reference to .Platform to fail without a render context, got %v", m)
+	}
+
+	m = RunAll(createdChart, values, namespace, WithSkipSchemaValidation(true), WithRenderContext(map[string]interface{}{
+		"Platform": map[string]interface{}{"tenant": map[string]interface{}{"name": "acme"}},
+	})).Messages
+	if len(m) != 1 || !strings.Contains(m[0].Err.Error(), "icon is recommended") {
+		t.Errorf("expected only the icon to be reported with the render context, got %v", m)
+	}
+}
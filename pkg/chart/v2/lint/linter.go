@@ -0,0 +1,77 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"path/filepath"
+	"sync"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// Linter lints charts repeatedly, as editor integrations and watch modes do.
+//
+// Unlike RunAll, a Linter keeps the loaded chart, the compiled values schemas
+// and the parsed templates of every chart it lints. A chart is loaded again
+// only when a file in its directory changes, and templates and schemas are
+// parsed again only when their content changes.
+//
+// A Linter is safe for concurrent use.
+type Linter struct {
+	options []LinterOption
+
+	mu     sync.Mutex
+	caches map[string]*support.Cache
+}
+
+// NewLinter creates a Linter that applies the given options to every run.
+func NewLinter(options ...LinterOption) *Linter {
+	return &Linter{
+		options: options,
+		caches:  make(map[string]*support.Cache),
+	}
+}
+
+// Lint runs all lint rules against the chart in baseDir. The options are
+// applied after those given to NewLinter.
+func (l *Linter) Lint(baseDir string, values map[string]interface{}, namespace string, options ...LinterOption) support.Linter {
+	chartDir, _ := filepath.Abs(baseDir)
+	opts := append(append([]LinterOption{}, l.options...), options...)
+	return runAll(chartDir, values, namespace, l.cache(chartDir), opts)
+}
+
+// Invalidate forgets everything cached for the chart in baseDir.
+//
+// Changes to the chart are detected without calling Invalidate. It is useful
+// when files may change without their size or modification time changing.
+func (l *Linter) Invalidate(baseDir string) {
+	chartDir, _ := filepath.Abs(baseDir)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.caches, chartDir)
+}
+
+func (l *Linter) cache(chartDir string) *support.Cache {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c, ok := l.caches[chartDir]
+	if !ok {
+		c = support.NewCache()
+		l.caches[chartDir] = c
+	}
+	return c
+}
@@ -0,0 +1,77 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestLinterMatchesRunAll(t *testing.T) {
+	linter := NewLinter()
+	for _, dir := range []string{goodChartDir, badChartDir, badValuesFileDir, subChartValuesDir, malformedTemplate} {
+		want := RunAll(dir, values, namespace).Messages
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, want, linter.Lint(dir, values, namespace).Messages, "%s, run %d", dir, i+1)
+		}
+	}
+}
+
+func TestLinterDetectsChanges(t *testing.T) {
+	dir, err := chartutil.Create("relint", t.TempDir())
+	require.NoError(t, err)
+
+	linter := NewLinter(WithSkipSchemaValidation(true))
+	result := linter.Lint(dir, values, namespace)
+	assert.Less(t, result.HighestSeverity, support.ErrorSev)
+
+	tpl := filepath.Join(dir, "templates", "broken.yaml")
+	require.NoError(t, os.WriteFile(tpl, []byte("{{ .Values.missing.field }"), 0644))
+	result = linter.Lint(dir, values, namespace)
+	assert.Equal(t, support.ErrorSev, result.HighestSeverity)
+	assert.True(t, hasMessage(result.Messages, "broken.yaml"), "expected a message for the broken template, got %v", result.Messages)
+
+	require.NoError(t, os.Remove(tpl))
+	result = linter.Lint(dir, values, namespace)
+	assert.Less(t, result.HighestSeverity, support.ErrorSev)
+}
+
+func TestLinterValuesDoNotLeakBetweenRuns(t *testing.T) {
+	linter := NewLinter()
+	want := RunAll(subChartValuesDir, values, namespace).Messages
+
+	overrides := map[string]interface{}{"subchart": map[string]interface{}{"enabled": false}}
+	linter.Lint(subChartValuesDir, overrides, namespace)
+	assert.Equal(t, want, linter.Lint(subChartValuesDir, values, namespace).Messages)
+}
+
+func hasMessage(messages []support.Message, substr string) bool {
+	for _, msg := range messages {
+		if strings.Contains(msg.Error(), substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/plugin/schema"
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+// NewRulePlugin creates a custom rule running the installed lint/v1 plugin of
+// the given name.
+//
+// Lint rule plugins are given the chart metadata, values and rendered
+// templates in their input message, rather than access to the chart
+// directory, so that plugins using the Wasm runtime can run without
+// filesystem or network access.
+func NewRulePlugin(settings *cli.EnvSettings, name string, args ...string) (rules.CustomRule, error) {
+	descriptor := plugin.Descriptor{
+		Name: name,
+		Type: "lint/v1",
+	}
+	p, err := plugin.FindPlugin(filepath.SplitList(settings.PluginsDirectory), descriptor)
+	if err != nil {
+		return rules.CustomRule{}, err
+	}
+
+	return rules.CustomRule{
+		Name: "plugin/" + name,
+		Lint: func(ch *chart.Chart, values common.Values, manifests map[string]string) ([]support.Message, error) {
+			return runRulePlugin(p, ch, values, manifests, args)
+		},
+	}, nil
+}
+
+func runRulePlugin(p plugin.Plugin, ch *chart.Chart, values common.Values, manifests map[string]string, args []string) ([]support.Message, error) {
+	input := &plugin.Input{
+		Message: schema.InputMessageLintV1{
+			Chart:     ch.Name(),
+			Version:   ch.Metadata.Version,
+			Values:    values,
+			Manifests: manifests,
+			ExtraArgs: args,
+		},
+	}
+	output, err := p.Invoke(context.Background(), input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke lint plugin %q: %w", p.Metadata().Name, err)
+	}
+
+	var messages []support.Message
+	for _, m := range output.Message.(schema.OutputMessageLintV1).Messages {
+		severity, err := support.ParseSeverity(m.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("lint plugin %q: %w", p.Metadata().Name, err)
+		}
+		messages = append(messages, support.NewMessage(severity, m.Path, errors.New(m.Message)))
+	}
+	return messages, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+func TestCustomRules(t *testing.T) {
+	var gotValues common.Values
+	var gotManifests map[string]string
+	rule := rules.CustomRule{
+		Name: "custom",
+		Lint: func(_ *chart.Chart, values common.Values, manifests map[string]string) ([]support.Message, error) {
+			gotValues, gotManifests = values, manifests
+			return []support.Message{support.NewMessage(support.InfoSev, "values.yaml", errors.New("custom finding"))}, nil
+		},
+	}
+	failing := rules.CustomRule{
+		Name: "failing",
+		Lint: func(*chart.Chart, common.Values, map[string]string) ([]support.Message, error) {
+			return nil, errors.New("boom")
+		},
+	}
+
+	linter := RunAll(goodChartDir, values, namespace, WithCustomRules(rule, failing))
+	require.Len(t, linter.Messages, 2)
+	assert.Equal(t, "custom", linter.MessageRule(0))
+	assert.Equal(t, "[INFO] values.yaml: custom finding", linter.Messages[0].Error())
+	assert.Equal(t, "failing", linter.MessageRule(1))
+	assert.Equal(t, support.ErrorSev, linter.Messages[1].Severity)
+	assert.Contains(t, linter.Messages[1].Err.Error(), `lint rule "failing" failed: boom`)
+
+	assert.Equal(t, "goodone-here", gotValues["name"])
+	assert.Contains(t, gotManifests, "templates/goodone.yaml")
+}
+
+func TestRulePlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+	settings := cli.New()
+	settings.PluginsDirectory = "testdata/plugins"
+
+	rule, err := NewRulePlugin(settings, "lint-v1")
+	require.NoError(t, err)
+	assert.Equal(t, "plugin/lint-v1", rule.Name)
+
+	m := RunAll(goodChartDir, values, namespace, WithCustomRules(rule)).Messages
+	require.Len(t, m, 1)
+	assert.Equal(t, "[WARNING] templates/goodone.yaml: name is goodone-here", m[0].Error())
+
+	_, err = NewRulePlugin(settings, "missing")
+	assert.Error(t, err)
+}
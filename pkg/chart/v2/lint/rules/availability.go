@@ -0,0 +1,194 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// scalableKinds are the kinds of workloads with a replica count.
+var scalableKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"ReplicaSet":  true,
+}
+
+// Availability lints the availability settings of the rendered resources of
+// the chart.
+//
+// It warns about PodDisruptionBudgets allowing no disruption of the pods of
+// the workloads rendered by the chart with the linted values, which blocks
+// node drains, and about HorizontalPodAutoscalers conflicting with the
+// replica count of the workload they scale.
+func Availability(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion) {
+	objects, err := allRenderedObjects(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return
+	}
+
+	autoscalers := make(map[string]renderedObject)
+	for _, obj := range objects {
+		if obj.kind() == "HorizontalPodAutoscaler" {
+			target := nestedMap(obj.Object, "spec", "scaleTargetRef")
+			autoscalers[nestedString(target, "kind")+"/"+nestedString(target, "name")] = obj
+		}
+	}
+
+	var workloads []scaledWorkload
+	for _, obj := range objects {
+		if !scalableKinds[obj.kind()] {
+			continue
+		}
+		labels, _ := podTemplateLabels(obj)
+		w := scaledWorkload{renderedObject: obj, labels: labels, replicas: 1}
+		if hpa, ok := autoscalers[obj.kind()+"/"+obj.name()]; ok {
+			w.replicas = intField(hpa.Object, 1, "spec", "minReplicas")
+		} else {
+			w.replicas = intField(obj.Object, 1, "spec", "replicas")
+		}
+		workloads = append(workloads, w)
+	}
+
+	for _, obj := range objects {
+		// The dependencies are linted on their own
+		if strings.HasPrefix(obj.Path, "charts/") {
+			continue
+		}
+		switch obj.kind() {
+		case "PodDisruptionBudget":
+			linter.RunLinterRule(support.WarningSev, obj.Path, validateDisruptionBudget(obj, workloads))
+		case "HorizontalPodAutoscaler":
+			linter.RunLinterRule(support.WarningSev, obj.Path, validateAutoscaler(obj, objects))
+		}
+	}
+}
+
+// scaledWorkload is a workload, with the labels of its pods and its minimum
+// replica count.
+type scaledWorkload struct {
+	renderedObject
+	labels   map[string]interface{}
+	replicas int
+}
+
+// validateDisruptionBudget checks that the PodDisruptionBudget obj allows the
+// eviction of at least one of the pods of the workloads it selects.
+func validateDisruptionBudget(obj renderedObject, workloads []scaledWorkload) error {
+	selector := nestedMap(obj.Object, "spec", "selector")
+	matchLabels := nestedMap(selector, "matchLabels")
+	// Expressions are not evaluated, so the selected pods are unknown.
+	if len(matchLabels) == 0 || selector["matchExpressions"] != nil {
+		return nil
+	}
+	pods := 0
+	for _, w := range workloads {
+		if matchesSelector(matchLabels, w.labels) {
+			pods += w.replicas
+		}
+	}
+	if pods == 0 {
+		return nil
+	}
+
+	spec := nestedMap(obj.Object, "spec")
+	var field string
+	var allowed int
+	if value, ok := spec["minAvailable"]; ok {
+		n, ok := scaleIntOrPercent(value, pods)
+		if !ok {
+			return nil
+		}
+		field = fmt.Sprintf("minAvailable: %v", value)
+		allowed = pods - n
+	} else if value, ok := spec["maxUnavailable"]; ok {
+		n, ok := scaleIntOrPercent(value, pods)
+		if !ok {
+			return nil
+		}
+		field = fmt.Sprintf("maxUnavailable: %v", value)
+		allowed = n
+	}
+	if field == "" || allowed > 0 {
+		return nil
+	}
+	return fmt.Errorf("%s %q allows no disruption of the %d pods it selects (%s): node drains are blocked", obj.kind(), obj.name(), pods, field)
+}
+
+// validateAutoscaler checks that the bounds of the HorizontalPodAutoscaler
+// obj are consistent, and that the workload it scales, if rendered, does not
+// set its own replica count.
+func validateAutoscaler(obj renderedObject, objects []renderedObject) error {
+	minReplicas := intField(obj.Object, 1, "spec", "minReplicas")
+	maxReplicas := intField(obj.Object, 0, "spec", "maxReplicas")
+	if maxReplicas > 0 && minReplicas > maxReplicas {
+		return fmt.Errorf("%s %q has minReplicas %d greater than maxReplicas %d", obj.kind(), obj.name(), minReplicas, maxReplicas)
+	}
+
+	target := nestedMap(obj.Object, "spec", "scaleTargetRef")
+	for _, w := range objects {
+		if w.kind() != nestedString(target, "kind") || w.name() != nestedString(target, "name") {
+			continue
+		}
+		if _, ok := nestedMap(w.Object, "spec")["replicas"]; !ok {
+			return nil
+		}
+		replicas := intField(w.Object, 1, "spec", "replicas")
+		return fmt.Errorf("%s %q scales %s %q, which sets replicas to %d: upgrades reset the replica count chosen by the autoscaler (minReplicas %d, maxReplicas %d)", obj.kind(), obj.name(), w.kind(), w.name(), replicas, minReplicas, maxReplicas)
+	}
+	return nil
+}
+
+// intField returns the integer found by following fields in obj, or def if
+// the path does not exist or is not a number.
+func intField(obj map[string]interface{}, def int, fields ...string) int {
+	switch v := nestedField(obj, fields...).(type) {
+	case float64:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return def
+}
+
+// scaleIntOrPercent returns value, an integer or a percentage of total,
+// rounding percentages up like the disruption controller does. It returns
+// false if value is neither.
+func scaleIntOrPercent(value interface{}, total int) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), true
+	case string:
+		if p, ok := strings.CutSuffix(v, "%"); ok {
+			percent, err := strconv.Atoi(p)
+			if err != nil {
+				return 0, false
+			}
+			return int(math.Ceil(float64(percent) * float64(total) / 100)), true
+		}
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
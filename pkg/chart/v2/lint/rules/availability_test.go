@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const availabilityResources = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: single
+spec:
+  template:
+    metadata:
+      labels:
+        app: single
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: web
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        app: db
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: single
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: single
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  minAvailable: 50%
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web-strict
+spec:
+  maxUnavailable: 0%
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: db
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: db
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: db
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: StatefulSet
+    name: db
+  minReplicas: 1
+  maxReplicas: 5
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: inverted
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: external
+  minReplicas: 4
+  maxReplicas: 2
+`
+
+func TestAvailability(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "availability", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/resources.yaml", Data: []byte(availabilityResources)},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Availability(&linter, values, namespace, nil)
+
+	var got []string
+	for _, msg := range linter.Messages {
+		assert.Equal(t, support.WarningSev, msg.Severity)
+		got = append(got, msg.Path+": "+msg.Err.Error())
+	}
+	assert.Equal(t, []string{
+		`templates/resources.yaml: PodDisruptionBudget "single" allows no disruption of the 1 pods it selects (minAvailable: 1): node drains are blocked`,
+		`templates/resources.yaml: PodDisruptionBudget "web-strict" allows no disruption of the 3 pods it selects (maxUnavailable: 0%): node drains are blocked`,
+		`templates/resources.yaml: PodDisruptionBudget "db" allows no disruption of the 1 pods it selects (minAvailable: 1): node drains are blocked`,
+		`templates/resources.yaml: HorizontalPodAutoscaler "db" scales StatefulSet "db", which sets replicas to 2: upgrades reset the replica count chosen by the autoscaler (minReplicas 1, maxReplicas 5)`,
+		`templates/resources.yaml: HorizontalPodAutoscaler "inverted" has minReplicas 4 greater than maxReplicas 2`,
+	}, got)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules // import "helm.sh/helm/v4/pkg/chart/v2/lint/rules"
+
+import (
+	"errors"
+
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// The categories of lint messages, for reports that group them by what they
+// check.
+const (
+	// CategoryLint is the category of the messages of the general rules.
+	CategoryLint = "lint"
+	// CategorySchema is the category of values not matching a schema.
+	CategorySchema = "schema"
+	// CategoryDeprecation is the category of deprecated Kubernetes APIs.
+	CategoryDeprecation = "deprecation"
+	// CategoryPolicy is the category of the opt-in policy rules, such as
+	// the labels, maintainers and images policies.
+	CategoryPolicy = "policy"
+)
+
+// policyRules are the names the policy rules are run with.
+var policyRules = map[string]bool{
+	"labels":      true,
+	"maintainers": true,
+	"images":      true,
+}
+
+// schemaError is a values schema violation found when rendering the
+// templates.
+type schemaError struct {
+	err error
+}
+
+func (e schemaError) Error() string { return e.err.Error() }
+func (e schemaError) Unwrap() error { return e.err }
+
+// Category returns the category of a lint message reported by the named rule.
+func Category(msg support.Message, rule string) string {
+	var deprecated deprecatedAPIError
+	var schema schemaError
+	var validation util.JSONSchemaValidationError
+	switch {
+	case errors.As(msg.Err, &deprecated):
+		return CategoryDeprecation
+	case errors.As(msg.Err, &schema), errors.As(msg.Err, &validation):
+		return CategorySchema
+	case policyRules[rule]:
+		return CategoryPolicy
+	}
+	return CategoryLint
+}
@@ -0,0 +1,48 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		msg  support.Message
+		want string
+	}{
+		{"lint", "chartfile", support.Message{Err: errors.New("icon is recommended")}, CategoryLint},
+		{"policy", "images", support.Message{Err: errors.New("image is not pinned")}, CategoryPolicy},
+		{"deprecation", "templates", support.Message{Err: deprecatedAPIError{Deprecated: "extensions/v1beta1 Ingress"}}, CategoryDeprecation},
+		{"schema", "templates", support.Message{Err: schemaError{errors.New("values don't meet the specifications")}}, CategorySchema},
+		{"wrapped schema", "values", support.Message{Err: fmt.Errorf("values.yaml: %w", schemaError{errors.New("invalid")})}, CategorySchema},
+		{"no rule", "", support.Message{Err: errors.New("unable to load chart")}, CategoryLint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Category(tt.msg, tt.rule); got != tt.want {
+				t.Errorf("expected category %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
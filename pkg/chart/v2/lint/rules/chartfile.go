@@ -67,6 +67,7 @@ func Chartfile(linter *support.Linter) {
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartIconURL(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartType(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartDependencies(chartFile))
+	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartRequires(chartFile))
 	linter.RunLinterRule(support.WarningSev, chartFileName, validateChartVersionStrictSemVerV2(chartFile))
 }
 
@@ -222,6 +223,13 @@ func validateChartType(cf *chart.Metadata) error {
 	return nil
 }
 
+func validateChartRequires(cf *chart.Metadata) error {
+	if cf.Requires != nil && cf.APIVersion != chart.APIVersionV2 {
+		return fmt.Errorf("requires is not valid in apiVersion '%s'. It is valid in apiVersion '%s'", cf.APIVersion, chart.APIVersionV2)
+	}
+	return cf.Requires.Validate()
+}
+
 // loadChartFileForTypeCheck loads the Chart.yaml
 // in a generic form of a map[string]interface{}, so that the type
 // of the values can be checked
@@ -29,7 +29,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
 )
 
 // Crds lints the CRDs in the Linter.
@@ -48,7 +47,7 @@ func Crds(linter *support.Linter) {
 	}
 
 	// Load chart and parse CRDs
-	chart, err := loader.Load(linter.ChartDir)
+	chart, err := linter.LoadChart()
 
 	chartLoaded := linter.RunLinterRule(support.ErrorSev, fpath, err)
 
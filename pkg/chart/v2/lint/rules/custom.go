@@ -0,0 +1,65 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// CustomRule is a lint rule provided by a plugin or by an application
+// embedding Helm.
+type CustomRule struct {
+	// Name is the name the rule is run with.
+	Name string
+	// Lint checks a chart. The values are the values the chart was rendered
+	// with, merged with its defaults, and the manifests are the rendered
+	// templates by path relative to the chart, as "templates/service.yaml".
+	Lint func(ch *chart.Chart, values common.Values, manifests map[string]string) ([]support.Message, error)
+}
+
+// Custom runs a custom rule against the rendered chart. A chart that does not
+// render is skipped, as the Templates rule reports it.
+func Custom(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion, rule CustomRule) {
+	ch, rendered, err := renderChart(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return
+	}
+	cvals, err := util.CoalesceValues(ch, values)
+	if err != nil {
+		return
+	}
+
+	manifests := make(map[string]string, len(rendered))
+	for name, content := range rendered {
+		manifests[strings.TrimPrefix(name, ch.Name()+"/")] = content
+	}
+
+	messages, err := rule.Lint(ch, cvals, manifests)
+	if err != nil {
+		linter.RunLinterRule(support.ErrorSev, "", fmt.Errorf("lint rule %q failed: %w", rule.Name, err))
+		return
+	}
+	for _, m := range messages {
+		linter.RunLinterRule(m.Severity, m.Path, m.Err)
+	}
+}
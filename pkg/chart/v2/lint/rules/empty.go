@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// EmptyOutput lints the rendered output of the chart, warning about templates
+// rendering nothing but whitespace and comments with the provided values, as
+// the result of a condition that is always false or of missing values. It
+// fails if the chart, including its dependencies, renders no resources at all.
+//
+// This rule is opt-in: it is only run by RunAll when empty output checks are
+// enabled, as templates enabled by values, such as an optional Ingress, are
+// empty by default.
+func EmptyOutput(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion) {
+	ch, rendered, err := renderChart(linter, values, namespace, kubeVersion)
+	if err != nil || len(ch.Templates) == 0 || (ch.Metadata != nil && ch.Metadata.Type == "library") {
+		return
+	}
+
+	for _, template := range ch.Templates {
+		if !isManifest(template.Name) || strings.HasPrefix(path.Base(template.Name), "_") {
+			continue
+		}
+		linter.RunLinterRule(support.WarningSev, template.Name, validateNotEmpty(rendered[path.Join(ch.Name(), template.Name)]))
+	}
+	linter.RunLinterRule(support.ErrorSev, "templates/", validateRendersResources(rendered))
+}
+
+// validateNotEmpty checks that a template renders more than whitespace and
+// comments.
+func validateNotEmpty(content string) error {
+	if isEmptyManifest(content) {
+		return errors.New("template renders no content with the provided values")
+	}
+	return nil
+}
+
+// validateRendersResources checks that at least one of the rendered manifests
+// is not empty.
+func validateRendersResources(rendered map[string]string) error {
+	for name, content := range rendered {
+		if isManifest(name) && !isEmptyManifest(content) {
+			return nil
+		}
+	}
+	return errors.New("chart renders no resources with the provided values")
+}
+
+func isManifest(name string) bool {
+	ext := path.Ext(name)
+	return ext == ".yaml" || ext == ".yml" || ext == ".json"
+}
+
+// isEmptyManifest returns true if a manifest holds nothing but whitespace,
+// comments and document separators.
+func isEmptyManifest(content string) bool {
+	for line := range strings.SplitSeq(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line != "---" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestEmptyOutput(t *testing.T) {
+	lint := func(t *testing.T, templates ...*common.File) []support.Message {
+		t.Helper()
+		mychart := chart.Chart{
+			Metadata:  &chart.Metadata{APIVersion: "v2", Name: "empty", Version: "0.1.0"},
+			Templates: templates,
+		}
+		tmpdir := t.TempDir()
+		if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+			t.Fatal(err)
+		}
+		linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+		EmptyOutput(&linter, values, namespace, nil)
+		return linter.Messages
+	}
+
+	configMap := &common.File{Name: "templates/cm.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\n")}
+	helpers := &common.File{Name: "templates/_helpers.tpl", Data: []byte(`{{define "x"}}{{end}}`)}
+	notes := &common.File{Name: "templates/NOTES.txt", Data: []byte("")}
+	disabled := &common.File{Name: "templates/disabled.yaml", Data: []byte("# disabled\n{{- if .Values.enabled }}\nkind: ConfigMap\n{{- end }}\n---\n")}
+
+	if msgs := lint(t, configMap, helpers, notes); len(msgs) != 0 {
+		t.Errorf("Expected no messages, got %v", msgs)
+	}
+
+	msgs := lint(t, configMap, disabled)
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 message, got %v", msgs)
+	}
+	if msgs[0].Severity != support.WarningSev || msgs[0].Path != "templates/disabled.yaml" || !strings.Contains(msgs[0].Err.Error(), "template renders no content") {
+		t.Errorf("Unexpected message %s", msgs[0])
+	}
+
+	msgs = lint(t, disabled, helpers)
+	if len(msgs) != 2 {
+		t.Fatalf("Expected 2 messages, got %v", msgs)
+	}
+	if msgs[1].Severity != support.ErrorSev || !strings.Contains(msgs[1].Err.Error(), "chart renders no resources") {
+		t.Errorf("Unexpected message %s", msgs[1])
+	}
+}
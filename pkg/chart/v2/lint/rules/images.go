@@ -0,0 +1,161 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// ImagePolicy describes the requirements on the container images used by the
+// rendered resources of a chart.
+type ImagePolicy struct {
+	// RequirePinned requires every image to have a digest or a tag other than
+	// "latest".
+	RequirePinned bool
+	// RequireDigest requires every image to have a digest.
+	RequireDigest bool
+	// AllowedRegistries restricts images to these registries, such as
+	// "registry.example.com", or to repositories under a registry path, such
+	// as "docker.io/library". Images without a registry are on "docker.io".
+	// If empty, any registry is allowed.
+	AllowedRegistries []string
+	// Verify, if set, is called with the fully qualified reference of every
+	// image, such as "docker.io/library/nginx:1.25", and returns an error if
+	// the image does not exist.
+	Verify func(image string) error
+}
+
+// IsZero reports whether the policy places no requirements on images.
+func (p ImagePolicy) IsZero() bool {
+	return !p.RequirePinned && !p.RequireDigest && len(p.AllowedRegistries) == 0 && p.Verify == nil
+}
+
+// Images lints the container images of the rendered resources of the chart
+// against the policy.
+//
+// This rule is opt-in: it is only run by RunAll when an image policy is
+// configured.
+func Images(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion, policy ImagePolicy) {
+	objects, err := renderedObjects(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return
+	}
+
+	verified := make(map[string]error)
+	for _, obj := range objects {
+		for _, image := range containerImages(obj.Object) {
+			linter.RunLinterRule(support.ErrorSev, obj.Path, validateImage(obj, image, policy, verified))
+		}
+	}
+}
+
+// validateImage checks a single image against the policy. Results of Verify
+// are remembered in verified, so every image is only looked up once.
+func validateImage(obj renderedObject, image string, policy ImagePolicy, verified map[string]error) error {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return fmt.Errorf("%s %q has an invalid image %q: %w", obj.kind(), obj.name(), image, err)
+	}
+
+	var problems []string
+	_, digested := named.(reference.Digested)
+	tagged, isTagged := named.(reference.Tagged)
+	switch {
+	case policy.RequireDigest && !digested:
+		problems = append(problems, "is not pinned to a digest")
+	case policy.RequirePinned && !digested && (!isTagged || tagged.Tag() == "latest"):
+		problems = append(problems, "is not pinned to a digest or a tag other than latest")
+	}
+
+	if len(policy.AllowedRegistries) > 0 && !registryAllowed(named, policy.AllowedRegistries) {
+		problems = append(problems, fmt.Sprintf("is not from an allowed registry (%s)", strings.Join(policy.AllowedRegistries, ", ")))
+	}
+
+	if policy.Verify != nil {
+		ref := reference.TagNameOnly(named).String()
+		err, ok := verified[ref]
+		if !ok {
+			err = policy.Verify(ref)
+			verified[ref] = err
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("could not be found: %v", err))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s %q image %q %s", obj.kind(), obj.name(), image, strings.Join(problems, "; "))
+}
+
+func registryAllowed(named reference.Named, allowed []string) bool {
+	name := named.Name()
+	for _, entry := range allowed {
+		entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+		if entry != "" && (name == entry || strings.HasPrefix(name, entry+"/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// containerImages returns the images of all containers, init containers and
+// ephemeral containers found anywhere in obj, so that pod templates nested in
+// workloads and custom resources are covered alike.
+func containerImages(obj map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if key == "containers" || key == "initContainers" || key == "ephemeralContainers" {
+					if list, ok := child.([]interface{}); ok {
+						for _, c := range list {
+							if c, ok := c.(map[string]interface{}); ok {
+								if image, ok := c["image"].(string); ok {
+									seen[image] = true
+								}
+							}
+						}
+					}
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(obj)
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
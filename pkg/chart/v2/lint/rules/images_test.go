@@ -0,0 +1,160 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const imagesDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: web
+        image: registry.example.com/team/web:1.2.3
+      - name: sidecar
+        image: nginx:latest
+`
+
+const imagesCronJob = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: job
+            image: registry.example.com/team/job@sha256:0000000000000000000000000000000000000000000000000000000000000000
+`
+
+func TestContainerImages(t *testing.T) {
+	obj := map[string]interface{}{
+		"kind": "Rollout",
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers":          []interface{}{map[string]interface{}{"image": "b:1"}, map[string]interface{}{"image": "a:1"}},
+					"ephemeralContainers": []interface{}{map[string]interface{}{"image": "b:1"}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, []string{"a:1", "b:1"}, containerImages(obj))
+}
+
+func TestValidateImage(t *testing.T) {
+	obj := renderedObject{Object: map[string]interface{}{"kind": "Pod", "metadata": map[string]interface{}{"name": "p"}}}
+	digest := "@sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	tests := []struct {
+		image  string
+		policy ImagePolicy
+		errMsg string
+	}{
+		{"nginx:1.25", ImagePolicy{RequirePinned: true}, ""},
+		{"nginx" + digest, ImagePolicy{RequirePinned: true}, ""},
+		{"nginx", ImagePolicy{RequirePinned: true}, "is not pinned to a digest or a tag other than latest"},
+		{"nginx:latest", ImagePolicy{RequirePinned: true}, "is not pinned to a digest or a tag other than latest"},
+		{"nginx:1.25", ImagePolicy{RequireDigest: true}, "is not pinned to a digest"},
+		{"nginx:1.25" + digest, ImagePolicy{RequireDigest: true}, ""},
+		{"nginx:1.25", ImagePolicy{AllowedRegistries: []string{"docker.io/library"}}, ""},
+		{"nginx:1.25", ImagePolicy{AllowedRegistries: []string{"registry.example.com"}}, "is not from an allowed registry (registry.example.com)"},
+		{"registry.example.com/team/web:1", ImagePolicy{AllowedRegistries: []string{"registry.example.com/"}}, ""},
+		{"registry.example.com.evil.io/web:1", ImagePolicy{AllowedRegistries: []string{"registry.example.com"}}, "is not from an allowed registry"},
+		{"Nginx:1.25", ImagePolicy{RequirePinned: true}, "has an invalid image"},
+	}
+	for _, tt := range tests {
+		err := validateImage(obj, tt.image, tt.policy, map[string]error{})
+		if tt.errMsg == "" {
+			assert.NoError(t, err, tt.image)
+			continue
+		}
+		if assert.Error(t, err, tt.image) {
+			assert.Contains(t, err.Error(), tt.errMsg, tt.image)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "images",
+			Version:    "0.1.0",
+		},
+		Templates: []*common.File{
+			{Name: "templates/deployment.yaml", Data: []byte(imagesDeployment)},
+			{Name: "templates/cronjob.yaml", Data: []byte(imagesCronJob)},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	var lookups []string
+	policy := ImagePolicy{
+		RequirePinned:     true,
+		AllowedRegistries: []string{"registry.example.com"},
+		Verify: func(image string) error {
+			lookups = append(lookups, image)
+			if strings.Contains(image, "/job@") {
+				return errors.New("manifest unknown")
+			}
+			return nil
+		},
+	}
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Images(&linter, values, namespace, nil, policy)
+
+	var got []string
+	for _, msg := range linter.Messages {
+		assert.Equal(t, support.ErrorSev, msg.Severity)
+		got = append(got, msg.Path+": "+msg.Err.Error())
+	}
+	assert.Equal(t, []string{
+		`templates/cronjob.yaml: CronJob "nightly" image "registry.example.com/team/job@sha256:0000000000000000000000000000000000000000000000000000000000000000" could not be found: manifest unknown`,
+		`templates/deployment.yaml: Deployment "web" image "busybox" is not pinned to a digest or a tag other than latest; is not from an allowed registry (registry.example.com)`,
+		`templates/deployment.yaml: Deployment "web" image "nginx:latest" is not pinned to a digest or a tag other than latest; is not from an allowed registry (registry.example.com)`,
+	}, got)
+	assert.ElementsMatch(t, []string{
+		"docker.io/library/busybox:latest",
+		"docker.io/library/nginx:latest",
+		"registry.example.com/team/web:1.2.3",
+		"registry.example.com/team/job@sha256:0000000000000000000000000000000000000000000000000000000000000000",
+	}, lookups)
+}
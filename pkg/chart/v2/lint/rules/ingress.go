@@ -0,0 +1,326 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// ExternalSecretsAnnotation is the Chart.yaml annotation documenting the
+// Secrets used by the chart that are created outside of it, as a comma
+// separated list of names or glob patterns.
+const ExternalSecretsAnnotation = "helm.sh/external-secrets"
+
+// ingressClassAnnotation is the annotation selecting the controller of an
+// Ingress before the ingressClassName field was introduced.
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// certManagerIssuerAnnotations are the annotations of an Ingress asking
+// cert-manager to create its TLS secrets.
+var certManagerIssuerAnnotations = []string{
+	"cert-manager.io/issuer",
+	"cert-manager.io/cluster-issuer",
+}
+
+// Ingress lints the Ingress and Gateway API resources rendered by the chart.
+//
+// It warns about Ingresses and HTTPRoutes routing traffic to a Service, or a
+// port of a Service, not rendered by the chart or its dependencies, and
+// about TLS secrets neither rendered by the chart nor documented as external
+// with the ExternalSecretsAnnotation of the chart. It also checks the
+// ingressClassName of Ingresses against the targeted Kubernetes version,
+// noting the Ingresses relying on the default IngressClass of the cluster.
+func Ingress(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion) {
+	ch, err := linter.LoadChart()
+	if err != nil {
+		return
+	}
+	objects, err := allRenderedObjects(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return
+	}
+	if kubeVersion == nil {
+		kubeVersion = &common.DefaultCapabilities.KubeVersion
+	}
+
+	var external []string
+	if ch.Metadata != nil {
+		for _, pattern := range strings.Split(ch.Metadata.Annotations[ExternalSecretsAnnotation], ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				external = append(external, pattern)
+			}
+		}
+	}
+
+	services := make(map[string]renderedObject)
+	secrets := make(map[string]bool)
+	for _, obj := range objects {
+		switch obj.kind() {
+		case "Service":
+			services[obj.name()] = obj
+		case "Secret":
+			secrets[obj.name()] = true
+		case "Certificate":
+			if name := nestedString(obj.Object, "spec", "secretName"); name != "" {
+				secrets[name] = true
+			}
+		}
+	}
+	isSecret := func(name string) bool {
+		if secrets[name] {
+			return true
+		}
+		for _, pattern := range external {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, obj := range objects {
+		// The dependencies are linted on their own
+		if strings.HasPrefix(obj.Path, "charts/") {
+			continue
+		}
+		switch obj.kind() {
+		case "Ingress":
+			for _, backend := range ingressBackends(obj) {
+				linter.RunLinterRule(support.WarningSev, obj.Path, validateBackend(obj, backend, services))
+			}
+			linter.RunLinterRule(support.WarningSev, obj.Path, validateIngressTLS(obj, isSecret))
+			linter.RunLinterRule(support.WarningSev, obj.Path, validateIngressClass(obj, kubeVersion))
+			linter.RunLinterRule(support.InfoSev, obj.Path, validateIngressClassName(obj, kubeVersion))
+		case "HTTPRoute":
+			for _, backend := range httpRouteBackends(obj, namespace) {
+				linter.RunLinterRule(support.WarningSev, obj.Path, validateBackend(obj, backend, services))
+			}
+		case "Gateway":
+			linter.RunLinterRule(support.WarningSev, obj.Path, validateGatewayTLS(obj, namespace, isSecret))
+		}
+	}
+}
+
+// serviceBackend is a port of a Service traffic is routed to.
+type serviceBackend struct {
+	Name string
+	// Port is the number or the name of the port.
+	Port string
+}
+
+// ingressBackends returns the Service backends of the Ingress obj, for both
+// the networking.k8s.io/v1 and the older v1beta1 schemas.
+func ingressBackends(obj renderedObject) []serviceBackend {
+	backend := func(b map[string]interface{}) (serviceBackend, bool) {
+		if b == nil {
+			return serviceBackend{}, false
+		}
+		if service := nestedMap(b, "service"); service != nil {
+			port := nestedMap(service, "port")
+			value := port["number"]
+			if value == nil {
+				value = port["name"]
+			}
+			return serviceBackend{Name: nestedString(service, "name"), Port: portString(value)}, true
+		}
+		if name := nestedString(b, "serviceName"); name != "" {
+			return serviceBackend{Name: name, Port: portString(b["servicePort"])}, true
+		}
+		return serviceBackend{}, false
+	}
+
+	var backends []serviceBackend
+	spec := nestedMap(obj.Object, "spec")
+	if b, ok := backend(nestedMap(spec, "defaultBackend")); ok {
+		backends = append(backends, b)
+	}
+	if b, ok := backend(nestedMap(spec, "backend")); ok {
+		backends = append(backends, b)
+	}
+	rules, _ := spec["rules"].([]interface{})
+	for _, rule := range rules {
+		rule, _ := rule.(map[string]interface{})
+		paths, _ := nestedField(rule, "http", "paths").([]interface{})
+		for _, p := range paths {
+			p, _ := p.(map[string]interface{})
+			if b, ok := backend(nestedMap(p, "backend")); ok {
+				backends = append(backends, b)
+			}
+		}
+	}
+	return backends
+}
+
+// httpRouteBackends returns the Service backends of the HTTPRoute obj in
+// namespace.
+func httpRouteBackends(obj renderedObject, namespace string) []serviceBackend {
+	var backends []serviceBackend
+	rules, _ := nestedField(obj.Object, "spec", "rules").([]interface{})
+	for _, rule := range rules {
+		rule, _ := rule.(map[string]interface{})
+		refs, _ := rule["backendRefs"].([]interface{})
+		for _, ref := range refs {
+			ref, _ := ref.(map[string]interface{})
+			if ref == nil || !isCoreRef(ref, "Service") {
+				continue
+			}
+			if ns := nestedString(ref, "namespace"); ns != "" && ns != namespace {
+				continue
+			}
+			backends = append(backends, serviceBackend{Name: nestedString(ref, "name"), Port: portString(ref["port"])})
+		}
+	}
+	return backends
+}
+
+// isCoreRef returns true if the Gateway API object reference ref is to an
+// object of the core group of kind, which is the default.
+func isCoreRef(ref map[string]interface{}, kind string) bool {
+	if k := nestedString(ref, "kind"); k != "" && k != kind {
+		return false
+	}
+	group := nestedString(ref, "group")
+	return group == "" || group == "core"
+}
+
+func portString(port interface{}) string {
+	switch p := port.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(p, 'f', -1, 64)
+	default:
+		return fmt.Sprint(p)
+	}
+}
+
+// validateBackend checks that backend is a port of one of services.
+func validateBackend(obj renderedObject, backend serviceBackend, services map[string]renderedObject) error {
+	if backend.Name == "" {
+		return nil
+	}
+	service, ok := services[backend.Name]
+	if !ok {
+		return fmt.Errorf("%s %q routes traffic to Service %q, which is not rendered by the chart", obj.kind(), obj.name(), backend.Name)
+	}
+	if backend.Port == "" {
+		return nil
+	}
+	ports, _ := nestedField(service.Object, "spec", "ports").([]interface{})
+	for _, port := range ports {
+		port, _ := port.(map[string]interface{})
+		if portString(port["port"]) == backend.Port || nestedString(port, "name") == backend.Port {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %q routes traffic to port %s of Service %q, which does not expose it", obj.kind(), obj.name(), backend.Port, backend.Name)
+}
+
+// validateIngressTLS checks that the TLS secrets of the Ingress obj are
+// known by isSecret, or created by cert-manager.
+func validateIngressTLS(obj renderedObject, isSecret func(string) bool) error {
+	annotations := nestedMap(obj.Object, "metadata", "annotations")
+	for _, annotation := range certManagerIssuerAnnotations {
+		if _, ok := annotations[annotation]; ok {
+			return nil
+		}
+	}
+
+	var missing []string
+	tls, _ := nestedField(obj.Object, "spec", "tls").([]interface{})
+	for _, t := range tls {
+		t, _ := t.(map[string]interface{})
+		if name := nestedString(t, "secretName"); name != "" && !isSecret(name) {
+			missing = append(missing, name)
+		}
+	}
+	return missingSecretsError(obj, missing)
+}
+
+// validateGatewayTLS checks that the certificates of the listeners of the
+// Gateway obj in namespace are known by isSecret.
+func validateGatewayTLS(obj renderedObject, namespace string, isSecret func(string) bool) error {
+	var missing []string
+	listeners, _ := nestedField(obj.Object, "spec", "listeners").([]interface{})
+	for _, listener := range listeners {
+		listener, _ := listener.(map[string]interface{})
+		refs, _ := nestedField(listener, "tls", "certificateRefs").([]interface{})
+		for _, ref := range refs {
+			ref, _ := ref.(map[string]interface{})
+			if ref == nil || !isCoreRef(ref, "Secret") {
+				continue
+			}
+			if ns := nestedString(ref, "namespace"); ns != "" && ns != namespace {
+				continue
+			}
+			if name := nestedString(ref, "name"); name != "" && !isSecret(name) {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missingSecretsError(obj, missing)
+}
+
+func missingSecretsError(obj renderedObject, missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s %q uses TLS secrets not rendered by the chart (%s): list them in the %s annotation of Chart.yaml if they are created outside of it", obj.kind(), obj.name(), strings.Join(missing, ", "), ExternalSecretsAnnotation)
+}
+
+// validateIngressClass checks that the class of the Ingress obj is selected
+// the way kubeVersion supports. The ingressClassName field deprecated the
+// kubernetes.io/ingress.class annotation.
+func validateIngressClass(obj renderedObject, kubeVersion *common.KubeVersion) error {
+	hasField := supportsIngressClassName(kubeVersion)
+
+	className := nestedString(obj.Object, "spec", "ingressClassName")
+	_, hasAnnotation := nestedMap(obj.Object, "metadata", "annotations")[ingressClassAnnotation]
+	switch {
+	case !hasField && className != "":
+		return fmt.Errorf("%s %q sets ingressClassName, which Kubernetes %s does not support: use the %s annotation", obj.kind(), obj.name(), kubeVersion, ingressClassAnnotation)
+	case hasField && className == "" && hasAnnotation:
+		return fmt.Errorf("%s %q uses the deprecated %s annotation: use ingressClassName", obj.kind(), obj.name(), ingressClassAnnotation)
+	}
+	return nil
+}
+
+// validateIngressClassName checks that the Ingress obj selects its class,
+// rather than relying on the default IngressClass of the cluster.
+func validateIngressClassName(obj renderedObject, kubeVersion *common.KubeVersion) error {
+	if !supportsIngressClassName(kubeVersion) || nestedString(obj.Object, "spec", "ingressClassName") != "" {
+		return nil
+	}
+	if _, ok := nestedMap(obj.Object, "metadata", "annotations")[ingressClassAnnotation]; ok {
+		return nil
+	}
+	return fmt.Errorf("%s %q has no ingressClassName: it is only served if the cluster has a default IngressClass", obj.kind(), obj.name())
+}
+
+// supportsIngressClassName returns true if kubeVersion has the
+// ingressClassName field of Ingresses, which was added in 1.18.
+func supportsIngressClassName(kubeVersion *common.KubeVersion) bool {
+	major, _ := strconv.Atoi(kubeVersion.Major)
+	minor, _ := strconv.Atoi(strings.TrimSuffix(kubeVersion.Minor, "+"))
+	return major > 1 || (major == 1 && minor >= 18)
+}
@@ -0,0 +1,192 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const ingressResources = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - name: http
+    port: 80
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-tls
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  ingressClassName: nginx
+  tls:
+  - secretName: web-tls
+  - secretName: shared-wildcard-tls
+  rules:
+  - http:
+      paths:
+      - path: /
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              name: http
+      - path: /api
+        pathType: Prefix
+        backend:
+          service:
+            name: web
+            port:
+              number: 8080
+      - path: /db
+        pathType: Prefix
+        backend:
+          service:
+            name: db
+            port:
+              number: 5432
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: legacy
+  annotations:
+    kubernetes.io/ingress.class: nginx
+spec:
+  tls:
+  - secretName: legacy-tls
+  defaultBackend:
+    service:
+      name: web
+      port:
+        number: 80
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: issued
+  annotations:
+    cert-manager.io/cluster-issuer: letsencrypt
+spec:
+  tls:
+  - secretName: issued-tls
+  defaultBackend:
+    service:
+      name: web
+      port:
+        number: 80
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: web
+spec:
+  gatewayClassName: example
+  listeners:
+  - name: https
+    protocol: HTTPS
+    port: 443
+    tls:
+      certificateRefs:
+      - name: web-tls
+      - name: gateway-tls
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+spec:
+  rules:
+  - backendRefs:
+    - name: web
+      port: 80
+    - name: web
+      port: 443
+    - name: other
+      namespace: elsewhere
+      port: 80
+    - group: example.com
+      kind: Backend
+      name: custom
+`
+
+func TestIngress(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:  "v2",
+			Name:        "ingress",
+			Version:     "0.1.0",
+			Annotations: map[string]string{ExternalSecretsAnnotation: "shared-*, other"},
+		},
+		Templates: []*common.File{
+			{Name: "templates/resources.yaml", Data: []byte(ingressResources)},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Ingress(&linter, values, namespace, nil)
+
+	var got []string
+	for _, msg := range linter.Messages {
+		got = append(got, msg.Error())
+	}
+	assert.Equal(t, []string{
+		`[WARNING] templates/resources.yaml: Ingress "web" routes traffic to port 8080 of Service "web", which does not expose it`,
+		`[WARNING] templates/resources.yaml: Ingress "web" routes traffic to Service "db", which is not rendered by the chart`,
+		`[WARNING] templates/resources.yaml: Ingress "legacy" uses TLS secrets not rendered by the chart (legacy-tls): list them in the helm.sh/external-secrets annotation of Chart.yaml if they are created outside of it`,
+		`[WARNING] templates/resources.yaml: Ingress "legacy" uses the deprecated kubernetes.io/ingress.class annotation: use ingressClassName`,
+		`[INFO] templates/resources.yaml: Ingress "issued" has no ingressClassName: it is only served if the cluster has a default IngressClass`,
+		`[WARNING] templates/resources.yaml: Gateway "web" uses TLS secrets not rendered by the chart (gateway-tls): list them in the helm.sh/external-secrets annotation of Chart.yaml if they are created outside of it`,
+		`[WARNING] templates/resources.yaml: HTTPRoute "web" routes traffic to port 443 of Service "web", which does not expose it`,
+	}, got)
+}
+
+func TestIngressClassKubeVersion(t *testing.T) {
+	obj := renderedObject{Object: map[string]interface{}{
+		"kind":     "Ingress",
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec":     map[string]interface{}{"ingressClassName": "nginx"},
+	}}
+
+	old := &common.KubeVersion{Version: "v1.17.0", Major: "1", Minor: "17"}
+	assert.EqualError(t, validateIngressClass(obj, old), `Ingress "web" sets ingressClassName, which Kubernetes v1.17.0 does not support: use the kubernetes.io/ingress.class annotation`)
+	assert.NoError(t, validateIngressClassName(obj, old))
+
+	current := &common.KubeVersion{Version: "v1.20.0", Major: "1", Minor: "20"}
+	assert.NoError(t, validateIngressClass(obj, current))
+	assert.NoError(t, validateIngressClassName(obj, current))
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// RecommendedLabels are the labels the Helm documentation recommends every
+// chart resource carries.
+//
+// See https://helm.sh/docs/chart_best_practices/labels/
+var RecommendedLabels = []string{
+	"app.kubernetes.io/name",
+	"app.kubernetes.io/instance",
+	"app.kubernetes.io/managed-by",
+	"helm.sh/chart",
+}
+
+// LabelConventions lints the rendered resources of the chart, checking that
+// each one carries the required labels and annotations.
+//
+// This rule is opt-in: it is only run by RunAll when label conventions are
+// configured.
+func LabelConventions(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion, labels, annotations []string) {
+	objects, err := renderedObjects(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return
+	}
+
+	for _, obj := range objects {
+		linter.RunLinterRule(support.WarningSev, obj.Path, validateRequiredKeys(obj, "label", nestedMap(obj.Object, "metadata", "labels"), labels))
+		linter.RunLinterRule(support.WarningSev, obj.Path, validateRequiredKeys(obj, "annotation", nestedMap(obj.Object, "metadata", "annotations"), annotations))
+	}
+}
+
+func validateRequiredKeys(obj renderedObject, what string, present map[string]interface{}, required []string) error {
+	var missing []string
+	for _, key := range required {
+		if _, ok := present[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s %q is missing required %s(s): %s", obj.kind(), obj.name(), what, strings.Join(missing, ", "))
+}
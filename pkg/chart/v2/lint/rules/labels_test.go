@@ -0,0 +1,91 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const labelledConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: labelled
+  labels:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+    app.kubernetes.io/instance: {{ .Release.Name }}
+    app.kubernetes.io/managed-by: {{ .Release.Service }}
+    helm.sh/chart: {{ .Chart.Name }}-{{ .Chart.Version }}
+  annotations:
+    example.com/owner: team-a
+`
+
+const unlabelledConfigMap = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unlabelled
+  labels:
+    app.kubernetes.io/name: {{ .Chart.Name }}
+`
+
+func TestLabelConventions(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "labels",
+			Version:    "0.1.0",
+		},
+		Templates: []*common.File{
+			{Name: "templates/labelled.yaml", Data: []byte(labelledConfigMap)},
+			{Name: "templates/unlabelled.yaml", Data: []byte(unlabelledConfigMap)},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	LabelConventions(&linter, values, namespace, nil, RecommendedLabels, []string{"example.com/owner"})
+
+	if l := len(linter.Messages); l != 2 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 2 lint warnings, got %d", l)
+	}
+	for _, msg := range linter.Messages {
+		if msg.Path != "templates/unlabelled.yaml" {
+			t.Errorf("Unexpected path %q", msg.Path)
+		}
+		if msg.Severity != support.WarningSev {
+			t.Errorf("Expected a warning, got severity %d", msg.Severity)
+		}
+	}
+	if !strings.Contains(linter.Messages[0].Err.Error(), "app.kubernetes.io/instance, app.kubernetes.io/managed-by, helm.sh/chart") {
+		t.Errorf("Unexpected error: %s", linter.Messages[0].Err)
+	}
+	if !strings.Contains(linter.Messages[1].Err.Error(), "missing required annotation(s): example.com/owner") {
+		t.Errorf("Unexpected error: %s", linter.Messages[1].Err)
+	}
+}
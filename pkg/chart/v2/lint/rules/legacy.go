@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+var (
+	// crdInstallHook matches a helm.sh/hook annotation listing crd-install.
+	crdInstallHook = regexp.MustCompile(`["']?helm\.sh/hook["']?\s*:\s*["']?[a-z,\- ]*\bcrd-install\b`)
+	// removedObjects matches the built-in objects that were removed in Helm 3.
+	removedObjects = regexp.MustCompile(`\.(Release\.Time|Capabilities\.TillerVersion)\b`)
+)
+
+// Legacy lints a chart for constructs of the Helm 2 era that are deprecated
+// or silently ignored by Helm 3 and later. 'helm chart migrate' rewrites the
+// chart to fix those that can be fixed automatically.
+//
+// Charts with apiVersion v1 are still supported, so their requirements.yaml
+// and requirements.lock files are not reported.
+func Legacy(linter *support.Linter) {
+	cf, err := chartutil.LoadChartfile(filepath.Join(linter.ChartDir, "Chart.yaml"))
+	if err != nil {
+		// Reported by the chartfile rule.
+		return
+	}
+	if cf.APIVersion != chart.APIVersionV1 {
+		for _, name := range []string{"requirements.yaml", "requirements.lock"} {
+			if _, err := os.Stat(filepath.Join(linter.ChartDir, name)); err == nil {
+				linter.RunLinterRule(support.WarningSev, name, validateLegacyRequirementsFile(name))
+			}
+		}
+	}
+
+	c, err := linter.LoadChart()
+	if err != nil {
+		// Reported by the templates rule.
+		return
+	}
+	linter.RunLinterRule(support.WarningSev, "values.yaml", validateGlobalValues(c))
+	for _, t := range c.Templates {
+		linter.RunLinterRule(support.WarningSev, t.Name, validateCrdInstallHook(t))
+		linter.RunLinterRule(support.WarningSev, t.Name, validateRemovedObjects(t))
+	}
+}
+
+func validateLegacyRequirementsFile(name string) error {
+	replacement := "the dependencies field of Chart.yaml"
+	if name == "requirements.lock" {
+		replacement = "Chart.lock"
+	}
+	return fmt.Errorf("%s is deprecated in favor of %s. Run 'helm chart migrate' to move it", name, replacement)
+}
+
+// validateGlobalValues checks the global values of a chart. Globals must be a
+// map, and are only shared with the dependencies when set in the top-level
+// global section; globals set in the section of a dependency are seen by that
+// dependency alone and are overridden by the top-level ones.
+func validateGlobalValues(c *chart.Chart) error {
+	var errs []error
+	if global, ok := c.Values[common.GlobalKey]; ok {
+		if _, ok := global.(map[string]interface{}); !ok {
+			errs = append(errs, fmt.Errorf("%s must be a map, got %T. Helm ignores globals that are not a map", common.GlobalKey, global))
+		}
+	}
+
+	var names []string
+	for _, dep := range c.Metadata.Dependencies {
+		name := dep.Name
+		if dep.Alias != "" {
+			name = dep.Alias
+		}
+		if section, ok := c.Values[name].(map[string]interface{}); ok {
+			if _, ok := section[common.GlobalKey]; ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, fmt.Errorf("%s.%s is only seen by the %s dependency and is overridden by the top-level %s section. Set shared values in the top-level %s section instead", name, common.GlobalKey, name, common.GlobalKey, common.GlobalKey))
+	}
+	return errors.Join(errs...)
+}
+
+func validateCrdInstallHook(t *common.File) error {
+	if !crdInstallHook.Match(t.Data) {
+		return nil
+	}
+	return errors.New("the crd-install hook was removed in Helm 3 and the resource is no longer installed. Move the CRD to the crds/ directory, or run 'helm chart migrate' to move it")
+}
+
+func validateRemovedObjects(t *common.File) error {
+	seen := make(map[string]bool)
+	var removed []string
+	for _, m := range removedObjects.FindAllSubmatch(t.Data, -1) {
+		if name := "." + string(m[1]); !seen[name] {
+			seen[name] = true
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("built-in objects removed in Helm 3 are used: %s", strings.Join(removed, ", "))
+}
@@ -0,0 +1,148 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestValidateGlobalValues(t *testing.T) {
+	tests := []struct {
+		values map[string]interface{}
+		err    string
+	}{
+		{values: map[string]interface{}{"global": map[string]interface{}{"image": "x"}, "db": map[string]interface{}{"port": 1}}},
+		{values: map[string]interface{}{"global": "x"}, err: "global must be a map, got string"},
+		{values: map[string]interface{}{"db": map[string]interface{}{"global": map[string]interface{}{}}}, err: "db.global is only seen by the db dependency"},
+		// Only the sections of dependencies are checked.
+		{values: map[string]interface{}{"other": map[string]interface{}{"global": map[string]interface{}{}}}},
+	}
+	for _, tt := range tests {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Dependencies: []*chart.Dependency{{Name: "postgresql", Alias: "db"}}},
+			Values:   tt.values,
+		}
+		err := validateGlobalValues(c)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("Unexpected error for %v: %s", tt.values, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Expected error %q for %v, got %v", tt.err, tt.values, err)
+		}
+	}
+}
+
+func TestValidateCrdInstallHook(t *testing.T) {
+	for data, hook := range map[string]bool{
+		"annotations:\n  helm.sh/hook: crd-install\n":                   true,
+		"annotations:\n  \"helm.sh/hook\": \"pre-install,crd-install\"": true,
+		"annotations:\n  helm.sh/hook: pre-install\n":                   false,
+		"annotations:\n  helm.sh/hook-weight: \"1\"\n":                  false,
+	} {
+		if err := validateCrdInstallHook(&common.File{Data: []byte(data)}); (err != nil) != hook {
+			t.Errorf("Expected the crd-install hook to be reported %t for %q, got %v", hook, data, err)
+		}
+	}
+}
+
+func TestValidateRemovedObjects(t *testing.T) {
+	err := validateRemovedObjects(&common.File{Data: []byte(`{{ .Release.Time }} {{ .Capabilities.TillerVersion.SemVer }} {{ .Release.Time.Seconds }} {{ .Release.Name }}`)})
+	if err == nil || err.Error() != "built-in objects removed in Helm 3 are used: .Release.Time, .Capabilities.TillerVersion" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if err := validateRemovedObjects(&common.File{Data: []byte(`{{ .Release.Timeout }} {{ .Capabilities.KubeVersion }}`)}); err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+}
+
+func TestLegacy(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion:   "v2",
+			Name:         "legacy",
+			Version:      "0.1.0",
+			Dependencies: []*chart.Dependency{{Name: "db", Version: "1.0.0"}},
+		},
+		Templates: []*common.File{
+			{Name: "templates/crd.yaml", Data: []byte("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  annotations:\n    helm.sh/hook: crd-install\n")},
+			{Name: "templates/configmap.yaml", Data: []byte("data:\n  time: {{ .Release.Time }}\n")},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(tmpdir, mychart.Name())
+	for name, data := range map[string]string{
+		"values.yaml":       "db:\n  global:\n    x: 1\n",
+		"requirements.lock": "dependencies: []\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	linter := support.Linter{ChartDir: dir}
+	Legacy(&linter)
+
+	expected := []struct{ path, text string }{
+		{"requirements.lock", "requirements.lock is deprecated in favor of Chart.lock"},
+		{"values.yaml", "db.global is only seen by the db dependency"},
+		{"templates/configmap.yaml", ".Release.Time"},
+		{"templates/crd.yaml", "crd-install hook was removed in Helm 3"},
+	}
+	if len(linter.Messages) != len(expected) {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected %d lint warnings, got %d", len(expected), len(linter.Messages))
+	}
+	for i, want := range expected {
+		msg := linter.Messages[i]
+		if msg.Severity != support.WarningSev || msg.Path != want.path || !strings.Contains(msg.Err.Error(), want.text) {
+			t.Errorf("Unexpected message %d: %s", i, msg)
+		}
+	}
+
+	// The requirements files of apiVersion v1 charts are not deprecated.
+	mychart.Metadata.APIVersion = "v1"
+	mychart.Metadata.Dependencies = nil
+	if err := chartutil.SaveChartfile(filepath.Join(dir, "Chart.yaml"), mychart.Metadata); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"values.yaml", "templates/crd.yaml", "templates/configmap.yaml"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linter = support.Linter{ChartDir: dir}
+	Legacy(&linter)
+	if len(linter.Messages) != 0 {
+		t.Errorf("Unexpected messages %v", linter.Messages)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// validateLibraryTemplateName checks that a template of a library chart is a
+// partial. The engine skips other templates of library charts, so their
+// content would silently never be used.
+func validateLibraryTemplateName(name string) error {
+	if strings.HasPrefix(path.Base(name), "_") {
+		return nil
+	}
+	return errors.New("library charts are not rendered, so only templates starting with '_' are used. Rename the file or move its content into a named template")
+}
+
+// validateDefineNames checks that the templates defined in a template file
+// are namespaced with the chart name, such as "mylib.labels".
+//
+// Defined templates are global across a chart and its dependencies, so a
+// template that is not namespaced may be overridden by, or override, one with
+// the same name in another chart.
+//
+// See https://helm.sh/docs/chart_best_practices/templates/#names-of-defined-templates
+func validateDefineNames(chartName, name string, data []byte) error {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(string(data), "{{", "}}", trees); err != nil {
+		// Parse errors are reported when the chart is rendered.
+		return nil
+	}
+
+	var invalid []string
+	for define, t := range trees {
+		if t.ParseName != name || define == name {
+			continue
+		}
+		if !strings.HasPrefix(define, chartName+".") {
+			invalid = append(invalid, fmt.Sprintf("%q", define))
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	sort.Strings(invalid)
+	return fmt.Errorf("defined template(s) %s should be prefixed with the chart name, as in %q", strings.Join(invalid, ", "), chartName+".name")
+}
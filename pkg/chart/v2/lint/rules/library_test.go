@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestValidateDefineNames(t *testing.T) {
+	tests := []struct {
+		data    string
+		invalid string
+	}{
+		{data: `{{- define "mylib.labels" -}}app: x{{- end }}`},
+		{data: `{{- define "mylib.labels" }}{{ include "other.name" . }}{{ end }}`},
+		{data: `{{ .Values.x | nindent 2 }}`},
+		{data: `{{ define "mylib.a" }}{{ end }}{{ define "labels" }}{{ end }}{{ block "fullname" . }}{{ end }}`, invalid: `"fullname", "labels"`},
+		{data: `{{ define "mylibrary.labels" }}{{ end }}`, invalid: `"mylibrary.labels"`},
+		// Parse errors are reported when rendering.
+		{data: `{{ define "labels" }}`},
+	}
+	for _, tt := range tests {
+		err := validateDefineNames("mylib", "templates/_helpers.tpl", []byte(tt.data))
+		if tt.invalid == "" {
+			if err != nil {
+				t.Errorf("Unexpected error for %q: %s", tt.data, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.invalid) {
+			t.Errorf("Expected error naming %s for %q, got %v", tt.invalid, tt.data, err)
+		}
+	}
+}
+
+func TestLibraryChartTemplates(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "mylib",
+			Version:    "0.1.0",
+			Type:       "library",
+		},
+		Templates: []*common.File{
+			{
+				Name: "templates/_helpers.tpl",
+				Data: []byte("{{- define \"mylib.labels\" -}}\napp: {{ .Chart.Name }}\n{{- end }}\n{{- define \"fullname\" -}}\n{{ .Release.Name }}\n{{- end }}\n"),
+			},
+			{
+				// Not rendered for a library chart, so the missing name is
+				// not reported.
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  labels: {}\n"),
+			},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+
+	if l := len(linter.Messages); l != 2 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 2 lint warnings, got %d", l)
+	}
+	for i, want := range []struct{ path, text string }{
+		{"templates/_helpers.tpl", `defined template(s) "fullname" should be prefixed with the chart name`},
+		{"templates/configmap.yaml", "only templates starting with '_' are used"},
+	} {
+		msg := linter.Messages[i]
+		if msg.Severity != support.WarningSev || msg.Path != want.path || !strings.Contains(msg.Err.Error(), want.text) {
+			t.Errorf("Unexpected message %d: %s", i, msg)
+		}
+	}
+}
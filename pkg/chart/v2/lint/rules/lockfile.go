@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v4/internal/resolver"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// LockFile lints the consistency of a chart's lock file with the dependencies
+// in Chart.yaml and the charts vendored in the charts/ directory.
+//
+// Charts without a lock file are not checked.
+func LockFile(linter *support.Linter) {
+	c, err := linter.LoadChart()
+	if err != nil || c.Lock == nil {
+		// Load errors are reported by the Dependencies rule.
+		return
+	}
+
+	lockFileName := "Chart.lock"
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		lockFileName = "requirements.lock"
+	}
+
+	linter.RunLinterRule(support.WarningSev, lockFileName, validateLockDigest(c))
+	linter.RunLinterRule(support.WarningSev, lockFileName, validateLockEntries(c))
+	linter.RunLinterRule(support.WarningSev, lockFileName, validateVendoredVersions(c))
+}
+
+// validateLockDigest checks that the lock file was generated from the current
+// dependencies in Chart.yaml, the same way 'helm dependency build' does.
+func validateLockDigest(c *chart.Chart) error {
+	digest, err := resolver.HashReq(c.Metadata.Dependencies, c.Lock.Dependencies)
+	if err != nil {
+		return err
+	}
+	if digest == c.Lock.Digest {
+		return nil
+	}
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		// Lock files generated by Helm 2 use a different digest.
+		// See https://github.com/helm/helm/issues/7233
+		if v2Digest, err := resolver.HashV2Req(c.Metadata.Dependencies); err == nil && v2Digest == c.Lock.Digest {
+			return nil
+		}
+	}
+	return fmt.Errorf("digest %q does not match the dependencies in Chart.yaml; run 'helm dependency update'", c.Lock.Digest)
+}
+
+// validateLockEntries checks that every dependency in Chart.yaml is locked and
+// that every locked dependency is still in Chart.yaml.
+func validateLockEntries(c *chart.Chart) error {
+	locked := map[string]bool{}
+	for _, dep := range c.Lock.Dependencies {
+		if dep != nil {
+			locked[dep.Name] = true
+		}
+	}
+	required := map[string]bool{}
+	var missing, extra []string
+	for _, dep := range c.Metadata.Dependencies {
+		if dep == nil || required[dep.Name] {
+			continue
+		}
+		required[dep.Name] = true
+		if !locked[dep.Name] {
+			missing = append(missing, dep.Name)
+		}
+	}
+	for _, dep := range c.Lock.Dependencies {
+		if dep != nil && !required[dep.Name] {
+			extra = append(extra, dep.Name)
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing entries for these dependencies: "+strings.Join(missing, ","))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "entries for dependencies not in Chart.yaml: "+strings.Join(extra, ","))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("lock file has %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateVendoredVersions checks that the charts in the charts/ directory
+// have the versions recorded in the lock file.
+func validateVendoredVersions(c *chart.Chart) error {
+	locked := map[string]string{}
+	for _, dep := range c.Lock.Dependencies {
+		if dep != nil {
+			locked[dep.Name] = dep.Version
+		}
+	}
+	var mismatched []string
+	for _, sub := range c.Dependencies() {
+		version, ok := locked[sub.Name()]
+		if !ok || sub.Metadata.Version == version {
+			continue
+		}
+		mismatched = append(mismatched, fmt.Sprintf("%s (%s, locked %s)", sub.Name(), sub.Metadata.Version, version))
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("charts directory has versions that do not match the lock file: %s", strings.Join(mismatched, ","))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/resolver"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func chartWithLock(t *testing.T) *chart.Chart {
+	t.Helper()
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "locked",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{
+				{Name: "sub1", Version: "^1.0.0", Repository: "https://example.com/charts"},
+				{Name: "sub2", Version: "^2.0.0", Repository: "https://example.com/charts"},
+			},
+		},
+		Lock: &chart.Lock{
+			Dependencies: []*chart.Dependency{
+				{Name: "sub1", Version: "1.0.1", Repository: "https://example.com/charts"},
+				{Name: "sub2", Version: "2.3.0", Repository: "https://example.com/charts"},
+			},
+		},
+	}
+	digest, err := resolver.HashReq(c.Metadata.Dependencies, c.Lock.Dependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Lock.Digest = digest
+	c.SetDependencies(
+		&chart.Chart{Metadata: &chart.Metadata{Name: "sub1", Version: "1.0.1", APIVersion: "v2"}},
+		&chart.Chart{Metadata: &chart.Metadata{Name: "sub2", Version: "2.3.0", APIVersion: "v2"}},
+	)
+	return c
+}
+
+func TestValidateLockDigest(t *testing.T) {
+	c := chartWithLock(t)
+	if err := validateLockDigest(c); err != nil {
+		t.Errorf("expected digest to match, got %v", err)
+	}
+
+	c.Metadata.Dependencies[1].Version = "^3.0.0"
+	if err := validateLockDigest(c); err == nil {
+		t.Error("expected a stale digest to be flagged")
+	}
+}
+
+func TestValidateLockEntries(t *testing.T) {
+	c := chartWithLock(t)
+	if err := validateLockEntries(c); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	c.Metadata.Dependencies = append(c.Metadata.Dependencies, &chart.Dependency{Name: "sub3"})
+	c.Lock.Dependencies = append(c.Lock.Dependencies, &chart.Dependency{Name: "sub4"})
+	err := validateLockEntries(c)
+	if err == nil {
+		t.Fatal("expected missing and extra lock entries to be flagged")
+	}
+	if !strings.Contains(err.Error(), "missing entries for these dependencies: sub3") || !strings.Contains(err.Error(), "not in Chart.yaml: sub4") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateVendoredVersions(t *testing.T) {
+	c := chartWithLock(t)
+	if err := validateVendoredVersions(c); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	c.Dependencies()[1].Metadata.Version = "2.2.0"
+	err := validateVendoredVersions(c)
+	if err == nil {
+		t.Fatal("expected a mismatched vendored version to be flagged")
+	}
+	if !strings.Contains(err.Error(), "sub2 (2.2.0, locked 2.3.0)") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	c := chartWithLock(t)
+	c.Dependencies()[0].Metadata.Version = "1.0.0"
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(c, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+	// SaveDir does not write the lock file.
+	lock, err := yaml.Marshal(c.Lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpdir, c.Name(), "Chart.lock"), lock, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, c.Name())}
+	LockFile(&linter)
+
+	if len(linter.Messages) != 1 {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected 1 lint warning, got %d", len(linter.Messages))
+	}
+	if msg := linter.Messages[0]; msg.Path != "Chart.lock" || msg.Severity != support.WarningSev {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// MaintainerPolicy describes the ownership requirements an organization places
+// on the maintainers listed in Chart.yaml.
+type MaintainerPolicy struct {
+	// Allowlist holds the maintainer emails and email domains that are
+	// allowed. An entry such as "jane@example.com" must match an email exactly;
+	// an entry such as "example.com" or "@example.com" is a domain and also
+	// matches its subdomains. Matching ignores case.
+	// If empty, any email is allowed.
+	Allowlist []string
+	// MinMaintainers is the number of maintainers a chart must list.
+	MinMaintainers int
+}
+
+// IsZero reports whether the policy places no requirements on maintainers.
+func (p MaintainerPolicy) IsZero() bool {
+	return len(p.Allowlist) == 0 && p.MinMaintainers <= 0
+}
+
+// Maintainers lints the maintainers in Chart.yaml against the policy.
+//
+// This rule is opt-in: it is only run by RunAll when a maintainer policy is
+// configured.
+func Maintainers(linter *support.Linter, policy MaintainerPolicy) {
+	chartFileName := "Chart.yaml"
+	chartFile, err := chartutil.LoadChartfile(filepath.Join(linter.ChartDir, chartFileName))
+	if err != nil {
+		// Reported by the Chartfile rule.
+		return
+	}
+
+	linter.RunLinterRule(support.ErrorSev, chartFileName, validateMaintainerCount(chartFile, policy.MinMaintainers))
+	for _, m := range chartFile.Maintainers {
+		if m == nil {
+			continue
+		}
+		linter.RunLinterRule(support.ErrorSev, chartFileName, validateMaintainerAllowed(m, policy.Allowlist))
+	}
+}
+
+func validateMaintainerCount(cf *chart.Metadata, minMaintainers int) error {
+	count := 0
+	for _, m := range cf.Maintainers {
+		if m != nil {
+			count++
+		}
+	}
+	if count < minMaintainers {
+		return fmt.Errorf("chart lists %d maintainer(s), at least %d are required", count, minMaintainers)
+	}
+	return nil
+}
+
+func validateMaintainerAllowed(m *chart.Maintainer, allowlist []string) error {
+	if len(allowlist) == 0 {
+		return nil
+	}
+	if m.Email == "" {
+		return fmt.Errorf("maintainer '%s' has no email, which is required to check it against the allowlist", m.Name)
+	}
+
+	email := strings.ToLower(m.Email)
+	_, domain, _ := strings.Cut(email, "@")
+	for _, entry := range allowlist {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if strings.Index(entry, "@") > 0 {
+			if email == entry {
+				return nil
+			}
+			continue
+		}
+		entry = strings.TrimPrefix(entry, "@")
+		if domain == entry || strings.HasSuffix(domain, "."+entry) {
+			return nil
+		}
+	}
+	return fmt.Errorf("email '%s' of maintainer '%s' is not in the allowlist", m.Email, m.Name)
+}
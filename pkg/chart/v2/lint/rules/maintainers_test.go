@@ -0,0 +1,93 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestValidateMaintainerAllowed(t *testing.T) {
+	allowlist := []string{"example.com", "@example.org", "Jane@Partner.io"}
+	tests := []struct {
+		email   string
+		allowed bool
+	}{
+		{"bob@example.com", true},
+		{"bob@EXAMPLE.com", true},
+		{"bob@eu.example.com", true},
+		{"bob@badexample.com", false},
+		{"bob@example.org", true},
+		{"jane@partner.io", true},
+		{"john@partner.io", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		err := validateMaintainerAllowed(&chart.Maintainer{Name: "m", Email: tt.email}, allowlist)
+		if tt.allowed && err != nil {
+			t.Errorf("expected %q to be allowed, got %v", tt.email, err)
+		}
+		if !tt.allowed && err == nil {
+			t.Errorf("expected %q not to be allowed", tt.email)
+		}
+	}
+
+	if err := validateMaintainerAllowed(&chart.Maintainer{Name: "m"}, nil); err != nil {
+		t.Errorf("expected any maintainer to be allowed without an allowlist, got %v", err)
+	}
+}
+
+func TestMaintainers(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "maintainers",
+			Version:    "0.1.0",
+			Maintainers: []*chart.Maintainer{
+				{Name: "alice", Email: "alice@example.com"},
+				{Name: "mallory", Email: "mallory@elsewhere.net"},
+			},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Maintainers(&linter, MaintainerPolicy{Allowlist: []string{"example.com"}, MinMaintainers: 3})
+
+	want := []string{
+		"chart lists 2 maintainer(s), at least 3 are required",
+		"email 'mallory@elsewhere.net' of maintainer 'mallory' is not in the allowlist",
+	}
+	if len(linter.Messages) != len(want) {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected %d lint errors, got %d", len(want), len(linter.Messages))
+	}
+	for i, msg := range linter.Messages {
+		if msg.Severity != support.ErrorSev || msg.Err.Error() != want[i] {
+			t.Errorf("Unexpected message %d: %s", i, msg)
+		}
+	}
+}
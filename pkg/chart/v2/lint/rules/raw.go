@@ -0,0 +1,43 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"bytes"
+	"errors"
+
+	"helm.sh/helm/v4/pkg/engine"
+)
+
+// validateRawManifestName checks that a file in raw/ is a manifest. Only YAML
+// and JSON files are included in the rendered output, others are ignored.
+func validateRawManifestName(name string) error {
+	if engine.IsRawManifest(name) {
+		return nil
+	}
+	return errors.New("only .yaml, .yml and .json files in raw/ are installed, this file is ignored")
+}
+
+// validateRawNoActions checks that a raw manifest has no template actions.
+// Raw manifests are installed verbatim, so actions in them are never
+// evaluated and usually mean the file belongs in templates/.
+func validateRawNoActions(data []byte) error {
+	if bytes.Contains(data, []byte("{{")) {
+		return errors.New("raw manifests are not rendered, so template actions in them are installed as they are. Move the file to templates/ if it needs to be rendered")
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/engine"
+)
+
+// renderedObject is a single Kubernetes object produced by a template of the
+// linted chart.
+type renderedObject struct {
+	// Path is the template the object was rendered from, relative to the chart.
+	Path   string
+	Object map[string]interface{}
+}
+
+func (o renderedObject) kind() string {
+	kind, _ := o.Object["kind"].(string)
+	return kind
+}
+
+func (o renderedObject) name() string {
+	return nestedString(o.Object, "metadata", "name")
+}
+
+// renderChart loads and renders the chart in the linter's directory.
+//
+// Errors are returned rather than reported, because the Templates rule is
+// responsible for reporting load and render failures. Rules built on the
+// rendered output should silently skip a chart that does not render.
+func renderChart(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion) (*chart.Chart, map[string]string, error) {
+	ch, err := linter.LoadChart()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := common.ReleaseOptions{
+		Name:      "test-release",
+		Namespace: namespace,
+	}
+
+	caps := common.DefaultCapabilities.Copy()
+	if kubeVersion != nil {
+		caps.KubeVersion = *kubeVersion
+	}
+
+	if err := chartutil.ProcessDependencies(ch, values); err != nil {
+		return nil, nil, err
+	}
+
+	cvals, err := util.CoalesceValues(ch, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(ch, cvals, options, caps, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var e engine.Engine
+	e.LintMode = true
+	e.TemplateCache = linter.TemplateCache()
+	e.Context = linter.RenderContext
+	rendered, err := e.Render(ch, valuesToRender)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, rendered, nil
+}
+
+// renderedObjects renders the chart in the linter's directory and decodes
+// every object produced by the YAML templates of the top-level chart.
+func renderedObjects(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion) ([]renderedObject, error) {
+	ch, rendered, err := renderChart(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []renderedObject
+	for _, template := range ch.Templates {
+		objects = append(objects, decodeObjects(template.Name, rendered[path.Join(ch.Name(), template.Name)])...)
+	}
+	return objects, nil
+}
+
+// allRenderedObjects is renderedObjects, including the objects produced by
+// the templates of the dependencies of the chart. Their path is relative to
+// the top-level chart, as "charts/dep/templates/deployment.yaml".
+func allRenderedObjects(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion) ([]renderedObject, error) {
+	ch, rendered, err := renderChart(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var objects []renderedObject
+	for _, name := range names {
+		objects = append(objects, decodeObjects(strings.TrimPrefix(name, ch.Name()+"/"), rendered[name])...)
+	}
+	return objects, nil
+}
+
+// decodeObjects decodes the objects of a rendered YAML template.
+func decodeObjects(name, content string) []renderedObject {
+	if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+		return nil
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+	var objects []renderedObject
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(content), 4096)
+	for {
+		var obj map[string]interface{}
+		err := decoder.Decode(&obj)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Invalid YAML is reported by the Templates rule.
+			break
+		}
+		if obj == nil {
+			continue
+		}
+		objects = append(objects, renderedObject{Path: name, Object: obj})
+	}
+	return objects
+}
+
+// nestedString returns the string found by following fields in obj, or an
+// empty string if the path does not exist or is not a string.
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	s, _ := nestedField(obj, fields...).(string)
+	return s
+}
+
+// nestedMap returns the map found by following fields in obj, or nil.
+func nestedMap(obj map[string]interface{}, fields ...string) map[string]interface{} {
+	m, _ := nestedField(obj, fields...).(map[string]interface{})
+	return m
+}
+
+func nestedField(obj map[string]interface{}, fields ...string) interface{} {
+	var cur interface{} = obj
+	for _, f := range fields {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[f]
+	}
+	return cur
+}
@@ -0,0 +1,42 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// Prerequisites lints the prerequisites in the requires section of
+// Chart.yaml against a description of the target cluster, such as one read
+// from a capabilities file.
+//
+// This rule is opt-in: it is only run by RunAll when a cluster is configured.
+func Prerequisites(linter *support.Linter, cluster chartutil.Cluster) {
+	chartFileName := "Chart.yaml"
+	chartFile, err := chartutil.LoadChartfile(filepath.Join(linter.ChartDir, chartFileName))
+	if err != nil || chartFile.Requires.Validate() != nil {
+		// Reported by the Chartfile rule.
+		return
+	}
+
+	for _, err := range chartutil.CheckPrerequisites(chartFile.Name, chartFile.Requires, cluster) {
+		linter.RunLinterRule(support.ErrorSev, chartFileName, err)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestPrerequisites(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "requires",
+			Version:    "0.1.0",
+			Requires: &chart.Prerequisites{
+				APIVersions:    []string{"apps/v1", "monitoring.coreos.com/v1"},
+				MinNodes:       3,
+				StorageClasses: []string{"fast"},
+			},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := 3
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Prerequisites(&linter, chartutil.Cluster{
+		APIVersions: common.VersionSet{"v1", "apps/v1"},
+		Nodes:       &nodes,
+	})
+
+	want := []string{
+		`chart "requires" requires API version "monitoring.coreos.com/v1", which the cluster does not serve; install the CRDs or operator providing it first`,
+	}
+	if len(linter.Messages) != len(want) {
+		for i, msg := range linter.Messages {
+			t.Logf("Message %d: %s", i, msg)
+		}
+		t.Fatalf("Expected %d lint errors, got %d", len(want), len(linter.Messages))
+	}
+	for i, msg := range linter.Messages {
+		if msg.Severity != support.ErrorSev || msg.Err.Error() != want[i] {
+			t.Errorf("Unexpected message %d: %s", i, msg)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// immutableSelectorKinds are the kinds of workloads whose label selector
+// cannot be changed once they are created.
+var immutableSelectorKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+}
+
+// mutableLabelKey matches label keys whose values usually change from one
+// release of a chart to the next.
+var mutableLabelKey = regexp.MustCompile(`(?i)(^|[/._-])(version|chart|revision|checksum|hash|timestamp|date|time)$`)
+
+// mutableLabelValue matches label values that look like timestamps, dates or
+// checksums.
+var mutableLabelValue = regexp.MustCompile(`^(\d{10,}|\d{4}-?\d{2}-?\d{2}([T_.-]?\d{2}[:.-]?\d{2}([:.-]?\d{2})?Z?)?|(sha\d+[-.:])?[0-9a-fA-F]{32,})$`)
+
+// Selectors lints the label selectors of the rendered resources of the chart.
+//
+// It warns about Deployments, StatefulSets and DaemonSets selecting pods by
+// labels whose values change between releases, such as a version, a
+// timestamp or a checksum. As selectors are immutable, upgrading a release
+// changing them fails.
+//
+// It also warns about Services selecting none of the pods of the workloads
+// rendered by the chart and its dependencies, which would leave them without
+// endpoints.
+func Selectors(linter *support.Linter, values map[string]interface{}, namespace string, kubeVersion *common.KubeVersion) {
+	objects, err := allRenderedObjects(linter, values, namespace, kubeVersion)
+	if err != nil {
+		return
+	}
+	var versions []string
+	if ch, err := linter.LoadChart(); err == nil && ch.Metadata != nil {
+		versions = []string{ch.Metadata.Version, ch.Metadata.AppVersion}
+	}
+
+	var pods []map[string]interface{}
+	for _, obj := range objects {
+		if labels, ok := podTemplateLabels(obj); ok {
+			pods = append(pods, labels)
+		}
+	}
+
+	for _, obj := range objects {
+		// The dependencies are linted on their own
+		if strings.HasPrefix(obj.Path, "charts/") {
+			continue
+		}
+		if immutableSelectorKinds[obj.kind()] {
+			linter.RunLinterRule(support.WarningSev, obj.Path, validateSelectorLabels(obj, versions))
+		}
+		if obj.kind() == "Service" && len(pods) > 0 {
+			linter.RunLinterRule(support.WarningSev, obj.Path, validateServiceSelector(obj, pods))
+		}
+	}
+}
+
+// podTemplateLabels returns the labels of the pods created by obj, and
+// whether obj creates pods.
+func podTemplateLabels(obj renderedObject) (map[string]interface{}, bool) {
+	var template map[string]interface{}
+	switch obj.kind() {
+	case "Pod":
+		template = obj.Object
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		template = nestedMap(obj.Object, "spec", "template")
+	case "CronJob":
+		template = nestedMap(obj.Object, "spec", "jobTemplate", "spec", "template")
+	}
+	if template == nil {
+		return nil, false
+	}
+	return nestedMap(template, "metadata", "labels"), true
+}
+
+// validateServiceSelector checks that the selector of the Service obj
+// matches the labels of at least one of pods.
+func validateServiceSelector(obj renderedObject, pods []map[string]interface{}) error {
+	selector := nestedMap(obj.Object, "spec", "selector")
+	if len(selector) == 0 || nestedString(obj.Object, "spec", "type") == "ExternalName" {
+		return nil
+	}
+	for _, labels := range pods {
+		if matchesSelector(selector, labels) {
+			return nil
+		}
+	}
+
+	keys := make([]string, 0, len(selector))
+	for key, value := range selector {
+		keys = append(keys, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("%s %q selects no pods rendered by the chart (%s): it will have no endpoints", obj.kind(), obj.name(), strings.Join(keys, ", "))
+}
+
+func matchesSelector(selector, labels map[string]interface{}) bool {
+	for key, value := range selector {
+		label, ok := labels[key]
+		if !ok || fmt.Sprint(label) != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateSelectorLabels checks that the selector of obj has no mutable
+// labels. versions are the versions of the chart, which are mutable values.
+func validateSelectorLabels(obj renderedObject, versions []string) error {
+	selector := nestedMap(obj.Object, "spec", "selector")
+	if selector == nil {
+		return nil
+	}
+
+	mutable := make(map[string]bool)
+	for key, value := range nestedMap(selector, "matchLabels") {
+		if isMutableLabel(key, []interface{}{value}, versions) {
+			mutable[key] = true
+		}
+	}
+	expressions, _ := selector["matchExpressions"].([]interface{})
+	for _, e := range expressions {
+		e, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		key, _ := e["key"].(string)
+		values, _ := e["values"].([]interface{})
+		if isMutableLabel(key, values, versions) {
+			mutable[key] = true
+		}
+	}
+	if len(mutable) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(mutable))
+	for key := range mutable {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("%s %q selects pods by labels that change between releases (%s): upgrades changing them fail, as the selector is immutable", obj.kind(), obj.name(), strings.Join(keys, ", "))
+}
+
+// isMutableLabel returns true if the label key, or one of its values, is
+// likely to change between releases.
+func isMutableLabel(key string, values []interface{}, versions []string) bool {
+	if mutableLabelKey.MatchString(key) {
+		return true
+	}
+	for _, v := range values {
+		value := fmt.Sprint(v)
+		if value == "" {
+			continue
+		}
+		if mutableLabelValue.MatchString(value) {
+			return true
+		}
+		for _, version := range versions {
+			if version != "" && (value == version || value == "v"+version || strings.HasSuffix(value, "-"+version)) {
+				return true
+			}
+		}
+	}
+	return false
+}
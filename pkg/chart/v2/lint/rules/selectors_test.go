@@ -0,0 +1,199 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+const selectorWorkloads = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: stable
+spec:
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{ .Chart.Name }}
+      app.kubernetes.io/instance: {{ .Release.Name }}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: versioned
+spec:
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{ .Chart.Name }}
+      app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+      release: {{ .Chart.Name }}-{{ .Chart.Version }}
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: stamped
+spec:
+  selector:
+    matchLabels:
+      deployed: "20240131T120000Z"
+    matchExpressions:
+    - key: config
+      operator: In
+      values: [{{ "config" | sha256sum | quote }}]
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: ignored
+spec:
+  selector:
+    version: {{ .Chart.Version | quote }}
+`
+
+func TestSelectors(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "selectors",
+			Version:    "0.1.0",
+			AppVersion: "2.3",
+		},
+		Templates: []*common.File{
+			{Name: "templates/workloads.yaml", Data: []byte(selectorWorkloads)},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Selectors(&linter, values, namespace, nil)
+
+	var got []string
+	for _, msg := range linter.Messages {
+		assert.Equal(t, support.WarningSev, msg.Severity)
+		got = append(got, msg.Path+": "+msg.Err.Error())
+	}
+	assert.Equal(t, []string{
+		`templates/workloads.yaml: Deployment "versioned" selects pods by labels that change between releases (app.kubernetes.io/version, release): upgrades changing them fail, as the selector is immutable`,
+		`templates/workloads.yaml: StatefulSet "stamped" selects pods by labels that change between releases (config, deployed): upgrades changing them fail, as the selector is immutable`,
+	}, got)
+}
+
+const selectorServices = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: typo
+spec:
+  selector:
+    app: wbe
+    tier: frontend
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+spec:
+  selector:
+    app: db
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: external
+spec:
+  type: ExternalName
+  externalName: example.com
+  selector:
+    app: other
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+        tier: frontend
+`
+
+const selectorSubchartStatefulSet = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  selector:
+    matchLabels:
+      app: db
+  template:
+    metadata:
+      labels:
+        app: db
+`
+
+func TestServiceSelectors(t *testing.T) {
+	mychart := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "services", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/services.yaml", Data: []byte(selectorServices)},
+		},
+	}
+	mychart.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: "v2", Name: "db", Version: "0.1.0"},
+		Templates: []*common.File{
+			{Name: "templates/statefulset.yaml", Data: []byte(selectorSubchartStatefulSet)},
+			{Name: "templates/service.yaml", Data: []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: sub\nspec:\n  selector:\n    app: nothing\n")},
+		},
+	})
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Selectors(&linter, values, namespace, nil)
+
+	var got []string
+	for _, msg := range linter.Messages {
+		assert.Equal(t, support.WarningSev, msg.Severity)
+		got = append(got, msg.Path+": "+msg.Err.Error())
+	}
+	assert.Equal(t, []string{
+		`templates/services.yaml: Service "typo" selects no pods rendered by the chart (app=wbe, tier=frontend): it will have no endpoints`,
+	}, got)
+}
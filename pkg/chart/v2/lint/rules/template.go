@@ -36,7 +36,6 @@ import (
 	"helm.sh/helm/v4/pkg/chart/common"
 	"helm.sh/helm/v4/pkg/chart/common/util"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/engine"
 )
@@ -68,7 +67,7 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 	}
 
 	// Load chart and parse templates
-	chart, err := loader.Load(linter.ChartDir)
+	chart, err := linter.LoadChart()
 
 	chartLoaded := linter.RunLinterRule(support.ErrorSev, fpath, err)
 
@@ -76,6 +75,26 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 		return
 	}
 
+	// Check the raw templates, since whitespace problems often only break the
+	// rendered YAML with some values.
+	for _, template := range chart.Templates {
+		switch filepath.Ext(template.Name) {
+		case ".yaml", ".yml", ".tpl":
+			linter.RunLinterRule(support.WarningSev, template.Name, validateIndentTabs(template.Data))
+			linter.RunLinterRule(support.WarningSev, template.Name, validateLineEndings(template.Data))
+		}
+	}
+
+	// Library charts only provide named templates to other charts. Their
+	// other templates are never rendered, so there are no manifests to check.
+	isLibrary := chart.Metadata != nil && chart.Metadata.Type == "library"
+	if isLibrary {
+		for _, template := range chart.Templates {
+			linter.RunLinterRule(support.WarningSev, template.Name, validateLibraryTemplateName(template.Name))
+			linter.RunLinterRule(support.WarningSev, template.Name, validateDefineNames(chart.Name(), template.Name, template.Data))
+		}
+	}
+
 	options := common.ReleaseOptions{
 		Name:      "test-release",
 		Namespace: namespace,
@@ -97,13 +116,22 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 		return
 	}
 
-	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chart, cvals, options, caps, skipSchemaValidation)
+	valuesToRender, err := util.ToRenderValuesWithSchemaValidation(chart, cvals, options, caps, true)
+	if err == nil && !skipSchemaValidation {
+		// Validate here rather than in ToRenderValuesWithSchemaValidation so
+		// that compiled schemas are reused across runs.
+		if verr := linter.SchemaCache().ValidateAgainstSchema(chart, valuesToRender["Values"].(common.Values)); verr != nil {
+			err = schemaError{fmt.Errorf("values don't meet the specifications of the schema(s) in the following chart(s):\n%w", verr)}
+		}
+	}
 	if err != nil {
 		linter.RunLinterRule(support.ErrorSev, fpath, err)
 		return
 	}
 	var e engine.Engine
 	e.LintMode = true
+	e.TemplateCache = linter.TemplateCache()
+	e.Context = linter.RenderContext
 	renderedContentMap, err := e.Render(chart, valuesToRender)
 
 	renderOk := linter.RunLinterRule(support.ErrorSev, fpath, err)
@@ -126,7 +154,7 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 		linter.RunLinterRule(support.ErrorSev, fpath, validateAllowedExtension(fileName))
 
 		// We only apply the following lint rules to yaml files
-		if filepath.Ext(fileName) != ".yaml" || filepath.Ext(fileName) == ".yml" {
+		if isLibrary || filepath.Ext(fileName) != ".yaml" || filepath.Ext(fileName) == ".yml" {
 			continue
 		}
 
@@ -138,37 +166,61 @@ func TemplatesWithSkipSchemaValidation(linter *support.Linter, values map[string
 		// linter.RunLinterRule(support.WarningSev, fpath, validateQuotes(string(preExecutedTemplate)))
 
 		renderedContent := renderedContentMap[path.Join(chart.Name(), fileName)]
-		if strings.TrimSpace(renderedContent) != "" {
-			linter.RunLinterRule(support.WarningSev, fpath, validateTopIndentLevel(renderedContent))
+		if !lintManifest(linter, fpath, renderedContent, kubeVersion) {
+			return
+		}
+	}
 
-			decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(renderedContent), 4096)
+	// The raw manifests are not rendered, but they are installed as they are
+	// and must be valid too.
+	for _, f := range chart.Files {
+		if !strings.HasPrefix(f.Name, engine.RawDir) {
+			continue
+		}
+		linter.RunLinterRule(support.WarningSev, f.Name, validateRawManifestName(f.Name))
+		linter.RunLinterRule(support.WarningSev, f.Name, validateRawNoActions(f.Data))
+		if content, ok := renderedContentMap[path.Join(chart.Name(), f.Name)]; ok {
+			if !lintManifest(linter, f.Name, content, kubeVersion) {
+				return
+			}
+		}
+	}
+}
 
-			// Lint all resources if the file contains multiple documents separated by ---
-			for {
-				// Even though k8sYamlStruct only defines a few fields, an error in any other
-				// key will be raised as well
-				var yamlStruct *k8sYamlStruct
+// lintManifest checks the resources of a rendered manifest. It returns false
+// if the manifest is not valid YAML.
+func lintManifest(linter *support.Linter, fpath, renderedContent string, kubeVersion *common.KubeVersion) bool {
+	if strings.TrimSpace(renderedContent) == "" {
+		return true
+	}
+	linter.RunLinterRule(support.WarningSev, fpath, validateTopIndentLevel(renderedContent))
 
-				err := decoder.Decode(&yamlStruct)
-				if err == io.EOF {
-					break
-				}
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(renderedContent), 4096)
 
-				//  If YAML linting fails here, it will always fail in the next block as well, so we should return here.
-				// fix https://github.com/helm/helm/issues/11391
-				if !linter.RunLinterRule(support.ErrorSev, fpath, validateYamlContent(err)) {
-					return
-				}
-				if yamlStruct != nil {
-					// NOTE: set to warnings to allow users to support out-of-date kubernetes
-					// Refs https://github.com/helm/helm/issues/8596
-					linter.RunLinterRule(support.WarningSev, fpath, validateMetadataName(yamlStruct))
-					linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct, kubeVersion))
-
-					linter.RunLinterRule(support.ErrorSev, fpath, validateMatchSelector(yamlStruct, renderedContent))
-					linter.RunLinterRule(support.ErrorSev, fpath, validateListAnnotations(yamlStruct, renderedContent))
-				}
-			}
+	// Lint all resources if the file contains multiple documents separated by ---
+	for {
+		// Even though k8sYamlStruct only defines a few fields, an error in any other
+		// key will be raised as well
+		var yamlStruct *k8sYamlStruct
+
+		err := decoder.Decode(&yamlStruct)
+		if err == io.EOF {
+			return true
+		}
+
+		//  If YAML linting fails here, it will always fail in the next block as well, so we should return here.
+		// fix https://github.com/helm/helm/issues/11391
+		if !linter.RunLinterRule(support.ErrorSev, fpath, validateYamlContent(err)) {
+			return false
+		}
+		if yamlStruct != nil {
+			// NOTE: set to warnings to allow users to support out-of-date kubernetes
+			// Refs https://github.com/helm/helm/issues/8596
+			linter.RunLinterRule(support.WarningSev, fpath, validateMetadataName(yamlStruct))
+			linter.RunLinterRule(support.WarningSev, fpath, validateNoDeprecations(yamlStruct, kubeVersion))
+
+			linter.RunLinterRule(support.ErrorSev, fpath, validateMatchSelector(yamlStruct, renderedContent))
+			linter.RunLinterRule(support.ErrorSev, fpath, validateListAnnotations(yamlStruct, renderedContent))
 		}
 	}
 }
@@ -199,6 +251,39 @@ func validateTopIndentLevel(content string) error {
 	return scanner.Err()
 }
 
+// validateIndentTabs checks that no line of a raw template is indented with
+// tabs, which YAML does not allow. Lines starting with an action that trims
+// the whitespace before it, such as "{{- if", are ignored.
+func validateIndentTabs(data []byte) error {
+	var first, count int
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, "{{-") || !strings.Contains(line[:len(line)-len(trimmed)], "\t") {
+			continue
+		}
+		if count == 0 {
+			first = i + 1
+		}
+		count++
+	}
+	if count > 0 {
+		return fmt.Errorf("%d line(s) are indented with tabs, starting at line %d. YAML does not allow tabs for indentation, so the rendered manifest may fail to parse", count, first)
+	}
+	return nil
+}
+
+// validateLineEndings checks that a raw template does not use Windows line
+// endings. Carriage returns end up inside rendered values when templates trim
+// or indent lines, which causes YAML errors that depend on the values.
+func validateLineEndings(data []byte) error {
+	count := bytes.Count(data, []byte("\r\n"))
+	if count == 0 {
+		return nil
+	}
+	first := bytes.Count(data[:bytes.Index(data, []byte("\r\n"))], []byte("\n")) + 1
+	return fmt.Errorf("%d line(s) end with Windows line endings (CRLF), starting at line %d. Use Unix line endings (LF) instead", count, first)
+}
+
 // Validation functions
 func templatesDirExists(templatesPath string) error {
 	_, err := os.Stat(templatesPath)
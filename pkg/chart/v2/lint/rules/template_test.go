@@ -369,6 +369,128 @@ func TestValidateTopIndentLevel(t *testing.T) {
 
 }
 
+func TestValidateIndentTabs(t *testing.T) {
+	for doc, shouldFail := range map[string]bool{
+		// Should not fail
+		"a:\n  b: c\n":                        false,
+		"a: \"tab\there\"\n":                  false,
+		"a:\n\t{{- if .Values.b }}\n  b: c\n": false,
+		"\t{{- /* comment */ -}}\n":           false,
+		// Should fail
+		"a:\n\tb: c\n":   true,
+		"a:\n  \tb: c\n": true,
+	} {
+		if err := validateIndentTabs([]byte(doc)); (err == nil) == shouldFail {
+			t.Errorf("Expected %t for %q", shouldFail, doc)
+		}
+	}
+
+	err := validateIndentTabs([]byte("a:\n  b: c\n\td: e\n\tf: g\n"))
+	if err == nil || !strings.Contains(err.Error(), "2 line(s) are indented with tabs, starting at line 3") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestValidateLineEndings(t *testing.T) {
+	if err := validateLineEndings([]byte("a: b\nc: d\n")); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	err := validateLineEndings([]byte("a: b\nc: d\r\ne: f\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "2 line(s) end with Windows line endings (CRLF), starting at line 2") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestTemplateWhitespace(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "whitespace",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*common.File{
+			{
+				Name: "templates/_helpers.tpl",
+				Data: []byte("{{- define \"labels\" }}\r\napp: x\r\n{{- end }}\r\n"),
+			},
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: whitespace\ndata:\n{{- if .Values.extra }}\n\textra: \"yes\"\n{{- end }}\n"),
+			},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tab only breaks rendering when extra is set, but is reported
+	// regardless of the values.
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	if len(linter.Messages) != 2 {
+		t.Fatalf("Expected 2 lint messages, got %v", linter.Messages)
+	}
+	for i, path := range []string{"templates/_helpers.tpl", "templates/configmap.yaml"} {
+		msg := linter.Messages[i]
+		if msg.Severity != support.WarningSev || msg.Path != path {
+			t.Errorf("Unexpected message %s", msg)
+		}
+	}
+}
+
+func TestTemplateRawFiles(t *testing.T) {
+	mychart := chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "rawfiles",
+			Version:    "0.1.0",
+			Icon:       "satisfy-the-linting-gods.gif",
+		},
+		Templates: []*common.File{
+			{Name: "templates/configmap.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: templated\n")},
+		},
+		Files: []*common.File{
+			{Name: "raw/ok.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: raw\n")},
+			{Name: "raw/notes.txt", Data: []byte("not a manifest")},
+			{Name: "raw/action.yaml", Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: raw-action\ndata:\n  key: '{{ .Values.key }}'\n")},
+			{Name: "raw/zz-invalid.yaml", Data: []byte("apiVersion: v1\nkind: [\n")},
+		},
+	}
+	tmpdir := t.TempDir()
+	if err := chartutil.SaveDir(&mychart, tmpdir); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, values, namespace, strict)
+	expect := []struct {
+		severity int
+		path     string
+	}{
+		{support.WarningSev, "raw/action.yaml"},
+		{support.WarningSev, "raw/notes.txt"},
+		{support.ErrorSev, "raw/zz-invalid.yaml"},
+	}
+	if len(linter.Messages) != len(expect) {
+		t.Fatalf("Expected %d lint messages, got %v", len(expect), linter.Messages)
+	}
+	for i, e := range expect {
+		if msg := linter.Messages[i]; msg.Severity != e.severity || msg.Path != e.path {
+			t.Errorf("Unexpected message %s, expected one for %s", msg, e.path)
+		}
+	}
+
+	// Raw files excluded by the values are not installed, so they are not
+	// checked either.
+	linter = support.Linter{ChartDir: filepath.Join(tmpdir, mychart.Name())}
+	Templates(&linter, map[string]interface{}{"rawFiles": []interface{}{"ok.yaml"}}, namespace, strict)
+	if len(linter.Messages) != 2 || linter.Messages[1].Path != "raw/notes.txt" {
+		t.Errorf("Expected only the warnings, got %v", linter.Messages)
+	}
+}
+
 // TestEmptyWithCommentsManifests checks the lint is not failing against empty manifests that contains only comments
 // See https://github.com/helm/helm/issues/8621
 func TestEmptyWithCommentsManifests(t *testing.T) {
@@ -17,6 +17,7 @@ limitations under the License.
 package rules
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -35,13 +36,22 @@ import (
 func ValuesWithOverrides(linter *support.Linter, valueOverrides map[string]interface{}) {
 	file := "values.yaml"
 	vf := filepath.Join(linter.ChartDir, file)
+	if linter.Ignored(file) {
+		// The values of the chart are those it is packaged with.
+		linter.RunLinterRule(support.InfoSev, file, errors.New("file is ignored by .helmignore"))
+		return
+	}
 	fileExists := linter.RunLinterRule(support.InfoSev, file, validateValuesFileExistence(vf))
 
 	if !fileExists {
 		return
 	}
 
-	linter.RunLinterRule(support.ErrorSev, file, validateValuesFile(vf, valueOverrides))
+	schemaPath := schemaPathFor(vf)
+	if linter.Ignored(filepath.Base(schemaPath)) {
+		schemaPath = ""
+	}
+	linter.RunLinterRule(support.ErrorSev, file, validateValues(vf, schemaPath, valueOverrides, linter.SchemaCache()))
 }
 
 func validateValuesFileExistence(valuesPath string) error {
@@ -52,7 +62,19 @@ func validateValuesFileExistence(valuesPath string) error {
 	return nil
 }
 
-func validateValuesFile(valuesPath string, overrides map[string]interface{}) error {
+func validateValuesFile(valuesPath string, overrides map[string]interface{}, schemas *util.SchemaCache) error {
+	return validateValues(valuesPath, schemaPathFor(valuesPath), overrides, schemas)
+}
+
+// schemaPathFor returns the path of the schema of the values file.
+func schemaPathFor(valuesPath string) string {
+	ext := filepath.Ext(valuesPath)
+	return valuesPath[:len(valuesPath)-len(ext)] + ".schema.json"
+}
+
+// validateValues validates the values file against the schema at
+// schemaPath, if it is not empty.
+func validateValues(valuesPath, schemaPath string, overrides map[string]interface{}, schemas *util.SchemaCache) error {
 	values, err := common.ReadValuesFile(valuesPath)
 	if err != nil {
 		return fmt.Errorf("unable to parse YAML: %w", err)
@@ -66,8 +88,9 @@ func validateValuesFile(valuesPath string, overrides map[string]interface{}) err
 	coalescedValues := util.CoalesceTables(make(map[string]interface{}, len(overrides)), overrides)
 	coalescedValues = util.CoalesceTables(coalescedValues, values)
 
-	ext := filepath.Ext(valuesPath)
-	schemaPath := valuesPath[:len(valuesPath)-len(ext)] + ".schema.json"
+	if schemaPath == "" {
+		return nil
+	}
 	schema, err := os.ReadFile(schemaPath)
 	if len(schema) == 0 {
 		return nil
@@ -75,5 +98,5 @@ func validateValuesFile(valuesPath string, overrides map[string]interface{}) err
 	if err != nil {
 		return err
 	}
-	return util.ValidateAgainstSingleSchema(coalescedValues, schema)
+	return schemas.ValidateAgainstSingleSchema(coalescedValues, schema)
 }
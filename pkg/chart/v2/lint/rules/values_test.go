@@ -19,11 +19,13 @@ package rules
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"helm.sh/helm/v4/internal/test/ensure"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
 )
 
 var nonExistingValuesFilePath = filepath.Join("/fake/dir", "values.yaml")
@@ -67,7 +69,7 @@ func TestValidateValuesFileWellFormed(t *testing.T) {
 	`
 	tmpdir := ensure.TempFile(t, "values.yaml", []byte(badYaml))
 	valfile := filepath.Join(tmpdir, "values.yaml")
-	if err := validateValuesFile(valfile, map[string]interface{}{}); err == nil {
+	if err := validateValuesFile(valfile, map[string]interface{}{}, nil); err == nil {
 		t.Fatal("expected values file to fail parsing")
 	}
 }
@@ -78,7 +80,7 @@ func TestValidateValuesFileSchema(t *testing.T) {
 	createTestingSchema(t, tmpdir)
 
 	valfile := filepath.Join(tmpdir, "values.yaml")
-	if err := validateValuesFile(valfile, map[string]interface{}{}); err != nil {
+	if err := validateValuesFile(valfile, map[string]interface{}{}, nil); err != nil {
 		t.Fatalf("Failed validation with %s", err)
 	}
 }
@@ -91,12 +93,12 @@ func TestValidateValuesFileSchemaFailure(t *testing.T) {
 
 	valfile := filepath.Join(tmpdir, "values.yaml")
 
-	err := validateValuesFile(valfile, map[string]interface{}{})
+	err := validateValuesFile(valfile, map[string]interface{}{}, nil)
 	if err == nil {
 		t.Fatal("expected values file to fail parsing")
 	}
 
-	assert.Contains(t, err.Error(), "- at '/username': got number, want string")
+	assert.Contains(t, err.Error(), "- at '/username' [type]: got number, want string")
 }
 
 func TestValidateValuesFileSchemaOverrides(t *testing.T) {
@@ -108,7 +110,7 @@ func TestValidateValuesFileSchemaOverrides(t *testing.T) {
 	createTestingSchema(t, tmpdir)
 
 	valfile := filepath.Join(tmpdir, "values.yaml")
-	if err := validateValuesFile(valfile, overrides); err != nil {
+	if err := validateValuesFile(valfile, overrides, nil); err != nil {
 		t.Fatalf("Failed validation with %s", err)
 	}
 }
@@ -129,7 +131,7 @@ func TestValidateValuesFile(t *testing.T) {
 			name:         "value not overridden",
 			yaml:         "username: admin\npassword:",
 			overrides:    map[string]interface{}{"username": "anotherUser"},
-			errorMessage: "- at '/password': got null, want string",
+			errorMessage: "- at '/password' [type]: got null, want string",
 		},
 		{
 			name:      "value overridden",
@@ -145,7 +147,7 @@ func TestValidateValuesFile(t *testing.T) {
 
 			valfile := filepath.Join(tmpdir, "values.yaml")
 
-			err := validateValuesFile(valfile, tt.overrides)
+			err := validateValuesFile(valfile, tt.overrides, nil)
 
 			switch {
 			case err != nil && tt.errorMessage == "":
@@ -159,6 +161,30 @@ func TestValidateValuesFile(t *testing.T) {
 	}
 }
 
+func TestValuesWithOverridesHelmignore(t *testing.T) {
+	// 1234 is an int, not a string, but the schema is not packaged.
+	tmpdir := ensure.TempFile(t, "values.yaml", []byte("username: 1234\npassword: swordfish"))
+	createTestingSchema(t, tmpdir)
+	if err := os.WriteFile(filepath.Join(tmpdir, ".helmignore"), []byte("*.json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	linter := &support.Linter{ChartDir: tmpdir}
+	ValuesWithOverrides(linter, nil)
+	if len(linter.Messages) != 0 {
+		t.Errorf("Expected the ignored schema not to be used, got %v", linter.Messages)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpdir, ".helmignore"), []byte("values.yaml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	linter = &support.Linter{ChartDir: tmpdir}
+	ValuesWithOverrides(linter, nil)
+	if len(linter.Messages) != 1 || linter.Messages[0].Severity != support.InfoSev || !strings.Contains(linter.Messages[0].Err.Error(), "ignored by .helmignore") {
+		t.Errorf("Expected an info message about the ignored values file, got %v", linter.Messages)
+	}
+}
+
 func createTestingSchema(t *testing.T, dir string) string {
 	t.Helper()
 	schemafile := filepath.Join(dir, "values.schema.json")
@@ -0,0 +1,314 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"helm.sh/helm/v4/internal/jsonrpc"
+	"helm.sh/helm/v4/internal/urlutil"
+)
+
+// JSON-RPC methods handled by a Server.
+const (
+	// MethodOpen starts tracking a chart and lints it. Its params are
+	// OpenParams.
+	MethodOpen = "chart/open"
+	// MethodDidChange tells the server that a file or directory changed. All
+	// tracked charts containing it are linted again. Its params are
+	// DidChangeParams. It is usually sent as a notification.
+	MethodDidChange = "chart/didChange"
+	// MethodClose stops tracking a chart and clears its diagnostics. Its
+	// params are CloseParams.
+	MethodClose = "chart/close"
+	// MethodShutdown stops the server after replying.
+	MethodShutdown = "shutdown"
+
+	// MethodPublishDiagnostics is the notification the server sends with the
+	// diagnostics of a file, as PublishDiagnosticsParams.
+	MethodPublishDiagnostics = "textDocument/publishDiagnostics"
+)
+
+// OpenParams are the params of MethodOpen.
+type OpenParams struct {
+	// Chart is the path or file URI of the chart directory.
+	Chart string `json:"chart"`
+	// Values are the values the chart is linted with.
+	Values map[string]interface{} `json:"values,omitempty"`
+	// Namespace is the namespace the chart is linted for.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// DidChangeParams are the params of MethodDidChange.
+type DidChangeParams struct {
+	// Path is the path or file URI of the file or directory that changed.
+	Path string `json:"path"`
+}
+
+// CloseParams are the params of MethodClose.
+type CloseParams struct {
+	// Chart is the path or file URI of the chart directory.
+	Chart string `json:"chart"`
+}
+
+// Server lints charts for editor integrations over JSON-RPC 2.0.
+//
+// Messages are framed with Content-Length headers, as in the Language Server
+// Protocol. A client opens the charts it wants linted and reports file
+// changes; the server lints the affected charts again, reusing the work
+// cached by its Linter, and publishes the diagnostics of every file in
+// "textDocument/publishDiagnostics" notifications. Files whose problems were
+// fixed get an empty list of diagnostics.
+type Server struct {
+	// Values and Namespace are used for charts opened without values or a
+	// namespace.
+	Values    map[string]interface{}
+	Namespace string
+
+	linter *Linter
+
+	mu     sync.Mutex
+	charts map[string]*serverChart
+	conn   *jsonrpc.Conn
+}
+
+type serverChart struct {
+	values    map[string]interface{}
+	namespace string
+	// published is the set of URIs with diagnostics.
+	published map[string]bool
+}
+
+// NewServer creates a Server that lints with linter, or with a new Linter if
+// linter is nil.
+func NewServer(linter *Linter) *Server {
+	if linter == nil {
+		linter = NewLinter()
+	}
+	return &Server{
+		linter: linter,
+		charts: make(map[string]*serverChart),
+	}
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until r is exhausted, a shutdown request is received or ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	conn := jsonrpc.NewConn(r, w)
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+	return jsonrpc.Serve(ctx, conn, s.handle)
+}
+
+// Attach makes the server publish diagnostics to w without serving requests
+// from a client. It is used to embed the server in another JSON-RPC server,
+// such as a language server, that calls Open, DidChange and Close itself and
+// writes to w from the same goroutine.
+func (s *Server) Attach(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conn = jsonrpc.NewConn(nil, w)
+}
+
+func (s *Server) handle(req *jsonrpc.Message) error {
+	var err error
+	switch req.Method {
+	case "":
+		return s.conn.Reply(req, nil, jsonrpc.Errorf(jsonrpc.CodeInvalidRequest, "method is required"))
+	case MethodOpen:
+		var p OpenParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			err = s.Open(p)
+		}
+	case MethodDidChange:
+		var p DidChangeParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			err = s.DidChange(p.Path)
+		}
+	case MethodClose:
+		var p CloseParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			err = s.Close(p.Chart)
+		}
+	case MethodShutdown:
+		if err := s.conn.Reply(req, nil, nil); err != nil {
+			return err
+		}
+		return jsonrpc.ErrStop
+	default:
+		return s.conn.Reply(req, nil, jsonrpc.Errorf(jsonrpc.CodeMethodNotFound, "method %q not found", req.Method))
+	}
+
+	if err != nil {
+		if req.IsNotification() {
+			slog.Warn("lint server notification failed", "method", req.Method, slog.Any("error", err))
+		}
+	}
+	return s.conn.Reply(req, nil, err)
+}
+
+// Open starts tracking the chart and publishes its diagnostics.
+func (s *Server) Open(p OpenParams) error {
+	if p.Chart == "" {
+		return errors.New("chart is required")
+	}
+	dir, err := filepath.Abs(urlutil.FilePath(p.Chart))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	c, ok := s.charts[dir]
+	if !ok {
+		c = &serverChart{published: make(map[string]bool)}
+		s.charts[dir] = c
+	}
+	c.values, c.namespace = p.Values, p.Namespace
+	if c.values == nil {
+		c.values = s.Values
+	}
+	if c.namespace == "" {
+		c.namespace = s.Namespace
+	}
+	s.mu.Unlock()
+
+	return s.lint(dir)
+}
+
+// DidChange lints every tracked chart that contains path again and publishes
+// the changed diagnostics.
+func (s *Server) DidChange(path string) error {
+	if path == "" {
+		return errors.New("path is required")
+	}
+	changed, err := filepath.Abs(urlutil.FilePath(path))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	var dirs []string
+	for dir := range s.charts {
+		if changed == dir || strings.HasPrefix(changed, dir+string(filepath.Separator)) {
+			dirs = append(dirs, dir)
+		}
+	}
+	s.mu.Unlock()
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if err := s.lint(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops tracking the chart and clears its diagnostics.
+func (s *Server) Close(chart string) error {
+	dir, err := filepath.Abs(urlutil.FilePath(chart))
+	if err != nil {
+		return err
+	}
+
+	// The published URIs are copied under the lock, as a lint in flight may
+	// still replace them.
+	s.mu.Lock()
+	c, ok := s.charts[dir]
+	delete(s.charts, dir)
+	var uris []string
+	if ok {
+		for uri := range c.published {
+			uris = append(uris, uri)
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("chart %s is not open", dir)
+	}
+	s.linter.Invalidate(dir)
+
+	sort.Strings(uris)
+	for _, uri := range uris {
+		if err := s.notify(MethodPublishDiagnostics, PublishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lint lints a tracked chart and publishes its diagnostics, clearing those of
+// files that no longer have any.
+func (s *Server) lint(dir string) error {
+	s.mu.Lock()
+	c, ok := s.charts[dir]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+	values, namespace := c.values, c.namespace
+	s.mu.Unlock()
+
+	result := s.linter.Lint(dir, values, namespace)
+	files := Diagnostics(dir, result.Messages)
+
+	published := make(map[string]bool, len(files))
+	for _, f := range files {
+		published[f.URI] = true
+	}
+	s.mu.Lock()
+	if s.charts[dir] != c {
+		// The chart was closed while it was linted.
+		s.mu.Unlock()
+		return nil
+	}
+	previous := c.published
+	c.published = published
+	s.mu.Unlock()
+
+	for uri := range previous {
+		if !published[uri] {
+			files = append(files, PublishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].URI < files[j].URI })
+	for _, f := range files {
+		if err := s.notify(MethodPublishDiagnostics, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Notify(method, params)
+}
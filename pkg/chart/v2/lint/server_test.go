@@ -0,0 +1,164 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/jsonrpc"
+	"helm.sh/helm/v4/internal/urlutil"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+func TestDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "values.yaml"), []byte("a: b\n"), 0644))
+
+	diags := Diagnostics(dir, []support.Message{
+		support.NewMessage(support.ErrorSev, "templates/", errors.New("parse error at (mychart/templates/a.yaml:3): unexpected EOF")),
+		support.NewMessage(support.ErrorSev, "templates/b.yaml", errors.New("template: mychart/templates/b.yaml:7:12: executing \"mychart/templates/b.yaml\" at <.Values.x.y>: nil pointer")),
+		support.NewMessage(support.WarningSev, "values.yaml", errors.New("unable to parse YAML: error converting YAML to JSON: yaml: line 4: could not find expected ':'")),
+		support.NewMessage(support.InfoSev, "Chart.yaml", errors.New("icon is recommended")),
+	})
+
+	want := []PublishDiagnosticsParams{{
+		URI:         urlutil.FileURI(filepath.Join(dir, "Chart.yaml")),
+		Diagnostics: []Diagnostic{{Severity: SeverityInformation, Source: "helm-lint", Message: "icon is recommended"}},
+	}, {
+		URI: urlutil.FileURI(filepath.Join(dir, "templates", "a.yaml")),
+		Diagnostics: []Diagnostic{{
+			Range:    Range{Start: Position{Line: 2}, End: Position{Line: 2}},
+			Severity: SeverityError,
+			Source:   "helm-lint",
+			Message:  "parse error at (mychart/templates/a.yaml:3): unexpected EOF",
+		}},
+	}, {
+		URI: urlutil.FileURI(filepath.Join(dir, "templates", "b.yaml")),
+		Diagnostics: []Diagnostic{{
+			Range:    Range{Start: Position{Line: 6, Character: 11}, End: Position{Line: 6, Character: 11}},
+			Severity: SeverityError,
+			Source:   "helm-lint",
+			Message:  "template: mychart/templates/b.yaml:7:12: executing \"mychart/templates/b.yaml\" at <.Values.x.y>: nil pointer",
+		}},
+	}, {
+		URI: urlutil.FileURI(filepath.Join(dir, "values.yaml")),
+		Diagnostics: []Diagnostic{{
+			Range:    Range{Start: Position{Line: 3}, End: Position{Line: 3}},
+			Severity: SeverityWarning,
+			Source:   "helm-lint",
+			Message:  "unable to parse YAML: error converting YAML to JSON: yaml: line 4: could not find expected ':'",
+		}},
+	}}
+	assert.Equal(t, want, diags)
+}
+
+// serverClient drives a Server over pipes, like an editor extension does.
+type serverClient struct {
+	t   *testing.T
+	in  *io.PipeWriter
+	out *jsonrpc.Conn
+	id  int
+}
+
+func (c *serverClient) send(method string, params interface{}, notification bool) int {
+	c.t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if !notification {
+		c.id++
+		msg["id"] = c.id
+	}
+	data, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	require.NoError(c.t, err)
+	return c.id
+}
+
+// until reads messages until the response to id and returns the diagnostics
+// published before it by URI, and the response error, if any.
+func (c *serverClient) until(id int) (map[string][]Diagnostic, *jsonrpc.Error) {
+	c.t.Helper()
+	published := make(map[string][]Diagnostic)
+	for {
+		msg, err := c.out.Read()
+		require.NoError(c.t, err)
+		if msg.Method == MethodPublishDiagnostics {
+			var params PublishDiagnosticsParams
+			require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+			published[params.URI] = params.Diagnostics
+			continue
+		}
+		require.False(c.t, msg.IsNotification(), "unexpected message %v", msg)
+		if string(msg.ID) == strconv.Itoa(id) {
+			return published, msg.Error
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir, err := chartutil.Create("served", t.TempDir())
+	require.NoError(t, err)
+	broken := filepath.Join(dir, "templates", "broken.yaml")
+	require.NoError(t, os.WriteFile(broken, []byte("{{ .Values.missing.field }"), 0644))
+	brokenURI := urlutil.FileURI(broken)
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(NewLinter(WithSkipSchemaValidation(true))).Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	client := &serverClient{t: t, in: inW, out: jsonrpc.NewConn(outR, io.Discard)}
+
+	published, rpcErr := client.until(client.send(MethodOpen, OpenParams{Chart: urlutil.FileURI(dir)}, false))
+	require.Nil(t, rpcErr)
+	require.Len(t, published[brokenURI], 1, "got %v", published)
+	assert.Equal(t, SeverityError, published[brokenURI][0].Severity)
+
+	_, rpcErr = client.until(client.send("chart/unknown", struct{}{}, false))
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, jsonrpc.CodeMethodNotFound, rpcErr.Code)
+
+	_, rpcErr = client.until(client.send(MethodOpen, "served", false))
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+
+	require.NoError(t, os.Remove(broken))
+	client.send(MethodDidChange, DidChangeParams{Path: broken}, true)
+	published, rpcErr = client.until(client.send(MethodClose, CloseParams{Chart: dir}, false))
+	require.Nil(t, rpcErr)
+	diags, ok := published[brokenURI]
+	assert.True(t, ok, "expected the diagnostics of the fixed template to be cleared")
+	assert.Empty(t, diags)
+
+	_, rpcErr = client.until(client.send(MethodShutdown, nil, false))
+	require.Nil(t, rpcErr)
+	require.NoError(t, <-done)
+}
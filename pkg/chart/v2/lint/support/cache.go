@@ -0,0 +1,198 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/mitchellh/copystructure"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/engine"
+)
+
+// Cache holds the work that can be shared between lint runs of the same chart:
+// the loaded chart, the compiled values schemas and the parsed templates.
+//
+// A loaded chart is reused until a file in the chart directory is added,
+// removed or modified. A nil *Cache is valid and caches nothing.
+type Cache struct {
+	// Schemas caches compiled values schemas.
+	Schemas *util.SchemaCache
+	// Templates caches parsed templates.
+	Templates *engine.TemplateCache
+
+	mu     sync.Mutex
+	charts map[string]cachedChart
+}
+
+type cachedChart struct {
+	fingerprint [sha256.Size]byte
+	chart       *chart.Chart
+	err         error
+}
+
+// NewCache creates an empty cache.
+func NewCache() *Cache {
+	return &Cache{
+		Schemas:   &util.SchemaCache{},
+		Templates: &engine.TemplateCache{},
+		charts:    make(map[string]cachedChart),
+	}
+}
+
+// LoadChart loads the chart at path, which may be a directory or an archive.
+//
+// Every call returns a separate copy of the chart, so callers may modify it,
+// for example by processing its dependencies.
+func (c *Cache) LoadChart(path string) (*chart.Chart, error) {
+	if c == nil {
+		return loader.Load(path)
+	}
+
+	fingerprint, err := fingerprintPath(path)
+	if err != nil {
+		return loader.Load(path)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.charts[path]
+	if !ok || cached.fingerprint != fingerprint {
+		ch, err := loader.Load(path)
+		cached = cachedChart{fingerprint: fingerprint, chart: ch, err: err}
+		c.charts[path] = cached
+	}
+	if cached.err != nil {
+		return nil, cached.err
+	}
+	return copyChart(cached.chart)
+}
+
+// Invalidate forgets the chart loaded from path, so that the next LoadChart
+// reads it again.
+func (c *Cache) Invalidate(path string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.charts, path)
+}
+
+// LoadChart loads the chart in ChartDir, reusing the cached chart if possible.
+func (l *Linter) LoadChart() (*chart.Chart, error) {
+	return l.Cache.LoadChart(l.ChartDir)
+}
+
+// SchemaCache returns the cache for compiled values schemas, or nil if the
+// linter has no cache.
+func (l *Linter) SchemaCache() *util.SchemaCache {
+	if l.Cache == nil {
+		return nil
+	}
+	return l.Cache.Schemas
+}
+
+// TemplateCache returns the cache for parsed templates, or nil if the linter
+// has no cache.
+func (l *Linter) TemplateCache() *engine.TemplateCache {
+	if l.Cache == nil {
+		return nil
+	}
+	return l.Cache.Templates
+}
+
+// fingerprintPath hashes the names, sizes and modification times of all files
+// under path.
+func fingerprintPath(path string) ([sha256.Size]byte, error) {
+	var fingerprint [sha256.Size]byte
+	h := sha256.New()
+	err := filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(info.Size()))
+		h.Write(buf[:])
+		binary.LittleEndian.PutUint64(buf[:], uint64(info.ModTime().UnixNano()))
+		h.Write(buf[:])
+		if info.Mode()&os.ModeSymlink != 0 {
+			// The loader follows symlinks, so hash what they point to.
+			if target, err := os.Stat(name); err == nil {
+				binary.LittleEndian.PutUint64(buf[:], uint64(target.ModTime().UnixNano()))
+				h.Write(buf[:])
+			}
+		}
+		return nil
+	})
+	copy(fingerprint[:], h.Sum(nil))
+	return fingerprint, err
+}
+
+// copyChart copies the parts of a chart that may be modified after loading.
+// Files and templates are shared, as they are never modified in place.
+func copyChart(c *chart.Chart) (*chart.Chart, error) {
+	out := *c
+	if c.Metadata != nil {
+		md := *c.Metadata
+		if c.Metadata.Dependencies != nil {
+			md.Dependencies = make([]*chart.Dependency, len(c.Metadata.Dependencies))
+			for i, dep := range c.Metadata.Dependencies {
+				if dep != nil {
+					d := *dep
+					md.Dependencies[i] = &d
+				}
+			}
+		}
+		out.Metadata = &md
+	}
+	out.Templates = append([]*common.File(nil), c.Templates...)
+	out.Files = append([]*common.File(nil), c.Files...)
+	if c.Values != nil {
+		vals, err := copystructure.Copy(c.Values)
+		if err != nil {
+			return nil, err
+		}
+		out.Values = vals.(map[string]interface{})
+	}
+
+	deps := make([]*chart.Chart, 0, len(c.Dependencies()))
+	for _, dep := range c.Dependencies() {
+		d, err := copyChart(dep)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, d)
+	}
+	out.SetDependencies(deps...)
+	return &out, nil
+}
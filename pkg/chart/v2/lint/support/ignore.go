@@ -0,0 +1,50 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+// Ignored reports whether the file name, relative to the chart directory with
+// forward slashes, is excluded from the chart by its .helmignore files, as
+// it is when the chart is loaded or packaged. The files of chart archives are
+// never ignored.
+func (l *Linter) Ignored(name string) bool {
+	rules, err := loader.IgnoreRules(l.ChartDir)
+	if err != nil {
+		// Loading the chart reports invalid .helmignore files.
+		return false
+	}
+
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		n := strings.Join(parts[:i+1], "/")
+		fi, err := os.Stat(filepath.Join(l.ChartDir, filepath.FromSlash(n)))
+		if err != nil {
+			return false
+		}
+		if rules.Ignore(n, fi) {
+			return true
+		}
+	}
+	return false
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package support
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
 
 // Severity indicates the severity of a Message.
 const (
@@ -35,13 +39,37 @@ var sev = []string{"UNKNOWN", "INFO", "WARNING", "ERROR"}
 
 // Linter encapsulates a linting run of a particular chart.
 type Linter struct {
-	Messages []Message
+	Messages []Message `json:"messages"`
 	// The highest severity of all the failing lint rules
-	HighestSeverity int
-	ChartDir        string
+	HighestSeverity int    `json:"highest_severity"`
+	ChartDir        string `json:"chart_dir"`
+	// Rules has the statistics of every rule run with RunRule, in order.
+	Rules []RuleStats `json:"rules,omitempty"`
+	// Cache, if set, is shared with other runs against the same chart
+	Cache *Cache `json:"-"`
+	// RenderContext holds the additional top-level objects passed to the
+	// templates rendered by the rules, as with the Context of engine.Engine
+	RenderContext map[string]interface{} `json:"-"`
+
+	// messageRules has the name of the rule that reported each of Messages.
+	messageRules []string
+}
+
+// MessageRule returns the name of the rule that reported l.Messages[i], or an
+// empty string if it was not reported by a rule run with RunRule.
+func (l *Linter) MessageRule(i int) string {
+	if i < 0 || i >= len(l.messageRules) {
+		return ""
+	}
+	return l.messageRules[i]
 }
 
 // Message describes an error encountered while linting.
+//
+// Messages are encoded to JSON as an object with the severity name, such as
+// "WARNING", the path and the error text:
+//
+//	{"severity":"WARNING","path":"templates/","message":"..."}
 type Message struct {
 	// Severity is one of the *Sev constants
 	Severity int
@@ -49,6 +77,40 @@ type Message struct {
 	Err      error
 }
 
+// messageJSON is the JSON representation of a Message.
+type messageJSON struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m Message) MarshalJSON() ([]byte, error) {
+	out := messageJSON{Path: m.Path, Severity: SeverityName(m.Severity)}
+	if m.Err != nil {
+		out.Message = m.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The error of the decoded message
+// only carries the error text.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var in messageJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	severity, err := ParseSeverity(in.Severity)
+	if err != nil {
+		return err
+	}
+	*m = Message{Severity: severity, Path: in.Path}
+	if in.Message != "" {
+		m.Err = errors.New(in.Message)
+	}
+	return nil
+}
+
 func (m Message) Error() string {
 	return fmt.Sprintf("[%s] %s: %s", sev[m.Severity], m.Path, m.Err.Error())
 }
@@ -17,8 +17,14 @@ limitations under the License.
 package support
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/test"
 )
 
 var errLint = errors.New("lint failed")
@@ -77,3 +83,21 @@ func TestMessage(t *testing.T) {
 		t.Errorf("Unexpected output: %s", m.Error())
 	}
 }
+
+func TestLinterJSON(t *testing.T) {
+	linter := Linter{ChartDir: "/charts/hello", Cache: NewCache()}
+	linter.RunLinterRule(InfoSev, "Chart.yaml", errors.New("icon is recommended"))
+	linter.RunLinterRule(ErrorSev, "templates/", errors.New("parse error"))
+
+	data, err := json.MarshalIndent(linter, "", "  ")
+	require.NoError(t, err)
+	test.AssertGoldenString(t, string(data)+"\n", "linter.json")
+
+	var decoded Linter
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	linter.Cache = nil
+	assert.Equal(t, linter, decoded)
+
+	var m Message
+	assert.Error(t, json.Unmarshal([]byte(`{"severity":"FATAL"}`), &m))
+}
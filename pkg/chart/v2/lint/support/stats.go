@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// RuleStats records what a lint rule reported in a run and how long it took.
+type RuleStats struct {
+	// Rule is the name of the rule, such as "templates".
+	Rule string `json:"rule"`
+	// Messages counts the messages the rule reported by severity name, such
+	// as "WARNING".
+	Messages map[string]int `json:"messages,omitempty"`
+	// Duration is the time the rule took, encoded in nanoseconds.
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Add adds the counts and duration of other to s.
+func (s *RuleStats) Add(other RuleStats) {
+	for name, n := range other.Messages {
+		if s.Messages == nil {
+			s.Messages = make(map[string]int)
+		}
+		s.Messages[name] += n
+	}
+	s.Duration += other.Duration
+}
+
+// SeverityName returns the name of a severity, such as "WARNING".
+func SeverityName(severity int) string {
+	if severity < 0 || severity >= len(sev) {
+		return sev[UnknownSev]
+	}
+	return sev[severity]
+}
+
+// ParseSeverity returns the severity of a name returned by SeverityName.
+func ParseSeverity(name string) (int, error) {
+	severity := slices.Index(sev, name)
+	if severity < 0 {
+		return UnknownSev, fmt.Errorf("unknown lint message severity %q", name)
+	}
+	return severity, nil
+}
+
+// RunRule runs the named rule against l, records its statistics in l.Rules
+// and records it as the rule of the messages it reports, for MessageRule.
+func (l *Linter) RunRule(name string, rule func(l *Linter)) {
+	start := time.Now()
+	first := len(l.Messages)
+	rule(l)
+
+	stats := RuleStats{Rule: name, Duration: time.Since(start)}
+	for len(l.messageRules) < first {
+		l.messageRules = append(l.messageRules, "")
+	}
+	l.messageRules = l.messageRules[:first]
+	for i := first; i < len(l.Messages); i++ {
+		l.messageRules = append(l.messageRules, name)
+	}
+	for _, msg := range l.Messages[first:] {
+		if stats.Messages == nil {
+			stats.Messages = make(map[string]int)
+		}
+		stats.Messages[SeverityName(msg.Severity)]++
+	}
+	l.Rules = append(l.Rules, stats)
+}
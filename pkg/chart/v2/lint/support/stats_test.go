@@ -0,0 +1,60 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunRule(t *testing.T) {
+	linter := Linter{}
+	linter.RunRule("first", func(l *Linter) {
+		l.RunLinterRule(WarningSev, "Chart.yaml", errors.New("first warning"))
+		l.RunLinterRule(WarningSev, "Chart.yaml", errors.New("second warning"))
+		l.RunLinterRule(ErrorSev, "Chart.yaml", errors.New("error"))
+	})
+	linter.RunRule("second", func(l *Linter) {
+		time.Sleep(time.Millisecond)
+	})
+
+	if len(linter.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(linter.Rules))
+	}
+	first, second := linter.Rules[0], linter.Rules[1]
+	if first.Rule != "first" || first.Messages["WARNING"] != 2 || first.Messages["ERROR"] != 1 {
+		t.Errorf("unexpected statistics of the first rule: %+v", first)
+	}
+	if second.Rule != "second" || second.Messages != nil || second.Duration < time.Millisecond {
+		t.Errorf("unexpected statistics of the second rule: %+v", second)
+	}
+
+	for i := range linter.Messages {
+		if rule := linter.MessageRule(i); rule != "first" {
+			t.Errorf("expected message %d to be reported by the first rule, got %q", i, rule)
+		}
+	}
+	if rule := linter.MessageRule(len(linter.Messages)); rule != "" {
+		t.Errorf("expected no rule past the last message, got %q", rule)
+	}
+
+	first.Add(second)
+	if first.Messages["WARNING"] != 2 || first.Duration != linter.Rules[0].Duration+second.Duration {
+		t.Errorf("unexpected sum of statistics: %+v", first)
+	}
+}
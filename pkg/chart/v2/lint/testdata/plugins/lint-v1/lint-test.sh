@@ -0,0 +1,10 @@
+#!/bin/sh
+input=$(cat)
+case "$input" in
+  *goodone-here*)
+    echo '{"messages":[{"severity":"WARNING","path":"templates/goodone.yaml","message":"name is goodone-here"}]}'
+    ;;
+  *)
+    echo '{"messages":[]}'
+    ;;
+esac
@@ -18,6 +18,7 @@ package loader
 
 import (
 	"archive/tar"
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"errors"
@@ -29,6 +30,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/klauspost/compress/zstd"
+
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 )
 
@@ -79,7 +82,7 @@ func LoadFile(name string) (*chart.Chart, error) {
 	return c, err
 }
 
-// ensureArchive's job is to return an informative error if the file does not appear to be a gzipped archive.
+// ensureArchive's job is to return an informative error if the file does not appear to be a chart archive.
 //
 // Sometimes users will provide a values.yaml for an argument where a chart is expected. One common occurrence
 // of this is invoking `helm template values.yaml mychart` which would otherwise produce a confusing error
@@ -94,6 +97,11 @@ func ensureArchive(name string, raw *os.File) error {
 		return fmt.Errorf("file '%s' cannot be read: %s", name, err)
 	}
 
+	// Charts may also be packaged as zstd compressed or plain tar archives.
+	if isZstdApplication(buffer) || isTarApplication(buffer) {
+		return nil
+	}
+
 	// Helm may identify achieve of the application/x-gzip as application/vnd.ms-fontobject.
 	// Fix for: https://github.com/helm/helm/issues/12261
 	if contentType := http.DetectContentType(buffer); contentType != "application/x-gzip" && !isGZipApplication(buffer) {
@@ -115,11 +123,60 @@ func isGZipApplication(data []byte) bool {
 	return bytes.HasPrefix(data, sig)
 }
 
+// isZstdApplication checks whether the archive is a zstd frame.
+func isZstdApplication(data []byte) bool {
+	sig := []byte("\x28\xB5\x2F\xFD")
+	return bytes.HasPrefix(data, sig)
+}
+
+// isTarApplication checks whether the archive is an uncompressed POSIX or GNU tar archive.
+func isTarApplication(data []byte) bool {
+	const magicOffset = 257
+	return len(data) >= magicOffset+5 && bytes.Equal(data[magicOffset:magicOffset+5], []byte("ustar"))
+}
+
+// isArchiveName reports whether name has the extension of a chart archive.
+func isArchiveName(name string) bool {
+	for _, ext := range []string{".tgz", ".tar.zst", ".tar"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// decompress returns the tar stream of a chart archive. The archive may be
+// gzip or zstd compressed, or an uncompressed tar archive; the format is
+// detected from its first bytes. Anything else is read as gzip, so that the
+// errors for invalid archives stay those of compress/gzip.
+func decompress(in io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReaderSize(in, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case isZstdApplication(head):
+		zr, err := zstd.NewReader(br, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case isTarApplication(head):
+		return io.NopCloser(br), nil
+	default:
+		return gzip.NewReader(br)
+	}
+}
+
 // LoadArchiveFiles reads in files out of an archive into memory. This function
 // performs important path security checks and should always be used before
 // expanding a tarball
+//
+// The archive may be gzip or zstd compressed, or an uncompressed tar archive.
 func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
-	unzipped, err := gzip.NewReader(in)
+	unzipped, err := decompress(in)
 	if err != nil {
 		return nil, err
 	}
@@ -138,55 +195,14 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 			return nil, err
 		}
 
-		if hd.FileInfo().IsDir() {
-			// Use this instead of hd.Typeflag because we don't have to do any
-			// inference chasing.
-			continue
+		n, err := archiveFileName(hd)
+		if err != nil {
+			return nil, err
 		}
-
-		switch hd.Typeflag {
-		// We don't want to process these extension header files.
-		case tar.TypeXGlobalHeader, tar.TypeXHeader:
+		if n == "" {
 			continue
 		}
 
-		// Archive could contain \ if generated on Windows
-		delimiter := "/"
-		if strings.ContainsRune(hd.Name, '\\') {
-			delimiter = "\\"
-		}
-
-		parts := strings.Split(hd.Name, delimiter)
-		n := strings.Join(parts[1:], delimiter)
-
-		// Normalize the path to the / delimiter
-		n = strings.ReplaceAll(n, delimiter, "/")
-
-		if path.IsAbs(n) {
-			return nil, errors.New("chart illegally contains absolute paths")
-		}
-
-		n = path.Clean(n)
-		if n == "." {
-			// In this case, the original path was relative when it should have been absolute.
-			return nil, fmt.Errorf("chart illegally contains content outside the base directory: %q", hd.Name)
-		}
-		if strings.HasPrefix(n, "..") {
-			return nil, errors.New("chart illegally references parent directory")
-		}
-
-		// In some particularly arcane acts of path creativity, it is possible to intermix
-		// UNIX and Windows style paths in such a way that you produce a result of the form
-		// c:/foo even after all the built-in absolute path checks. So we explicitly check
-		// for this condition.
-		if drivePathPattern.MatchString(n) {
-			return nil, errors.New("chart contains illegally named files")
-		}
-
-		if parts[0] == "Chart.yaml" {
-			return nil, errors.New("chart yaml not in base directory")
-		}
-
 		if hd.Size > remainingSize {
 			return nil, fmt.Errorf("decompressed chart is larger than the maximum size %d", MaxDecompressedChartSize)
 		}
@@ -223,7 +239,8 @@ func LoadArchiveFiles(in io.Reader) ([]*BufferedFile, error) {
 	return files, nil
 }
 
-// LoadArchive loads from a reader containing a compressed tar archive.
+// LoadArchive loads from a reader containing a tar archive, optionally
+// compressed with gzip or zstd.
 func LoadArchive(in io.Reader) (*chart.Chart, error) {
 	files, err := LoadArchiveFiles(in)
 	if err != nil {
@@ -232,3 +249,59 @@ func LoadArchive(in io.Reader) (*chart.Chart, error) {
 
 	return LoadFiles(files)
 }
+
+// archiveFileName returns the normalized name of the file of a tar header
+// relative to the chart directory, or an empty name for headers that are not
+// files. It performs the path security checks of the archive.
+func archiveFileName(hd *tar.Header) (string, error) {
+	if hd.FileInfo().IsDir() {
+		// Use this instead of hd.Typeflag because we don't have to do any
+		// inference chasing.
+		return "", nil
+	}
+
+	switch hd.Typeflag {
+	// We don't want to process these extension header files.
+	case tar.TypeXGlobalHeader, tar.TypeXHeader:
+		return "", nil
+	}
+
+	// Archive could contain \ if generated on Windows
+	delimiter := "/"
+	if strings.ContainsRune(hd.Name, '\\') {
+		delimiter = "\\"
+	}
+
+	parts := strings.Split(hd.Name, delimiter)
+	n := strings.Join(parts[1:], delimiter)
+
+	// Normalize the path to the / delimiter
+	n = strings.ReplaceAll(n, delimiter, "/")
+
+	if path.IsAbs(n) {
+		return "", errors.New("chart illegally contains absolute paths")
+	}
+
+	n = path.Clean(n)
+	if n == "." {
+		// In this case, the original path was relative when it should have been absolute.
+		return "", fmt.Errorf("chart illegally contains content outside the base directory: %q", hd.Name)
+	}
+	if strings.HasPrefix(n, "..") {
+		return "", errors.New("chart illegally references parent directory")
+	}
+
+	// In some particularly arcane acts of path creativity, it is possible to intermix
+	// UNIX and Windows style paths in such a way that you produce a result of the form
+	// c:/foo even after all the built-in absolute path checks. So we explicitly check
+	// for this condition.
+	if drivePathPattern.MatchString(n) {
+		return "", errors.New("chart contains illegally named files")
+	}
+
+	if parts[0] == "Chart.yaml" {
+		return "", errors.New("chart yaml not in base directory")
+	}
+
+	return n, nil
+}
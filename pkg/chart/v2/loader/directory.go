@@ -42,26 +42,56 @@ func (l DirLoader) Load() (*chart.Chart, error) {
 //
 // This loads charts only from directories.
 func LoadDir(dir string) (*chart.Chart, error) {
-	topdir, err := filepath.Abs(dir)
+	files := []*BufferedFile{}
+	err := walkChartDir(dir, func(n, name string, fi os.FileInfo) error {
+		if fi.Size() > MaxDecompressedFileSize {
+			return fmt.Errorf("chart file %q is larger than the maximum file size %d", fi.Name(), MaxDecompressedFileSize)
+		}
+
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", n, err)
+		}
+
+		data = bytes.TrimPrefix(data, utf8bom)
+
+		files = append(files, &BufferedFile{Name: n, Data: data})
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		// Just used for errors.
+		return &chart.Chart{}, err
 	}
 
-	// Just used for errors.
-	c := &chart.Chart{}
+	return LoadFiles(files)
+}
 
-	rules := ignore.Empty()
-	ifile := filepath.Join(topdir, ignore.HelmIgnore)
-	if _, err := os.Stat(ifile); err == nil {
-		r, err := ignore.ParseFile(ifile)
-		if err != nil {
-			return c, err
-		}
-		rules = r
+// IgnoreRules returns the rules excluding files from the chart in dir when it
+// is loaded or packaged: the rules of the .helmignore files of the chart and
+// of its subdirectories, and the default rules.
+func IgnoreRules(dir string) (*ignore.Rules, error) {
+	rules, err := ignore.ParseDir(dir)
+	if err != nil {
+		return nil, err
 	}
 	rules.AddDefaults()
+	return rules, nil
+}
+
+// walkChartDir calls fn with the name relative to the chart and the path of
+// every regular file of the chart in dir that is not ignored by its
+// .helmignore files.
+func walkChartDir(dir string, fn func(n, name string, fi os.FileInfo) error) error {
+	topdir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	rules, err := IgnoreRules(topdir)
+	if err != nil {
+		return err
+	}
 
-	files := []*BufferedFile{}
 	topdir += string(filepath.Separator)
 
 	walk := func(name string, fi os.FileInfo, err error) error {
@@ -99,23 +129,7 @@ func LoadDir(dir string) (*chart.Chart, error) {
 			return fmt.Errorf("cannot load irregular file %s as it has file mode type bits set", name)
 		}
 
-		if fi.Size() > MaxDecompressedFileSize {
-			return fmt.Errorf("chart file %q is larger than the maximum file size %d", fi.Name(), MaxDecompressedFileSize)
-		}
-
-		data, err := os.ReadFile(name)
-		if err != nil {
-			return fmt.Errorf("error reading %s: %w", n, err)
-		}
-
-		data = bytes.TrimPrefix(data, utf8bom)
-
-		files = append(files, &BufferedFile{Name: n, Data: data})
-		return nil
-	}
-	if err = sympath.Walk(topdir, walk); err != nil {
-		return c, err
+		return fn(n, name, fi)
 	}
-
-	return LoadFiles(files)
+	return sympath.Walk(topdir, walk)
 }
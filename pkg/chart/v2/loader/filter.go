@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// Filter selects the files of a chart loaded by LoadFiltered. The zero Filter
+// loads every file.
+//
+// Patterns are matched with path.Match against the path of a file relative to
+// the chart directory, and against each of its parent directories, so
+// "templates/tests" selects every file in that directory. Patterns without a
+// slash are matched against base names, so "*.png" selects PNG files at any
+// depth, including in subcharts.
+//
+// The files describing a chart or subchart, such as Chart.yaml and
+// values.yaml, are always loaded.
+type Filter struct {
+	// Include are the patterns of the files to load. If empty, every file is
+	// included.
+	Include []string
+	// Exclude are the patterns of the files to leave out, even if included.
+	Exclude []string
+	// MaxFileSize leaves out the files larger than this many bytes, if it is
+	// greater than zero.
+	MaxFileSize int64
+}
+
+// IsZero reports whether the filter loads every file.
+func (f Filter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0 && f.MaxFileSize <= 0
+}
+
+// LoadFiltered loads the chart archive or directory at name, leaving out the
+// files not selected by the filter. Charts archived in the charts/ directory
+// are loaded whole if they are selected.
+func LoadFiltered(name string, filter Filter) (*chart.Chart, error) {
+	if filter.IsZero() {
+		return Load(name)
+	}
+	for _, p := range slices.Concat(filter.Include, filter.Exclude) {
+		if _, err := path.Match(strings.TrimSuffix(p, "/"), ""); err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %w", p, err)
+		}
+	}
+
+	lc, err := LoadLazy(name)
+	if err != nil {
+		return nil, err
+	}
+	return lc.Load(filter.skip)
+}
+
+func (f Filter) skip(lf LazyFile) bool {
+	if isChartFile(lf.Name) {
+		return false
+	}
+	if f.MaxFileSize > 0 && lf.Size > f.MaxFileSize {
+		return true
+	}
+	if len(f.Include) > 0 && !matchAny(f.Include, lf.Name) {
+		return true
+	}
+	return matchAny(f.Exclude, lf.Name)
+}
+
+// isChartFile reports whether name is one of the files describing the chart
+// or one of its unarchived subcharts.
+func isChartFile(name string) bool {
+	for {
+		if eagerFiles[name] {
+			return true
+		}
+		rest, ok := strings.CutPrefix(name, "charts/")
+		if !ok {
+			return false
+		}
+		_, name, ok = strings.Cut(rest, "/")
+		if !ok {
+			return false
+		}
+	}
+}
+
+// matchAny reports whether one of the patterns matches the file name or one
+// of its parent directories.
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		p = strings.TrimSuffix(p, "/")
+		for i := 0; i <= len(name); i++ {
+			if i < len(name) && name[i] != '/' {
+				continue
+			}
+			target := name[:i]
+			if !strings.Contains(p, "/") {
+				target = path.Base(target)
+			}
+			if ok, _ := path.Match(p, target); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadFiltered(t *testing.T) {
+	for _, name := range []string{"testdata/frobnitz", "testdata/frobnitz-1.2.3.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			c, err := LoadFiltered(name, Filter{
+				Include: []string{"templates/", "charts/alpine", "*.md"},
+				Exclude: []string{"docs"},
+			})
+			if err != nil {
+				t.Fatalf("Failed to load testdata: %s", err)
+			}
+			if c.Name() != "frobnitz" || c.Values == nil {
+				t.Errorf("Expected the metadata and values to be loaded, got %+v", c)
+			}
+			if len(c.Templates) != 1 {
+				t.Errorf("Expected the templates to be loaded, got %v", c.Templates)
+			}
+			var files []string
+			for _, f := range c.Files {
+				files = append(files, f.Name)
+			}
+			if !slices.Equal(files, []string{"README.md"}) {
+				t.Errorf("Expected only README.md in the files, got %v", files)
+			}
+
+			deps := c.Dependencies()
+			if len(deps) != 1 || deps[0].Name() != "alpine" {
+				t.Fatalf("Expected only the alpine subchart to be loaded, got %v", deps)
+			}
+			if len(deps[0].Templates) != 1 || len(deps[0].Dependencies()) != 2 {
+				t.Errorf("Unexpected alpine subchart %+v", deps[0])
+			}
+		})
+	}
+}
+
+func TestLoadFilteredKeepsChartFiles(t *testing.T) {
+	c, err := LoadFiltered("testdata/frobnitz", Filter{Include: []string{"templates/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Files) != 0 {
+		t.Errorf("Expected no files to be loaded, got %d", len(c.Files))
+	}
+	// Only the metadata and values of the unarchived subcharts are loaded.
+	deps := c.Dependencies()
+	if len(deps) != 1 || deps[0].Name() != "alpine" || deps[0].Values == nil || len(deps[0].Templates) != 0 {
+		t.Fatalf("Unexpected subcharts %v", deps)
+	}
+	if mast1 := deps[0].Dependencies(); len(mast1) != 1 || mast1[0].Name() != "mast1" {
+		t.Errorf("Expected the mast1 subchart of alpine to be loaded, got %v", mast1)
+	}
+}
+
+func TestLoadFilteredMaxFileSize(t *testing.T) {
+	c, err := LoadFiltered("testdata/frobnitz", Filter{MaxFileSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range c.Files {
+		if len(f.Data) > 100 {
+			t.Errorf("Expected %s to be left out", f.Name)
+		}
+	}
+	if c.Values == nil {
+		t.Error("Expected the values to be loaded regardless of their size")
+	}
+}
+
+func TestLoadFilteredInvalidPattern(t *testing.T) {
+	if _, err := LoadFiltered("testdata/frobnitz", Filter{Exclude: []string{"["}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/ignore"
+)
+
+// gitEntry is a file of a git tree.
+type gitEntry struct {
+	name   string
+	object string
+	mode   string
+	size   int64
+}
+
+// LoadGitRef loads the chart in the directory dir of a git working tree as
+// of the revision ref, such as a branch, a tag or a commit. The files are
+// read from the git object database, leaving the working tree untouched, so
+// dir does not have to exist in the working tree. The .helmignore files of the
+// chart at that revision are honored.
+//
+// The git executable must be installed.
+func LoadGitRef(dir, ref string) (*chart.Chart, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Run git in the closest existing directory, which is in the working tree.
+	cwd, pathspec := abs, "."
+	for {
+		if fi, err := os.Stat(cwd); err == nil && fi.IsDir() {
+			break
+		}
+		parent := filepath.Dir(cwd)
+		if parent == cwd {
+			return nil, fmt.Errorf("path %q not found", dir)
+		}
+		pathspec = path.Join(filepath.Base(cwd), pathspec)
+		cwd = parent
+	}
+
+	if _, err := runGit(cwd, nil, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("unknown git revision %q", ref)
+		}
+		return nil, err
+	}
+	out, err := runGit(cwd, nil, "ls-tree", "-r", "-l", "-z", ref, "--", pathspec)
+	if err != nil {
+		return nil, err
+	}
+	prefix := ""
+	if pathspec != "." {
+		prefix = pathspec + "/"
+	}
+	entries, err := parseGitTree(out, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("path %q not found in git revision %q", dir, ref)
+	}
+
+	data, err := readGitObjects(cwd, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := ignore.Empty()
+	var nested []*ignore.Rules
+	var nestedDirs []string
+	for i, e := range entries {
+		if path.Base(e.name) != ignore.HelmIgnore || e.mode == "160000" {
+			continue
+		}
+		r, err := ignore.Parse(bytes.NewReader(data[i]))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.name, err)
+		}
+		if e.name == ignore.HelmIgnore {
+			rules = r
+		} else {
+			nested = append(nested, r)
+			nestedDirs = append(nestedDirs, path.Dir(e.name))
+		}
+	}
+	for i, r := range nested {
+		rules.AddDir(nestedDirs[i], r)
+	}
+	rules.AddDefaults()
+
+	var files []*BufferedFile
+	for i, e := range entries {
+		if gitIgnored(rules, e) {
+			continue
+		}
+		switch e.mode {
+		case "120000":
+			return nil, fmt.Errorf("chart file %q is a symlink, which is not supported in git revisions", e.name)
+		case "160000":
+			// Submodules are not part of the tree.
+			continue
+		}
+		if e.size > MaxDecompressedFileSize {
+			return nil, fmt.Errorf("chart file %q is larger than the maximum file size %d", e.name, MaxDecompressedFileSize)
+		}
+		files = append(files, &BufferedFile{Name: e.name, Data: bytes.TrimPrefix(data[i], utf8bom)})
+	}
+	return LoadFiles(files)
+}
+
+// parseGitTree parses the output of git ls-tree -r -l -z, returning the
+// entries with their names relative to the directory prefix.
+func parseGitTree(out []byte, prefix string) ([]gitEntry, error) {
+	var entries []gitEntry
+	for _, record := range bytes.Split(out, []byte{0}) {
+		if len(record) == 0 {
+			continue
+		}
+		meta, name, ok := strings.Cut(string(record), "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 4 {
+			return nil, fmt.Errorf("unexpected git ls-tree output %q", record)
+		}
+		e := gitEntry{
+			name:   strings.TrimPrefix(name, prefix),
+			mode:   fields[0],
+			object: fields[2],
+		}
+		if fields[1] == "blob" {
+			size, err := strconv.ParseInt(fields[3], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected git ls-tree output %q", record)
+			}
+			e.size = size
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// readGitObjects returns the contents of the blobs of the entries, nil for
+// the other entries.
+func readGitObjects(dir string, entries []gitEntry) ([][]byte, error) {
+	var in bytes.Buffer
+	for _, e := range entries {
+		if e.mode != "160000" {
+			fmt.Fprintln(&in, e.object)
+		}
+	}
+	out, err := runGit(dir, &in, "cat-file", "--batch")
+	if err != nil {
+		return nil, err
+	}
+
+	r := bufio.NewReader(bytes.NewReader(out))
+	data := make([][]byte, len(entries))
+	for i, e := range entries {
+		if e.mode == "160000" {
+			continue
+		}
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("reading git object %s: %w", e.object, err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 || fields[0] != e.object {
+			return nil, fmt.Errorf("unexpected git cat-file output %q", strings.TrimSpace(header))
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git cat-file output %q", strings.TrimSpace(header))
+		}
+		if size > MaxDecompressedFileSize {
+			return nil, fmt.Errorf("chart file %q is larger than the maximum file size %d", e.name, MaxDecompressedFileSize)
+		}
+		data[i] = make([]byte, size)
+		if _, err := io.ReadFull(r, data[i]); err != nil {
+			return nil, fmt.Errorf("reading git object %s: %w", e.object, err)
+		}
+		if _, err := r.Discard(1); err != nil {
+			return nil, fmt.Errorf("reading git object %s: %w", e.object, err)
+		}
+	}
+	return data, nil
+}
+
+// gitIgnored reports whether the .helmignore rules ignore the entry or one of
+// its parent directories.
+func gitIgnored(rules *ignore.Rules, e gitEntry) bool {
+	parts := strings.Split(e.name, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if rules.Ignore(dir, gitFileInfo{name: parts[i-1], dir: true}) {
+			return true
+		}
+	}
+	return rules.Ignore(e.name, gitFileInfo{name: parts[len(parts)-1], size: e.size})
+}
+
+func runGit(dir string, stdin io.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// gitFileInfo describes a file of a git tree to the .helmignore rules.
+type gitFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi gitFileInfo) Name() string { return fi.name }
+func (fi gitFileInfo) Size() int64  { return fi.size }
+func (fi gitFileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+func (fi gitFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi gitFileInfo) IsDir() bool        { return fi.dir }
+func (fi gitFileInfo) Sys() any           { return nil }
@@ -0,0 +1,147 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a git repository with the chart at charts/foo, committed
+// and tagged v1, and returns its directory.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	chartDir := filepath.Join(repo, "charts", "foo")
+	writeFiles(t, chartDir, map[string]string{
+		"Chart.yaml":            "apiVersion: v2\nname: foo\nversion: 1.0.0\n",
+		"values.yaml":           "replicas: 1\n",
+		".helmignore":           "ignored/\n",
+		"ignored/secret.txt":    "secret\n",
+		"files/.helmignore":     "/*.bak\n",
+		"files/config.bak":      "old\n",
+		"files/config.txt":      "new\n",
+		"files/sub/config.bak":  "kept\n",
+		"templates/deploy.yaml": "replicas: {{ .Values.replicas }}\n",
+	})
+	writeFiles(t, repo, map[string]string{"README.md": "repo\n"})
+
+	git(t, repo, "init", "-q")
+	git(t, repo, "add", "-A")
+	git(t, repo, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "v1")
+	git(t, repo, "tag", "v1")
+	return repo
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadGitRef(t *testing.T) {
+	repo := gitRepo(t)
+	chartDir := filepath.Join(repo, "charts", "foo")
+
+	// Change the working tree after the tag.
+	writeFiles(t, chartDir, map[string]string{
+		"Chart.yaml":             "apiVersion: v2\nname: foo\nversion: 2.0.0\n",
+		"templates/service.yaml": "kind: Service\n",
+	})
+
+	c, err := LoadGitRef(chartDir, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Metadata.Version != "1.0.0" {
+		t.Errorf("Expected version 1.0.0, got %s", c.Metadata.Version)
+	}
+	if len(c.Templates) != 1 || c.Templates[0].Name != "templates/deploy.yaml" {
+		t.Errorf("Expected only the committed template, got %v", c.Templates)
+	}
+	files := map[string]bool{}
+	for _, f := range c.Files {
+		files[f.Name] = true
+		if strings.HasPrefix(f.Name, "ignored/") {
+			t.Errorf("Expected %s to be ignored", f.Name)
+		}
+	}
+	if files["files/config.bak"] || !files["files/config.txt"] || !files["files/sub/config.bak"] {
+		t.Errorf("Expected files/.helmignore to only ignore files/config.bak, got %v", files)
+	}
+	if c.Values["replicas"] != float64(1) {
+		t.Errorf("Unexpected values %v", c.Values)
+	}
+
+	// The working tree is left untouched.
+	data, err := os.ReadFile(filepath.Join(chartDir, "Chart.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "version: 2.0.0") {
+		t.Errorf("Expected the working tree to be unchanged, got:\n%s", data)
+	}
+}
+
+func TestLoadGitRefRemovedChart(t *testing.T) {
+	repo := gitRepo(t)
+	if err := os.RemoveAll(filepath.Join(repo, "charts")); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadGitRef(filepath.Join(repo, "charts", "foo"), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name() != "foo" {
+		t.Errorf("Expected chart foo, got %s", c.Name())
+	}
+}
+
+func TestLoadGitRefErrors(t *testing.T) {
+	repo := gitRepo(t)
+	chartDir := filepath.Join(repo, "charts", "foo")
+
+	if _, err := LoadGitRef(chartDir, "no-such-branch"); err == nil || err.Error() != `unknown git revision "no-such-branch"` {
+		t.Errorf("Expected an unknown revision error, got %v", err)
+	}
+	if _, err := LoadGitRef(filepath.Join(repo, "charts", "bar"), "v1"); err == nil || !strings.Contains(err.Error(), "not found in git revision") {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// LazyFile is a file of a LazyChart.
+type LazyFile struct {
+	// Name is the path of the file relative to the chart directory.
+	Name string
+	// Size is the size of the file in bytes.
+	Size int64
+}
+
+// LazyChart is a chart loaded without its templates and files.
+//
+// Only the files describing the chart, Chart.yaml, Chart.lock, values.yaml
+// and values.schema.json, are loaded into memory. The other files are only
+// listed, and read on demand, so that tools that only need the metadata or
+// the values of a chart do not pay for loading very large charts.
+type LazyChart struct {
+	// Chart holds the metadata, lock, values and schema of the chart, and
+	// their raw files. Its templates, files and dependencies are not loaded.
+	Chart *chart.Chart
+
+	files  []LazyFile
+	source lazySource
+}
+
+// lazySource reads the files of a lazily loaded chart.
+type lazySource interface {
+	// read calls fn with the data of each file for which want returns true.
+	read(want func(LazyFile) bool, fn func(f LazyFile, data []byte) error) error
+	// open streams the file name.
+	open(name string) (io.ReadCloser, error)
+}
+
+// eagerFiles are the files loaded by LoadLazy.
+var eagerFiles = map[string]bool{
+	"Chart.yaml":         true,
+	"Chart.lock":         true,
+	"values.yaml":        true,
+	"values.schema.json": true,
+	"requirements.yaml":  true,
+	"requirements.lock":  true,
+}
+
+// LoadLazy loads the chart archive or directory at name lazily.
+//
+// Reading the archive still decompresses it, but the data of the files that
+// are not loaded is discarded, so memory use does not depend on their size.
+// Only the loaded files count toward MaxDecompressedFileSize and
+// MaxDecompressedChartSize.
+func LoadLazy(name string) (*LazyChart, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var source lazySource
+	if fi.IsDir() {
+		topdir, err := filepath.Abs(name)
+		if err != nil {
+			return nil, err
+		}
+		source = lazyDir(topdir)
+	} else {
+		raw, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		err = ensureArchive(name, raw)
+		raw.Close()
+		if err != nil {
+			return nil, err
+		}
+		source = lazyArchive(name)
+	}
+
+	lc := &LazyChart{source: source}
+	var eager []*BufferedFile
+	err = source.read(func(f LazyFile) bool {
+		lc.files = append(lc.files, f)
+		return eagerFiles[f.Name]
+	}, func(f LazyFile, data []byte) error {
+		eager = append(eager, &BufferedFile{Name: f.Name, Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(lc.files) == 0 {
+		return nil, errors.New("no files in chart archive")
+	}
+
+	lc.Chart, err = LoadFiles(eager)
+	if err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+// Files lists all the files of the chart, including those of the subcharts
+// in charts/.
+func (lc *LazyChart) Files() []LazyFile {
+	return lc.files
+}
+
+// Templates lists the templates of the chart.
+func (lc *LazyChart) Templates() []LazyFile {
+	var templates []LazyFile
+	for _, f := range lc.files {
+		if strings.HasPrefix(f.Name, "templates/") {
+			templates = append(templates, f)
+		}
+	}
+	return templates
+}
+
+// ReadFile reads a file of the chart. Files larger than
+// MaxDecompressedFileSize cannot be read.
+func (lc *LazyChart) ReadFile(name string) ([]byte, error) {
+	var data []byte
+	found := false
+	err := lc.source.read(func(f LazyFile) bool {
+		return f.Name == name
+	}, func(_ LazyFile, d []byte) error {
+		data, found = d, true
+		return errStopReading
+	})
+	if err != nil && !errors.Is(err, errStopReading) {
+		return nil, err
+	}
+	if !found {
+		return nil, lc.notFound(name)
+	}
+	return data, nil
+}
+
+// Open streams a file of the chart. Unlike ReadFile, it does not read the
+// file into memory, so the file may be larger than MaxDecompressedFileSize.
+// The caller must close the returned reader.
+func (lc *LazyChart) Open(name string) (io.ReadCloser, error) {
+	if !slices.ContainsFunc(lc.files, func(f LazyFile) bool { return f.Name == name }) {
+		return nil, lc.notFound(name)
+	}
+	return lc.source.open(name)
+}
+
+func (lc *LazyChart) notFound(name string) error {
+	return fmt.Errorf("file %q not found in chart %s", name, lc.Chart.Name())
+}
+
+// Load loads the whole chart, leaving out the files for which skip returns
+// true. A nil skip loads all the files.
+func (lc *LazyChart) Load(skip func(LazyFile) bool) (*chart.Chart, error) {
+	var files []*BufferedFile
+	remainingSize := MaxDecompressedChartSize
+	err := lc.source.read(func(f LazyFile) bool {
+		return skip == nil || !skip(f)
+	}, func(f LazyFile, data []byte) error {
+		remainingSize -= int64(len(data))
+		if remainingSize < 0 {
+			return fmt.Errorf("decompressed chart is larger than the maximum size %d", MaxDecompressedChartSize)
+		}
+		files = append(files, &BufferedFile{Name: f.Name, Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return LoadFiles(files)
+}
+
+// SkipLargerThan skips the files larger than size when loading a LazyChart,
+// except the files every chart needs: its metadata, values and templates.
+func SkipLargerThan(size int64) func(LazyFile) bool {
+	return func(f LazyFile) bool {
+		return f.Size > size && !eagerFiles[f.Name] && !strings.HasPrefix(f.Name, "templates/")
+	}
+}
+
+// errStopReading stops reading the files of a chart early.
+var errStopReading = errors.New("stop reading")
+
+// readLimited reads a file of the given size, enforcing
+// MaxDecompressedFileSize.
+func readLimited(name string, size int64, r io.Reader) ([]byte, error) {
+	if size > MaxDecompressedFileSize {
+		return nil, fmt.Errorf("chart file %q is larger than the maximum file size %d", name, MaxDecompressedFileSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxDecompressedFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > MaxDecompressedFileSize {
+		return nil, fmt.Errorf("chart file %q is larger than the maximum file size %d", name, MaxDecompressedFileSize)
+	}
+	return bytes.TrimPrefix(data, utf8bom), nil
+}
+
+// lazyArchive is a chart archive, read again from the start for every read.
+type lazyArchive string
+
+// archiveReader reads the tar stream of a chart archive.
+type archiveReader struct {
+	*tar.Reader
+	raw      *os.File
+	unzipped io.ReadCloser
+}
+
+func (a lazyArchive) reader() (*archiveReader, error) {
+	raw, err := os.Open(string(a))
+	if err != nil {
+		return nil, err
+	}
+	unzipped, err := decompress(raw)
+	if err != nil {
+		raw.Close()
+		if errors.Is(err, gzip.ErrHeader) {
+			return nil, fmt.Errorf("file '%s' does not appear to be a valid chart file (details: %s)", string(a), err)
+		}
+		return nil, err
+	}
+	return &archiveReader{Reader: tar.NewReader(unzipped), raw: raw, unzipped: unzipped}, nil
+}
+
+func (r *archiveReader) Close() error {
+	return errors.Join(r.unzipped.Close(), r.raw.Close())
+}
+
+func (a lazyArchive) open(name string) (io.ReadCloser, error) {
+	tr, err := a.reader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		hd, err := tr.Next()
+		if err != nil {
+			tr.Close()
+			if err == io.EOF {
+				return nil, fmt.Errorf("file %q not found in %s", name, string(a))
+			}
+			return nil, err
+		}
+		if n, err := archiveFileName(hd); err != nil {
+			tr.Close()
+			return nil, err
+		} else if n == name {
+			return tr, nil
+		}
+	}
+}
+
+func (a lazyArchive) read(want func(LazyFile) bool, fn func(LazyFile, []byte) error) error {
+	tr, err := a.reader()
+	if err != nil {
+		return err
+	}
+	defer tr.Close()
+
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		n, err := archiveFileName(hd)
+		if err != nil {
+			return err
+		}
+		if n == "" {
+			continue
+		}
+
+		f := LazyFile{Name: n, Size: hd.Size}
+		if !want(f) {
+			continue
+		}
+		data, err := readLimited(n, hd.Size, tr)
+		if err != nil {
+			return err
+		}
+		if err := fn(f, data); err != nil {
+			return err
+		}
+	}
+}
+
+// lazyDir is a chart directory. Its files are read from disk when needed.
+type lazyDir string
+
+func (d lazyDir) open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+func (d lazyDir) read(want func(LazyFile) bool, fn func(LazyFile, []byte) error) error {
+	return walkChartDir(string(d), func(n, name string, fi os.FileInfo) error {
+		f := LazyFile{Name: n, Size: fi.Size()}
+		if !want(f) {
+			return nil
+		}
+		file, err := os.Open(name)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", n, err)
+		}
+		defer file.Close()
+		data, err := readLimited(n, f.Size, file)
+		if err != nil {
+			return err
+		}
+		return fn(f, data)
+	})
+}
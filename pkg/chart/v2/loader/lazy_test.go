@@ -0,0 +1,164 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadLazy(t *testing.T) {
+	for _, name := range []string{"testdata/frobnitz", "testdata/frobnitz-1.2.3.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			lc, err := LoadLazy(name)
+			if err != nil {
+				t.Fatalf("Failed to load testdata: %s", err)
+			}
+			c := lc.Chart
+			if c.Name() != "frobnitz" || c.Lock == nil || c.Values == nil {
+				t.Errorf("Expected the metadata, lock and values to be loaded, got %+v", c)
+			}
+			if len(c.Templates) != 0 || len(c.Files) != 0 || len(c.Dependencies()) != 0 {
+				t.Errorf("Expected the templates, files and dependencies not to be loaded")
+			}
+
+			templates := lc.Templates()
+			if len(templates) != 1 || templates[0].Name != "templates/template.tpl" || templates[0].Size == 0 {
+				t.Errorf("Unexpected templates %v", templates)
+			}
+			var names []string
+			for _, f := range lc.Files() {
+				names = append(names, f.Name)
+			}
+			for _, want := range []string{"Chart.yaml", "docs/README.md", "charts/mariner-4.3.2.tgz", "charts/alpine/Chart.yaml"} {
+				if !strings.Contains(strings.Join(names, ","), want) {
+					t.Errorf("Expected %s in the files, got %v", want, names)
+				}
+			}
+
+			data, err := lc.ReadFile("templates/template.tpl")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), "Hello {{.Name") {
+				t.Errorf("Unexpected template %q", data)
+			}
+			if _, err := lc.ReadFile("missing.txt"); err == nil || err.Error() != `file "missing.txt" not found in chart frobnitz` {
+				t.Errorf("Unexpected error %v", err)
+			}
+
+			r, err := lc.Open("templates/template.tpl")
+			if err != nil {
+				t.Fatal(err)
+			}
+			streamed, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(streamed, data) {
+				t.Errorf("Expected the streamed template to be %q, got %q", data, streamed)
+			}
+			if _, err := lc.Open("missing.txt"); err == nil || err.Error() != `file "missing.txt" not found in chart frobnitz` {
+				t.Errorf("Unexpected error %v", err)
+			}
+
+			full, err := lc.Load(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifyFrobnitz(t, full)
+			verifyChart(t, full)
+			verifyDependencies(t, full)
+		})
+	}
+}
+
+func TestLoadLazyLargeFiles(t *testing.T) {
+	defer func(size int64) { MaxDecompressedFileSize = size }(MaxDecompressedFileSize)
+	MaxDecompressedFileSize = 1024
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"big/Chart.yaml", []byte("apiVersion: v2\nname: big\nversion: 0.1.0\n")},
+		{"big/values.yaml", []byte("replicaCount: 1\n")},
+		{"big/templates/configmap.yaml", []byte("kind: ConfigMap\n")},
+		{"big/data/large.bin", bytes.Repeat([]byte("x"), 4096)},
+	}
+	var buf bytes.Buffer
+	zipper := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zipper)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	zipper.Close()
+	archive := filepath.Join(t.TempDir(), "big-0.1.0.tgz")
+	if err := os.WriteFile(archive, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadFile(archive); err == nil {
+		t.Fatal("Expected the archive to be too large to load in full")
+	}
+
+	lc, err := LoadLazy(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lc.Chart.Name() != "big" || lc.Chart.Values["replicaCount"] != 1.0 {
+		t.Errorf("Unexpected chart %+v", lc.Chart)
+	}
+	if f := lc.Files()[3]; f.Name != "data/large.bin" || f.Size != 4096 {
+		t.Errorf("Unexpected file %+v", f)
+	}
+	if _, err := lc.ReadFile("data/large.bin"); err == nil || !strings.Contains(err.Error(), "larger than the maximum file size 1024") {
+		t.Errorf("Expected a file size error, got %v", err)
+	}
+	if _, err := lc.Load(nil); err == nil {
+		t.Error("Expected an error loading the large file")
+	}
+	r, err := lc.Open("data/large.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if n, err := io.Copy(io.Discard, r); err != nil || n != 4096 {
+		t.Errorf("Expected to stream the large file, got %d bytes and %v", n, err)
+	}
+
+	c, err := lc.Load(SkipLargerThan(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Templates) != 1 || len(c.Files) != 0 {
+		t.Errorf("Expected the large file to be skipped, got templates %v and files %v", c.Templates, c.Files)
+	}
+}
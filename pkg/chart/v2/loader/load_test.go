@@ -26,6 +26,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -49,6 +50,26 @@ func TestLoadDir(t *testing.T) {
 	verifyDependenciesLock(t, c)
 }
 
+func TestLoadDirFileSources(t *testing.T) {
+	c, err := Load("testdata/frobnitz")
+	if err != nil {
+		t.Fatalf("Failed to load testdata: %s", err)
+	}
+	sources := c.FileSources()
+	if len(sources) == 0 {
+		t.Fatal("Expected file sources")
+	}
+	for _, s := range sources {
+		expected := chart.FileOriginChart
+		if s.Chart != c {
+			expected = chart.FileOriginDependency
+		}
+		if s.Origin != expected {
+			t.Errorf("Expected %s to come from %s, got %s", s.Path, expected, s.Origin)
+		}
+	}
+}
+
 func TestLoadDirWithDevNull(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("test only works on unix systems with /dev/null present")
@@ -88,6 +109,48 @@ func TestLoadDirWithSymlink(t *testing.T) {
 	verifyDependenciesLock(t, c)
 }
 
+func TestLoadDirWithNestedHelmignore(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Chart.yaml":              "apiVersion: v2\nname: nested\nversion: 1.0.0\n",
+		".helmignore":             "*.swp\n",
+		"files/.helmignore":       "/*.bak\ncache/\n",
+		"files/config.bak":        "ignored",
+		"files/config.swp":        "ignored",
+		"files/config.txt":        "kept",
+		"files/cache/data":        "ignored",
+		"files/sub/config.bak":    "kept",
+		"config.bak":              "kept",
+		"templates/configmap.yml": "kind: ConfigMap",
+	})
+
+	c, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, f := range c.Files {
+		files = append(files, f.Name)
+	}
+	sort.Strings(files)
+	expected := []string{".helmignore", "config.bak", "files/.helmignore", "files/config.txt", "files/sub/config.bak"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected files %v, got %v", expected, files)
+	}
+
+	rules, err := IgnoreRules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(filepath.Join(dir, "files", "config.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rules.Ignore("files/config.bak", fi) {
+		t.Error("Expected the compiled rules to ignore files/config.bak")
+	}
+}
+
 func TestBomTestData(t *testing.T) {
 	testFiles := []string{"frobnitz_with_bom/.helmignore", "frobnitz_with_bom/templates/template.tpl", "frobnitz_with_bom/Chart.yaml"}
 	for _, file := range testFiles {
@@ -544,6 +607,32 @@ foo:
 	}
 }
 
+func TestMergeMapsPatches(t *testing.T) {
+	merge := map[string]interface{}{common.PatchKey: "merge"}
+	a := map[string]interface{}{
+		"resources": map[string]interface{}{common.PatchKey: "replace", "cpu": "1", "memory": "1Gi"},
+		"labels":    map[string]interface{}{"team": "core"},
+		"args":      []interface{}{merge, "--port=80"},
+		"env":       []interface{}{"A=1"},
+	}
+	b := map[string]interface{}{
+		"resources": map[string]interface{}{common.PatchKey: "merge", "memory": "2Gi"},
+		"labels":    map[string]interface{}{common.PatchKey: "replace", "tier": "web"},
+		"args":      []interface{}{merge, "--verbose"},
+		"env":       []interface{}{"B=2"},
+	}
+
+	expected := map[string]interface{}{
+		"resources": map[string]interface{}{common.PatchKey: "replace", "cpu": "1", "memory": "2Gi"},
+		"labels":    map[string]interface{}{common.PatchKey: "replace", "tier": "web"},
+		"args":      []interface{}{merge, "--port=80", "--verbose"},
+		"env":       []interface{}{"B=2"},
+	}
+	if got := MergeMaps(a, b); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the patch directives of b to be followed. Expected: %v, got %v", expected, got)
+	}
+}
+
 func TestMergeValuesV2(t *testing.T) {
 	nestedMap := map[string]interface{}{
 		"foo": "bar",
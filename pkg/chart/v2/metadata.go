@@ -81,6 +81,8 @@ type Metadata struct {
 	Dependencies []*Dependency `json:"dependencies,omitempty"`
 	// Specifies the chart type: application or library
 	Type string `json:"type,omitempty"`
+	// Requires declares the prerequisites the cluster must meet for the chart.
+	Requires *Prerequisites `json:"requires,omitempty"`
 }
 
 // Validate checks the metadata for known issues and sanitizes string
@@ -132,6 +134,10 @@ func (md *Metadata) Validate() error {
 		}
 	}
 
+	if err := md.Requires.Validate(); err != nil {
+		return err
+	}
+
 	// Aliases need to be validated here to make sure that the alias name does
 	// not contain any illegal characters.
 	dependencies := map[string]*Dependency{}
@@ -177,6 +177,34 @@ func TestValidate(t *testing.T) {
 			&Metadata{APIVersion: "v2", Name: "test", Version: "1.2.3.4"},
 			ValidationError("chart.metadata.version \"1.2.3.4\" is invalid"),
 		},
+		{
+			"requires valid",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", Requires: &Prerequisites{
+				APIVersions:    []string{"v1", "monitoring.coreos.com/v1", "monitoring.coreos.com/v1/ServiceMonitor"},
+				MinNodes:       3,
+				StorageClasses: []string{"fast"},
+			}},
+			nil,
+		},
+		{
+			"requires invalid api version",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", Requires: &Prerequisites{
+				APIVersions: []string{"a/b/c/d"},
+			}},
+			ValidationError("requires.apiVersions entry \"a/b/c/d\" is invalid: use group/version or group/version/Kind"),
+		},
+		{
+			"requires negative node count",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", Requires: &Prerequisites{MinNodes: -1}},
+			ValidationError("requires.minNodes must not be negative, got -1"),
+		},
+		{
+			"requires empty storage class",
+			&Metadata{APIVersion: "v2", Name: "test", Version: "1.0", Requires: &Prerequisites{
+				StorageClasses: []string{" "},
+			}},
+			ValidationError("requires.storageClasses must not contain empty entries"),
+		},
 	}
 
 	for _, tt := range tests {
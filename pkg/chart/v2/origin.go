@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"path"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// FileOrigin tells where a file of a chart comes from.
+type FileOrigin string
+
+const (
+	// FileOriginChart is the origin of the files loaded with the chart itself.
+	FileOriginChart FileOrigin = "chart"
+	// FileOriginDependency is the origin of the files loaded with a
+	// dependency of the chart.
+	FileOriginDependency FileOrigin = "dependency"
+	// FileOriginInjected is the origin of the files added to a chart or to
+	// one of its dependencies after they were loaded, or changed since.
+	FileOriginInjected FileOrigin = "injected"
+)
+
+// FileSource describes a template or a file of a chart or of one of its
+// dependencies.
+type FileSource struct {
+	// Path is the name of the file prefixed with the full path of its chart,
+	// as in "mychart/charts/mysubchart/templates/service.yaml". It is the
+	// name templates are rendered under.
+	Path string
+	// Chart is the chart or dependency the file belongs to.
+	Chart *Chart
+	// File is the template or the file.
+	File *common.File
+	// Origin tells where the file comes from.
+	Origin FileOrigin
+}
+
+// FileSources returns the templates and the files of the chart and of its
+// dependencies, recursively, with their origin.
+//
+// A file is loaded with a chart if the loader read it, under the same name
+// and with the same contents, into the Raw files of that chart. The files of
+// charts that were not loaded, such as charts built in code, are injected.
+func (ch *Chart) FileSources() []FileSource {
+	var sources []FileSource
+	ch.appendFileSources(ch, &sources)
+	return sources
+}
+
+// FileSource returns the source with the given Path of the templates and the
+// files of the chart and of its dependencies, if there is one.
+func (ch *Chart) FileSource(name string) (FileSource, bool) {
+	for _, s := range ch.FileSources() {
+		if s.Path == name {
+			return s, true
+		}
+	}
+	return FileSource{}, false
+}
+
+func (ch *Chart) appendFileSources(root *Chart, sources *[]FileSource) {
+	origin := FileOriginChart
+	if ch != root {
+		origin = FileOriginDependency
+	}
+	raw := make(map[string][]byte, len(ch.Raw))
+	for _, f := range ch.Raw {
+		raw[f.Name] = f.Data
+	}
+
+	prefix := ch.ChartFullPath()
+	for _, files := range [][]*common.File{ch.Templates, ch.Files} {
+		for _, f := range files {
+			s := FileSource{Path: path.Join(prefix, f.Name), Chart: ch, File: f, Origin: origin}
+			if data, ok := raw[f.Name]; !ok || !bytes.Equal(data, f.Data) {
+				s.Origin = FileOriginInjected
+			}
+			*sources = append(*sources, s)
+		}
+	}
+
+	for _, dep := range ch.Dependencies() {
+		dep.appendFileSources(root, sources)
+	}
+}
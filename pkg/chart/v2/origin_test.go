@@ -0,0 +1,79 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+func TestFileSources(t *testing.T) {
+	loaded := func(name, data string) (*common.File, *common.File) {
+		return &common.File{Name: name, Data: []byte(data)}, &common.File{Name: name, Data: []byte(data)}
+	}
+
+	deploy, rawDeploy := loaded("templates/deploy.yaml", "kind: Deployment")
+	readme, rawReadme := loaded("README.md", "# parent")
+	parent := &Chart{
+		Metadata:  &Metadata{Name: "parent"},
+		Raw:       []*common.File{rawDeploy, rawReadme},
+		Templates: []*common.File{deploy, {Name: "templates/extra.yaml", Data: []byte("kind: ConfigMap")}},
+		Files:     []*common.File{readme},
+	}
+
+	svc, rawSvc := loaded("templates/service.yaml", "kind: Service")
+	sub := &Chart{
+		Metadata:  &Metadata{Name: "sub"},
+		Raw:       []*common.File{rawSvc, {Name: "templates/patched.yaml", Data: []byte("kind: ConfigMap")}},
+		Templates: []*common.File{svc, {Name: "templates/patched.yaml", Data: []byte("kind: Secret")}},
+	}
+	built := &Chart{
+		Metadata:  &Metadata{Name: "built"},
+		Templates: []*common.File{{Name: "templates/job.yaml", Data: []byte("kind: Job")}},
+	}
+	parent.AddDependency(sub, built)
+
+	expected := map[string]FileOrigin{
+		"parent/templates/deploy.yaml":             FileOriginChart,
+		"parent/templates/extra.yaml":              FileOriginInjected,
+		"parent/README.md":                         FileOriginChart,
+		"parent/charts/sub/templates/service.yaml": FileOriginDependency,
+		"parent/charts/sub/templates/patched.yaml": FileOriginInjected,
+		"parent/charts/built/templates/job.yaml":   FileOriginInjected,
+	}
+	sources := parent.FileSources()
+	assert.Len(t, sources, len(expected))
+	for _, s := range sources {
+		assert.Equal(t, expected[s.Path], s.Origin, s.Path)
+	}
+
+	s, ok := parent.FileSource("parent/charts/sub/templates/service.yaml")
+	assert.True(t, ok)
+	assert.Same(t, sub, s.Chart)
+	assert.Same(t, svc, s.File)
+
+	// The origin is relative to the chart asked.
+	s, ok = sub.FileSource("parent/charts/sub/templates/service.yaml")
+	assert.True(t, ok)
+	assert.Equal(t, FileOriginChart, s.Origin)
+
+	_, ok = parent.FileSource("parent/templates/missing.yaml")
+	assert.False(t, ok)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import "strings"
+
+// Prerequisites describes what a chart needs from the cluster it is
+// installed into, declared in the requires section of Chart.yaml.
+//
+// Helm checks the prerequisites before it applies any resource of the chart,
+// so that a missing operator or storage class fails the install with a clear
+// message instead of halfway through.
+type Prerequisites struct {
+	// APIVersions are the API versions, such as "monitoring.coreos.com/v1",
+	// and API resources, such as "monitoring.coreos.com/v1/ServiceMonitor",
+	// the cluster must serve. These are usually provided by CRDs installed
+	// outside of the chart.
+	APIVersions []string `json:"apiVersions,omitempty"`
+	// MinNodes is the number of nodes the cluster must have.
+	MinNodes int `json:"minNodes,omitempty"`
+	// StorageClasses are the names of the StorageClasses the cluster must
+	// have.
+	StorageClasses []string `json:"storageClasses,omitempty"`
+}
+
+// Validate checks the prerequisites for known issues and sanitizes string
+// characters.
+func (p *Prerequisites) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for i, v := range p.APIVersions {
+		v = sanitizeString(strings.TrimSpace(v))
+		if v == "" {
+			return ValidationError("requires.apiVersions must not contain empty entries")
+		}
+		if n := strings.Count(v, "/"); n > 2 || strings.Contains(v, "//") || strings.HasPrefix(v, "/") || strings.HasSuffix(v, "/") {
+			return ValidationErrorf("requires.apiVersions entry %q is invalid: use group/version or group/version/Kind", v)
+		}
+		p.APIVersions[i] = v
+	}
+	if p.MinNodes < 0 {
+		return ValidationErrorf("requires.minNodes must not be negative, got %d", p.MinNodes)
+	}
+	for i, sc := range p.StorageClasses {
+		sc = sanitizeString(strings.TrimSpace(sc))
+		if sc == "" {
+			return ValidationError("requires.storageClasses must not contain empty entries")
+		}
+		p.StorageClasses[i] = sc
+	}
+	return nil
+}
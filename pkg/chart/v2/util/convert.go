@@ -0,0 +1,217 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/internal/resolver"
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+const (
+	requirementsName     = "requirements.yaml"
+	requirementsLockName = "requirements.lock"
+	lockfileName         = "Chart.lock"
+)
+
+// ConvertedFile is a file of a chart rewritten by Convert.
+type ConvertedFile struct {
+	// Name is the path of the file relative to the chart directory.
+	Name string
+	// Old are the contents of the file before the conversion, nil if the
+	// file is created.
+	Old []byte
+	// New are the contents of the file after the conversion, nil if the
+	// file is removed.
+	New []byte
+}
+
+// Conversion describes the rewriting of the metadata files of a chart from
+// apiVersion v1 to apiVersion v2.
+type Conversion struct {
+	// Files are the files rewritten by the conversion, in the order they
+	// are written. Removed files are last.
+	Files []ConvertedFile
+	// Changes describe the conversion, one change per line.
+	Changes []string
+}
+
+// Empty reports whether the chart is already converted.
+func (c *Conversion) Empty() bool {
+	return len(c.Files) == 0
+}
+
+// Convert plans the conversion of the metadata files of the chart in dir
+// from apiVersion v1 to apiVersion v2, without changing the chart:
+//
+//   - apiVersion v1 is changed to v2 in Chart.yaml,
+//   - the dependencies in requirements.yaml are moved to Chart.yaml,
+//   - requirements.lock is rewritten as Chart.lock. A digest computed by
+//     Helm 2, which Helm only accepts for apiVersion v1 charts, is replaced
+//     by the digest Helm computes for the same dependencies.
+//
+// Apply makes the changes.
+func Convert(dir string) (*Conversion, error) {
+	c := &Conversion{}
+
+	chartfile := filepath.Join(dir, ChartfileName)
+	chartData, err := os.ReadFile(chartfile)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", ChartfileName, err)
+	}
+	cf, err := LoadChartfile(chartfile)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", ChartfileName, err)
+	}
+	saveChartfile := false
+	if cf.APIVersion == "" || cf.APIVersion == chart.APIVersionV1 {
+		cf.APIVersion = chart.APIVersionV2
+		saveChartfile = true
+		c.Changes = append(c.Changes, fmt.Sprintf("%s: changed apiVersion to %s", ChartfileName, chart.APIVersionV2))
+	}
+
+	var removed []ConvertedFile
+	requirements, err := readOptional(filepath.Join(dir, requirementsName))
+	if err != nil {
+		return nil, err
+	}
+	if requirements != nil {
+		var req struct {
+			Dependencies []*chart.Dependency `json:"dependencies"`
+		}
+		if err := yaml.Unmarshal(requirements, &req); err != nil {
+			return nil, fmt.Errorf("cannot load %s: %w", requirementsName, err)
+		}
+		if len(cf.Dependencies) > 0 && len(req.Dependencies) > 0 {
+			return nil, fmt.Errorf("dependencies are declared in both %s and %s, merge them into %s by hand", ChartfileName, requirementsName, ChartfileName)
+		}
+		if len(req.Dependencies) > 0 {
+			cf.Dependencies = req.Dependencies
+			saveChartfile = true
+		}
+		removed = append(removed, ConvertedFile{Name: requirementsName, Old: requirements})
+		c.Changes = append(c.Changes, fmt.Sprintf("%s: moved %d dependencies to %s", requirementsName, len(req.Dependencies), ChartfileName))
+	}
+
+	if saveChartfile {
+		out, err := yaml.Marshal(cf)
+		if err != nil {
+			return nil, err
+		}
+		c.Files = append(c.Files, ConvertedFile{Name: ChartfileName, Old: chartData, New: out})
+	}
+
+	requirementsLock, err := readOptional(filepath.Join(dir, requirementsLockName))
+	if err != nil {
+		return nil, err
+	}
+	if requirementsLock != nil {
+		if _, err := os.Stat(filepath.Join(dir, lockfileName)); err == nil {
+			return nil, fmt.Errorf("both %s and %s exist, remove one of them", requirementsLockName, lockfileName)
+		}
+		lock, rewritten, err := convertLock(requirementsLock, cf.Dependencies)
+		if err != nil {
+			return nil, err
+		}
+		c.Files = append(c.Files, ConvertedFile{Name: lockfileName, New: lock})
+		removed = append(removed, ConvertedFile{Name: requirementsLockName, Old: requirementsLock})
+		if rewritten {
+			c.Changes = append(c.Changes, fmt.Sprintf("%s: renamed to %s with the digest of Helm 3", requirementsLockName, lockfileName))
+		} else {
+			c.Changes = append(c.Changes, fmt.Sprintf("%s: renamed to %s", requirementsLockName, lockfileName))
+		}
+	}
+
+	c.Files = append(c.Files, removed...)
+	return c, nil
+}
+
+// convertLock returns the contents of Chart.lock for the requirements.lock
+// data. The lock is rewritten, and true is returned, only if its digest was
+// computed by Helm 2 for the dependencies.
+func convertLock(data []byte, deps []*chart.Dependency) ([]byte, bool, error) {
+	lock := &chart.Lock{}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, false, fmt.Errorf("cannot load %s: %w", requirementsLockName, err)
+	}
+
+	v2Digest, err := resolver.HashV2Req(deps)
+	if err != nil {
+		return nil, false, err
+	}
+	if lock.Digest != v2Digest {
+		return data, false, nil
+	}
+
+	if lock.Digest, err = resolver.HashReq(deps, lock.Dependencies); err != nil {
+		return nil, false, err
+	}
+	out, err := yaml.Marshal(lock)
+	return out, true, err
+}
+
+// readOptional returns the contents of the file, or nil if it does not exist.
+func readOptional(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Apply writes the converted files to the chart in dir, and removes the
+// files left out by the conversion.
+func (c *Conversion) Apply(dir string) error {
+	for _, f := range c.Files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+		var err error
+		if f.New == nil {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, f.New, 0644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Diff returns the unified diff of the conversion.
+func (c *Conversion) Diff() string {
+	var b strings.Builder
+	for _, f := range c.Files {
+		var a, n *common.File
+		if f.Old != nil {
+			a = &common.File{Name: f.Name, Data: f.Old}
+		}
+		if f.New != nil {
+			n = &common.File{Name: f.Name, Data: f.New}
+		}
+		b.WriteString(unifiedDiff(f.Name, a, n))
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/internal/resolver"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+func writeV1Chart(t *testing.T, lockDigest string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "legacy")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Chart.yaml":        "apiVersion: v1\nname: legacy\nversion: 0.1.0\n",
+		"requirements.yaml": "dependencies:\n- name: db\n  version: 1.0.0\n  repository: https://example.com/charts\n",
+		"requirements.lock": "dependencies:\n- name: db\n  version: 1.0.0\n  repository: https://example.com/charts\ndigest: " + lockDigest + "\ngenerated: \"2019-01-01T00:00:00Z\"\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestConvert(t *testing.T) {
+	deps := []*chart.Dependency{{Name: "db", Version: "1.0.0", Repository: "https://example.com/charts"}}
+	helm2Digest, err := resolver.HashV2Req(deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helm3Digest, err := resolver.HashReq(deps, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := writeV1Chart(t, helm2Digest)
+
+	c, err := Convert(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Chart.yaml: changed apiVersion to v2",
+		"requirements.yaml: moved 1 dependencies to Chart.yaml",
+		"requirements.lock: renamed to Chart.lock with the digest of Helm 3",
+	}
+	if !reflect.DeepEqual(c.Changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, c.Changes)
+	}
+	var names []string
+	for _, f := range c.Files {
+		names = append(names, f.Name)
+	}
+	if expected := []string{"Chart.yaml", "Chart.lock", "requirements.yaml", "requirements.lock"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected files %v, got %v", expected, names)
+	}
+
+	diff := c.Diff()
+	for _, s := range []string{
+		"--- a/Chart.yaml\n+++ b/Chart.yaml\n",
+		"-apiVersion: v1\n+apiVersion: v2\n",
+		"+digest: " + helm3Digest + "\n",
+		"--- a/requirements.yaml\n+++ b/requirements.yaml\n",
+	} {
+		if !strings.Contains(diff, s) {
+			t.Errorf("Expected the diff to contain %q, got:\n%s", s, diff)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Chart.lock")); !os.IsNotExist(err) {
+		t.Error("Expected Convert to leave the chart unchanged")
+	}
+
+	if err := c.Apply(dir); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := loader.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.Metadata.APIVersion != chart.APIVersionV2 || len(ch.Metadata.Dependencies) != 1 {
+		t.Errorf("Unexpected metadata %+v", ch.Metadata)
+	}
+	if ch.Lock == nil || ch.Lock.Digest != helm3Digest {
+		t.Errorf("Expected Chart.lock with digest %s, got %+v", helm3Digest, ch.Lock)
+	}
+	for _, name := range []string{"requirements.yaml", "requirements.lock"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", name)
+		}
+	}
+
+	c, err = Convert(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Empty() || len(c.Changes) != 0 || c.Diff() != "" {
+		t.Errorf("Expected nothing to convert, got %+v", c)
+	}
+}
+
+func TestConvertKeepsLockDigest(t *testing.T) {
+	dir := writeV1Chart(t, "sha256:abc")
+	c, err := Convert(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Changes[2] != "requirements.lock: renamed to Chart.lock" {
+		t.Errorf("Unexpected change %q", c.Changes[2])
+	}
+	old, err := os.ReadFile(filepath.Join(dir, "requirements.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(c.Files[1].New) != string(old) {
+		t.Errorf("Expected the lock to be renamed unchanged, got:\n%s", c.Files[1].New)
+	}
+}
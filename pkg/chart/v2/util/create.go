@@ -687,7 +687,7 @@ func CreateFrom(chartfile *chart.Metadata, dest, src string) error {
 	return SaveDir(schart, dest)
 }
 
-// Create creates a new chart in a directory.
+// Create creates a new chart in a directory from the default profile.
 //
 // Inside of dir, this will create a directory based on the name of
 // chartfile.Name. It will then write the Chart.yaml into this directory and
@@ -701,12 +701,23 @@ func CreateFrom(chartfile *chart.Metadata, dest, src string) error {
 // error. In such a case, this will attempt to clean up by removing the
 // new chart directory.
 func Create(name, dir string) (string, error) {
+	return CreateWithProfile(name, dir, DefaultProfile)
+}
+
+// CreateWithProfile creates a new chart in a directory, like Create, with the
+// files of the named profile. See Profiles for the available profiles.
+func CreateWithProfile(name, dir, profile string) (string, error) {
 
 	// Sanity-check the name of a chart so user doesn't create one that causes problems.
 	if err := validateChartName(name); err != nil {
 		return "", err
 	}
 
+	p, err := LookupProfile(profile)
+	if err != nil {
+		return "", err
+	}
+
 	path, err := filepath.Abs(dir)
 	if err != nil {
 		return path, err
@@ -723,90 +734,92 @@ func Create(name, dir string) (string, error) {
 		return cdir, fmt.Errorf("file %s already exists and is not a directory", cdir)
 	}
 
+	for _, file := range p.Files(name) {
+		fpath := filepath.Join(cdir, file.Name)
+		if _, err := os.Stat(fpath); err == nil {
+			// There is no handle to a preferred output stream here.
+			fmt.Fprintf(Stderr, "WARNING: File %q already exists. Overwriting.\n", fpath)
+		}
+		if err := writeFile(fpath, file.Data); err != nil {
+			return cdir, err
+		}
+	}
+	// Need to add the ChartsDir explicitly as it does not contain any file OOTB
+	if err := os.MkdirAll(filepath.Join(cdir, ChartsDir), 0755); err != nil {
+		return cdir, err
+	}
+	return cdir, nil
+}
+
+// deploymentFiles returns the files of the default profile, a web application
+// run by a Deployment.
+func deploymentFiles(name string) []*common.File {
 	// Note: If adding a new template below (i.e., to `helm create`) which is disabled by default (similar to hpa and
 	// ingress below); or making an existing template disabled by default, add the enabling condition in
 	// `TestHelmCreateChart_CheckDeprecatedWarnings` in `pkg/lint/lint_test.go` to make it run through deprecation checks
 	// with latest Kubernetes version.
-	files := []struct {
-		path    string
-		content []byte
-	}{
+	return []*common.File{
 		{
 			// Chart.yaml
-			path:    filepath.Join(cdir, ChartfileName),
-			content: fmt.Appendf(nil, defaultChartfile, name),
+			Name: ChartfileName,
+			Data: fmt.Appendf(nil, defaultChartfile, name),
 		},
 		{
 			// values.yaml
-			path:    filepath.Join(cdir, ValuesfileName),
-			content: fmt.Appendf(nil, defaultValues, name),
+			Name: ValuesfileName,
+			Data: fmt.Appendf(nil, defaultValues, name),
 		},
 		{
 			// .helmignore
-			path:    filepath.Join(cdir, IgnorefileName),
-			content: []byte(defaultIgnore),
+			Name: IgnorefileName,
+			Data: []byte(defaultIgnore),
 		},
 		{
 			// ingress.yaml
-			path:    filepath.Join(cdir, IngressFileName),
-			content: transform(defaultIngress, name),
+			Name: IngressFileName,
+			Data: transform(defaultIngress, name),
 		},
 		{
 			// httproute.yaml
-			path:    filepath.Join(cdir, HTTPRouteFileName),
-			content: transform(defaultHTTPRoute, name),
+			Name: HTTPRouteFileName,
+			Data: transform(defaultHTTPRoute, name),
 		},
 		{
 			// deployment.yaml
-			path:    filepath.Join(cdir, DeploymentName),
-			content: transform(defaultDeployment, name),
+			Name: DeploymentName,
+			Data: transform(defaultDeployment, name),
 		},
 		{
 			// service.yaml
-			path:    filepath.Join(cdir, ServiceName),
-			content: transform(defaultService, name),
+			Name: ServiceName,
+			Data: transform(defaultService, name),
 		},
 		{
 			// serviceaccount.yaml
-			path:    filepath.Join(cdir, ServiceAccountName),
-			content: transform(defaultServiceAccount, name),
+			Name: ServiceAccountName,
+			Data: transform(defaultServiceAccount, name),
 		},
 		{
 			// hpa.yaml
-			path:    filepath.Join(cdir, HorizontalPodAutoscalerName),
-			content: transform(defaultHorizontalPodAutoscaler, name),
+			Name: HorizontalPodAutoscalerName,
+			Data: transform(defaultHorizontalPodAutoscaler, name),
 		},
 		{
 			// NOTES.txt
-			path:    filepath.Join(cdir, NotesName),
-			content: transform(defaultNotes, name),
+			Name: NotesName,
+			Data: transform(defaultNotes, name),
 		},
 		{
 			// _helpers.tpl
-			path:    filepath.Join(cdir, HelpersName),
-			content: transform(defaultHelpers, name),
+			Name: HelpersName,
+			Data: transform(defaultHelpers, name),
 		},
 		{
 			// test-connection.yaml
-			path:    filepath.Join(cdir, TestConnectionName),
-			content: transform(defaultTestConnection, name),
+			Name: TestConnectionName,
+			Data: transform(defaultTestConnection, name),
 		},
 	}
-
-	for _, file := range files {
-		if _, err := os.Stat(file.path); err == nil {
-			// There is no handle to a preferred output stream here.
-			fmt.Fprintf(Stderr, "WARNING: File %q already exists. Overwriting.\n", file.path)
-		}
-		if err := writeFile(file.path, file.content); err != nil {
-			return cdir, err
-		}
-	}
-	// Need to add the ChartsDir explicitly as it does not contain any file OOTB
-	if err := os.MkdirAll(filepath.Join(cdir, ChartsDir), 0755); err != nil {
-		return cdir, err
-	}
-	return cdir, nil
 }
 
 // transform performs a string replacement of the specified source for
@@ -0,0 +1,486 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// CreateFromManifests creates a new chart in dir from existing Kubernetes
+// manifests, such as the output of 'kubectl get -o yaml'. The chart is named
+// after the base name of dir.
+//
+// Each object gets its own template. The names of the objects and the
+// references between them are prefixed with the release name, the common
+// labels of the chart are added, and the replicas and container images of
+// workloads are moved into values.yaml. Fields set by the cluster, such as
+// status and metadata.uid, and namespaces are removed.
+//
+// The returned string is the absolute path of the new chart.
+func CreateFromManifests(dir string, manifests []*common.File) (string, error) {
+	name := filepath.Base(dir)
+	if err := validateChartName(name); err != nil {
+		return "", err
+	}
+
+	objects, err := decodeManifests(manifests)
+	if err != nil {
+		return "", err
+	}
+	if len(objects) == 0 {
+		return "", errors.New("no Kubernetes objects found in the manifests")
+	}
+
+	cdir, err := filepath.Abs(dir)
+	if err != nil {
+		return cdir, err
+	}
+	if fi, err := os.Stat(filepath.Dir(cdir)); err != nil {
+		return cdir, err
+	} else if !fi.IsDir() {
+		return cdir, fmt.Errorf("no such directory %s", filepath.Dir(cdir))
+	}
+	if fi, err := os.Stat(cdir); err == nil && !fi.IsDir() {
+		return cdir, fmt.Errorf("file %s already exists and is not a directory", cdir)
+	}
+
+	files, err := newManifestConverter(name, objects).files()
+	if err != nil {
+		return cdir, err
+	}
+	for _, file := range files {
+		fpath := filepath.Join(cdir, file.Name)
+		if _, err := os.Stat(fpath); err == nil {
+			fmt.Fprintf(Stderr, "WARNING: File %q already exists. Overwriting.\n", fpath)
+		}
+		if err := writeFile(fpath, file.Data); err != nil {
+			return cdir, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(cdir, ChartsDir), 0755); err != nil {
+		return cdir, err
+	}
+	return cdir, nil
+}
+
+// manifestObject is a Kubernetes object decoded from a manifest.
+type manifestObject struct {
+	kind string
+	name string
+	obj  map[string]interface{}
+}
+
+// decodeManifests decodes the objects in the YAML or JSON manifests,
+// expanding lists.
+func decodeManifests(manifests []*common.File) ([]manifestObject, error) {
+	var objects []manifestObject
+	var add func(file string, obj map[string]interface{}) error
+	add = func(file string, obj map[string]interface{}) error {
+		kind, _ := obj["kind"].(string)
+		if kind == "" || obj["apiVersion"] == nil {
+			return fmt.Errorf("%s: object without apiVersion or kind", file)
+		}
+		if strings.HasSuffix(kind, "List") {
+			items, _ := obj["items"].([]interface{})
+			for _, item := range items {
+				if m, ok := item.(map[string]interface{}); ok {
+					if err := add(file, m); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+		name, _ := mapAt(obj, "metadata")["name"].(string)
+		objects = append(objects, manifestObject{kind: kind, name: name, obj: obj})
+		return nil
+	}
+
+	for _, f := range manifests {
+		decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(f.Data), 4096)
+		for {
+			var obj map[string]interface{}
+			if err := decoder.Decode(&obj); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("%s: %w", f.Name, err)
+			}
+			if len(obj) == 0 {
+				continue
+			}
+			if err := add(f.Name, obj); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return objects, nil
+}
+
+// templateToken matches the placeholders replaced by template actions after
+// an object is marshaled to YAML.
+var templateToken = regexp.MustCompile(`__HELM_TEMPLATE_[0-9]+__`)
+
+// labelsToken matches the placeholder label replaced by the common labels.
+var labelsToken = regexp.MustCompile(`(?m)^( *)__HELM_LABELS__: ""$`)
+
+// manifestConverter turns Kubernetes objects into the templates and values of
+// a chart.
+type manifestConverter struct {
+	chart   string
+	objects []manifestObject
+	// names are the names of the converted objects. References to them are
+	// prefixed like the names themselves.
+	names  map[string]bool
+	values map[string]interface{}
+	// actions are the template actions of the placeholders.
+	actions []string
+}
+
+func newManifestConverter(chart string, objects []manifestObject) *manifestConverter {
+	m := &manifestConverter{
+		chart:   chart,
+		objects: objects,
+		names:   make(map[string]bool),
+		values: map[string]interface{}{
+			"nameOverride":     "",
+			"fullnameOverride": "",
+		},
+	}
+	for _, o := range objects {
+		if o.name != "" {
+			m.names[o.name] = true
+		}
+	}
+	return m
+}
+
+// files returns all files of the chart.
+func (m *manifestConverter) files() ([]*common.File, error) {
+	files := []*common.File{
+		{Name: ChartfileName, Data: fmt.Appendf(nil, defaultChartfile, m.chart)},
+		{Name: IgnorefileName, Data: []byte(defaultIgnore)},
+		{Name: HelpersName, Data: transform(defaultHelpers, m.chart)},
+	}
+
+	keys := make(map[string]bool)
+	templates := make(map[string]bool)
+	for _, o := range m.objects {
+		key := valuesKey(o.name)
+		if keys[key] {
+			key += o.kind
+		}
+		keys[key] = true
+
+		data, err := m.template(o, key)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s %s: %w", o.kind, o.name, err)
+		}
+
+		base := strings.ToLower(o.kind)
+		if o.name != "" {
+			base = o.name + "-" + base
+		}
+		fname := base + ".yaml"
+		for i := 2; templates[fname]; i++ {
+			fname = fmt.Sprintf("%s-%d.yaml", base, i)
+		}
+		templates[fname] = true
+		files = append(files, &common.File{Name: filepath.Join(TemplatesDir, fname), Data: data})
+	}
+
+	values, err := yaml.Marshal(m.values)
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("# Default values for %s.\n# They were extracted from the manifests the chart was created from.\n\n", m.chart)
+	files = append(files, &common.File{Name: ValuesfileName, Data: append([]byte(header), values...)})
+	return files, nil
+}
+
+// template converts an object into a template, with its values under key.
+func (m *manifestConverter) template(o manifestObject, key string) ([]byte, error) {
+	obj := o.obj
+	delete(obj, "status")
+
+	meta := mapAt(obj, "metadata")
+	for _, field := range []string{"namespace", "uid", "resourceVersion", "creationTimestamp", "generation", "managedFields", "selfLink"} {
+		delete(meta, field)
+	}
+	if annotations := mapAt(meta, "annotations"); annotations != nil {
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		delete(annotations, "deployment.kubernetes.io/revision")
+		if len(annotations) == 0 {
+			delete(meta, "annotations")
+		}
+	}
+	if meta != nil {
+		m.rename(meta, "name")
+
+		// The common labels replace the labels they set.
+		labels := mapAt(meta, "labels")
+		if labels == nil {
+			labels = make(map[string]interface{})
+			meta["labels"] = labels
+		}
+		for _, l := range []string{"helm.sh/chart", "app.kubernetes.io/name", "app.kubernetes.io/instance", "app.kubernetes.io/version", "app.kubernetes.io/managed-by"} {
+			delete(labels, l)
+		}
+		labels["__HELM_LABELS__"] = ""
+	}
+
+	m.renameReferences(o.kind, obj)
+	if err := m.parameterize(o.kind, obj, key); err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	data = labelsToken.ReplaceAllFunc(data, func(line []byte) []byte {
+		indent := len(line) - len(bytes.TrimLeft(line, " "))
+		return fmt.Appendf(nil, "%s{{- include %q . | nindent %d }}", line[:indent], m.chart+".labels", indent)
+	})
+	data = templateToken.ReplaceAllFunc(data, func(token []byte) []byte {
+		var i int
+		fmt.Sscanf(string(token), "__HELM_TEMPLATE_%d__", &i)
+		return []byte(m.actions[i])
+	})
+	return data, nil
+}
+
+// action returns a placeholder for a template action.
+func (m *manifestConverter) action(action string) string {
+	m.actions = append(m.actions, action)
+	return fmt.Sprintf("__HELM_TEMPLATE_%d__", len(m.actions)-1)
+}
+
+// nameAction returns the template action of the name of an object, prefixed
+// with the full name of the release.
+func (m *manifestConverter) nameAction(name string) string {
+	if name == m.chart {
+		return fmt.Sprintf("{{ include %q . }}", m.chart+".fullname")
+	}
+	return fmt.Sprintf("{{ include %q . }}-%s", m.chart+".fullname", name)
+}
+
+// rename replaces the name in parent[field] if it is the name of a converted
+// object.
+func (m *manifestConverter) rename(parent map[string]interface{}, field string) {
+	if name, ok := parent[field].(string); ok && m.names[name] {
+		parent[field] = m.action(m.nameAction(name))
+	}
+}
+
+// renameReferences renames the references to other converted objects.
+func (m *manifestConverter) renameReferences(kind string, obj map[string]interface{}) {
+	spec := mapAt(obj, "spec")
+	switch kind {
+	case "StatefulSet":
+		m.rename(spec, "serviceName")
+	case "Ingress":
+		m.rename(mapAt(spec, "defaultBackend", "service"), "name")
+		for _, rule := range mapsAt(spec, "rules") {
+			for _, path := range mapsAt(mapAt(rule, "http"), "paths") {
+				m.rename(mapAt(path, "backend", "service"), "name")
+			}
+		}
+		for _, tls := range mapsAt(spec, "tls") {
+			m.rename(tls, "secretName")
+		}
+	case "HorizontalPodAutoscaler":
+		m.rename(mapAt(spec, "scaleTargetRef"), "name")
+	case "RoleBinding", "ClusterRoleBinding":
+		m.rename(mapAt(obj, "roleRef"), "name")
+		for _, subject := range mapsAt(obj, "subjects") {
+			if subject["kind"] == "ServiceAccount" {
+				m.rename(subject, "name")
+				if _, ok := subject["namespace"]; ok {
+					subject["namespace"] = m.action("{{ .Release.Namespace }}")
+				}
+			}
+		}
+	}
+
+	pod := workloadPodSpec(kind, obj)
+	if pod == nil {
+		return
+	}
+	m.rename(pod, "serviceAccountName")
+	for _, secret := range mapsAt(pod, "imagePullSecrets") {
+		m.rename(secret, "name")
+	}
+	for _, volume := range mapsAt(pod, "volumes") {
+		m.rename(mapAt(volume, "configMap"), "name")
+		m.rename(mapAt(volume, "secret"), "secretName")
+		m.rename(mapAt(volume, "persistentVolumeClaim"), "claimName")
+	}
+	for _, c := range containers(pod) {
+		for _, env := range mapsAt(c, "env") {
+			m.rename(mapAt(env, "valueFrom", "configMapKeyRef"), "name")
+			m.rename(mapAt(env, "valueFrom", "secretKeyRef"), "name")
+		}
+		for _, envFrom := range mapsAt(c, "envFrom") {
+			m.rename(mapAt(envFrom, "configMapRef"), "name")
+			m.rename(mapAt(envFrom, "secretRef"), "name")
+		}
+	}
+}
+
+// parameterize moves the replicas and container images of a workload into
+// the values under key.
+func (m *manifestConverter) parameterize(kind string, obj map[string]interface{}, key string) error {
+	vals := make(map[string]interface{})
+	path := ".Values." + key
+
+	switch kind {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		spec := mapAt(obj, "spec")
+		if replicas, ok := spec["replicas"]; ok {
+			vals["replicaCount"] = replicas
+			spec["replicas"] = m.action(fmt.Sprintf("{{ %s.replicaCount }}", path))
+		}
+	}
+
+	if pod := workloadPodSpec(kind, obj); pod != nil {
+		images := make(map[string]interface{})
+		for _, c := range containers(pod) {
+			image, ok := c["image"].(string)
+			if !ok || image == "" {
+				continue
+			}
+			cname, _ := c["name"].(string)
+			ckey := valuesKey(cname)
+			if _, ok := images[ckey]; ok {
+				return fmt.Errorf("containers with the same name %q", cname)
+			}
+			ipath := fmt.Sprintf("%s.images.%s", path, ckey)
+
+			repo, tag, digest := splitImage(image)
+			img := map[string]interface{}{"repository": repo}
+			action := fmt.Sprintf("{{ %s.repository }}", ipath)
+			switch {
+			case digest != "":
+				img["digest"] = digest
+				action += fmt.Sprintf("@{{ %s.digest }}", ipath)
+			case tag != "":
+				img["tag"] = tag
+				action += fmt.Sprintf(":{{ %s.tag }}", ipath)
+			}
+			images[ckey] = img
+			c["image"] = m.action(fmt.Sprintf("%q", action))
+		}
+		if len(images) > 0 {
+			vals["images"] = images
+		}
+	}
+
+	if len(vals) > 0 {
+		m.values[key] = vals
+	}
+	return nil
+}
+
+// workloadPodSpec returns the pod spec of a workload, or nil if kind has none.
+func workloadPodSpec(kind string, obj map[string]interface{}) map[string]interface{} {
+	switch kind {
+	case "Pod":
+		return mapAt(obj, "spec")
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		return mapAt(obj, "spec", "template", "spec")
+	case "CronJob":
+		return mapAt(obj, "spec", "jobTemplate", "spec", "template", "spec")
+	}
+	return nil
+}
+
+// containers returns the init and regular containers of a pod spec.
+func containers(pod map[string]interface{}) []map[string]interface{} {
+	return append(mapsAt(pod, "initContainers"), mapsAt(pod, "containers")...)
+}
+
+// splitImage splits a container image into its repository and its tag or
+// digest.
+func splitImage(image string) (repository, tag, digest string) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i], "", image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:], ""
+	}
+	return image, "", ""
+}
+
+// valuesKey returns a key usable in a .Values path for name, in lower camel
+// case such as "myApp" for "my-app".
+func valuesKey(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)):
+			upper = b.Len() > 0
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	key := b.String()
+	if key == "" || !unicode.IsLetter(rune(key[0])) {
+		key = "resource" + key
+	}
+	return key
+}
+
+// mapAt returns the map found by following fields from m, or nil.
+func mapAt(m map[string]interface{}, fields ...string) map[string]interface{} {
+	for _, f := range fields {
+		if m == nil {
+			return nil
+		}
+		m, _ = m[f].(map[string]interface{})
+	}
+	return m
+}
+
+// mapsAt returns the maps in the list at m[field].
+func mapsAt(m map[string]interface{}, field string) []map[string]interface{} {
+	list, _ := m[field].([]interface{})
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if im, ok := item.(map[string]interface{}); ok {
+			out = append(out, im)
+		}
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/engine"
+)
+
+const testManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+  uid: 6c9d3a52-0d4e-4b4e-9d0f-6a3f0b0f3c1e
+  labels:
+    app: web
+    app.kubernetes.io/name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      serviceAccountName: web
+      initContainers:
+        - name: migrate
+          image: registry.example.com:5000/migrate@sha256:0123456789abcdef
+      containers:
+        - name: nginx
+          image: nginx:1.25
+          envFrom:
+            - configMapRef:
+                name: web-config
+            - secretRef:
+                name: external-secret
+status:
+  replicas: 3
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: web-config
+    data:
+      key: value
+  - apiVersion: v1
+    kind: ServiceAccount
+    metadata:
+      name: web
+`
+
+func TestCreateFromManifests(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "myapp")
+	c, err := CreateFromManifests(dir, []*common.File{{Name: "app.yaml", Data: []byte(testManifests)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mychart, err := loader.LoadDir(c)
+	if err != nil {
+		t.Fatalf("Failed to load newly created chart %q: %s", c, err)
+	}
+	if mychart.Name() != "myapp" {
+		t.Errorf("Expected name to be 'myapp', got %q", mychart.Name())
+	}
+	if l := len(mychart.Templates); l != 4 {
+		t.Errorf("Expected 4 templates, got %d", l)
+	}
+
+	web, ok := mychart.Values["web"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected values for web, got %v", mychart.Values)
+	}
+	if web["replicaCount"] != 3.0 {
+		t.Errorf("Expected 3 replicas, got %v", web["replicaCount"])
+	}
+
+	vals, err := util.ToRenderValues(mychart, map[string]interface{}{
+		"web": map[string]interface{}{"images": map[string]interface{}{"nginx": map[string]interface{}{"tag": "1.26"}}},
+	}, common.ReleaseOptions{Name: "rel", Namespace: "default"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := engine.Render(mychart, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deployment := out["myapp/templates/web-deployment.yaml"]
+	for _, want := range []string{
+		"  name: rel-myapp-web\n",
+		"    app.kubernetes.io/name: myapp\n",
+		"    app: web\n",
+		"  replicas: 3\n",
+		"image: \"nginx:1.26\"",
+		"image: \"registry.example.com:5000/migrate@sha256:0123456789abcdef\"",
+		"name: rel-myapp-web-config\n",
+		"name: external-secret\n",
+		"serviceAccountName: rel-myapp-web\n",
+	} {
+		if !strings.Contains(deployment, want) {
+			t.Errorf("Expected %q in the deployment:\n%s", want, deployment)
+		}
+	}
+	for _, unwanted := range []string{"namespace:", "uid:", "status:", "app.kubernetes.io/name: web"} {
+		if strings.Contains(deployment, unwanted) {
+			t.Errorf("Unexpected %q in the deployment:\n%s", unwanted, deployment)
+		}
+	}
+	if cm := out["myapp/templates/web-config-configmap.yaml"]; !strings.Contains(cm, "name: rel-myapp-web-config\n") {
+		t.Errorf("Unexpected config map:\n%s", cm)
+	}
+}
+
+func TestCreateFromManifestsErrors(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "myapp")
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{data: "# only a comment\n", err: "no Kubernetes objects found"},
+		{data: "metadata:\n  name: x\n", err: "app.yaml: object without apiVersion or kind"},
+		{data: "kind: [\n", err: "app.yaml:"},
+	}
+	for _, tt := range tests {
+		_, err := CreateFromManifests(dir, []*common.File{{Name: "app.yaml", Data: []byte(tt.data)}})
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Expected error containing %q for %q, got %v", tt.err, tt.data, err)
+		}
+	}
+}
+
+func TestValuesKey(t *testing.T) {
+	for name, key := range map[string]string{
+		"web":            "web",
+		"my-app":         "myApp",
+		"my.app_v2":      "myAppV2",
+		"2048-game":      "resource2048Game",
+		"":               "resource",
+		"already-Camel-": "alreadyCamel",
+	} {
+		if got := valuesKey(name); got != key {
+			t.Errorf("valuesKey(%q) = %q, expected %q", name, got, key)
+		}
+	}
+}
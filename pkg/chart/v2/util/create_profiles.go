@@ -0,0 +1,780 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// DefaultProfile is the profile used by Create.
+const DefaultProfile = "deployment"
+
+// Profile is a scaffold that new charts can be created from, for a kind of
+// application such as a web service or a scheduled job.
+type Profile struct {
+	// Name identifies the profile, as in 'helm create --starter-profile NAME'.
+	Name string
+	// Description is a short summary of the charts the profile creates.
+	Description string
+	// Files returns the files of a new chart with the given name. Their names
+	// are relative to the chart directory and use the OS path separator.
+	Files func(name string) []*common.File
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]Profile{
+		DefaultProfile: {
+			Name:        DefaultProfile,
+			Description: "a web application run by a Deployment, with a Service and optional Ingress",
+			Files:       deploymentFiles,
+		},
+		"statefulset": {
+			Name:        "statefulset",
+			Description: "a stateful application run by a StatefulSet, with persistent storage",
+			Files:       statefulSetFiles,
+		},
+		"cronjob": {
+			Name:        "cronjob",
+			Description: "a job run on a schedule by a CronJob",
+			Files:       cronJobFiles,
+		},
+		"operator": {
+			Name:        "operator",
+			Description: "a Kubernetes controller, with RBAC rules and leader election",
+			Files:       operatorFiles,
+		},
+		"grpc": {
+			Name:        "grpc",
+			Description: "a gRPC service run by a Deployment, with gRPC health probes",
+			Files:       grpcFiles,
+		},
+		"library": {
+			Name:        "library",
+			Description: "a library chart providing named templates to other charts",
+			Files:       libraryFiles,
+		},
+	}
+)
+
+// RegisterProfile makes a profile available to CreateWithProfile. It returns
+// an error if the profile is incomplete or one with the same name exists.
+func RegisterProfile(p Profile) error {
+	if p.Name == "" || p.Files == nil {
+		return fmt.Errorf("profile %q must have a name and files", p.Name)
+	}
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	if _, ok := profiles[p.Name]; ok {
+		return fmt.Errorf("profile %q is already registered", p.Name)
+	}
+	profiles[p.Name] = p
+	return nil
+}
+
+// Profiles returns the registered profiles, sorted by name.
+func Profiles() []Profile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	out := make([]Profile, 0, len(profiles))
+	for _, p := range profiles {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// LookupProfile returns the registered profile with the given name.
+func LookupProfile(name string) (Profile, error) {
+	profilesMu.RLock()
+	p, ok := profiles[name]
+	profilesMu.RUnlock()
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for _, p := range Profiles() {
+			names = append(names, p.Name)
+		}
+		return Profile{}, fmt.Errorf("unknown profile %q, valid profiles are: %s", name, strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+const (
+	// StatefulSetName is the name of the example statefulset file.
+	StatefulSetName = TemplatesDir + sep + "statefulset.yaml"
+	// CronJobName is the name of the example cronjob file.
+	CronJobName = TemplatesDir + sep + "cronjob.yaml"
+	// RBACName is the name of the example RBAC file.
+	RBACName = TemplatesDir + sep + "rbac.yaml"
+)
+
+// commonFiles returns the files shared by the application profiles: the
+// Chart.yaml, values.yaml, .helmignore, helpers and service account.
+func commonFiles(name, values string) []*common.File {
+	return []*common.File{
+		{Name: ChartfileName, Data: fmt.Appendf(nil, defaultChartfile, name)},
+		{Name: ValuesfileName, Data: transform(values, name)},
+		{Name: IgnorefileName, Data: []byte(defaultIgnore)},
+		{Name: HelpersName, Data: transform(defaultHelpers, name)},
+		{Name: ServiceAccountName, Data: transform(defaultServiceAccount, name)},
+	}
+}
+
+func statefulSetFiles(name string) []*common.File {
+	return append(commonFiles(name, statefulSetValues),
+		&common.File{Name: StatefulSetName, Data: transform(statefulSetTemplate, name)},
+		&common.File{Name: ServiceName, Data: transform(statefulSetService, name)},
+		&common.File{Name: NotesName, Data: transform(statefulSetNotes, name)},
+		&common.File{Name: TestConnectionName, Data: transform(defaultTestConnection, name)},
+	)
+}
+
+func cronJobFiles(name string) []*common.File {
+	return append(commonFiles(name, cronJobValues),
+		&common.File{Name: CronJobName, Data: transform(cronJobTemplate, name)},
+		&common.File{Name: NotesName, Data: transform(cronJobNotes, name)},
+	)
+}
+
+func operatorFiles(name string) []*common.File {
+	return append(commonFiles(name, operatorValues),
+		&common.File{Name: DeploymentName, Data: transform(operatorDeployment, name)},
+		&common.File{Name: RBACName, Data: transform(operatorRBAC, name)},
+		&common.File{Name: NotesName, Data: transform(operatorNotes, name)},
+	)
+}
+
+func grpcFiles(name string) []*common.File {
+	return append(commonFiles(name, grpcValues),
+		&common.File{Name: DeploymentName, Data: transform(grpcDeployment, name)},
+		&common.File{Name: ServiceName, Data: transform(grpcService, name)},
+		&common.File{Name: NotesName, Data: transform(grpcNotes, name)},
+	)
+}
+
+func libraryFiles(name string) []*common.File {
+	chartfile := strings.Replace(fmt.Sprintf(defaultChartfile, name), "type: application", "type: library", 1)
+	return []*common.File{
+		{Name: ChartfileName, Data: []byte(chartfile)},
+		{Name: ValuesfileName, Data: transform(libraryValues, name)},
+		{Name: IgnorefileName, Data: []byte(defaultIgnore)},
+		{Name: HelpersName, Data: transform(libraryHelpers, name)},
+	}
+}
+
+// podValues are the values of the pod templates shared by the application
+// profiles.
+const podValues = `# This is the name of the secrets for pulling an image from a private repository.
+imagePullSecrets: []
+# This is to override the chart name.
+nameOverride: ""
+fullnameOverride: ""
+
+# This section builds out the service account more information can be found here: https://kubernetes.io/docs/concepts/security/service-accounts/
+serviceAccount:
+  # Specifies whether a service account should be created.
+  create: true
+  # Automatically mount a ServiceAccount's API credentials?
+  automount: true
+  # Annotations to add to the service account.
+  annotations: {}
+  # The name of the service account to use.
+  # If not set and create is true, a name is generated using the fullname template.
+  name: ""
+
+podAnnotations: {}
+podLabels: {}
+
+podSecurityContext: {}
+  # fsGroup: 2000
+
+securityContext: {}
+  # capabilities:
+  #   drop:
+  #   - ALL
+  # readOnlyRootFilesystem: true
+  # runAsNonRoot: true
+  # runAsUser: 1000
+
+resources: {}
+  # limits:
+  #   cpu: 100m
+  #   memory: 128Mi
+  # requests:
+  #   cpu: 100m
+  #   memory: 128Mi
+
+nodeSelector: {}
+
+tolerations: []
+
+affinity: {}
+`
+
+// podSpec is the part of a pod template shared by the application profiles,
+// before the containers, indented for a Deployment or StatefulSet.
+const podSpec = `    metadata:
+      {{- with .Values.podAnnotations }}
+      annotations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      labels:
+        {{- include "<CHARTNAME>.labels" . | nindent 8 }}
+        {{- with .Values.podLabels }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+    spec:
+      {{- with .Values.imagePullSecrets }}
+      imagePullSecrets:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      serviceAccountName: {{ include "<CHARTNAME>.serviceAccountName" . }}
+      {{- with .Values.podSecurityContext }}
+      securityContext:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.nodeSelector }}
+      nodeSelector:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.affinity }}
+      affinity:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+      {{- with .Values.tolerations }}
+      tolerations:
+        {{- toYaml . | nindent 8 }}
+      {{- end }}
+`
+
+// containerSpec is the start of the main container shared by the application
+// profiles, indented for a Deployment or StatefulSet.
+const containerSpec = `        - name: {{ .Chart.Name }}
+          {{- with .Values.securityContext }}
+          securityContext:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          {{- with .Values.resources }}
+          resources:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+`
+
+const statefulSetValues = `# Default values for <CHARTNAME>.
+# This is a YAML-formatted file.
+# Declare variables to be passed into your templates.
+
+# This will set the number of replicas more information can be found here: https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/
+replicaCount: 1
+
+# This sets the container image more information can be found here: https://kubernetes.io/docs/concepts/containers/images/
+image:
+  repository: nginx
+  # This sets the pull policy for images.
+  pullPolicy: IfNotPresent
+  # Overrides the image tag whose default is the chart appVersion.
+  tag: ""
+
+# This sets up the headless service that gives each replica a stable network identity.
+service:
+  port: 80
+
+# This sets up a persistent volume for each replica more information can be found here: https://kubernetes.io/docs/concepts/storage/persistent-volumes/
+persistence:
+  enabled: true
+  # The path the volume is mounted at in the container.
+  mountPath: /data
+  # If not set, the default storage class of the cluster is used.
+  storageClass: ""
+  accessModes:
+    - ReadWriteOnce
+  size: 1Gi
+
+` + podValues
+
+const statefulSetTemplate = `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  serviceName: {{ include "<CHARTNAME>.fullname" . }}
+  selector:
+    matchLabels:
+      {{- include "<CHARTNAME>.selectorLabels" . | nindent 6 }}
+  template:
+` + podSpec + `      containers:
+` + containerSpec + `          ports:
+            - name: http
+              containerPort: {{ .Values.service.port }}
+              protocol: TCP
+          {{- if .Values.persistence.enabled }}
+          volumeMounts:
+            - name: data
+              mountPath: {{ .Values.persistence.mountPath }}
+          {{- end }}
+  {{- if .Values.persistence.enabled }}
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+      spec:
+        accessModes:
+          {{- toYaml .Values.persistence.accessModes | nindent 10 }}
+        {{- with .Values.persistence.storageClass }}
+        storageClassName: {{ . }}
+        {{- end }}
+        resources:
+          requests:
+            storage: {{ .Values.persistence.size }}
+  {{- end }}
+`
+
+const statefulSetService = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  clusterIP: None
+  ports:
+    - port: {{ .Values.service.port }}
+      targetPort: http
+      protocol: TCP
+      name: http
+  selector:
+    {{- include "<CHARTNAME>.selectorLabels" . | nindent 4 }}
+`
+
+const statefulSetNotes = `1. Each replica is reachable at a stable address through the headless service:
+{{- range $i := until (int .Values.replicaCount) }}
+  {{ include "<CHARTNAME>.fullname" $ }}-{{ $i }}.{{ include "<CHARTNAME>.fullname" $ }}.{{ $.Release.Namespace }}.svc:{{ $.Values.service.port }}
+{{- end }}
+
+2. Watch the replicas start in order by running:
+  kubectl get pods --namespace {{ .Release.Namespace }} -l "app.kubernetes.io/name={{ include "<CHARTNAME>.name" . }},app.kubernetes.io/instance={{ .Release.Name }}" -w
+`
+
+const cronJobValues = `# Default values for <CHARTNAME>.
+# This is a YAML-formatted file.
+# Declare variables to be passed into your templates.
+
+# This sets the schedule of the job in cron format more information can be found here: https://kubernetes.io/docs/concepts/workloads/controllers/cron-jobs/#schedule-syntax
+schedule: "0 * * * *"
+# This sets the time zone of the schedule, such as "Etc/UTC". The time zone of the controller manager is used if it is not set.
+timeZone: ""
+# Allow, Forbid or Replace concurrent runs of the job.
+concurrencyPolicy: Forbid
+# Suspend future runs of the job.
+suspend: false
+successfulJobsHistoryLimit: 3
+failedJobsHistoryLimit: 1
+# The number of retries before a run of the job is marked as failed.
+backoffLimit: 2
+
+# This sets the container image more information can be found here: https://kubernetes.io/docs/concepts/containers/images/
+image:
+  repository: busybox
+  # This sets the pull policy for images.
+  pullPolicy: IfNotPresent
+  # Overrides the image tag whose default is the chart appVersion.
+  tag: "1.36"
+
+# The command run by the job.
+command:
+  - /bin/sh
+  - -c
+  - date; echo Hello from the scheduled job
+
+` + podValues
+
+const cronJobTemplate = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  schedule: {{ .Values.schedule | quote }}
+  {{- with .Values.timeZone }}
+  timeZone: {{ . | quote }}
+  {{- end }}
+  concurrencyPolicy: {{ .Values.concurrencyPolicy }}
+  suspend: {{ .Values.suspend }}
+  successfulJobsHistoryLimit: {{ .Values.successfulJobsHistoryLimit }}
+  failedJobsHistoryLimit: {{ .Values.failedJobsHistoryLimit }}
+  jobTemplate:
+    spec:
+      backoffLimit: {{ .Values.backoffLimit }}
+      template:
+        metadata:
+          {{- with .Values.podAnnotations }}
+          annotations:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          labels:
+            {{- include "<CHARTNAME>.labels" . | nindent 12 }}
+            {{- with .Values.podLabels }}
+            {{- toYaml . | nindent 12 }}
+            {{- end }}
+        spec:
+          restartPolicy: OnFailure
+          {{- with .Values.imagePullSecrets }}
+          imagePullSecrets:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          serviceAccountName: {{ include "<CHARTNAME>.serviceAccountName" . }}
+          {{- with .Values.podSecurityContext }}
+          securityContext:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          containers:
+            - name: {{ .Chart.Name }}
+              {{- with .Values.securityContext }}
+              securityContext:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+              image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+              imagePullPolicy: {{ .Values.image.pullPolicy }}
+              {{- with .Values.command }}
+              command:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+              {{- with .Values.resources }}
+              resources:
+                {{- toYaml . | nindent 16 }}
+              {{- end }}
+          {{- with .Values.nodeSelector }}
+          nodeSelector:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with .Values.affinity }}
+          affinity:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with .Values.tolerations }}
+          tolerations:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+`
+
+const cronJobNotes = `1. The job runs on the schedule "{{ .Values.schedule }}"{{ if .Values.suspend }}, but is suspended{{ end }}.
+
+2. Start a run of the job now by running:
+  kubectl create job --namespace {{ .Release.Namespace }} --from=cronjob/{{ include "<CHARTNAME>.fullname" . }} {{ include "<CHARTNAME>.fullname" . | trunc 50 | trimSuffix "-" }}-manual
+
+3. List the runs of the job by running:
+  kubectl get jobs --namespace {{ .Release.Namespace }} -l "app.kubernetes.io/instance={{ .Release.Name }}"
+`
+
+const operatorValues = `# Default values for <CHARTNAME>.
+# This is a YAML-formatted file.
+# Declare variables to be passed into your templates.
+
+# Only one replica is active at a time when leader election is enabled; the others are standbys.
+replicaCount: 1
+
+# This sets the container image of the controller more information can be found here: https://kubernetes.io/docs/concepts/containers/images/
+image:
+  repository: example.com/<CHARTNAME>-controller
+  # This sets the pull policy for images.
+  pullPolicy: IfNotPresent
+  # Overrides the image tag whose default is the chart appVersion.
+  tag: ""
+
+# Leader election makes sure only one replica reconciles resources at a time.
+leaderElection:
+  enabled: true
+
+# This creates the roles the controller needs more information can be found here: https://kubernetes.io/docs/reference/access-authn-authz/rbac/
+rbac:
+  create: true
+  # The rules of the cluster role of the controller. Grant access to the resources it manages.
+  rules:
+    - apiGroups: [""]
+      resources: ["configmaps"]
+      verbs: ["get", "list", "watch"]
+
+# The ports of the metrics and health probe endpoints of the controller.
+metrics:
+  port: 8080
+health:
+  port: 8081
+
+` + podValues
+
+const operatorDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      {{- include "<CHARTNAME>.selectorLabels" . | nindent 6 }}
+  template:
+` + podSpec + `      containers:
+` + containerSpec + `          args:
+            - --metrics-bind-address=:{{ .Values.metrics.port }}
+            - --health-probe-bind-address=:{{ .Values.health.port }}
+            {{- if .Values.leaderElection.enabled }}
+            - --leader-elect
+            {{- end }}
+          env:
+            - name: POD_NAMESPACE
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.namespace
+          ports:
+            - name: metrics
+              containerPort: {{ .Values.metrics.port }}
+              protocol: TCP
+            - name: health
+              containerPort: {{ .Values.health.port }}
+              protocol: TCP
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: health
+          readinessProbe:
+            httpGet:
+              path: /readyz
+              port: health
+`
+
+const operatorRBAC = `{{- if .Values.rbac.create -}}
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+rules:
+  {{- toYaml .Values.rbac.rules | nindent 2 }}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: {{ include "<CHARTNAME>.fullname" . }}
+subjects:
+  - kind: ServiceAccount
+    name: {{ include "<CHARTNAME>.serviceAccountName" . }}
+    namespace: {{ .Release.Namespace }}
+{{- if .Values.leaderElection.enabled }}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}-leader-election
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+rules:
+  - apiGroups: ["coordination.k8s.io"]
+    resources: ["leases"]
+    verbs: ["get", "list", "watch", "create", "update", "patch", "delete"]
+  - apiGroups: [""]
+    resources: ["events"]
+    verbs: ["create", "patch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}-leader-election
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: {{ include "<CHARTNAME>.fullname" . }}-leader-election
+subjects:
+  - kind: ServiceAccount
+    name: {{ include "<CHARTNAME>.serviceAccountName" . }}
+    namespace: {{ .Release.Namespace }}
+{{- end }}
+{{- end }}
+`
+
+const operatorNotes = `1. The controller is running in the {{ .Release.Namespace }} namespace. Follow its logs by running:
+  kubectl logs --namespace {{ .Release.Namespace }} deployment/{{ include "<CHARTNAME>.fullname" . }} -f
+
+2. Custom resource definitions placed in the crds/ directory of the chart are installed before the controller.
+`
+
+const grpcValues = `# Default values for <CHARTNAME>.
+# This is a YAML-formatted file.
+# Declare variables to be passed into your templates.
+
+# This will set the replicaset count more information can be found here: https://kubernetes.io/docs/concepts/workloads/controllers/replicaset/
+replicaCount: 1
+
+# This sets the container image more information can be found here: https://kubernetes.io/docs/concepts/containers/images/
+image:
+  repository: example.com/<CHARTNAME>
+  # This sets the pull policy for images.
+  pullPolicy: IfNotPresent
+  # Overrides the image tag whose default is the chart appVersion.
+  tag: ""
+
+# This is for setting up a service more information can be found here: https://kubernetes.io/docs/concepts/services-networking/service/
+service:
+  type: ClusterIP
+  port: 50051
+
+# The probes use the gRPC health checking protocol more information can be found here: https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-startup-probes/#define-a-grpc-liveness-probe
+livenessProbe:
+  grpc:
+    port: 50051
+readinessProbe:
+  grpc:
+    port: 50051
+
+` + podValues
+
+const grpcDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      {{- include "<CHARTNAME>.selectorLabels" . | nindent 6 }}
+  template:
+` + podSpec + `      containers:
+` + containerSpec + `          ports:
+            - name: grpc
+              containerPort: {{ .Values.service.port }}
+              protocol: TCP
+          {{- with .Values.livenessProbe }}
+          livenessProbe:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+          {{- with .Values.readinessProbe }}
+          readinessProbe:
+            {{- toYaml . | nindent 12 }}
+          {{- end }}
+`
+
+const grpcService = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "<CHARTNAME>.fullname" . }}
+  labels:
+    {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+spec:
+  type: {{ .Values.service.type }}
+  ports:
+    - port: {{ .Values.service.port }}
+      targetPort: grpc
+      protocol: TCP
+      appProtocol: grpc
+      name: grpc
+  selector:
+    {{- include "<CHARTNAME>.selectorLabels" . | nindent 4 }}
+`
+
+const grpcNotes = `1. Reach the gRPC service from your machine by running:
+  kubectl --namespace {{ .Release.Namespace }} port-forward service/{{ include "<CHARTNAME>.fullname" . }} {{ .Values.service.port }}
+
+2. Then, for example, check its health with grpcurl:
+  grpcurl -plaintext 127.0.0.1:{{ .Values.service.port }} grpc.health.v1.Health/Check
+`
+
+const libraryValues = `# Default values for <CHARTNAME>.
+#
+# Library charts are not installed, so these values are only used when the
+# templates of this chart are included by another chart. Values of the
+# including chart take precedence.
+`
+
+const libraryHelpers = `{{/*
+Named templates of this library. Charts using it declare it as a dependency
+in their Chart.yaml and include the templates, for example:
+
+  metadata:
+    name: {{ include "<CHARTNAME>.fullname" . }}
+    labels:
+      {{- include "<CHARTNAME>.labels" . | nindent 4 }}
+
+Templates of a library are shared with every chart that uses it, so their
+names are prefixed with the name of the library.
+*/}}
+
+{{/*
+Expand the name of the chart.
+*/}}
+{{- define "<CHARTNAME>.name" -}}
+{{- default .Chart.Name .Values.nameOverride | trunc 63 | trimSuffix "-" }}
+{{- end }}
+
+{{/*
+Create a default fully qualified app name.
+We truncate at 63 chars because some Kubernetes name fields are limited to this (by the DNS naming spec).
+If release name contains chart name it will be used as a full name.
+*/}}
+{{- define "<CHARTNAME>.fullname" -}}
+{{- if .Values.fullnameOverride }}
+{{- .Values.fullnameOverride | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- $name := default .Chart.Name .Values.nameOverride }}
+{{- if contains $name .Release.Name }}
+{{- .Release.Name | trunc 63 | trimSuffix "-" }}
+{{- else }}
+{{- printf "%s-%s" .Release.Name $name | trunc 63 | trimSuffix "-" }}
+{{- end }}
+{{- end }}
+{{- end }}
+
+{{/*
+Common labels
+*/}}
+{{- define "<CHARTNAME>.labels" -}}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version | replace "+" "_" | trunc 63 | trimSuffix "-" }}
+{{ include "<CHARTNAME>.selectorLabels" . }}
+{{- if .Chart.AppVersion }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+{{- end }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+
+{{/*
+Selector labels
+*/}}
+{{- define "<CHARTNAME>.selectorLabels" -}}
+app.kubernetes.io/name: {{ include "<CHARTNAME>.name" . }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+`
@@ -0,0 +1,118 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+func TestCreateWithProfile(t *testing.T) {
+	tests := []struct {
+		profile string
+		files   []string
+		typ     string
+	}{
+		{profile: "statefulset", files: []string{StatefulSetName, ServiceName, ServiceAccountName, TestConnectionName}, typ: "application"},
+		{profile: "cronjob", files: []string{CronJobName, ServiceAccountName}, typ: "application"},
+		{profile: "operator", files: []string{DeploymentName, RBACName, ServiceAccountName}, typ: "application"},
+		{profile: "grpc", files: []string{DeploymentName, ServiceName, ServiceAccountName}, typ: "application"},
+		{profile: "library", files: []string{HelpersName}, typ: "library"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			tdir := t.TempDir()
+			c, err := CreateWithProfile("foo", tdir, tt.profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mychart, err := loader.LoadDir(c)
+			if err != nil {
+				t.Fatalf("Failed to load newly created chart %q: %s", c, err)
+			}
+			if mychart.Name() != "foo" {
+				t.Errorf("Expected name to be 'foo', got %q", mychart.Name())
+			}
+			if mychart.Metadata.Type != tt.typ {
+				t.Errorf("Expected type %q, got %q", tt.typ, mychart.Metadata.Type)
+			}
+
+			for _, f := range append(tt.files, ChartfileName, ValuesfileName, IgnorefileName, ChartsDir) {
+				if _, err := os.Stat(filepath.Join(c, f)); err != nil {
+					t.Errorf("Expected %s file: %s", f, err)
+				}
+			}
+			for _, f := range mychart.Templates {
+				if strings.Contains(string(f.Data), "<CHARTNAME>") {
+					t.Errorf("Expected the chart name to be set in %s", f.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateWithUnknownProfile(t *testing.T) {
+	_, err := CreateWithProfile("foo", t.TempDir(), "unknown")
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "unknown"`) {
+		t.Errorf("Expected an unknown profile error, got %v", err)
+	}
+}
+
+func TestRegisterProfile(t *testing.T) {
+	p := Profile{
+		Name:        "test-register",
+		Description: "a single config map",
+		Files: func(name string) []*common.File {
+			return []*common.File{
+				{Name: ChartfileName, Data: []byte("apiVersion: v2\nname: " + name + "\nversion: 0.1.0\n")},
+				{Name: filepath.Join(TemplatesDir, "configmap.yaml"), Data: []byte("apiVersion: v1\nkind: ConfigMap\n")},
+			}
+		},
+	}
+	if err := RegisterProfile(p); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		profilesMu.Lock()
+		delete(profiles, p.Name)
+		profilesMu.Unlock()
+	})
+	if err := RegisterProfile(p); err == nil {
+		t.Error("Expected an error registering a profile twice")
+	}
+	if err := RegisterProfile(Profile{Name: "no-files"}); err == nil {
+		t.Error("Expected an error registering a profile without files")
+	}
+
+	c, err := CreateWithProfile("foo", t.TempDir(), p.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mychart, err := loader.LoadDir(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(mychart.Templates); l != 1 {
+		t.Errorf("Expected 1 template, got %d", l)
+	}
+}
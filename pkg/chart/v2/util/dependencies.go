@@ -94,6 +94,65 @@ func processDependencyTags(reqs []*chart.Dependency, cvals common.Values) {
 	}
 }
 
+// explainDependency describes why the dependency r was enabled or disabled by
+// processDependencyTags and processDependencyConditions. A condition with a
+// boolean value takes precedence over tags, which take precedence over the
+// default of being enabled.
+func explainDependency(r *chart.Dependency, cvals common.Values, cpath string) DependencyStatus {
+	s := DependencyStatus{Chart: r.Name, Version: r.Version, Enabled: r.Enabled}
+
+	var ignored []string
+	for c := range strings.SplitSeq(strings.TrimSpace(r.Condition), ",") {
+		if len(c) == 0 {
+			continue
+		}
+		vv, err := cvals.PathValue(cpath + c)
+		if err != nil {
+			continue
+		}
+		if bv, ok := vv.(bool); ok {
+			s.Source = "condition"
+			s.Reason = fmt.Sprintf("condition %q is %t", cpath+c, bv)
+			return s
+		}
+		ignored = append(ignored, fmt.Sprintf("condition %q is not a boolean and was ignored", cpath+c))
+	}
+
+	if vt, err := cvals.Table("tags"); err == nil {
+		var trueTags, falseTags []string
+		for _, k := range r.Tags {
+			if bv, ok := vt[k].(bool); ok {
+				if bv {
+					trueTags = append(trueTags, fmt.Sprintf("%q", k))
+				} else {
+					falseTags = append(falseTags, fmt.Sprintf("%q", k))
+				}
+			}
+		}
+		switch {
+		case len(trueTags) > 0:
+			s.Source = "tags"
+			s.Reason = fmt.Sprintf("tag %s is true", strings.Join(trueTags, ", "))
+		case len(falseTags) > 0:
+			s.Source = "tags"
+			s.Reason = fmt.Sprintf("tag %s is false", strings.Join(falseTags, ", "))
+		}
+	}
+	if s.Source == "" {
+		s.Source = "default"
+		switch {
+		case r.Condition == "" && len(r.Tags) == 0:
+			s.Reason = "no condition or tags are declared"
+		default:
+			s.Reason = "no condition or tag is set in the values"
+		}
+	}
+	if len(ignored) > 0 {
+		s.Reason = strings.Join(append(ignored, s.Reason), "; ")
+	}
+	return s
+}
+
 // getAliasDependency finds the chart for an alias dependency and copies parts that will be modified
 func getAliasDependency(charts []*chart.Chart, dep *chart.Dependency) *chart.Chart {
 	for _, c := range charts {
@@ -142,6 +201,63 @@ func copyMetadata(metadata *chart.Metadata) *chart.Metadata {
 
 // processDependencyEnabled removes disabled charts from dependencies
 func processDependencyEnabled(c *chart.Chart, v map[string]interface{}, path string) error {
+	return processDependencyEnabledWithStatus(c, v, path, nil)
+}
+
+// DependencyStatus reports whether a dependency of a chart is enabled for a
+// set of values, and why.
+type DependencyStatus struct {
+	// Path locates the dependency in the chart tree by name or alias, such as
+	// "backend.redis" for the redis dependency of the backend dependency.
+	Path string `json:"path"`
+	// Chart is the name of the chart the dependency refers to.
+	Chart string `json:"chart"`
+	// Version is the version range of the dependency.
+	Version string `json:"version"`
+	Enabled bool   `json:"enabled"`
+	// Source is what decided whether the dependency is enabled: "condition",
+	// "tags" or "default".
+	Source string `json:"source"`
+	// Reason explains the decision, such as `condition "redis.enabled" is false`.
+	Reason string `json:"reason"`
+	// Missing is set if the chart of the dependency is not in the charts/
+	// directory, so its own dependencies could not be checked.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// DependencyStatuses reports, for the values v, which dependencies of the
+// chart and of its enabled dependencies are enabled, and why. Dependencies are
+// listed in the order of Chart.yaml, each followed by its own dependencies.
+//
+// Like ProcessDependencies, it removes the disabled dependencies from c.
+func DependencyStatuses(c *chart.Chart, v common.Values) ([]DependencyStatus, error) {
+	var statuses []DependencyStatus
+	// Dependencies are processed breadth first, so sort the statuses of each
+	// chart after its parent.
+	children := make(map[string][]DependencyStatus)
+	var top []DependencyStatus
+	err := processDependencyEnabledWithStatus(c, v, "", func(parent string, s DependencyStatus) {
+		if parent == "" {
+			top = append(top, s)
+		} else {
+			children[parent] = append(children[parent], s)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	var add func([]DependencyStatus)
+	add = func(list []DependencyStatus) {
+		for _, s := range list {
+			statuses = append(statuses, s)
+			add(children[s.Path])
+		}
+	}
+	add(top)
+	return statuses, nil
+}
+
+func processDependencyEnabledWithStatus(c *chart.Chart, v map[string]interface{}, path string, record func(parent string, s DependencyStatus)) error {
 	if c.Metadata.Dependencies == nil {
 		return nil
 	}
@@ -162,6 +278,8 @@ Loop:
 		chartDependencies = append(chartDependencies, existing)
 	}
 
+	// chartNames are the chart names of aliased dependencies.
+	chartNames := make(map[*chart.Dependency]string)
 	for _, req := range c.Metadata.Dependencies {
 		if req == nil {
 			continue
@@ -170,6 +288,7 @@ Loop:
 			chartDependencies = append(chartDependencies, chartDependency)
 		}
 		if req.Alias != "" {
+			chartNames[req] = req.Name
 			req.Name = req.Alias
 		}
 	}
@@ -186,6 +305,27 @@ Loop:
 	// flag dependencies as enabled/disabled
 	processDependencyTags(c.Metadata.Dependencies, cvals)
 	processDependencyConditions(c.Metadata.Dependencies, cvals, path)
+	if record != nil {
+		parent := strings.TrimSuffix(path, ".")
+		for _, r := range c.Metadata.Dependencies {
+			if r == nil {
+				continue
+			}
+			s := explainDependency(r, cvals, path)
+			s.Path = path + r.Name
+			if name, ok := chartNames[r]; ok {
+				s.Chart = name
+			}
+			s.Missing = true
+			for _, dep := range chartDependencies {
+				if dep.Name() == r.Name {
+					s.Missing = false
+					break
+				}
+			}
+			record(parent, s)
+		}
+	}
 	// make a map of charts to remove
 	rm := map[string]struct{}{}
 	for _, r := range c.Metadata.Dependencies {
@@ -214,7 +354,7 @@ Loop:
 	// recursively call self to process sub dependencies
 	for _, t := range cd {
 		subpath := path + t.Metadata.Name + "."
-		if err := processDependencyEnabled(t, cvals, subpath); err != nil {
+		if err := processDependencyEnabledWithStatus(t, cvals, subpath, record); err != nil {
 			return err
 		}
 	}
@@ -62,6 +62,34 @@ func TestLoadDependency(t *testing.T) {
 	check(c.Lock.Dependencies)
 }
 
+func TestDependencyStatuses(t *testing.T) {
+	type M = map[string]interface{}
+	c := loadChart(t, "testdata/subpop")
+	statuses, err := DependencyStatuses(c, M{
+		"subchart1": M{"subcharta": M{"enabled": false}},
+		"subchart2": M{"enabled": "yes"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DependencyStatus{
+		{Path: "subchart1", Chart: "subchart1", Version: "0.1.0", Enabled: true, Source: "tags", Reason: `tag "front-end" is true`},
+		{Path: "subchart1.subcharta", Chart: "subcharta", Version: "0.1.0", Enabled: false, Source: "condition", Reason: `condition "subchart1.subcharta.enabled" is false`},
+		{Path: "subchart1.subchartb", Chart: "subchartb", Version: "0.1.0", Enabled: true, Source: "tags", Reason: `tag "front-end" is true`},
+		{Path: "subchart2", Chart: "subchart2", Version: "0.1.0", Enabled: false, Source: "tags", Reason: `condition "subchart2.enabled" is not a boolean and was ignored; tag "back-end" is false`},
+		{Path: "subchart2alias", Chart: "subchart2", Version: "0.1.0", Enabled: false, Source: "condition", Reason: `condition "subchart2alias.enabled" is false`},
+	}
+	if len(statuses) != len(expected) {
+		t.Fatalf("expected %d statuses, got %+v", len(expected), statuses)
+	}
+	for i, s := range statuses {
+		if s != expected[i] {
+			t.Errorf("status %d:\nexpected %+v\ngot      %+v", i, expected[i], s)
+		}
+	}
+}
+
 func TestDependencyEnabled(t *testing.T) {
 	type M = map[string]interface{}
 	tests := []struct {
@@ -0,0 +1,232 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// ChangeType is the kind of a change between two charts.
+type ChangeType string
+
+const (
+	// ChangeAdded is a field or file only present in the second chart.
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved is a field or file only present in the first chart.
+	ChangeRemoved ChangeType = "removed"
+	// ChangeModified is a field or file present in both charts with different contents.
+	ChangeModified ChangeType = "modified"
+)
+
+// FieldChange is a change of a Chart.yaml field or of a default value.
+type FieldChange struct {
+	// Path is the dotted path of the field, for example "image.tag".
+	Path string     `json:"path"`
+	Type ChangeType `json:"type"`
+	// Old is the value in the first chart, unset for added fields.
+	Old interface{} `json:"old,omitempty"`
+	// New is the value in the second chart, unset for removed fields.
+	New interface{} `json:"new,omitempty"`
+}
+
+// FileChange is a change of a template or file.
+type FileChange struct {
+	// Name is the path of the file relative to the chart directory.
+	Name string     `json:"name"`
+	Type ChangeType `json:"type"`
+	// Diff is the unified diff of the file. It is empty for binary files.
+	Diff string `json:"diff,omitempty"`
+}
+
+// ChartDiff is the difference between two charts.
+type ChartDiff struct {
+	// Metadata are the changes of the fields of Chart.yaml.
+	Metadata []FieldChange `json:"metadata"`
+	// Values are the changes of the default values of values.yaml.
+	Values []FieldChange `json:"values"`
+	// Templates are the changes of the files in templates/.
+	Templates []FileChange `json:"templates"`
+	// Files are the changes of the other files of the chart, including
+	// values.schema.json.
+	Files []FileChange `json:"files"`
+}
+
+// Empty reports whether the charts are identical.
+func (d *ChartDiff) Empty() bool {
+	return len(d.Metadata) == 0 && len(d.Values) == 0 && len(d.Templates) == 0 && len(d.Files) == 0
+}
+
+// DiffCharts returns the difference between the charts a and b.
+//
+// Maps are compared key by key, while lists are compared as a whole. The
+// subcharts of the charts are compared through the dependencies in their
+// metadata only.
+func DiffCharts(a, b *chart.Chart) (*ChartDiff, error) {
+	ma, err := metadataMap(a.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	mb, err := metadataMap(b.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ChartDiff{}
+	d.Metadata = diffMaps("", ma, mb, nil)
+	d.Values = diffMaps("", a.Values, b.Values, nil)
+	d.Templates = diffFiles(a.Templates, b.Templates)
+	d.Files = diffFiles(chartFiles(a), chartFiles(b))
+	return d, nil
+}
+
+// metadataMap returns the fields of the metadata as they are written to Chart.yaml.
+func metadataMap(md *chart.Metadata) (map[string]interface{}, error) {
+	if md == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, json.Unmarshal(data, &m)
+}
+
+// chartFiles returns the files of the chart, with the schema as values.schema.json.
+func chartFiles(c *chart.Chart) []*common.File {
+	if c.Schema == nil {
+		return c.Files
+	}
+	return append(slices.Clip(c.Files), &common.File{Name: SchemafileName, Data: c.Schema})
+}
+
+func diffMaps(prefix string, a, b map[string]interface{}, changes []FieldChange) []FieldChange {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(keys)) {
+		p := k
+		if prefix != "" {
+			p = prefix + "." + k
+		}
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inA:
+			changes = append(changes, FieldChange{Path: p, Type: ChangeAdded, New: vb})
+		case !inB:
+			changes = append(changes, FieldChange{Path: p, Type: ChangeRemoved, Old: va})
+		default:
+			ta, aIsMap := va.(map[string]interface{})
+			tb, bIsMap := vb.(map[string]interface{})
+			if aIsMap && bIsMap {
+				changes = diffMaps(p, ta, tb, changes)
+			} else if !reflect.DeepEqual(va, vb) {
+				changes = append(changes, FieldChange{Path: p, Type: ChangeModified, Old: va, New: vb})
+			}
+		}
+	}
+	return changes
+}
+
+func diffFiles(a, b []*common.File) []FileChange {
+	files := map[string][2]*common.File{}
+	for _, f := range a {
+		files[f.Name] = [2]*common.File{f, nil}
+	}
+	for _, f := range b {
+		pair := files[f.Name]
+		pair[1] = f
+		files[f.Name] = pair
+	}
+
+	var changes []FileChange
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		fa, fb := files[name][0], files[name][1]
+		var c FileChange
+		switch {
+		case fa == nil:
+			c = FileChange{Name: name, Type: ChangeAdded}
+		case fb == nil:
+			c = FileChange{Name: name, Type: ChangeRemoved}
+		case !bytes.Equal(fa.Data, fb.Data):
+			c = FileChange{Name: name, Type: ChangeModified}
+		default:
+			continue
+		}
+		c.Diff = unifiedDiff(name, fa, fb)
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// unifiedDiff returns the unified diff between two versions of a text file,
+// either of which may be missing.
+func unifiedDiff(name string, a, b *common.File) string {
+	var da, db []byte
+	if a != nil {
+		da = a.Data
+	}
+	if b != nil {
+		db = b.Data
+	}
+	if !utf8.Valid(da) || !utf8.Valid(db) {
+		return ""
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(da),
+		B:        diffLines(db),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+// diffLines splits data into lines, keeping their line endings. A missing
+// final newline is added, as difflib expects.
+func diffLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+	return lines
+}
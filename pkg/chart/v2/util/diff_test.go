@@ -0,0 +1,99 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestDiffCharts(t *testing.T) {
+	a := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ahab", Version: "1.0.0"},
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{"tag": "1.0", "pullPolicy": "IfNotPresent"},
+			"debug": true,
+		},
+		Templates: []*common.File{
+			{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\nreplicas: 1\n")},
+			{Name: "templates/service.yaml", Data: []byte("kind: Service\n")},
+		},
+		Files: []*common.File{
+			{Name: "README.md", Data: []byte("# ahab\n")},
+		},
+	}
+	b := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ahab", Version: "1.1.0", AppVersion: "2.0"},
+		Values: map[string]interface{}{
+			"image":    map[string]interface{}{"tag": "1.1", "pullPolicy": "IfNotPresent"},
+			"replicas": float64(2),
+		},
+		Templates: []*common.File{
+			{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\nreplicas: 2\n")},
+			{Name: "templates/ingress.yaml", Data: []byte("kind: Ingress\n")},
+		},
+		Files: []*common.File{
+			{Name: "README.md", Data: []byte("# ahab\n")},
+		},
+		Schema: []byte(`{"type": "object"}`),
+	}
+
+	d, err := DiffCharts(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMetadata := []FieldChange{
+		{Path: "appVersion", Type: ChangeAdded, New: "2.0"},
+		{Path: "version", Type: ChangeModified, Old: "1.0.0", New: "1.1.0"},
+	}
+	if !reflect.DeepEqual(d.Metadata, expectedMetadata) {
+		t.Errorf("Expected metadata changes %v, got %v", expectedMetadata, d.Metadata)
+	}
+	expectedValues := []FieldChange{
+		{Path: "debug", Type: ChangeRemoved, Old: true},
+		{Path: "image.tag", Type: ChangeModified, Old: "1.0", New: "1.1"},
+		{Path: "replicas", Type: ChangeAdded, New: float64(2)},
+	}
+	if !reflect.DeepEqual(d.Values, expectedValues) {
+		t.Errorf("Expected value changes %v, got %v", expectedValues, d.Values)
+	}
+
+	var templates []string
+	for _, c := range d.Templates {
+		templates = append(templates, string(c.Type)+" "+c.Name)
+	}
+	expectedTemplates := "modified templates/deployment.yaml,added templates/ingress.yaml,removed templates/service.yaml"
+	if got := strings.Join(templates, ","); got != expectedTemplates {
+		t.Errorf("Expected template changes %s, got %s", expectedTemplates, got)
+	}
+	if diff := d.Templates[0].Diff; !strings.Contains(diff, "-replicas: 1\n+replicas: 2\n") {
+		t.Errorf("Unexpected diff of templates/deployment.yaml:\n%s", diff)
+	}
+
+	if len(d.Files) != 1 || d.Files[0].Name != SchemafileName || d.Files[0].Type != ChangeAdded {
+		t.Errorf("Expected only %s to be added, got %v", SchemafileName, d.Files)
+	}
+
+	if d, err := DiffCharts(a, a); err != nil || !d.Empty() {
+		t.Errorf("Expected no changes between identical charts, got %v (%v)", d, err)
+	}
+}
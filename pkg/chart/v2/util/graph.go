@@ -0,0 +1,214 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// DependencyGraph is the graph of the dependencies of a chart and of its
+// dependencies, recursively. A chart required by several charts is a single
+// node, so the graph is a directed acyclic graph rooted at the chart.
+type DependencyGraph struct {
+	// Nodes are the charts of the graph, the root chart first, then in the
+	// order they are found following the dependencies of each chart: those
+	// declared in Chart.yaml in their order, then the others by name.
+	Nodes []*DependencyNode `json:"nodes"`
+	// Edges are the dependencies between the charts, in the same order.
+	Edges []*DependencyEdge `json:"edges"`
+}
+
+// DependencyNode is a chart of a DependencyGraph.
+type DependencyNode struct {
+	// ID identifies the chart in the graph, as "name@version".
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Missing is set if the chart of a dependency is not in the charts/
+	// directory. Version is then the version range of the dependency, and
+	// the dependencies of the chart are unknown.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// DependencyEdge is a dependency of a chart on another in a DependencyGraph.
+type DependencyEdge struct {
+	// From and To are the IDs of the dependent chart and of its dependency.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Alias, Version, Repository, Condition and Tags are those of the
+	// dependency in Chart.yaml. They are empty for the charts found in the
+	// charts/ directory without being declared.
+	Alias      string   `json:"alias,omitempty"`
+	Version    string   `json:"version,omitempty"`
+	Repository string   `json:"repository,omitempty"`
+	Condition  string   `json:"condition,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// BuildDependencyGraph returns the graph of the dependencies of the chart as
+// loaded, before the dependencies are enabled or disabled by values. Each
+// declared dependency is resolved to the chart of charts/ with its name and a
+// version in its range, as when the chart is installed.
+func BuildDependencyGraph(c *chart.Chart) *DependencyGraph {
+	g := &DependencyGraph{}
+	nodes := make(map[string]*DependencyNode)
+	g.addChart(c, nodes)
+	return g
+}
+
+// addChart adds the chart, if it is not in the graph yet, with its
+// dependencies, and returns its node.
+func (g *DependencyGraph) addChart(c *chart.Chart, nodes map[string]*DependencyNode) *DependencyNode {
+	id := c.Name() + "@" + c.Metadata.Version
+	if n, ok := nodes[id]; ok {
+		return n
+	}
+	n := &DependencyNode{ID: id, Name: c.Name(), Version: c.Metadata.Version}
+	nodes[id] = n
+	g.Nodes = append(g.Nodes, n)
+
+	resolved := make(map[*chart.Chart]bool)
+	for _, req := range c.Metadata.Dependencies {
+		if req == nil {
+			continue
+		}
+		edge := &DependencyEdge{
+			From:       id,
+			Alias:      req.Alias,
+			Version:    req.Version,
+			Repository: req.Repository,
+			Condition:  req.Condition,
+			Tags:       req.Tags,
+		}
+		g.Edges = append(g.Edges, edge)
+		var to *DependencyNode
+		for _, dep := range c.Dependencies() {
+			if dep.Name() == req.Name && IsCompatibleRange(req.Version, dep.Metadata.Version) {
+				resolved[dep] = true
+				to = g.addChart(dep, nodes)
+				break
+			}
+		}
+		if to == nil {
+			to = g.addMissing(req, nodes)
+		}
+		edge.To = to.ID
+	}
+
+	// Charts in charts/ are dependencies even if they are not declared.
+	var undeclared []*chart.Chart
+	for _, dep := range c.Dependencies() {
+		if !resolved[dep] {
+			undeclared = append(undeclared, dep)
+		}
+	}
+	sort.SliceStable(undeclared, func(i, j int) bool {
+		return undeclared[i].Name() < undeclared[j].Name()
+	})
+	for _, dep := range undeclared {
+		edge := &DependencyEdge{From: id}
+		g.Edges = append(g.Edges, edge)
+		edge.To = g.addChart(dep, nodes).ID
+	}
+	return n
+}
+
+func (g *DependencyGraph) addMissing(req *chart.Dependency, nodes map[string]*DependencyNode) *DependencyNode {
+	id := req.Name + "@" + req.Version
+	if n, ok := nodes[id]; ok {
+		return n
+	}
+	n := &DependencyNode{ID: id, Name: req.Name, Version: req.Version, Missing: true}
+	nodes[id] = n
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+// label describes the dependency on an edge of a drawing of the graph.
+func (e *DependencyEdge) label() string {
+	var parts []string
+	if e.Alias != "" {
+		parts = append(parts, "as "+e.Alias)
+	}
+	if e.Version != "" {
+		parts = append(parts, e.Version)
+	}
+	if e.Condition != "" {
+		parts = append(parts, "if "+e.Condition)
+	}
+	if len(e.Tags) > 0 {
+		parts = append(parts, "tags "+strings.Join(e.Tags, ","))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DOT returns the graph in the DOT language of Graphviz. Missing charts are
+// drawn dashed.
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s [label=%s", strconv.Quote(n.ID), strconv.Quote(n.Name+"\n"+n.Version))
+		if n.Missing {
+			b.WriteString(", style=dashed")
+		}
+		b.WriteString("];\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s", strconv.Quote(e.From), strconv.Quote(e.To))
+		if label := e.label(); label != "" {
+			fmt.Fprintf(&b, " [label=%s]", strconv.Quote(label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart. Missing charts are drawn
+// with rounded corners and a question mark.
+func (g *DependencyGraph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("graph TD\n")
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		if n.Missing {
+			fmt.Fprintf(&b, "\t%s(%s)\n", ids[n.ID], mermaidText(n.Name+" "+n.Version+" ?"))
+		} else {
+			fmt.Fprintf(&b, "\t%s[%s]\n", ids[n.ID], mermaidText(n.Name+" "+n.Version))
+		}
+	}
+	for _, e := range g.Edges {
+		if label := e.label(); label != "" {
+			fmt.Fprintf(&b, "\t%s -->|%s| %s\n", ids[e.From], mermaidText(label), ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "\t%s --> %s\n", ids[e.From], ids[e.To])
+		}
+	}
+	return b.String()
+}
+
+// mermaidText quotes text for Mermaid, which escapes quotes as entities.
+func mermaidText(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
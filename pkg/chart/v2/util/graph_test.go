@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestBuildDependencyGraph(t *testing.T) {
+	newChart := func(name, version string, deps ...*chart.Dependency) *chart.Chart {
+		return &chart.Chart{Metadata: &chart.Metadata{Name: name, Version: version, Dependencies: deps}}
+	}
+
+	redis := newChart("redis", "18.1.0")
+	backend := newChart("backend", "1.0.0", &chart.Dependency{Name: "redis", Version: "^18.0.0"})
+	backend.AddDependency(newChart("redis", "18.1.0"))
+	root := newChart("app", "0.1.0",
+		&chart.Dependency{Name: "backend", Version: "1.0.0", Repository: "https://example.com/charts", Condition: "backend.enabled"},
+		&chart.Dependency{Name: "redis", Alias: "cache", Version: "~18.1", Tags: []string{"cache"}},
+		&chart.Dependency{Name: "postgresql", Version: "^15.0.0", Repository: "oci://example.com/charts"},
+	)
+	root.AddDependency(backend, redis, newChart("local", "0.0.1"))
+
+	g := BuildDependencyGraph(root)
+
+	expectedDOT := `digraph dependencies {
+	"app@0.1.0" [label="app\n0.1.0"];
+	"backend@1.0.0" [label="backend\n1.0.0"];
+	"redis@18.1.0" [label="redis\n18.1.0"];
+	"postgresql@^15.0.0" [label="postgresql\n^15.0.0", style=dashed];
+	"local@0.0.1" [label="local\n0.0.1"];
+	"app@0.1.0" -> "backend@1.0.0" [label="1.0.0, if backend.enabled"];
+	"backend@1.0.0" -> "redis@18.1.0" [label="^18.0.0"];
+	"app@0.1.0" -> "redis@18.1.0" [label="as cache, ~18.1, tags cache"];
+	"app@0.1.0" -> "postgresql@^15.0.0" [label="^15.0.0"];
+	"app@0.1.0" -> "local@0.0.1";
+}
+`
+	if dot := g.DOT(); dot != expectedDOT {
+		t.Errorf("Expected DOT:\n%s\ngot:\n%s", expectedDOT, dot)
+	}
+
+	expectedMermaid := `graph TD
+	n0["app 0.1.0"]
+	n1["backend 1.0.0"]
+	n2["redis 18.1.0"]
+	n3("postgresql ^15.0.0 ?")
+	n4["local 0.0.1"]
+	n0 -->|"1.0.0, if backend.enabled"| n1
+	n1 -->|"^18.0.0"| n2
+	n0 -->|"as cache, ~18.1, tags cache"| n2
+	n0 -->|"^15.0.0"| n3
+	n0 --> n4
+`
+	if mermaid := g.Mermaid(); mermaid != expectedMermaid {
+		t.Errorf("Expected Mermaid:\n%s\ngot:\n%s", expectedMermaid, mermaid)
+	}
+
+	if e := g.Edges[0]; e.Repository != "https://example.com/charts" || e.Condition != "backend.enabled" {
+		t.Errorf("Unexpected edge %+v", e)
+	}
+	if n := g.Nodes[3]; !n.Missing || n.Name != "postgresql" {
+		t.Errorf("Expected postgresql to be missing, got %+v", n)
+	}
+}
+
+func TestBuildDependencyGraphMermaidQuotes(t *testing.T) {
+	c := &chart.Chart{Metadata: &chart.Metadata{
+		Name:         "app",
+		Version:      "0.1.0",
+		Dependencies: []*chart.Dependency{{Name: "dep", Version: "1.0.0", Condition: `a"b`}},
+	}}
+	expected := `graph TD
+	n0["app 0.1.0"]
+	n1("dep 1.0.0 ?")
+	n0 -->|"1.0.0, if a#quot;b"| n1
+`
+	if mermaid := BuildDependencyGraph(c).Mermaid(); mermaid != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, mermaid)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	crdInstallHook = regexp.MustCompile(`["']?helm\.sh/hook["']?\s*:\s*["']?[a-z,\- ]*\bcrd-install\b`)
+	removedObjects = regexp.MustCompile(`\.(Release\.Time|Capabilities\.TillerVersion)\b`)
+)
+
+// hookAnnotations are dropped from the CRDs moved out of crd-install hooks.
+var hookAnnotations = []string{"helm.sh/hook", "helm.sh/hook-weight", "helm.sh/hook-delete-policy"}
+
+// Migration describes the changes Migrate made to a chart.
+type Migration struct {
+	// Changes are the changes made to the chart.
+	Changes []string
+	// Manual are the Helm 2 constructs left in the chart that have to be
+	// migrated by hand.
+	Manual []string
+}
+
+// Migrate rewrites the chart in dir from the Helm 2 layout to the apiVersion
+// v2 layout:
+//
+//   - apiVersion v1 is changed to v2,
+//   - the dependencies in requirements.yaml are moved to Chart.yaml,
+//   - requirements.lock is renamed to Chart.lock, see Convert,
+//   - templates of CRDs installed with the crd-install hook, which Helm 3
+//     removed, are moved to the crds/ directory.
+//
+// Constructs that cannot be migrated automatically, such as templated CRDs
+// and the built-in objects removed in Helm 3, are listed in Manual.
+//
+// If dryRun is true the chart is left unchanged, and the returned Migration
+// describes the changes that would be made.
+func Migrate(dir string, dryRun bool) (*Migration, error) {
+	m := &Migration{}
+	var ops []func() error
+
+	conv, err := Convert(dir)
+	if err != nil {
+		return nil, err
+	}
+	m.Changes = append(m.Changes, conv.Changes...)
+	if !conv.Empty() {
+		ops = append(ops, func() error { return conv.Apply(dir) })
+	}
+
+	templateOps, err := migrateTemplates(dir, m)
+	if err != nil {
+		return nil, err
+	}
+	ops = append(ops, templateOps...)
+
+	if dryRun {
+		return m, nil
+	}
+	for _, op := range ops {
+		if err := op(); err != nil {
+			return m, err
+		}
+	}
+	return m, nil
+}
+
+// migrateTemplates returns the operations moving the CRDs of crd-install
+// hooks to the crds/ directory, and records the constructs of the templates
+// that have to be migrated by hand.
+func migrateTemplates(dir string, m *Migration) ([]func() error, error) {
+	var ops []func() error
+	templates := filepath.Join(dir, TemplatesDir)
+	err := filepath.WalkDir(templates, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == templates {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		seen := make(map[string]bool)
+		for _, match := range removedObjects.FindAllSubmatch(data, -1) {
+			if object := "." + string(match[1]); !seen[object] {
+				seen[object] = true
+				m.Manual = append(m.Manual, fmt.Sprintf("%s: %s was removed in Helm 3", name, object))
+			}
+		}
+
+		if !crdInstallHook.Match(data) {
+			return nil
+		}
+		if bytes.Contains(data, []byte("{{")) {
+			m.Manual = append(m.Manual, fmt.Sprintf("%s: uses the crd-install hook, but templated CRDs have to be moved to crds/ by hand", name))
+			return nil
+		}
+		crd, err := stripCRDHooks(data)
+		if err != nil {
+			m.Manual = append(m.Manual, fmt.Sprintf("%s: uses the crd-install hook, but %s", name, err))
+			return nil
+		}
+
+		target := filepath.Join(dir, "crds", strings.TrimPrefix(name, TemplatesDir+"/"))
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("cannot move %s: %s already exists", name, target)
+		}
+		ops = append(ops, func() error {
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, crd, 0644); err != nil {
+				return err
+			}
+			return os.Remove(path)
+		})
+		targetRel, _ := filepath.Rel(dir, target)
+		m.Changes = append(m.Changes, fmt.Sprintf("%s: moved the crd-install hook to %s", name, filepath.ToSlash(targetRel)))
+		return nil
+	})
+	return ops, err
+}
+
+// stripCRDHooks removes the hook annotations from the CRDs in data. It fails
+// if data holds anything but CRDs.
+func stripCRDHooks(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	decoder := k8syaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("it cannot be parsed: %w", err)
+		}
+		if obj == nil {
+			continue
+		}
+		if obj["kind"] != "CustomResourceDefinition" {
+			return nil, fmt.Errorf("it holds a %v that is not a CRD", obj["kind"])
+		}
+		if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				for _, a := range hookAnnotations {
+					delete(annotations, a)
+				}
+				if len(annotations) == 0 {
+					delete(metadata, "annotations")
+				}
+			}
+		}
+		doc, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		if out.Len() > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(doc)
+	}
+	return out.Bytes(), nil
+}
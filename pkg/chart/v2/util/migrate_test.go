@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+)
+
+func writeLegacyChart(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "legacy")
+	files := map[string]string{
+		"Chart.yaml":        "apiVersion: v1\nname: legacy\nversion: 0.1.0\n",
+		"requirements.yaml": "dependencies:\n- name: db\n  version: 1.0.0\n  repository: https://example.com/charts\n  condition: db.enabled\n",
+		"requirements.lock": "dependencies:\n- name: db\n  version: 1.0.0\n  repository: https://example.com/charts\ndigest: sha256:abc\ngenerated: \"2019-01-01T00:00:00Z\"\n",
+		"templates/crds/crontab.yaml": `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.example.com
+  annotations:
+    helm.sh/hook: crd-install
+    helm.sh/hook-delete-policy: before-hook-creation
+    example.com/owner: ops
+spec:
+  group: example.com
+`,
+		"templates/templated-crd.yaml": "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: {{ .Values.name }}\n  annotations:\n    helm.sh/hook: crd-install\n",
+		"templates/configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\ndata:\n  installed: {{ .Release.Time }}\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMigrate(t *testing.T) {
+	dir := writeLegacyChart(t)
+
+	expected := &Migration{
+		Changes: []string{
+			"Chart.yaml: changed apiVersion to v2",
+			"requirements.yaml: moved 1 dependencies to Chart.yaml",
+			"requirements.lock: renamed to Chart.lock",
+			"templates/crds/crontab.yaml: moved the crd-install hook to crds/crds/crontab.yaml",
+		},
+		Manual: []string{
+			"templates/configmap.yaml: .Release.Time was removed in Helm 3",
+			"templates/templated-crd.yaml: uses the crd-install hook, but templated CRDs have to be moved to crds/ by hand",
+		},
+	}
+
+	m, err := Migrate(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected %v, got %v", expected, m)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "requirements.yaml")); err != nil {
+		t.Errorf("Expected a dry run to leave the chart unchanged: %s", err)
+	}
+
+	m, err = Migrate(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected %v, got %v", expected, m)
+	}
+
+	c, err := loader.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Metadata.APIVersion != chart.APIVersionV2 || len(c.Metadata.Dependencies) != 1 || c.Metadata.Dependencies[0].Condition != "db.enabled" {
+		t.Errorf("Unexpected metadata %+v", c.Metadata)
+	}
+	if c.Lock == nil || c.Lock.Digest != "sha256:abc" {
+		t.Errorf("Expected the lock to be moved to Chart.lock, got %+v", c.Lock)
+	}
+	for _, name := range []string{"requirements.yaml", "requirements.lock", "templates/crds/crontab.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", name)
+		}
+	}
+	crds := c.CRDObjects()
+	if len(crds) != 1 || crds[0].Name != "crds/crds/crontab.yaml" {
+		t.Fatalf("Expected the CRD to be moved to crds/, got %v", crds)
+	}
+	crd := string(crds[0].File.Data)
+	if strings.Contains(crd, "helm.sh/hook") || !strings.Contains(crd, "example.com/owner: ops") || !strings.Contains(crd, "name: crontabs.example.com") {
+		t.Errorf("Expected the hook annotations to be removed, got:\n%s", crd)
+	}
+
+	// Migrating again only lists what is left to migrate by hand.
+	m, err = Migrate(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Changes) != 0 || !reflect.DeepEqual(m.Manual, expected.Manual) {
+		t.Errorf("Unexpected migration %v", m)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	dir := writeLegacyChart(t)
+	if err := os.WriteFile(filepath.Join(dir, "Chart.lock"), []byte("dependencies: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(dir, false); err == nil || !strings.Contains(err.Error(), "both requirements.lock and Chart.lock exist") {
+		t.Errorf("Expected a lock conflict, got %v", err)
+	}
+
+	dir = writeLegacyChart(t)
+	if err := os.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte("apiVersion: v2\nname: legacy\nversion: 0.1.0\ndependencies:\n- name: cache\n  version: 1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Migrate(dir, false); err == nil || !strings.Contains(err.Error(), "dependencies are declared in both") {
+		t.Errorf("Expected a dependencies conflict, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "requirements.yaml")); err != nil {
+		t.Errorf("Expected a failed migration to leave the chart unchanged: %s", err)
+	}
+
+	if _, err := Migrate(t.TempDir(), false); err == nil {
+		t.Error("Expected an error for a directory without a chart")
+	}
+}
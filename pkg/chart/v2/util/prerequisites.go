@@ -0,0 +1,149 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// Cluster describes what is known about a cluster when checking the
+// prerequisites of a chart.
+//
+// Facts left unset are unknown, and the prerequisites depending on them are
+// not checked.
+type Cluster struct {
+	// APIVersions are the API versions and API resources the cluster serves.
+	APIVersions common.VersionSet `json:"apiVersions,omitempty"`
+	// Nodes is the number of nodes in the cluster.
+	Nodes *int `json:"nodes,omitempty"`
+	// StorageClasses are the names of the StorageClasses in the cluster. A
+	// nil slice means they are unknown, an empty one that there are none.
+	StorageClasses []string `json:"storageClasses,omitempty"`
+}
+
+// LoadCluster reads a capabilities file describing a cluster.
+//
+// The file is YAML with the same keys as Cluster, for example:
+//
+//	apiVersions:
+//	  - monitoring.coreos.com/v1
+//	  - monitoring.coreos.com/v1/ServiceMonitor
+//	nodes: 3
+//	storageClasses:
+//	  - standard
+func LoadCluster(filename string) (*Cluster, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cluster := new(Cluster)
+	if err := yaml.UnmarshalStrict(b, cluster); err != nil {
+		return nil, fmt.Errorf("invalid capabilities file %s: %w", filename, err)
+	}
+	return cluster, nil
+}
+
+// CheckPrerequisites checks the prerequisites declared by a chart against the
+// cluster. It returns an error for each prerequisite the cluster does not
+// meet, each naming the chart by name.
+func CheckPrerequisites(name string, p *chart.Prerequisites, cluster Cluster) []error {
+	if p == nil {
+		return nil
+	}
+	var errs []error
+	if cluster.APIVersions != nil {
+		for _, v := range p.APIVersions {
+			if cluster.APIVersions.Has(v) {
+				continue
+			}
+			what := "API version"
+			if isAPIResource(v) {
+				what = "API resource"
+			}
+			errs = append(errs, fmt.Errorf("chart %q requires %s %q, which the cluster does not serve; install the CRDs or operator providing it first", name, what, v))
+		}
+	}
+	if cluster.Nodes != nil && *cluster.Nodes < p.MinNodes {
+		errs = append(errs, fmt.Errorf("chart %q requires at least %d node(s), but the cluster has %d", name, p.MinNodes, *cluster.Nodes))
+	}
+	if cluster.StorageClasses != nil {
+		for _, sc := range p.StorageClasses {
+			if !slices.Contains(cluster.StorageClasses, sc) {
+				errs = append(errs, fmt.Errorf("chart %q requires StorageClass %q, which does not exist in the cluster", name, sc))
+			}
+		}
+	}
+	return errs
+}
+
+// CheckChartPrerequisites checks the prerequisites declared by the chart and
+// its dependencies against the cluster, returning an error listing every
+// prerequisite the cluster does not meet.
+func CheckChartPrerequisites(chrt *chart.Chart, cluster Cluster) error {
+	var errs []error
+	walkPrerequisites(chrt, func(ch *chart.Chart) {
+		errs = append(errs, CheckPrerequisites(ch.ChartFullPath(), ch.Metadata.Requires, cluster)...)
+	})
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("cluster does not meet the prerequisites of the chart:\n%w", errors.Join(errs...))
+}
+
+// ChartPrerequisites returns the prerequisites declared by the chart and its
+// dependencies combined, or nil if none declare any.
+func ChartPrerequisites(chrt *chart.Chart) *chart.Prerequisites {
+	var all *chart.Prerequisites
+	walkPrerequisites(chrt, func(ch *chart.Chart) {
+		p := ch.Metadata.Requires
+		if all == nil {
+			all = &chart.Prerequisites{}
+		}
+		all.APIVersions = append(all.APIVersions, p.APIVersions...)
+		all.MinNodes = max(all.MinNodes, p.MinNodes)
+		all.StorageClasses = append(all.StorageClasses, p.StorageClasses...)
+	})
+	return all
+}
+
+// isAPIResource reports whether v names an API resource, such as "v1/Pod",
+// rather than an API version. Kinds start with an upper case letter.
+func isAPIResource(v string) bool {
+	i := strings.LastIndex(v, "/")
+	return i >= 0 && i+1 < len(v) && unicode.IsUpper(rune(v[i+1]))
+}
+
+// walkPrerequisites calls fn for the chart and each of its dependencies that
+// declares prerequisites.
+func walkPrerequisites(chrt *chart.Chart, fn func(*chart.Chart)) {
+	if chrt.Metadata != nil && chrt.Metadata.Requires != nil {
+		fn(chrt)
+	}
+	for _, dep := range chrt.Dependencies() {
+		walkPrerequisites(dep, fn)
+	}
+}
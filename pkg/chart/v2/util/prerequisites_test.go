@@ -0,0 +1,102 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestCheckChartPrerequisites(t *testing.T) {
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: "0.1.0", Requires: &chart.Prerequisites{
+			APIVersions:    []string{"monitoring.coreos.com/v1/ServiceMonitor", "apps/v1"},
+			StorageClasses: []string{"fast"},
+		}},
+	}
+	parent.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "db", Version: "0.1.0", Requires: &chart.Prerequisites{
+			APIVersions: []string{"postgresql.cnpg.io/v1"},
+			MinNodes:    3,
+		}},
+	})
+
+	nodes := 1
+	cluster := Cluster{
+		APIVersions:    common.VersionSet{"v1", "apps/v1"},
+		Nodes:          &nodes,
+		StorageClasses: []string{"standard"},
+	}
+	err := CheckChartPrerequisites(parent, cluster)
+	if err == nil {
+		t.Fatal("expected the prerequisites to fail")
+	}
+	want := `cluster does not meet the prerequisites of the chart:
+chart "app" requires API resource "monitoring.coreos.com/v1/ServiceMonitor", which the cluster does not serve; install the CRDs or operator providing it first
+chart "app" requires StorageClass "fast", which does not exist in the cluster
+chart "app/charts/db" requires API version "postgresql.cnpg.io/v1", which the cluster does not serve; install the CRDs or operator providing it first
+chart "app/charts/db" requires at least 3 node(s), but the cluster has 1`
+	if err.Error() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, err)
+	}
+
+	// Unknown facts are not checked.
+	if err := CheckChartPrerequisites(parent, Cluster{APIVersions: common.VersionSet{"apps/v1", "postgresql.cnpg.io/v1", "monitoring.coreos.com/v1/ServiceMonitor"}}); err != nil {
+		t.Errorf("expected unknown node count and StorageClasses to be skipped, got %v", err)
+	}
+
+	got := ChartPrerequisites(parent)
+	wantAll := &chart.Prerequisites{
+		APIVersions:    []string{"monitoring.coreos.com/v1/ServiceMonitor", "apps/v1", "postgresql.cnpg.io/v1"},
+		MinNodes:       3,
+		StorageClasses: []string{"fast"},
+	}
+	if !reflect.DeepEqual(got, wantAll) {
+		t.Errorf("expected %+v, got %+v", wantAll, got)
+	}
+	if got := ChartPrerequisites(&chart.Chart{Metadata: &chart.Metadata{Name: "plain"}}); got != nil {
+		t.Errorf("expected no prerequisites, got %+v", got)
+	}
+}
+
+func TestLoadCluster(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "capabilities.yaml")
+	if err := os.WriteFile(filename, []byte("apiVersions: [v1, apps/v1]\nnodes: 2\nstorageClasses: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cluster, err := LoadCluster(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cluster.APIVersions.Has("apps/v1") || cluster.Nodes == nil || *cluster.Nodes != 2 || cluster.StorageClasses == nil {
+		t.Errorf("unexpected cluster %+v", cluster)
+	}
+
+	if err := os.WriteFile(filename, []byte("node: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCluster(filename); err == nil || !strings.Contains(err.Error(), "invalid capabilities file") {
+		t.Errorf("expected an error for an unknown key, got %v", err)
+	}
+}
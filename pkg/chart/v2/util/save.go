@@ -22,11 +22,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"sigs.k8s.io/yaml"
 
 	"helm.sh/helm/v4/pkg/chart/common"
@@ -35,6 +39,63 @@ import (
 
 var headerBytes = []byte("+aHR0cHM6Ly95b3V0dS5iZS96OVV6MWljandyTQo=")
 
+// SaveOption configures how Save writes a chart archive.
+type SaveOption func(*saveOptions)
+
+type saveOptions struct {
+	reproducible bool
+	modTime      time.Time
+	format       ArchiveFormat
+}
+
+// ArchiveFormat is the format of a chart archive written by Save.
+type ArchiveFormat string
+
+const (
+	// ArchiveGzip writes a gzip compressed tar archive with the .tgz extension.
+	ArchiveGzip ArchiveFormat = "gzip"
+	// ArchiveZstd writes a zstd compressed tar archive with the .tar.zst
+	// extension. It packs and unpacks large charts faster than gzip.
+	ArchiveZstd ArchiveFormat = "zstd"
+	// ArchiveTar writes an uncompressed tar archive with the .tar extension.
+	ArchiveTar ArchiveFormat = "tar"
+)
+
+// ArchiveFormats are the formats Save can write.
+var ArchiveFormats = []ArchiveFormat{ArchiveGzip, ArchiveZstd, ArchiveTar}
+
+// Extension returns the file extension of archives of the format.
+func (f ArchiveFormat) Extension() string {
+	switch f {
+	case ArchiveZstd:
+		return ".tar.zst"
+	case ArchiveTar:
+		return ".tar"
+	default:
+		return ".tgz"
+	}
+}
+
+// WithReproducible makes Save write byte-identical archives for identical
+// charts: files are written in a stable order with normalized headers, and
+// their modification time is modTime instead of the current time.
+//
+// The gzip header of an archive never records a modification time.
+func WithReproducible(modTime time.Time) SaveOption {
+	return func(o *saveOptions) {
+		o.reproducible = true
+		o.modTime = modTime
+	}
+}
+
+// WithFormat makes Save write an archive of the given format instead of a
+// gzip compressed one.
+func WithFormat(format ArchiveFormat) SaveOption {
+	return func(o *saveOptions) {
+		o.format = format
+	}
+}
+
 // SaveDir saves a chart as files in a directory.
 //
 // This takes the chart name, and creates a new subdirectory inside of the given dest
@@ -102,15 +163,24 @@ func SaveDir(c *chart.Chart, dest string) error {
 // This takes an existing chart and a destination directory.
 //
 // If the directory is /foo, and the chart is named bar, with version 1.0.0, this
-// will generate /foo/bar-1.0.0.tgz.
+// will generate /foo/bar-1.0.0.tgz, or /foo/bar-1.0.0.tar.zst and
+// /foo/bar-1.0.0.tar for the zstd and tar formats.
 //
 // This returns the absolute path to the chart archive file.
-func Save(c *chart.Chart, outDir string) (string, error) {
+func Save(c *chart.Chart, outDir string, options ...SaveOption) (string, error) {
+	o := saveOptions{format: ArchiveGzip}
+	for _, option := range options {
+		option(&o)
+	}
+	if !slices.Contains(ArchiveFormats, o.format) {
+		return "", fmt.Errorf("unknown chart archive format %q", o.format)
+	}
+
 	if err := c.Validate(); err != nil {
 		return "", fmt.Errorf("chart validation: %w", err)
 	}
 
-	filename := fmt.Sprintf("%s-%s.tgz", c.Name(), c.Metadata.Version)
+	filename := fmt.Sprintf("%s-%s%s", c.Name(), c.Metadata.Version, o.format.Extension())
 	filename = filepath.Join(outDir, filename)
 	dir := filepath.Dir(filename)
 	if stat, err := os.Stat(dir); err != nil {
@@ -130,31 +200,54 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 		return "", err
 	}
 
-	// Wrap in gzip writer
-	zipper := gzip.NewWriter(f)
-	zipper.Extra = headerBytes
-	zipper.Comment = "Helm"
+	compressor, err := newCompressor(f, o.format)
+	if err != nil {
+		f.Close()
+		os.Remove(filename)
+		return "", err
+	}
 
 	// Wrap in tar writer
-	twriter := tar.NewWriter(zipper)
+	twriter := tar.NewWriter(compressor)
 	rollback := false
 	defer func() {
 		twriter.Close()
-		zipper.Close()
+		compressor.Close()
 		f.Close()
 		if rollback {
 			os.Remove(filename)
 		}
 	}()
 
-	if err := writeTarContents(twriter, c, ""); err != nil {
+	if err := writeTarContents(twriter, c, "", &o); err != nil {
 		rollback = true
 		return filename, err
 	}
 	return filename, nil
 }
 
-func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
+// newCompressor wraps w in the compression of the archive format.
+func newCompressor(w io.Writer, format ArchiveFormat) (io.WriteCloser, error) {
+	switch format {
+	case ArchiveZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	case ArchiveTar:
+		return nopWriteCloser{w}, nil
+	default:
+		zipper := gzip.NewWriter(w)
+		zipper.Extra = headerBytes
+		zipper.Comment = "Helm"
+		return zipper, nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string, o *saveOptions) error {
 	err := validateName(c.Name())
 	if err != nil {
 		return err
@@ -175,7 +268,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	if err != nil {
 		return err
 	}
-	if err := writeToTar(out, filepath.Join(base, ChartfileName), cdata); err != nil {
+	if err := writeToTar(out, filepath.Join(base, ChartfileName), cdata, o); err != nil {
 		return err
 	}
 
@@ -187,7 +280,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 			if err != nil {
 				return err
 			}
-			if err := writeToTar(out, filepath.Join(base, "Chart.lock"), ldata); err != nil {
+			if err := writeToTar(out, filepath.Join(base, "Chart.lock"), ldata, o); err != nil {
 				return err
 			}
 		}
@@ -196,7 +289,7 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 	// Save values.yaml
 	for _, f := range c.Raw {
 		if f.Name == ValuesfileName {
-			if err := writeToTar(out, filepath.Join(base, ValuesfileName), f.Data); err != nil {
+			if err := writeToTar(out, filepath.Join(base, ValuesfileName), f.Data, o); err != nil {
 				return err
 			}
 		}
@@ -207,38 +300,53 @@ func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
 		if !json.Valid(c.Schema) {
 			return errors.New("invalid JSON in " + SchemafileName)
 		}
-		if err := writeToTar(out, filepath.Join(base, SchemafileName), c.Schema); err != nil {
+		if err := writeToTar(out, filepath.Join(base, SchemafileName), c.Schema, o); err != nil {
 			return err
 		}
 	}
 
+	templates, files, deps := c.Templates, c.Files, c.Dependencies()
+	if o.reproducible {
+		templates, files = sortedFiles(templates), sortedFiles(files)
+		deps = slices.SortedStableFunc(slices.Values(deps), func(a, b *chart.Chart) int {
+			return strings.Compare(a.Name(), b.Name())
+		})
+	}
+
 	// Save templates
-	for _, f := range c.Templates {
+	for _, f := range templates {
 		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data); err != nil {
+		if err := writeToTar(out, n, f.Data, o); err != nil {
 			return err
 		}
 	}
 
 	// Save files
-	for _, f := range c.Files {
+	for _, f := range files {
 		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data); err != nil {
+		if err := writeToTar(out, n, f.Data, o); err != nil {
 			return err
 		}
 	}
 
 	// Save dependencies
-	for _, dep := range c.Dependencies() {
-		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir)); err != nil {
+	for _, dep := range deps {
+		if err := writeTarContents(out, dep, filepath.Join(base, ChartsDir), o); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// sortedFiles returns a copy of files sorted by name.
+func sortedFiles(files []*common.File) []*common.File {
+	return slices.SortedStableFunc(slices.Values(files), func(a, b *common.File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
 // writeToTar writes a single file to a tar archive.
-func writeToTar(out *tar.Writer, name string, body []byte) error {
+func writeToTar(out *tar.Writer, name string, body []byte, o *saveOptions) error {
 	// TODO: Do we need to create dummy parent directory names if none exist?
 	h := &tar.Header{
 		Name:    filepath.ToSlash(name),
@@ -246,6 +354,10 @@ func writeToTar(out *tar.Writer, name string, body []byte) error {
 		Size:    int64(len(body)),
 		ModTime: time.Now(),
 	}
+	if o.reproducible {
+		h.Typeflag = tar.TypeReg
+		h.ModTime = o.modTime.UTC().Truncate(time.Second)
+	}
 	if err := out.WriteHeader(h); err != nil {
 		return err
 	}
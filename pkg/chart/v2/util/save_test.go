@@ -180,6 +180,127 @@ func TestSavePreservesTimestamps(t *testing.T) {
 	}
 }
 
+func TestSaveReproducible(t *testing.T) {
+	newChart := func(reversed bool) *chart.Chart {
+		files := []*common.File{
+			{Name: "a.txt", Data: []byte("a")},
+			{Name: "b/c.txt", Data: []byte("c")},
+		}
+		deps := []string{"alpha", "beta"}
+		if reversed {
+			files[0], files[1] = files[1], files[0]
+			deps[0], deps[1] = deps[1], deps[0]
+		}
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ahab", Version: "1.2.3"},
+			Files:    files,
+			Templates: []*common.File{
+				{Name: "templates/a.yaml", Data: []byte("kind: A")},
+			},
+		}
+		for _, name := range deps {
+			c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: "0.1.0"}})
+		}
+		return c
+	}
+	modTime := time.Date(2024, time.March, 1, 12, 0, 0, 500, time.UTC)
+
+	var archives [][]byte
+	for _, reversed := range []bool{false, true} {
+		where, err := Save(newChart(reversed), t.TempDir(), WithReproducible(modTime))
+		if err != nil {
+			t.Fatalf("Failed to save: %s", err)
+		}
+		data, err := os.ReadFile(where)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archives = append(archives, data)
+
+		headers, err := retrieveAllHeadersFromTar(where)
+		if err != nil {
+			t.Fatalf("Failed to parse tar: %v", err)
+		}
+		var names []string
+		for _, h := range headers {
+			names = append(names, h.Name)
+			if !h.ModTime.Equal(modTime.Truncate(time.Second)) || h.Mode != 0644 || h.Uid != 0 || h.Gid != 0 {
+				t.Errorf("Unexpected header %+v", h)
+			}
+		}
+		expected := "ahab/Chart.yaml,ahab/templates/a.yaml,ahab/a.txt,ahab/b/c.txt,ahab/charts/alpha/Chart.yaml,ahab/charts/beta/Chart.yaml"
+		if got := strings.Join(names, ","); got != expected {
+			t.Errorf("Expected files %s, got %s", expected, got)
+		}
+	}
+	if !bytes.Equal(archives[0], archives[1]) {
+		t.Error("Expected identical charts to be saved to identical archives")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(archives[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !zr.ModTime.IsZero() {
+		t.Errorf("Expected no modification time in the gzip header, got %s", zr.ModTime)
+	}
+}
+
+func TestSaveFormats(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ahab", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/a.yaml", Data: []byte("kind: A")},
+		},
+		Files: []*common.File{
+			{Name: "scheherazade/shahryar.txt", Data: []byte("1,001 Nights")},
+		},
+	}
+
+	for _, tt := range []struct {
+		format ArchiveFormat
+		ext    string
+	}{
+		{ArchiveGzip, ".tgz"},
+		{ArchiveZstd, ".tar.zst"},
+		{ArchiveTar, ".tar"},
+	} {
+		t.Run(string(tt.format), func(t *testing.T) {
+			where, err := Save(c, t.TempDir(), WithFormat(tt.format))
+			if err != nil {
+				t.Fatalf("Failed to save: %s", err)
+			}
+			if filepath.Base(where) != "ahab-1.2.3"+tt.ext {
+				t.Fatalf("Expected archive ahab-1.2.3%s, got %s", tt.ext, where)
+			}
+
+			c2, err := loader.LoadFile(where)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(c2.Templates) != 1 || len(c2.Files) != 1 || string(c2.Files[0].Data) != "1,001 Nights" {
+				t.Fatalf("Chart loaded from %s did not match", where)
+			}
+
+			lc, err := loader.LoadLazy(where)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := lc.ReadFile("scheherazade/shahryar.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "1,001 Nights" {
+				t.Fatalf("Expected file read lazily from %s to match, got %q", where, data)
+			}
+		})
+	}
+
+	if _, err := Save(c, t.TempDir(), WithFormat("rar")); err == nil {
+		t.Error("Expected an error for an unknown archive format")
+	}
+}
+
 // We could refactor `load.go` to use this `retrieveAllHeadersFromTar` function
 // as well, so we are not duplicating components of the code which iterate
 // through the tar.
@@ -0,0 +1,212 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// SchemaDraft is the JSON Schema dialect of the schemas generated by GenerateSchema.
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// descriptionMarker starts the comment describing a value, as in
+//
+//	# -- The number of replicas.
+//	replicaCount: 1
+const descriptionMarker = "--"
+
+// GenerateSchema infers a JSON Schema from the contents of a values.yaml
+// file, to be used as a starting point for values.schema.json.
+//
+// Every value is typed after its default, which is also the default of the
+// schema for scalars. The items of a list are typed after its first item.
+// Values preceded or followed on the same line by a comment starting with
+// "# --" are described by the rest of that comment, and by the comment lines
+// following it.
+//
+// The schema does not require any value nor forbid additional ones.
+func GenerateSchema(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse values: %w", err)
+	}
+
+	schema := map[string]interface{}{"type": "object"}
+	if len(doc.Content) > 0 {
+		root := resolveAlias(doc.Content[0])
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("values must be a map, got %s", nodeKind(root))
+		}
+		var err error
+		if schema, err = nodeSchema(root); err != nil {
+			return nil, err
+		}
+	}
+	schema["$schema"] = SchemaDraft
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+func nodeSchema(n *yaml.Node) (map[string]interface{}, error) {
+	n = resolveAlias(n)
+	schema := map[string]interface{}{}
+	switch n.Kind {
+	case yaml.MappingNode:
+		schema["type"] = "object"
+		props := map[string]interface{}{}
+		if err := mappingProperties(n, props); err != nil {
+			return nil, err
+		}
+		if len(props) > 0 {
+			schema["properties"] = props
+		} else {
+			schema["default"] = map[string]interface{}{}
+		}
+	case yaml.SequenceNode:
+		schema["type"] = "array"
+		if len(n.Content) > 0 {
+			items, err := nodeSchema(n.Content[0])
+			if err != nil {
+				return nil, err
+			}
+			schema["items"] = withoutDefaults(items)
+		} else {
+			schema["default"] = []interface{}{}
+		}
+	case yaml.ScalarNode:
+		var def interface{}
+		if err := n.Decode(&def); err != nil {
+			return nil, err
+		}
+		switch n.ShortTag() {
+		case "!!null":
+			return schema, nil
+		case "!!bool":
+			schema["type"] = "boolean"
+		case "!!int":
+			schema["type"] = "integer"
+		case "!!float":
+			schema["type"] = "number"
+		default:
+			schema["type"] = "string"
+			def = n.Value
+		}
+		schema["default"] = def
+	}
+	return schema, nil
+}
+
+// mappingProperties adds the schemas of the values of the mapping to props,
+// following merge keys.
+func mappingProperties(n *yaml.Node, props map[string]interface{}) error {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.ShortTag() == "!!merge" {
+			merged := resolveAlias(value)
+			sources := []*yaml.Node{merged}
+			if merged.Kind == yaml.SequenceNode {
+				sources = merged.Content
+			}
+			for _, s := range sources {
+				if err := mappingProperties(resolveAlias(s), props); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		prop, err := nodeSchema(value)
+		if err != nil {
+			return err
+		}
+		if desc := description(key.HeadComment, key.LineComment, value.LineComment); desc != "" {
+			prop["description"] = desc
+		}
+		props[key.Value] = prop
+	}
+	return nil
+}
+
+// description returns the description in the first of the comments with one.
+func description(comments ...string) string {
+	for _, c := range comments {
+		lines := strings.Split(c, "\n")
+		start := -1
+		for i, l := range lines {
+			if strings.HasPrefix(commentText(l), descriptionMarker) {
+				start = i
+			}
+		}
+		if start < 0 {
+			continue
+		}
+
+		text := []string{strings.TrimSpace(strings.TrimPrefix(commentText(lines[start]), descriptionMarker))}
+		for _, l := range lines[start+1:] {
+			if t := commentText(l); t != "" {
+				text = append(text, t)
+			}
+		}
+		return strings.TrimSpace(strings.Join(text, " "))
+	}
+	return ""
+}
+
+func commentText(line string) string {
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+}
+
+// withoutDefaults removes the defaults of the schema and of its properties,
+// which are those of a single item when it describes the items of a list.
+func withoutDefaults(schema map[string]interface{}) map[string]interface{} {
+	delete(schema, "default")
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		for _, p := range props {
+			withoutDefaults(p.(map[string]interface{}))
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		withoutDefaults(items)
+	}
+	return schema
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+func nodeKind(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.ScalarNode:
+		return "a scalar"
+	default:
+		return "an unknown node"
+	}
+}
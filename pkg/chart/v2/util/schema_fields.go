@@ -0,0 +1,247 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValuesField describes a value of a chart, as found in its values schema
+// and its default values.
+type ValuesField struct {
+	// Path is the dotted path of the value, such as "image.tag". The items
+	// of a list are designated by "[]", as in "ports[].name".
+	Path string `json:"path"`
+	// Type is the list of the JSON Schema types of the value.
+	Type []string `json:"type,omitempty"`
+	// Enum is the list of the allowed values, if restricted.
+	Enum []interface{} `json:"enum,omitempty"`
+	// Default is the default of the schema, or the default value of the
+	// chart.
+	Default interface{} `json:"default,omitempty"`
+	// Description is the description of the schema.
+	Description string `json:"description,omitempty"`
+	// Required is true if the schema requires the value.
+	Required bool `json:"required,omitempty"`
+}
+
+// maxSchemaDepth bounds the nesting of the schemas followed by ValuesFields,
+// as references may be recursive.
+const maxSchemaDepth = 32
+
+// ValuesFields flattens the values schema and the default values of a chart
+// into the list of the values of the chart, sorted by path. It is the
+// canonical description of the configuration of a chart for completions and
+// user interfaces.
+//
+// Either schema or values may be empty. The properties of the schema are
+// followed through local references, such as "#/definitions/port", and
+// allOf. Values only found in the default values are typed after them.
+func ValuesFields(schema []byte, values map[string]interface{}) ([]ValuesField, error) {
+	fields := make(map[string]*ValuesField)
+	field := func(path string) *ValuesField {
+		if fields[path] == nil {
+			fields[path] = &ValuesField{Path: path}
+		}
+		return fields[path]
+	}
+
+	if len(schema) > 0 {
+		var root map[string]interface{}
+		if err := json.Unmarshal(schema, &root); err != nil {
+			return nil, fmt.Errorf("failed to parse values schema: %w", err)
+		}
+		w := schemaWalker{root: root, field: field}
+		w.walk(root, "", 0)
+	}
+	valuesFields(values, "", field)
+
+	out := make([]ValuesField, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// schemaWalker records the fields of the schemas nested in root.
+type schemaWalker struct {
+	root  map[string]interface{}
+	field func(path string) *ValuesField
+}
+
+func (w *schemaWalker) walk(schema map[string]interface{}, path string, depth int) {
+	if depth > maxSchemaDepth {
+		return
+	}
+	schema = w.resolve(schema, depth)
+
+	if path != "" {
+		f := w.field(path)
+		if types := schemaTypes(schema); len(types) > 0 && len(f.Type) == 0 {
+			f.Type = types
+		}
+		if enum, ok := schema["enum"].([]interface{}); ok && f.Enum == nil {
+			f.Enum = enum
+		}
+		if def, ok := schema["default"]; ok && f.Default == nil {
+			f.Default = def
+		}
+		if desc, ok := schema["description"].(string); ok && f.Description == "" {
+			f.Description = desc
+		}
+	}
+
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, key := range list {
+			if key, ok := key.(string); ok {
+				required[key] = true
+			}
+		}
+	}
+	props, _ := schema["properties"].(map[string]interface{})
+	for key, prop := range props {
+		if prop, ok := prop.(map[string]interface{}); ok {
+			child := joinValuesPath(path, key)
+			w.walk(prop, child, depth+1)
+			if required[key] {
+				w.field(child).Required = true
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		w.walk(items, path+"[]", depth+1)
+	}
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if sub, ok := sub.(map[string]interface{}); ok {
+				w.walk(sub, path, depth+1)
+			}
+		}
+	}
+}
+
+// resolve follows the local references of schema, such as
+// "#/definitions/port" or "#/$defs/port". Other references are ignored.
+func (w *schemaWalker) resolve(schema map[string]interface{}, depth int) map[string]interface{} {
+	for ; depth <= maxSchemaDepth; depth++ {
+		ref, ok := schema["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return schema
+		}
+		var cur interface{} = w.root
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+			if key == "" {
+				continue
+			}
+			key = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+			m, _ := cur.(map[string]interface{})
+			cur = m[key]
+		}
+		target, ok := cur.(map[string]interface{})
+		if !ok {
+			return schema
+		}
+		// Keywords next to the reference take precedence.
+		merged := make(map[string]interface{}, len(target)+len(schema))
+		for k, v := range target {
+			merged[k] = v
+		}
+		for k, v := range schema {
+			if k != "$ref" {
+				merged[k] = v
+			}
+		}
+		schema = merged
+	}
+	return schema
+}
+
+// schemaTypes returns the types of schema, which may be a single type or a
+// list of types.
+func schemaTypes(schema map[string]interface{}) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			types = append(types, fmt.Sprint(v))
+		}
+		return types
+	}
+	return nil
+}
+
+// valuesFields records the fields of the default values v found at path,
+// completing the fields of the schema.
+func valuesFields(v interface{}, path string, field func(string) *ValuesField) {
+	if path != "" {
+		f := field(path)
+		if len(f.Type) == 0 {
+			if t := valueType(v); t != "" {
+				f.Type = []string{t}
+			}
+		}
+		switch v.(type) {
+		case map[string]interface{}, []interface{}, nil:
+		default:
+			if f.Default == nil {
+				f.Default = v
+			}
+		}
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			valuesFields(value, joinValuesPath(path, key), field)
+		}
+	case []interface{}:
+		for _, item := range v {
+			valuesFields(item, path+"[]", field)
+		}
+	}
+}
+
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	}
+	return ""
+}
+
+func joinValuesPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
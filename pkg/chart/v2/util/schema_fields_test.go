@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValuesFields(t *testing.T) {
+	schema := []byte(`{
+  "type": "object",
+  "required": ["image"],
+  "definitions": {
+    "port": {
+      "type": "object",
+      "properties": {
+        "name": {"type": "string"},
+        "protocol": {"type": "string", "enum": ["TCP", "UDP"], "default": "TCP"}
+      }
+    }
+  },
+  "properties": {
+    "image": {
+      "type": "object",
+      "required": ["repository"],
+      "properties": {
+        "repository": {"type": "string", "description": "The image repository."},
+        "pullPolicy": {"type": "string", "enum": ["Always", "IfNotPresent", "Never"]}
+      }
+    },
+    "ports": {"type": "array", "items": {"$ref": "#/definitions/port"}},
+    "replicas": {
+      "allOf": [{"type": ["integer", "null"]}, {"description": "The number of replicas."}]
+    }
+  }
+}`)
+	values := map[string]interface{}{
+		"image":    map[string]interface{}{"repository": "nginx", "tag": "1.25"},
+		"replicas": float64(2),
+		"debug":    false,
+	}
+
+	fields, err := ValuesFields(schema, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []ValuesField{
+		{Path: "debug", Type: []string{"boolean"}, Default: false},
+		{Path: "image", Type: []string{"object"}, Required: true},
+		{Path: "image.pullPolicy", Type: []string{"string"}, Enum: []interface{}{"Always", "IfNotPresent", "Never"}},
+		{Path: "image.repository", Type: []string{"string"}, Default: "nginx", Description: "The image repository.", Required: true},
+		{Path: "image.tag", Type: []string{"string"}, Default: "1.25"},
+		{Path: "ports", Type: []string{"array"}},
+		{Path: "ports[]", Type: []string{"object"}},
+		{Path: "ports[].name", Type: []string{"string"}},
+		{Path: "ports[].protocol", Type: []string{"string"}, Enum: []interface{}{"TCP", "UDP"}, Default: "TCP"},
+		{Path: "replicas", Type: []string{"integer", "null"}, Default: float64(2), Description: "The number of replicas."},
+	}
+	if !reflect.DeepEqual(expected, fields) {
+		t.Errorf("expected\n%+v\ngot\n%+v", expected, fields)
+	}
+
+	if _, err := ValuesFields([]byte("{"), values); err == nil {
+		t.Error("expected an error for an invalid schema")
+	}
+}
+
+func TestValuesFieldsRecursiveSchema(t *testing.T) {
+	schema := []byte(`{
+  "definitions": {"node": {"type": "object", "properties": {"child": {"$ref": "#/definitions/node"}}}},
+  "properties": {"tree": {"$ref": "#/definitions/node"}}
+}`)
+	fields, err := ValuesFields(schema, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) == 0 || len(fields) > maxSchemaDepth+1 {
+		t.Errorf("expected the recursion to be bounded, got %d fields", len(fields))
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	commonutil "helm.sh/helm/v4/pkg/chart/common/util"
+)
+
+const schemaTestValues = `# -- Number of replicas
+# to run.
+replicaCount: 1
+
+defaults: &defaults
+  timeout: 30
+
+image:
+  <<: *defaults
+  repository: nginx # -- The image repository.
+  # Some notes that are not a description.
+  pullPolicy: IfNotPresent
+  tag: ""
+
+ratio: 0.5
+enabled: true
+nodeName: ~
+ports:
+- name: http
+  port: 80
+tolerations: []
+annotations: {}
+`
+
+func TestGenerateSchema(t *testing.T) {
+	out, err := GenerateSchema([]byte(schemaTestValues))
+	require.NoError(t, err)
+
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "annotations": {"type": "object", "default": {}},
+    "defaults": {
+      "type": "object",
+      "properties": {"timeout": {"type": "integer", "default": 30}}
+    },
+    "enabled": {"type": "boolean", "default": true},
+    "image": {
+      "type": "object",
+      "properties": {
+        "pullPolicy": {"type": "string", "default": "IfNotPresent"},
+        "repository": {"type": "string", "default": "nginx", "description": "The image repository."},
+        "tag": {"type": "string", "default": ""},
+        "timeout": {"type": "integer", "default": 30}
+      }
+    },
+    "nodeName": {},
+    "ports": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {"name": {"type": "string"}, "port": {"type": "integer"}}
+      }
+    },
+    "ratio": {"type": "number", "default": 0.5},
+    "replicaCount": {"type": "integer", "default": 1, "description": "Number of replicas to run."},
+    "tolerations": {"type": "array", "default": []}
+  },
+  "type": "object"
+}`
+	assert.JSONEq(t, expected, string(out))
+
+	values, err := common.ReadValues([]byte(schemaTestValues))
+	require.NoError(t, err)
+	assert.NoError(t, commonutil.ValidateAgainstSingleSchema(values, out), "values should be valid against their own schema")
+}
+
+func TestGenerateSchemaEmpty(t *testing.T) {
+	out, err := GenerateSchema(nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"$schema": "http://json-schema.org/draft-07/schema#", "type": "object"}`, string(out))
+}
+
+func TestGenerateSchemaNotAMap(t *testing.T) {
+	_, err := GenerateSchema([]byte("- a\n- b\n"))
+	assert.EqualError(t, err, "values must be a map, got a list")
+
+	_, err = GenerateSchema([]byte("a: [\n"))
+	assert.ErrorContains(t, err, "failed to parse values")
+}
+
+func TestGenerateSchemaCreatedChart(t *testing.T) {
+	dir, err := Create("foo", t.TempDir())
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, ValuesfileName))
+	require.NoError(t, err)
+	values, err := common.ReadValues(data)
+	require.NoError(t, err)
+
+	out, err := GenerateSchema(data)
+	require.NoError(t, err)
+	require.True(t, json.Valid(out))
+	assert.NoError(t, commonutil.ValidateAgainstSingleSchema(values, out))
+}
@@ -0,0 +1,62 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/copystructure"
+
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/strvals"
+)
+
+// ApplyGlobValues sets the strvals lines of globs, whose keys may hold
+// wildcards such as "workers.*.replicas=2", over vals.
+//
+// The wildcards are expanded against the default values of the chart
+// coalesced with vals, so that they match the tables the release would be
+// rendered with. The lines are applied in order, each seeing the values set
+// by the ones before it. vals is not modified.
+func ApplyGlobValues(chrt *chart.Chart, vals map[string]interface{}, globs []string) (map[string]interface{}, error) {
+	if len(globs) == 0 {
+		return vals, nil
+	}
+
+	v, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, err
+	}
+	out, _ := v.(map[string]interface{})
+	if out == nil {
+		out = map[string]interface{}{}
+	}
+	existing, err := util.CoalesceValues(chrt, out)
+	if err != nil {
+		return nil, err
+	}
+	for _, glob := range globs {
+		if err := strvals.ParseGlobInto(glob, existing, out); err != nil {
+			return nil, fmt.Errorf("failed parsing glob values %q: %w", glob, err)
+		}
+		if err := strvals.ParseGlobInto(glob, existing, existing); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
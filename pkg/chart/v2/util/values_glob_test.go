@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestApplyGlobValues(t *testing.T) {
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "workers", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"workers": map[string]interface{}{
+				"api":   map[string]interface{}{"replicas": 1},
+				"batch": map[string]interface{}{"replicas": 1},
+			},
+		},
+	}
+	vals := map[string]interface{}{
+		"workers": map[string]interface{}{"cron": map[string]interface{}{"schedule": "@daily"}},
+	}
+
+	got, err := ApplyGlobValues(chrt, vals, []string{"workers.*.cpu=500m", "workers.c*.cpu=1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"workers": map[string]interface{}{
+			"api":   map[string]interface{}{"cpu": "500m"},
+			"batch": map[string]interface{}{"cpu": "500m"},
+			"cron":  map[string]interface{}{"cpu": int64(1), "schedule": "@daily"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if _, ok := vals["workers"].(map[string]interface{})["api"]; ok {
+		t.Errorf("expected the supplied values to be left alone, got %v", vals)
+	}
+
+	if got, _ := ApplyGlobValues(chrt, vals, nil); !reflect.DeepEqual(got, vals) {
+		t.Errorf("expected the values to be returned as is without globs, got %v", got)
+	}
+	if _, err := ApplyGlobValues(chrt, vals, []string{"workers.*"}); err == nil {
+		t.Error("expected an error for a glob without a value")
+	}
+}
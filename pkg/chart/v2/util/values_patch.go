@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"sigs.k8s.io/yaml"
+)
+
+// ApplyValuesPatches applies RFC 6902 JSON Patch documents, in order, to
+// values.
+//
+// The patches are given as JSON, or as the equivalent YAML. Their paths are
+// JSON Pointers into the values, such as "/resources/limits", allowing to
+// remove a key or to insert an item in a list, which --set cannot do. The
+// values are meant to be coalesced with those of the chart already, so that
+// the paths of the patches can point at the defaults of the chart.
+//
+// Numbers in the patched values are float64, as in values read from files.
+// vals is not modified.
+func ApplyValuesPatches(vals map[string]interface{}, patches [][]byte) (map[string]interface{}, error) {
+	if len(patches) == 0 {
+		return vals, nil
+	}
+
+	doc, err := json.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	for i, data := range patches {
+		data, err = yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse values patch %d: %w", i+1, err)
+		}
+		patch, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse values patch %d: %w", i+1, err)
+		}
+		if doc, err = patch.Apply(doc); err != nil {
+			return nil, fmt.Errorf("cannot apply values patch %d: %w", i+1, err)
+		}
+	}
+
+	var patched map[string]interface{}
+	if err := json.Unmarshal(doc, &patched); err != nil {
+		return nil, fmt.Errorf("values patches must leave a map of values: %w", err)
+	}
+	if patched == nil {
+		patched = map[string]interface{}{}
+	}
+	return patched, nil
+}
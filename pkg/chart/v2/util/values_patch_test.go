@@ -0,0 +1,64 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyValuesPatches(t *testing.T) {
+	vals := map[string]interface{}{
+		"replicas":  1,
+		"args":      []interface{}{"--quiet"},
+		"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "1"}, "requests": map[string]interface{}{"cpu": "100m"}},
+	}
+	patches := [][]byte{
+		[]byte(`[{"op": "remove", "path": "/resources/limits"}, {"op": "add", "path": "/args/-", "value": "--verbose"}]`),
+		[]byte("- op: replace\n  path: /replicas\n  value: 3\n- op: copy\n  from: /resources/requests\n  path: /resources/limits\n"),
+	}
+
+	got, err := ApplyValuesPatches(vals, patches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"replicas":  float64(3),
+		"args":      []interface{}{"--quiet", "--verbose"},
+		"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}, "requests": map[string]interface{}{"cpu": "100m"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if _, ok := vals["resources"].(map[string]interface{})["limits"].(map[string]interface{})["cpu"]; !ok || vals["replicas"] != 1 {
+		t.Errorf("expected the values to be left alone, got %v", vals)
+	}
+
+	if got, _ := ApplyValuesPatches(vals, nil); !reflect.DeepEqual(got, vals) {
+		t.Errorf("expected the values to be returned as is without patches, got %v", got)
+	}
+
+	for _, patch := range []string{
+		`{"op": "remove", "path": "/replicas"}`,
+		`[{"op": "remove", "path": "/missing"}]`,
+		`[{"op": "replace", "path": "", "value": [1]}]`,
+	} {
+		if _, err := ApplyValuesPatches(vals, [][]byte{[]byte(patch)}); err == nil {
+			t.Errorf("expected an error for patch %s", patch)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/copystructure"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// ValuesProfilesDir is the directory of a chart holding its values profiles.
+//
+// A values profile is a named set of values, such as values/production.yaml,
+// layered over the default values of the chart when selected. A profile may
+// come with a schema, such as values/production.schema.json, which the values
+// are validated against when the profile is used.
+const ValuesProfilesDir = "values"
+
+// ValuesProfiles returns the names of the values profiles of a chart, sorted.
+func ValuesProfiles(chrt *chart.Chart) []string {
+	var names []string
+	for _, f := range chrt.Files {
+		if name, ok := valuesProfileName(f.Name); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func valuesProfileName(file string) (string, bool) {
+	dir, base := path.Split(file)
+	if dir != ValuesProfilesDir+"/" {
+		return "", false
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		if name, ok := strings.CutSuffix(base, ext); ok && name != "" && !strings.HasSuffix(name, ".schema") {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func valuesProfileFile(chrt *chart.Chart, name string) *common.File {
+	for _, f := range chrt.Files {
+		if n, ok := valuesProfileName(f.Name); ok && n == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func valuesProfileSchema(chrt *chart.Chart, name string) []byte {
+	for _, f := range chrt.Files {
+		if f.Name == path.Join(ValuesProfilesDir, name+".schema.json") {
+			return f.Data
+		}
+	}
+	return nil
+}
+
+// ApplyValuesProfiles layers the named values profiles of a chart under vals.
+//
+// Profiles are merged in the order given, each overriding the ones before it,
+// and vals overrides them all. The default values of the chart are left to be
+// coalesced under the result when rendering, as for any user-supplied values.
+// vals is not modified.
+func ApplyValuesProfiles(chrt *chart.Chart, vals map[string]interface{}, profiles []string) (map[string]interface{}, error) {
+	if len(profiles) == 0 {
+		return vals, nil
+	}
+
+	layered := map[string]interface{}{}
+	for _, name := range profiles {
+		f := valuesProfileFile(chrt, name)
+		if f == nil {
+			available := ValuesProfiles(chrt)
+			if len(available) == 0 {
+				return nil, fmt.Errorf("values profile %q not found: chart %s has no %s directory", name, chrt.Name(), ValuesProfilesDir)
+			}
+			return nil, fmt.Errorf("values profile %q not found in chart %s, available profiles: %s", name, chrt.Name(), strings.Join(available, ", "))
+		}
+		profileVals, err := common.ReadValues(f.Data)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load values profile %q: %w", name, err)
+		}
+		layered = util.MergeTables(profileVals, layered)
+	}
+
+	v, err := copystructure.Copy(vals)
+	if err != nil {
+		return nil, err
+	}
+	userVals, _ := v.(map[string]interface{})
+	if userVals == nil {
+		userVals = map[string]interface{}{}
+	}
+	return util.MergeTables(userVals, layered), nil
+}
+
+// ValidateValuesProfiles validates values against the schemas of the named
+// values profiles of a chart. Profiles without a schema are skipped.
+//
+// vals are the values supplied for a release, with the profiles applied; they
+// are coalesced with the default values of the chart before validation.
+func ValidateValuesProfiles(chrt *chart.Chart, vals map[string]interface{}, profiles []string) error {
+	var coalesced common.Values
+	for _, name := range profiles {
+		schema := valuesProfileSchema(chrt, name)
+		if schema == nil {
+			continue
+		}
+		if coalesced == nil {
+			var err error
+			if coalesced, err = util.CoalesceValues(chrt, vals); err != nil {
+				return err
+			}
+		}
+		if err := util.ValidateAgainstSingleSchema(coalesced, schema); err != nil {
+			return fmt.Errorf("values don't meet the specifications of the schema of values profile %q:\n%w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func valuesProfilesChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "profiles", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"replicas":  1,
+			"resources": map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+		},
+		Files: []*common.File{
+			{Name: "values/production.yaml", Data: []byte("replicas: 3\nresources:\n  cpu: 1\n  memory: 1Gi\n")},
+			{Name: "values/production.schema.json", Data: []byte(`{"properties": {"replicas": {"minimum": 2}}}`)},
+			{Name: "values/small.yml", Data: []byte("resources:\n  memory: 64Mi\n")},
+			{Name: "values/nested/ignored.yaml", Data: []byte("replicas: 0\n")},
+			{Name: "files/values.yaml", Data: []byte("replicas: 0\n")},
+		},
+	}
+}
+
+func TestValuesProfiles(t *testing.T) {
+	if got, want := ValuesProfiles(valuesProfilesChart()), []string{"production", "small"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected profiles %v, got %v", want, got)
+	}
+}
+
+func TestApplyValuesProfiles(t *testing.T) {
+	chrt := valuesProfilesChart()
+	vals := map[string]interface{}{"resources": map[string]interface{}{"cpu": "2"}}
+
+	got, err := ApplyValuesProfiles(chrt, vals, []string{"production", "small"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"replicas":  float64(3),
+		"resources": map[string]interface{}{"cpu": "2", "memory": "64Mi"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if !reflect.DeepEqual(vals, map[string]interface{}{"resources": map[string]interface{}{"cpu": "2"}}) {
+		t.Errorf("expected the supplied values to be left alone, got %v", vals)
+	}
+
+	if got, _ := ApplyValuesProfiles(chrt, vals, nil); !reflect.DeepEqual(got, vals) {
+		t.Errorf("expected the supplied values without profiles, got %v", got)
+	}
+
+	_, err = ApplyValuesProfiles(chrt, vals, []string{"staging"})
+	if err == nil || !strings.Contains(err.Error(), "available profiles: production, small") {
+		t.Errorf("expected an error listing the profiles, got %v", err)
+	}
+}
+
+func TestValidateValuesProfiles(t *testing.T) {
+	chrt := valuesProfilesChart()
+	profiles := []string{"production", "small"}
+
+	vals, err := ApplyValuesProfiles(chrt, nil, profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateValuesProfiles(chrt, vals, profiles); err != nil {
+		t.Errorf("expected the profiles to be valid, got %v", err)
+	}
+
+	vals, err = ApplyValuesProfiles(chrt, map[string]interface{}{"replicas": 1}, profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ValidateValuesProfiles(chrt, vals, profiles)
+	if err == nil || !strings.Contains(err.Error(), `values profile "production"`) {
+		t.Errorf("expected the production schema to reject one replica, got %v", err)
+	}
+	if err := ValidateValuesProfiles(chrt, vals, []string{"small"}); err != nil {
+		t.Errorf("expected profiles without a schema to be skipped, got %v", err)
+	}
+}
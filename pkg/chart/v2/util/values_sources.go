@@ -0,0 +1,200 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/common/util"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// UserSuppliedSource is the source of the user-supplied values of releases
+// that did not record where their values came from.
+const UserSuppliedSource = "user-supplied"
+
+// ChartDefaultSource describes a value set by the values.yaml of a chart.
+func ChartDefaultSource(chartPath string) string {
+	return fmt.Sprintf("chart default (%s)", chartPath)
+}
+
+// ParentOverrideSource describes a value of a subchart set by the values.yaml
+// of one of its parent charts.
+func ParentOverrideSource(chartPath string) string {
+	return fmt.Sprintf("parent override (%s)", chartPath)
+}
+
+// ValuesFileSource describes a value set by the nth values file, counting
+// from 1, given with -f/--values.
+func ValuesFileSource(n int, filename string) string {
+	return fmt.Sprintf("-f %s (file %d)", filename, n)
+}
+
+// FlagSource describes a value set by a flag such as --set, given the flag
+// and its argument.
+func FlagSource(flag, arg string) string {
+	return fmt.Sprintf("%s %s", flag, arg)
+}
+
+// ValueOrigin is a value of a release along with where it came from.
+type ValueOrigin struct {
+	// Path is the dot-separated path of the value, such as "image.tag".
+	Path string `json:"path"`
+	// Value is the value at Path. Tables are only reported when they are
+	// empty; otherwise each of their values is.
+	Value interface{} `json:"value"`
+	// Source describes what set the value.
+	Source string `json:"source"`
+}
+
+// RecordValuesSources records that source set each of the values in vals, in
+// the map from value paths to sources. Sources recorded later win, as values
+// merged later do.
+func RecordValuesSources(sources map[string]string, vals map[string]interface{}, source string) {
+	walkValues(vals, "", func(path string, _ interface{}) {
+		sources[path] = source
+	})
+}
+
+// MergeValuesSources combines maps from value paths to sources, later ones
+// winning, keeping only the sources of the values in vals.
+func MergeValuesSources(vals map[string]interface{}, sources ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	walkValues(vals, "", func(path string, _ interface{}) {
+		for _, s := range sources {
+			if source, ok := s[path]; ok {
+				merged[path] = source
+			}
+		}
+	})
+	return merged
+}
+
+// ExplainValues reports, for every value the chart would be rendered with
+// given the user-supplied values vals, which source set it: the default
+// values of a chart, those of a parent chart overriding a subchart, or a
+// user-supplied source recorded in sources.
+//
+// The user-supplied values without a recorded source are reported as
+// UserSuppliedSource. The values are sorted by path.
+func ExplainValues(chrt *chart.Chart, vals map[string]interface{}, sources map[string]string) ([]ValueOrigin, error) {
+	final, err := util.CoalesceValues(chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	return explainValues(chrt, final, vals, sources), nil
+}
+
+// ExplainUserValues reports which user-supplied source set each of the values
+// in vals, as ExplainValues does, without the default values of the chart.
+func ExplainUserValues(vals map[string]interface{}, sources map[string]string) []ValueOrigin {
+	return explainValues(nil, vals, vals, sources)
+}
+
+func explainValues(chrt *chart.Chart, final, vals map[string]interface{}, sources map[string]string) []ValueOrigin {
+	user := map[string]string{}
+	walkValues(vals, "", func(path string, _ interface{}) {
+		if source, ok := sources[path]; ok {
+			user[path] = source
+		} else {
+			user[path] = UserSuppliedSource
+		}
+	})
+	defaults := map[string]string{}
+	if chrt != nil {
+		recordChartSources(chrt, "", defaults)
+	}
+
+	var origins []ValueOrigin
+	walkValues(final, "", func(path string, v interface{}) {
+		candidates := globalCandidates(path)
+		source := ""
+		for _, layer := range []map[string]string{user, defaults} {
+			for _, c := range candidates {
+				if s, ok := layer[c]; ok {
+					source = s
+					break
+				}
+			}
+			if source != "" {
+				break
+			}
+		}
+		origins = append(origins, ValueOrigin{Path: path, Value: v, Source: source})
+	})
+	return origins
+}
+
+// recordChartSources records the values of the chart and its subcharts in
+// the map, prefixing them with the path of the chart in the values. The
+// values of parent charts are recorded after those of their subcharts, as
+// they take precedence.
+func recordChartSources(chrt *chart.Chart, prefix string, sources map[string]string) {
+	subcharts := map[string]bool{}
+	for _, dep := range chrt.Dependencies() {
+		subcharts[dep.Name()] = true
+		recordChartSources(dep, joinValuesPath(prefix, dep.Name()), sources)
+	}
+	walkValues(chrt.Values, "", func(path string, _ interface{}) {
+		key, _, _ := strings.Cut(path, ".")
+		if subcharts[key] {
+			sources[joinValuesPath(prefix, path)] = ParentOverrideSource(chrt.ChartFullPath())
+		} else {
+			sources[joinValuesPath(prefix, path)] = ChartDefaultSource(chrt.ChartFullPath())
+		}
+	})
+}
+
+// globalCandidates returns the paths a value may have been copied from, in
+// order of precedence. Global values are copied from parent charts to their
+// subcharts, so "sub.global.x" may come from "global.x".
+func globalCandidates(path string) []string {
+	segments := strings.Split(path, ".")
+	i := slices.Index(segments, common.GlobalKey)
+	if i < 0 {
+		return []string{path}
+	}
+	rest := strings.Join(segments[i:], ".")
+	candidates := make([]string, 0, i+1)
+	for j := 0; j <= i; j++ {
+		candidates = append(candidates, joinValuesPath(strings.Join(segments[:j], "."), rest))
+	}
+	return candidates
+}
+
+// walkValues calls fn with the path and value of every value in vals that is
+// not a non-empty table, in order of path.
+func walkValues(vals map[string]interface{}, prefix string, fn func(path string, v interface{})) {
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		path := joinValuesPath(prefix, k)
+		v := vals[k]
+		if table, ok := v.(map[string]interface{}); ok && len(table) > 0 {
+			walkValues(table, path, fn)
+			continue
+		}
+		fn(path, v)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+func TestExplainValues(t *testing.T) {
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"replicas": 1,
+			"image":    map[string]interface{}{"repository": "app", "tag": "1.0"},
+			"global":   map[string]interface{}{"region": "eu"},
+			"db":       map[string]interface{}{"storage": "20Gi"},
+		},
+	}
+	parent.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "db", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"storage": "10Gi",
+			"port":    5432,
+		},
+	})
+
+	sources := map[string]string{}
+	RecordValuesSources(sources, map[string]interface{}{"image": map[string]interface{}{"tag": "2.0"}}, ValuesFileSource(1, "prod.yaml"))
+	RecordValuesSources(sources, map[string]interface{}{"global": map[string]interface{}{"region": "us"}}, FlagSource("--set", "global.region=us"))
+	vals := map[string]interface{}{
+		"image":  map[string]interface{}{"tag": "2.0"},
+		"global": map[string]interface{}{"region": "us", "env": "prod"},
+	}
+
+	got, err := ExplainValues(parent, vals, sources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ValueOrigin{
+		{Path: "db.global.env", Value: "prod", Source: UserSuppliedSource},
+		{Path: "db.global.region", Value: "us", Source: "--set global.region=us"},
+		{Path: "db.port", Value: 5432, Source: "chart default (app/charts/db)"},
+		{Path: "db.storage", Value: "20Gi", Source: "parent override (app)"},
+		{Path: "global.env", Value: "prod", Source: UserSuppliedSource},
+		{Path: "global.region", Value: "us", Source: "--set global.region=us"},
+		{Path: "image.repository", Value: "app", Source: "chart default (app)"},
+		{Path: "image.tag", Value: "2.0", Source: "-f prod.yaml (file 1)"},
+		{Path: "replicas", Value: 1, Source: "chart default (app)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected\n%v\ngot\n%v", want, got)
+	}
+
+	user := ExplainUserValues(vals, sources)
+	if len(user) != 3 || user[2].Path != "image.tag" || user[2].Source != "-f prod.yaml (file 1)" {
+		t.Errorf("unexpected user-supplied values %v", user)
+	}
+}
+
+func TestMergeValuesSources(t *testing.T) {
+	vals := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "2.0"},
+		"ports": []interface{}{80},
+	}
+	older := map[string]string{"image.tag": "-f old.yaml (file 1)", "ports": "--set ports={80}", "removed": "--set removed=1"}
+	newer := map[string]string{"image.tag": "--set image.tag=2.0", "image.tag.x": "stale"}
+
+	got := MergeValuesSources(vals, older, newer)
+	want := map[string]string{"image.tag": "--set image.tag=2.0", "ports": "--set ports={80}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
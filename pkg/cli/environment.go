@@ -85,6 +85,9 @@ type EnvSettings struct {
 	PluginsDirectory string
 	// MaxHistory is the max release history maintained.
 	MaxHistory int
+	// MaxFailedHistory is the max number of failed revisions maintained in
+	// the release history.
+	MaxFailedHistory int
 	// BurstLimit is the default client-side throttling limit.
 	BurstLimit int
 	// QPS is queries per second which may be used to avoid throttling.
@@ -99,6 +102,7 @@ func New() *EnvSettings {
 	env := &EnvSettings{
 		namespace:                 os.Getenv("HELM_NAMESPACE"),
 		MaxHistory:                envIntOr("HELM_MAX_HISTORY", defaultMaxHistory),
+		MaxFailedHistory:          envIntOr("HELM_MAX_FAILED_HISTORY", 0),
 		KubeContext:               os.Getenv("HELM_KUBECONTEXT"),
 		KubeToken:                 os.Getenv("HELM_KUBETOKEN"),
 		KubeAsUser:                os.Getenv("HELM_KUBEASUSER"),
@@ -241,20 +245,21 @@ func envColorMode() string {
 
 func (s *EnvSettings) EnvVars() map[string]string {
 	envvars := map[string]string{
-		"HELM_BIN":               os.Args[0],
-		"HELM_CACHE_HOME":        helmpath.CachePath(""),
-		"HELM_CONFIG_HOME":       helmpath.ConfigPath(""),
-		"HELM_DATA_HOME":         helmpath.DataPath(""),
-		"HELM_DEBUG":             fmt.Sprint(s.Debug),
-		"HELM_PLUGINS":           s.PluginsDirectory,
-		"HELM_REGISTRY_CONFIG":   s.RegistryConfig,
-		"HELM_REPOSITORY_CACHE":  s.RepositoryCache,
-		"HELM_CONTENT_CACHE":     s.ContentCache,
-		"HELM_REPOSITORY_CONFIG": s.RepositoryConfig,
-		"HELM_NAMESPACE":         s.Namespace(),
-		"HELM_MAX_HISTORY":       strconv.Itoa(s.MaxHistory),
-		"HELM_BURST_LIMIT":       strconv.Itoa(s.BurstLimit),
-		"HELM_QPS":               strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
+		"HELM_BIN":                os.Args[0],
+		"HELM_CACHE_HOME":         helmpath.CachePath(""),
+		"HELM_CONFIG_HOME":        helmpath.ConfigPath(""),
+		"HELM_DATA_HOME":          helmpath.DataPath(""),
+		"HELM_DEBUG":              fmt.Sprint(s.Debug),
+		"HELM_PLUGINS":            s.PluginsDirectory,
+		"HELM_REGISTRY_CONFIG":    s.RegistryConfig,
+		"HELM_REPOSITORY_CACHE":   s.RepositoryCache,
+		"HELM_CONTENT_CACHE":      s.ContentCache,
+		"HELM_REPOSITORY_CONFIG":  s.RepositoryConfig,
+		"HELM_NAMESPACE":          s.Namespace(),
+		"HELM_MAX_HISTORY":        strconv.Itoa(s.MaxHistory),
+		"HELM_MAX_FAILED_HISTORY": strconv.Itoa(s.MaxFailedHistory),
+		"HELM_BURST_LIMIT":        strconv.Itoa(s.BurstLimit),
+		"HELM_QPS":                strconv.FormatFloat(float64(s.QPS), 'f', 2, 32),
 
 		// broken, these are populated from helm flags and not kubeconfig.
 		"HELM_KUBECONTEXT":                  s.KubeContext,
@@ -17,6 +17,7 @@ limitations under the License.
 package output
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -32,20 +33,48 @@ const (
 	Table Format = "table"
 	JSON  Format = "json"
 	YAML  Format = "yaml"
+	// JSONV1 and YAMLV1 write the JSON and YAML output wrapped in a
+	// Document, whose schema is versioned.
+	JSONV1 Format = "json/v1"
+	YAMLV1 Format = "yaml/v1"
 )
 
+// APIVersion is the apiVersion of the Documents written by the JSONV1 and
+// YAMLV1 formats.
+const APIVersion = "output.helm.sh/v1"
+
+// Document is the envelope of versioned JSON and YAML output. Scripts can
+// check its apiVersion and kind before reading its data, which keeps its
+// schema until the apiVersion changes.
+type Document struct {
+	APIVersion string `json:"apiVersion"`
+	// Kind names the schema of Data, for example ReleaseList.
+	Kind string `json:"kind"`
+	// Data is the output of the unversioned JSON format.
+	Data json.RawMessage `json:"data"`
+}
+
+// Kinded is implemented by Writers whose output can be written in the
+// versioned formats.
+type Kinded interface {
+	// Kind returns the kind of the Document wrapping the output.
+	Kind() string
+}
+
 // Formats returns a list of the string representation of the supported formats
 func Formats() []string {
-	return []string{Table.String(), JSON.String(), YAML.String()}
+	return []string{Table.String(), JSON.String(), YAML.String(), JSONV1.String(), YAMLV1.String()}
 }
 
 // FormatsWithDesc returns a list of the string representation of the supported formats
 // including a description
 func FormatsWithDesc() map[string]string {
 	return map[string]string{
-		Table.String(): "Output result in human-readable format",
-		JSON.String():  "Output result in JSON format",
-		YAML.String():  "Output result in YAML format",
+		Table.String():  "Output result in human-readable format",
+		JSON.String():   "Output result in JSON format",
+		YAML.String():   "Output result in YAML format",
+		JSONV1.String(): "Output result in JSON format, wrapped in a versioned document",
+		YAMLV1.String(): "Output result in YAML format, wrapped in a versioned document",
 	}
 }
 
@@ -57,6 +86,12 @@ func (o Format) String() string {
 	return string(o)
 }
 
+// Structured reports whether the format is meant to be read by programs
+// rather than people.
+func (o Format) Structured() bool {
+	return o != Table
+}
+
 // Write the output in the given format to the io.Writer. Unsupported formats
 // will return an error
 func (o Format) Write(out io.Writer, w Writer) error {
@@ -67,10 +102,39 @@ func (o Format) Write(out io.Writer, w Writer) error {
 		return w.WriteJSON(out)
 	case YAML:
 		return w.WriteYAML(out)
+	case JSONV1:
+		doc, err := newDocument(w)
+		if err != nil {
+			return err
+		}
+		return EncodeJSON(out, doc)
+	case YAMLV1:
+		doc, err := newDocument(w)
+		if err != nil {
+			return err
+		}
+		return EncodeYAML(out, doc)
 	}
 	return ErrInvalidFormatType
 }
 
+// newDocument wraps the JSON output of w in a Document.
+func newDocument(w Writer) (*Document, error) {
+	k, ok := w.(Kinded)
+	if !ok {
+		return nil, fmt.Errorf("%w: the output has no versioned schema", ErrInvalidFormatType)
+	}
+	var buf bytes.Buffer
+	if err := w.WriteJSON(&buf); err != nil {
+		return nil, err
+	}
+	return &Document{
+		APIVersion: APIVersion,
+		Kind:       k.Kind(),
+		Data:       bytes.TrimSpace(buf.Bytes()),
+	}, nil
+}
+
 // ParseFormat takes a raw string and returns the matching Format.
 // If the format does not exist, ErrInvalidFormatType is returned
 func ParseFormat(s string) (out Format, err error) {
@@ -81,6 +145,10 @@ func ParseFormat(s string) (out Format, err error) {
 		out, err = JSON, nil
 	case YAML.String():
 		out, err = YAML, nil
+	case JSONV1.String():
+		out, err = JSONV1, nil
+	case YAMLV1.String():
+		out, err = YAMLV1, nil
 	default:
 		out, err = "", ErrInvalidFormatType
 	}
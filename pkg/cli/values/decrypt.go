@@ -0,0 +1,144 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Decrypter decrypts the values files given via -f/--values that are
+// encrypted in the format it supports.
+type Decrypter interface {
+	// Detect reports whether data is encrypted in the format of the
+	// Decrypter.
+	Detect(data []byte) bool
+	// Decrypt returns the plaintext values of data.
+	Decrypt(data []byte) ([]byte, error)
+}
+
+// DefaultDecrypters returns the built-in Decrypters, decrypting values
+// files encrypted with sops and age keys, or with age itself, using the age
+// identities of LoadAgeIdentities.
+func DefaultDecrypters() []Decrypter {
+	return []Decrypter{&SopsDecrypter{}, &AgeDecrypter{}}
+}
+
+// decrypt decrypts data with the first of decrypters detecting it, and
+// returns data unchanged if none does.
+func decrypt(data []byte, decrypters []Decrypter) ([]byte, error) {
+	for _, d := range decrypters {
+		if d.Detect(data) {
+			return d.Decrypt(data)
+		}
+	}
+	return data, nil
+}
+
+// ageHeader is the first line of binary age files.
+const ageHeader = "age-encryption.org/v1\n"
+
+// AgeDecrypter decrypts values files encrypted with age, in the binary or
+// the armored format.
+type AgeDecrypter struct {
+	// Identities are the keys the files are decrypted with. If empty, the
+	// identities of LoadAgeIdentities are used.
+	Identities []age.Identity
+}
+
+// Detect implements Decrypter.
+func (d *AgeDecrypter) Detect(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(ageHeader)) ||
+		bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header))
+}
+
+// Decrypt implements Decrypter.
+func (d *AgeDecrypter) Decrypt(data []byte) ([]byte, error) {
+	identities := d.Identities
+	if len(identities) == 0 {
+		var err error
+		if identities, err = LoadAgeIdentities(); err != nil {
+			return nil, err
+		}
+	}
+	return ageDecrypt(data, identities)
+}
+
+func ageDecrypt(data []byte, identities []age.Identity) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if !bytes.HasPrefix(data, []byte(ageHeader)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// LoadAgeIdentities loads the age identities used to decrypt values files,
+// following the conventions of sops. They are read from the SOPS_AGE_KEY
+// environment variable, the file named by SOPS_AGE_KEY_FILE, and the
+// sops/age/keys.txt file of the user configuration directory, such as
+// $XDG_CONFIG_HOME/sops/age/keys.txt.
+func LoadAgeIdentities() ([]age.Identity, error) {
+	var identities []age.Identity
+	if key := os.Getenv("SOPS_AGE_KEY"); key != "" {
+		ids, err := age.ParseIdentities(strings.NewReader(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SOPS_AGE_KEY: %w", err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	files := []string{os.Getenv("SOPS_AGE_KEY_FILE")}
+	if dir, err := os.UserConfigDir(); err == nil {
+		files = append(files, filepath.Join(dir, "sops", "age", "keys.txt"))
+	}
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		f, err := os.Open(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids, err := age.ParseIdentities(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse age identities of %s: %w", file, err)
+		}
+		identities = append(identities, ids...)
+	}
+
+	if len(identities) == 0 {
+		return nil, errors.New("no age identity found: set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE")
+	}
+	return identities, nil
+}
@@ -0,0 +1,321 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func ageEncrypt(t *testing.T, data []byte, recipient age.Recipient) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	a := armor.NewWriter(&b)
+	w, err := age.Encrypt(a, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// sopsEncrypt encrypts value at path the way sops does.
+func sopsEncrypt(t *testing.T, key []byte, value, typ string, path ...string) string {
+	t.Helper()
+	return sopsEncryptData(t, key, value, typ, strings.Join(path, ":")+":")
+}
+
+// sopsEncryptData encrypts value, authenticating additionalData with it.
+func sopsEncryptData(t *testing.T, key []byte, value, typ, additionalData string) string {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := make([]byte, 32)
+	if _, err := rand.Read(iv); err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := gcm.Seal(nil, iv, []byte(value), []byte(additionalData))
+	data, tag := out[:len(out)-gcm.Overhead()], out[len(out)-gcm.Overhead():]
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", enc(data), enc(iv), enc(tag), typ)
+}
+
+func sopsFile(t *testing.T, identity *age.X25519Identity) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	const lastModified = "2024-01-31T12:00:00Z"
+
+	// The MAC covers the values in document order, comments included.
+	mac := sha512.New()
+	for _, v := range []string{" database settings", "s3cr3t", "5432", "True", "db-0", "db-1", "1.2.3"} {
+		mac.Write([]byte(v))
+	}
+	values := fmt.Sprintf(`# database settings
+database:
+    password: %s
+    port: %s
+    tls: %s
+    hosts:
+        - %s
+        - %s
+image:
+    tag: 1.2.3
+`,
+		sopsEncrypt(t, key, "s3cr3t", "str", "database", "password"),
+		sopsEncrypt(t, key, "5432", "int", "database", "port"),
+		sopsEncrypt(t, key, "True", "bool", "database", "tls"),
+		sopsEncrypt(t, key, "db-0", "str", "database", "hosts"),
+		sopsEncrypt(t, key, "db-1", "str", "database", "hosts"),
+	)
+
+	metadata, err := yaml.Marshal(map[string]interface{}{
+		"sops": map[string]interface{}{
+			"age": []interface{}{map[string]interface{}{
+				"recipient": identity.Recipient().String(),
+				"enc":       string(ageEncrypt(t, key, identity.Recipient())),
+			}},
+			"encrypted_regex": "^database$",
+			"lastmodified":    lastModified,
+			"mac":             sopsEncryptData(t, key, fmt.Sprintf("%X", mac.Sum(nil)), "str", lastModified),
+			"version":         "3.8.1",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append([]byte(values), metadata...)
+}
+
+func TestSopsDecrypter(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := sopsFile(t, identity)
+
+	d := &SopsDecrypter{Identities: []age.Identity{identity}}
+	if !d.Detect(data) {
+		t.Fatal("expected the sops file to be detected")
+	}
+	if d.Detect([]byte("sops: ENC[AES256_GCM,\n")) {
+		t.Error("expected a values file without sops metadata not to be detected")
+	}
+
+	plain, err := d.Decrypt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := yaml.Unmarshal(plain, &got); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "1.2.3"},
+		"database": map[string]interface{}{
+			"password": "s3cr3t",
+			"port":     float64(5432),
+			"tls":      true,
+			"hosts":    []interface{}{"db-0", "db-1"},
+		},
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d = &SopsDecrypter{Identities: []age.Identity{other}}
+	if _, err := d.Decrypt(data); err == nil || !strings.Contains(err.Error(), "failed to decrypt the sops data key") {
+		t.Errorf("expected a data key error, got %v", err)
+	}
+
+	// Moving an encrypted value breaks its authentication
+	moved := bytes.Replace(data, []byte("password:"), []byte("passwd:"), 1)
+	d = &SopsDecrypter{Identities: []age.Identity{identity}}
+	if _, err := d.Decrypt(moved); err == nil || !strings.Contains(err.Error(), "failed to decrypt database.passwd") {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+}
+
+func TestSopsDecrypterTampered(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := sopsFile(t, identity)
+	lines := strings.Split(string(data), "\n")
+	edit := func(prefix, replacement string) []byte {
+		t.Helper()
+		for i, line := range lines {
+			if strings.HasPrefix(line, prefix) {
+				edited := slices.Clone(lines)
+				edited[i] = replacement
+				return []byte(strings.Join(edited, "\n"))
+			}
+		}
+		t.Fatalf("no line starts with %q", prefix)
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"removed value", edit("    password:", ""), "sops MAC mismatch"},
+		{"changed plaintext value", edit("    tag:", "    tag: 6.6.6"), "sops MAC mismatch"},
+		{"changed comment", edit("# database settings", "# other settings"), "sops MAC mismatch"},
+		{"plaintext value", edit("    password:", "    password: s3cr3t"), "sops value database.password is not encrypted"},
+		{"changed MAC", edit("  mac:", "  mac: "+sopsEncryptData(t, make([]byte, 32), "0", "str", "2024-01-31T12:00:00Z")), "failed to decrypt the sops MAC"},
+	}
+	d := &SopsDecrypter{Identities: []age.Identity{identity}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := d.Decrypt(tt.data); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestAgeDecrypter(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := ageEncrypt(t, []byte("password: s3cr3t\n"), identity.Recipient())
+
+	d := &AgeDecrypter{Identities: []age.Identity{identity}}
+	if !d.Detect(data) {
+		t.Fatal("expected the age file to be detected")
+	}
+	if d.Detect([]byte("password: s3cr3t\n")) {
+		t.Error("expected a plain values file not to be detected")
+	}
+	plain, err := d.Decrypt(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != "password: s3cr3t\n" {
+		t.Errorf("unexpected plaintext %q", plain)
+	}
+}
+
+func TestLoadAgeIdentities(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	if err := os.WriteFile(keyFile, []byte("# created: now\n"+identity.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	if _, err := LoadAgeIdentities(); err == nil {
+		t.Error("expected an error without identities")
+	}
+
+	t.Setenv("SOPS_AGE_KEY_FILE", keyFile)
+	identities, err := LoadAgeIdentities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(identities) != 1 || identities[0].(*age.X25519Identity).String() != identity.String() {
+		t.Errorf("unexpected identities %v", identities)
+	}
+}
+
+func TestMergeValuesDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	secrets := filepath.Join(dir, "secrets.enc.yaml")
+	if err := os.WriteFile(secrets, sopsFile(t, identity), 0600); err != nil {
+		t.Fatal(err)
+	}
+	plain := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(plain, []byte("image:\n  repository: nginx\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOPS_AGE_KEY", identity.String())
+
+	opts := &Options{ValueFiles: []string{plain, secrets}}
+	vals, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vals["image"]; !reflect.DeepEqual(got, map[string]interface{}{"repository": "nginx", "tag": "1.2.3"}) {
+		t.Errorf("unexpected image values %v", got)
+	}
+	if got := vals["database"].(map[string]interface{})["password"]; got != "s3cr3t" {
+		t.Errorf("expected the decrypted password, got %v", got)
+	}
+	if _, ok := vals["sops"]; ok {
+		t.Error("expected the sops metadata to be removed")
+	}
+
+	opts = &Options{ValueFiles: []string{secrets}, Decrypters: []Decrypter{}}
+	vals, err = opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vals["sops"]; !ok {
+		t.Error("expected the file to be left encrypted without decrypters")
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EnvExpansion is the mode of expansion of the environment variables
+// referenced by the values files.
+type EnvExpansion string
+
+const (
+	// EnvExpansionOff leaves the values files as they are.
+	EnvExpansionOff EnvExpansion = ""
+	// EnvExpansionLenient expands the references to unset variables to
+	// empty strings, as envsubst does.
+	EnvExpansionLenient EnvExpansion = "lenient"
+	// EnvExpansionStrict fails on the references to unset variables.
+	EnvExpansionStrict EnvExpansion = "strict"
+)
+
+// envReference matches a ${NAME} reference to an environment variable, or
+// its $${NAME} escape.
+var envReference = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnv replaces the ${NAME} references to environment variables in the
+// content of a values file, before it is parsed. $${NAME} is replaced with a
+// literal ${NAME}. Other uses of $, such as the ${env:NAME} expressions of
+// ExpandTemplates, are left alone.
+//
+// The values of the variables are inserted as they are, so that they must be
+// valid YAML where they are referenced; quote the references to variables
+// which may hold special characters. Unset variables are expanded to empty
+// strings, unless mode is EnvExpansionStrict, in which case an error lists
+// them.
+//
+// lookupEnv is usually os.LookupEnv.
+func ExpandEnv(data []byte, mode EnvExpansion, lookupEnv func(string) (string, bool)) ([]byte, error) {
+	switch mode {
+	case EnvExpansionOff:
+		return data, nil
+	case EnvExpansionLenient, EnvExpansionStrict:
+	default:
+		return nil, fmt.Errorf("invalid environment expansion mode %q: must be %q or %q", mode, EnvExpansionLenient, EnvExpansionStrict)
+	}
+
+	unset := map[string]bool{}
+	expanded := envReference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		if ref[1] == '$' {
+			return ref[1:]
+		}
+		name := string(envReference.FindSubmatch(ref)[1])
+		v, ok := lookupEnv(name)
+		if !ok {
+			unset[name] = true
+		}
+		return []byte(v)
+	})
+	if mode == EnvExpansionStrict && len(unset) > 0 {
+		names := make([]string, 0, len(unset))
+		for name := range unset {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unset environment variables: %s", strings.Join(names, ", "))
+	}
+	return expanded, nil
+}
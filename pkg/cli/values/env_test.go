@@ -0,0 +1,103 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"REGISTRY": "registry.example.com", "EMPTY": ""}
+	lookupEnv := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name   string
+		mode   EnvExpansion
+		values string
+		expect string
+		err    string
+	}{
+		{
+			name:   "off",
+			values: "image: ${REGISTRY}/app",
+			expect: "image: ${REGISTRY}/app",
+		},
+		{
+			name:   "lenient",
+			mode:   EnvExpansionLenient,
+			values: "image: ${REGISTRY}/app\ntag: \"${TAG}\"\nempty: x${EMPTY}x",
+			expect: "image: registry.example.com/app\ntag: \"\"\nempty: xx",
+		},
+		{
+			name:   "escapes and other expressions",
+			mode:   EnvExpansionStrict,
+			values: "literal: $${REGISTRY}\ntemplate: ${env:TAG}\nshell: $REGISTRY ${1}",
+			expect: "literal: ${REGISTRY}\ntemplate: ${env:TAG}\nshell: $REGISTRY ${1}",
+		},
+		{
+			name:   "strict",
+			mode:   EnvExpansionStrict,
+			values: "image: ${REGISTRY}/app:${TAG}\ndigest: ${DIGEST}\ntag: ${TAG}",
+			err:    "unset environment variables: DIGEST, TAG",
+		},
+		{
+			name:   "invalid mode",
+			mode:   "always",
+			values: "image: ${REGISTRY}",
+			err:    `invalid environment expansion mode "always"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnv([]byte(tt.values), tt.mode, lookupEnv)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, string(got))
+		})
+	}
+}
+
+func TestMergeValuesEnvExpansion(t *testing.T) {
+	t.Setenv("HELM_TEST_REPLICAS", "3")
+
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("replicas: ${HELM_TEST_REPLICAS}\nhost: ${HELM_TEST_UNSET}\n"), 0644))
+
+	opts := Options{ValueFiles: []string{valuesFile}, EnvExpansion: EnvExpansionLenient}
+	vals, err := opts.MergeValues(getter.Providers{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"replicas": float64(3), "host": nil}, vals)
+
+	opts.EnvExpansion = EnvExpansionStrict
+	_, err = opts.MergeValues(getter.Providers{})
+	assert.ErrorContains(t, err, "HELM_TEST_UNSET")
+}
@@ -18,6 +18,8 @@ package values
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -26,37 +28,103 @@ import (
 	"strings"
 
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/strvals"
 )
 
 // Options captures the different ways to specify values
 type Options struct {
-	ValueFiles    []string // -f/--values
-	StringValues  []string // --set-string
-	Values        []string // --set
-	FileValues    []string // --set-file
-	JSONValues    []string // --set-json
-	LiteralValues []string // --set-literal
+	ValueFiles    []string     // -f/--values
+	StringValues  []string     // --set-string
+	IntValues     []string     // --set-int
+	BoolValues    []string     // --set-bool
+	FloatValues   []string     // --set-float
+	Values        []string     // --set
+	FileValues    []string     // --set-file
+	JSONValues    []string     // --set-json
+	LiteralValues []string     // --set-literal
+	Template      bool         // --values-template
+	Patches       []string     // --values-patch
+	EnvExpansion  EnvExpansion // --values-env
+
+	// Decrypters decrypt the encrypted values files. If nil, the
+	// DefaultDecrypters are used.
+	Decrypters []Decrypter
 }
 
 // MergeValues merges values from files specified via -f/--values and directly
-// via --set-json, --set, --set-string, or --set-file, marshaling them to YAML
+// via --set-json, --set, --set-string, --set-int, --set-bool, --set-float, or
+// --set-file, marshaling them to YAML
+//
+// Values files encrypted with sops or age are decrypted with the Decrypters.
+// The references to environment variables in the values files are then
+// expanded with ExpandEnv, if EnvExpansion is set. The default values of the
+// chart are never expanded.
+//
+// If Template is set, the expressions of the merged values are expanded with
+// ExpandTemplates before the literal values given via --set-literal are set.
 func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, error) {
+	return opts.mergeValues(p, nil)
+}
+
+// MergeValuesWithSources merges the values as MergeValues does, also returning
+// which values file or flag set each of the values, keyed by the
+// dot-separated path of the value.
+func (opts *Options) MergeValuesWithSources(p getter.Providers) (map[string]interface{}, map[string]string, error) {
+	sources := map[string]string{}
+	base, err := opts.mergeValues(p, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+	return base, chartutil.MergeValuesSources(base, sources), nil
+}
+
+// mergeValues merges the values, recording their sources in sources unless
+// it is nil.
+func (opts *Options) mergeValues(p getter.Providers, sources map[string]string) (map[string]interface{}, error) {
 	base := map[string]interface{}{}
+	// record records the source of the values set by a single file or flag.
+	// The flags are parsed again on their own, reading no files, to find the
+	// values they set.
+	record := func(source string, parse func(dest map[string]interface{}) error) {
+		if sources == nil {
+			return
+		}
+		vals := map[string]interface{}{}
+		if err := parse(vals); err == nil {
+			chartutil.RecordValuesSources(sources, vals, source)
+		}
+	}
 
-	// User specified a values files via -f/--values
-	for _, filePath := range opts.ValueFiles {
+	decrypters := opts.Decrypters
+	if decrypters == nil {
+		decrypters = DefaultDecrypters()
+	}
+
+	// User specified a values files via -f/--values, possibly encrypted
+	for i, filePath := range opts.ValueFiles {
 		raw, err := readFile(filePath, p)
 		if err != nil {
 			return nil, err
 		}
+		raw, err = decrypt(raw, decrypters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", filePath, err)
+		}
+		raw, err = ExpandEnv(raw, opts.EnvExpansion, os.LookupEnv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand %s: %w", filePath, err)
+		}
 		currentMap, err := loader.LoadValues(bytes.NewReader(raw))
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
 		}
 		// Merge with the previous map
 		base = loader.MergeMaps(base, currentMap)
+		if sources != nil {
+			chartutil.RecordValuesSources(sources, currentMap, chartutil.ValuesFileSource(i+1, filePath))
+		}
 	}
 
 	// User specified a value via --set-json
@@ -69,11 +137,17 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 				return nil, fmt.Errorf("failed parsing --set-json data JSON: %s", value)
 			}
 			base = loader.MergeMaps(base, jsonMap)
+			if sources != nil {
+				chartutil.RecordValuesSources(sources, jsonMap, chartutil.FlagSource("--set-json", value))
+			}
 		} else {
 			// Otherwise, parse it as key=value format
 			if err := strvals.ParseJSON(value, base); err != nil {
 				return nil, fmt.Errorf("failed parsing --set-json data %s", value)
 			}
+			record(chartutil.FlagSource("--set-json", value), func(dest map[string]interface{}) error {
+				return strvals.ParseJSON(value, dest)
+			})
 		}
 	}
 
@@ -82,6 +156,9 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		if err := strvals.ParseInto(value, base); err != nil {
 			return nil, fmt.Errorf("failed parsing --set data: %w", err)
 		}
+		record(chartutil.FlagSource("--set", value), func(dest map[string]interface{}) error {
+			return strvals.ParseInto(value, dest)
+		})
 	}
 
 	// User specified a value via --set-string
@@ -89,6 +166,29 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		if err := strvals.ParseIntoString(value, base); err != nil {
 			return nil, fmt.Errorf("failed parsing --set-string data: %w", err)
 		}
+		record(chartutil.FlagSource("--set-string", value), func(dest map[string]interface{}) error {
+			return strvals.ParseIntoString(value, dest)
+		})
+	}
+
+	// User specified a value via --set-int, --set-bool or --set-float
+	for _, typed := range []struct {
+		flag   string
+		values []string
+		typ    strvals.Type
+	}{
+		{"--set-int", opts.IntValues, strvals.TypeInt},
+		{"--set-bool", opts.BoolValues, strvals.TypeBool},
+		{"--set-float", opts.FloatValues, strvals.TypeFloat},
+	} {
+		for _, value := range typed.values {
+			if err := strvals.ParseIntoTyped(value, base, typed.typ); err != nil {
+				return nil, fmt.Errorf("failed parsing %s data: %w", typed.flag, err)
+			}
+			record(chartutil.FlagSource(typed.flag, value), func(dest map[string]interface{}) error {
+				return strvals.ParseIntoTyped(value, dest, typed.typ)
+			})
+		}
 	}
 
 	// User specified a value via --set-file
@@ -103,6 +203,16 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		if err := strvals.ParseIntoFile(value, base, reader); err != nil {
 			return nil, fmt.Errorf("failed parsing --set-file data: %w", err)
 		}
+		record(chartutil.FlagSource("--set-file", value), func(dest map[string]interface{}) error {
+			return strvals.ParseIntoFile(value, dest, func([]rune) (interface{}, error) { return "", nil })
+		})
+	}
+
+	// User asked to expand the expressions of the values via --values-template
+	if opts.Template {
+		if err := ExpandTemplates(base, os.LookupEnv); err != nil {
+			return nil, err
+		}
 	}
 
 	// User specified a value via --set-literal
@@ -110,13 +220,56 @@ func (opts *Options) MergeValues(p getter.Providers) (map[string]interface{}, er
 		if err := strvals.ParseLiteralInto(value, base); err != nil {
 			return nil, fmt.Errorf("failed parsing --set-literal data: %w", err)
 		}
+		record(chartutil.FlagSource("--set-literal", value), func(dest map[string]interface{}) error {
+			return strvals.ParseLiteralInto(value, dest)
+		})
 	}
 
 	return base, nil
 }
 
+// ReadPatches reads the JSON Patch files given via --values-patch, from the
+// local filesystem, stdin or a URL like the values files.
+//
+// The patches are left to be applied to the values coalesced with those of
+// the chart.
+func (opts *Options) ReadPatches(p getter.Providers) ([][]byte, error) {
+	var patches [][]byte
+	for _, filePath := range opts.Patches {
+		data, err := readFile(filePath, p)
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, data)
+	}
+	return patches, nil
+}
+
+// checksumPrefix introduces the digest pinning the content of a file given
+// to readFile.
+const checksumPrefix = "#sha256="
+
 // readFile load a file from stdin, the local directory, or a remote file with a url.
+//
+// The content of the file can be pinned with a "#sha256=<hex digest>" suffix,
+// making readFile fail if it does not match.
 func readFile(filePath string, p getter.Providers) ([]byte, error) {
+	filePath, checksum, _ := strings.Cut(filePath, checksumPrefix)
+	data, err := fetchFile(filePath, p)
+	if err != nil {
+		return nil, err
+	}
+	if checksum == "" {
+		return data, nil
+	}
+	sum := sha256.Sum256(data)
+	if digest := hex.EncodeToString(sum[:]); !strings.EqualFold(digest, checksum) {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", filePath, checksum, digest)
+	}
+	return data, nil
+}
+
+func fetchFile(filePath string, p getter.Providers) ([]byte, error) {
 	if strings.TrimSpace(filePath) == "-" {
 		return io.ReadAll(os.Stdin)
 	}
@@ -130,7 +283,8 @@ func readFile(filePath string, p getter.Providers) ([]byte, error) {
 	if err != nil {
 		return os.ReadFile(filePath)
 	}
-	data, err := g.Get(filePath, getter.WithURL(filePath))
+	// Values files pushed to registries are artifacts of their own.
+	data, err := g.Get(filePath, getter.WithURL(filePath), getter.WithArtifactType("values"))
 	if err != nil {
 		return nil, err
 	}
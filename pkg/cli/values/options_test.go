@@ -133,6 +133,23 @@ func TestReadFile(t *testing.T) {
 			expectError:  false,
 			expectedData: []byte("oci content"),
 		},
+		{
+			name:     "remote file with pinned checksum - success",
+			filePath: "https://example.com/values.yaml#sha256=0709E9B00585BA4764FD4D89BDEFEC5B1A20B3735C50D8E33A27F740023CECA2",
+			providers: getter.Providers{
+				mockProvider([]string{"https"}, []byte("remote content"), nil),
+			},
+			expectError:  false,
+			expectedData: []byte("remote content"),
+		},
+		{
+			name:     "remote file with pinned checksum - mismatch",
+			filePath: "https://example.com/values.yaml#sha256=0709e9b00585ba4764fd4d89bdefec5b1a20b3735c50d8e33a27f740023ceca2",
+			providers: getter.Providers{
+				mockProvider([]string{"https"}, []byte("changed content"), nil),
+			},
+			expectError: true,
+		},
 		{
 			name:     "remote file - getter error",
 			filePath: "http://example.com/values.yaml",
@@ -341,6 +358,27 @@ func TestMergeValuesCLI(t *testing.T) {
 				"foo": "123",
 			},
 		},
+		{
+			name: "set typed values",
+			opts: Options{
+				IntValues:   []string{"replicas=3"},
+				BoolValues:  []string{"enabled=0,debug=false", "enabled=true"},
+				FloatValues: []string{"ratio=1"},
+			},
+			expected: map[string]interface{}{
+				"replicas": int64(3),
+				"enabled":  true,
+				"debug":    false,
+				"ratio":    1.0,
+			},
+		},
+		{
+			name: "set typed value error",
+			opts: Options{
+				IntValues: []string{"replicas=three"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "set literal value",
 			opts: Options{
@@ -372,6 +410,36 @@ func TestMergeValuesCLI(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "values template",
+			opts: Options{
+				Values:        []string{"host=example.com", "url=https://${values:host}/"},
+				LiteralValues: []string{"raw=${values:host}"},
+				Template:      true,
+			},
+			expected: map[string]interface{}{
+				"host": "example.com",
+				"url":  "https://example.com/",
+				"raw":  "${values:host}",
+			},
+		},
+		{
+			name: "values template disabled",
+			opts: Options{
+				Values: []string{"url=${values:host}"},
+			},
+			expected: map[string]interface{}{
+				"url": "${values:host}",
+			},
+		},
+		{
+			name: "values template error",
+			opts: Options{
+				Values:   []string{"url=${values:host}"},
+				Template: true,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -387,3 +455,44 @@ func TestMergeValuesCLI(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeValuesWithSources(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	prod := filepath.Join(dir, "prod.yaml")
+	if err := os.WriteFile(base, []byte("image:\n  repository: app\n  tag: \"1.0\"\nreplicas: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(prod, []byte("replicas: 3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{
+		ValueFiles:   []string{base, prod},
+		Values:       []string{"image.tag=2.0"},
+		StringValues: []string{"build=42"},
+		IntValues:    []string{"port=8080"},
+	}
+	vals, sources, err := opts.MergeValuesWithSources(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"build":            "--set-string build=42",
+		"image.repository": fmt.Sprintf("-f %s (file 1)", base),
+		"image.tag":        "--set image.tag=2.0",
+		"port":             "--set-int port=8080",
+		"replicas":         fmt.Sprintf("-f %s (file 2)", prod),
+	}
+	if !reflect.DeepEqual(sources, want) {
+		t.Errorf("expected sources %v, got %v", want, sources)
+	}
+
+	merged, err := opts.MergeValues(getter.Providers{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vals, merged) {
+		t.Errorf("expected the same values as MergeValues %v, got %v", merged, vals)
+	}
+}
@@ -0,0 +1,449 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	yamlv3 "go.yaml.in/yaml/v3"
+	"sigs.k8s.io/yaml"
+)
+
+// sopsEncryptedValue matches the values encrypted by sops.
+var sopsEncryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// SopsDecrypter decrypts values files encrypted with sops, in YAML or JSON,
+// using the age keys of the files. Other sops key types, such as cloud KMS
+// or PGP keys, are not supported.
+//
+// Each value is authenticated by the AES-GCM encryption of sops, binding it
+// to its path in the file, and the file as a whole by the sops MAC. Like the
+// sops CLI, files whose MAC does not match, for example because values were
+// removed or replaced with plaintext, are refused.
+type SopsDecrypter struct {
+	// Identities are the keys the files are decrypted with. If empty, the
+	// identities of LoadAgeIdentities are used.
+	Identities []age.Identity
+}
+
+// sopsMetadata is the subset of the sops metadata of a file used to decrypt
+// it.
+type sopsMetadata struct {
+	Age       []sopsAgeKey `yaml:"age"`
+	KeyGroups []struct {
+		Age []sopsAgeKey `yaml:"age"`
+	} `yaml:"key_groups"`
+	ShamirThreshold         int    `yaml:"shamir_threshold"`
+	LastModified            string `yaml:"lastmodified"`
+	MAC                     string `yaml:"mac"`
+	MACOnlyEncrypted        bool   `yaml:"mac_only_encrypted"`
+	UnencryptedSuffix       string `yaml:"unencrypted_suffix"`
+	EncryptedSuffix         string `yaml:"encrypted_suffix"`
+	UnencryptedRegex        string `yaml:"unencrypted_regex"`
+	EncryptedRegex          string `yaml:"encrypted_regex"`
+	UnencryptedCommentRegex string `yaml:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   string `yaml:"encrypted_comment_regex"`
+	Version                 string `yaml:"version"`
+}
+
+// sopsAgeKey is the data key of a sops file, encrypted for an age recipient.
+type sopsAgeKey struct {
+	Recipient string `yaml:"recipient"`
+	Enc       string `yaml:"enc"`
+}
+
+// Detect implements Decrypter.
+func (d *SopsDecrypter) Detect(data []byte) bool {
+	if !bytes.Contains(data, []byte("sops")) || !bytes.Contains(data, []byte("ENC[AES256_GCM,")) {
+		return false
+	}
+	_, _, err := parseSops(data)
+	return err == nil
+}
+
+// Decrypt implements Decrypter.
+func (d *SopsDecrypter) Decrypt(data []byte) ([]byte, error) {
+	doc, metadata, err := parseSops(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := metadata.Age
+	for _, group := range metadata.KeyGroups {
+		keys = append(keys, group.Age...)
+	}
+	if len(metadata.KeyGroups) > 1 && metadata.ShamirThreshold > 1 {
+		return nil, errors.New("sops files with a Shamir threshold are not supported")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("sops file has no age key: only age keys are supported")
+	}
+	if metadata.UnencryptedCommentRegex != "" || metadata.EncryptedCommentRegex != "" {
+		return nil, errors.New("sops files encrypted with a comment regex are not supported")
+	}
+
+	identities := d.Identities
+	if len(identities) == 0 {
+		if identities, err = LoadAgeIdentities(); err != nil {
+			return nil, err
+		}
+	}
+	var dataKey []byte
+	for _, key := range keys {
+		if dataKey, err = ageDecrypt([]byte(key.Enc), identities); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the sops data key: %w", err)
+	}
+
+	tree, err := newSopsTree(metadata, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := tree.mapping(doc, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := tree.verifyMAC(); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(plain)
+}
+
+// parseSops parses a sops file into its document node, in document order,
+// and its metadata.
+func parseSops(data []byte) (*yamlv3.Node, *sopsMetadata, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if doc.Kind != yamlv3.DocumentNode || len(doc.Content) != 1 || doc.Content[0].Kind != yamlv3.MappingNode {
+		return nil, nil, errors.New("no sops metadata")
+	}
+	root := doc.Content[0]
+	var raw *yamlv3.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "sops" {
+			raw = root.Content[i+1]
+		}
+	}
+	if raw == nil {
+		return nil, nil, errors.New("no sops metadata")
+	}
+
+	var metadata sopsMetadata
+	if err := raw.Decode(&metadata); err != nil {
+		return nil, nil, err
+	}
+	if metadata.MAC == "" || metadata.Version == "" {
+		return nil, nil, errors.New("invalid sops metadata")
+	}
+	return &doc, &metadata, nil
+}
+
+// sopsTree decrypts the values of a sops file and computes its MAC. Like
+// sops, it walks the file in document order, comments included.
+type sopsTree struct {
+	metadata    *sopsMetadata
+	key         []byte
+	encrypted   *regexp.Regexp
+	unencrypted *regexp.Regexp
+	mac         hash.Hash
+}
+
+func newSopsTree(metadata *sopsMetadata, key []byte) (*sopsTree, error) {
+	t := &sopsTree{metadata: metadata, key: key, mac: sha512.New()}
+	var err error
+	if metadata.EncryptedRegex != "" {
+		if t.encrypted, err = regexp.Compile(metadata.EncryptedRegex); err != nil {
+			return nil, fmt.Errorf("invalid sops encrypted_regex: %w", err)
+		}
+	}
+	if metadata.UnencryptedRegex != "" {
+		if t.unencrypted, err = regexp.Compile(metadata.UnencryptedRegex); err != nil {
+			return nil, fmt.Errorf("invalid sops unencrypted_regex: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// isEncrypted reports whether sops encrypts the values found at path,
+// following the suffix and regex rules of the file.
+func (t *sopsTree) isEncrypted(path []string) bool {
+	m := t.metadata
+	encrypted := true
+	if m.UnencryptedSuffix != "" {
+		for _, k := range path {
+			if strings.HasSuffix(k, m.UnencryptedSuffix) {
+				encrypted = false
+				break
+			}
+		}
+	}
+	if m.EncryptedSuffix != "" {
+		encrypted = false
+		for _, k := range path {
+			if strings.HasSuffix(k, m.EncryptedSuffix) {
+				encrypted = true
+				break
+			}
+		}
+	}
+	if t.unencrypted != nil {
+		for _, k := range path {
+			if t.unencrypted.MatchString(k) {
+				encrypted = false
+				break
+			}
+		}
+	}
+	if t.encrypted != nil {
+		encrypted = false
+		for _, k := range path {
+			if t.encrypted.MatchString(k) {
+				encrypted = true
+				break
+			}
+		}
+	}
+	return encrypted
+}
+
+// mapping decrypts a document or mapping node found at path. The "sops" key
+// of the document is skipped.
+func (t *sopsTree) mapping(node *yamlv3.Node, path []string, commentsHandled bool) (map[string]interface{}, error) {
+	if !commentsHandled {
+		t.comments(path, node.HeadComment, node.LineComment)
+	}
+	out := map[string]interface{}{}
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		for _, n := range node.Content {
+			m, err := t.mapping(n, path, false)
+			if err != nil {
+				return nil, err
+			}
+			maps.Copy(out, m)
+		}
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Kind != yamlv3.ScalarNode {
+				return nil, fmt.Errorf("sops file has a non-string key at line %d", key.Line)
+			}
+			t.comments(path, key.HeadComment, key.LineComment)
+			scalar := value.Kind == yamlv3.ScalarNode || value.Kind == yamlv3.AliasNode
+			if scalar {
+				t.comments(path, value.HeadComment, value.LineComment)
+			}
+			if len(path) != 0 || key.Value != "sops" {
+				v, err := t.value(value, append(path[:len(path):len(path)], key.Value), scalar)
+				if err != nil {
+					return nil, err
+				}
+				out[key.Value] = v
+			}
+			if scalar {
+				t.comments(path, value.FootComment)
+			}
+			t.comments(path, key.FootComment)
+		}
+	default:
+		return nil, errors.New("sops files must be mappings")
+	}
+	return out, nil
+}
+
+// value decrypts the node found at path. Like sops, the items of lists share
+// the path of the list.
+func (t *sopsTree) value(node *yamlv3.Node, path []string, commentsHandled bool) (interface{}, error) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		return t.mapping(node, path, false)
+	case yamlv3.SequenceNode:
+		if !commentsHandled {
+			t.comments(path, node.HeadComment, node.LineComment)
+		}
+		out := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			t.comments(path, item.HeadComment, item.LineComment)
+			v, err := t.value(item, path, true)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			t.comments(path, item.FootComment)
+		}
+		return out, nil
+	case yamlv3.AliasNode:
+		return t.value(node.Alias, path, false)
+	case yamlv3.ScalarNode:
+		var v interface{}
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		return t.leaf(v, path)
+	}
+	return nil, fmt.Errorf("unsupported YAML node at line %d", node.Line)
+}
+
+// leaf decrypts a scalar value found at path and adds it to the MAC.
+func (t *sopsTree) leaf(v interface{}, path []string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	encrypted := t.isEncrypted(path)
+	if encrypted {
+		s, ok := v.(string)
+		if !ok || !sopsEncryptedValue.MatchString(s) {
+			return nil, fmt.Errorf("sops value %s is not encrypted", strings.Join(path, "."))
+		}
+		plain, err := sopsDecryptValue(s, t.key, strings.Join(path, ":")+":")
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+		}
+		v = plain
+	}
+	if encrypted || !t.metadata.MACOnlyEncrypted {
+		b, err := sopsBytes(v)
+		if err != nil {
+			return nil, fmt.Errorf("sops value %s: %w", strings.Join(path, "."), err)
+		}
+		t.mac.Write(b)
+	}
+	return v, nil
+}
+
+// comments adds YAML comments found in the mapping or list at path to the
+// MAC. Sops keeps comments, encrypted or not, and authenticates them too.
+func (t *sopsTree) comments(path []string, comments ...string) {
+	encrypted := t.isEncrypted(path)
+	if !encrypted && t.metadata.MACOnlyEncrypted {
+		return
+	}
+	for _, comment := range comments {
+		for _, line := range strings.Split(comment, "\n") {
+			if line == "" {
+				continue
+			}
+			value := line[1:]
+			// Like sops, comments that do not decrypt are taken as written.
+			if encrypted && sopsEncryptedValue.MatchString(value) {
+				if plain, err := sopsDecryptValue(value, t.key, strings.Join(path, ":")+":"); err == nil {
+					value = fmt.Sprint(plain)
+				}
+			}
+			t.mac.Write([]byte(value))
+		}
+	}
+}
+
+// verifyMAC compares the MAC of the file with the one computed over its
+// values.
+func (t *sopsTree) verifyMAC() error {
+	lastModified, err := time.Parse(time.RFC3339, t.metadata.LastModified)
+	if err != nil {
+		return fmt.Errorf("invalid sops lastmodified: %w", err)
+	}
+	mac, err := sopsDecryptValue(t.metadata.MAC, t.key, lastModified.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt the sops MAC: %w", err)
+	}
+	if mac != fmt.Sprintf("%X", t.mac.Sum(nil)) {
+		return errors.New("sops MAC mismatch: the file was modified after it was encrypted")
+	}
+	return nil
+}
+
+// sopsBytes returns the bytes of a value authenticated by the sops MAC.
+func sopsBytes(v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case int:
+		return []byte(strconv.Itoa(v)), nil
+	case int64:
+		return []byte(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return []byte(strconv.FormatUint(v, 10)), nil
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case bool:
+		if v {
+			return []byte("True"), nil
+		}
+		return []byte("False"), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+// sopsDecryptValue decrypts a value encrypted by sops, authenticating
+// additionalData with it.
+func sopsDecryptValue(value string, key []byte, additionalData string) (interface{}, error) {
+	m := sopsEncryptedValue.FindStringSubmatch(value)
+	var parts [3][]byte
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(m[i+1])
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = b
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ := m[4]; typ {
+	case "str", "bytes", "comment":
+		return string(plain), nil
+	case "int":
+		return strconv.Atoi(string(plain))
+	case "float":
+		return strconv.ParseFloat(string(plain), 64)
+	case "bool":
+		return strconv.ParseBool(strings.ToLower(string(plain)))
+	default:
+		return nil, fmt.Errorf("unsupported sops value type %q", typ)
+	}
+}
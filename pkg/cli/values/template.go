@@ -0,0 +1,235 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExpandTemplates replaces the expressions in the string values of vals, in
+// place. The syntax is deliberately restricted to two kinds of expressions:
+//
+//	${env:NAME}           the value of the environment variable NAME, which
+//	                      must be set
+//	${env:NAME:-default}  the value of NAME, or default if it is not set
+//	${values:path.to.key} the value of another key of vals, expanded first
+//
+// A string made of a single ${values:...} expression is replaced with the
+// referenced value itself, keeping its type. Otherwise the referenced value
+// must be a scalar and is inserted as text. Use $${ for a literal ${.
+//
+// lookupEnv is usually os.LookupEnv.
+func ExpandTemplates(vals map[string]interface{}, lookupEnv func(string) (string, bool)) error {
+	e := &valuesExpander{
+		root:      vals,
+		lookupEnv: lookupEnv,
+		state:     map[string]expandState{},
+	}
+	_, err := e.expand(nil, vals)
+	return err
+}
+
+type expandState int
+
+const (
+	expanding expandState = iota + 1
+	expanded
+)
+
+type valuesExpander struct {
+	root      map[string]interface{}
+	lookupEnv func(string) (string, bool)
+	// state tracks the values being and already expanded, by path, to detect
+	// reference cycles and never expand a value twice.
+	state map[string]expandState
+}
+
+func (e *valuesExpander) expand(path []string, v interface{}) (interface{}, error) {
+	key := strings.Join(path, "\x00")
+	switch e.state[key] {
+	case expanded:
+		return v, nil
+	case expanding:
+		return nil, fmt.Errorf("values template at %q: reference cycle", dotted(path))
+	}
+	e.state[key] = expanding
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			nv, err := e.expand(appendPath(path, k), child)
+			if err != nil {
+				return nil, err
+			}
+			t[k] = nv
+		}
+	case []interface{}:
+		for i, child := range t {
+			nv, err := e.expand(appendPath(path, strconv.Itoa(i)), child)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = nv
+		}
+	case string:
+		nv, err := e.expandString(path, t)
+		if err != nil {
+			return nil, err
+		}
+		v = nv
+	}
+
+	e.state[key] = expanded
+	return v, nil
+}
+
+func (e *valuesExpander) expandString(path []string, s string) (interface{}, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		if i > 0 && s[i-1] == '$' {
+			// $${ is an escaped ${.
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("values template at %q: unterminated expression %q", dotted(path), s[i:])
+		}
+		expr := s[i+2 : i+end]
+		whole := i == 0 && end == len(s)-1 && b.Len() == 0
+
+		v, err := e.evaluate(expr)
+		if err != nil {
+			return nil, fmt.Errorf("values template at %q: %w", dotted(path), err)
+		}
+		if whole {
+			return v, nil
+		}
+		text, err := scalarText(v)
+		if err != nil {
+			return nil, fmt.Errorf("values template at %q: %s: %w", dotted(path), expr, err)
+		}
+		b.WriteString(s[:i])
+		b.WriteString(text)
+		s = s[i+end+1:]
+	}
+	return b.String(), nil
+}
+
+func (e *valuesExpander) evaluate(expr string) (interface{}, error) {
+	kind, arg, ok := strings.Cut(expr, ":")
+	switch {
+	case ok && kind == "env":
+		name, def, hasDefault := strings.Cut(arg, ":-")
+		if name == "" {
+			return nil, fmt.Errorf("invalid expression %q: missing variable name", expr)
+		}
+		if v, ok := e.lookupEnv(name); ok {
+			return v, nil
+		}
+		if hasDefault {
+			return def, nil
+		}
+		return nil, fmt.Errorf("environment variable %q is not set", name)
+	case ok && kind == "values":
+		if arg == "" {
+			return nil, fmt.Errorf("invalid expression %q: missing key", expr)
+		}
+		return e.resolve(strings.Split(arg, "."))
+	}
+	return nil, fmt.Errorf("invalid expression %q: must be ${env:NAME} or ${values:key}", expr)
+}
+
+// resolve returns the expanded value at the path, storing it back so it is
+// not expanded again.
+func (e *valuesExpander) resolve(path []string) (interface{}, error) {
+	var parent interface{} = e.root
+	for i, k := range path {
+		var child interface{}
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			v, ok := p[k]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", dotted(path[:i+1]))
+			}
+			child = v
+		case []interface{}:
+			n, err := strconv.Atoi(k)
+			if err != nil || n < 0 || n >= len(p) {
+				return nil, fmt.Errorf("key %q not found", dotted(path[:i+1]))
+			}
+			child = p[n]
+		default:
+			return nil, fmt.Errorf("key %q not found", dotted(path[:i+1]))
+		}
+
+		// Only the referenced value is expanded as a whole. On the way to it,
+		// strings are expanded as they may reference the next map or list,
+		// but maps and lists are not as they may contain the reference.
+		_, isString := child.(string)
+		if i == len(path)-1 || isString {
+			v, err := e.expand(path[:i+1], child)
+			if err != nil {
+				return nil, err
+			}
+			switch p := parent.(type) {
+			case map[string]interface{}:
+				p[k] = v
+			case []interface{}:
+				n, _ := strconv.Atoi(k)
+				p[n] = v
+			}
+			child = v
+		}
+		parent = child
+	}
+	return parent, nil
+}
+
+func scalarText(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	case map[string]interface{}, []interface{}:
+		return "", fmt.Errorf("cannot insert a %T into a string", v)
+	}
+	return fmt.Sprint(v), nil
+}
+
+func appendPath(path []string, k string) []string {
+	return append(path[:len(path):len(path)], k)
+}
+
+func dotted(path []string) string {
+	return strings.Join(path, ".")
+}
@@ -0,0 +1,112 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package values
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestExpandTemplates(t *testing.T) {
+	env := map[string]string{"REGISTRY": "registry.example.com", "EMPTY": ""}
+	lookupEnv := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name   string
+		values string
+		expect string
+		err    string
+	}{
+		{
+			name:   "environment",
+			values: "image: ${env:REGISTRY}/app\ntag: ${env:TAG:-latest}\nempty: x${env:EMPTY:-unused}x",
+			expect: "empty: xx\nimage: registry.example.com/app\ntag: latest\n",
+		},
+		{
+			name:   "references keep their type",
+			values: "port: 8080\nreplicas: ${values:port}\nlabels: {app: web}\nselector: ${values:labels}",
+			expect: "labels:\n  app: web\nport: 8080\nreplicas: 8080\nselector:\n  app: web\n",
+		},
+		{
+			name:   "references are expanded first",
+			values: "url: http://${values:service.host}:${values:service.port}\nservice: {host: '${values:name}.svc', port: 80}\nname: web",
+			expect: "name: web\nservice:\n  host: web.svc\n  port: 80\nurl: http://web.svc:80\n",
+		},
+		{
+			name:   "references into lists and through references",
+			values: "hosts: [a, b]\nsecond: ${values:hosts.1}\nalias: ${values:nested}\nnested: {key: v}\nvia: ${values:alias.key}",
+			expect: "alias:\n  key: v\nhosts:\n- a\n- b\nnested:\n  key: v\nsecond: b\nvia: v\n",
+		},
+		{
+			name:   "escape",
+			values: "shell: $${HOME} and $${values:x}",
+			expect: "shell: ${HOME} and ${values:x}\n",
+		},
+		{
+			name:   "unset variable",
+			values: "a: {b: '${env:NOPE}'}",
+			err:    `values template at "a.b": environment variable "NOPE" is not set`,
+		},
+		{
+			name:   "missing key",
+			values: "a: ${values:b.c}\nb: {}",
+			err:    `values template at "a": key "b.c" not found`,
+		},
+		{
+			name:   "cycle",
+			values: "a: ${values:b}\nb: x${values:a}",
+			err:    "reference cycle",
+		},
+		{
+			name:   "map inside a string",
+			values: "a: x${values:b}\nb: {c: d}",
+			err:    `values template at "a": values:b: cannot insert a map[string]interface {} into a string`,
+		},
+		{
+			name:   "unknown expression",
+			values: "a: ${file:/etc/passwd}",
+			err:    `invalid expression "file:/etc/passwd"`,
+		},
+		{
+			name:   "unterminated",
+			values: "a: ${env:HOME",
+			err:    "unterminated expression",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var vals map[string]interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.values), &vals))
+
+			err := ExpandTemplates(vals, lookupEnv)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			out, err := yaml.Marshal(vals)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, string(out))
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const applyDesc = `
+This command installs a release, or upgrades it if it already exists.
+
+Applying is idempotent: if the chart and values are identical to the deployed
+release, nothing is changed and no new revision is recorded. The command
+reports how many resources of each kind were created, updated, left unchanged
+or deleted, which makes it suitable for reconciliation loops.
+
+Use '--dry-run' to report the pending changes without modifying the release
+or the cluster.
+`
+
+func newApplyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewApply(cfg)
+	valueOpts := &values.Options{}
+	var outfmt output.Format
+	var chartRefreshPolicy string
+
+	cmd := &cobra.Command{
+		Use:   "apply [RELEASE] [CHART]",
+		Short: "install or upgrade a release idempotently",
+		Long:  applyDesc,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return compListReleases(toComplete, args, cfg)
+			}
+			if len(args) == 1 {
+				return compListCharts(toComplete, true)
+			}
+			return noMoreArgsComp()
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.Namespace = settings.Namespace()
+
+			up := client.Upgrade
+			policy, err := action.ParseChartRefreshPolicy(chartRefreshPolicy)
+			if err != nil {
+				return err
+			}
+			up.ChartRefreshPolicy = policy
+			registryClient, err := newRegistryClient(up.CertFile, up.KeyFile, up.CaFile,
+				up.InsecureSkipTLSverify, up.PlainHTTP, up.Username, up.Password)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+			up.SetRegistryClient(registryClient)
+			client.Install.SetRegistryClient(registryClient)
+
+			// The install path shares the options bound to the upgrade flags.
+			in := client.Install
+			in.ChartPathOptions = up.ChartPathOptions
+			in.ServerSideApply = up.ServerSideApply != "false"
+			in.ForceConflicts = up.ForceConflicts
+			in.DisableHooks = up.DisableHooks
+			in.Timeout = up.Timeout
+			in.WaitStrategy = up.WaitStrategy
+			in.WaitForJobs = up.WaitForJobs
+			in.Description = up.Description
+			in.Labels = up.Labels
+			in.SkipSchemaValidation = up.SkipSchemaValidation
+			in.SkipPrerequisites = up.SkipPrerequisites
+			in.PostRenderer = up.PostRenderer
+
+			chartPath, err := up.LocateChart(args[1], settings)
+			if err != nil {
+				return err
+			}
+
+			vals, sources, err := valueOpts.MergeValuesWithSources(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			up.ValuesSources = sources
+			in.ValuesSources = sources
+
+			ch, err := up.LoadChart(chartPath)
+			if err != nil {
+				return err
+			}
+			if req := ch.Metadata.Dependencies; req != nil {
+				if err := action.CheckDependencies(ch, req); err != nil {
+					return fmt.Errorf("an error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies: %w", err)
+				}
+			}
+
+			res, err := client.Run(args[0], ch, vals)
+			if err != nil {
+				return fmt.Errorf("APPLY FAILED: %w", err)
+			}
+			return outfmt.Write(out, &applyPrinter{res})
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&client.Install.CreateNamespace, "create-namespace", false, "create the release namespace if not present")
+	f.BoolVar(&client.DryRun, "dry-run", false, "report the pending changes without modifying the release or the cluster")
+	f.StringVar(&chartRefreshPolicy, "chart-refresh-policy", string(action.ChartRefreshIfChanged), "must be \"always\", \"if-changed\" or \"never\". \"always\" upgrades the release even when the chart and values match the deployed release, \"never\" refuses to replace the deployed chart")
+	f.StringVar(&client.Upgrade.ServerSideApply, "server-side", "auto", "must be \"true\", \"false\" or \"auto\". Object updates run in the server instead of the client (\"auto\" defaults the value from the previous chart release's method)")
+	f.BoolVar(&client.Upgrade.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
+	addFieldManagerFlag(f, cfg)
+	f.BoolVar(&client.Upgrade.DisableHooks, "no-hooks", false, "disable pre/post install and upgrade hooks")
+	f.DurationVar(&client.Upgrade.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
+	f.BoolVar(&client.Upgrade.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
+	f.IntVar(&client.Upgrade.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	f.IntVar(&client.Upgrade.MaxFailedHistory, "history-max-failed", settings.MaxFailedHistory, "limit the number of failed and pending-rollback revisions saved per release, pruned before the other revisions. Use 0 for no separate limit")
+	f.BoolVar(&client.Upgrade.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
+	f.BoolVar(&client.Upgrade.SkipPrerequisites, "skip-prerequisites", false, "if set, the prerequisites in the requires section of Chart.yaml are not checked against the cluster")
+	f.StringToStringVarP(&client.Upgrade.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be separated by comma.")
+	f.StringVar(&client.Upgrade.Description, "description", "", "add a custom description")
+	addChartPathOptionsFlags(f, &client.Upgrade.ChartPathOptions)
+	addChartFilterFlags(f, &client.Upgrade.Filter)
+	addValueOptionsFlags(f, valueOpts)
+	bindOutputFlag(cmd, &outfmt)
+	bindPostRenderFlag(cmd, &client.Upgrade.PostRenderer, settings)
+	AddWaitFlag(cmd, &client.Upgrade.WaitStrategy)
+
+	return cmd
+}
+
+type applyPrinter struct {
+	result *action.ApplyResult
+}
+
+type applyOutput struct {
+	Name      string               `json:"name"`
+	Namespace string               `json:"namespace"`
+	Revision  int                  `json:"revision"`
+	Status    string               `json:"status"`
+	Operation string               `json:"operation"`
+	DryRun    bool                 `json:"dry_run"`
+	Changes   action.ChangeSummary `json:"changes"`
+}
+
+func (p *applyPrinter) output() applyOutput {
+	rel := p.result.Release
+	return applyOutput{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		Status:    rel.Info.Status.String(),
+		Operation: p.result.Operation,
+		DryRun:    p.result.DryRun,
+		Changes:   p.result.Changes,
+	}
+}
+
+func (p *applyPrinter) Kind() string {
+	return "ApplyResult"
+}
+
+func (p *applyPrinter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, p.output())
+}
+
+func (p *applyPrinter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, p.output())
+}
+
+func (p *applyPrinter) WriteTable(out io.Writer) error {
+	rel := p.result.Release
+	verb := "upgraded"
+	if p.result.Operation == action.ApplyOperationInstall {
+		verb = "installed"
+	}
+	switch {
+	case p.result.Operation == action.ApplyOperationNone:
+		fmt.Fprintf(out, "Release %q is unchanged at revision %d.\n", rel.Name, rel.Version)
+	case p.result.DryRun:
+		fmt.Fprintf(out, "Release %q would be %s (dry run).\n", rel.Name, verb)
+	default:
+		fmt.Fprintf(out, "Release %q has been %s to revision %d.\n", rel.Name, verb, rel.Version)
+	}
+
+	tbl := uitable.New()
+	tbl.AddRow("KIND", "CREATED", "UPDATED", "UNCHANGED", "DELETED")
+	for _, kind := range p.result.Changes.Kinds() {
+		c := p.result.Changes[kind]
+		tbl.AddRow(kind, c.Created, c.Updated, c.Unchanged, c.Deleted)
+	}
+	return output.EncodeTable(out, tbl)
+}
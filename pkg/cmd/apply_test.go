@@ -0,0 +1,53 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestApplyCmd(t *testing.T) {
+	chartPath := "testdata/testcharts/alpine"
+	ch, err := loader.Load(chartPath)
+	if err != nil {
+		t.Fatalf("Error loading chart: %v", err)
+	}
+
+	tests := []cmdTestCase{
+		{
+			name:   "apply a new release",
+			cmd:    "apply aeneas " + chartPath,
+			golden: "output/apply-install.txt",
+		},
+		{
+			name:   "apply an existing release with new values",
+			cmd:    "apply aeneas " + chartPath + " --set Name=other -o json",
+			golden: "output/apply-upgrade.json",
+			rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas", Chart: ch})},
+		},
+		{
+			name:   "apply an existing release in dry-run mode",
+			cmd:    "apply aeneas " + chartPath + " --set Name=other --dry-run",
+			golden: "output/apply-dry-run.txt",
+			rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "aeneas", Chart: ch})},
+		},
+	}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const chartConvertDesc = `
+Convert the metadata files of a chart directory from apiVersion v1 to v2 in
+place.
+
+The chart's apiVersion is changed to v2, the dependencies in requirements.yaml
+are moved to Chart.yaml and requirements.lock is rewritten as Chart.lock. A
+lock digest computed by Helm 2 is replaced by the digest of Helm 3, so the
+dependencies do not have to be updated again.
+
+Use --dry-run to print the diff of the conversion without making it. To also
+migrate the templates of a Helm 2 chart, use 'helm chart migrate'.
+`
+
+func newChartConvertCmd(out io.Writer) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "convert [CHART]",
+		Short: "convert a chart from apiVersion v1 to v2",
+		Long:  chartConvertDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			c, err := util.Convert(chartpath)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case c.Empty():
+				fmt.Fprintf(out, "Nothing to convert in %s\n", chartpath)
+				return nil
+			case dryRun:
+				fmt.Fprint(out, c.Diff())
+				return nil
+			}
+			if err := c.Apply(chartpath); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Converted %s:\n", chartpath)
+			for _, change := range c.Changes {
+				fmt.Fprintf(out, "  %s\n", change)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the diff of the conversion without making it")
+
+	return cmd
+}
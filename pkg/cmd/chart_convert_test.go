@@ -0,0 +1,80 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChartConvertCmd(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "legacy")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"Chart.yaml":        "apiVersion: v1\nname: legacy\nversion: 0.1.0\n",
+		"requirements.yaml": "dependencies:\n- name: db\n  version: 1.0.0\n  repository: https://example.com/charts\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, out, err := executeActionCommand("chart convert --dry-run " + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `--- a/Chart.yaml
++++ b/Chart.yaml
+@@ -1,3 +1,7 @@
+-apiVersion: v1
++apiVersion: v2
++dependencies:
++- name: db
++  repository: https://example.com/charts
++  version: 1.0.0
+ name: legacy
+ version: 0.1.0
+--- a/requirements.yaml
++++ b/requirements.yaml
+@@ -1,4 +0,0 @@
+-dependencies:
+-- name: db
+-  version: 1.0.0
+-  repository: https://example.com/charts
+`
+	if out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	if _, out, err = executeActionCommand("chart convert " + dir); err != nil {
+		t.Fatal(err)
+	}
+	expected = "Converted " + dir + ":\n  Chart.yaml: changed apiVersion to v2\n  requirements.yaml: moved 1 dependencies to Chart.yaml\n"
+	if out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	if _, out, err = executeActionCommand("chart convert " + dir); err != nil {
+		t.Fatal(err)
+	}
+	if out != "Nothing to convert in "+dir+"\n" {
+		t.Errorf("Unexpected output %q", out)
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const chartDiffDesc = `
+Show what changed between two charts (directories, files, URLs or charts in a
+repository or registry).
+
+The changed fields of Chart.yaml and default values are listed with their old
+and new values, followed by the unified diffs of the changed templates and
+files. Use --summary to only list the changed templates and files.
+
+'--version' selects the version of the first chart, and '--to-version' the
+version of the second chart:
+
+    $ helm chart diff myrepo/mychart myrepo/mychart --version 1.0.0 --to-version 1.1.0
+`
+
+type chartDiffWriter struct {
+	diff    *chartutil.ChartDiff
+	summary bool
+}
+
+func newChartDiffCmd(out io.Writer) *cobra.Command {
+	client := action.NewChartDiff()
+	var outfmt output.Format
+	var summary bool
+
+	cmd := &cobra.Command{
+		Use:   "diff CHART1 CHART2",
+		Short: "show the changes between two charts",
+		Long:  chartDiffDesc,
+		Args:  require.ExactArgs(2),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 1 {
+				return noMoreArgsComp()
+			}
+			return compListCharts(toComplete, true)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
+				client.InsecureSkipTLSverify, client.PlainHTTP, client.Username, client.Password)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+			client.SetRegistryClient(registryClient)
+
+			from, to, err := client.Locate(args[0], args[1], settings)
+			if err != nil {
+				return err
+			}
+			diff, err := client.Run(from, to)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &chartDiffWriter{diff: diff, summary: summary})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&client.ToVersion, "to-version", "", "specify a version constraint for the version of the second chart. If this is not specified, the latest version is used")
+	f.BoolVar(&summary, "summary", false, "only list the changed templates and files, without their diffs")
+	addChartPathOptionsFlags(f, &client.ChartPathOptions)
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+func (w *chartDiffWriter) WriteTable(out io.Writer) error {
+	if w.diff.Empty() {
+		_, _ = fmt.Fprintln(out, "The charts are identical")
+		return nil
+	}
+
+	writeFields := func(title string, changes []chartutil.FieldChange) {
+		if len(changes) == 0 {
+			return
+		}
+		_, _ = fmt.Fprintf(out, "%s:\n", title)
+		for _, c := range changes {
+			switch c.Type {
+			case chartutil.ChangeAdded:
+				_, _ = fmt.Fprintf(out, "  + %s: %s\n", c.Path, formatDiffValue(c.New))
+			case chartutil.ChangeRemoved:
+				_, _ = fmt.Fprintf(out, "  - %s: %s\n", c.Path, formatDiffValue(c.Old))
+			default:
+				_, _ = fmt.Fprintf(out, "  ~ %s: %s -> %s\n", c.Path, formatDiffValue(c.Old), formatDiffValue(c.New))
+			}
+		}
+	}
+	writeFiles := func(title string, changes []chartutil.FileChange) {
+		if len(changes) == 0 {
+			return
+		}
+		_, _ = fmt.Fprintf(out, "%s:\n", title)
+		for _, c := range changes {
+			_, _ = fmt.Fprintf(out, "  %s %s\n", map[chartutil.ChangeType]string{
+				chartutil.ChangeAdded:    "+",
+				chartutil.ChangeRemoved:  "-",
+				chartutil.ChangeModified: "~",
+			}[c.Type], c.Name)
+		}
+	}
+
+	writeFields("METADATA", w.diff.Metadata)
+	writeFields("VALUES", w.diff.Values)
+	writeFiles("TEMPLATES", w.diff.Templates)
+	writeFiles("FILES", w.diff.Files)
+
+	if w.summary {
+		return nil
+	}
+	for _, changes := range [][]chartutil.FileChange{w.diff.Templates, w.diff.Files} {
+		for _, c := range changes {
+			if c.Diff == "" {
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "\n%s", c.Diff)
+		}
+	}
+	return nil
+}
+
+func (w *chartDiffWriter) Kind() string {
+	return "ChartDiff"
+}
+
+func (w *chartDiffWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.diff)
+}
+
+func (w *chartDiffWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.diff)
+}
+
+// formatDiffValue formats a value of a Chart.yaml field or of a default value
+// on a single line.
+func formatDiffValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChartDiffCmd(t *testing.T) {
+	writeChart := func(version, replicas string) string {
+		dir := filepath.Join(t.TempDir(), "ahab")
+		if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		for name, data := range map[string]string{
+			"Chart.yaml":                "apiVersion: v2\nname: ahab\nversion: " + version + "\n",
+			"values.yaml":               "replicas: " + replicas + "\n",
+			"templates/deployment.yaml": "kind: Deployment\nreplicas: {{ .Values.replicas }}\n",
+		} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+	from, to := writeChart("1.0.0", "1"), writeChart("1.1.0", "2")
+
+	_, out, err := executeActionCommand("chart diff " + from + " " + to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "METADATA:\n  ~ version: \"1.0.0\" -> \"1.1.0\"\nVALUES:\n  ~ replicas: 1 -> 2\n"
+	if out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	if _, out, err = executeActionCommand("chart diff " + from + " " + from); err != nil {
+		t.Fatal(err)
+	}
+	if out != "The charts are identical\n" {
+		t.Errorf("Unexpected output %q", out)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const chartMigrateDesc = `
+Rewrite a chart directory from the Helm 2 layout to the apiVersion v2 layout.
+
+The chart's apiVersion is changed to v2, the dependencies in requirements.yaml
+are moved to Chart.yaml and requirements.lock is renamed to Chart.lock. CRDs
+installed with the crd-install hook, which Helm 3 removed, are moved from
+templates/ to crds/.
+
+Constructs that cannot be migrated automatically, such as templated CRDs and
+the .Release.Time object, are listed so they can be migrated by hand. Use
+--dry-run to see the changes without making them. 'helm lint' reports the
+legacy constructs left in a chart.
+`
+
+func newChartMigrateCmd(out io.Writer) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate [CHART]",
+		Short: "migrate a Helm 2 chart to the apiVersion v2 layout",
+		Long:  chartMigrateDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			m, err := util.Migrate(chartpath, dryRun)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case len(m.Changes) == 0:
+				fmt.Fprintf(out, "Nothing to migrate in %s\n", chartpath)
+			case dryRun:
+				fmt.Fprintf(out, "Would migrate %s:\n", chartpath)
+			default:
+				fmt.Fprintf(out, "Migrated %s:\n", chartpath)
+			}
+			for _, change := range m.Changes {
+				fmt.Fprintf(out, "  %s\n", change)
+			}
+			if len(m.Manual) > 0 {
+				fmt.Fprintln(out, "To migrate by hand:")
+				for _, manual := range m.Manual {
+					fmt.Fprintf(out, "  %s\n", manual)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes without making them")
+
+	return cmd
+}
@@ -0,0 +1,69 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChartMigrateCmd(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "legacy")
+	if err := os.MkdirAll(filepath.Join(dir, "templates"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string]string{
+		"Chart.yaml":               "apiVersion: v1\nname: legacy\nversion: 0.1.0\n",
+		"templates/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\ndata:\n  installed: {{ .Release.Time }}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, out, err := executeActionCommand("chart migrate --dry-run " + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Would migrate " + dir + ":\n  Chart.yaml: changed apiVersion to v2\nTo migrate by hand:\n  templates/configmap.yaml: .Release.Time was removed in Helm 3\n"
+	if out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	if _, out, err = executeActionCommand("chart migrate " + dir); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Migrated "+dir+":\n") {
+		t.Errorf("Unexpected output %q", out)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "apiVersion: v2") {
+		t.Errorf("Expected the chart to be migrated, got:\n%s", data)
+	}
+
+	if _, out, err = executeActionCommand("chart migrate " + dir); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, "Nothing to migrate in "+dir+"\n") {
+		t.Errorf("Unexpected output %q", out)
+	}
+}
@@ -19,10 +19,15 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"io/fs"
+	"log"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/pkg/chart/common"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cmd/require"
@@ -48,10 +53,30 @@ something like this:
 do not exist, Helm will attempt to create them as it goes. If the given
 destination exists and there are files in that directory, conflicting files
 will be overwritten, but other files will be left alone.
+
+By default, the chart runs a web application with a Deployment. Use
+'--starter-profile' to create a different kind of chart instead:
+
+    $ helm create mydb --starter-profile statefulset
+
+The built-in profiles are:
+
+%s
+Use '--starter' to create a chart from your own starter chart instead.
+
+Use '--from-manifests' to turn existing Kubernetes manifests into a chart. Each
+object in the YAML or JSON files of the given file or directory becomes a
+template. Object names are prefixed with the release name, the common labels of
+the chart are added, and the replicas and container images of workloads are
+moved into values.yaml:
+
+    $ helm create myapp --from-manifests ./k8s/
 `
 
 type createOptions struct {
 	starter    string // --starter
+	profile    string // --starter-profile
+	manifests  string // --from-manifests
 	name       string
 	starterDir string
 }
@@ -62,7 +87,7 @@ func newCreateCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create NAME",
 		Short: "create a new chart with the given name",
-		Long:  createDesc,
+		Long:  fmt.Sprintf(createDesc, profileList()),
 		Args:  require.ExactArgs(1),
 		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
 			if len(args) == 0 {
@@ -81,10 +106,49 @@ func newCreateCmd(out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&o.starter, "starter", "p", "", "the name or absolute path to Helm starter scaffold")
+	cmd.Flags().StringVar(&o.profile, "starter-profile", chartutil.DefaultProfile, "the built-in profile to scaffold the chart from")
+	cmd.Flags().StringVar(&o.manifests, "from-manifests", "", "the path to a file or directory of Kubernetes manifests to create the chart from")
+	cmd.MarkFlagsMutuallyExclusive("starter", "starter-profile", "from-manifests")
+
+	err := cmd.RegisterFlagCompletionFunc("starter-profile", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for _, p := range chartutil.Profiles() {
+			names = append(names, fmt.Sprintf("%s\t%s", p.Name, p.Description))
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return cmd
 }
 
+// profileList lists the built-in profiles for the help text.
+func profileList() string {
+	var b strings.Builder
+	for _, p := range chartutil.Profiles() {
+		fmt.Fprintf(&b, "- %s: %s\n", p.Name, p.Description)
+	}
+	return b.String()
+}
+
 func (o *createOptions) run(out io.Writer) error {
+	if o.manifests != "" {
+		manifests, err := readManifests(o.manifests)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Creating %s\n", o.name)
+		chartutil.Stderr = out
+		_, err = chartutil.CreateFromManifests(o.name, manifests)
+		return err
+	}
+	if o.starter == "" {
+		if _, err := chartutil.LookupProfile(o.profile); err != nil {
+			return err
+		}
+	}
 	fmt.Fprintf(out, "Creating %s\n", o.name)
 
 	chartname := filepath.Base(o.name)
@@ -108,6 +172,40 @@ func (o *createOptions) run(out io.Writer) error {
 	}
 
 	chartutil.Stderr = out
-	_, err := chartutil.Create(chartname, filepath.Dir(o.name))
+	_, err := chartutil.CreateWithProfile(chartname, filepath.Dir(o.name), o.profile)
 	return err
 }
+
+// readManifests reads the YAML and JSON files in path, a file or a directory
+// searched recursively.
+func readManifests(path string) ([]*common.File, error) {
+	var manifests []*common.File
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(p) {
+		case ".yaml", ".yml", ".json":
+		default:
+			if p != path {
+				return nil
+			}
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, &common.File{Name: p, Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no YAML or JSON files found in %s", path)
+	}
+	return manifests, nil
+}
@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"helm.sh/helm/v4/internal/test/ensure"
@@ -59,6 +61,78 @@ func TestCreateCmd(t *testing.T) {
 	}
 }
 
+func TestCreateProfileCmd(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ensure.HelmHome(t)
+	cname := "testchart"
+
+	if _, _, err := executeActionCommand("create --starter-profile cronjob " + cname); err != nil {
+		t.Fatalf("Failed to run create: %s", err)
+	}
+
+	c, err := loader.LoadDir(cname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Values["schedule"]; !ok {
+		t.Errorf("Expected the values of the cronjob profile, got %v", c.Values)
+	}
+
+	_, _, err = executeActionCommand("create --starter-profile unknown other")
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "unknown"`) {
+		t.Errorf("Expected an unknown profile error, got %v", err)
+	}
+	if _, err := os.Stat("other"); !os.IsNotExist(err) {
+		t.Errorf("Expected no chart to be created for an unknown profile")
+	}
+
+	_, _, err = executeActionCommand("create --starter-profile cronjob --starter mystarter other")
+	if err == nil {
+		t.Error("Expected an error using both --starter and --starter-profile")
+	}
+}
+
+func TestCreateFromManifestsCmd(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ensure.HelmHome(t)
+	cname := "testchart"
+
+	os.MkdirAll(filepath.Join("manifests", "nested"), 0o755)
+	deployment := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 2\n  template:\n    spec:\n      containers:\n        - name: app\n          image: nginx:1.25\n"
+	service := `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}`
+	os.WriteFile(filepath.Join("manifests", "deployment.yaml"), []byte(deployment), 0o644)
+	os.WriteFile(filepath.Join("manifests", "nested", "service.json"), []byte(service), 0o644)
+	os.WriteFile(filepath.Join("manifests", "README.md"), []byte("# not a manifest"), 0o644)
+
+	if _, _, err := executeActionCommand("create --from-manifests manifests " + cname); err != nil {
+		t.Fatalf("Failed to run create: %s", err)
+	}
+
+	c, err := loader.LoadDir(cname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var templates []string
+	for _, tpl := range c.Templates {
+		templates = append(templates, tpl.Name)
+	}
+	for _, want := range []string{"templates/_helpers.tpl", "templates/web-deployment.yaml", "templates/web-service.yaml"} {
+		if !slices.Contains(templates, want) {
+			t.Errorf("Expected template %s, got %v", want, templates)
+		}
+	}
+
+	_, _, err = executeActionCommand("create --from-manifests " + filepath.Join("manifests", "nested", "missing") + " other")
+	if err == nil {
+		t.Error("Expected an error for a missing manifests path")
+	}
+
+	_, _, err = executeActionCommand("create --from-manifests manifests --starter-profile cronjob other")
+	if err == nil {
+		t.Error("Expected an error using both --from-manifests and --starter-profile")
+	}
+}
+
 func TestCreateStarterCmd(t *testing.T) {
 	t.Chdir(t.TempDir())
 	ensure.HelmHome(t)
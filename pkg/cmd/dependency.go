@@ -16,6 +16,7 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"path/filepath"
 
@@ -23,7 +24,9 @@ import (
 	"github.com/spf13/pflag"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
 )
 
 const dependencyDesc = `
@@ -85,7 +88,7 @@ This will produce an error if the chart cannot be loaded.
 
 func newDependencyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "dependency update|build|list",
+		Use:     "dependency update|build|list|status|graph|vendor|prefetch",
 		Aliases: []string{"dep", "dependencies"},
 		Short:   "manage a chart's dependencies",
 		Long:    dependencyDesc,
@@ -95,12 +98,22 @@ func newDependencyCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	cmd.AddCommand(newDependencyListCmd(out))
 	cmd.AddCommand(newDependencyUpdateCmd(cfg, out))
 	cmd.AddCommand(newDependencyBuildCmd(out))
+	cmd.AddCommand(newDependencyStatusCmd(out))
+	cmd.AddCommand(newDependencyGraphCmd(out))
+	cmd.AddCommand(newDependencyVendorCmd(out))
+	cmd.AddCommand(newDependencyPrefetchCmd(out))
 
 	return cmd
 }
 
+type dependencyListWriter struct {
+	client    *action.Dependency
+	chartpath string
+}
+
 func newDependencyListCmd(out io.Writer) *cobra.Command {
 	client := action.NewDependency()
+	var outfmt output.Format
 	cmd := &cobra.Command{
 		Use:     "list CHART",
 		Aliases: []string{"ls"},
@@ -112,16 +125,41 @@ func newDependencyListCmd(out io.Writer) *cobra.Command {
 			if len(args) > 0 {
 				chartpath = filepath.Clean(args[0])
 			}
-			return client.List(chartpath, out)
+			return outfmt.Write(out, &dependencyListWriter{client, chartpath})
 		},
 	}
 
 	f := cmd.Flags()
 
 	f.UintVar(&client.ColumnWidth, "max-col-width", 80, "maximum column width for output table")
+	bindOutputFlag(cmd, &outfmt)
 	return cmd
 }
 
+func (w *dependencyListWriter) WriteTable(out io.Writer) error {
+	return w.client.List(w.chartpath, out)
+}
+
+func (w *dependencyListWriter) Kind() string {
+	return "DependencyList"
+}
+
+func (w *dependencyListWriter) WriteJSON(out io.Writer) error {
+	deps, err := w.client.ListDependencies(w.chartpath)
+	if err != nil {
+		return err
+	}
+	return output.EncodeJSON(out, deps)
+}
+
+func (w *dependencyListWriter) WriteYAML(out io.Writer) error {
+	deps, err := w.client.ListDependencies(w.chartpath)
+	if err != nil {
+		return err
+	}
+	return output.EncodeYAML(out, deps)
+}
+
 func addDependencySubcommandFlags(f *pflag.FlagSet, client *action.Dependency) {
 	f.BoolVar(&client.Verify, "verify", false, "verify the packages against signatures")
 	f.StringVar(&client.Keyring, "keyring", defaultKeyring(), "keyring containing public keys")
@@ -134,3 +172,21 @@ func addDependencySubcommandFlags(f *pflag.FlagSet, client *action.Dependency) {
 	f.BoolVar(&client.PlainHTTP, "plain-http", false, "use insecure HTTP connections for the chart download")
 	f.StringVar(&client.CaFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
 }
+
+// addDependencyDownloadFlags adds the flags of the dependency subcommands which
+// download the dependencies into charts/.
+func addDependencyDownloadFlags(f *pflag.FlagSet, client *action.Dependency) {
+	f.BoolVar(&client.Offline, "offline", false, "resolve and fetch the dependencies only from the dependency cache populated by 'helm dependency prefetch'")
+	f.IntVar(&client.Parallelism, "parallelism", downloader.DefaultParallelism, "number of dependencies downloaded at the same time")
+	f.IntVar(&client.Retries, "retries", 2, "number of times a failed download is retried")
+}
+
+// dependencyProgress returns a function reporting the finished downloads of
+// the dependencies.
+func dependencyProgress(out io.Writer) downloader.DownloadProgressFunc {
+	return func(p downloader.DownloadProgress) {
+		if p.Phase == downloader.DownloadDone {
+			fmt.Fprintf(out, "Downloaded %s %s (%d/%d)\n", p.Name, p.Version, p.Done, p.Total)
+		}
+	}
+}
@@ -71,6 +71,10 @@ func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 				RepositoryCache:  settings.RepositoryCache,
 				ContentCache:     settings.ContentCache,
 				Debug:            settings.Debug,
+				Offline:          client.Offline,
+				Parallelism:      client.Parallelism,
+				Retries:          client.Retries,
+				Progress:         dependencyProgress(out),
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyIfPossible
@@ -85,6 +89,7 @@ func newDependencyBuildCmd(out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	addDependencySubcommandFlags(f, client)
+	addDependencyDownloadFlags(f, client)
 
 	return cmd
 }
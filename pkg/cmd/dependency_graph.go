@@ -0,0 +1,112 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const dependencyGraphDesc = `
+Draw the graph of the dependencies of a chart and of its dependencies.
+
+Every dependency is resolved to the chart in the charts/ directory it refers
+to, so the graph shows the versions that would be installed, with the version
+ranges, aliases, conditions and tags of Chart.yaml on the edges. A chart used
+by several charts appears once. Dependencies whose chart is missing from the
+charts/ directory are drawn dashed; run 'helm dependency build' first to
+include them and their own dependencies.
+
+The graph is written in the DOT language of Graphviz, or as a Mermaid flowchart
+with --format mermaid:
+
+    $ helm dependency graph mychart | dot -Tsvg > dependencies.svg
+
+With --output json or yaml, the nodes and edges of the graph are written
+instead.
+`
+
+const (
+	graphFormatDOT     = "dot"
+	graphFormatMermaid = "mermaid"
+)
+
+func newDependencyGraphCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var outfmt output.Format
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "graph CHART",
+		Short: "draw the dependency graph of a chart",
+		Long:  dependencyGraphDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if format != graphFormatDOT && format != graphFormatMermaid {
+				return fmt.Errorf("invalid format %q: must be %s or %s", format, graphFormatDOT, graphFormatMermaid)
+			}
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			g, err := client.Graph(chartpath)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &dependencyGraphWriter{graph: g, format: format})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&format, "format", graphFormatDOT, fmt.Sprintf("format of the drawing of the graph. Allowed values: %s, %s", graphFormatDOT, graphFormatMermaid))
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+type dependencyGraphWriter struct {
+	graph  *chartutil.DependencyGraph
+	format string
+}
+
+func (w *dependencyGraphWriter) WriteTable(out io.Writer) error {
+	drawing := w.graph.DOT()
+	if w.format == graphFormatMermaid {
+		drawing = w.graph.Mermaid()
+	}
+	_, err := io.WriteString(out, drawing)
+	return err
+}
+
+func (w *dependencyGraphWriter) Kind() string {
+	return "DependencyGraph"
+}
+
+func (w *dependencyGraphWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.graph)
+}
+
+func (w *dependencyGraphWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.graph)
+}
@@ -0,0 +1,43 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestDependencyGraphCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "dot",
+		cmd:    "dependency graph testdata/testcharts/chart-missing-deps",
+		golden: "output/dependency-graph.txt",
+	}, {
+		name:   "mermaid",
+		cmd:    "dependency graph testdata/testcharts/chart-missing-deps --format mermaid",
+		golden: "output/dependency-graph-mermaid.txt",
+	}, {
+		name:   "json",
+		cmd:    "dependency graph testdata/testcharts/chart-missing-deps -o json",
+		golden: "output/dependency-graph-json.txt",
+	}, {
+		name:      "invalid format",
+		cmd:       "dependency graph testdata/testcharts/chart-missing-deps --format svg",
+		golden:    "output/dependency-graph-invalid-format.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const dependencyPrefetchDesc = `
+Download the dependencies of a chart into the dependency cache.
+
+Prefetch fetches the dependency versions of the Chart.lock file and the newest
+versions satisfying the constraints of Chart.yaml, and stores them in the
+content cache along with an index of the cached versions of each repository.
+
+The cache can then be used by 'helm dependency update --offline' and
+'helm dependency build --offline' in restricted networks, by copying the
+content cache directory (see 'helm env HELM_CONTENT_CACHE') to the machines
+which cannot reach the repositories.
+`
+
+func newDependencyPrefetchCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+
+	cmd := &cobra.Command{
+		Use:   "prefetch CHART",
+		Short: "download the dependencies of a chart into the dependency cache",
+		Long:  dependencyPrefetchDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
+				client.InsecureSkipTLSverify, client.PlainHTTP, client.Username, client.Password)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+
+			man := &downloader.Manager{
+				Out:              out,
+				ChartPath:        chartpath,
+				Keyring:          client.Keyring,
+				SkipUpdate:       client.SkipRefresh,
+				Getters:          getter.All(settings),
+				RegistryClient:   registryClient,
+				RepositoryConfig: settings.RepositoryConfig,
+				RepositoryCache:  settings.RepositoryCache,
+				ContentCache:     settings.ContentCache,
+				Debug:            settings.Debug,
+			}
+			if client.Verify {
+				man.Verify = downloader.VerifyAlways
+			}
+			err = man.Prefetch()
+			if e, ok := err.(downloader.ErrRepoNotFound); ok {
+				return fmt.Errorf("%s. Please add the missing repos via 'helm repo add'", e.Error())
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	addDependencySubcommandFlags(f, client)
+
+	return cmd
+}
@@ -0,0 +1,111 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"path/filepath"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const dependencyStatusDesc = `
+Report which dependencies of a chart are enabled for the given values, and why.
+
+Dependencies can be enabled or disabled by the 'condition' and 'tags' fields
+in Chart.yaml. A condition set to a boolean in the values takes precedence over
+tags, and a dependency without either is enabled by default. This command
+evaluates them the same way installing the chart would, including for the
+dependencies of enabled dependencies, without rendering any templates:
+
+    $ helm dependency status mychart -f production.yaml
+    DEPENDENCY          CHART       VERSION ENABLED SOURCE      REASON
+    redis               redis       ^18.0.0 true    condition   condition "redis.enabled" is true
+    postgresql          postgresql  ^15.0.0 false   tags        tag "database" is false
+
+Dependencies whose chart is missing from the charts/ directory are reported,
+but their own dependencies cannot be checked. Run 'helm dependency build'
+first to include them.
+`
+
+func newDependencyStatusCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	valueOpts := &values.Options{}
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "status CHART",
+		Short: "show which dependencies are enabled for the given values",
+		Long:  dependencyStatusDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+			statuses, err := client.Status(chartpath, vals)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, dependencyStatusWriter(statuses))
+		},
+	}
+
+	f := cmd.Flags()
+	addValueOptionsFlags(f, valueOpts)
+	bindOutputFlag(cmd, &outfmt)
+	return cmd
+}
+
+type dependencyStatusWriter []chartutil.DependencyStatus
+
+func (w dependencyStatusWriter) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.AddRow("DEPENDENCY", "CHART", "VERSION", "ENABLED", "SOURCE", "REASON")
+	for _, s := range w {
+		reason := s.Reason
+		if s.Missing {
+			reason += " (chart missing from charts/)"
+		}
+		tbl.AddRow(s.Path, s.Chart, s.Version, s.Enabled, s.Source, reason)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (w dependencyStatusWriter) Kind() string {
+	return "DependencyStatusList"
+}
+
+func (w dependencyStatusWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, []chartutil.DependencyStatus(w))
+}
+
+func (w dependencyStatusWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, []chartutil.DependencyStatus(w))
+}
@@ -48,10 +48,35 @@ func TestDependencyListCmd(t *testing.T) {
 			name:   "Dependencies in chart archive",
 			cmd:    "dependency list testdata/testcharts/reqtest-0.1.0.tgz",
 			golden: "output/dependency-list-archive.txt",
+		}, {
+			name:   "Dependencies in chart dir as JSON",
+			cmd:    "dependency list testdata/testcharts/reqtest -o json",
+			golden: "output/dependency-list.json",
+		}, {
+			name:   "Dependencies in chart dir as versioned YAML",
+			cmd:    "dependency list testdata/testcharts/reqtest -o yaml/v1",
+			golden: "output/dependency-list-v1.yaml",
 		}}
 	runTestCmd(t, tests)
 }
 
+func TestDependencyStatusCmd(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:   "Dependencies enabled by default",
+		cmd:    "dependency status testdata/testcharts/subchart",
+		golden: "output/dependency-status.txt",
+	}, {
+		name:   "Dependencies disabled by values",
+		cmd:    "dependency status testdata/testcharts/subchart --set subcharta.enabled=false --set tags.front-end=false -o yaml",
+		golden: "output/dependency-status.yaml",
+	}, {
+		name:   "Dependency missing from charts directory",
+		cmd:    "dependency status testdata/testcharts/chart-missing-deps -o json",
+		golden: "output/dependency-status-missing.json",
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestDependencyFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "dependency", false)
 }
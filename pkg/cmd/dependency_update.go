@@ -41,11 +41,26 @@ rebuild the dependencies to an exact version.
 Dependencies are not required to be represented in 'Chart.yaml'. For that
 reason, an update command will not remove charts unless they are (a) present
 in the Chart.yaml file, but (b) at the wrong version.
+
+The '--bump' flag first raises the version constraints of the repository
+dependencies in 'Chart.yaml' to newer releases, then regenerates the lock file:
+
+    patch              the newest release with the same major and minor version
+    minor              the newest release with the same major version
+    latest-compatible  the newest release whose chart API version is supported
+
+Exact versions stay exact, and '~' and '^' ranges keep their operator. Other
+ranges are replaced with a '~' range for patch bumps and a '^' range otherwise.
+
+With '--offline', the dependencies are resolved and fetched only from the
+dependency cache populated by 'helm dependency prefetch', without accessing
+any repository or registry.
 `
 
 // newDependencyUpdateCmd creates a new dependency update command.
 func newDependencyUpdateCmd(_ *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewDependency()
+	var bump string
 
 	cmd := &cobra.Command{
 		Use:     "update CHART",
@@ -75,6 +90,11 @@ func newDependencyUpdateCmd(_ *action.Configuration, out io.Writer) *cobra.Comma
 				RepositoryCache:  settings.RepositoryCache,
 				ContentCache:     settings.ContentCache,
 				Debug:            settings.Debug,
+				Bump:             downloader.BumpStrategy(bump),
+				Offline:          client.Offline,
+				Parallelism:      client.Parallelism,
+				Retries:          client.Retries,
+				Progress:         dependencyProgress(out),
 			}
 			if client.Verify {
 				man.Verify = downloader.VerifyAlways
@@ -85,6 +105,12 @@ func newDependencyUpdateCmd(_ *action.Configuration, out io.Writer) *cobra.Comma
 
 	f := cmd.Flags()
 	addDependencySubcommandFlags(f, client)
+	addDependencyDownloadFlags(f, client)
+	f.StringVar(&bump, "bump", "", "raise the version constraints in Chart.yaml before updating: patch, minor or latest-compatible")
+
+	cmd.RegisterFlagCompletionFunc("bump", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"latest-compatible", "minor", "patch"}, cobra.ShellCompDirectiveNoFileComp
+	})
 
 	return cmd
 }
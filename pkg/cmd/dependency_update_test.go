@@ -81,6 +81,9 @@ func TestDependencyUpdateCmd(t *testing.T) {
 	if !strings.Contains(out, `update from the "test" chart repository`) {
 		t.Errorf("Repo did not get updated\n%s", out)
 	}
+	if !strings.Contains(out, "Downloaded reqtest 0.1.0 (") {
+		t.Errorf("Download progress was not reported\n%s", out)
+	}
 
 	// Make sure the actual file got downloaded.
 	expect := dir(chartname, "charts/reqtest-0.1.0.tgz")
@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/downloader"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const dependencyVendorDesc = `
+Unpack the dependencies of a chart into directories under charts/.
+
+Vendor fetches the dependencies like 'helm dependency build' does, then unpacks
+each one into charts/<name>/ instead of keeping its .tgz archive, so the
+dependencies can be patched locally. The origin of the vendored dependencies
+and the digests of their archives and files are recorded in charts/.vendor.yaml.
+
+Vendored dependencies at the version of the Chart.lock file are left as they
+are, local changes included. When a dependency moves to another version, its
+directory is only replaced if it has no local changes, unless --overwrite is
+set. With --overwrite, all the vendored dependencies are fetched and unpacked
+again.
+`
+
+func newDependencyVendorCmd(out io.Writer) *cobra.Command {
+	client := action.NewDependency()
+	var overwrite bool
+
+	cmd := &cobra.Command{
+		Use:   "vendor CHART",
+		Short: "unpack the dependencies of a chart into editable directories",
+		Long:  dependencyVendorDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
+				client.InsecureSkipTLSverify, client.PlainHTTP, client.Username, client.Password)
+			if err != nil {
+				return fmt.Errorf("missing registry client: %w", err)
+			}
+
+			man := &downloader.Manager{
+				Out:              out,
+				ChartPath:        chartpath,
+				Keyring:          client.Keyring,
+				SkipUpdate:       client.SkipRefresh,
+				Getters:          getter.All(settings),
+				RegistryClient:   registryClient,
+				RepositoryConfig: settings.RepositoryConfig,
+				RepositoryCache:  settings.RepositoryCache,
+				ContentCache:     settings.ContentCache,
+				Debug:            settings.Debug,
+				Overwrite:        overwrite,
+				Offline:          client.Offline,
+				Parallelism:      client.Parallelism,
+				Retries:          client.Retries,
+				Progress:         dependencyProgress(out),
+			}
+			if client.Verify {
+				man.Verify = downloader.VerifyIfPossible
+			}
+			err = man.Vendor()
+			if e, ok := err.(downloader.ErrRepoNotFound); ok {
+				return fmt.Errorf("%s. Please add the missing repos via 'helm repo add'", e.Error())
+			}
+			return err
+		},
+	}
+
+	f := cmd.Flags()
+	addDependencySubcommandFlags(f, client)
+	addDependencyDownloadFlags(f, client)
+	f.BoolVar(&overwrite, "overwrite", false, "replace the vendored dependencies, even those with local changes")
+
+	return cmd
+}
@@ -17,20 +17,41 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"sort"
+	"strings"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/storage"
 )
 
 var envHelp = `
 Env prints out all the environment information in use by Helm.
+
+With --storage-stats, it prints statistics on the releases stored in the
+namespace instead, or in all namespaces with --all-namespaces: the number of
+releases and revisions, the size of their stored payloads, the largest
+releases, and how many revisions the releases of each namespace have. They
+help to spot the releases whose history grows too large before the Kubernetes
+API server suffers from it; see 'helm gc' and the --history-max flag to trim
+it.
 `
 
-func newEnvCmd(out io.Writer) *cobra.Command {
+func newEnvCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	var (
+		storageStats  bool
+		allNamespaces bool
+		outfmt        output.Format
+	)
+
 	cmd := &cobra.Command{
 		Use:   "env",
 		Short: "helm client environment information",
@@ -44,7 +65,23 @@ func newEnvCmd(out io.Writer) *cobra.Command {
 
 			return noMoreArgsComp()
 		},
-		Run: func(_ *cobra.Command, args []string) {
+		RunE: func(_ *cobra.Command, args []string) error {
+			if storageStats {
+				if len(args) > 0 {
+					return errors.New("--storage-stats does not take an environment variable")
+				}
+				if allNamespaces {
+					if err := cfg.Init(settings.RESTClientGetter(), "", os.Getenv("HELM_DRIVER")); err != nil {
+						return err
+					}
+				}
+				stats, err := cfg.Releases.Stats()
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &storageStatsWriter{stats})
+			}
+
 			envVars := settings.EnvVars()
 
 			if len(args) == 0 {
@@ -58,11 +95,86 @@ func newEnvCmd(out io.Writer) *cobra.Command {
 			} else {
 				fmt.Fprintf(out, "%s\n", envVars[args[0]])
 			}
+			return nil
 		},
 	}
+
+	f := cmd.Flags()
+	f.BoolVar(&storageStats, "storage-stats", false, "print statistics on the releases stored in the namespace, such as their number, size and revisions, instead of the environment")
+	f.BoolVarP(&allNamespaces, "all-namespaces", "A", false, "with --storage-stats, print the statistics of the releases of all namespaces")
+	bindOutputFlag(cmd, &outfmt)
+
 	return cmd
 }
 
+type storageStatsWriter struct {
+	stats *storage.Stats
+}
+
+func (w *storageStatsWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("DRIVER", "RELEASES", "REVISIONS", "SIZE (BYTES)")
+	table.AddRow(w.stats.Driver, w.stats.Releases, w.stats.Revisions, w.stats.Size)
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+	if w.stats.Releases == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(out, "\nLARGEST RELEASES:")
+	table = uitable.New()
+	table.AddRow("NAME", "NAMESPACE", "REVISIONS", "SIZE (BYTES)")
+	for _, r := range w.stats.Largest {
+		table.AddRow(r.Name, r.Namespace, r.Revisions, r.Size)
+	}
+	if err := output.EncodeTable(out, table); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "\nNAMESPACES:")
+	table = uitable.New()
+	table.AddRow("NAMESPACE", "RELEASES", "REVISIONS", "SIZE (BYTES)", "MAX REVISIONS", "RELEASES BY REVISIONS")
+	for _, ns := range w.stats.Namespaces {
+		table.AddRow(ns.Namespace, ns.Releases, ns.Revisions, ns.Size, ns.MaxRevisions, formatDistribution(ns.Distribution))
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *storageStatsWriter) Kind() string {
+	return "StorageStats"
+}
+
+func (w *storageStatsWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.stats)
+}
+
+func (w *storageStatsWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.stats)
+}
+
+// formatDistribution formats the non-empty ranges of a distribution of
+// releases by number of revisions, such as "1:3 6-10:1 >50:1".
+func formatDistribution(buckets []storage.RevisionBucket) string {
+	var ranges []string
+	for _, b := range buckets {
+		if b.Releases == 0 {
+			continue
+		}
+		var r string
+		switch {
+		case b.Max == 0:
+			r = fmt.Sprintf(">%d", b.Min-1)
+		case b.Min == b.Max:
+			r = fmt.Sprint(b.Min)
+		default:
+			r = fmt.Sprintf("%d-%d", b.Min, b.Max)
+		}
+		ranges = append(ranges, fmt.Sprintf("%s:%d", r, b.Releases))
+	}
+	return strings.Join(ranges, " ")
+}
+
 func getSortedEnvVarKeys() []string {
 	envVars := settings.EnvVars()
 
@@ -18,6 +18,8 @@ package cmd
 
 import (
 	"testing"
+
+	release "helm.sh/helm/v4/pkg/release/v1"
 )
 
 func TestEnv(t *testing.T) {
@@ -29,6 +31,44 @@ func TestEnv(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestEnvStorageStats(t *testing.T) {
+	mk := func(name string, vers int, status release.Status) *release.Release {
+		return release.Mock(&release.MockReleaseOptions{
+			Name:    name,
+			Version: vers,
+			Status:  status,
+		})
+	}
+	rels := []*release.Release{
+		mk("angry-bird", 3, release.StatusDeployed),
+		mk("angry-bird", 2, release.StatusSuperseded),
+		mk("angry-bird", 1, release.StatusSuperseded),
+		mk("happy-bird", 1, release.StatusDeployed),
+	}
+
+	tests := []cmdTestCase{{
+		name:   "print the storage statistics",
+		cmd:    "env --storage-stats",
+		rels:   rels,
+		golden: "output/env-storage-stats.txt",
+	}, {
+		name:   "print the storage statistics in json",
+		cmd:    "env --storage-stats --output json",
+		rels:   rels,
+		golden: "output/env-storage-stats.json",
+	}, {
+		name:   "print the storage statistics without releases",
+		cmd:    "env --storage-stats",
+		golden: "output/env-storage-stats-empty.txt",
+	}, {
+		name:      "print the storage statistics with a variable",
+		cmd:       "env --storage-stats HELM_BIN",
+		wantError: true,
+		golden:    "output/env-storage-stats-args.txt",
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestEnvFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "env", false)
 	checkFileCompletion(t, "env HELM_BIN", false)
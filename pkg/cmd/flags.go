@@ -23,7 +23,9 @@ import (
 	"log/slog"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -31,6 +33,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
@@ -44,15 +47,30 @@ const (
 	outputFlag         = "output"
 	postRenderFlag     = "post-renderer"
 	postRenderArgsFlag = "post-renderer-args"
+
+	postRenderPipelineFlag   = "post-renderer-pipeline"
+	postRenderCaptureDirFlag = "post-renderer-capture-dir"
 )
 
 func addValueOptionsFlags(f *pflag.FlagSet, v *values.Options) {
-	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL (can specify multiple)")
+	f.StringSliceVarP(&v.ValueFiles, "values", "f", []string{}, "specify values in a YAML file or a URL, such as https:// or oci://, optionally pinned with #sha256=<digest> (can specify multiple)")
 	f.StringArrayVar(&v.Values, "set", []string{}, "set values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.StringValues, "set-string", []string{}, "set STRING values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&v.IntValues, "set-int", []string{}, "set INTEGER values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&v.BoolValues, "set-bool", []string{}, "set BOOLEAN values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
+	f.StringArrayVar(&v.FloatValues, "set-float", []string{}, "set FLOAT values on the command line (can specify multiple or separate values with commas: key1=val1,key2=val2)")
 	f.StringArrayVar(&v.FileValues, "set-file", []string{}, "set values from respective files specified via the command line (can specify multiple or separate values with commas: key1=path1,key2=path2)")
 	f.StringArrayVar(&v.JSONValues, "set-json", []string{}, "set JSON values on the command line (can specify multiple or separate values with commas: key1=jsonval1,key2=jsonval2 or using json format: {\"key1\": jsonval1, \"key2\": \"jsonval2\"})")
 	f.StringArrayVar(&v.LiteralValues, "set-literal", []string{}, "set a literal STRING value on the command line")
+	f.StringVar((*string)(&v.EnvExpansion), "values-env", "", "expand the ${NAME} references to environment variables in the values files, never in the chart. Unset variables expand to empty strings with --values-env or --values-env=lenient, and fail with --values-env=strict")
+	f.Lookup("values-env").NoOptDefVal = string(values.EnvExpansionLenient)
+	f.BoolVar(&v.Template, "values-template", false, "expand ${env:NAME}, ${env:NAME:-default} and ${values:key.path} expressions in the values, except those given with --set-literal")
+}
+
+// addValuesPatchFlag adds the --values-patch flag to the commands applying
+// JSON Patches to the values coalesced with those of the chart.
+func addValuesPatchFlag(f *pflag.FlagSet, v *values.Options) {
+	f.StringArrayVar(&v.Patches, "values-patch", []string{}, "apply an RFC 6902 JSON Patch file to the values coalesced with those of the chart (can specify multiple)")
 }
 
 func AddWaitFlag(cmd *cobra.Command, wait *kube.WaitStrategy) {
@@ -65,6 +83,57 @@ func AddWaitFlag(cmd *cobra.Command, wait *kube.WaitStrategy) {
 	cmd.Flags().Lookup("wait").NoOptDefVal = string(kube.StatusWatcherStrategy)
 }
 
+// addFieldManagerFlag adds the --field-manager flag, setting the field manager
+// resources are created and updated with.
+func addFieldManagerFlag(f *pflag.FlagSet, cfg *action.Configuration) {
+	f.StringVar(&cfg.FieldManager, "field-manager", "", "name of the field manager owning the fields applied to resources. Defaults to the name of the program")
+}
+
+// addWaitProgressFlag adds the --wait-progress flag, enabled by default, which
+// prints the rollout progress reported while waiting to the standard error of
+// the command.
+func addWaitProgressFlag(cmd *cobra.Command, progress *kube.RolloutProgressFunc) {
+	printer := func(s kube.RolloutStatus) {
+		fmt.Fprintln(cmd.ErrOrStderr(), s)
+	}
+	cmd.Flags().Var(
+		newWaitProgressValue(printer, progress),
+		"wait-progress",
+		"if set and --wait enabled, print the rollout progress of Deployments and StatefulSets, with the reasons of stalled pods, while waiting",
+	)
+	cmd.Flags().Lookup("wait-progress").NoOptDefVal = "true"
+}
+
+type waitProgressValue struct {
+	printer  kube.RolloutProgressFunc
+	progress *kube.RolloutProgressFunc
+}
+
+func newWaitProgressValue(printer kube.RolloutProgressFunc, progress *kube.RolloutProgressFunc) *waitProgressValue {
+	*progress = printer
+	return &waitProgressValue{printer: printer, progress: progress}
+}
+
+func (v *waitProgressValue) String() string {
+	return strconv.FormatBool(*v.progress != nil)
+}
+
+func (v *waitProgressValue) Set(s string) error {
+	enabled, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v.progress = nil
+	if enabled {
+		*v.progress = v.printer
+	}
+	return nil
+}
+
+func (v *waitProgressValue) Type() string {
+	return "bool"
+}
+
 type waitValue kube.WaitStrategy
 
 func newWaitValue(defaultValue kube.WaitStrategy, ws *kube.WaitStrategy) *waitValue {
@@ -101,6 +170,34 @@ func (ws *waitValue) Type() string {
 	return "WaitStrategy"
 }
 
+// bindAtFlag adds the --at flag, which selects the revision of a release that
+// was deployed at a given time.
+func bindAtFlag(f *pflag.FlagSet, at *time.Time) {
+	f.Var((*timeValue)(at), "at", "get the revision of the named release that was deployed at this time, in RFC 3339 format (e.g. 2024-06-01T12:00:00Z). Cannot be used with --revision")
+}
+
+type timeValue time.Time
+
+func (t *timeValue) String() string {
+	if t == nil || time.Time(*t).IsZero() {
+		return ""
+	}
+	return time.Time(*t).Format(time.RFC3339)
+}
+
+func (t *timeValue) Set(s string) error {
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("invalid time %q, expected RFC 3339 format such as 2024-06-01T12:00:00Z", s)
+	}
+	*t = timeValue(parsed)
+	return nil
+}
+
+func (t *timeValue) Type() string {
+	return "time"
+}
+
 func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.StringVar(&c.Version, "version", "", "specify a version constraint for the chart version to use. This constraint can be a specific tag (e.g. 1.1.1) or it may reference a valid range (e.g. ^2.0.0). If this is not specified, the latest version is used")
 	f.BoolVar(&c.Verify, "verify", false, "verify the package before using it")
@@ -116,6 +213,13 @@ func addChartPathOptionsFlags(f *pflag.FlagSet, c *action.ChartPathOptions) {
 	f.BoolVar(&c.PassCredentialsAll, "pass-credentials", false, "pass credentials to all domains")
 }
 
+// addChartFilterFlags adds the flags selecting the files of the chart to load.
+func addChartFilterFlags(f *pflag.FlagSet, filter *loader.Filter) {
+	f.StringSliceVar(&filter.Include, "include-files", nil, "only load the chart files matching these patterns, such as 'templates/*'. Chart.yaml and values.yaml of the chart and its subcharts are always loaded")
+	f.StringSliceVar(&filter.Exclude, "exclude-files", nil, "do not load the chart files matching these patterns, such as 'templates/tests' or '*.png'")
+	f.Int64Var(&filter.MaxFileSize, "max-file-size", 0, "do not load the chart files larger than this many bytes")
+}
+
 // bindOutputFlag will add the output flag to the given command and bind the
 // value to the given format pointer
 func bindOutputFlag(cmd *cobra.Command, varRef *output.Format) {
@@ -167,16 +271,66 @@ func (o *outputValue) Set(s string) error {
 
 // TODO there is probably a better way to pass cobra settings than as a param
 func bindPostRenderFlag(cmd *cobra.Command, varRef *postrenderer.PostRenderer, settings *cli.EnvSettings) {
-	p := &postRendererOptions{varRef, "", []string{}, settings}
-	cmd.Flags().Var(&postRendererString{p}, postRenderFlag, "the name of a postrenderer type plugin to be used for post rendering. If it exists, the plugin will be used")
-	cmd.Flags().Var(&postRendererArgsSlice{p}, postRenderArgsFlag, "an argument to the post-renderer (can specify multiple)")
+	p := &postRendererOptions{renderer: varRef, settings: settings}
+	cmd.Flags().Var(&postRendererString{p}, postRenderFlag, "the name of a postrenderer type plugin to be used for post rendering. If it exists, the plugin will be used. Use \"kustomize\" with the directory of an overlay as argument for the built-in kustomize post-renderer. Can be specified multiple times to run several post-renderers in order")
+	cmd.Flags().Var(&postRendererArgsSlice{p}, postRenderArgsFlag, "an argument to the post-renderer given last (can specify multiple)")
+	cmd.Flags().Var(&postRendererPath{p, &p.pipelineFile}, postRenderPipelineFlag, "a file listing post-renderers to run in order, before the ones given with --post-renderer")
+	cmd.Flags().Var(&postRendererPath{p, &p.captureDir}, postRenderCaptureDirFlag, "a directory to write the input and output of every post-renderer to, for debugging")
 }
 
 type postRendererOptions struct {
-	renderer   *postrenderer.PostRenderer
+	renderer *postrenderer.PostRenderer
+	// stages are the plugins given with --post-renderer, in order.
+	stages []postRendererStage
+	// args are the arguments given before any --post-renderer, which go to
+	// the first one.
+	args         []string
+	pipelineFile string
+	captureDir   string
+	settings     *cli.EnvSettings
+}
+
+type postRendererStage struct {
 	pluginName string
 	args       []string
-	settings   *cli.EnvSettings
+}
+
+// lastArgs returns the arguments of the post-renderer given last.
+func (o *postRendererOptions) lastArgs() *[]string {
+	if len(o.stages) == 0 {
+		return &o.args
+	}
+	return &o.stages[len(o.stages)-1].args
+}
+
+// build sets the renderer from the options given so far. A single
+// post-renderer is used as is; several are run by a pipeline.
+func (o *postRendererOptions) build() error {
+	p := &postrenderer.Pipeline{CaptureDir: o.captureDir}
+	if o.pipelineFile != "" {
+		loaded, err := postrenderer.LoadPipelineFile(o.settings, o.pipelineFile)
+		if err != nil {
+			return err
+		}
+		p.Stages = loaded.Stages
+	}
+	for _, s := range o.stages {
+		pr, err := postrenderer.New(o.settings, s.pluginName, s.args...)
+		if err != nil {
+			return err
+		}
+		p.Stages = append(p.Stages, postrenderer.Stage{Name: s.pluginName, Renderer: pr})
+	}
+
+	switch {
+	case len(p.Stages) == 0:
+		*o.renderer = nil
+	case len(p.Stages) == 1 && p.CaptureDir == "":
+		*o.renderer = p.Stages[0].Renderer
+	default:
+		*o.renderer = p
+	}
+	return nil
 }
 
 type postRendererString struct {
@@ -184,7 +338,11 @@ type postRendererString struct {
 }
 
 func (p *postRendererString) String() string {
-	return p.options.pluginName
+	names := make([]string, 0, len(p.options.stages))
+	for _, s := range p.options.stages {
+		names = append(names, s.pluginName)
+	}
+	return strings.Join(names, ",")
 }
 
 func (p *postRendererString) Type() string {
@@ -195,16 +353,17 @@ func (p *postRendererString) Set(val string) error {
 	if val == "" {
 		return nil
 	}
-	if p.options.pluginName != "" {
-		return fmt.Errorf("cannot specify --post-renderer flag more than once")
+	for _, s := range p.options.stages {
+		if s.pluginName == val {
+			return fmt.Errorf("post-renderer %q is specified more than once", val)
+		}
 	}
-	p.options.pluginName = val
-	pr, err := postrenderer.NewPostRendererPlugin(p.options.settings, p.options.pluginName, p.options.args...)
-	if err != nil {
-		return err
+	stage := postRendererStage{pluginName: val}
+	if len(p.options.stages) == 0 {
+		stage.args = p.options.args
 	}
-	*p.options.renderer = pr
-	return nil
+	p.options.stages = append(p.options.stages, stage)
+	return p.options.build()
 }
 
 type postRendererArgsSlice struct {
@@ -212,7 +371,7 @@ type postRendererArgsSlice struct {
 }
 
 func (p *postRendererArgsSlice) String() string {
-	return "[" + strings.Join(p.options.args, ",") + "]"
+	return "[" + strings.Join(*p.options.lastArgs(), ",") + "]"
 }
 
 func (p *postRendererArgsSlice) Type() string {
@@ -222,32 +381,48 @@ func (p *postRendererArgsSlice) Type() string {
 func (p *postRendererArgsSlice) Set(val string) error {
 
 	// a post-renderer defined by a user may accept empty arguments
-	p.options.args = append(p.options.args, val)
+	args := p.options.lastArgs()
+	*args = append(*args, val)
 
-	if p.options.pluginName == "" {
+	if len(p.options.stages) == 0 {
 		return nil
 	}
 	// overwrite if already create PostRenderer by `post-renderer` flags
-	pr, err := postrenderer.NewPostRendererPlugin(p.options.settings, p.options.pluginName, p.options.args...)
-	if err != nil {
-		return err
-	}
-	*p.options.renderer = pr
-	return nil
+	return p.options.build()
 }
 
 func (p *postRendererArgsSlice) Append(val string) error {
-	p.options.args = append(p.options.args, val)
+	args := p.options.lastArgs()
+	*args = append(*args, val)
 	return nil
 }
 
 func (p *postRendererArgsSlice) Replace(val []string) error {
-	p.options.args = val
+	*p.options.lastArgs() = val
 	return nil
 }
 
 func (p *postRendererArgsSlice) GetSlice() []string {
-	return p.options.args
+	return *p.options.lastArgs()
+}
+
+// postRendererPath is a flag setting a path of the post-renderer options.
+type postRendererPath struct {
+	options *postRendererOptions
+	path    *string
+}
+
+func (p *postRendererPath) String() string {
+	return *p.path
+}
+
+func (p *postRendererPath) Type() string {
+	return "string"
+}
+
+func (p *postRendererPath) Set(val string) error {
+	*p.path = val
+	return p.options.build()
 }
 
 func compVersionFlag(chartRef string, _ string) ([]string, cobra.ShellCompDirective) {
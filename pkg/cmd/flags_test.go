@@ -17,13 +17,17 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
 	"helm.sh/helm/v4/pkg/action"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/kube"
+	"helm.sh/helm/v4/pkg/postrenderer"
 	release "helm.sh/helm/v4/pkg/release/v1"
 	helmtime "helm.sh/helm/v4/pkg/time"
 )
@@ -98,7 +102,7 @@ func outputFlagCompletionTest(t *testing.T, cmdName string) {
 	runTestCmd(t, tests)
 }
 
-func TestPostRendererFlagSetOnce(t *testing.T) {
+func TestPostRendererFlagDuplicate(t *testing.T) {
 	cfg := action.Configuration{}
 	client := action.NewInstall(&cfg)
 	settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
@@ -114,9 +118,60 @@ func TestPostRendererFlagSetOnce(t *testing.T) {
 
 	// Set the plugin name again to the same value is not ok
 	err = str.Set("postrenderer-v1")
-	require.Error(t, err)
+	require.ErrorContains(t, err, "specified more than once")
+}
 
-	// Set the plugin name again to a different value is not ok
-	err = str.Set("cat")
+func TestPostRendererFlagUnknownPlugin(t *testing.T) {
+	cfg := action.Configuration{}
+	client := action.NewInstall(&cfg)
+	settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
+	str := postRendererString{
+		options: &postRendererOptions{
+			renderer: &client.PostRenderer,
+			settings: settings,
+		},
+	}
+	// Set the plugin name to a plugin that does not exist is not ok
+	err := str.Set("cat")
 	require.Error(t, err)
 }
+
+func TestPostRendererFlagPipeline(t *testing.T) {
+	settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
+	cmd := &cobra.Command{}
+	var pr postrenderer.PostRenderer
+	bindPostRenderFlag(cmd, &pr, settings)
+
+	// A single post-renderer is used as is.
+	require.NoError(t, cmd.Flags().Parse([]string{"--post-renderer", "postrenderer-v1"}))
+	require.NotNil(t, pr)
+	_, isPipeline := pr.(*postrenderer.Pipeline)
+	require.False(t, isPipeline)
+
+	// Capturing the stages requires a pipeline.
+	dir := t.TempDir()
+	require.NoError(t, cmd.Flags().Parse([]string{"--post-renderer-capture-dir", dir}))
+	p, ok := pr.(*postrenderer.Pipeline)
+	require.True(t, ok)
+	require.Equal(t, dir, p.CaptureDir)
+	require.Len(t, p.Stages, 1)
+	require.Equal(t, "postrenderer-v1", p.Stages[0].Name)
+}
+
+func TestWaitProgressFlag(t *testing.T) {
+	cmd := &cobra.Command{}
+	errOut := &bytes.Buffer{}
+	cmd.SetErr(errOut)
+	var progress kube.RolloutProgressFunc
+	addWaitProgressFlag(cmd, &progress)
+
+	// Enabled by default, printing to the standard error.
+	require.NotNil(t, progress)
+	progress(kube.RolloutStatus{Kind: "Deployment", Name: "web", Message: "1 of 2 new replicas updated, 0 ready, 0 available", Stalls: []string{"pod/web-a container app: ImagePullBackOff"}})
+	require.Equal(t, "deployment/web: 1 of 2 new replicas updated, 0 ready, 0 available\n  pod/web-a container app: ImagePullBackOff\n", errOut.String())
+
+	require.NoError(t, cmd.Flags().Parse([]string{"--wait-progress=false"}))
+	require.Nil(t, progress)
+	require.NoError(t, cmd.Flags().Parse([]string{"--wait-progress"}))
+	require.NotNil(t, progress)
+}
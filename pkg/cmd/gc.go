@@ -0,0 +1,145 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const gcDesc = `
+This command finds what interrupted or misbehaving operations left behind:
+
+- hook resources, such as Jobs and Pods, that still exist although their
+  'helm.sh/hook-delete-policy' says they should have been deleted after the
+  hook succeeded or failed, and
+- release revisions stuck in a pending status for longer than
+  '--pending-timeout', which make Helm refuse to upgrade or roll back.
+
+By default the leftovers are only reported. Use '--delete' to delete the hook
+resources and mark the stale pending revisions as failed.
+
+Either '--release' or '--all' must be given:
+
+    $ helm gc --release my-release
+    $ helm gc --all --delete
+`
+
+func newGCCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewGC(cfg)
+	var name string
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:               "gc",
+		Short:             "find and remove leftover hook resources and stale pending releases",
+		Long:              gcDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if name == "" && !client.All {
+				return errors.New("either --release or --all must be set")
+			}
+			if name != "" && client.All {
+				return errors.New("--release and --all cannot be used together")
+			}
+
+			leftovers, err := client.Run(name)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &gcPrinter{leftovers: leftovers, delete: client.Delete})
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&name, "release", "", "inspect only this release")
+	f.BoolVar(&client.All, "all", false, "inspect all releases in the namespace")
+	f.BoolVar(&client.Delete, "delete", false, "delete the leftovers instead of only reporting them")
+	f.DurationVar(&client.PendingTimeout, "pending-timeout", time.Hour, "how long a release may be pending before it is considered stale")
+	bindOutputFlag(cmd, &outfmt)
+
+	err := cmd.RegisterFlagCompletionFunc("release", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return compListReleases(toComplete, args, cfg)
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+type gcPrinter struct {
+	leftovers []*action.Leftover
+	delete    bool
+}
+
+func (p *gcPrinter) Kind() string {
+	return "GarbageCollectionResult"
+}
+
+func (p *gcPrinter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, p.items())
+}
+
+func (p *gcPrinter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, p.items())
+}
+
+func (p *gcPrinter) WriteTable(out io.Writer) error {
+	if len(p.leftovers) == 0 {
+		_, _ = fmt.Fprintln(out, "No leftovers found.")
+		return nil
+	}
+
+	tbl := uitable.New()
+	tbl.AddRow("RELEASE", "REVISION", "KIND", "NAME", "NAMESPACE", "REASON", "ACTION")
+	for _, l := range p.leftovers {
+		result := "none"
+		if l.Deleted {
+			result = "deleted"
+			if l.Kind == action.LeftoverPendingRelease {
+				result = "marked failed"
+			}
+		}
+		tbl.AddRow(l.Release, strconv.Itoa(l.Revision), l.Kind, l.Name, l.Namespace, l.Reason, result)
+	}
+	if err := output.EncodeTable(out, tbl); err != nil {
+		return err
+	}
+	if !p.delete {
+		_, _ = fmt.Fprintln(out, "\nRun with --delete to remove these leftovers.")
+	}
+	return nil
+}
+
+func (p *gcPrinter) items() []*action.Leftover {
+	if p.leftovers == nil {
+		return []*action.Leftover{}
+	}
+	return p.leftovers
+}
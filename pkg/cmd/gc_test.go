@@ -0,0 +1,72 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+	helmtime "helm.sh/helm/v4/pkg/time"
+)
+
+func TestGCCmd(t *testing.T) {
+	rels := []*release.Release{{
+		Name:      "funny-honey",
+		Namespace: "default",
+		Info: &release.Info{
+			Status:       release.StatusPendingUpgrade,
+			LastDeployed: helmtime.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Chart:   &chart.Chart{},
+		Version: 1,
+	}}
+
+	tests := []cmdTestCase{{
+		name:   "report a stale pending release",
+		cmd:    "gc --release funny-honey",
+		golden: "output/gc-pending.txt",
+		rels:   rels,
+	}, {
+		name:   "report a stale pending release as json",
+		cmd:    "gc --all -o json",
+		golden: "output/gc-pending-json.txt",
+		rels:   rels,
+	}, {
+		name:   "mark a stale pending release as failed",
+		cmd:    "gc --all --delete",
+		golden: "output/gc-pending-delete.txt",
+		rels:   rels,
+	}, {
+		name:   "nothing to collect",
+		cmd:    "gc --all --pending-timeout 1000000h",
+		golden: "output/gc-none.txt",
+		rels:   rels,
+	}, {
+		name:      "neither release nor all",
+		cmd:       "gc",
+		golden:    "output/gc-no-target.txt",
+		wantError: true,
+	}, {
+		name:      "both release and all",
+		cmd:       "gc --release funny-honey --all",
+		golden:    "output/gc-both-targets.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
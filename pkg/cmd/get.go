@@ -34,6 +34,12 @@ get extended information about the release, including:
 - The notes provided by the chart of the release
 - The hooks associated with the release
 - The metadata of the release
+
+By default, the latest revision of the release is used. Use '--revision' to
+select a revision by number, or '--at' to select the revision that was deployed
+at a given time, such as when investigating an incident:
+
+    $ helm get manifest myrelease --at 2024-06-01T12:00:00Z
 `
 
 func newGetCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
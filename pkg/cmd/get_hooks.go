@@ -60,6 +60,7 @@ func newGetHooksCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	cmd.Flags().IntVar(&client.Version, "revision", 0, "get the named release with revision")
+	bindAtFlag(cmd.Flags(), &client.At)
 	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 1 {
 			return compListRevisions(toComplete, cfg, args[0])
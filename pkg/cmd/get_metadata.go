@@ -60,6 +60,7 @@ func newGetMetadataCmd(cfg *action.Configuration, out io.Writer) *cobra.Command
 
 	f := cmd.Flags()
 	f.IntVar(&client.Version, "revision", 0, "specify release revision")
+	bindAtFlag(f, &client.At)
 	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 1 {
 			return compListRevisions(toComplete, cfg, args[0])
@@ -107,6 +108,10 @@ func (w metadataWriter) WriteTable(out io.Writer) error {
 	return nil
 }
 
+func (w metadataWriter) Kind() string {
+	return "ReleaseMetadata"
+}
+
 func (w metadataWriter) WriteJSON(out io.Writer) error {
 	return output.EncodeJSON(out, w.metadata)
 }
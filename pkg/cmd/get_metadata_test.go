@@ -20,9 +20,17 @@ import (
 	"testing"
 
 	release "helm.sh/helm/v4/pkg/release/v1"
+	helmtime "helm.sh/helm/v4/pkg/time"
 )
 
 func TestGetMetadataCmd(t *testing.T) {
+	upgraded := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Version: 2})
+	upgraded.Info.LastDeployed = helmtime.Unix(1717243200, 0).UTC()
+	history := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Status: release.StatusSuperseded}),
+		upgraded,
+	}
+
 	tests := []cmdTestCase{{
 		name:   "get metadata with a release",
 		cmd:    "get metadata thomas-guide",
@@ -44,6 +52,29 @@ func TestGetMetadataCmd(t *testing.T) {
 		cmd:    "get metadata thomas-guide --output yaml",
 		golden: "output/get-metadata.yaml",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "thomas-guide", Labels: map[string]string{"key1": "value1"}})},
+	}, {
+		name:   "get metadata at a time",
+		cmd:    "get metadata thomas-guide --at 2000-01-01T00:00:00Z --output yaml",
+		golden: "output/get-metadata-at.yaml",
+		rels:   history,
+	}, {
+		name:      "get metadata at a time before the release",
+		cmd:       "get metadata thomas-guide --at 1970-01-01T00:00:00Z",
+		golden:    "output/get-metadata-at-before.txt",
+		rels:      history,
+		wantError: true,
+	}, {
+		name:      "get metadata at an invalid time",
+		cmd:       "get metadata thomas-guide --at yesterday",
+		golden:    "output/get-metadata-at-invalid.txt",
+		rels:      history,
+		wantError: true,
+	}, {
+		name:      "get metadata with both a revision and a time",
+		cmd:       "get metadata thomas-guide --revision 1 --at 2000-01-01T00:00:00Z",
+		golden:    "output/get-metadata-at-revision.txt",
+		rels:      history,
+		wantError: true,
 	}}
 	runTestCmd(t, tests)
 }
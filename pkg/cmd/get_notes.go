@@ -20,19 +20,44 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
+	coloroutput "helm.sh/helm/v4/internal/cli/output"
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
 
 var getNotesHelp = `
 This command shows notes provided by the chart of a named release.
+
+The notes may use basic markdown: headings, fenced code blocks, list items,
+inline code, bold text and links. On a terminal, the notes are rendered and
+wrapped to its width; otherwise they are written as they are, for scripts.
+Use --output to choose the format:
+
+- auto: render the notes on a terminal, write them as they are otherwise
+- plain: write the notes as they are
+- text: render the notes for a terminal
+- html: render the notes as an HTML fragment
 `
 
+const (
+	notesFormatAuto  = "auto"
+	notesFormatPlain = "plain"
+	notesFormatText  = "text"
+	notesFormatHTML  = "html"
+)
+
+// defaultNotesWidth is the width the notes are wrapped to when the output is
+// not a terminal.
+const defaultNotesWidth = 80
+
 func newGetNotesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewGet(cfg)
+	var format string
 
 	cmd := &cobra.Command{
 		Use:   "notes RELEASE_NAME",
@@ -46,12 +71,17 @@ func newGetNotesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			switch format {
+			case notesFormatAuto, notesFormatPlain, notesFormatText, notesFormatHTML:
+			default:
+				return fmt.Errorf("invalid format %q: must be one of %s, %s, %s or %s", format, notesFormatAuto, notesFormatPlain, notesFormatText, notesFormatHTML)
+			}
 			res, err := client.Run(args[0])
 			if err != nil {
 				return err
 			}
 			if len(res.Info.Notes) > 0 {
-				fmt.Fprintf(out, "NOTES:\n%s\n", res.Info.Notes)
+				writeNotes(out, res.Info.Notes, format, settings.ShouldDisableColor())
 			}
 			return nil
 		},
@@ -59,6 +89,8 @@ func newGetNotesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.IntVar(&client.Version, "revision", 0, "get the named release with revision")
+	f.StringVarP(&format, "output", "o", notesFormatAuto, fmt.Sprintf("prints the notes in the specified format. Allowed values: %s, %s, %s, %s", notesFormatAuto, notesFormatPlain, notesFormatText, notesFormatHTML))
+	bindAtFlag(f, &client.At)
 	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 1 {
 			return compListRevisions(toComplete, cfg, args[0])
@@ -66,9 +98,37 @@ func newGetNotesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	})
 
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = cmd.RegisterFlagCompletionFunc("output", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{notesFormatAuto, notesFormatPlain, notesFormatText, notesFormatHTML}, cobra.ShellCompDirectiveNoFileComp
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	return cmd
 }
+
+// writeNotes writes the notes of a release in the format.
+func writeNotes(out io.Writer, notes, format string, noColor bool) {
+	width := defaultNotesWidth
+	if f, ok := out.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if w, _, err := term.GetSize(int(f.Fd())); err == nil {
+			width = w
+		}
+		if format == notesFormatAuto {
+			format = notesFormatText
+		}
+	}
+
+	switch format {
+	case notesFormatText:
+		fmt.Fprintf(out, "NOTES:\n%s", coloroutput.RenderNotes(notes, width, noColor))
+	case notesFormatHTML:
+		fmt.Fprint(out, coloroutput.RenderNotesHTML(notes))
+	default:
+		fmt.Fprintf(out, "NOTES:\n%s\n", notes)
+	}
+}
@@ -23,11 +23,35 @@ import (
 )
 
 func TestGetNotesCmd(t *testing.T) {
+	markdown := release.Mock(&release.MockReleaseOptions{Name: "the-markdown"})
+	markdown.Info.Notes = "# Thanks for installing\n\nThe **web server** is documented in [the docs](https://helm.sh/docs), read them before changing the values of the chart.\n\n1. Get the URL:\n  kubectl get svc <name>\n\n```\nhelm test the-markdown\n```\n"
+
 	tests := []cmdTestCase{{
 		name:   "get notes of a deployed release",
 		cmd:    "get notes the-limerick",
 		golden: "output/get-notes.txt",
 		rels:   []*release.Release{release.Mock(&release.MockReleaseOptions{Name: "the-limerick"})},
+	}, {
+		name:   "get notes in plain format",
+		cmd:    "get notes the-markdown --output plain",
+		golden: "output/get-notes-plain.txt",
+		rels:   []*release.Release{markdown},
+	}, {
+		name:   "get notes rendered as text",
+		cmd:    "get notes the-markdown --output text",
+		golden: "output/get-notes-text.txt",
+		rels:   []*release.Release{markdown},
+	}, {
+		name:   "get notes rendered as html",
+		cmd:    "get notes the-markdown -o html",
+		golden: "output/get-notes-html.txt",
+		rels:   []*release.Release{markdown},
+	}, {
+		name:      "get notes with an invalid format",
+		cmd:       "get notes the-markdown -o pdf",
+		golden:    "output/get-notes-invalid-format.txt",
+		rels:      []*release.Release{markdown},
+		wantError: true,
 	}, {
 		name:      "get notes without args",
 		cmd:       "get notes",
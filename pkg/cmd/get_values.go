@@ -17,21 +17,39 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
 )
 
 var getValuesHelp = `
 This command downloads a values file for a given release.
+
+To debug surprising merges, '--explain' lists every value along with the
+source that set it: the values file given with -f and its position, the flag
+such as --set, or, with '--all', the default values of a chart or those of a
+parent chart overriding a subchart:
+
+    $ helm get values --all --explain myrelease
+    PATH            	VALUE	SOURCE
+    image.repository	nginx	chart default (mychart)
+    image.tag       	1.27 	-f values-prod.yaml (file 2)
+    replicaCount    	3    	--set replicaCount=3
 `
 
+type explainedValuesWriter struct {
+	origins []chartutil.ValueOrigin
+}
+
 type valuesWriter struct {
 	vals      map[string]interface{}
 	allValues bool
@@ -39,6 +57,7 @@ type valuesWriter struct {
 
 func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var outfmt output.Format
+	var explain bool
 	client := action.NewGetValues(cfg)
 
 	cmd := &cobra.Command{
@@ -53,6 +72,13 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return compListReleases(toComplete, args, cfg)
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if explain {
+				origins, err := client.RunExplain(args[0])
+				if err != nil {
+					return err
+				}
+				return outfmt.Write(out, &explainedValuesWriter{origins})
+			}
 			vals, err := client.Run(args[0])
 			if err != nil {
 				return err
@@ -63,6 +89,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.IntVar(&client.Version, "revision", 0, "get the named release with revision")
+	bindAtFlag(f, &client.At)
 	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 1 {
 			return compListRevisions(toComplete, cfg, args[0])
@@ -75,6 +102,7 @@ func newGetValuesCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	f.BoolVarP(&client.AllValues, "all", "a", false, "dump all (computed) values")
+	f.BoolVar(&explain, "explain", false, "list every value along with the values file, flag or chart that set it")
 	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
@@ -89,6 +117,10 @@ func (v valuesWriter) WriteTable(out io.Writer) error {
 	return output.EncodeYAML(out, v.vals)
 }
 
+func (v valuesWriter) Kind() string {
+	return "Values"
+}
+
 func (v valuesWriter) WriteJSON(out io.Writer) error {
 	return output.EncodeJSON(out, v.vals)
 }
@@ -96,3 +128,37 @@ func (v valuesWriter) WriteJSON(out io.Writer) error {
 func (v valuesWriter) WriteYAML(out io.Writer) error {
 	return output.EncodeYAML(out, v.vals)
 }
+
+func (v explainedValuesWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("PATH", "VALUE", "SOURCE")
+	for _, o := range v.origins {
+		table.AddRow(o.Path, formatExplainedValue(o.Value), o.Source)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (v explainedValuesWriter) Kind() string {
+	return "ExplainedValues"
+}
+
+func (v explainedValuesWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, v.origins)
+}
+
+func (v explainedValuesWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, v.origins)
+}
+
+// formatExplainedValue formats a value for the table, strings as they are and
+// other values as JSON.
+func formatExplainedValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -53,6 +53,33 @@ func TestGetValuesCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestGetValuesCmdExplain(t *testing.T) {
+	explained := func() []*release.Release {
+		rel := release.Mock(&release.MockReleaseOptions{Name: "thomas-guide"})
+		rel.Chart.Values = map[string]interface{}{"name": "default", "replicas": 1}
+		rel.Config = map[string]interface{}{"name": "value", "image": map[string]interface{}{"tag": "1.2"}}
+		rel.ConfigSources = map[string]string{"name": "--set name=value"}
+		return []*release.Release{rel}
+	}
+	tests := []cmdTestCase{{
+		name:   "get values with sources",
+		cmd:    "get values thomas-guide --explain",
+		golden: "output/get-values-explain.txt",
+		rels:   explained(),
+	}, {
+		name:   "get all values with sources",
+		cmd:    "get values thomas-guide --all --explain",
+		golden: "output/get-values-explain-all.txt",
+		rels:   explained(),
+	}, {
+		name:   "get values with sources to json",
+		cmd:    "get values thomas-guide --explain --output json",
+		golden: "output/get-values-explain.json",
+		rels:   explained(),
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestGetValuesCompletion(t *testing.T) {
 	checkReleaseCompletion(t, "get values", false)
 }
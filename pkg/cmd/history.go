@@ -94,6 +94,10 @@ type releaseInfo struct {
 
 type releaseHistory []releaseInfo
 
+func (r releaseHistory) Kind() string {
+	return "ReleaseHistory"
+}
+
 func (r releaseHistory) WriteJSON(out io.Writer) error {
 	return output.EncodeJSON(out, r)
 }
@@ -34,7 +34,6 @@ import (
 
 	"helm.sh/helm/v4/pkg/action"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
@@ -98,6 +97,77 @@ And in the following example, 'foo' is set to '{"key1":"value1","key2":"bar"}':
 
     $ helm install --set-json='foo={"key1":"value1","key2":"value2"}' --set-json='foo.key2="bar"' myredis ./redis
 
+Maps of values are merged with the values they override, while lists replace
+them. A '$patch' directive changes this: a map with '$patch: replace' replaces
+the map it overrides, a list starting with a '- $patch: merge' item is
+appended to the list it overrides, and '$patch: delete' removes the key like a
+null value does:
+
+    resources:
+      $patch: replace
+      limits:
+        memory: 1Gi
+    extraArgs:
+    - $patch: merge
+    - --verbose
+
+Values files encrypted with sops using age keys, or with age itself, are
+decrypted transparently. The age identities are read from the SOPS_AGE_KEY and
+SOPS_AGE_KEY_FILE environment variables, or from the sops/age/keys.txt file of
+the user configuration directory:
+
+    $ helm install -f values.yaml -f secrets.enc.yaml myredis ./redis
+
+The '--values-env' flag expands the ${NAME} references to environment
+variables in the values files, never in the default values of the chart,
+before they are parsed. Unset variables expand to empty strings, or make the
+command fail with '--values-env=strict'. Use $${NAME} for a literal ${NAME}:
+
+    $ cat values.yaml
+    image:
+      tag: "${IMAGE_TAG}"
+    $ IMAGE_TAG=1.2.3 helm install --values-env=strict -f values.yaml myredis ./redis
+
+A chart may ship named sets of values in its values/ directory, such as
+values/production.yaml, selected with the '--values-profile' flag. Profiles
+are layered over the default values of the chart in the order they are given,
+and the '--values' and '--set' flags override them. A profile with a schema,
+such as values/production.schema.json, is validated against it:
+
+    $ helm install --values-profile production,small myredis ./redis
+
+To set a value in each of a set of tables, use the '--set-glob' flag. A '*' in
+its keys matches the keys of the table at that path in the values of the chart,
+coalesced with the other values given, and the value is set in all of them:
+
+    $ helm install --set-glob 'workers.*.resources.limits.cpu=500m' myapp ./app
+
+To remove a value, or to edit a list in place, use the '--values-patch' flag
+with an RFC 6902 JSON Patch file, written in JSON or YAML. The patch is applied
+to the values once they are coalesced with the default values of the chart:
+
+    $ cat patch.yaml
+    - op: remove
+      path: /resources/limits
+    - op: add
+      path: /extraArgs/-
+      value: --verbose
+    $ helm install --values-patch patch.yaml myredis ./redis
+
+A chart may declare the prerequisites it needs from the cluster in the
+'requires' section of its Chart.yaml: API versions or resources served by the
+cluster, usually provided by CRDs, a minimum number of nodes, and StorageClasses.
+They are checked before any resource of the chart is applied, and the install
+fails listing every prerequisite the cluster does not meet. Use
+'--skip-prerequisites' to install anyway:
+
+    requires:
+      apiVersions:
+        - monitoring.coreos.com/v1/ServiceMonitor
+      minNodes: 3
+      storageClasses:
+        - fast-ssd
+
 To check the generated manifests of a release without installing the chart,
 the --debug and --dry-run flags can be combined.
 
@@ -174,6 +244,7 @@ func newInstallCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	addInstallFlags(cmd, cmd.Flags(), client, valueOpts)
+	addFieldManagerFlag(cmd.Flags(), cfg)
 	// hide-secret is not available in all places the install flags are used so
 	// it is added separately
 	f := cmd.Flags()
@@ -211,15 +282,23 @@ func addInstallFlags(cmd *cobra.Command, f *pflag.FlagSet, client *action.Instal
 	f.BoolVar(&client.RollbackOnFailure, "rollback-on-failure", false, "if set, Helm will rollback (uninstall) the installation upon failure. The --wait flag will be default to \"watcher\" if --rollback-on-failure is set")
 	f.MarkDeprecated("atomic", "use --rollback-on-failure instead")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed. By default, CRDs are installed if not already present")
+	f.BoolVar(&client.SkipPrerequisites, "skip-prerequisites", false, "if set, the prerequisites in the requires section of Chart.yaml are not checked against the cluster")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
 	f.StringToStringVarP(&client.Labels, "labels", "l", nil, "Labels that would be added to release metadata. Should be divided by comma.")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
+	f.BoolVar(&client.StrictValues, "strict-values", false, "fail rendering when a template references a value that is not defined")
+	f.StringSliceVar(&client.ValuesProfiles, "values-profile", []string{}, "layer a values profile from the values/ directory of the chart under the supplied values (can specify multiple or separate values with commas: production,small)")
+	f.StringArrayVar(&client.GlobValues, "set-glob", []string{}, "set values on the command line with wildcards in their keys, expanded against the values of the chart (can specify multiple or separate values with commas: workers.*.replicas=2,workers.*.cpu=500m)")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in install output. Does not affect presence in chart metadata")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, install will ignore the check for helm annotations and take ownership of the existing resources")
+	f.StringVar(&client.ExportBundle, "export-bundle", "", "after the release is deployed, export its rollback bundle to this directory or OCI repository (oci://). See 'helm rollback --from-bundle'")
 	addValueOptionsFlags(f, valueOpts)
+	addValuesPatchFlag(f, valueOpts)
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
+	addChartFilterFlags(f, &client.Filter)
 	AddWaitFlag(cmd, &client.WaitStrategy)
+	addWaitProgressFlag(cmd, &client.WaitProgress)
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
 	cmd.MarkFlagsMutuallyExclusive("force", "force-conflicts")
 
@@ -259,13 +338,17 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 	slog.Debug("Chart path", "path", cp)
 
 	p := getter.All(settings)
-	vals, err := valueOpts.MergeValues(p)
+	vals, sources, err := valueOpts.MergeValuesWithSources(p)
 	if err != nil {
 		return nil, err
 	}
+	client.ValuesSources = sources
+	if client.ValuesPatches, err = valueOpts.ReadPatches(p); err != nil {
+		return nil, err
+	}
 
 	// Check chart dependencies to make sure all are present in /charts
-	chartRequested, err := loader.Load(cp)
+	chartRequested, err := client.LoadChart(cp)
 	if err != nil {
 		return nil, err
 	}
@@ -300,7 +383,7 @@ func runInstall(args []string, client *action.Install, valueOpts *values.Options
 					return nil, err
 				}
 				// Reload the chart with the updated Chart.lock file.
-				if chartRequested, err = loader.Load(cp); err != nil {
+				if chartRequested, err = client.LoadChart(cp); err != nil {
 					return nil, fmt.Errorf("failed reloading chart after repo update: %w", err)
 				}
 			} else {
@@ -196,7 +196,7 @@ func TestInstall(t *testing.T) {
 			name:      "install with schema file, extra values from yaml, with errors",
 			cmd:       "install schema testdata/testcharts/chart-with-schema -f testdata/testcharts/chart-with-schema/extra-values.yaml",
 			wantError: true,
-			golden:    "output/schema-negative.txt",
+			golden:    "output/schema-negative-values-file.txt",
 		},
 		// Install, values from yaml, extra values from cli, schematized with errors
 		{
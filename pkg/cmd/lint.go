@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 
@@ -28,7 +29,11 @@ import (
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart/common"
+	"helm.sh/helm/v4/pkg/chart/v2/lint"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
 	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/getter"
 )
@@ -40,18 +45,96 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+By default, all values files and '--set' options are merged before linting.
+With '--values-matrix', the chart is linted once per values file instead, so
+that problems that only occur with a particular set of values are reported
+under that values file:
+
+    $ helm lint --values-matrix -f values-prod.yaml -f values-dev.yaml ./mychart
+
+With '--watch', the command keeps running and lints the chart again whenever
+one of its files, or one of the values files given with '-f/--values', changes,
+reporting the issues that appeared or were resolved since the previous run.
+Charts and values are cached between runs, so only the work affected by a
+change is repeated.
+
+With '--serve', the command runs a JSON-RPC 2.0 server on stdin and stdout for
+editor integrations. Editors open charts with the 'chart/open' method, report
+file changes with 'chart/didChange' and receive the lint findings as Language
+Server Protocol 'textDocument/publishDiagnostics' notifications.
+
+Registries with ownership requirements can check the chart's maintainers with
+'--allowed-maintainers', which accepts emails and email domains, and
+'--min-maintainers':
+
+    $ helm lint --allowed-maintainers example.com --min-maintainers 2 ./mychart
+
+The container images of the rendered resources can be checked against a
+policy: '--require-pinned-images' rejects images tagged 'latest' or not tagged
+at all, '--require-image-digests' requires digests, '--allowed-registries'
+restricts the registries images are pulled from, and '--online' looks every
+image up in its registry:
+
+    $ helm lint --require-pinned-images --allowed-registries registry.example.com ./mychart
+
+The prerequisites a chart declares in the 'requires' section of Chart.yaml can
+be checked against a target cluster without contacting it, by describing the
+cluster in a capabilities file given with '--capabilities-file':
+
+    $ cat capabilities.yaml
+    apiVersions:
+      - monitoring.coreos.com/v1
+      - monitoring.coreos.com/v1/ServiceMonitor
+    nodes: 3
+    storageClasses:
+      - standard
+    $ helm lint --capabilities-file capabilities.yaml ./mychart
+
+Custom rules can be added with lint plugins, given with '--rule-plugin'. A
+lint plugin receives the chart's values and rendered templates and returns its
+findings. Plugins using the Wasm runtime can be installed from OCI registries
+like any other plugin, and run in a sandbox without filesystem or network
+access unless their plugin.yaml grants it:
+
+    $ helm plugin install oci://registry.example.com/helm-plugins/no-host-network
+    $ helm lint --rule-plugin no-host-network ./mychart
+
+With '--output json' or '--output yaml', the messages of every chart are
+printed along with a summary: the number of charts scanned and failed, the
+number of messages by severity, and the messages and duration of every rule.
+Dashboards can collect the summary to track the lint health of charts over
+time.
+
+With '--report', a validation report is also written to a JSON file. It lists
+the findings of every chart, each with its category: 'schema' for values not
+matching a schema, 'deprecation' for deprecated Kubernetes APIs, 'policy' for
+the maintainer, image and label policies, and 'lint' for everything else. The
+report ends with the number of valid and invalid charts and the number of
+findings by category and severity, so that CI gates and dashboards can consume
+a single artifact per chart:
+
+    $ helm lint --report report.json --require-pinned-images ./mychart
 `
 
 func newLintCmd(out io.Writer) *cobra.Command {
 	client := action.NewLint()
 	valueOpts := &values.Options{}
 	var kubeVersion string
+	var capabilitiesFile string
+	var recommendedLabels bool
+	var valuesMatrix bool
+	var watch bool
+	var serve bool
+	var reportFile string
+	var rulePlugins []string
+	var outfmt output.Format
 
 	cmd := &cobra.Command{
 		Use:   "lint PATH",
 		Short: "examine a chart for possible issues",
 		Long:  longLintHelp,
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			paths := []string{"."}
 			if len(args) > 0 {
 				paths = args
@@ -65,6 +148,14 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				client.KubeVersion = parsedKubeVersion
 			}
 
+			if capabilitiesFile != "" {
+				cluster, err := chartutil.LoadCluster(capabilitiesFile)
+				if err != nil {
+					return err
+				}
+				client.Cluster = cluster
+			}
+
 			if client.WithSubcharts {
 				for _, p := range paths {
 					filepath.Walk(filepath.Join(p, "charts"), func(path string, info os.FileInfo, _ error) error {
@@ -80,65 +171,78 @@ func newLintCmd(out io.Writer) *cobra.Command {
 				}
 			}
 
+			if recommendedLabels {
+				client.RequiredLabels = append(client.RequiredLabels, rules.RecommendedLabels...)
+			}
+
+			if client.VerifyImages {
+				registryClient, err := newDefaultRegistryClient(false, "", "")
+				if err != nil {
+					return fmt.Errorf("missing registry client: %w", err)
+				}
+				client.SetRegistryClient(registryClient)
+			}
+
+			for _, name := range rulePlugins {
+				rule, err := lint.NewRulePlugin(settings, name)
+				if err != nil {
+					return fmt.Errorf("unable to load lint rule plugin %q: %w", name, err)
+				}
+				client.CustomRules = append(client.CustomRules, rule)
+			}
+
 			client.Namespace = settings.Namespace()
-			vals, err := valueOpts.MergeValues(getter.All(settings))
+			profiles, err := lintValueProfiles(valueOpts, valuesMatrix)
 			if err != nil {
 				return err
 			}
 
-			var message strings.Builder
-			failed := 0
-			errorsOrWarnings := 0
+			if outfmt != output.Table && (watch || serve) {
+				return errors.New("--output cannot be used with --watch or --serve")
+			}
 
-			for _, path := range paths {
-				result := client.Run([]string{path}, vals)
+			if reportFile != "" && (watch || serve) {
+				return errors.New("--report cannot be used with --watch or --serve")
+			}
 
-				// If there is no errors/warnings and quiet flag is set
-				// go to the next chart
-				hasWarningsOrErrors := action.HasWarningsOrErrors(result)
-				if hasWarningsOrErrors {
-					errorsOrWarnings++
-				}
-				if client.Quiet && !hasWarningsOrErrors {
-					continue
+			if serve {
+				if watch || valuesMatrix {
+					return errors.New("--serve cannot be used with --watch or --values-matrix")
 				}
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				return client.Serve(ctx, cmd.InOrStdin(), out, profiles[0].values)
+			}
 
-				fmt.Fprintf(&message, "==> Linting %s\n", path)
+			if watch {
+				client.Linter = lint.NewLinter()
+				ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+				defer stop()
+				return watchLint(ctx, out, paths, valueOpts.ValueFiles, watchLintRun(client, paths, valueOpts, valuesMatrix))
+			}
 
-				// All the Errors that are generated by a chart
-				// that failed a lint will be included in the
-				// results.Messages so we only need to print
-				// the Errors if there are no Messages.
-				if len(result.Messages) == 0 {
-					for _, err := range result.Errors {
-						fmt.Fprintf(&message, "Error %s\n", err)
-					}
+			report := runLint(client, paths, profiles)
+			if reportFile != "" {
+				if err := writeValidationReport(reportFile, report); err != nil {
+					return err
 				}
-
-				for _, msg := range result.Messages {
-					if !client.Quiet || msg.Severity > support.InfoSev {
-						fmt.Fprintf(&message, "%s\n", msg)
-					}
+			}
+			if outfmt != output.Table {
+				if err := outfmt.Write(out, report); err != nil {
+					return err
 				}
-
-				if len(result.Errors) != 0 {
-					failed++
+				if report.failed > 0 {
+					return errors.New(report.summary)
 				}
-
-				// Adding extra new line here to break up the
-				// results, stops this from being a big wall of
-				// text and makes it easier to follow.
-				fmt.Fprint(&message, "\n")
+				return nil
 			}
 
-			fmt.Fprint(out, message.String())
-
-			summary := fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), failed)
-			if failed > 0 {
-				return errors.New(summary)
+			fmt.Fprint(out, report.output)
+			if report.failed > 0 {
+				return errors.New(report.summary)
 			}
-			if !client.Quiet || errorsOrWarnings > 0 {
-				fmt.Fprintln(out, summary)
+			if !client.Quiet || report.errorsOrWarnings > 0 {
+				fmt.Fprintln(out, report.summary)
 			}
 			return nil
 		},
@@ -150,7 +254,211 @@ func newLintCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&client.Quiet, "quiet", false, "print only warnings and errors")
 	f.BoolVar(&client.SkipSchemaValidation, "skip-schema-validation", false, "if set, disables JSON schema validation")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for capabilities and deprecation checks")
+	f.BoolVar(&watch, "watch", false, "keep running and lint again whenever a file in the chart or a values file changes")
+	f.BoolVar(&serve, "serve", false, "serve lint diagnostics to an editor over JSON-RPC on stdin and stdout instead of linting PATH")
+	f.BoolVar(&valuesMatrix, "values-matrix", false, "lint the chart once per values file given with -f/--values instead of once with the merged values")
+	f.StringSliceVar(&rulePlugins, "rule-plugin", nil, "run the lint rules of this installed lint plugin (can specify multiple or separate values with commas)")
+	f.BoolVar(&recommendedLabels, "recommended-labels", false, "warn when rendered resources are missing the labels recommended by the Helm best practices")
+	f.StringSliceVar(&client.RequiredLabels, "require-label", nil, "warn when rendered resources are missing this label (can specify multiple or separate values with commas)")
+	f.StringSliceVar(&client.RequiredAnnotations, "require-annotation", nil, "warn when rendered resources are missing this annotation (can specify multiple or separate values with commas)")
+	f.StringSliceVar(&client.AllowedMaintainers, "allowed-maintainers", nil, "fail unless every maintainer has an email matching one of these emails or domains (can specify multiple or separate values with commas)")
+	f.IntVar(&client.MinMaintainers, "min-maintainers", 0, "fail unless the chart lists at least this many maintainers")
+	f.BoolVar(&client.RequirePinnedImages, "require-pinned-images", false, "fail when a container image has neither a digest nor a tag other than latest")
+	f.BoolVar(&client.RequireImageDigests, "require-image-digests", false, "fail when a container image is not pinned to a digest")
+	f.StringSliceVar(&client.AllowedRegistries, "allowed-registries", nil, "fail when a container image is not from one of these registries or registry paths (can specify multiple or separate values with commas)")
+	f.BoolVar(&client.VerifyImages, "online", false, "fail when a container image cannot be found in its registry")
+	f.StringVar(&capabilitiesFile, "capabilities-file", "", "check the prerequisites in the requires section of Chart.yaml against the cluster described in this YAML file of apiVersions, nodes and storageClasses")
+	f.BoolVar(&client.CheckEmptyOutput, "check-empty-output", false, "warn when a template renders no content, and fail when the chart renders no resources")
+	f.BoolVar(&client.CheckSelectors, "check-selectors", false, "warn when a workload selects pods by labels that change between releases, or a Service selects none of the pods of the chart")
+	f.BoolVar(&client.CheckIngress, "check-ingress", false, "warn when an Ingress or HTTPRoute routes to a Service or port the chart does not render, or uses a TLS secret neither rendered nor documented as external")
+	f.BoolVar(&client.CheckAvailability, "check-availability", false, "warn when a PodDisruptionBudget allows no disruption, or an autoscaler conflicts with the replica count of the workload it scales")
+	f.StringVar(&reportFile, "report", "", "write a JSON validation report of the findings of every chart, grouped by category, to this file")
 	addValueOptionsFlags(f, valueOpts)
+	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
+
+// lintValueProfile is a set of values a chart is linted with. The name is
+// empty unless the chart is linted once per values file.
+type lintValueProfile struct {
+	name   string
+	values map[string]interface{}
+}
+
+// lintValueProfiles returns the values to lint with. Without matrix, all value
+// options are merged into a single profile. With matrix, each values file is
+// its own profile, combined with the --set style options.
+func lintValueProfiles(valueOpts *values.Options, matrix bool) ([]lintValueProfile, error) {
+	p := getter.All(settings)
+	if !matrix {
+		vals, err := valueOpts.MergeValues(p)
+		if err != nil {
+			return nil, err
+		}
+		return []lintValueProfile{{values: vals}}, nil
+	}
+
+	if len(valueOpts.ValueFiles) == 0 {
+		return nil, errors.New("--values-matrix requires at least one values file")
+	}
+	profiles := make([]lintValueProfile, 0, len(valueOpts.ValueFiles))
+	for _, file := range valueOpts.ValueFiles {
+		opts := *valueOpts
+		opts.ValueFiles = []string{file}
+		vals, err := opts.MergeValues(p)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, lintValueProfile{name: file, values: vals})
+	}
+	return profiles, nil
+}
+
+// watchLintRun returns the run function of watchLint. The values files are
+// watched too, so they are read again on every run.
+func watchLintRun(client *action.Lint, paths []string, valueOpts *values.Options, matrix bool) func() *lintReport {
+	return func() *lintReport {
+		profiles, err := lintValueProfiles(valueOpts, matrix)
+		if err != nil {
+			return &lintReport{summary: fmt.Sprintf("Error: %s", err), failed: len(paths), diagnostics: []string{err.Error()}}
+		}
+		return runLint(client, paths, profiles)
+	}
+}
+
+// lintReport is the outcome of linting a set of charts.
+type lintReport struct {
+	// output is the full report, excluding the summary.
+	output           string
+	summary          string
+	failed           int
+	errorsOrWarnings int
+	// diagnostics lists every reported issue, qualified by the chart and the
+	// values it was found with.
+	diagnostics []string
+	// charts has the result of every chart and value profile, and stats the
+	// summary of all of them, for the structured output formats.
+	charts []lintChartResult
+	stats  action.LintSummary
+}
+
+// lintChartResult is the result of linting a chart with a value profile.
+type lintChartResult struct {
+	Path     string            `json:"path"`
+	Values   string            `json:"values,omitempty"`
+	Messages []support.Message `json:"messages"`
+	// Errors are reported when the chart could not be linted at all.
+	Errors []string `json:"errors,omitempty"`
+
+	// all has every message, even those hidden by --quiet, rules the rule
+	// that reported each of them, and failed is set when the chart failed
+	// the lint, for the validation report.
+	all    []support.Message
+	rules  []string
+	failed bool
+}
+
+func (r *lintReport) Kind() string {
+	return "LintReport"
+}
+
+func (r *lintReport) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, r.structured())
+}
+
+func (r *lintReport) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, r.structured())
+}
+
+func (r *lintReport) WriteTable(out io.Writer) error {
+	_, err := fmt.Fprint(out, r.output)
+	return err
+}
+
+func (r *lintReport) structured() interface{} {
+	return struct {
+		Charts  []lintChartResult  `json:"charts"`
+		Summary action.LintSummary `json:"summary"`
+	}{r.charts, r.stats}
+}
+
+// runLint lints every chart with every value profile.
+func runLint(client *action.Lint, paths []string, profiles []lintValueProfile) *lintReport {
+	var message strings.Builder
+	report := &lintReport{charts: []lintChartResult{}, stats: action.NewLintSummary()}
+
+	for _, path := range paths {
+		chartFailed := false
+		for _, profile := range profiles {
+			result := client.Run([]string{path}, profile.values)
+			report.stats.Add(result.Summary)
+			chart := lintChartResult{Path: path, Values: profile.name, Messages: []support.Message{}, all: result.Messages, rules: result.MessageRules, failed: len(result.Errors) != 0}
+
+			// If there is no errors/warnings and quiet flag is set
+			// go to the next chart
+			hasWarningsOrErrors := action.HasWarningsOrErrors(result)
+			if hasWarningsOrErrors {
+				report.errorsOrWarnings++
+			}
+			if len(result.Errors) != 0 {
+				chartFailed = true
+			}
+			if len(result.Messages) == 0 {
+				for _, err := range result.Errors {
+					chart.Errors = append(chart.Errors, err.Error())
+				}
+			}
+			for _, msg := range result.Messages {
+				if !client.Quiet || msg.Severity > support.InfoSev {
+					chart.Messages = append(chart.Messages, msg)
+				}
+			}
+			report.charts = append(report.charts, chart)
+
+			if client.Quiet && !hasWarningsOrErrors {
+				continue
+			}
+
+			source := path
+			if profile.name != "" {
+				source = fmt.Sprintf("%s with values %s", path, profile.name)
+			}
+			fmt.Fprintf(&message, "==> Linting %s\n", source)
+
+			// All the Errors that are generated by a chart
+			// that failed a lint will be included in the
+			// results.Messages so we only need to print
+			// the Errors if there are no Messages.
+			if len(result.Messages) == 0 {
+				for _, err := range result.Errors {
+					fmt.Fprintf(&message, "Error %s\n", err)
+					report.diagnostics = append(report.diagnostics, fmt.Sprintf("%s: Error %s", source, err))
+				}
+			}
+
+			for _, msg := range result.Messages {
+				if !client.Quiet || msg.Severity > support.InfoSev {
+					fmt.Fprintf(&message, "%s\n", msg)
+					report.diagnostics = append(report.diagnostics, fmt.Sprintf("%s: %s", source, msg))
+				}
+			}
+
+			// Adding extra new line here to break up the
+			// results, stops this from being a big wall of
+			// text and makes it easier to follow.
+			fmt.Fprint(&message, "\n")
+		}
+		if chartFailed {
+			report.failed++
+		}
+	}
+
+	report.output = message.String()
+	report.summary = fmt.Sprintf("%d chart(s) linted, %d chart(s) failed", len(paths), report.failed)
+	// Charts linted with several value profiles count once, as in the
+	// summary line.
+	report.stats.ChartsScanned = len(paths)
+	report.stats.ChartsFailed = report.failed
+	return report
+}
@@ -0,0 +1,120 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
+)
+
+// validationReport is the consolidated report written by 'helm lint --report',
+// with the findings of every chart and value profile.
+type validationReport struct {
+	Charts  []validationChart `json:"charts"`
+	Summary validationSummary `json:"summary"`
+}
+
+// validationChart is the result of validating a chart with a value profile.
+type validationChart struct {
+	Path   string `json:"path"`
+	Values string `json:"values,omitempty"`
+	// Valid is false when the chart failed the lint.
+	Valid    bool                `json:"valid"`
+	Findings []validationFinding `json:"findings"`
+	// Errors are reported when the chart could not be linted at all.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// validationFinding is a lint message, with the category of what it checks.
+type validationFinding struct {
+	Category string `json:"category"`
+	Rule     string `json:"rule,omitempty"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// validationSummary counts the charts by outcome, and the findings by
+// category and severity name.
+type validationSummary struct {
+	Valid    int                       `json:"valid"`
+	Invalid  int                       `json:"invalid"`
+	Findings map[string]map[string]int `json:"findings"`
+}
+
+// newValidationReport builds the validation report of a lint run.
+func newValidationReport(report *lintReport) *validationReport {
+	v := &validationReport{
+		Charts:  make([]validationChart, 0, len(report.charts)),
+		Summary: validationSummary{Findings: make(map[string]map[string]int)},
+	}
+	for _, c := range report.charts {
+		chart := validationChart{
+			Path:     c.Path,
+			Values:   c.Values,
+			Valid:    !c.failed,
+			Findings: make([]validationFinding, 0, len(c.all)),
+			Errors:   c.Errors,
+		}
+		for i, msg := range c.all {
+			var rule string
+			if i < len(c.rules) {
+				rule = c.rules[i]
+			}
+			finding := validationFinding{
+				Category: rules.Category(msg, rule),
+				Rule:     rule,
+				Severity: support.SeverityName(msg.Severity),
+				Path:     msg.Path,
+			}
+			if msg.Err != nil {
+				finding.Message = msg.Err.Error()
+			}
+			chart.Findings = append(chart.Findings, finding)
+
+			counts := v.Summary.Findings[finding.Category]
+			if counts == nil {
+				counts = make(map[string]int)
+				v.Summary.Findings[finding.Category] = counts
+			}
+			counts[finding.Severity]++
+		}
+		if chart.Valid {
+			v.Summary.Valid++
+		} else {
+			v.Summary.Invalid++
+		}
+		v.Charts = append(v.Charts, chart)
+	}
+	return v
+}
+
+// writeValidationReport writes the validation report of a lint run to a file.
+func writeValidationReport(path string, report *lintReport) error {
+	data, err := json.MarshalIndent(newValidationReport(report), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write the validation report: %w", err)
+	}
+	return nil
+}
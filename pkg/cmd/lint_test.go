@@ -17,8 +17,16 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/rules"
+	"helm.sh/helm/v4/pkg/chart/v2/lint/support"
 )
 
 func TestLintCmdWithSubchartsFlag(t *testing.T) {
@@ -37,6 +45,164 @@ func TestLintCmdWithSubchartsFlag(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestLintCmdWithLabelConventions(t *testing.T) {
+	testChart := "testdata/testcharts/alpine"
+	tests := []cmdTestCase{{
+		name:   "lint chart using --recommended-labels flag",
+		cmd:    fmt.Sprintf("lint --recommended-labels %s", testChart),
+		golden: "output/lint-recommended-labels.txt",
+	}, {
+		name:      "lint chart using --require-annotation and --strict flags",
+		cmd:       fmt.Sprintf("lint --strict --require-annotation example.com/owner %s", testChart),
+		golden:    "output/lint-require-annotation-strict.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithCapabilitiesFile(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-requires"
+	tests := []cmdTestCase{{
+		name:   "lint chart with prerequisites",
+		cmd:    fmt.Sprintf("lint %s", testChart),
+		golden: "output/lint-requires.txt",
+	}, {
+		name:      "lint chart with prerequisites using --capabilities-file flag",
+		cmd:       fmt.Sprintf("lint --capabilities-file testdata/capabilities.yaml %s", testChart),
+		golden:    "output/lint-requires-capabilities-file.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithMaintainerPolicy(t *testing.T) {
+	testChart := "testdata/testcharts/alpine"
+	tests := []cmdTestCase{{
+		name:      "lint chart using --min-maintainers flag",
+		cmd:       fmt.Sprintf("lint --min-maintainers 2 %s", testChart),
+		golden:    "output/lint-min-maintainers.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithImagePolicy(t *testing.T) {
+	testChart := "testdata/testcharts/alpine"
+	tests := []cmdTestCase{{
+		name:   "lint chart using --require-pinned-images flag",
+		cmd:    fmt.Sprintf("lint --require-pinned-images %s", testChart),
+		golden: "output/lint-require-pinned-images.txt",
+	}, {
+		name:      "lint chart using --allowed-registries flag",
+		cmd:       fmt.Sprintf("lint --allowed-registries registry.example.com %s", testChart),
+		golden:    "output/lint-allowed-registries.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdServeConflicts(t *testing.T) {
+	tests := []cmdTestCase{{
+		name:      "lint using --serve and --watch flags",
+		cmd:       "lint --serve --watch",
+		golden:    "output/lint-serve-watch.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithOutputFlag(t *testing.T) {
+	_, out, err := executeActionCommand("lint -o json testdata/testcharts/alpine testdata/testcharts/chart-bad-requirements")
+	if err == nil {
+		t.Fatal("expected the chart with bad requirements to fail")
+	}
+
+	var report struct {
+		Charts []struct {
+			Path     string            `json:"path"`
+			Messages []support.Message `json:"messages"`
+		} `json:"charts"`
+		Summary action.LintSummary `json:"summary"`
+	}
+	// The error is printed after the report.
+	if err := json.NewDecoder(strings.NewReader(out)).Decode(&report); err != nil {
+		t.Fatalf("output is not JSON: %s\n%s", err, out)
+	}
+	if len(report.Charts) != 2 || report.Charts[0].Path != "testdata/testcharts/alpine" || len(report.Charts[0].Messages) != 1 {
+		t.Errorf("unexpected charts %+v", report.Charts)
+	}
+	summary := report.Summary
+	if summary.ChartsScanned != 2 || summary.ChartsFailed != 1 {
+		t.Errorf("expected 2 charts scanned and 1 failed, got %d and %d", summary.ChartsScanned, summary.ChartsFailed)
+	}
+	if summary.Messages["ERROR"] != 2 || summary.Messages["WARNING"] != 1 || summary.Messages["INFO"] != 1 {
+		t.Errorf("unexpected message counts %v", summary.Messages)
+	}
+	if len(summary.Rules) == 0 || summary.Rules[0].Rule != "chartfile" {
+		t.Errorf("unexpected rules %+v", summary.Rules)
+	}
+
+	tests := []cmdTestCase{{
+		name:      "lint using --output and --watch flags",
+		cmd:       "lint -o json --watch testdata/testcharts/alpine",
+		golden:    "output/lint-output-watch.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
+func TestLintCmdWithReportFlag(t *testing.T) {
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+	cmd := fmt.Sprintf("lint --quiet --kube-version 1.22.0 --recommended-labels --report %s testdata/testcharts/alpine testdata/testcharts/chart-with-deprecated-api testdata/testcharts/chart-with-schema-negative", reportFile)
+	if _, _, err := executeActionCommand(cmd); err == nil {
+		t.Fatal("expected the chart not matching its schema to fail")
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report validationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("report is not JSON: %s\n%s", err, data)
+	}
+	if len(report.Charts) != 3 || !report.Charts[0].Valid || !report.Charts[1].Valid || report.Charts[2].Valid {
+		t.Fatalf("unexpected charts %+v", report.Charts)
+	}
+	if report.Summary.Valid != 2 || report.Summary.Invalid != 1 {
+		t.Errorf("expected 2 valid charts and 1 invalid, got %d and %d", report.Summary.Valid, report.Summary.Invalid)
+	}
+
+	categories := func(c validationChart) map[string]string {
+		found := map[string]string{}
+		for _, f := range c.Findings {
+			found[f.Category] = f.Rule
+		}
+		return found
+	}
+	// Findings hidden by --quiet are still reported.
+	if got := categories(report.Charts[0]); got[rules.CategoryPolicy] != "labels" || got[rules.CategoryLint] != "chartfile" {
+		t.Errorf("expected a labels policy finding and a chartfile finding, got %v", report.Charts[0].Findings)
+	}
+	if got := categories(report.Charts[1]); got[rules.CategoryDeprecation] != "templates" {
+		t.Errorf("expected a deprecation finding, got %v", report.Charts[1].Findings)
+	}
+	if got := categories(report.Charts[2]); got[rules.CategorySchema] != "templates" {
+		t.Errorf("expected a schema finding, got %v", report.Charts[2].Findings)
+	}
+	if report.Summary.Findings[rules.CategorySchema]["ERROR"] == 0 || report.Summary.Findings[rules.CategoryDeprecation]["WARNING"] != 1 {
+		t.Errorf("unexpected finding counts %v", report.Summary.Findings)
+	}
+
+	tests := []cmdTestCase{{
+		name:      "lint using --report and --watch flags",
+		cmd:       "lint --report report.json --watch testdata/testcharts/alpine",
+		golden:    "output/lint-report-watch.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestLintCmdWithQuietFlag(t *testing.T) {
 	testChart1 := "testdata/testcharts/alpine"
 	testChart2 := "testdata/testcharts/chart-bad-requirements"
@@ -91,6 +257,22 @@ func TestLintCmdWithKubeVersionFlag(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestLintCmdWithValuesMatrix(t *testing.T) {
+	testChart := "testdata/testcharts/chart-with-schema"
+	tests := []cmdTestCase{{
+		name:      "lint chart once per values file",
+		cmd:       fmt.Sprintf("lint --values-matrix -f %[1]s/values.yaml -f %[1]s/extra-values.yaml %[1]s", testChart),
+		golden:    "output/lint-values-matrix.txt",
+		wantError: true,
+	}, {
+		name:      "lint chart with --values-matrix but no values file",
+		cmd:       fmt.Sprintf("lint --values-matrix %s", testChart),
+		golden:    "output/lint-values-matrix-no-files.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestLintFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "lint", true)
 	checkFileCompletion(t, "lint mypath", true) // Multiple paths can be given
@@ -0,0 +1,203 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// lintWatchDelay is how long the watcher waits for further changes before
+// linting again, so that saving several files at once triggers a single run.
+var lintWatchDelay = 200 * time.Millisecond
+
+// watchLint lints the charts in paths, then lints them again whenever a file
+// under one of the paths or one of the local valueFiles changes, until ctx is
+// done. After the first run, only the issues that appeared or were resolved
+// since the previous run are printed.
+func watchLint(ctx context.Context, out io.Writer, paths, valueFiles []string, run func() *lintReport) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to watch charts: %w", err)
+	}
+	defer watcher.Close()
+
+	w := &lintWatcher{Watcher: watcher, dirs: map[string]bool{}, valueFiles: map[string]bool{}}
+	for _, path := range paths {
+		if err := w.addChart(path); err != nil {
+			return fmt.Errorf("unable to watch %s: %w", path, err)
+		}
+	}
+	for _, file := range valueFiles {
+		if err := w.addValueFile(file); err != nil {
+			return fmt.Errorf("unable to watch %s: %w", file, err)
+		}
+	}
+
+	previous := run()
+	fmt.Fprint(out, previous.output)
+	fmt.Fprintln(out, previous.summary)
+	fmt.Fprintln(out, "\nWatching for changes. Press Ctrl+C to stop.")
+
+	for {
+		changed, err := waitForLintChanges(ctx, w)
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+
+		current := run()
+		fmt.Fprintf(out, "\n==> Changed: %s\n", changed[0])
+		if len(changed) > 1 {
+			fmt.Fprintf(out, "    and %d other file(s)\n", len(changed)-1)
+		}
+		added, resolved := diffDiagnostics(previous.diagnostics, current.diagnostics)
+		for _, d := range added {
+			fmt.Fprintf(out, "+ %s\n", d)
+		}
+		for _, d := range resolved {
+			fmt.Fprintf(out, "- %s\n", d)
+		}
+		if len(added) == 0 && len(resolved) == 0 {
+			fmt.Fprintln(out, "No new or resolved issues.")
+		}
+		fmt.Fprintln(out, current.summary)
+		previous = current
+	}
+}
+
+// waitForLintChanges blocks until files change and no further change follows
+// within lintWatchDelay. It returns the changed files, or none if ctx is done.
+func waitForLintChanges(ctx context.Context, watcher *lintWatcher) ([]string, error) {
+	var changed []string
+	seen := map[string]bool{}
+	var settle <-chan time.Time
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-settle:
+			return changed, nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("watching charts failed: %w", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil, nil
+			}
+			// Permission changes do not affect the lint results.
+			if event.Op == fsnotify.Chmod || !watcher.relevant(event.Name) {
+				continue
+			}
+			// New directories are not watched automatically.
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					_ = watcher.addChart(event.Name)
+				}
+			}
+			if !seen[event.Name] {
+				seen[event.Name] = true
+				changed = append(changed, event.Name)
+			}
+			settle = time.After(lintWatchDelay)
+		}
+	}
+}
+
+// lintWatcher watches the files of charts and of values files.
+type lintWatcher struct {
+	*fsnotify.Watcher
+	// dirs are the watched paths of the charts.
+	dirs map[string]bool
+	// valueFiles are the watched values files. They are watched through their
+	// directory, as editors often save a file by replacing it.
+	valueFiles map[string]bool
+}
+
+// addChart watches path and, if it is a directory, every directory below it.
+func (w *lintWatcher) addChart(path string) error {
+	return filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || name == path {
+			name = filepath.Clean(name)
+			w.dirs[name] = true
+			return w.Add(name)
+		}
+		return nil
+	})
+}
+
+// addValueFile watches a values file. Values read from standard input or from
+// a URL cannot be watched and are ignored.
+func (w *lintWatcher) addValueFile(file string) error {
+	if file == "-" || strings.Contains(file, "://") {
+		return nil
+	}
+	file = filepath.Clean(file)
+	w.valueFiles[file] = true
+	return w.Add(filepath.Dir(file))
+}
+
+// relevant reports whether a change to the named file may change the lint
+// results, as the directory of a values file may hold unrelated files.
+func (w *lintWatcher) relevant(name string) bool {
+	name = filepath.Clean(name)
+	return w.dirs[name] || w.dirs[filepath.Dir(name)] || w.valueFiles[name]
+}
+
+// diffDiagnostics returns the diagnostics in current that are not in previous,
+// and those in previous that are not in current, each in their original order.
+func diffDiagnostics(previous, current []string) (added, resolved []string) {
+	before := make(map[string]int, len(previous))
+	for _, d := range previous {
+		before[d]++
+	}
+	after := make(map[string]int, len(current))
+	for _, d := range current {
+		after[d]++
+	}
+	for _, d := range current {
+		if before[d] > 0 {
+			before[d]--
+			continue
+		}
+		added = append(added, d)
+	}
+	for _, d := range previous {
+		if after[d] > 0 {
+			after[d]--
+			continue
+		}
+		resolved = append(resolved, d)
+	}
+	return added, resolved
+}
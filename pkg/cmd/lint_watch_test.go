@@ -0,0 +1,162 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/v2/lint"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli/values"
+)
+
+func TestDiffDiagnostics(t *testing.T) {
+	added, resolved := diffDiagnostics(
+		[]string{"a", "b", "b", "c"},
+		[]string{"b", "c", "d"},
+	)
+	assert.Equal(t, []string{"d"}, added)
+	assert.Equal(t, []string{"a", "b"}, resolved)
+}
+
+// syncBuffer is a bytes.Buffer that can be written and read concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchLint(t *testing.T) {
+	defer func(d time.Duration) { lintWatchDelay = d }(lintWatchDelay)
+	lintWatchDelay = 10 * time.Millisecond
+
+	dir, err := chartutil.Create("watched", t.TempDir())
+	require.NoError(t, err)
+
+	client := action.NewLint()
+	client.Linter = lint.NewLinter()
+	paths := []string{dir}
+	profiles := []lintValueProfile{{values: map[string]interface{}{}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- watchLint(ctx, out, paths, nil, func() *lintReport {
+			return runLint(client, paths, profiles)
+		})
+	}()
+
+	waitForOutput := func(substr string) {
+		t.Helper()
+		waitForWatchOutput(t, out, substr)
+	}
+
+	waitForOutput("Watching for changes")
+	assert.Contains(t, out.String(), "1 chart(s) linted, 0 chart(s) failed")
+
+	broken := filepath.Join(dir, "templates", "broken.yaml")
+	require.NoError(t, os.WriteFile(broken, []byte("{{ .Values.missing.field }"), 0644))
+	waitForOutput("+ " + dir + ": [ERROR] templates/: parse error at (watched/templates/broken.yaml:1)")
+	waitForOutput("1 chart(s) linted, 1 chart(s) failed")
+
+	require.NoError(t, os.Remove(broken))
+	waitForOutput("- " + dir + ": [ERROR] templates/: parse error at (watched/templates/broken.yaml:1)")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("watchLint did not stop after the context was cancelled")
+	}
+}
+
+func TestWatchLintValuesFile(t *testing.T) {
+	defer func(d time.Duration) { lintWatchDelay = d }(lintWatchDelay)
+	lintWatchDelay = 10 * time.Millisecond
+
+	dir, err := chartutil.Create("watched", t.TempDir())
+	require.NoError(t, err)
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesFile, []byte("replicaCount: 2\n"), 0644))
+
+	client := action.NewLint()
+	client.Linter = lint.NewLinter()
+	paths := []string{dir}
+	valueOpts := &values.Options{ValueFiles: []string{valuesFile}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- watchLint(ctx, out, paths, valueOpts.ValueFiles, watchLintRun(client, paths, valueOpts, false))
+	}()
+
+	waitForWatchOutput(t, out, "Watching for changes")
+	assert.Contains(t, out.String(), "1 chart(s) linted, 0 chart(s) failed")
+
+	require.NoError(t, os.WriteFile(valuesFile, []byte("replicaCount: [\n"), 0644))
+	waitForWatchOutput(t, out, "==> Changed: "+valuesFile)
+	waitForWatchOutput(t, out, "+ failed to parse "+valuesFile)
+
+	require.NoError(t, os.WriteFile(valuesFile, []byte("replicaCount: 3\n"), 0644))
+	waitForWatchOutput(t, out, "- failed to parse "+valuesFile)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("watchLint did not stop after the context was cancelled")
+	}
+}
+
+func waitForWatchOutput(t *testing.T, out *syncBuffer, substr string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !strings.Contains(out.String(), substr) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected output to contain %q, got:\n%s", substr, out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -221,6 +221,10 @@ func (w *releaseListWriter) WriteTable(out io.Writer) error {
 	return output.EncodeTable(out, table)
 }
 
+func (w *releaseListWriter) Kind() string {
+	return "ReleaseList"
+}
+
 func (w *releaseListWriter) WriteJSON(out io.Writer) error {
 	return output.EncodeJSON(out, w.releases)
 }
@@ -213,6 +213,11 @@ func TestListCmd(t *testing.T) {
 		cmd:    "list --short --output json",
 		golden: "output/list-short-json.txt",
 		rels:   releaseFixture,
+	}, {
+		name:   "list releases in versioned json output format",
+		cmd:    "list --max 1 --output json/v1",
+		golden: "output/list-max-json-v1.txt",
+		rels:   releaseFixture,
 	}, {
 		name:   "list superseded releases",
 		cmd:    "list --superseded",
@@ -339,6 +339,13 @@ func pluginDynamicComp(plug plugin.Plugin, cmd *cobra.Command, args []string, to
 		return nil, cobra.ShellCompDirectiveDefault
 	}
 
+	// Completion cannot ask for consent, so it only runs plugins that were
+	// granted their permissions before.
+	if granted, err := pluginConsentStore().Granted(plug.Metadata()); err != nil || !granted {
+		cobra.CompDebugln(fmt.Sprintf("Permissions of plugin %q not granted", plug.Metadata().Name), settings.Debug)
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
 	var ignoreFlags bool
 	if cliConfig, ok := subprocessPlug.Metadata().Config.(*schema.ConfigCLIV1); ok {
 		ignoreFlags = cliConfig.IgnoreFlags
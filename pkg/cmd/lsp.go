@@ -0,0 +1,61 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/chart/v2/lint"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/lsp"
+)
+
+const lspDesc = `
+Start a Language Server Protocol server on stdin and stdout for editing charts.
+
+Editors that support the Language Server Protocol can run this command to get:
+
+- completion of '.Values' paths, from the chart's values.schema.json and
+  values.yaml
+- go to definition for the templates named in 'include' and 'template' calls,
+  across the chart and its subcharts
+- documentation of template functions on hover
+- the findings of 'helm lint' as diagnostics, updated whenever a file of the
+  chart is saved
+`
+
+func newLSPCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "lsp",
+		Short:             "start a language server for chart authoring on stdin and stdout",
+		Long:              lspDesc,
+		Args:              require.NoArgs,
+		ValidArgsFunction: noMoreArgsCompFunc,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			linter := lint.NewServer(nil)
+			linter.Namespace = settings.Namespace()
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return lsp.NewServer(linter).Serve(ctx, cmd.InOrStdin(), out)
+		},
+	}
+	return cmd
+}
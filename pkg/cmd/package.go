@@ -26,6 +26,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/downloader"
 	"helm.sh/helm/v4/pkg/getter"
@@ -45,6 +46,15 @@ provide '--keyring path/to/secret/keys' and '--key keyname'.
 
 If '--keyring' is not specified, Helm usually defaults to the public keyring
 unless your environment is otherwise configured.
+
+Use '--reproducible' to package identical charts into byte-identical archives.
+The files in the archive are written in a stable order, and their modification
+time is taken from the SOURCE_DATE_EPOCH environment variable, or is the Unix
+epoch if it is not set.
+
+Use '--format' to write a zstd compressed archive (.tar.zst), which packs and
+unpacks large charts faster, or an uncompressed tar archive (.tar). Helm loads
+charts from archives in any of these formats.
 `
 
 func newPackageCmd(out io.Writer) *cobra.Command {
@@ -126,6 +136,8 @@ func newPackageCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&client.AppVersion, "app-version", "", "set the appVersion on the chart to this version")
 	f.StringVarP(&client.Destination, "destination", "d", ".", "location to write the chart.")
 	f.BoolVarP(&client.DependencyUpdate, "dependency-update", "u", false, `update dependencies from "Chart.yaml" to dir "charts/" before packaging`)
+	f.BoolVar(&client.Reproducible, "reproducible", false, "package identical charts into byte-identical archives, using SOURCE_DATE_EPOCH as the modification time of the files")
+	f.StringVar((*string)(&client.Format), "format", string(chartutil.ArchiveGzip), fmt.Sprintf("format of the chart archive. One of %v", chartutil.ArchiveFormats))
 	f.StringVar(&client.Username, "username", "", "chart repository username where to locate the requested chart")
 	f.StringVar(&client.Password, "password", "", "chart repository password where to locate the requested chart")
 	f.StringVar(&client.CertFile, "cert-file", "", "identify HTTPS client using this SSL certificate file")
@@ -0,0 +1,97 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/pkg/helmpath"
+)
+
+// acceptPluginPermissionsEnv is the environment variable granting plugins
+// the permissions they declare without asking, when set to 1.
+const acceptPluginPermissionsEnv = "HELM_ACCEPT_PLUGIN_PERMISSIONS"
+
+// errPluginPermissionsDeclined is returned when the user does not grant a
+// plugin its permissions.
+var errPluginPermissionsDeclined = errors.New("plugin permissions were not granted")
+
+// stdinIsTerminal reports whether the user can be prompted on stdin.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func pluginConsentStore() *plugin.ConsentStore {
+	return plugin.NewConsentStore(helmpath.ConfigPath("plugin-consent.yaml"))
+}
+
+// grantPluginPermissions makes sure the user consents to the permissions of
+// the plugin before it runs, asking for them unless they were granted before,
+// accept is set or HELM_ACCEPT_PLUGIN_PERMISSIONS is set to 1. If the user
+// cannot be asked, the permissions are not granted.
+func grantPluginPermissions(m plugin.Metadata, accept bool, in io.Reader, out io.Writer) error {
+	store := pluginConsentStore()
+	if granted, err := store.Granted(m); err != nil || granted {
+		return err
+	}
+
+	switch {
+	case accept || os.Getenv(acceptPluginPermissionsEnv) == "1":
+	case stdinIsTerminal():
+		if !promptPluginPermissions(m, in, out) {
+			return errPluginPermissionsDeclined
+		}
+	default:
+		return fmt.Errorf("%w: plugin %q requests %s. Run helm in a terminal to be asked for them, or set %s=1 to grant them",
+			errPluginPermissionsDeclined, m.Name, strings.Join(m.Permissions.Describe(), ", "), acceptPluginPermissionsEnv)
+	}
+	return store.Grant(m)
+}
+
+// requirePluginConsent makes every plugin ask for the consent of the user
+// to its permissions on its first run.
+func requirePluginConsent() {
+	plugin.SetConsentCheck(func(m plugin.Metadata) error {
+		return grantPluginPermissions(m, false, os.Stdin, os.Stderr)
+	})
+}
+
+// promptPluginPermissions lists the permissions of the plugin and reports
+// whether the user grants them.
+func promptPluginPermissions(m plugin.Metadata, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "Plugin %q requests:\n", m.Name)
+	for _, line := range m.Permissions.Describe() {
+		fmt.Fprintf(out, "  - %s\n", line)
+	}
+	fmt.Fprint(out, "Grant these permissions? [y/N]: ")
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/internal/plugin"
+	"helm.sh/helm/v4/internal/test/ensure"
+)
+
+func setStdinIsTerminal(t *testing.T, terminal bool) {
+	t.Helper()
+	orig := stdinIsTerminal
+	stdinIsTerminal = func() bool { return terminal }
+	t.Cleanup(func() { stdinIsTerminal = orig })
+}
+
+func TestGrantPluginPermissions(t *testing.T) {
+	ensure.HelmHome(t)
+	setStdinIsTerminal(t, true)
+
+	m := plugin.Metadata{Name: "restricted", Permissions: &plugin.Permissions{Network: true}}
+	granted := func() bool {
+		ok, err := pluginConsentStore().Granted(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok
+	}
+
+	var out bytes.Buffer
+	err := grantPluginPermissions(m, false, strings.NewReader("n\n"), &out)
+	if !errors.Is(err, errPluginPermissionsDeclined) {
+		t.Fatalf("Expected the permissions to be declined, got %v", err)
+	}
+	expected := "Plugin \"restricted\" requests:\n  - network access\nGrant these permissions? [y/N]: "
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+	if granted() {
+		t.Error("Expected the permissions not to be granted")
+	}
+
+	if err := grantPluginPermissions(m, false, strings.NewReader("yes\n"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !granted() {
+		t.Error("Expected the permissions to be granted")
+	}
+
+	// Granted permissions are not asked again.
+	out.Reset()
+	if err := grantPluginPermissions(m, false, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no prompt, got %q", out.String())
+	}
+
+	// Changed permissions are.
+	m.Permissions.Kubeconfig = true
+	if err := grantPluginPermissions(m, false, strings.NewReader(""), &out); !errors.Is(err, errPluginPermissionsDeclined) {
+		t.Fatalf("Expected the permissions to be declined, got %v", err)
+	}
+	if err := grantPluginPermissions(m, true, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !granted() {
+		t.Error("Expected the accepted permissions to be granted")
+	}
+}
+
+func TestGrantPluginPermissionsNonInteractive(t *testing.T) {
+	ensure.HelmHome(t)
+	setStdinIsTerminal(t, false)
+
+	m := plugin.Metadata{Name: "unrestricted"}
+	var out bytes.Buffer
+	err := grantPluginPermissions(m, false, strings.NewReader(""), &out)
+	if !errors.Is(err, errPluginPermissionsDeclined) {
+		t.Fatalf("Expected the permissions not to be granted without a terminal, got %v", err)
+	}
+	if !strings.Contains(err.Error(), acceptPluginPermissionsEnv) {
+		t.Errorf("Expected the error to mention %s, got %v", acceptPluginPermissionsEnv, err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no prompt, got %q", out.String())
+	}
+
+	t.Setenv(acceptPluginPermissionsEnv, "1")
+	if err := grantPluginPermissions(m, false, strings.NewReader(""), &out); err != nil {
+		t.Fatal(err)
+	}
+	granted, err := pluginConsentStore().Granted(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !granted {
+		t.Error("Expected the permissions to be granted with the environment override")
+	}
+}
+
+// grantPluginPermissionsForTest records the consent to the permissions of the
+// plugins in dir, in a temporary Helm home.
+func grantPluginPermissionsForTest(t *testing.T, dir string) {
+	t.Helper()
+	ensure.HelmHome(t)
+	plugins, err := plugin.LoadAll(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range plugins {
+		if err := pluginConsentStore().Grant(p.Metadata()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
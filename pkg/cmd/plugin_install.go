@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -36,6 +37,8 @@ type pluginInstallOptions struct {
 	// signing options
 	verify  bool
 	keyring string
+	// acceptPermissions grants the plugin its permissions without asking
+	acceptPermissions bool
 	// OCI-specific options
 	certFile              string
 	keyFile               string
@@ -55,6 +58,14 @@ alongside the tarball.
 For local development, plugins installed from local directories are automatically
 treated as "local dev" and do not require signatures.
 Use --verify=false to skip signature verification for remote plugins.
+
+Before the plugin runs for the first time, including its install hook, you are
+asked to grant the permissions it declares in plugin.yaml, such as network or
+Kubernetes configuration access. Plugins declaring no permissions run with your
+full user privileges. Use --accept-permissions, or set
+HELM_ACCEPT_PLUGIN_PERMISSIONS=1, to grant them without being asked, for
+example in scripts. Without a terminal to ask in, plugins whose permissions
+were not granted do not run.
 `
 
 func newPluginInstallCmd(out io.Writer) *cobra.Command {
@@ -83,6 +94,7 @@ func newPluginInstallCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.version, "version", "", "specify a version constraint. If this is not specified, the latest version is installed")
 	cmd.Flags().BoolVar(&o.verify, "verify", true, "verify the plugin signature before installing")
 	cmd.Flags().StringVar(&o.keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
+	cmd.Flags().BoolVar(&o.acceptPermissions, "accept-permissions", false, "grant the plugin the permissions it declares without asking")
 
 	// Add OCI-specific flags
 	cmd.Flags().StringVar(&o.certFile, "cert-file", "", "identify registry client using this SSL certificate file")
@@ -176,6 +188,13 @@ func (o *pluginInstallOptions) run(out io.Writer) error {
 		return fmt.Errorf("plugin is installed but unusable: %w", err)
 	}
 
+	if err := grantPluginPermissions(p.Metadata(), o.acceptPermissions, os.Stdin, out); err != nil {
+		if rmErr := os.RemoveAll(i.Path()); rmErr != nil {
+			slog.Debug("failed to remove plugin", "path", i.Path(), "error", rmErr)
+		}
+		return err
+	}
+
 	if err := runHook(p, plugin.Install); err != nil {
 		return err
 	}
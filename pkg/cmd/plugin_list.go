@@ -27,10 +27,25 @@ import (
 
 	"helm.sh/helm/v4/internal/plugin"
 	"helm.sh/helm/v4/internal/plugin/schema"
+	"helm.sh/helm/v4/pkg/cli/output"
 )
 
+type pluginElement struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Type       string `json:"type"`
+	APIVersion string `json:"apiVersion"`
+	Provenance string `json:"provenance"`
+	Source     string `json:"source"`
+}
+
+type pluginListWriter struct {
+	plugins []pluginElement
+}
+
 func newPluginListCmd(out io.Writer) *cobra.Command {
 	var pluginType string
+	var outfmt output.Format
 	cmd := &cobra.Command{
 		Use:               "list",
 		Aliases:           []string{"ls"},
@@ -50,8 +65,8 @@ func newPluginListCmd(out io.Writer) *cobra.Command {
 			// Get signing info for all plugins
 			signingInfo := plugin.GetSigningInfoForPlugins(plugins)
 
-			table := uitable.New()
-			table.AddRow("NAME", "VERSION", "TYPE", "APIVERSION", "PROVENANCE", "SOURCE")
+			// Initialize the array so no plugins returns an empty array instead of null
+			elements := make([]pluginElement, 0, len(plugins))
 			for _, p := range plugins {
 				m := p.Metadata()
 				sourceURL := m.SourceURL
@@ -63,19 +78,48 @@ func newPluginListCmd(out io.Writer) *cobra.Command {
 				if info, ok := signingInfo[m.Name]; ok {
 					signedStatus = info.Status
 				}
-				table.AddRow(m.Name, m.Version, m.Type, m.APIVersion, signedStatus, sourceURL)
+				elements = append(elements, pluginElement{
+					Name:       m.Name,
+					Version:    m.Version,
+					Type:       m.Type,
+					APIVersion: m.APIVersion,
+					Provenance: signedStatus,
+					Source:     sourceURL,
+				})
 			}
-			fmt.Fprintln(out, table)
-			return nil
+			return outfmt.Write(out, &pluginListWriter{elements})
 		},
 	}
 
 	f := cmd.Flags()
 	f.StringVar(&pluginType, "type", "", "Plugin type")
+	bindOutputFlag(cmd, &outfmt)
 
 	return cmd
 }
 
+func (w *pluginListWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.AddRow("NAME", "VERSION", "TYPE", "APIVERSION", "PROVENANCE", "SOURCE")
+	for _, p := range w.plugins {
+		table.AddRow(p.Name, p.Version, p.Type, p.APIVersion, p.Provenance, p.Source)
+	}
+	_, err := fmt.Fprintln(out, table)
+	return err
+}
+
+func (w *pluginListWriter) Kind() string {
+	return "PluginList"
+}
+
+func (w *pluginListWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.plugins)
+}
+
+func (w *pluginListWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.plugins)
+}
+
 // Returns all plugins from plugins, except those with names matching ignoredPluginNames
 func filterPlugins(plugins []plugin.Plugin, ignoredPluginNames []string) []plugin.Plugin {
 	// if ignoredPluginNames is nil or empty, just return plugins
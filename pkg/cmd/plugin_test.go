@@ -85,6 +85,7 @@ func TestManuallyProcessArgs(t *testing.T) {
 
 func TestLoadCLIPlugins(t *testing.T) {
 	settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
+	grantPluginPermissionsForTest(t, settings.PluginsDirectory)
 	settings.RepositoryConfig = "testdata/helmhome/helm/repositories.yaml"
 	settings.RepositoryCache = "testdata/helmhome/helm/repository"
 
@@ -163,6 +164,7 @@ func TestLoadCLIPlugins(t *testing.T) {
 
 func TestLoadPluginsWithSpace(t *testing.T) {
 	settings.PluginsDirectory = "testdata/helm home with space/helm/plugins"
+	grantPluginPermissionsForTest(t, settings.PluginsDirectory)
 	settings.RepositoryConfig = "testdata/helm home with space/helm/repositories.yaml"
 	settings.RepositoryCache = "testdata/helm home with space/helm/repository"
 
@@ -328,6 +330,7 @@ func TestPluginDynamicCompletion(t *testing.T) {
 		golden: "output/plugin_echo_no_directive.txt",
 		rels:   []*release.Release{},
 	}}
+	grantPluginPermissionsForTest(t, "testdata/helmhome/helm/plugins")
 	for _, test := range tests {
 		settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
 		runTestCmd(t, []cmdTestCase{test})
@@ -425,3 +428,15 @@ func TestPluginUpdateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "plugin update", false)
 	checkFileCompletion(t, "plugin update myplugin", false)
 }
+
+func TestPluginListCmd(t *testing.T) {
+	defer resetEnv()()
+	settings.PluginsDirectory = "testdata/helmhome/helm/plugins"
+
+	tests := []cmdTestCase{{
+		name:   "list plugins as JSON",
+		cmd:    "plugin list --type cli/v1 -o json",
+		golden: "output/plugin-list.json",
+	}}
+	runTestCmd(t, tests)
+}
@@ -88,6 +88,9 @@ func uninstallPlugin(p plugin.Plugin) error {
 	if err := os.RemoveAll(p.Dir()); err != nil {
 		return err
 	}
+	if err := pluginConsentStore().Revoke(p.Metadata().Name); err != nil {
+		slog.Debug("failed to revoke plugin permissions", "plugin", p.Metadata().Name, "error", err)
+	}
 
 	// Clean up versioned tarball and provenance files from HELM_PLUGINS directory
 	// These files are saved with pattern: PLUGIN_NAME-VERSION.tgz and PLUGIN_NAME-VERSION.tgz.prov
@@ -0,0 +1,128 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const profileHelp = `
+This command runs 'helm template' or 'helm install' while profiling its CPU
+and memory usage, to investigate slow or memory hungry charts and releases.
+
+The profiles are written in the pprof format and can be inspected with
+'go tool pprof'. The memory profile holds all the allocations of the command,
+so use '-sample_index=alloc_space' to see where memory is allocated. A summary
+of the time and allocations is printed to stderr.
+
+    $ helm profile template myrelease ./mychart --cpuprofile cpu.out --memprofile mem.out
+    $ go tool pprof -top cpu.out
+
+Note that 'helm profile install' installs the release as 'helm install' does.
+Use '--dry-run' to profile it without changing the cluster.
+`
+
+func newProfileCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "profile the CPU and memory usage of a command",
+		Long:  profileHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(profiled(newTemplateCmd(cfg, out)))
+	cmd.AddCommand(profiled(newInstallCmd(cfg, out)))
+
+	return cmd
+}
+
+type profileOptions struct {
+	cpuProfile string // --cpuprofile
+	memProfile string // --memprofile
+}
+
+// profiled adds the profiling flags to cmd and profiles its runs.
+func profiled(cmd *cobra.Command) *cobra.Command {
+	o := &profileOptions{}
+	f := cmd.Flags()
+	f.StringVar(&o.cpuProfile, "cpuprofile", "", "write a CPU profile to this file")
+	f.StringVar(&o.memProfile, "memprofile", "", "write a memory profile of all allocations to this file")
+
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return o.run(cmd.ErrOrStderr(), func() error { return run(cmd, args) })
+	}
+	return cmd
+}
+
+// run runs fn while profiling it and prints a summary to w.
+func (o *profileOptions) run(w io.Writer, fn func() error) error {
+	var cpuFile *os.File
+	if o.cpuProfile != "" {
+		f, err := startCPUProfile(o.cpuProfile)
+		if err != nil {
+			return err
+		}
+		cpuFile = f
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	errs := []error{fn()}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	if cpuFile != nil {
+		if err := stopCPUProfile(cpuFile); err != nil {
+			errs = append(errs, fmt.Errorf("could not write CPU profile: %w", err))
+		}
+	}
+	if o.memProfile != "" {
+		if err := writeMemProfile(o.memProfile, "allocs"); err != nil {
+			errs = append(errs, fmt.Errorf("could not write memory profile: %w", err))
+		}
+	}
+
+	fmt.Fprintf(w, "Completed in %s with %d allocations (%s)\n",
+		elapsed.Round(time.Millisecond), after.Mallocs-before.Mallocs, formatBytes(after.TotalAlloc-before.TotalAlloc))
+	return errors.Join(errs...)
+}
+
+// formatBytes formats a number of bytes with a binary unit.
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,66 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestProfileCmd(t *testing.T) {
+	dir := t.TempDir()
+	cpu := filepath.Join(dir, "cpu.out")
+	mem := filepath.Join(dir, "mem.out")
+
+	for _, sub := range []string{"template", "install --dry-run"} {
+		_, out, err := executeActionCommand("profile " + sub + " myrelease testdata/testcharts/empty --cpuprofile " + cpu + " --memprofile " + mem)
+		if err != nil {
+			t.Fatalf("%s: %s", sub, err)
+		}
+		if !regexp.MustCompile(`Completed in [0-9.]+m?s with [0-9]+ allocations \([0-9.]+ [KMG]?i?B\)`).MatchString(out) {
+			t.Errorf("%s: expected a summary, got %q", sub, out)
+		}
+		for _, path := range []string{cpu, mem} {
+			if fi, err := os.Stat(path); err != nil || fi.Size() == 0 {
+				t.Errorf("%s: expected a profile in %s: %v", sub, path, err)
+			}
+			os.Remove(path)
+		}
+	}
+
+	_, _, err := executeActionCommand("profile template myrelease testdata/testcharts/empty --cpuprofile " + filepath.Join(dir, "missing", "cpu.out"))
+	if err == nil {
+		t.Error("Expected an error for a CPU profile that cannot be created")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for n, expect := range map[uint64]string{
+		0:           "0 B",
+		1023:        "1023 B",
+		1024:        "1.0 KiB",
+		1536:        "1.5 KiB",
+		5 << 20:     "5.0 MiB",
+		3 << 30 / 2: "1.5 GiB",
+	} {
+		if got := formatBytes(n); got != expect {
+			t.Errorf("formatBytes(%d) = %q, expected %q", n, got, expect)
+		}
+	}
+}
@@ -41,14 +41,9 @@ func init() {
 func startProfiling() error {
 	if cpuProfilePath != "" {
 		var err error
-		cpuProfileFile, err = os.Create(cpuProfilePath)
+		cpuProfileFile, err = startCPUProfile(cpuProfilePath)
 		if err != nil {
-			return fmt.Errorf("could not create CPU profile: %w", err)
-		}
-		if err := pprof.StartCPUProfile(cpuProfileFile); err != nil {
-			cpuProfileFile.Close()
-			cpuProfileFile = nil
-			return fmt.Errorf("could not start CPU profile: %w", err)
+			return err
 		}
 	}
 	return nil
@@ -62,25 +57,12 @@ func stopProfiling() error {
 
 	// Stop CPU profiling if it was started
 	if cpuProfileFile != nil {
-		pprof.StopCPUProfile()
-		err := cpuProfileFile.Close()
-		if err != nil {
-			errs = append(errs, err)
-		}
+		errs = append(errs, stopCPUProfile(cpuProfileFile))
 		cpuProfileFile = nil
 	}
 
 	if memProfilePath != "" {
-		f, err := os.Create(memProfilePath)
-		if err != nil {
-			errs = append(errs, err)
-		}
-		defer f.Close()
-
-		runtime.GC() // get up-to-date statistics
-		if err := pprof.WriteHeapProfile(f); err != nil {
-			errs = append(errs, err)
-		}
+		errs = append(errs, writeMemProfile(memProfilePath, "heap"))
 	}
 
 	if err := errors.Join(errs...); err != nil {
@@ -89,3 +71,35 @@ func stopProfiling() error {
 
 	return nil
 }
+
+// startCPUProfile starts profiling CPU usage to the file at path.
+func startCPUProfile(path string) (*os.File, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not start CPU profile: %w", err)
+	}
+	return f, nil
+}
+
+// stopCPUProfile stops profiling CPU usage and closes the profile.
+func stopCPUProfile(f *os.File) error {
+	pprof.StopCPUProfile()
+	return f.Close()
+}
+
+// writeMemProfile writes the named memory profile, "heap" or "allocs", to
+// the file at path.
+func writeMemProfile(path, name string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	runtime.GC() // get up-to-date statistics
+	return pprof.Lookup(name).WriteTo(f, 0)
+}
@@ -25,6 +25,7 @@ import (
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/pusher"
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 const pushDesc = `
@@ -84,7 +85,8 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				action.WithTLSClientConfig(o.certFile, o.keyFile, o.caFile),
 				action.WithInsecureSkipTLSVerify(o.insecureSkipTLSverify),
 				action.WithPlainHTTP(o.plainHTTP),
-				action.WithPushOptWriter(out))
+				action.WithPushOptWriter(out),
+				action.WithPushProgress(pushProgress(out)))
 			client.Settings = settings
 			output, err := client.Run(chartRef, remote)
 			if err != nil {
@@ -106,3 +108,17 @@ func newPushCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 	return cmd
 }
+
+// pushProgress returns a function reporting the progress of the upload of the
+// content of a chart. Layers uploaded in chunks are reported after each chunk.
+func pushProgress(out io.Writer) registry.PushProgressFunc {
+	// A layer uploaded in chunks is reported again when the copy skips it.
+	done := map[string]bool{}
+	return func(p registry.PushProgress) {
+		if done[p.Digest] {
+			return
+		}
+		done[p.Digest] = p.Uploaded == p.Total
+		fmt.Fprintf(out, "Uploaded %s: %d/%d bytes\n", p.Digest, p.Uploaded, p.Total)
+	}
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"testing"
+
+	"helm.sh/helm/v4/pkg/registry"
 )
 
 func TestPushFileCompletion(t *testing.T) {
@@ -25,3 +28,20 @@ func TestPushFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "push package.tgz", false)
 	checkFileCompletion(t, "push package.tgz oci://localhost:5000", false)
 }
+
+func TestPushProgress(t *testing.T) {
+	var out bytes.Buffer
+	progress := pushProgress(&out)
+	progress(registry.PushProgress{Digest: "sha256:layer", Uploaded: 16, Total: 32})
+	progress(registry.PushProgress{Digest: "sha256:layer", Uploaded: 32, Total: 32})
+	// The copy skips the layer uploaded in chunks and reports it again.
+	progress(registry.PushProgress{Digest: "sha256:layer", Uploaded: 32, Total: 32})
+	progress(registry.PushProgress{Digest: "sha256:config", Uploaded: 8, Total: 8})
+
+	expect := "Uploaded sha256:layer: 16/32 bytes\n" +
+		"Uploaded sha256:layer: 32/32 bytes\n" +
+		"Uploaded sha256:config: 8/8 bytes\n"
+	if out.String() != expect {
+		t.Errorf("expected %q, got %q", expect, out.String())
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+var releaseHelp = `
+This command consists of multiple subcommands to manage the state of a release.
+
+It can be used to pause a release, for example during an incident, so that
+upgrades and rollbacks are refused until the release is resumed.
+`
+
+func newReleaseCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release pause|resume [ARGS]",
+		Short: "pause and resume releases",
+		Long:  releaseHelp,
+		Args:  require.NoArgs,
+	}
+
+	cmd.AddCommand(newReleasePauseCmd(cfg, out))
+	cmd.AddCommand(newReleaseResumeCmd(cfg, out))
+
+	return cmd
+}
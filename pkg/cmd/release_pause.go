@@ -0,0 +1,67 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const releasePauseDesc = `
+This command pauses a release.
+
+While a release is paused, 'helm upgrade' and 'helm rollback' fail without
+changing the release. Pausing only records the paused state in the release
+storage; the resources of the release are not modified.
+
+Pausing is advisory, not a lock. Upgrades and rollbacks check the paused state
+when they start, so an operation already in progress, or one that starts at
+the same moment as the pause, still completes.
+
+Use 'helm release resume' to allow changes again.
+`
+
+func newReleasePauseCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewPause(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "pause RELEASE_NAME",
+		Short: "refuse upgrades and rollbacks of a release until it is resumed",
+		Long:  releasePauseDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if _, err := client.Run(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Release %q has been paused. Upgrades and rollbacks will be refused until it is resumed.\n", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,73 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"helm.sh/helm/v4/pkg/action"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestReleasePauseCmd(t *testing.T) {
+	rels := func(custom release.Status) []*release.Release {
+		return []*release.Release{
+			{
+				Name:    "funny-honey",
+				Info:    &release.Info{Status: release.StatusSuperseded},
+				Chart:   &chart.Chart{},
+				Version: 1,
+			},
+			{
+				Name:    "funny-honey",
+				Info:    &release.Info{Status: release.StatusDeployed, CustomStatus: custom},
+				Chart:   &chart.Chart{},
+				Version: 2,
+			},
+		}
+	}
+
+	tests := []cmdTestCase{{
+		name:   "pause a release",
+		cmd:    "release pause funny-honey",
+		golden: "output/release-pause.txt",
+		rels:   rels(""),
+	}, {
+		name:   "resume a paused release",
+		cmd:    "release resume funny-honey",
+		golden: "output/release-resume.txt",
+		rels:   rels(action.StatusPaused),
+	}, {
+		name:      "rollback a paused release",
+		cmd:       "rollback funny-honey 1",
+		golden:    "output/release-paused-rollback.txt",
+		rels:      rels(action.StatusPaused),
+		wantError: true,
+	}, {
+		name:      "pause a release that does not exist",
+		cmd:       "release pause no-such-release",
+		golden:    "output/release-pause-missing.txt",
+		wantError: true,
+	}, {
+		name:      "pause without a release name",
+		cmd:       "release pause",
+		golden:    "output/release-pause-no-args.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const releaseResumeDesc = `
+This command resumes a release paused with 'helm release pause', so that it
+can be upgraded and rolled back again.
+`
+
+func newReleaseResumeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewResume(cfg)
+
+	cmd := &cobra.Command{
+		Use:   "resume RELEASE_NAME",
+		Short: "allow upgrades and rollbacks of a paused release",
+		Long:  releaseResumeDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if _, err := client.Run(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Release %q has been resumed.\n", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -29,13 +29,13 @@ import (
 var repoHelm = `
 This command consists of multiple subcommands to interact with chart repositories.
 
-It can be used to add, remove, list, and index chart repositories.
+It can be used to add, remove, list, index, and verify chart repositories.
 `
 
 func newRepoCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "repo add|remove|list|index|update [ARGS]",
-		Short: "add, list, remove, update, and index chart repositories",
+		Use:   "repo add|remove|list|index|update|verify [ARGS]",
+		Short: "add, list, remove, update, index, and verify chart repositories",
 		Long:  repoHelm,
 		Args:  require.NoArgs,
 	}
@@ -45,6 +45,7 @@ func newRepoCmd(out io.Writer) *cobra.Command {
 	cmd.AddCommand(newRepoRemoveCmd(out))
 	cmd.AddCommand(newRepoIndexCmd(out))
 	cmd.AddCommand(newRepoUpdateCmd(out))
+	cmd.AddCommand(newRepoVerifyCmd(out))
 
 	return cmd
 }
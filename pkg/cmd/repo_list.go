@@ -41,7 +41,7 @@ func newRepoListCmd(out io.Writer) *cobra.Command {
 			// or the file isn't the right format to be parsed the error is ignored. The
 			// repositories will be 0.
 			f, _ := repo.LoadFile(settings.RepositoryConfig)
-			if len(f.Repositories) == 0 && outfmt != output.JSON && outfmt != output.YAML {
+			if len(f.Repositories) == 0 && !outfmt.Structured() {
 				fmt.Fprintln(cmd.ErrOrStderr(), "no repositories to show")
 				return nil
 			}
@@ -73,6 +73,10 @@ func (r *repoListWriter) WriteTable(out io.Writer) error {
 	return output.EncodeTable(out, table)
 }
 
+func (r *repoListWriter) Kind() string {
+	return "RepositoryList"
+}
+
 func (r *repoListWriter) WriteJSON(out io.Writer) error {
 	return r.encodeByFormat(out, output.JSON)
 }
@@ -21,11 +21,11 @@ import (
 	"fmt"
 	"io"
 	"slices"
-	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/require"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/repo/v1"
@@ -38,20 +38,46 @@ Information is cached locally, where it is used by commands like 'helm search'.
 You can optionally specify a list of repositories you want to update.
 	$ helm repo update <repo_name> ...
 To update all the repositories, use 'helm repo update'.
+
+Repositories are updated concurrently, at most '--parallelism' at a time. By
+default every repository is updated, and the command fails if any of them
+failed to update. Use '--fail-fast' to stop updating repositories after the
+first failure, or '--ignore-errors' to succeed even if some repositories failed
+to update. Use '--output json' to get the status, duration and error of the
+update of every repository.
 `
 
 var errNoRepositories = errors.New("no repositories found. You must add one before updating")
 
 type repoUpdateOptions struct {
-	update    func([]*repo.ChartRepository, io.Writer) error
-	repoFile  string
-	repoCache string
-	names     []string
-	timeout   time.Duration
+	update       func([]*repo.ChartRepository, io.Writer) error
+	repoFile     string
+	repoCache    string
+	names        []string
+	timeout      time.Duration
+	parallelism  int
+	failFast     bool
+	ignoreErrors bool
+	outfmt       output.Format
+}
+
+// repoUpdateElement is the status of the update of a repository in the
+// structured output of 'helm repo update'.
+type repoUpdateElement struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+type repoUpdateWriter struct {
+	results []repoUpdateElement
 }
 
 func newRepoUpdateCmd(out io.Writer) *cobra.Command {
-	o := &repoUpdateOptions{update: updateCharts}
+	o := &repoUpdateOptions{}
+	o.update = o.updateCharts
 
 	cmd := &cobra.Command{
 		Use:     "update [REPO1 [REPO2 ...]]",
@@ -63,6 +89,9 @@ func newRepoUpdateCmd(out io.Writer) *cobra.Command {
 			return compListRepos(toComplete, args), cobra.ShellCompDirectiveNoFileComp
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if o.failFast && o.ignoreErrors {
+				return errors.New("--fail-fast and --ignore-errors cannot be used together")
+			}
 			o.repoFile = settings.RepositoryConfig
 			o.repoCache = settings.RepositoryCache
 			o.names = args
@@ -72,6 +101,10 @@ func newRepoUpdateCmd(out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.DurationVar(&o.timeout, "timeout", getter.DefaultHTTPTimeout*time.Second, "time to wait for the index file download to complete")
+	f.IntVar(&o.parallelism, "parallelism", 0, "maximum number of repositories to update at the same time. Use 0 for no limit")
+	f.BoolVar(&o.failFast, "fail-fast", false, "stop updating repositories after the first failure")
+	f.BoolVar(&o.ignoreErrors, "ignore-errors", false, "succeed even if some repositories failed to update")
+	bindOutputFlag(cmd, &o.outfmt)
 
 	return cmd
 }
@@ -113,48 +146,100 @@ func (o *repoUpdateOptions) run(out io.Writer) error {
 	return o.update(repos, out)
 }
 
+// updateCharts updates the repositories with the default options.
 func updateCharts(repos []*repo.ChartRepository, out io.Writer) error {
-	fmt.Fprintln(out, "Hang tight while we grab the latest from your chart repositories...")
-	var wg sync.WaitGroup
-	failRepoURLChan := make(chan string, len(repos))
-
-	writeMutex := sync.Mutex{}
-	for _, re := range repos {
-		wg.Add(1)
-		go func(re *repo.ChartRepository) {
-			defer wg.Done()
-			if _, err := re.DownloadIndexFile(); err != nil {
-				writeMutex.Lock()
-				defer writeMutex.Unlock()
-				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", re.Config.Name, re.Config.URL, err)
-				failRepoURLChan <- re.Config.URL
-			} else {
-				writeMutex.Lock()
-				defer writeMutex.Unlock()
-				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", re.Config.Name)
-			}
-		}(re)
+	o := &repoUpdateOptions{outfmt: output.Table}
+	return o.updateCharts(repos, out)
+}
+
+func (o *repoUpdateOptions) updateCharts(repos []*repo.ChartRepository, out io.Writer) error {
+	table := !o.outfmt.Structured()
+	if table {
+		fmt.Fprintln(out, "Hang tight while we grab the latest from your chart repositories...")
 	}
 
-	go func() {
-		wg.Wait()
-		close(failRepoURLChan)
-	}()
+	results := repo.UpdateIndexes(repos, repo.UpdateOptions{
+		Parallelism: o.parallelism,
+		FailFast:    o.failFast,
+		Progress: func(res repo.UpdateResult) {
+			if !table {
+				return
+			}
+			re := res.Repository
+			switch {
+			case errors.Is(res.Err, repo.ErrUpdateSkipped):
+				fmt.Fprintf(out, "...Skipped the %q chart repository\n", re.Config.Name)
+			case res.Err != nil:
+				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", re.Config.Name, re.Config.URL, res.Err)
+			default:
+				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", re.Config.Name)
+			}
+		},
+	})
 
 	var repoFailList []string
-	for url := range failRepoURLChan {
-		repoFailList = append(repoFailList, url)
+	elements := make([]repoUpdateElement, 0, len(results))
+	for _, res := range results {
+		e := repoUpdateElement{
+			Name:     res.Repository.Config.Name,
+			URL:      res.Repository.Config.URL,
+			Status:   "updated",
+			Duration: res.Duration.String(),
+		}
+		switch {
+		case errors.Is(res.Err, repo.ErrUpdateSkipped):
+			e.Status, e.Error = "skipped", res.Err.Error()
+		case res.Err != nil:
+			e.Status, e.Error = "failed", res.Err.Error()
+			repoFailList = append(repoFailList, res.Repository.Config.URL)
+		}
+		elements = append(elements, e)
+	}
+
+	if !table {
+		if err := o.outfmt.Write(out, &repoUpdateWriter{elements}); err != nil {
+			return err
+		}
 	}
 
 	if len(repoFailList) > 0 {
-		return fmt.Errorf("failed to update the following repositories: %s",
-			repoFailList)
+		if !o.ignoreErrors {
+			return fmt.Errorf("failed to update the following repositories: %s",
+				repoFailList)
+		}
+		if table {
+			fmt.Fprintf(out, "Update Complete, but %d of %d repositories failed to update.\n", len(repoFailList), len(results))
+		}
+		return nil
 	}
 
-	fmt.Fprintln(out, "Update Complete. ⎈Happy Helming!⎈")
+	if table {
+		fmt.Fprintln(out, "Update Complete. ⎈Happy Helming!⎈")
+	}
 	return nil
 }
 
+func (w *repoUpdateWriter) WriteTable(out io.Writer) error {
+	for _, e := range w.results {
+		if _, err := fmt.Fprintf(out, "%s\t%s\n", e.Name, e.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *repoUpdateWriter) Kind() string {
+	return "RepositoryUpdateList"
+}
+
+func (w *repoUpdateWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.results)
+}
+
+func (w *repoUpdateWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.results)
+}
+
 func checkRequestedRepos(requestedRepos []string, validRepos []*repo.Entry) error {
 	for _, requestedRepo := range requestedRepos {
 		found := false
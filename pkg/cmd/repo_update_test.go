@@ -17,6 +17,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -25,6 +26,7 @@ import (
 	"testing"
 
 	"helm.sh/helm/v4/internal/test/ensure"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/repo/v1"
 	"helm.sh/helm/v4/pkg/repo/v1/repotest"
@@ -210,3 +212,49 @@ func TestUpdateChartsFailWithError(t *testing.T) {
 		t.Error("Update was not successful and should return error message because 'fail-on-repo-update-fail' flag set")
 	}
 }
+
+func TestUpdateChartsIgnoreErrors(t *testing.T) {
+	defer resetEnv()()
+	ensure.HelmHome(t)
+
+	ts := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/testserver/*.*"),
+	)
+	defer ts.Stop()
+
+	var repos []*repo.ChartRepository
+	for name, url := range map[string]string{"good": ts.URL(), "bad": ts.URL() + "55"} {
+		r, err := repo.NewChartRepository(&repo.Entry{Name: name, URL: url}, getter.All(settings))
+		if err != nil {
+			t.Fatal(err)
+		}
+		repos = append(repos, r)
+	}
+
+	b := bytes.NewBuffer(nil)
+	o := &repoUpdateOptions{outfmt: output.JSON, ignoreErrors: true}
+	if err := o.updateCharts(repos, b); err != nil {
+		t.Fatalf("Expected errors to be ignored, got %v", err)
+	}
+
+	var results []repoUpdateElement
+	if err := json.Unmarshal(b.Bytes(), &results); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", b.String(), err)
+	}
+	statuses := map[string]string{}
+	for _, r := range results {
+		statuses[r.Name] = r.Status
+		if r.Duration == "" {
+			t.Errorf("Expected a duration for repository %s", r.Name)
+		}
+	}
+	if statuses["good"] != "updated" || statuses["bad"] != "failed" {
+		t.Errorf("Unexpected statuses %v", statuses)
+	}
+
+	o = &repoUpdateOptions{outfmt: output.Table}
+	if err := o.updateCharts(repos, io.Discard); err == nil {
+		t.Error("Expected an error without --ignore-errors")
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+const verifyRepoDesc = `
+Verify checks that a chart repository is consistent end-to-end before it is
+published or used.
+
+The index file of the repository is downloaded and checked for schema errors,
+invalid chart versions and versions listed more than once. Every chart archive
+referenced by the index is then downloaded, and its digest is compared with
+the one recorded in the index. The presence of a provenance file is checked for
+every chart version.
+
+Missing digests and provenance files are reported as warnings. Use
+'--require-provenance' to report missing provenance files as errors, or
+'--skip-downloads' to only check the index file. The command fails if any
+error is found.
+`
+
+type repoVerifyOptions struct {
+	name              string
+	repoFile          string
+	timeout           time.Duration
+	parallelism       int
+	skipDownloads     bool
+	requireProvenance bool
+	outfmt            output.Format
+}
+
+func newRepoVerifyCmd(out io.Writer) *cobra.Command {
+	o := &repoVerifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify [NAME]",
+		Short: "verify the index and charts of a chart repository",
+		Long:  verifyRepoDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListRepos(toComplete, args), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			o.name = args[0]
+			o.repoFile = settings.RepositoryConfig
+			return o.run(out)
+		},
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&o.timeout, "timeout", getter.DefaultHTTPTimeout*time.Second, "time to wait for each download to complete")
+	f.IntVar(&o.parallelism, "parallelism", repo.DefaultVerifyParallelism, "maximum number of charts to download at the same time")
+	f.BoolVar(&o.skipDownloads, "skip-downloads", false, "only verify the index file, without downloading the charts")
+	f.BoolVar(&o.requireProvenance, "require-provenance", false, "report chart versions without a provenance file as errors")
+	bindOutputFlag(cmd, &o.outfmt)
+
+	return cmd
+}
+
+func (o *repoVerifyOptions) run(out io.Writer) error {
+	f, err := repo.LoadFile(o.repoFile)
+	if isNotExist(err) || (err == nil && len(f.Repositories) == 0) {
+		return errors.New("no repositories configured")
+	}
+	if err != nil {
+		return fmt.Errorf("failed loading file: %s: %w", o.repoFile, err)
+	}
+	cfg := f.Get(o.name)
+	if cfg == nil {
+		return fmt.Errorf("no repo named %q found", o.name)
+	}
+
+	r, err := repo.NewChartRepository(cfg, getter.All(settings, getter.WithTimeout(o.timeout)))
+	if err != nil {
+		return err
+	}
+	report, err := r.Verify(repo.VerifyOptions{
+		Parallelism:       o.parallelism,
+		SkipDownloads:     o.skipDownloads,
+		RequireProvenance: o.requireProvenance,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := o.outfmt.Write(out, &repoVerifyWriter{report}); err != nil {
+		return err
+	}
+	if n := report.Count(repo.SeverityError); n > 0 {
+		return fmt.Errorf("repository %q failed verification with %d error(s)", o.name, n)
+	}
+	return nil
+}
+
+type repoVerifyWriter struct {
+	report *repo.VerifyReport
+}
+
+func (w *repoVerifyWriter) WriteTable(out io.Writer) error {
+	r := w.report
+	if len(r.Issues) > 0 {
+		table := uitable.New()
+		table.MaxColWidth = 80
+		table.Wrap = true
+		table.AddRow("SEVERITY", "CHART", "VERSION", "CHECK", "MESSAGE")
+		for _, i := range r.Issues {
+			table.AddRow(i.Severity, i.Chart, i.Version, i.Check, i.Message)
+		}
+		if err := output.EncodeTable(out, table); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(out, "Verified %d versions of %d charts in %q: %d error(s), %d warning(s)\n",
+		r.Versions, r.Charts, r.Repository, r.Count(repo.SeverityError), r.Count(repo.SeverityWarning))
+	return err
+}
+
+func (w *repoVerifyWriter) Kind() string {
+	return "RepositoryVerification"
+}
+
+func (w *repoVerifyWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.encodable())
+}
+
+func (w *repoVerifyWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.encodable())
+}
+
+// encodable returns the report with an empty list of issues instead of null.
+func (w *repoVerifyWriter) encodable() repo.VerifyReport {
+	r := *w.report
+	if r.Issues == nil {
+		r.Issues = []repo.VerifyIssue{}
+	}
+	return r
+}
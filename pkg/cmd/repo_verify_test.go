@@ -0,0 +1,95 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/internal/test/ensure"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/repo/v1"
+	"helm.sh/helm/v4/pkg/repo/v1/repotest"
+)
+
+func TestRepoVerifyCmd(t *testing.T) {
+	defer resetEnv()()
+	ensure.HelmHome(t)
+
+	ts := repotest.NewTempServer(t,
+		repotest.WithChartSourceGlob("testdata/testcharts/compressedchart-0.*.tgz"),
+	)
+	defer ts.Stop()
+
+	o := &repoVerifyOptions{
+		name:        "test",
+		repoFile:    filepath.Join(ts.Root(), "repositories.yaml"),
+		parallelism: repo.DefaultVerifyParallelism,
+		outfmt:      output.Table,
+	}
+	var out bytes.Buffer
+	if err := o.run(&out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if !strings.Contains(got, `Verified 3 versions of 1 charts in "test": 0 error(s), 3 warning(s)`) {
+		t.Errorf("unexpected summary: %q", got)
+	}
+	if !strings.Contains(got, "no provenance file") {
+		t.Errorf("expected missing provenance warnings, got %q", got)
+	}
+
+	// A chart that is not in the repository anymore fails the verification
+	if err := os.Remove(filepath.Join(ts.Root(), "compressedchart-0.2.0.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	o.requireProvenance = true
+	o.outfmt = output.JSON
+	out.Reset()
+	err := o.run(&out)
+	if err == nil || !strings.Contains(err.Error(), `repository "test" failed verification with 3 error(s)`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report repo.VerifyReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Count(repo.SeverityError) != 3 {
+		t.Errorf("expected 3 errors, got %+v", report.Issues)
+	}
+}
+
+func TestRepoVerifyUnknownRepo(t *testing.T) {
+	o := &repoVerifyOptions{name: "nope", repoFile: "testdata/repositories.yaml"}
+	err := o.run(&bytes.Buffer{})
+	if err == nil || err.Error() != `no repo named "nope" found` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRepoVerifyOutputCompletion(t *testing.T) {
+	outputFlagCompletionTest(t, "repo verify")
+}
+
+func TestRepoVerifyFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "repo verify", false)
+	checkFileCompletion(t, "repo verify repo1", false)
+}
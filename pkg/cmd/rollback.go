@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strconv"
@@ -36,10 +37,20 @@ second is a revision (version) number. If this argument is omitted or set to
 0, it will roll back to the previous release.
 
 To see revision numbers, run 'helm history RELEASE'.
+
+Releases can also be rolled back from a rollback bundle, even if their release
+history in the cluster was lost. A rollback bundle holds the rendered manifests,
+the values and the chart of a revision. Bundles are exported with the
+'--export-bundle' flag of 'helm install', 'helm upgrade' and 'helm rollback',
+to a directory or to an OCI registry:
+
+    $ helm upgrade --export-bundle oci://registry.example.com/bundles myrelease ./mychart
+    $ helm rollback myrelease --from-bundle oci://registry.example.com/bundles/myrelease:v3
 `
 
 func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewRollback(cfg)
+	var fromBundle string
 
 	cmd := &cobra.Command{
 		Use:   "rollback <RELEASE> [REVISION]",
@@ -58,6 +69,17 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			return noMoreArgsComp()
 		},
 		RunE: func(_ *cobra.Command, args []string) error {
+			if fromBundle != "" {
+				if len(args) > 1 {
+					return errors.New("a revision cannot be used with --from-bundle")
+				}
+				bundle, err := action.LoadBundle(cfg, fromBundle)
+				if err != nil {
+					return err
+				}
+				client.Bundle = bundle
+			}
+
 			if len(args) > 1 {
 				ver, err := strconv.Atoi(args[1])
 				if err != nil {
@@ -80,14 +102,20 @@ func newRollbackCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.ForceReplace, "force-replace", false, "force resource updates by replacement")
 	f.BoolVar(&client.ForceReplace, "force", false, "deprecated")
 	f.MarkDeprecated("force", "use --force-replace instead")
+	f.StringSliceVar(&client.ForceRecreate, "force-recreate", []string{}, "delete and recreate the given resources (as kind/name) when their update is rejected because of immutable fields")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.StringVar(&client.ServerSideApply, "server-side", "auto", "must be \"true\", \"false\" or \"auto\". Object updates run in the server instead of the client (\"auto\" defaults the value from the previous chart release's method)")
+	addFieldManagerFlag(f, cfg)
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "prevent hooks from running during rollback")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&client.WaitForJobs, "wait-for-jobs", false, "if set and --wait enabled, will wait until all Jobs have been completed before marking the release as successful. It will wait for as long as --timeout")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this rollback when rollback fails")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	f.IntVar(&client.MaxFailedHistory, "history-max-failed", settings.MaxFailedHistory, "limit the number of failed and pending-rollback revisions saved per release, pruned before the other revisions. Use 0 for no separate limit")
+	f.StringVar(&fromBundle, "from-bundle", "", "roll back to the revision in this rollback bundle, a file or an OCI reference (oci://), instead of a revision from the release history")
+	f.StringVar(&client.ExportBundle, "export-bundle", "", "after the release is rolled back, export its rollback bundle to this directory or OCI repository (oci://)")
 	AddWaitFlag(cmd, &client.WaitStrategy)
+	addWaitProgressFlag(cmd, &client.WaitProgress)
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
 	cmd.MarkFlagsMutuallyExclusive("force", "force-conflicts")
 
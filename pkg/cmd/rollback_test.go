@@ -18,9 +18,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"helm.sh/helm/v4/pkg/action"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	release "helm.sh/helm/v4/pkg/release/v1"
 )
@@ -82,6 +85,35 @@ func TestRollbackCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestRollbackCmdFromBundle(t *testing.T) {
+	rel := release.Mock(&release.MockReleaseOptions{Name: "funny-honey", Version: 4})
+	b, err := action.NewBundle(rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "funny-honey.v4.bundle")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tests := []cmdTestCase{{
+		name:   "rollback a release without history from a bundle",
+		cmd:    fmt.Sprintf("rollback funny-honey --from-bundle %s", path),
+		golden: "output/rollback.txt",
+	}, {
+		name:      "rollback a release from a bundle with a revision",
+		cmd:       fmt.Sprintf("rollback funny-honey 1 --from-bundle %s", path),
+		golden:    "output/rollback-from-bundle-revision.txt",
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
+
 func TestRollbackRevisionCompletion(t *testing.T) {
 	mk := func(name string, vers int, status release.Status) *release.Release {
 		return release.Mock(&release.MockReleaseOptions{
@@ -57,6 +57,7 @@ Environment variables:
 
 | Name                               | Description                                                                                                |
 |------------------------------------|------------------------------------------------------------------------------------------------------------|
+| $HELM_ACCEPT_PLUGIN_PERMISSIONS    | grant plugins the permissions they declare without asking. Set HELM_ACCEPT_PLUGIN_PERMISSIONS=1.           |
 | $HELM_CACHE_HOME                   | set an alternative location for storing cached files.                                                      |
 | $HELM_CONFIG_HOME                  | set an alternative location for storing Helm configuration.                                                |
 | $HELM_DATA_HOME                    | set an alternative location for storing Helm data.                                                         |
@@ -64,6 +65,7 @@ Environment variables:
 | $HELM_DRIVER                       | set the backend storage driver. Values are: configmap, secret, memory, sql.                                |
 | $HELM_DRIVER_SQL_CONNECTION_STRING | set the connection string the SQL storage driver should use.                                               |
 | $HELM_MAX_HISTORY                  | set the maximum number of helm release history.                                                            |
+| $HELM_MAX_FAILED_HISTORY           | set the maximum number of failed revisions kept in helm release history.                                   |
 | $HELM_NAMESPACE                    | set the namespace used for the helm operations.                                                            |
 | $HELM_NO_PLUGINS                   | disable plugins. Set HELM_NO_PLUGINS=1 to disable plugins.                                                 |
 | $HELM_PLUGINS                      | set the path to the plugins directory                                                                      |
@@ -255,30 +257,39 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 	// Add subcommands
 	cmd.AddCommand(
 		// chart commands
+		newChartCmd(out),
 		newCreateCmd(out),
 		newDependencyCmd(actionConfig, out),
 		newPullCmd(actionConfig, out),
 		newShowCmd(actionConfig, out),
 		newLintCmd(out),
+		newLSPCmd(out),
 		newPackageCmd(out),
 		newRepoCmd(out),
+		newSchemaCmd(out),
+		newValuesCmd(out),
 		newSearchCmd(out),
 		newVerifyCmd(out),
 
 		// release commands
+		newApplyCmd(actionConfig, out),
+		newGCCmd(actionConfig, out),
 		newGetCmd(actionConfig, out),
 		newHistoryCmd(actionConfig, out),
 		newInstallCmd(actionConfig, out),
 		newListCmd(actionConfig, out),
+		newProfileCmd(actionConfig, out),
+		newReleaseCmd(actionConfig, out),
 		newReleaseTestCmd(actionConfig, out),
 		newRollbackCmd(actionConfig, out),
 		newStatusCmd(actionConfig, out),
 		newTemplateCmd(actionConfig, out),
 		newUninstallCmd(actionConfig, out),
 		newUpgradeCmd(actionConfig, out),
+		newVerifyReleaseCmd(actionConfig, out),
 
 		newCompletionCmd(out),
-		newEnvCmd(out),
+		newEnvCmd(actionConfig, out),
 		newPluginCmd(out),
 		newVersionCmd(out),
 
@@ -291,6 +302,9 @@ func newRootCmdWithConfig(actionConfig *action.Configuration, out io.Writer, arg
 		newPushCmd(actionConfig, out),
 	)
 
+	// Plugins of every type ask for consent to their permissions first
+	requirePluginConsent()
+
 	// Find and add CLI plugins
 	loadCLIPlugins(cmd, out)
 
@@ -0,0 +1,132 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const schemaExportDesc = `
+Export the values of a chart in a normalized form, flattening its
+values.schema.json and its values.yaml into the list of its values.
+
+Each value is listed with its path, such as 'image.tag' or 'ports[].name' for
+the fields of the items of a list, its types, allowed values, default and
+description. The schema takes precedence over the default values, which only
+complete it.
+
+Use '-o json' or '-o yaml' to feed the export to other tools, such as user
+interfaces configuring the chart.
+`
+
+func newSchemaExportCmd(out io.Writer) *cobra.Command {
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "export [CHART]",
+		Short: "export the values of a chart from its schema and defaults",
+		Long:  schemaExportDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			ch, err := loader.Load(chartpath)
+			if err != nil {
+				return err
+			}
+			fields, err := util.ValuesFields(ch.Schema, ch.Values)
+			if err != nil {
+				return err
+			}
+			return outfmt.Write(out, &schemaExportWriter{fields})
+		},
+	}
+
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+type schemaExportWriter struct {
+	fields []util.ValuesField
+}
+
+func (w *schemaExportWriter) WriteTable(out io.Writer) error {
+	table := uitable.New()
+	table.MaxColWidth = 60
+	table.Wrap = true
+	table.AddRow("PATH", "TYPE", "DEFAULT", "ALLOWED", "DESCRIPTION")
+	for _, f := range w.fields {
+		table.AddRow(f.Path, strings.Join(f.Type, " | "), formatFieldValue(f.Default), formatFieldValues(f.Enum), f.Description)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *schemaExportWriter) Kind() string {
+	return "ValuesFields"
+}
+
+func (w *schemaExportWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.encodable())
+}
+
+func (w *schemaExportWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.encodable())
+}
+
+// encodable returns the fields as an empty list instead of null.
+func (w *schemaExportWriter) encodable() []util.ValuesField {
+	if w.fields == nil {
+		return []util.ValuesField{}
+	}
+	return w.fields
+}
+
+// formatFieldValue formats a value of a field as JSON, so that strings are
+// distinguished from other types.
+func formatFieldValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func formatFieldValues(values []interface{}) string {
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		formatted = append(formatted, formatFieldValue(v))
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaExportCmd(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml":         "apiVersion: v2\nname: exported\nversion: 0.1.0\n",
+		"values.yaml":        "image:\n  tag: \"1.25\"\nreplicaCount: 1\n",
+		"values.schema.json": `{"properties": {"image": {"type": "object", "properties": {"pullPolicy": {"type": "string", "enum": ["Always", "IfNotPresent"], "description": "When to pull."}}}}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, out, err := executeActionCommand("schema export " + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "PATH            \tTYPE  \tDEFAULT\tALLOWED                 \tDESCRIPTION  \n" +
+		"image           \tobject\t       \t                        \t             \n" +
+		"image.pullPolicy\tstring\t       \t\"Always\", \"IfNotPresent\"\tWhen to pull.\n" +
+		"image.tag       \tstring\t\"1.25\" \t                        \t             \n" +
+		"replicaCount    \tnumber\t1      \t                        \t             \n"
+	if out != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+
+	_, out, err = executeActionCommand("schema export -o json " + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `{"path":"image.pullPolicy","type":["string"],"enum":["Always","IfNotPresent"],"description":"When to pull."}`) {
+		t.Errorf("unexpected JSON output %q", out)
+	}
+}
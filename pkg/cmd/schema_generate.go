@@ -0,0 +1,89 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const schemaGenerateDesc = `
+Generate a JSON Schema for the values of a chart directory from its values.yaml.
+
+Every value is typed after its default, which is also the default in the
+schema. A value is described by the comment starting with '# --' above it or
+on the same line, for example:
+
+    # -- The number of replicas to run.
+    replicaCount: 1
+
+The schema is printed, or written to values.schema.json in the chart directory
+with --write. It is a starting point: it neither requires values nor rejects
+unknown ones, so review and tighten it before publishing the chart.
+`
+
+func newSchemaGenerateCmd(out io.Writer) *cobra.Command {
+	var write, force bool
+
+	cmd := &cobra.Command{
+		Use:   "generate [CHART]",
+		Short: "generate values.schema.json from values.yaml",
+		Long:  schemaGenerateDesc,
+		Args:  require.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			chartpath := "."
+			if len(args) > 0 {
+				chartpath = filepath.Clean(args[0])
+			}
+			data, err := os.ReadFile(filepath.Join(chartpath, util.ValuesfileName))
+			if err != nil {
+				return err
+			}
+			schema, err := util.GenerateSchema(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", util.ValuesfileName, err)
+			}
+
+			if !write {
+				_, err := out.Write(schema)
+				return err
+			}
+			schemaPath := filepath.Join(chartpath, util.SchemafileName)
+			if _, err := os.Stat(schemaPath); err == nil && !force {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", schemaPath)
+			}
+			if err := os.WriteFile(schemaPath, schema, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Wrote %s\n", schemaPath)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&write, "write", false, "write the schema to values.schema.json in the chart directory")
+	f.BoolVar(&force, "force", false, "overwrite an existing values.schema.json when used with --write")
+
+	return cmd
+}
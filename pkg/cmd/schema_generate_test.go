@@ -0,0 +1,75 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaGenerateCmd(t *testing.T) {
+	dir := t.TempDir()
+	values := "# -- The number of replicas.\nreplicaCount: 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "values.yaml"), []byte(values), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, out, err := executeActionCommand("schema generate " + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "properties": {
+    "replicaCount": {
+      "default": 1,
+      "description": "The number of replicas.",
+      "type": "integer"
+    }
+  },
+  "type": "object"
+}
+`
+	if out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	schemaPath := filepath.Join(dir, "values.schema.json")
+	if _, out, err = executeActionCommand("schema generate --write " + dir); err != nil {
+		t.Fatal(err)
+	}
+	if out != "Wrote "+schemaPath+"\n" {
+		t.Errorf("Unexpected output %q", out)
+	}
+	data, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	_, _, err = executeActionCommand("schema generate --write " + dir)
+	if err == nil || !strings.Contains(err.Error(), "use --force to overwrite it") {
+		t.Errorf("Expected an error about the existing schema, got %v", err)
+	}
+	if _, _, err = executeActionCommand("schema generate --write --force " + dir); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -26,11 +26,13 @@ package search
 import (
 	"path"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
 
+	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/repo/v1"
 )
 
@@ -42,6 +44,9 @@ type Result struct {
 	Name  string
 	Score int
 	Chart *repo.ChartVersion
+	// Sources are the names of the results merged into this one by Merge,
+	// such as "stable/nginx" or "oci://example.com/charts/nginx".
+	Sources []string
 }
 
 // Index is a searchable index of chart information.
@@ -90,6 +95,31 @@ func (i *Index) AddRepo(rname string, ind *repo.IndexFile, all bool) {
 	}
 }
 
+// AddOCI adds the versions of a chart from an OCI repository, such as
+// oci://example.com/charts/nginx, to the search index.
+//
+// The versions are the tags of the repository, newest first, as listed by
+// the registry. Only the newest is added unless all is set.
+func (i *Index) AddOCI(ref string, versions []string, all bool) {
+	if len(versions) == 0 {
+		return
+	}
+	ref = strings.TrimSuffix(ref, "/")
+	name := path.Base(ref)
+	for _, v := range versions {
+		cv := &repo.ChartVersion{Metadata: &chart.Metadata{Name: name, Version: v}}
+		line := name + sep + ref + sep + sep
+		if !all {
+			i.lines[ref] = line
+			i.charts[ref] = cv
+			return
+		}
+		versionedName := ref + verSep + v
+		i.lines[versionedName] = line
+		i.charts[versionedName] = cv
+	}
+}
+
 // All returns all charts in the index as if they were search results.
 //
 // Each will be given a score of 0.
@@ -177,6 +207,57 @@ func (i *Index) SearchRegexp(re string, threshold int) ([]*Result, error) {
 	return buf, nil
 }
 
+// Merge merges the results for the same chart found in several sources,
+// such as a chart repository and an OCI registry, into one.
+//
+// Results are the same chart when their chart names match and, if versions is
+// set, their versions too. The merged result is the one with the newest
+// version, preferring one with a description, with the names of all the merged results as its Sources. The order
+// of the first result of each chart is kept.
+func Merge(res []*Result, versions bool) []*Result {
+	merged := make([]*Result, 0, len(res))
+	byChart := map[string]*Result{}
+	for _, r := range res {
+		key := r.Chart.Name
+		if versions {
+			key += verSep + r.Chart.Version
+		}
+		m, ok := byChart[key]
+		if !ok {
+			m = &Result{Name: r.Name, Score: r.Score, Chart: r.Chart}
+			byChart[key] = m
+			merged = append(merged, m)
+		} else {
+			switch {
+			case newer(r.Chart, m.Chart):
+				m.Name, m.Chart = r.Name, r.Chart
+			case !newer(m.Chart, r.Chart) && m.Chart.Description == "":
+				// Prefer the result carrying the metadata of the chart,
+				// which the tags of OCI repositories lack.
+				m.Name, m.Chart = r.Name, r.Chart
+			}
+			m.Score = min(m.Score, r.Score)
+		}
+		if !slices.Contains(m.Sources, r.Name) {
+			m.Sources = append(m.Sources, r.Name)
+		}
+	}
+	return merged
+}
+
+// newer reports whether the version of a is newer than the one of b.
+func newer(a, b *repo.ChartVersion) bool {
+	va, err := semver.NewVersion(a.Version)
+	if err != nil {
+		return false
+	}
+	vb, err := semver.NewVersion(b.Version)
+	if err != nil {
+		return true
+	}
+	return va.GreaterThan(vb)
+}
+
 // SortScore does an in-place sort of the results.
 //
 // Lowest scores are highest on the list. Matching scores are subsorted alphabetically.
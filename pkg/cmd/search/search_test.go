@@ -309,3 +309,55 @@ func TestCalcScore(t *testing.T) {
 		t.Errorf("Expected 3, got %d", r)
 	}
 }
+
+func TestAddOCI(t *testing.T) {
+	i := NewIndex()
+	i.AddOCI("oci://example.com/charts/alpine", []string{"0.3.0", "0.2.0"}, false)
+	i.AddOCI("oci://example.com/charts/empty", nil, false)
+
+	res := i.SearchLiteral("example.com", 100)
+	if len(res) != 1 {
+		t.Fatalf("Expected 1 result, got %d", len(res))
+	}
+	if res[0].Name != "oci://example.com/charts/alpine" || res[0].Chart.Name != "alpine" || res[0].Chart.Version != "0.3.0" {
+		t.Errorf("Unexpected result %s: %s-%s", res[0].Name, res[0].Chart.Name, res[0].Chart.Version)
+	}
+
+	i = NewIndex()
+	i.AddOCI("oci://example.com/charts/alpine/", []string{"0.3.0", "0.2.0"}, true)
+	if all := i.All(); len(all) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(all))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	version := func(name, v string) *repo.ChartVersion {
+		return &repo.ChartVersion{Metadata: &chart.Metadata{Name: name, Version: v}}
+	}
+	in := []*Result{
+		{Name: "testing/alpine", Score: 1, Chart: version("alpine", "0.2.0")},
+		{Name: "testing/nginx", Score: 1, Chart: version("nginx", "1.0.0")},
+		{Name: "oci://example.com/charts/alpine", Score: 0, Chart: version("alpine", "0.3.0")},
+		{Name: "mirror/alpine", Score: 2, Chart: version("alpine", "0.2.0")},
+	}
+
+	res := Merge(in, false)
+	if len(res) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(res))
+	}
+	alpine := res[0]
+	if alpine.Name != "oci://example.com/charts/alpine" || alpine.Chart.Version != "0.3.0" || alpine.Score != 0 {
+		t.Errorf("Unexpected merged result %s-%s with score %d", alpine.Name, alpine.Chart.Version, alpine.Score)
+	}
+	if got := strings.Join(alpine.Sources, ","); got != "testing/alpine,oci://example.com/charts/alpine,mirror/alpine" {
+		t.Errorf("Unexpected sources %s", got)
+	}
+
+	res = Merge(in, true)
+	if len(res) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(res))
+	}
+	if got := strings.Join(res[0].Sources, ","); res[0].Chart.Version != "0.2.0" || got != "testing/alpine,mirror/alpine" {
+		t.Errorf("Unexpected merged result %s with sources %s", res[0].Chart.Version, got)
+	}
+}
@@ -164,6 +164,10 @@ func (h *hubSearchWriter) WriteTable(out io.Writer) error {
 	return output.EncodeTable(out, table)
 }
 
+func (h *hubSearchWriter) Kind() string {
+	return "HubSearchResultList"
+}
+
 func (h *hubSearchWriter) WriteJSON(out io.Writer) error {
 	return h.encodeByFormat(out, output.JSON)
 }
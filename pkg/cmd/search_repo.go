@@ -34,6 +34,7 @@ import (
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cmd/search"
 	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/repo/v1"
 )
 
@@ -57,7 +58,16 @@ Examples:
     # Search for the latest stable release for nginx-ingress with a major version of 1
     $ helm search repo nginx-ingress --version ^1.0.0
 
+    # Search for nginx in the repositories and in an OCI registry together
+    $ helm search repo nginx --oci-repo oci://registry.example.com/charts/nginx
+
 Repositories are managed with 'helm repo' commands.
+
+The OCI repositories given with --oci-repo are searched along with the
+repositories, their versions being the tags listed by the registry. The results
+for the same chart, found in several of them, are then merged into one: the
+newest version is shown, and the places the chart was found in are listed as
+its sources.
 `
 
 // searchMaxScore suggests that any score higher than this is not considered a match.
@@ -73,6 +83,10 @@ type searchRepoOptions struct {
 	repoCacheDir   string
 	outputFormat   output.Format
 	failOnNoResult bool
+	ociRepos       []string
+	// listTags lists the versions of a chart in an OCI repository, newest
+	// first.
+	listTags func(ref string) ([]string, error)
 }
 
 func newSearchRepoCmd(out io.Writer) *cobra.Command {
@@ -85,6 +99,13 @@ func newSearchRepoCmd(out io.Writer) *cobra.Command {
 		RunE: func(_ *cobra.Command, args []string) error {
 			o.repoFile = settings.RepositoryConfig
 			o.repoCacheDir = settings.RepositoryCache
+			if len(o.ociRepos) > 0 {
+				registryClient, err := newDefaultRegistryClient(false, "", "")
+				if err != nil {
+					return fmt.Errorf("missing registry client: %w", err)
+				}
+				o.listTags = registryClient.Tags
+			}
 			return o.run(out, args)
 		},
 	}
@@ -96,6 +117,7 @@ func newSearchRepoCmd(out io.Writer) *cobra.Command {
 	f.StringVar(&o.version, "version", "", "search using semantic versioning constraints on repositories you have added")
 	f.UintVar(&o.maxColWidth, "max-col-width", 50, "maximum column width for output table")
 	f.BoolVar(&o.failOnNoResult, "fail-on-no-result", false, "search fails if no results are found")
+	f.StringArrayVar(&o.ociRepos, "oci-repo", []string{}, "search the versions of a chart in an OCI repository, such as oci://example.com/charts/nginx, along with the repositories you have added (can specify multiple)")
 
 	bindOutputFlag(cmd, &o.outputFormat)
 
@@ -126,8 +148,11 @@ func (o *searchRepoOptions) run(out io.Writer, args []string) error {
 	if err != nil {
 		return err
 	}
+	if len(o.ociRepos) > 0 {
+		data = search.Merge(data, o.versions)
+	}
 
-	return o.outputFormat.Write(out, &repoSearchWriter{data, o.maxColWidth, o.failOnNoResult})
+	return o.outputFormat.Write(out, &repoSearchWriter{data, o.maxColWidth, o.failOnNoResult, len(o.ociRepos) > 0})
 }
 
 func (o *searchRepoOptions) setupSearchedVersion() {
@@ -178,9 +203,15 @@ func (o *searchRepoOptions) applyConstraint(res []*search.Result) ([]*search.Res
 }
 
 func (o *searchRepoOptions) buildIndex() (*search.Index, error) {
+	for _, ref := range o.ociRepos {
+		if !registry.IsOCI(ref) {
+			return nil, fmt.Errorf("%q is not an OCI repository reference", ref)
+		}
+	}
+
 	// Load the repositories.yaml
 	rf, err := repo.LoadFile(o.repoFile)
-	if isNotExist(err) || len(rf.Repositories) == 0 {
+	if (isNotExist(err) || len(rf.Repositories) == 0) && len(o.ociRepos) == 0 {
 		return nil, errors.New("no repositories configured")
 	}
 
@@ -196,20 +227,30 @@ func (o *searchRepoOptions) buildIndex() (*search.Index, error) {
 
 		i.AddRepo(n, ind, o.versions || len(o.version) > 0)
 	}
+	for _, ref := range o.ociRepos {
+		tags, err := o.listTags(strings.TrimPrefix(ref, fmt.Sprintf("%s://", registry.OCIScheme)))
+		if err != nil {
+			slog.Warn("could not list the tags of the OCI repository", "repository", ref, slog.Any("error", err))
+			continue
+		}
+		i.AddOCI(ref, tags, o.versions || len(o.version) > 0)
+	}
 	return i, nil
 }
 
 type repoChartElement struct {
-	Name        string `json:"name"`
-	Version     string `json:"version"`
-	AppVersion  string `json:"app_version"`
-	Description string `json:"description"`
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	AppVersion  string   `json:"app_version"`
+	Description string   `json:"description"`
+	Sources     []string `json:"sources,omitempty"`
 }
 
 type repoSearchWriter struct {
 	results        []*search.Result
 	columnWidth    uint
 	failOnNoResult bool
+	sources        bool
 }
 
 func (r *repoSearchWriter) WriteTable(out io.Writer) error {
@@ -227,13 +268,25 @@ func (r *repoSearchWriter) WriteTable(out io.Writer) error {
 	}
 	table := uitable.New()
 	table.MaxColWidth = r.columnWidth
-	table.AddRow("NAME", "CHART VERSION", "APP VERSION", "DESCRIPTION")
-	for _, r := range r.results {
-		table.AddRow(r.Name, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description)
+	if r.sources {
+		table.AddRow("NAME", "CHART VERSION", "APP VERSION", "DESCRIPTION", "SOURCES")
+	} else {
+		table.AddRow("NAME", "CHART VERSION", "APP VERSION", "DESCRIPTION")
+	}
+	for _, res := range r.results {
+		if r.sources {
+			table.AddRow(res.Name, res.Chart.Version, res.Chart.AppVersion, res.Chart.Description, strings.Join(res.Sources, ", "))
+			continue
+		}
+		table.AddRow(res.Name, res.Chart.Version, res.Chart.AppVersion, res.Chart.Description)
 	}
 	return output.EncodeTable(out, table)
 }
 
+func (r *repoSearchWriter) Kind() string {
+	return "RepoSearchResultList"
+}
+
 func (r *repoSearchWriter) WriteJSON(out io.Writer) error {
 	return r.encodeByFormat(out, output.JSON)
 }
@@ -252,7 +305,7 @@ func (r *repoSearchWriter) encodeByFormat(out io.Writer, format output.Format) e
 	chartList := make([]repoChartElement, 0, len(r.results))
 
 	for _, r := range r.results {
-		chartList = append(chartList, repoChartElement{r.Name, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description})
+		chartList = append(chartList, repoChartElement{r.Name, r.Chart.Version, r.Chart.AppVersion, r.Chart.Description, r.Sources})
 	}
 
 	switch format {
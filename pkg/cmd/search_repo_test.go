@@ -17,7 +17,12 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"testing"
+
+	"helm.sh/helm/v4/internal/test"
+	"helm.sh/helm/v4/pkg/cli/output"
 )
 
 func TestSearchRepositoriesCmd(t *testing.T) {
@@ -98,6 +103,56 @@ func TestSearchRepositoriesCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestSearchRepoWithOCIRepos(t *testing.T) {
+	tags := map[string][]string{
+		"example.com/charts/alpine": {"0.4.0", "0.3.0-rc.1", "0.2.0"},
+		"example.com/charts/nginx":  {"1.0.0"},
+	}
+	tests := []struct {
+		name   string
+		args   []string
+		opts   searchRepoOptions
+		golden string
+	}{{
+		name:   "merge the results for the same chart",
+		args:   []string{"alpine"},
+		golden: "output/search-oci-repos.txt",
+	}, {
+		name:   "apply the version constraint to the OCI repositories",
+		args:   []string{"alpine"},
+		opts:   searchRepoOptions{versions: true, version: "< 0.4"},
+		golden: "output/search-oci-repos-versions.txt",
+	}, {
+		name:   "list the sources in json",
+		opts:   searchRepoOptions{outputFormat: output.JSON},
+		golden: "output/search-oci-repos-json.txt",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.opts
+			o.repoFile = "testdata/helmhome/helm/repositories.yaml"
+			o.repoCacheDir = "testdata/helmhome/helm/repository"
+			o.maxColWidth = 50
+			if o.outputFormat == "" {
+				o.outputFormat = output.Table
+			}
+			o.ociRepos = []string{"oci://example.com/charts/alpine", "oci://example.com/charts/nginx", "oci://example.com/charts/missing"}
+			o.listTags = func(ref string) ([]string, error) {
+				if v, ok := tags[ref]; ok {
+					return v, nil
+				}
+				return nil, errors.New("not found")
+			}
+			var out bytes.Buffer
+			if err := o.run(&out, tt.args); err != nil {
+				t.Fatal(err)
+			}
+			test.AssertGoldenString(t, out.String(), tt.golden)
+		})
+	}
+}
+
 func TestSearchRepoOutputCompletion(t *testing.T) {
 	outputFlagCompletionTest(t, "search repo")
 }
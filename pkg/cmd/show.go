@@ -57,6 +57,17 @@ This command inspects a chart (directory, file, or URL) and displays the content
 of the CustomResourceDefinition files
 `
 
+const showFilesDesc = `
+This command inspects a chart (directory, file, or URL) and lists its files
+and their sizes, including the files of its subcharts.
+
+When file names are given, the contents of those files are written instead.
+The files are streamed from the chart archive without extracting the rest of
+the chart, so a single template of a very large chart can be viewed quickly:
+
+    $ helm show files mychart-1.0.0.tgz templates/deployment.yaml
+`
+
 func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewShow(action.ShowAll, cfg)
 
@@ -181,7 +192,35 @@ func newShowCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		},
 	}
 
-	cmds := []*cobra.Command{all, readmeSubCmd, valuesSubCmd, chartSubCmd, crdsSubCmd}
+	filesSubCmd := &cobra.Command{
+		Use:               "files [CHART] [FILE...]",
+		Short:             "list the chart's files, or show the given files",
+		Long:              showFilesDesc,
+		Args:              require.MinimumNArgs(1),
+		ValidArgsFunction: validArgsFunc,
+		RunE: func(_ *cobra.Command, args []string) error {
+			client.OutputFormat = action.ShowFiles
+			err := addRegistryClient(client)
+			if err != nil {
+				return err
+			}
+			if len(args) == 1 {
+				output, err := runShow(args, client)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(out, output)
+				return nil
+			}
+			cp, err := locateShowChart(args[0], client)
+			if err != nil {
+				return err
+			}
+			return client.WriteFiles(cp, args[1:], out)
+		},
+	}
+
+	cmds := []*cobra.Command{all, readmeSubCmd, valuesSubCmd, chartSubCmd, crdsSubCmd, filesSubCmd}
 	for _, subCmd := range cmds {
 		addShowFlags(subCmd, client)
 		showCommand.AddCommand(subCmd)
@@ -212,17 +251,20 @@ func addShowFlags(subCmd *cobra.Command, client *action.Show) {
 }
 
 func runShow(args []string, client *action.Show) (string, error) {
+	cp, err := locateShowChart(args[0], client)
+	if err != nil {
+		return "", err
+	}
+	return client.Run(cp)
+}
+
+func locateShowChart(name string, client *action.Show) (string, error) {
 	slog.Debug("original chart version", "version", client.Version)
 	if client.Version == "" && client.Devel {
 		slog.Debug("setting version to >0.0.0-0")
 		client.Version = ">0.0.0-0"
 	}
-
-	cp, err := client.LocateChart(args[0], settings)
-	if err != nil {
-		return "", err
-	}
-	return client.Run(cp)
+	return client.LocateChart(name, settings)
 }
 
 func addRegistryClient(client *action.Show) error {
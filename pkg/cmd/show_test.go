@@ -91,6 +91,32 @@ func TestShowPreReleaseChart(t *testing.T) {
 	}
 }
 
+func TestShowFilesCmd(t *testing.T) {
+	chartpath := "testdata/testcharts/compressedchart-0.1.0.tgz"
+
+	_, out, err := executeActionCommand("show files " + chartpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Chart.yaml", "values.yaml", "templates/template.tpl", ".helmignore"} {
+		if !strings.Contains(out, name) {
+			t.Errorf("Expected %s to be listed, got:\n%s", name, out)
+		}
+	}
+
+	_, out, err = executeActionCommand("show files " + chartpath + " templates/template.tpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Hello {{.Name | default \"world\"}}\n"; out != expected {
+		t.Errorf("Expected %q, got %q", expected, out)
+	}
+
+	if _, _, err = executeActionCommand("show files " + chartpath + " templates/missing.yaml"); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
 func TestShowVersionCompletion(t *testing.T) {
 	repoFile := "testdata/helmhome/helm/repositories.yaml"
 	repoCache := "testdata/helmhome/helm/repository"
@@ -156,3 +182,7 @@ func TestShowValuesFileCompletion(t *testing.T) {
 func TestShowCRDsFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "show crds", true)
 }
+
+func TestShowFilesFileCompletion(t *testing.T) {
+	checkFileCompletion(t, "show files", true)
+}
@@ -117,6 +117,10 @@ type statusPrinter struct {
 	noColor      bool
 }
 
+func (s statusPrinter) Kind() string {
+	return "Release"
+}
+
 func (s statusPrinter) WriteJSON(out io.Writer) error {
 	return output.EncodeJSON(out, s.release)
 }
@@ -18,6 +18,8 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -29,15 +31,20 @@ import (
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	release "helm.sh/helm/v4/pkg/release/v1"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
 	"helm.sh/helm/v4/pkg/chart/common"
+	charttest "helm.sh/helm/v4/pkg/chart/test"
+	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/engine"
 	releaseutil "helm.sh/helm/v4/pkg/release/v1/util"
 )
 
@@ -47,6 +54,36 @@ Render chart templates locally and display the output.
 Any values that would normally be looked up or retrieved in-cluster will be
 faked locally. Additionally, none of the server-side testing of chart validity
 (e.g. whether an API is supported) is done.
+
+To render a chart directory of a git working tree as of another revision, for
+example to compare its output across branches, use --git-ref. The chart is
+read from the git history, leaving the working tree untouched.
+
+    $ helm template mychart ./charts/mychart --git-ref main
+
+To write each rendered resource to its own file rather than one file per
+template, use --split-resources with --output-dir. The files are named by the
+kind and name of their resource, as in 'deployment/web.yaml', and start with a
+comment recording the template they were rendered from and the digest of the
+values. A manifest-index.json describing the files is written for tooling.
+
+    $ helm template mychart ./charts/mychart --output-dir out --split-resources
+
+To find out why a chart is slow to render, use --profile to print the
+templates that took the longest to render to stderr, or --trace to write a
+JSON trace of the render: the templates executed, how long they took, the
+templates they included, and the values they reference.
+
+    $ helm template mychart ./charts/mychart --profile > /dev/null
+
+To check that the rendered manifests are accepted by Kubernetes without a
+cluster, use --envtest. The manifests are applied to an ephemeral control
+plane, made of the etcd and kube-apiserver binaries installed by setup-envtest
+in the directory named by $KUBEBUILDER_ASSETS, which validates and defaults
+them like a cluster would. The objects rejected, and the fields defaulted, are
+printed to stderr.
+
+    $ KUBEBUILDER_ASSETS=$(setup-envtest use -p path) helm template mychart ./charts/mychart --envtest
 `
 
 func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
@@ -58,6 +95,10 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	var kubeVersion string
 	var extraAPIs []string
 	var showFiles []string
+	var profile bool
+	var traceFile string
+	var envTest bool
+	var splitResources bool
 
 	cmd := &cobra.Command{
 		Use:   "template [NAME] [CHART]",
@@ -67,7 +108,7 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return compInstall(args, toComplete, client)
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			if kubeVersion != "" {
 				parsedKubeVersion, err := common.ParseKubeVersion(kubeVersion)
 				if err != nil {
@@ -76,6 +117,27 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				client.KubeVersion = parsedKubeVersion
 			}
 
+			if envTest && validate {
+				return errors.New("--envtest cannot be used with --validate")
+			}
+
+			if splitResources && client.OutputDir == "" {
+				return errors.New("--split-resources requires --output-dir")
+			}
+			if splitResources && len(showFiles) > 0 {
+				return errors.New("--split-resources cannot be used with --show-only")
+			}
+			// The resources are split from the manifest of the release, so
+			// the templates must not be written to the output directory.
+			outputDir := client.OutputDir
+			if splitResources {
+				client.OutputDir = ""
+			}
+
+			if client.GitRef != "" && (client.DependencyUpdate || client.Verify) {
+				return errors.New("--git-ref cannot be used with --dependency-update or --verify")
+			}
+
 			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
 				client.InsecureSkipTLSverify, client.PlainHTTP, client.Username, client.Password)
 			if err != nil {
@@ -95,7 +157,15 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			client.ClientOnly = !validate
 			client.APIVersions = common.VersionSet(extraAPIs)
 			client.IncludeCRDs = includeCrds
+			if profile || traceFile != "" {
+				cfg.RenderTrace = &engine.RenderTrace{}
+			}
 			rel, err := runInstall(args, client, valueOpts, out)
+			if cfg.RenderTrace != nil {
+				if err := writeRenderTrace(cmd.ErrOrStderr(), cfg.RenderTrace, profile, traceFile); err != nil {
+					return err
+				}
+			}
 
 			if err != nil && !settings.Debug {
 				if rel != nil {
@@ -106,7 +176,23 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 
 			// We ignore a potential error here because, when the --debug flag was specified,
 			// we always want to print the YAML, even if it is not valid. The error is still returned afterwards.
-			if rel != nil {
+			if rel != nil && splitResources {
+				dir := outputDir
+				if client.UseReleaseName {
+					dir = filepath.Join(outputDir, client.ReleaseName)
+				}
+				includeHook := func(h *release.Hook) bool {
+					return !client.DisableHooks && !(skipTests && isTestHook(h))
+				}
+				index, err := action.WriteResourceFiles(dir, rel, includeHook)
+				if err != nil {
+					return err
+				}
+				for _, r := range index.Resources {
+					fmt.Fprintf(out, "wrote %s\n", filepath.Join(dir, filepath.FromSlash(r.File)))
+				}
+				fmt.Fprintf(out, "wrote %s\n", filepath.Join(dir, action.ManifestIndexFile))
+			} else if rel != nil {
 				var manifests bytes.Buffer
 				fmt.Fprintln(&manifests, strings.TrimSpace(rel.Manifest))
 				if !client.DisableHooks {
@@ -188,6 +274,9 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				}
 			}
 
+			if envTest && rel != nil && err == nil {
+				return runEnvTest(cmd.Context(), cmd.ErrOrStderr(), rel, includeCrds, skipTests, settings.Namespace())
+			}
 			return err
 		},
 	}
@@ -197,17 +286,116 @@ func newTemplateCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringArrayVarP(&showFiles, "show-only", "s", []string{}, "only show manifests rendered from the given templates")
 	f.StringVar(&client.OutputDir, "output-dir", "", "writes the executed templates to files in output-dir instead of stdout")
 	f.BoolVar(&validate, "validate", false, "validate your manifests against the Kubernetes cluster you are currently pointing at. This is the same validation performed on an install")
+	f.BoolVar(&envTest, "envtest", false, "validate your manifests against an ephemeral control plane started with the etcd and kube-apiserver binaries of $KUBEBUILDER_ASSETS, and print a report to stderr")
 	f.BoolVar(&includeCrds, "include-crds", false, "include CRDs in the templated output")
 	f.BoolVar(&skipTests, "skip-tests", false, "skip tests from templated output")
 	f.BoolVar(&client.IsUpgrade, "is-upgrade", false, "set .Release.IsUpgrade instead of .Release.IsInstall")
 	f.StringVar(&kubeVersion, "kube-version", "", "Kubernetes version used for Capabilities.KubeVersion")
 	f.StringSliceVarP(&extraAPIs, "api-versions", "a", []string{}, "Kubernetes api versions used for Capabilities.APIVersions (multiple can be specified)")
 	f.BoolVar(&client.UseReleaseName, "release-name", false, "use release name in the output-dir path.")
+	f.BoolVar(&splitResources, "split-resources", false, "with --output-dir, write each rendered resource to its own file named by kind and name, along with a manifest-index.json describing them")
+	f.StringVar(&client.GitRef, "git-ref", "", "render the chart directory as of this git revision (branch, tag or commit), without changing the working tree")
+	f.BoolVar(&profile, "profile", false, "print the templates that took the longest to render to stderr")
+	f.StringVar(&traceFile, "trace", "", "write a JSON trace of the templates rendered, their duration, includes and values referenced to this file")
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 
 	return cmd
 }
 
+// profileTemplates is the number of templates printed by --profile.
+const profileTemplates = 10
+
+// writeRenderTrace prints the slowest templates of trace to w if profile is
+// set, and writes trace as JSON to traceFile if set.
+func writeRenderTrace(w io.Writer, trace *engine.RenderTrace, profile bool, traceFile string) error {
+	if traceFile != "" {
+		data, err := json.MarshalIndent(trace, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(traceFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("could not write the render trace: %w", err)
+		}
+	}
+	if !profile {
+		return nil
+	}
+
+	table := uitable.New()
+	table.AddRow("TEMPLATE", "DURATION", "INCLUDES")
+	for _, t := range trace.Slowest(profileTemplates) {
+		table.AddRow(t.Name, t.Duration.Round(time.Microsecond), countIncludes(t))
+	}
+	if err := output.EncodeTable(w, table); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Rendered %d templates in %s\n", len(trace.Templates), trace.Duration.Round(time.Microsecond))
+	return err
+}
+
+// countIncludes returns the number of templates included by t, directly or
+// not.
+func countIncludes(t *engine.TemplateTrace) int {
+	n := len(t.Includes)
+	for _, i := range t.Includes {
+		n += countIncludes(i)
+	}
+	return n
+}
+
+// runEnvTest applies the manifests and the hooks of rel, and the CRDs of its
+// chart unless they are included in its manifests, to an ephemeral control
+// plane, and prints the objects it rejected and the fields it defaulted to w.
+func runEnvTest(ctx context.Context, w io.Writer, rel *release.Release, includeCrds, skipTests bool, namespace string) error {
+	var crds strings.Builder
+	if !includeCrds && rel.Chart != nil {
+		for _, crd := range rel.Chart.CRDObjects() {
+			fmt.Fprintf(&crds, "---\n# Source: %s\n%s\n", crd.Filename, crd.File.Data)
+		}
+	}
+	var manifest strings.Builder
+	fmt.Fprintln(&manifest, rel.Manifest)
+	for _, h := range rel.Hooks {
+		if skipTests && isTestHook(h) {
+			continue
+		}
+		fmt.Fprintf(&manifest, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+
+	env := &charttest.Environment{}
+	if err := env.Start(); err != nil {
+		return err
+	}
+	defer env.Stop()
+	report, err := env.Accept(ctx, crds.String(), manifest.String(), namespace)
+	if err != nil {
+		return err
+	}
+
+	table := uitable.New()
+	table.MaxColWidth = 80
+	table.Wrap = true
+	table.AddRow("RESULT", "SOURCE", "KIND", "NAME", "DETAILS")
+	for _, r := range report.Results {
+		result, details := "accepted", ""
+		if !r.Accepted {
+			result, details = "rejected", r.Error
+		} else if len(r.Defaulted) > 0 {
+			details = "defaulted " + strings.Join(r.Defaulted, ", ")
+		}
+		table.AddRow(result, r.Path, r.Kind, r.Name, details)
+	}
+	if err := output.EncodeTable(w, table); err != nil {
+		return err
+	}
+	rejected := report.Rejected()
+	fmt.Fprintf(w, "%d of %d objects accepted by the control plane\n", len(report.Results)-rejected, len(report.Results))
+	if rejected > 0 {
+		return fmt.Errorf("%d objects rejected by the control plane", rejected)
+	}
+	return nil
+}
+
 func isTestHook(h *release.Hook) bool {
 	return slices.Contains(h.Events, release.HookTest)
 }
@@ -17,9 +17,17 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/engine"
 )
 
 var chartPath = "testdata/testcharts/subchart"
@@ -48,6 +56,11 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf(`template '%s' --name-template='foobar-{{ b64enc "abc" | lower }}-baz'`, chartPath),
 			golden: "output/template-name-template.txt",
 		},
+		{
+			name:   "check exclude files",
+			cmd:    fmt.Sprintf("template '%s' --exclude-files templates/tests,charts", chartPath),
+			golden: "output/template-exclude-files.txt",
+		},
 		{
 			name:      "check no args",
 			cmd:       "template",
@@ -96,6 +109,50 @@ func TestTemplateCmd(t *testing.T) {
 			cmd:    fmt.Sprintf("template '%s' --include-crds", chartPath),
 			golden: "output/template-with-crds.txt",
 		},
+		{
+			name:   "template with strict values",
+			cmd:    fmt.Sprintf("template '%s' --strict-values", chartPath),
+			golden: "output/template.txt",
+		},
+		{
+			name:      "template with strict values and an undefined value",
+			cmd:       fmt.Sprintf("template '%s' --strict-values --set service=null", chartPath),
+			wantError: true,
+			golden:    "output/template-strict-values.txt",
+		},
+		{
+			name:   "template with values profiles",
+			cmd:    "template profiles testdata/testcharts/chart-with-values-profiles --values-profile production,small --set replicas=5",
+			golden: "output/template-values-profiles.txt",
+		},
+		{
+			name:      "template with a values profile failing its schema",
+			cmd:       "template profiles testdata/testcharts/chart-with-values-profiles --values-profile production --set replicas=1",
+			wantError: true,
+			golden:    "output/template-values-profile-schema.txt",
+		},
+		{
+			name:      "template with an unknown values profile",
+			cmd:       "template profiles testdata/testcharts/chart-with-values-profiles --values-profile staging",
+			wantError: true,
+			golden:    "output/template-values-profile-unknown.txt",
+		},
+		{
+			name:   "template with glob values",
+			cmd:    "template profiles testdata/testcharts/chart-with-values-profiles --values-profile production --set-glob *.memory=2Gi",
+			golden: "output/template-set-glob.txt",
+		},
+		{
+			name:   "template with a values patch",
+			cmd:    "template profiles testdata/testcharts/chart-with-values-profiles --values-patch testdata/values-patch.yaml",
+			golden: "output/template-values-patch.txt",
+		},
+		{
+			name:      "template with a failing values patch",
+			cmd:       "template profiles testdata/testcharts/chart-with-values-profiles --values-profile production --values-patch testdata/values-patch.yaml",
+			wantError: true,
+			golden:    "output/template-values-patch-failed.txt",
+		},
 		{
 			name:   "template with show-only one",
 			cmd:    fmt.Sprintf("template '%s' --show-only templates/service.yaml", chartPath),
@@ -170,6 +227,64 @@ func TestTemplateCmd(t *testing.T) {
 	runTestCmd(t, tests)
 }
 
+func TestTemplateGitRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	chartDir := filepath.Join(repo, "mychart")
+	writeChart := func(replicas string) {
+		t.Helper()
+		for name, data := range map[string]string{
+			"Chart.yaml":               "apiVersion: v2\nname: mychart\nversion: 0.1.0\n",
+			"templates/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm\ndata:\n  replicas: \"" + replicas + "\"\n",
+		} {
+			path := filepath.Join(chartDir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	writeChart("1")
+	git("init", "-q")
+	git("add", "-A")
+	git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	writeChart("3")
+
+	_, out, err := executeActionCommand("template " + chartDir + " --git-ref HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `replicas: "1"`) {
+		t.Errorf("Expected the committed chart to be rendered, got:\n%s", out)
+	}
+
+	_, out, err = executeActionCommand("template " + chartDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `replicas: "3"`) {
+		t.Errorf("Expected the working tree to be rendered, got:\n%s", out)
+	}
+
+	_, _, err = executeActionCommand("template " + chartDir + " --git-ref HEAD --dependency-update")
+	if err == nil || !strings.Contains(err.Error(), "--git-ref cannot be used with --dependency-update or --verify") {
+		t.Errorf("Expected a flag conflict, got %v", err)
+	}
+}
+
 func TestTemplateVersionCompletion(t *testing.T) {
 	repoFile := "testdata/helmhome/helm/repositories.yaml"
 	repoCache := "testdata/helmhome/helm/repository"
@@ -206,3 +321,92 @@ func TestTemplateFileCompletion(t *testing.T) {
 	checkFileCompletion(t, "template myname", true)
 	checkFileCompletion(t, "template myname mychart", false)
 }
+
+func TestTemplateProfile(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "trace.json")
+	_, out, err := executeActionCommand(fmt.Sprintf("template '%s' --profile --trace %s", chartPath, traceFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "TEMPLATE") || !strings.Contains(out, "subchart/templates/service.yaml") {
+		t.Errorf("Expected the slowest templates to be printed, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Rendered 10 templates in ") {
+		t.Errorf("Expected a summary of the render, got:\n%s", out)
+	}
+
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace engine.RenderTrace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range trace.Templates {
+		if tt.Name == "subchart/templates/service.yaml" {
+			if want := []string{"service.externalPort", "service.internalPort", "service.name", "service.type"}; !slices.Equal(tt.ValuesPaths, want) {
+				t.Errorf("Expected values paths %v, got %v", want, tt.ValuesPaths)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected the service to be traced, got %s", data)
+}
+
+func TestTemplateEnvTest(t *testing.T) {
+	_, _, err := executeActionCommand(fmt.Sprintf("template '%s' --envtest --validate", chartPath))
+	if err == nil || err.Error() != "--envtest cannot be used with --validate" {
+		t.Errorf("Expected --envtest and --validate to conflict, got %v", err)
+	}
+
+	t.Setenv("KUBEBUILDER_ASSETS", "")
+	_, _, err = executeActionCommand(fmt.Sprintf("template '%s' --envtest", chartPath))
+	if err == nil || !strings.Contains(err.Error(), "KUBEBUILDER_ASSETS") {
+		t.Errorf("Expected an error about the missing envtest binaries, got %v", err)
+	}
+}
+
+func TestTemplateSplitResources(t *testing.T) {
+	dir := t.TempDir()
+	_, out, err := executeActionCommand(fmt.Sprintf("template '%s' --output-dir %s --split-resources --skip-tests", chartPath, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "wrote "+filepath.Join(dir, "service", "subchart.yaml")) {
+		t.Errorf("Expected the service to be written to its own file, got:\n%s", out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "service", "subchart.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# Source: subchart/templates/service.yaml\n# Values digest: sha256:") {
+		t.Errorf("Expected a header recording the source template and values digest, got:\n%s", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, action.ManifestIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index action.ManifestIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+	if index.Chart != "subchart-0.1.0" {
+		t.Errorf("Expected the index to name the chart, got %q", index.Chart)
+	}
+	for _, r := range index.Resources {
+		if r.Hook {
+			t.Errorf("Expected test hooks to be skipped, got %+v", r)
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(r.File))); err != nil {
+			t.Errorf("Expected %s to be written: %s", r.File, err)
+		}
+	}
+
+	_, _, err = executeActionCommand(fmt.Sprintf("template '%s' --split-resources", chartPath))
+	if err == nil || err.Error() != "--split-resources requires --output-dir" {
+		t.Errorf("Expected --split-resources to require --output-dir, got %v", err)
+	}
+}
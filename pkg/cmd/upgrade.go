@@ -18,19 +18,20 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"helm.sh/helm/v4/pkg/action"
-	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	"helm.sh/helm/v4/pkg/cli/output"
 	"helm.sh/helm/v4/pkg/cli/values"
 	"helm.sh/helm/v4/pkg/cmd/require"
@@ -77,13 +78,27 @@ or '--set' flags. Priority is given to new values.
 The --dry-run flag will output all generated chart manifests, including Secrets
 which can contain sensitive values. To hide Kubernetes Secrets use the
 --hide-secret flag. Please carefully consider how and when these flags are used.
+
+Before changing anything, the upgrade can be analyzed for consequences that
+need review: pods restarted by changed pod templates, changed or removed
+PersistentVolumeClaims, Services whose IPs change, CustomResourceDefinitions
+created, changed or removed, and Roles or bindings granting more permissions.
+The '--show-impact' flag prints them to stderr, as a table or with the
+'--output' format, and '--confirm' asks for confirmation before upgrading:
+
+    $ helm upgrade --confirm redis ./redis
+    $ helm upgrade --dry-run --show-impact -o json redis ./redis 2> impact.json
 `
 
 func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	client := action.NewUpgrade(cfg)
 	valueOpts := &values.Options{}
 	var outfmt output.Format
+	var confirm bool
+	var showImpact bool
 	var createNamespace bool
+	var chartRefreshPolicy string
+	var historyCompaction string
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [RELEASE] [CHART]",
@@ -99,7 +114,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 			return noMoreArgsComp()
 		},
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			client.Namespace = settings.Namespace()
 
 			registryClient, err := newRegistryClient(client.CertFile, client.KeyFile, client.CaFile,
@@ -135,9 +150,11 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					instClient.DryRunOption = client.DryRunOption
 					instClient.DisableHooks = client.DisableHooks
 					instClient.SkipCRDs = client.SkipCRDs
+					instClient.SkipPrerequisites = client.SkipPrerequisites
 					instClient.Timeout = client.Timeout
 					instClient.WaitStrategy = client.WaitStrategy
 					instClient.WaitForJobs = client.WaitForJobs
+					instClient.WaitProgress = client.WaitProgress
 					instClient.Devel = client.Devel
 					instClient.Namespace = client.Namespace
 					instClient.RollbackOnFailure = client.RollbackOnFailure
@@ -150,8 +167,12 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 					instClient.DependencyUpdate = client.DependencyUpdate
 					instClient.Labels = client.Labels
 					instClient.EnableDNS = client.EnableDNS
+					instClient.StrictValues = client.StrictValues
+					instClient.ValuesProfiles = client.ValuesProfiles
+					instClient.GlobValues = client.GlobValues
 					instClient.HideSecret = client.HideSecret
 					instClient.TakeOwnership = client.TakeOwnership
+					instClient.ExportBundle = client.ExportBundle
 
 					if isReleaseUninstalled(versions) {
 						instClient.Replace = true
@@ -173,6 +194,15 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				}
 			}
 
+			client.ChartRefreshPolicy, err = action.ParseChartRefreshPolicy(chartRefreshPolicy)
+			if err != nil {
+				return err
+			}
+			client.HistoryCompaction, err = action.ParseHistoryCompaction(historyCompaction)
+			if err != nil {
+				return err
+			}
+
 			if client.Version == "" && client.Devel {
 				slog.Debug("setting version to >0.0.0-0")
 				client.Version = ">0.0.0-0"
@@ -188,13 +218,17 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 			}
 
 			p := getter.All(settings)
-			vals, err := valueOpts.MergeValues(p)
+			vals, sources, err := valueOpts.MergeValuesWithSources(p)
 			if err != nil {
 				return err
 			}
+			client.ValuesSources = sources
+			if client.ValuesPatches, err = valueOpts.ReadPatches(p); err != nil {
+				return err
+			}
 
 			// Check chart dependencies to make sure all are present in /charts
-			ch, err := loader.Load(chartPath)
+			ch, err := client.LoadChart(chartPath)
 			if err != nil {
 				return err
 			}
@@ -217,7 +251,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 							return err
 						}
 						// Reload the chart with the updated Chart.lock file.
-						if ch, err = loader.Load(chartPath); err != nil {
+						if ch, err = client.LoadChart(chartPath); err != nil {
 							return fmt.Errorf("failed reloading chart after repo update: %w", err)
 						}
 					} else {
@@ -245,12 +279,19 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 				cancel()
 			}()
 
-			rel, err := client.RunWithContext(ctx, args[0], ch, vals)
-			if err != nil {
-				return fmt.Errorf("UPGRADE FAILED: %w", err)
+			if confirm || showImpact {
+				dryRun := client.DryRun || slices.Contains([]string{"true", "client", "server"}, client.DryRunOption)
+				client.Approve = upgradeApproval(args[0], outfmt, showImpact, confirm && !dryRun, cmd.InOrStdin(), cmd.ErrOrStderr())
 			}
 
-			if outfmt == output.Table {
+			rel, err := client.RunWithContext(ctx, args[0], ch, vals)
+			if errors.Is(err, action.ErrNoChange) {
+				if outfmt == output.Table {
+					fmt.Fprintf(out, "Release %q is unchanged. Skipping upgrade.\n", args[0])
+				}
+			} else if err != nil {
+				return fmt.Errorf("UPGRADE FAILED: %w", err)
+			} else if outfmt == output.Table {
 				fmt.Fprintf(out, "Release %q has been upgraded. Happy Helming!\n", args[0])
 			}
 
@@ -274,10 +315,13 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.ForceReplace, "force-replace", false, "force resource updates by replacement")
 	f.BoolVar(&client.ForceReplace, "force", false, "deprecated")
 	f.MarkDeprecated("force", "use --force-replace instead")
+	f.StringSliceVar(&client.ForceRecreate, "force-recreate", []string{}, "delete and recreate the given resources (as kind/name) when their update is rejected because of immutable fields")
 	f.BoolVar(&client.ForceConflicts, "force-conflicts", false, "if set server-side apply will force changes against conflicts")
 	f.StringVar(&client.ServerSideApply, "server-side", "auto", "must be \"true\", \"false\" or \"auto\". Object updates run in the server instead of the client (\"auto\" defaults the value from the previous chart release's method)")
+	addFieldManagerFlag(f, cfg)
 	f.BoolVar(&client.DisableHooks, "no-hooks", false, "disable pre/post upgrade hooks")
 	f.BoolVar(&client.DisableOpenAPIValidation, "disable-openapi-validation", false, "if set, the upgrade process will not validate rendered templates against the Kubernetes OpenAPI Schema")
+	f.BoolVar(&client.SkipPrerequisites, "skip-prerequisites", false, "if set, the prerequisites in the requires section of Chart.yaml are not checked against the cluster")
 	f.BoolVar(&client.SkipCRDs, "skip-crds", false, "if set, no CRDs will be installed when an upgrade is performed with install flag enabled. By default, CRDs are installed if not already present, when an upgrade is performed with install flag enabled")
 	f.DurationVar(&client.Timeout, "timeout", 300*time.Second, "time to wait for any individual Kubernetes operation (like Jobs for hooks)")
 	f.BoolVar(&client.ResetValues, "reset-values", false, "when upgrading, reset the values to the ones built into the chart")
@@ -288,6 +332,7 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.BoolVar(&client.RollbackOnFailure, "atomic", false, "deprecated")
 	f.MarkDeprecated("atomic", "use --rollback-on-failure instead")
 	f.IntVar(&client.MaxHistory, "history-max", settings.MaxHistory, "limit the maximum number of revisions saved per release. Use 0 for no limit")
+	f.IntVar(&client.MaxFailedHistory, "history-max-failed", settings.MaxFailedHistory, "limit the number of failed and pending-rollback revisions saved per release, pruned before the other revisions. Use 0 for no separate limit")
 	f.BoolVar(&client.CleanupOnFail, "cleanup-on-fail", false, "allow deletion of new resources created in this upgrade when upgrade fails")
 	f.BoolVar(&client.SubNotes, "render-subchart-notes", false, "if set, render subchart notes along with the parent")
 	f.BoolVar(&client.HideNotes, "hide-notes", false, "if set, do not show notes in upgrade output. Does not affect presence in chart metadata")
@@ -296,12 +341,23 @@ func newUpgradeCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
 	f.StringVar(&client.Description, "description", "", "add a custom description")
 	f.BoolVar(&client.DependencyUpdate, "dependency-update", false, "update dependencies if they are missing before installing the chart")
 	f.BoolVar(&client.EnableDNS, "enable-dns", false, "enable DNS lookups when rendering templates")
+	f.BoolVar(&client.StrictValues, "strict-values", false, "fail rendering when a template references a value that is not defined")
+	f.StringSliceVar(&client.ValuesProfiles, "values-profile", []string{}, "layer a values profile from the values/ directory of the chart under the supplied values (can specify multiple or separate values with commas: production,small)")
+	f.StringArrayVar(&client.GlobValues, "set-glob", []string{}, "set values on the command line with wildcards in their keys, expanded against the values of the chart (can specify multiple or separate values with commas: workers.*.replicas=2,workers.*.cpu=500m)")
 	f.BoolVar(&client.TakeOwnership, "take-ownership", false, "if set, upgrade will ignore the check for helm annotations and take ownership of the existing resources")
+	f.BoolVar(&showImpact, "show-impact", false, "print the impacts of the upgrade, such as pod restarts and Service IP changes, to stderr in the --output format before upgrading")
+	f.BoolVar(&confirm, "confirm", false, "print the impacts of the upgrade and ask for confirmation before upgrading. Ignored with --dry-run")
+	f.StringVar(&client.ExportBundle, "export-bundle", "", "after the release is deployed, export its rollback bundle to this directory or OCI repository (oci://). See 'helm rollback --from-bundle'")
+	f.StringVar(&chartRefreshPolicy, "chart-refresh-policy", string(action.ChartRefreshAlways), "must be \"always\", \"if-changed\" or \"never\". \"if-changed\" skips the upgrade when the chart and values match the deployed release, \"never\" additionally refuses to replace the deployed chart")
+	f.StringVar(&historyCompaction, "history-compaction", string(action.HistoryCompactionNone), "must be \"none\", \"skip\" or \"reference\". When the rendered manifest and values match the deployed release, \"skip\" creates no revision and \"reference\" creates one that shares the chart and manifest of the deployed revision instead of copying them")
 	addChartPathOptionsFlags(f, &client.ChartPathOptions)
+	addChartFilterFlags(f, &client.Filter)
 	addValueOptionsFlags(f, valueOpts)
+	addValuesPatchFlag(f, valueOpts)
 	bindOutputFlag(cmd, &outfmt)
 	bindPostRenderFlag(cmd, &client.PostRenderer, settings)
 	AddWaitFlag(cmd, &client.WaitStrategy)
+	addWaitProgressFlag(cmd, &client.WaitProgress)
 	cmd.MarkFlagsMutuallyExclusive("force-replace", "force-conflicts")
 	cmd.MarkFlagsMutuallyExclusive("force", "force-conflicts")
 
@@ -0,0 +1,101 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gosuri/uitable"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+)
+
+// errUpgradeNotConfirmed is returned when the user declines an upgrade.
+var errUpgradeNotConfirmed = errors.New("upgrade not confirmed")
+
+// upgradeApproval returns the approval gate of 'helm upgrade'. It prints the
+// impacts of the upgrade to errOut in the output format if show is set, and
+// asks the user to confirm the upgrade on in if confirm is set.
+func upgradeApproval(name string, outfmt output.Format, show, confirm bool, in io.Reader, errOut io.Writer) func([]action.Impact) error {
+	return func(impacts []action.Impact) error {
+		w := &impactWriter{impacts: impacts}
+		if show {
+			if err := outfmt.Write(errOut, w); err != nil {
+				return err
+			}
+		}
+		if !confirm {
+			return nil
+		}
+		if !show || outfmt != output.Table {
+			if err := w.WriteTable(errOut); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(errOut, "Upgrade release %q? [y/N]: ", name)
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return nil
+		}
+		return errUpgradeNotConfirmed
+	}
+}
+
+// impactWriter prints the impacts of an upgrade.
+type impactWriter struct {
+	impacts []action.Impact
+}
+
+func (w *impactWriter) WriteTable(out io.Writer) error {
+	if len(w.impacts) == 0 {
+		_, err := fmt.Fprintln(out, "The upgrade has no impact that needs review")
+		return err
+	}
+	table := uitable.New()
+	table.MaxColWidth = 100
+	table.Wrap = true
+	table.AddRow("IMPACT", "KIND", "NAMESPACE", "NAME", "REASON")
+	for _, i := range w.impacts {
+		table.AddRow(i.Category, i.Kind, i.Namespace, i.Name, i.Reason)
+	}
+	return output.EncodeTable(out, table)
+}
+
+func (w *impactWriter) Kind() string {
+	return "UpgradeImpact"
+}
+
+func (w *impactWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, w.structured())
+}
+
+func (w *impactWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, w.structured())
+}
+
+func (w *impactWriter) structured() []action.Impact {
+	if w.impacts == nil {
+		return []action.Impact{}
+	}
+	return w.impacts
+}
@@ -574,3 +574,58 @@ func TestUpgradeWithDryRun(t *testing.T) {
 		t.Error("expected error when --hide-secret used without --dry-run")
 	}
 }
+
+func TestUpgradeWithConfirm(t *testing.T) {
+	releaseName := "funny-bunny-impact"
+	relMock, ch, chartPath := prepareMockRelease(t, releaseName)
+
+	defer resetEnv()()
+
+	store := storageFixture()
+	rel := relMock(releaseName, 1, ch)
+	rel.Manifest = "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n  namespace: default\n"
+	store.Create(rel)
+
+	answer := func(s string) *os.File {
+		t.Helper()
+		in := filepath.Join(t.TempDir(), "answer")
+		if err := os.WriteFile(in, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Open(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+
+	cmd := fmt.Sprintf("upgrade %s --confirm '%s'", releaseName, chartPath)
+	_, out, err := executeActionCommandStdinC(store, answer("n\n"), cmd)
+	if err == nil || !strings.Contains(err.Error(), "upgrade not confirmed") {
+		t.Errorf("expected the upgrade not to be confirmed, got %v", err)
+	}
+	if !strings.Contains(out, "removed; its IPs are released") || !strings.Contains(out, fmt.Sprintf("Upgrade release %q? [y/N]: ", releaseName)) {
+		t.Errorf("expected the impacts and a prompt, got:\n%s", out)
+	}
+	if _, err := store.Get(releaseName, 2); err == nil {
+		t.Error("expected no new release after declining the upgrade")
+	}
+
+	_, _, err = executeActionCommandStdinC(store, answer("y\n"), cmd)
+	if err != nil {
+		t.Errorf("unexpected error, got '%v'", err)
+	}
+	if _, err := store.Get(releaseName, 2); err != nil {
+		t.Errorf("expected the confirmed upgrade to be stored, got '%v'", err)
+	}
+
+	cmd = fmt.Sprintf("upgrade %s --dry-run --confirm --show-impact -o json '%s'", releaseName, chartPath)
+	_, out, err = executeActionCommandC(store, cmd)
+	if err != nil {
+		t.Errorf("unexpected error, got '%v'", err)
+	}
+	if !strings.HasPrefix(out, "[]\n") {
+		t.Errorf("expected an empty list of impacts before the release, got:\n%s", out)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cli/values"
+	"helm.sh/helm/v4/pkg/cmd/require"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+const valuesBuildDesc = `
+Merge values files and values set on the command line the way 'helm install'
+does, and print the result.
+
+The values are merged in the order they are given: the values files from left
+to right, then the --set flags. With --schema, the merged values are coalesced
+with the default values of a chart directory or archive and of its enabled
+dependencies, and validated against the values.schema.json files of the
+charts, exactly as 'helm install' would before rendering the templates:
+
+    $ helm values build -f base.yaml -f prod.yaml --set image.tag=1.2.3 --schema ./mychart
+
+The command fails if the values do not meet a schema, so it can be used to
+check values in a CI pipeline without rendering the chart.
+`
+
+func newValuesBuildCmd(out io.Writer) *cobra.Command {
+	valueOpts := &values.Options{}
+	var schema string
+	var skipSchemaValidation bool
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "merge values files and validate them against the schema of a chart",
+		Long:  valuesBuildDesc,
+		Args:  require.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			vals, err := valueOpts.MergeValues(getter.All(settings))
+			if err != nil {
+				return err
+			}
+
+			if schema != "" {
+				chrt, err := loader.Load(schema)
+				if err != nil {
+					return err
+				}
+				if req := chrt.Metadata.Dependencies; req != nil {
+					if err := action.CheckDependencies(chrt, req); err != nil {
+						return fmt.Errorf("an error occurred while checking for chart dependencies. You may need to run `helm dependency build` to fetch missing dependencies: %w", err)
+					}
+				}
+				if vals, err = action.BuildValues(chrt, vals, skipSchemaValidation); err != nil {
+					return err
+				}
+			}
+
+			return outfmt.Write(out, builtValuesWriter(vals))
+		},
+	}
+
+	f := cmd.Flags()
+	addValueOptionsFlags(f, valueOpts)
+	f.StringVar(&schema, "schema", "", "coalesce the values with the defaults of this chart and validate them against its schema")
+	f.BoolVar(&skipSchemaValidation, "skip-schema-validation", false, "coalesce the values with the defaults of the chart given with --schema without validating them")
+	bindOutputFlag(cmd, &outfmt)
+
+	return cmd
+}
+
+// builtValuesWriter writes merged values, as YAML unless another format is
+// requested.
+type builtValuesWriter map[string]interface{}
+
+func (v builtValuesWriter) Kind() string {
+	return "Values"
+}
+
+func (v builtValuesWriter) WriteTable(out io.Writer) error {
+	return output.EncodeYAML(out, map[string]interface{}(v))
+}
+
+func (v builtValuesWriter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, map[string]interface{}(v))
+}
+
+func (v builtValuesWriter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, map[string]interface{}(v))
+}
@@ -0,0 +1,68 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestValuesBuildCmd(t *testing.T) {
+	chartPath := "testdata/testcharts/chart-with-schema"
+	tests := []cmdTestCase{
+		{
+			name:   "merge values files and set values",
+			cmd:    "values build -f testdata/testcharts/upgradetest/values.yaml -f testdata/testcharts/chart-with-schema/values.yaml --set age=30,employmentInfo.title=CTO",
+			golden: "output/values-build.txt",
+		},
+		{
+			name:   "coalesce with the chart values",
+			cmd:    "values build --set employmentInfo.salary=200000 --schema " + chartPath,
+			golden: "output/values-build-schema.txt",
+		},
+		{
+			name:   "json output",
+			cmd:    "values build --set age=30 -o json",
+			golden: "output/values-build-json.txt",
+		},
+		{
+			name:   "versioned json output",
+			cmd:    "values build --set age=30 -o json/v1",
+			golden: "output/values-build-json-v1.txt",
+		},
+		{
+			name:      "values not meeting the schema",
+			cmd:       "values build -f " + chartPath + "/extra-values.yaml --schema " + chartPath,
+			wantError: true,
+		},
+		{
+			name:      "values not meeting the schema of a subchart",
+			cmd:       "values build --schema testdata/testcharts/chart-with-schema-and-subchart",
+			wantError: true,
+		},
+		{
+			name:   "skip schema validation",
+			cmd:    "values build -f " + chartPath + "/extra-values.yaml --schema " + chartPath + " --skip-schema-validation",
+			golden: "output/values-build-skip-schema-validation.txt",
+		},
+		{
+			name:      "missing dependencies",
+			cmd:       "values build --schema testdata/testcharts/chart-missing-deps",
+			wantError: true,
+		},
+	}
+	runTestCmd(t, tests)
+}
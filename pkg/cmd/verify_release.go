@@ -0,0 +1,141 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"helm.sh/helm/v4/pkg/action"
+	"helm.sh/helm/v4/pkg/cli/output"
+	"helm.sh/helm/v4/pkg/cmd/require"
+)
+
+const verifyReleaseDesc = `
+This command checks that a release was not corrupted or tampered with.
+
+It checks that:
+
+- the chart, values, manifest and hooks stored for the release have the
+  digests recorded when the release was created,
+- the chart archive in the repository or registry the chart was pulled from
+  has the digest recorded when the release was created, and
+- the resources of the release exist in the cluster and carry the ownership
+  metadata of the release.
+
+Releases created by older versions of Helm have no recorded digests, and those
+checks are skipped. Use '--skip-chart-source' to verify without network access
+to the chart source, and '--skip-live' to verify without access to the cluster.
+
+The command fails if any check fails:
+
+    $ helm verify-release my-release
+    $ helm verify-release my-release --revision 3 -o json
+`
+
+func newVerifyReleaseCmd(cfg *action.Configuration, out io.Writer) *cobra.Command {
+	client := action.NewVerifyRelease(cfg)
+	var outfmt output.Format
+
+	cmd := &cobra.Command{
+		Use:   "verify-release RELEASE_NAME",
+		Short: "check a release for corruption or tampering",
+		Long:  verifyReleaseDesc,
+		Args:  require.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return noMoreArgsComp()
+			}
+			return compListReleases(toComplete, args, cfg)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			checks, err := client.Run(args[0], settings)
+			if err != nil {
+				return err
+			}
+			if err := outfmt.Write(out, &verifyReleasePrinter{checks: checks}); err != nil {
+				return err
+			}
+			var failed int
+			for _, c := range checks {
+				if c.Status == action.IntegrityFailed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("release %q failed %d of %d integrity checks", args[0], failed, len(checks))
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&client.Version, "revision", 0, "verify the named release with revision")
+	f.BoolVar(&client.SkipChartSource, "skip-chart-source", false, "do not pull the chart from its repository or registry")
+	f.BoolVar(&client.SkipLive, "skip-live", false, "do not check the resources of the release in the cluster")
+	bindOutputFlag(cmd, &outfmt)
+
+	err := cmd.RegisterFlagCompletionFunc("revision", func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return compListRevisions(toComplete, cfg, args[0])
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return cmd
+}
+
+type verifyReleasePrinter struct {
+	checks []*action.IntegrityCheck
+}
+
+func (p *verifyReleasePrinter) Kind() string {
+	return "ReleaseIntegrity"
+}
+
+func (p *verifyReleasePrinter) WriteJSON(out io.Writer) error {
+	return output.EncodeJSON(out, p.items())
+}
+
+func (p *verifyReleasePrinter) WriteYAML(out io.Writer) error {
+	return output.EncodeYAML(out, p.items())
+}
+
+func (p *verifyReleasePrinter) WriteTable(out io.Writer) error {
+	tbl := uitable.New()
+	tbl.MaxColWidth = 100
+	tbl.Wrap = true
+	tbl.AddRow("CHECK", "SUBJECT", "STATUS", "DETAILS")
+	for _, c := range p.checks {
+		tbl.AddRow(c.Check, c.Subject, c.Status, c.Message)
+	}
+	return output.EncodeTable(out, tbl)
+}
+
+func (p *verifyReleasePrinter) items() []*action.IntegrityCheck {
+	if p.checks == nil {
+		return []*action.IntegrityCheck{}
+	}
+	return p.checks
+}
@@ -0,0 +1,58 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	release "helm.sh/helm/v4/pkg/release/v1"
+)
+
+func TestVerifyReleaseCmd(t *testing.T) {
+	legacy := release.Mock(&release.MockReleaseOptions{Name: "legacy"})
+	tampered := release.Mock(&release.MockReleaseOptions{Name: "tampered", Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "foo", Version: "0.1.0"}}})
+	tampered.Integrity = &release.Integrity{
+		Manifest: "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+	}
+	rels := []*release.Release{legacy, tampered}
+
+	tests := []cmdTestCase{{
+		name:   "release without recorded digests",
+		cmd:    "verify-release legacy --skip-live",
+		golden: "output/verify-release-legacy.txt",
+		rels:   rels,
+	}, {
+		name:   "release without recorded digests as json",
+		cmd:    "verify-release legacy --skip-live -o json",
+		golden: "output/verify-release-legacy-json.txt",
+		rels:   rels,
+	}, {
+		name:      "tampered release",
+		cmd:       "verify-release tampered --skip-live",
+		golden:    "output/verify-release-tampered.txt",
+		rels:      rels,
+		wantError: true,
+	}, {
+		name:      "missing release",
+		cmd:       "verify-release missing --skip-live",
+		golden:    "output/verify-release-missing.txt",
+		rels:      rels,
+		wantError: true,
+	}}
+	runTestCmd(t, tests)
+}
@@ -0,0 +1,348 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"go.yaml.in/yaml/v3"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/registry"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+// BumpStrategy describes how Manager.Update raises the version constraints of
+// the dependencies before resolving them.
+type BumpStrategy string
+
+const (
+	// BumpNone leaves the version constraints untouched.
+	BumpNone BumpStrategy = ""
+	// BumpPatch moves each dependency to the newest release with the same
+	// major and minor version.
+	BumpPatch BumpStrategy = "patch"
+	// BumpMinor moves each dependency to the newest release with the same
+	// major version.
+	BumpMinor BumpStrategy = "minor"
+	// BumpLatestCompatible moves each dependency to its newest release, across
+	// major versions, whose chart API version the parent chart can depend on.
+	BumpLatestCompatible BumpStrategy = "latest-compatible"
+)
+
+// BumpStrategies lists the strategies accepted by Manager.Bump.
+var BumpStrategies = []BumpStrategy{BumpPatch, BumpMinor, BumpLatestCompatible}
+
+func (s BumpStrategy) validate() error {
+	for _, v := range BumpStrategies {
+		if s == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid bump strategy %q: must be one of patch, minor or latest-compatible", s)
+}
+
+// bumpCandidate is an available version of a dependency.
+type bumpCandidate struct {
+	version    *semver.Version
+	apiVersion string
+}
+
+// bump raises the version constraints of the repository dependencies of the
+// chart to the newest versions allowed by the strategy, and rewrites them in
+// the file declaring the dependencies.
+func (m *Manager) bump(c *chart.Chart, repoNames map[string]string) error {
+	bumped := map[int]string{}
+	for i, d := range c.Metadata.Dependencies {
+		// Local charts are not versioned by a repository.
+		if d.Repository == "" || strings.HasPrefix(d.Repository, "file://") {
+			continue
+		}
+
+		candidates, err := m.bumpCandidates(d, repoNames[d.Name])
+		if err != nil {
+			return err
+		}
+		current, err := currentVersion(d, c.Lock, candidates)
+		if err != nil {
+			return err
+		}
+		next := selectBump(m.Bump, c.Metadata.APIVersion, current, candidates)
+		if next == nil || next.LessThan(current) {
+			continue
+		}
+
+		// The constraint is rewritten even when the current version is already
+		// the newest, raising its lower bound like `go get -u` does.
+		constraint := bumpConstraint(m.Bump, d.Version, next)
+		if constraint == d.Version {
+			continue
+		}
+		fmt.Fprintf(m.Out, "Bumping %s from %q to %q\n", d.Name, d.Version, constraint)
+		d.Version = constraint
+		bumped[i] = constraint
+	}
+	if len(bumped) == 0 {
+		return nil
+	}
+
+	return rewriteDependencyVersions(dependenciesFile(m.ChartPath, c), bumped)
+}
+
+// bumpCandidates returns the released versions of a dependency, newest first.
+func (m *Manager) bumpCandidates(d *chart.Dependency, repoName string) ([]bumpCandidate, error) {
+	var candidates []bumpCandidate
+	if registry.IsOCI(d.Repository) {
+		if m.RegistryClient == nil {
+			return nil, fmt.Errorf("no registry client to list the versions of %s", d.Name)
+		}
+		ref := fmt.Sprintf("%s/%s", strings.TrimPrefix(d.Repository, fmt.Sprintf("%s://", registry.OCIScheme)), d.Name)
+		tags, err := m.RegistryClient.Tags(ref)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve list of tags for repository %s: %w", d.Repository, err)
+		}
+		for _, tag := range tags {
+			if v, err := semver.NewVersion(tag); err == nil {
+				candidates = append(candidates, bumpCandidate{version: v})
+			}
+		}
+	} else {
+		index, err := repo.LoadIndexFile(filepath.Join(m.RepositoryCache, helmpath.CacheIndexFile(repoName)))
+		if err != nil {
+			return nil, fmt.Errorf("no cached repository for %s found. (try 'helm repo update'): %w", repoName, err)
+		}
+		versions, ok := index.Entries[d.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s chart not found in repo %s", d.Name, d.Repository)
+		}
+		for _, cv := range versions {
+			v, err := semver.NewVersion(cv.Version)
+			if err != nil || len(cv.URLs) == 0 {
+				// Not a legit entry.
+				continue
+			}
+			candidates = append(candidates, bumpCandidate{version: v, apiVersion: cv.APIVersion})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].version.GreaterThan(candidates[j].version)
+	})
+	return candidates, nil
+}
+
+// currentVersion returns the version of a dependency the bump starts from: the
+// locked version when it still satisfies the constraint, or else the newest
+// available version satisfying it.
+func currentVersion(d *chart.Dependency, lock *chart.Lock, candidates []bumpCandidate) (*semver.Version, error) {
+	constraint, err := semver.NewConstraint(d.Version)
+	if err != nil {
+		return nil, fmt.Errorf("dependency %q has an invalid version/constraint format: %w", d.Name, err)
+	}
+
+	if lock != nil {
+		for _, l := range lock.Dependencies {
+			if l.Name != d.Name || l.Repository != d.Repository {
+				continue
+			}
+			if v, err := semver.NewVersion(l.Version); err == nil && constraint.Check(v) {
+				return v, nil
+			}
+		}
+	}
+
+	for _, c := range candidates {
+		if constraint.Check(c.version) {
+			return c.version, nil
+		}
+	}
+	return nil, fmt.Errorf("can't get a valid version for %q (repository %q, version %q) to bump from", d.Name, d.Repository, d.Version)
+}
+
+// selectBump returns the newest candidate the strategy allows moving to from
+// the current version, or nil if there is none. Pre-releases are only
+// considered when bumping from a pre-release.
+func selectBump(strategy BumpStrategy, parentAPIVersion string, current *semver.Version, candidates []bumpCandidate) *semver.Version {
+	for _, c := range candidates {
+		v := c.version
+		if v.Prerelease() != "" && current.Prerelease() == "" {
+			continue
+		}
+		switch strategy {
+		case BumpPatch:
+			if v.Major() != current.Major() || v.Minor() != current.Minor() {
+				continue
+			}
+		case BumpMinor:
+			if v.Major() != current.Major() {
+				continue
+			}
+		case BumpLatestCompatible:
+			if !compatibleAPIVersion(parentAPIVersion, c.apiVersion) {
+				continue
+			}
+		}
+		return v
+	}
+	return nil
+}
+
+// compatibleAPIVersion reports whether a chart of the given API version can
+// depend on a chart of the other one. Unknown API versions, such as those of
+// OCI tags, are assumed compatible.
+func compatibleAPIVersion(parent, dependency string) bool {
+	switch dependency {
+	case "", chart.APIVersionV1:
+		return true
+	case chart.APIVersionV2:
+		return parent != chart.APIVersionV1
+	}
+	return false
+}
+
+// bumpConstraint returns the constraint for the new version. Exact versions
+// stay exact, and tilde and caret ranges keep their operator. Other ranges are
+// replaced with the range of the strategy: a tilde range for patch bumps and a
+// caret range otherwise.
+func bumpConstraint(strategy BumpStrategy, old string, next *semver.Version) string {
+	old = strings.TrimSpace(old)
+	switch {
+	case isExactVersion(old):
+		return next.Original()
+	case strings.HasPrefix(old, "~") && !strings.Contains(old, " "):
+		return "~" + next.Original()
+	case strings.HasPrefix(old, "^") && !strings.Contains(old, " "):
+		return "^" + next.Original()
+	case strategy == BumpPatch:
+		return "~" + next.Original()
+	}
+	return "^" + next.Original()
+}
+
+func isExactVersion(s string) bool {
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(s, "v"))
+	return err == nil
+}
+
+// dependenciesFile returns the file declaring the dependencies of the chart.
+func dependenciesFile(chartpath string, c *chart.Chart) string {
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		if p := filepath.Join(chartpath, "requirements.yaml"); fileExists(p) {
+			return p
+		}
+	}
+	return filepath.Join(chartpath, "Chart.yaml")
+}
+
+func fileExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+// rewriteDependencyVersions replaces the version of the dependencies at the
+// given indexes in the file. Only the version scalars are rewritten so the
+// comments and the layout of the file are preserved.
+func rewriteDependencyVersions(filename string, versions map[int]string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("cannot parse %s: %w", filename, err)
+	}
+	deps := mappingValue(&doc, "dependencies")
+	if deps == nil || deps.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%s: no dependencies found", filename)
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	for i, version := range versions {
+		if i >= len(deps.Content) {
+			return fmt.Errorf("%s: dependency %d not found", filename, i)
+		}
+		node := mappingValue(deps.Content[i], "version")
+		if node == nil {
+			return fmt.Errorf("%s: dependency %d has no version", filename, i)
+		}
+		if err := replaceScalar(lines, node, version); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, bytes.Join(lines, []byte("\n")), fi.Mode())
+}
+
+// mappingValue returns the value of a key of a mapping node, unwrapping a
+// document node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// replaceScalar replaces a single-line scalar in place, keeping its quoting.
+func replaceScalar(lines [][]byte, node *yaml.Node, value string) error {
+	var quote string
+	switch node.Style {
+	case 0:
+	case yaml.DoubleQuotedStyle:
+		quote = `"`
+	case yaml.SingleQuotedStyle:
+		quote = `'`
+	default:
+		return fmt.Errorf("line %d: cannot rewrite version %q", node.Line, node.Value)
+	}
+
+	if node.Line < 1 || node.Line > len(lines) {
+		return fmt.Errorf("line %d: version %q not found", node.Line, node.Value)
+	}
+	line := lines[node.Line-1]
+	start := node.Column - 1
+	old := []byte(quote + node.Value + quote)
+	if start < 0 || start > len(line) || !bytes.HasPrefix(line[start:], old) {
+		return fmt.Errorf("line %d: cannot rewrite version %q", node.Line, node.Value)
+	}
+
+	var b bytes.Buffer
+	b.Write(line[:start])
+	b.WriteString(quote + value + quote)
+	b.Write(line[start+len(old):])
+	lines[node.Line-1] = b.Bytes()
+	return nil
+}
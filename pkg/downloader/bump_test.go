@@ -0,0 +1,184 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/repo/v1/repotest"
+)
+
+func TestSelectBump(t *testing.T) {
+	var candidates []bumpCandidate
+	for _, v := range []string{"3.0.0", "2.1.0-beta.1", "2.0.0", "1.3.0", "1.2.4", "1.2.3"} {
+		apiVersion := chart.APIVersionV2
+		if v == "3.0.0" {
+			apiVersion = "v3"
+		}
+		candidates = append(candidates, bumpCandidate{version: semver.MustParse(v), apiVersion: apiVersion})
+	}
+
+	tests := []struct {
+		strategy BumpStrategy
+		current  string
+		expect   string
+	}{
+		{BumpPatch, "1.2.3", "1.2.4"},
+		{BumpMinor, "1.2.3", "1.3.0"},
+		{BumpLatestCompatible, "1.2.3", "2.0.0"},
+		{BumpLatestCompatible, "2.1.0-alpha.1", "2.1.0-beta.1"},
+		{BumpPatch, "1.4.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy)+" "+tt.current, func(t *testing.T) {
+			got := selectBump(tt.strategy, chart.APIVersionV2, semver.MustParse(tt.current), candidates)
+			if tt.expect == "" {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.expect, got.Original())
+		})
+	}
+}
+
+func TestBumpConstraint(t *testing.T) {
+	tests := []struct {
+		strategy BumpStrategy
+		old      string
+		expect   string
+	}{
+		{BumpMinor, "1.2.3", "1.5.0"},
+		{BumpMinor, "~1.2.3", "~1.5.0"},
+		{BumpPatch, "^1.2.3", "^1.5.0"},
+		{BumpPatch, ">=1.2.0 <2.0.0", "~1.5.0"},
+		{BumpMinor, "1.x", "^1.5.0"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expect, bumpConstraint(tt.strategy, tt.old, semver.MustParse("1.5.0")), tt.old)
+	}
+}
+
+func TestRewriteDependencyVersions(t *testing.T) {
+	in := `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+# The database.
+- name: db
+  version: "~1.2.0" # pinned to patch releases
+  repository: https://example.com/charts
+- name: cache
+  repository: https://example.com/charts
+  version: 2.0.0
+`
+	expect := `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+# The database.
+- name: db
+  version: "~1.2.5" # pinned to patch releases
+  repository: https://example.com/charts
+- name: cache
+  repository: https://example.com/charts
+  version: 2.3.1
+`
+	filename := filepath.Join(t.TempDir(), "Chart.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(in), 0o644))
+	require.NoError(t, rewriteDependencyVersions(filename, map[int]string{0: "~1.2.5", 1: "2.3.1"}))
+
+	out, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, expect, string(out))
+
+	assert.ErrorContains(t, rewriteDependencyVersions(filename, map[int]string{2: "1.0.0"}), "dependency 2 not found")
+}
+
+func TestUpdateBump(t *testing.T) {
+	charts := t.TempDir()
+	for _, v := range []string{"1.0.0", "1.0.1", "1.1.0", "2.0.0"} {
+		ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "dep", Version: v}}
+		_, err := chartutil.Save(ch, charts)
+		require.NoError(t, err)
+	}
+
+	srv := repotest.NewTempServer(t, repotest.WithChartSourceGlob(filepath.Join(charts, "*.tgz")))
+	defer srv.Stop()
+	require.NoError(t, srv.LinkIndices())
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	tests := []struct {
+		strategy BumpStrategy
+		expect   string
+	}{
+		{BumpPatch, "~1.0.1"},
+		{BumpMinor, "~1.1.0"},
+		{BumpLatestCompatible, "~2.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			chartpath := dir("parent-" + string(tt.strategy))
+			require.NoError(t, os.MkdirAll(chartpath, 0o755))
+			chartfile := `apiVersion: v2
+name: parent
+version: 0.1.0
+dependencies:
+  - name: dep
+    version: ~1.0.0 # keep
+    repository: ` + srv.URL() + "\n"
+			require.NoError(t, os.WriteFile(filepath.Join(chartpath, "Chart.yaml"), []byte(chartfile), 0o644))
+
+			out := &bytes.Buffer{}
+			m := &Manager{
+				ChartPath:        chartpath,
+				Out:              out,
+				Getters:          getter.Providers{getter.Provider{Schemes: []string{"http", "https"}, New: getter.NewHTTPGetter}},
+				RepositoryConfig: dir("repositories.yaml"),
+				RepositoryCache:  dir(),
+				ContentCache:     t.TempDir(),
+				Bump:             tt.strategy,
+			}
+			require.NoError(t, m.Update())
+			assert.Contains(t, out.String(), `Bumping dep from "~1.0.0" to "`+tt.expect+`"`)
+
+			data, err := os.ReadFile(filepath.Join(chartpath, "Chart.yaml"))
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "    version: "+tt.expect+" # keep\n")
+
+			lock, err := os.ReadFile(filepath.Join(chartpath, "Chart.lock"))
+			require.NoError(t, err)
+			assert.Contains(t, string(lock), "version: "+tt.expect[1:])
+			assert.FileExists(t, filepath.Join(chartpath, "charts", "dep-"+tt.expect[1:]+".tgz"))
+		})
+	}
+
+	m := &Manager{ChartPath: dir(), Bump: "major"}
+	assert.ErrorContains(t, m.Update(), `invalid bump strategy "major"`)
+}
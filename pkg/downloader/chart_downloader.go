@@ -447,11 +447,26 @@ func (c *ChartDownloader) ResolveChartVersion(ref, version string) (string, *url
 	// Next, we need to load the index, and actually look up the chart.
 	idxFile := filepath.Join(c.RepositoryCache, helmpath.CacheIndexFile(r.Config.Name))
 	i, err := repo.LoadIndexFile(idxFile)
+	var cv *repo.ChartVersion
+	if err == nil {
+		cv, err = i.Get(chartName, version)
+	}
+	// The cached index may be missing or predate the chart version, refresh
+	// it before failing.
 	if err != nil {
+		r.CachePath = c.RepositoryCache
+		if _, updated, rerr := r.RefreshIndexFile(); rerr != nil {
+			slog.Debug("failed to refresh repository index", "repo", r.Config.Name, slog.Any("error", rerr))
+		} else if updated {
+			slog.Debug("refreshed stale repository index", "repo", r.Config.Name)
+			if i, err = repo.LoadIndexFile(idxFile); err == nil {
+				cv, err = i.Get(chartName, version)
+			}
+		}
+	}
+	if i == nil {
 		return "", u, fmt.Errorf("no cached repo found. (try 'helm repo update'): %w", err)
 	}
-
-	cv, err := i.Get(chartName, version)
 	if err != nil {
 		return "", u, fmt.Errorf("chart %q matching %s not found in %s index. (try 'helm repo update'): %w", chartName, version, r.Config.Name, err)
 	}
@@ -21,12 +21,16 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"helm.sh/helm/v4/internal/test/ensure"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/cli"
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/helmpath"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/repo/v1"
 	"helm.sh/helm/v4/pkg/repo/v1/repotest"
@@ -158,6 +162,44 @@ func TestResolveChartOpts(t *testing.T) {
 	}
 }
 
+func TestResolveChartVersionRefreshesStaleIndex(t *testing.T) {
+	charts := t.TempDir()
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "dep", Version: v}}
+		_, err := chartutil.Save(ch, charts)
+		require.NoError(t, err)
+	}
+	srv := repotest.NewTempServer(t, repotest.WithChartSourceGlob(filepath.Join(charts, "*.tgz")))
+	defer srv.Stop()
+
+	// The cached index predates the release of 1.1.0.
+	cache := t.TempDir()
+	stale := repo.NewIndexFile()
+	require.NoError(t, stale.MustAdd(&chart.Metadata{APIVersion: chart.APIVersionV2, Name: "dep", Version: "1.0.0"}, "dep-1.0.0.tgz", srv.URL(), ""))
+	idxFile := filepath.Join(cache, helmpath.CacheIndexFile("test"))
+	require.NoError(t, stale.WriteFile(idxFile, 0644))
+	past := time.Now().Add(-24 * time.Hour)
+	require.NoError(t, os.Chtimes(idxFile, past, past))
+
+	rf := repo.NewFile()
+	rf.Add(&repo.Entry{Name: "test", URL: srv.URL()})
+	repositories := filepath.Join(t.TempDir(), "repositories.yaml")
+	require.NoError(t, rf.WriteFile(repositories, 0644))
+
+	c := ChartDownloader{
+		Out:              os.Stderr,
+		RepositoryConfig: repositories,
+		RepositoryCache:  cache,
+		Getters:          getter.All(&cli.EnvSettings{}),
+	}
+	_, u, err := c.ResolveChartVersion("test/dep", "1.1.0")
+	require.NoError(t, err)
+	require.Equal(t, srv.URL()+"/dep-1.1.0.tgz", u.String())
+
+	_, _, err = c.ResolveChartVersion("test/dep", "2.0.0")
+	require.ErrorContains(t, err, `chart "dep" matching 2.0.0 not found in test index`)
+}
+
 func TestVerifyChart(t *testing.T) {
 	v, err := VerifyChart("testdata/signtest-0.1.0.tgz", "testdata/signtest-0.1.0.tgz.prov", "testdata/helm-test-key.pub")
 	if err != nil {
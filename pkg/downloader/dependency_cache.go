@@ -0,0 +1,230 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ifs "helm.sh/helm/v4/internal/third_party/dep/fs"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	"helm.sh/helm/v4/pkg/provenance"
+	"helm.sh/helm/v4/pkg/repo/v1"
+)
+
+// dependencyIndexDir is the directory of the content cache holding the index
+// of the charts prefetched from each repository.
+const dependencyIndexDir = "dependencies"
+
+// Prefetch downloads the dependencies of the chart into the content cache, so
+// that Update and Build can later resolve and fetch them with Offline set, in
+// networks where the repositories cannot be reached.
+//
+// Both the versions of the lock file and the newest versions satisfying the
+// constraints of the dependencies are cached.
+func (m *Manager) Prefetch() error {
+	if m.ContentCache == "" {
+		return errors.New("content cache must be set")
+	}
+	c, err := m.loadChartDir()
+	if err != nil {
+		return err
+	}
+	req := c.Metadata.Dependencies
+	if req == nil {
+		return nil
+	}
+
+	repoNames, err := m.resolveRepoNames(req)
+	if err != nil {
+		return err
+	}
+	repoNames, err = m.ensureMissingRepos(repoNames, req)
+	if err != nil {
+		return err
+	}
+	if !m.SkipUpdate {
+		if err := m.UpdateRepositories(); err != nil {
+			return err
+		}
+	}
+
+	lock, err := m.resolve(req, repoNames)
+	if err != nil {
+		return err
+	}
+	deps := lock.Dependencies
+	if c.Lock != nil {
+		deps = append(deps, c.Lock.Dependencies...)
+	}
+
+	repos, err := m.loadChartRepositories()
+	if err != nil {
+		return err
+	}
+	fetched := map[string]bool{}
+	for _, dep := range deps {
+		// Charts in charts/ and local directories are not cached.
+		if dep.Repository == "" || strings.HasPrefix(dep.Repository, "file://") {
+			continue
+		}
+		k := dep.Repository + "\x00" + dep.Name + "\x00" + dep.Version
+		if fetched[k] {
+			continue
+		}
+		fetched[k] = true
+		if err := m.prefetch(dep, repos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefetch downloads the chart of a dependency into the content cache and adds
+// it to the dependency index of its repository.
+func (m *Manager) prefetch(dep *chart.Dependency, repos map[string]*repo.ChartRepository) error {
+	dl, churl, version, err := m.chartDownloader(dep, repos)
+	if err != nil {
+		return err
+	}
+	pth, _, err := dl.DownloadToCache(churl, version)
+	if err != nil {
+		return fmt.Errorf("could not download %s: %w", churl, err)
+	}
+	digest, err := provenance.DigestFile(pth)
+	if err != nil {
+		return err
+	}
+	ch, err := loader.LoadFile(pth)
+	if err != nil {
+		return fmt.Errorf("could not load %s: %w", churl, err)
+	}
+
+	idx, err := m.loadDependencyIndex(dep.Repository)
+	if err != nil {
+		return err
+	}
+	cv := &repo.ChartVersion{
+		Metadata: ch.Metadata,
+		URLs:     []string{churl},
+		Created:  time.Now(),
+		Digest:   digest,
+	}
+	versions := idx.Entries[ch.Name()]
+	for i, v := range versions {
+		if v.Version == cv.Version {
+			versions = append(versions[:i], versions[i+1:]...)
+			break
+		}
+	}
+	idx.Entries[ch.Name()] = append(versions, cv)
+	idx.SortEntries()
+	idx.Generated = time.Now()
+
+	file := m.dependencyIndexFile(dep.Repository)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := idx.WriteFile(file, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(m.Out, "Cached %s %s from %s\n", ch.Name(), ch.Metadata.Version, dep.Repository)
+	return nil
+}
+
+// copyFromDependencyCache copies the chart of a dependency, and its provenance
+// file when verifying, from the content cache into dest.
+func (m *Manager) copyFromDependencyCache(dep *chart.Dependency, dest string) error {
+	idx, err := m.loadDependencyIndex(dep.Repository)
+	if err != nil {
+		return err
+	}
+	cv, err := findVersionedEntry(dep.Version, idx.Entries[dep.Name])
+	if err != nil {
+		return fmt.Errorf("%s %s from %s is not in the dependency cache (try 'helm dependency prefetch')", dep.Name, dep.Version, dep.Repository)
+	}
+	digest, err := hex.DecodeString(cv.Digest)
+	if err != nil || len(digest) != sha256.Size {
+		return fmt.Errorf("invalid digest %q for %s %s in the dependency cache", cv.Digest, dep.Name, dep.Version)
+	}
+	var key [sha256.Size]byte
+	copy(key[:], digest)
+
+	cache := &DiskCache{Root: m.ContentCache}
+	pth, err := cache.Get(key, CacheChart)
+	if err != nil {
+		return fmt.Errorf("%s %s is missing from the content cache (try 'helm dependency prefetch'): %w", dep.Name, dep.Version, err)
+	}
+	// The content of the cache can be changed outside of Helm.
+	if sum, err := provenance.DigestFile(pth); err != nil {
+		return err
+	} else if sum != cv.Digest {
+		return fmt.Errorf("digest mismatch for %s %s in the content cache", dep.Name, dep.Version)
+	}
+
+	destfile := filepath.Join(dest, fmt.Sprintf("%s-%s.tgz", dep.Name, cv.Version))
+	if err := ifs.CopyFile(pth, destfile); err != nil {
+		return err
+	}
+	dep.Digest = "sha256:" + cv.Digest
+
+	if m.Verify == VerifyNever {
+		return nil
+	}
+	ppth, err := cache.Get(key, CacheProv)
+	if err != nil {
+		if m.Verify == VerifyAlways {
+			return fmt.Errorf("no provenance file for %s %s in the content cache", dep.Name, dep.Version)
+		}
+		fmt.Fprintf(m.Out, "WARNING: Verification not found for %s %s in the content cache\n", dep.Name, dep.Version)
+		return nil
+	}
+	if err := ifs.CopyFile(ppth, destfile+".prov"); err != nil {
+		return err
+	}
+	if m.Verify != VerifyLater {
+		if _, err := VerifyChart(destfile, destfile+".prov", m.Keyring); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadDependencyIndex loads the index of the charts prefetched from the
+// repository. It returns an empty index if there are none.
+func (m *Manager) loadDependencyIndex(repository string) (*repo.IndexFile, error) {
+	idx, err := repo.LoadIndexFile(m.dependencyIndexFile(repository))
+	if errors.Is(err, fs.ErrNotExist) {
+		return repo.NewIndexFile(), nil
+	}
+	return idx, err
+}
+
+// dependencyIndexFile returns the path of the index of the charts prefetched
+// from the repository.
+func (m *Manager) dependencyIndexFile(repository string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(repository, "/")))
+	return filepath.Join(m.ContentCache, dependencyIndexDir, fmt.Sprintf("%x.yaml", sum))
+}
@@ -0,0 +1,128 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/repo/v1/repotest"
+)
+
+func TestPrefetchOffline(t *testing.T) {
+	charts := t.TempDir()
+	for _, v := range []string{"1.0.0", "1.1.0", "2.0.0"} {
+		ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "dep", Version: v}}
+		_, err := chartutil.Save(ch, charts)
+		require.NoError(t, err)
+	}
+
+	srv := repotest.NewTempServer(t, repotest.WithChartSourceGlob(filepath.Join(charts, "*.tgz")))
+	require.NoError(t, srv.LinkIndices())
+	root := srv.Root()
+	url := srv.URL()
+
+	chartpath := filepath.Join(root, "parent")
+	require.NoError(t, os.MkdirAll(chartpath, 0o755))
+	writeChart := func(constraint string) {
+		chartfile := "apiVersion: v2\nname: parent\nversion: 0.1.0\ndependencies:\n  - name: dep\n    version: " +
+			constraint + "\n    repository: " + url + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(chartpath, "Chart.yaml"), []byte(chartfile), 0o644))
+	}
+	contentCache := t.TempDir()
+	manager := func(out *bytes.Buffer, offline bool) *Manager {
+		return &Manager{
+			ChartPath:        chartpath,
+			Out:              out,
+			Getters:          getter.Providers{getter.Provider{Schemes: []string{"http", "https"}, New: getter.NewHTTPGetter}},
+			RepositoryConfig: filepath.Join(root, "repositories.yaml"),
+			RepositoryCache:  root,
+			ContentCache:     contentCache,
+			Offline:          offline,
+		}
+	}
+
+	// Lock 1.0.0, then prefetch both it and the newest version allowed.
+	writeChart("1.0.0")
+	require.NoError(t, manager(&bytes.Buffer{}, false).Update())
+	writeChart("^1.0.0")
+	out := &bytes.Buffer{}
+	require.NoError(t, manager(out, false).Prefetch())
+	assert.Contains(t, out.String(), "Cached dep 1.1.0 from "+url)
+	assert.Contains(t, out.String(), "Cached dep 1.0.0 from "+url)
+	assert.NotContains(t, out.String(), "2.0.0")
+
+	// Offline, nothing comes from the repository.
+	srv.Stop()
+	require.NoError(t, os.Remove(filepath.Join(chartpath, "charts", "dep-1.0.0.tgz")))
+
+	writeChart("1.0.0")
+	out = &bytes.Buffer{}
+	require.NoError(t, manager(out, true).Build())
+	assert.Contains(t, out.String(), "Copying dep from the dependency cache")
+	assert.FileExists(t, filepath.Join(chartpath, "charts", "dep-1.0.0.tgz"))
+
+	writeChart("^1.0.0")
+	require.NoError(t, manager(&bytes.Buffer{}, true).Update())
+	assert.FileExists(t, filepath.Join(chartpath, "charts", "dep-1.1.0.tgz"))
+	assert.NoFileExists(t, filepath.Join(chartpath, "charts", "dep-1.0.0.tgz"))
+	lock, err := os.ReadFile(filepath.Join(chartpath, "Chart.lock"))
+	require.NoError(t, err)
+	assert.Contains(t, string(lock), "version: 1.1.0")
+
+	writeChart("^2.0.0")
+	assert.ErrorContains(t, manager(&bytes.Buffer{}, true).Update(), `can't get a valid version for 1 subchart(s): "dep"`)
+
+	m := manager(&bytes.Buffer{}, true)
+	m.Bump = BumpMinor
+	assert.ErrorContains(t, m.Update(), "cannot be bumped offline")
+}
+
+func TestCopyFromDependencyCacheDigestMismatch(t *testing.T) {
+	dir := t.TempDir()
+	ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "dep", Version: "1.0.0"}}
+	archive, err := chartutil.Save(ch, dir)
+	require.NoError(t, err)
+
+	m := &Manager{ContentCache: t.TempDir(), Out: &bytes.Buffer{}}
+	srcRepo := "https://example.com/charts"
+	idx, err := m.loadDependencyIndex(srcRepo)
+	require.NoError(t, err)
+	require.NoError(t, idx.MustAdd(ch.Metadata, "dep-1.0.0.tgz", srcRepo, "0000000000000000000000000000000000000000000000000000000000000000"))
+	require.NoError(t, os.MkdirAll(filepath.Dir(m.dependencyIndexFile(srcRepo)), 0o755))
+	require.NoError(t, idx.WriteFile(m.dependencyIndexFile(srcRepo), 0o644))
+
+	var key [32]byte
+	data, err := os.ReadFile(archive)
+	require.NoError(t, err)
+	_, err = (&DiskCache{Root: m.ContentCache}).Put(key, bytes.NewReader(data), CacheChart)
+	require.NoError(t, err)
+
+	dep := &chart.Dependency{Name: "dep", Version: "1.0.0", Repository: srcRepo}
+	assert.ErrorContains(t, m.copyFromDependencyCache(dep, dir), "digest mismatch for dep 1.0.0")
+
+	dep.Version = "1.1.0"
+	assert.ErrorContains(t, m.copyFromDependencyCache(dep, dir), "is not in the dependency cache")
+}
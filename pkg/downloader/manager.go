@@ -41,6 +41,7 @@ import (
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/helmpath"
+	"helm.sh/helm/v4/pkg/provenance"
 	"helm.sh/helm/v4/pkg/registry"
 	"helm.sh/helm/v4/pkg/repo/v1"
 )
@@ -78,6 +79,23 @@ type Manager struct {
 
 	// ContentCache is a location where a cache of charts can be stored
 	ContentCache string
+	// Parallelism is the number of dependencies downloaded at the same time.
+	// DefaultParallelism is used if it is not set.
+	Parallelism int
+	// Retries is the number of times a failed download is retried, waiting
+	// longer before each retry.
+	Retries int
+	// Progress is called as the downloads of the dependencies progress.
+	Progress DownloadProgressFunc
+	// Offline makes Update and Build resolve and fetch the dependencies from
+	// the dependency cache populated by Prefetch only, without network access.
+	Offline bool
+	// Overwrite makes Vendor replace the vendored dependencies, even those
+	// with local changes.
+	Overwrite bool
+	// Bump is the strategy Update uses to raise the version constraints of
+	// the dependencies in Chart.yaml before resolving them.
+	Bump BumpStrategy
 }
 
 // Build rebuilds a local charts directory from a lockfile.
@@ -131,6 +149,12 @@ func (m *Manager) Build() error {
 		}
 	}
 
+	// Offline, the charts come from the dependency cache rather than from
+	// the repositories.
+	if m.Offline {
+		return m.downloadAll(lock.Dependencies)
+	}
+
 	// Check that all of the repos we're dependent on actually exist.
 	if err := m.hasAllRepos(lock.Dependencies); err != nil {
 		return err
@@ -152,7 +176,22 @@ func (m *Manager) Build() error {
 // It first reads the Chart.yaml file, and then attempts to
 // negotiate versions based on that. It will download the versions
 // from remote chart repositories unless SkipUpdate is true.
+//
+// If Bump is set, the version constraints in Chart.yaml are first raised to
+// the newest versions allowed by the strategy.
+//
+// If Offline is set, the dependencies are resolved and fetched from the
+// dependency cache populated by Prefetch only.
 func (m *Manager) Update() error {
+	if m.Bump != BumpNone {
+		if err := m.Bump.validate(); err != nil {
+			return err
+		}
+		if m.Offline {
+			return errors.New("dependencies cannot be bumped offline")
+		}
+	}
+
 	c, err := m.loadChartDir()
 	if err != nil {
 		return err
@@ -179,19 +218,27 @@ func (m *Manager) Update() error {
 	// rather than automatic. In Helm v4 require users to add repositories. They
 	// should have to add them in order to make sure they are aware of the
 	// repositories and opt-in to any locations, for security.
-	repoNames, err = m.ensureMissingRepos(repoNames, req)
-	if err != nil {
-		return err
+	if !m.Offline {
+		repoNames, err = m.ensureMissingRepos(repoNames, req)
+		if err != nil {
+			return err
+		}
 	}
 
 	// For each of the repositories Helm is configured to know about, update
 	// the index information locally.
-	if !m.SkipUpdate {
+	if !m.SkipUpdate && !m.Offline {
 		if err := m.UpdateRepositories(); err != nil {
 			return err
 		}
 	}
 
+	if m.Bump != BumpNone {
+		if err := m.bump(c, repoNames); err != nil {
+			return err
+		}
+	}
+
 	// Now we need to find out which version of a chart best satisfies the
 	// dependencies in the Chart.yaml
 	lock, err := m.resolve(req, repoNames)
@@ -234,7 +281,10 @@ func (m *Manager) loadChartDir() (*chart.Chart, error) {
 //
 // This returns a lock file, which has all of the dependencies normalized to a specific version.
 func (m *Manager) resolve(req []*chart.Dependency, repoNames map[string]string) (*chart.Lock, error) {
-	res := resolver.New(m.ChartPath, m.RepositoryCache, m.RegistryClient)
+	res := resolver.New(m.ChartPath, m.RepositoryCache, m.RegistryClient).WithRefresh(m.refreshRepo)
+	if m.Offline {
+		res = resolver.NewWithIndex(m.ChartPath, m.loadDependencyIndex)
+	}
 	return res.Resolve(req, repoNames)
 }
 
@@ -243,9 +293,12 @@ func (m *Manager) resolve(req []*chart.Dependency, repoNames map[string]string)
 // It will delete versions of the chart that exist on disk and might cause
 // a conflict.
 func (m *Manager) downloadAll(deps []*chart.Dependency) error {
-	repos, err := m.loadChartRepositories()
-	if err != nil {
-		return err
+	var repos map[string]*repo.ChartRepository
+	if !m.Offline {
+		var err error
+		if repos, err = m.loadChartRepositories(); err != nil {
+			return err
+		}
 	}
 
 	destPath := filepath.Join(m.ChartPath, "charts")
@@ -270,10 +323,30 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 	}
 	defer os.RemoveAll(tmpPath)
 
+	vendored, err := LoadVendorManifest(m.ChartPath)
+	if err != nil {
+		return err
+	}
+
+	// The digests of the lock file are checked once the charts are saved
+	locked := make(map[*chart.Dependency]string, len(deps))
+	for _, dep := range deps {
+		locked[dep] = dep.Digest
+	}
+
 	fmt.Fprintf(m.Out, "Saving %d charts\n", len(deps))
 	var saveError error
-	churls := make(map[string]struct{})
+	churls := make(map[string]*chartDownload)
+	var downloads []*chartDownload
+	duplicates := make(map[*chart.Dependency]*chartDownload)
 	for _, dep := range deps {
+		// Vendored dependencies are kept as they are, local changes included
+		if dep.Repository != "" && !m.Overwrite && isVendored(vendored, destPath, dep.Name, dep.Version) {
+			fmt.Fprintf(m.Out, "Dependency %s is vendored in charts/%s\n", dep.Name, dep.Name)
+			dep.Digest = vendored.Get(dep.Name).Digest
+			continue
+		}
+
 		// No repository means the chart is in charts directory
 		if dep.Repository == "" {
 			fmt.Fprintf(m.Out, "Dependency %s did not declare a repository. Assuming it exists in the charts directory\n", dep.Name)
@@ -298,6 +371,10 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 				saveError = fmt.Errorf("dependency %s at version %s does not satisfy the constraint %s", dep.Name, ch.Metadata.Version, dep.Version)
 				break
 			}
+			if dep.Digest, err = filesDigest(chartPath); err != nil {
+				saveError = err
+				break
+			}
 			continue
 		}
 		if strings.HasPrefix(dep.Repository, "file://") {
@@ -310,58 +387,57 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 				break
 			}
 			dep.Version = ver
+			// Archives are not reproducible, the files of the chart are digested instead
+			origPath, err := resolver.GetLocalPath(dep.Repository, m.ChartPath)
+			if err == nil {
+				dep.Digest, err = filesDigest(origPath)
+			}
+			if err != nil {
+				saveError = err
+				break
+			}
+			continue
+		}
+
+		// Offline, the charts only come from the dependency cache
+		if m.Offline {
+			fmt.Fprintf(m.Out, "Copying %s from the dependency cache\n", dep.Name)
+			if err := m.copyFromDependencyCache(dep, tmpPath); err != nil {
+				saveError = err
+				break
+			}
 			continue
 		}
 
 		// Any failure to resolve/download a chart should fail:
 		// https://github.com/helm/helm/issues/1439
-		churl, username, password, insecureskiptlsverify, passcredentialsall, caFile, certFile, keyFile, err := m.findChartURL(dep.Name, dep.Version, dep.Repository, repos)
+		dl, churl, version, err := m.chartDownloader(dep, repos)
 		if err != nil {
-			saveError = fmt.Errorf("could not find %s: %w", churl, err)
+			saveError = err
 			break
 		}
 
-		if _, ok := churls[churl]; ok {
+		if d, ok := churls[churl]; ok {
 			fmt.Fprintf(m.Out, "Already downloaded %s from repo %s\n", dep.Name, dep.Repository)
+			duplicates[dep] = d
 			continue
 		}
 
 		fmt.Fprintf(m.Out, "Downloading %s from repo %s\n", dep.Name, dep.Repository)
+		d := &chartDownload{dep: dep, dl: dl, url: churl, version: version}
+		downloads = append(downloads, d)
+		churls[churl] = d
+	}
 
-		dl := ChartDownloader{
-			Out:              m.Out,
-			Verify:           m.Verify,
-			Keyring:          m.Keyring,
-			RepositoryConfig: m.RepositoryConfig,
-			RepositoryCache:  m.RepositoryCache,
-			ContentCache:     m.ContentCache,
-			RegistryClient:   m.RegistryClient,
-			Getters:          m.Getters,
-			Options: []getter.Option{
-				getter.WithBasicAuth(username, password),
-				getter.WithPassCredentialsAll(passcredentialsall),
-				getter.WithInsecureSkipVerifyTLS(insecureskiptlsverify),
-				getter.WithTLSClientConfig(certFile, keyFile, caFile),
-			},
-		}
-
-		version := ""
-		if registry.IsOCI(churl) {
-			churl, version, err = parseOCIRef(churl)
-			if err != nil {
-				return fmt.Errorf("could not parse OCI reference: %w", err)
-			}
-			dl.Options = append(dl.Options,
-				getter.WithRegistryClient(m.RegistryClient),
-				getter.WithTagName(version))
-		}
-
-		if _, _, err = dl.DownloadTo(churl, version, tmpPath); err != nil {
-			saveError = fmt.Errorf("could not download %s: %w", churl, err)
-			break
-		}
-
-		churls[churl] = struct{}{}
+	// The charts from repositories and registries are downloaded in parallel
+	if saveError == nil {
+		saveError = m.downloadParallel(downloads, tmpPath)
+	}
+	if saveError == nil {
+		saveError = archiveDigests(downloads, duplicates)
+	}
+	if saveError == nil {
+		saveError = m.checkLockDigests(deps, locked)
 	}
 
 	// TODO: this should probably be refactored to be a []error, so we can capture and provide more information rather than "last error wins".
@@ -377,6 +453,82 @@ func (m *Manager) downloadAll(deps []*chart.Dependency) error {
 	return nil
 }
 
+// archiveDigests sets the digests of the dependencies downloaded from
+// repositories and registries to those of their chart archives.
+func archiveDigests(downloads []*chartDownload, duplicates map[*chart.Dependency]*chartDownload) error {
+	for _, d := range downloads {
+		digest, err := provenance.DigestFile(d.path)
+		if err != nil {
+			return err
+		}
+		d.dep.Digest = "sha256:" + digest
+	}
+	for dep, d := range duplicates {
+		dep.Digest = d.dep.Digest
+	}
+	return nil
+}
+
+// checkLockDigests compares the digests of the saved dependencies with those
+// recorded in the lock file. Charts from repositories and registries must
+// match, while local charts are expected to change and only warn.
+func (m *Manager) checkLockDigests(deps []*chart.Dependency, locked map[*chart.Dependency]string) error {
+	for _, dep := range deps {
+		expected := locked[dep]
+		if expected == "" || dep.Digest == "" || expected == dep.Digest {
+			continue
+		}
+		if dep.Repository == "" || strings.HasPrefix(dep.Repository, "file://") {
+			fmt.Fprintf(m.Out, "WARNING: %s has changed since the lock file was generated\n", dep.Name)
+			continue
+		}
+		return fmt.Errorf("digest mismatch for %s %s: the lock file has %s, got %s", dep.Name, dep.Version, expected, dep.Digest)
+	}
+	return nil
+}
+
+// chartDownloader returns a downloader for the chart of a dependency, along
+// with the URL and version of the chart to download.
+func (m *Manager) chartDownloader(dep *chart.Dependency, repos map[string]*repo.ChartRepository) (*ChartDownloader, string, string, error) {
+	churl, username, password, insecureskiptlsverify, passcredentialsall, caFile, certFile, keyFile, err := m.findChartURL(dep.Name, dep.Version, dep.Repository, repos)
+	// The cached index may predate the locked version, refresh it and retry
+	if err != nil && m.refreshCachedRepo(dep.Repository, repos) {
+		churl, username, password, insecureskiptlsverify, passcredentialsall, caFile, certFile, keyFile, err = m.findChartURL(dep.Name, dep.Version, dep.Repository, repos)
+	}
+	if err != nil {
+		return nil, churl, "", fmt.Errorf("could not find %s: %w", churl, err)
+	}
+
+	dl := &ChartDownloader{
+		Out:              m.Out,
+		Verify:           m.Verify,
+		Keyring:          m.Keyring,
+		RepositoryConfig: m.RepositoryConfig,
+		RepositoryCache:  m.RepositoryCache,
+		ContentCache:     m.ContentCache,
+		RegistryClient:   m.RegistryClient,
+		Getters:          m.Getters,
+		Options: []getter.Option{
+			getter.WithBasicAuth(username, password),
+			getter.WithPassCredentialsAll(passcredentialsall),
+			getter.WithInsecureSkipVerifyTLS(insecureskiptlsverify),
+			getter.WithTLSClientConfig(certFile, keyFile, caFile),
+		},
+	}
+
+	version := ""
+	if registry.IsOCI(churl) {
+		churl, version, err = parseOCIRef(churl)
+		if err != nil {
+			return nil, churl, "", fmt.Errorf("could not parse OCI reference: %w", err)
+		}
+		dl.Options = append(dl.Options,
+			getter.WithRegistryClient(m.RegistryClient),
+			getter.WithTagName(version))
+	}
+	return dl, churl, version, nil
+}
+
 func parseOCIRef(chartRef string) (string, string, error) {
 	refTagRegexp := regexp.MustCompile(`^(oci://[^:]+(:[0-9]{1,5})?[^:]+):(.*)$`)
 	caps := refTagRegexp.FindStringSubmatch(chartRef)
@@ -441,7 +593,7 @@ func (m *Manager) safeMoveDeps(deps []*chart.Dependency, source, dest string) er
 	fmt.Fprintln(m.Out, "Deleting outdated charts")
 	// find all files that exist in dest that do not exist in source; delete them (outdated dependencies)
 	for _, file := range destFiles {
-		if !file.IsDir() && !existsInSourceDirectory[file.Name()] {
+		if !file.IsDir() && !existsInSourceDirectory[file.Name()] && file.Name() != VendorManifestFile {
 			fname := filepath.Join(dest, file.Name())
 			ch, err := loader.LoadFile(fname)
 			if err != nil {
@@ -717,6 +869,55 @@ func (m *Manager) parallelRepoUpdate(repos []*repo.Entry) error {
 	return nil
 }
 
+// refreshRepo refreshes the cached index of a repository, with a conditional
+// request so that an up to date index is not downloaded again. It reports
+// whether the index changed.
+func (m *Manager) refreshRepo(repoName, repoURL string) (bool, error) {
+	entry := &repo.Entry{Name: repoName, URL: repoURL}
+	if rf, err := loadRepoConfig(m.RepositoryConfig); err == nil {
+		if e := rf.Get(repoName); e != nil {
+			entry = e
+		}
+	}
+	r, err := repo.NewChartRepository(entry, m.Getters)
+	if err != nil {
+		return false, err
+	}
+	r.CachePath = m.RepositoryCache
+	_, updated, err := r.RefreshIndexFile()
+	if err != nil {
+		fmt.Fprintf(m.Out, "...Unable to refresh the index of the %q chart repository: %s\n", repoURL, err)
+		return false, err
+	}
+	if updated {
+		fmt.Fprintf(m.Out, "...Refreshed the stale index of the %q chart repository\n", repoURL)
+	}
+	return updated, nil
+}
+
+// refreshCachedRepo refreshes the index of the cached repository with the
+// given URL, and reloads it into repos. It reports whether the index changed.
+func (m *Manager) refreshCachedRepo(repoURL string, repos map[string]*repo.ChartRepository) bool {
+	if registry.IsOCI(repoURL) {
+		return false
+	}
+	for name, cr := range repos {
+		if !urlutil.Equal(repoURL, cr.Config.URL) {
+			continue
+		}
+		if updated, err := m.refreshRepo(name, repoURL); err != nil || !updated {
+			return false
+		}
+		index, err := repo.LoadIndexFile(filepath.Join(m.RepositoryCache, helmpath.CacheIndexFile(name)))
+		if err != nil {
+			return false
+		}
+		cr.IndexFile = index
+		return true
+	}
+	return false
+}
+
 // findChartURL searches the cache of repo data for a chart that has the name and the repoURL specified.
 //
 // 'name' is the name of the chart. Version is an exact semver, or an empty string. If empty, the
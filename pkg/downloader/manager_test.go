@@ -22,16 +22,19 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/yaml"
 
+	"helm.sh/helm/v4/internal/resolver"
 	chart "helm.sh/helm/v4/pkg/chart/v2"
 	"helm.sh/helm/v4/pkg/chart/v2/loader"
 	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
 	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/provenance"
 	"helm.sh/helm/v4/pkg/repo/v1"
 	"helm.sh/helm/v4/pkg/repo/v1/repotest"
 )
@@ -368,6 +371,101 @@ func TestUpdateBeforeBuild(t *testing.T) {
 	}
 }
 
+func TestLockDigests(t *testing.T) {
+	srv := repotest.NewTempServer(
+		t,
+		repotest.WithChartSourceGlob("testdata/*.tgz*"),
+	)
+	defer srv.Stop()
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+	dir := func(p ...string) string {
+		return filepath.Join(append([]string{srv.Root()}, p...)...)
+	}
+
+	dep := func(name string) *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{Name: name, Version: "0.1.0", APIVersion: "v2"},
+		}
+	}
+	if err := chartutil.SaveDir(dep("dep-chart"), dir()); err != nil {
+		t.Fatal(err)
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "with-mixed-dependencies",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+			Dependencies: []*chart.Dependency{
+				{Name: "local-subchart", Version: "0.1.0", Repository: srv.URL()},
+				{Name: "dep-chart", Version: ">=0.1.0", Repository: "file://../dep-chart"},
+				{Name: "in-charts", Version: "0.1.0"},
+			},
+		},
+	}
+	if err := chartutil.SaveDir(c, dir()); err != nil {
+		t.Fatal(err)
+	}
+	if err := chartutil.SaveDir(dep("in-charts"), dir(c.Metadata.Name, "charts")); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{
+		ChartPath: dir(c.Metadata.Name),
+		Out:       new(bytes.Buffer),
+		Getters: getter.Providers{getter.Provider{
+			Schemes: []string{"http", "https"},
+			New:     getter.NewHTTPGetter,
+		}},
+		RepositoryConfig: dir("repositories.yaml"),
+		RepositoryCache:  dir(),
+		ContentCache:     t.TempDir(),
+	}
+	loadLock := func() *chart.Lock {
+		t.Helper()
+		ch, err := loader.LoadDir(m.ChartPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ch.Lock
+	}
+
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	lock := loadLock()
+	for _, d := range lock.Dependencies {
+		assert.Regexp(t, "^sha256:[0-9a-f]{64}$", d.Digest, d.Name)
+	}
+	archive, err := provenance.DigestFile("testdata/local-subchart-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "sha256:"+archive, lock.Dependencies[0].Digest)
+
+	// Archiving the file:// dependency again does not change the lock file
+	if err := m.Update(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, lock.Digest, loadLock().Digest)
+	if err := m.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A chart that does not match the lock file is rejected
+	lock.Dependencies[0].Digest = "sha256:" + strings.Repeat("0", 64)
+	req := c.Metadata.Dependencies
+	if lock.Digest, err = resolver.HashReq(req, lock.Dependencies); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeLock(m.ChartPath, lock, false); err != nil {
+		t.Fatal(err)
+	}
+	err = m.Build()
+	assert.ErrorContains(t, err, "digest mismatch for local-subchart 0.1.0")
+}
+
 // TestUpdateWithNoRepo is for the case of a dependency that has no repo listed.
 // This happens when the dependency is in the charts directory and does not need
 // to be fetched.
@@ -0,0 +1,183 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+)
+
+// DefaultParallelism is the number of dependencies the Manager downloads at
+// the same time when Parallelism is not set.
+const DefaultParallelism = 4
+
+// downloadRetryBackoff is the delay before the first retry of a failed
+// download. It doubles with each retry.
+var downloadRetryBackoff = time.Second
+
+// DownloadPhase is the phase of the download of a dependency.
+type DownloadPhase string
+
+const (
+	// DownloadStarted is reported when the download of a dependency starts.
+	DownloadStarted DownloadPhase = "started"
+	// DownloadRetrying is reported when an attempt failed and is retried.
+	DownloadRetrying DownloadPhase = "retrying"
+	// DownloadDone is reported when a dependency was downloaded.
+	DownloadDone DownloadPhase = "done"
+	// DownloadFailed is reported when all the attempts failed.
+	DownloadFailed DownloadPhase = "failed"
+)
+
+// DownloadProgress reports the progress of the download of a dependency.
+type DownloadProgress struct {
+	Name       string
+	Version    string
+	Repository string
+	Phase      DownloadPhase
+	// Attempt is the number of the current attempt, starting at 1.
+	Attempt int
+	// Err is the error of the last attempt when retrying or failed.
+	Err error
+	// Done is the number of finished downloads, and Total the number of
+	// downloads.
+	Done  int
+	Total int
+}
+
+// DownloadProgressFunc is called as the downloads of the dependencies
+// progress. The calls are serialized.
+type DownloadProgressFunc func(DownloadProgress)
+
+// chartDownload is the download of the chart of a dependency.
+type chartDownload struct {
+	dep     *chart.Dependency
+	dl      *ChartDownloader
+	url     string
+	version string
+	// path is where the chart archive was saved
+	path string
+}
+
+// downloadParallel downloads the charts into dest, running up to Parallelism
+// downloads at the same time and retrying failed ones up to Retries times.
+//
+// It returns the first error. The downloads which did not start yet are then
+// skipped.
+func (m *Manager) downloadParallel(downloads []*chartDownload, dest string) error {
+	parallelism := m.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+	out := &lockedWriter{w: m.Out}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		firstErr error
+	)
+	// report must be called with mu held.
+	report := func(d *chartDownload, phase DownloadPhase, attempt int, err error) {
+		if m.Progress == nil {
+			return
+		}
+		m.Progress(DownloadProgress{
+			Name:       d.dep.Name,
+			Version:    d.dep.Version,
+			Repository: d.dep.Repository,
+			Phase:      phase,
+			Attempt:    attempt,
+			Err:        err,
+			Done:       done,
+			Total:      len(downloads),
+		})
+	}
+
+	sem := make(chan struct{}, parallelism)
+	for _, d := range downloads {
+		sem <- struct{}{}
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+
+		d.dl.Out = out
+		wg.Add(1)
+		go func(d *chartDownload) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			backoff := downloadRetryBackoff
+			for attempt := 1; ; attempt++ {
+				if attempt == 1 {
+					mu.Lock()
+					report(d, DownloadStarted, attempt, nil)
+					mu.Unlock()
+				}
+
+				path, _, err := d.dl.DownloadTo(d.url, d.version, dest)
+
+				mu.Lock()
+				switch {
+				case err == nil:
+					d.path = path
+					done++
+					report(d, DownloadDone, attempt, nil)
+				case attempt > m.Retries:
+					done++
+					report(d, DownloadFailed, attempt, err)
+					if firstErr == nil {
+						firstErr = fmt.Errorf("could not download %s: %w", d.url, err)
+					}
+				default:
+					fmt.Fprintf(out, "Retrying the download of %s in %s: %s\n", d.dep.Name, backoff, err)
+					report(d, DownloadRetrying, attempt+1, err)
+				}
+				mu.Unlock()
+				if err == nil || attempt > m.Retries {
+					return
+				}
+
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+		}(d)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// lockedWriter serializes the writes to a writer shared by goroutines.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// flakyGetter serves chart archives and fails the first attempts to get the
+// URLs listed in failures.
+type flakyGetter struct {
+	archives map[string][]byte
+	mu       sync.Mutex
+	failures map[string]int
+
+	running    atomic.Int32
+	maxRunning atomic.Int32
+}
+
+func (g *flakyGetter) Get(url string, _ ...getter.Option) (*bytes.Buffer, error) {
+	running := g.running.Add(1)
+	defer g.running.Add(-1)
+	for {
+		maxRunning := g.maxRunning.Load()
+		if running <= maxRunning || g.maxRunning.CompareAndSwap(maxRunning, running) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.failures[url] > 0 {
+		g.failures[url]--
+		return nil, errors.New("connection reset by peer")
+	}
+	return bytes.NewBuffer(g.archives[url]), nil
+}
+
+func testDownloads(t *testing.T, g *flakyGetter, names ...string) []*chartDownload {
+	t.Helper()
+	dir := t.TempDir()
+	g.archives = map[string][]byte{}
+	var downloads []*chartDownload
+	for _, name := range names {
+		ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: "1.0.0"}}
+		archive, err := chartutil.Save(ch, dir)
+		require.NoError(t, err)
+		data, err := os.ReadFile(archive)
+		require.NoError(t, err)
+		url := "test://charts.example.com/" + filepath.Base(archive)
+		g.archives[url] = data
+
+		downloads = append(downloads, &chartDownload{
+			dep: &chart.Dependency{Name: name, Version: "1.0.0", Repository: "test://charts.example.com"},
+			dl: &ChartDownloader{
+				Getters: getter.Providers{{
+					Schemes: []string{"test"},
+					New:     func(...getter.Option) (getter.Getter, error) { return g, nil },
+				}},
+				RepositoryConfig: filepath.Join(dir, "repositories.yaml"),
+				ContentCache:     t.TempDir(),
+			},
+			url: url,
+		})
+	}
+	return downloads
+}
+
+func TestDownloadParallel(t *testing.T) {
+	backoff := downloadRetryBackoff
+	downloadRetryBackoff = time.Millisecond
+	defer func() { downloadRetryBackoff = backoff }()
+
+	g := &flakyGetter{failures: map[string]int{"test://charts.example.com/b-1.0.0.tgz": 2}}
+	downloads := testDownloads(t, g, "a", "b", "c", "d")
+
+	var events []DownloadProgress
+	out := &bytes.Buffer{}
+	m := &Manager{
+		Out:         out,
+		Parallelism: 2,
+		Retries:     2,
+		Progress:    func(p DownloadProgress) { events = append(events, p) },
+	}
+	dest := t.TempDir()
+	require.NoError(t, m.downloadParallel(downloads, dest))
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.FileExists(t, filepath.Join(dest, name+"-1.0.0.tgz"))
+	}
+	assert.LessOrEqual(t, g.maxRunning.Load(), int32(2))
+	assert.Contains(t, out.String(), "Retrying the download of b in 1ms: connection reset by peer")
+	assert.Contains(t, out.String(), "Retrying the download of b in 2ms: connection reset by peer")
+
+	phases := map[DownloadPhase]int{}
+	for _, e := range events {
+		phases[e.Phase]++
+		assert.Equal(t, 4, e.Total)
+	}
+	assert.Equal(t, map[DownloadPhase]int{DownloadStarted: 4, DownloadRetrying: 2, DownloadDone: 4}, phases)
+	last := events[len(events)-1]
+	assert.Equal(t, DownloadDone, last.Phase)
+	assert.Equal(t, 4, last.Done)
+}
+
+func TestDownloadParallelFailure(t *testing.T) {
+	backoff := downloadRetryBackoff
+	downloadRetryBackoff = time.Millisecond
+	defer func() { downloadRetryBackoff = backoff }()
+
+	g := &flakyGetter{failures: map[string]int{"test://charts.example.com/a-1.0.0.tgz": 2}}
+	downloads := testDownloads(t, g, "a")
+
+	var failed *DownloadProgress
+	m := &Manager{
+		Out:     &bytes.Buffer{},
+		Retries: 1,
+		Progress: func(p DownloadProgress) {
+			if p.Phase == DownloadFailed {
+				failed = &p
+			}
+		},
+	}
+	err := m.downloadParallel(downloads, t.TempDir())
+	assert.ErrorContains(t, err, "could not download test://charts.example.com/a-1.0.0.tgz: connection reset by peer")
+	require.NotNil(t, failed)
+	assert.Equal(t, 2, failed.Attempt)
+	assert.Equal(t, "a", failed.Name)
+}
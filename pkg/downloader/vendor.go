@@ -0,0 +1,286 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/v2/loader"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/provenance"
+)
+
+// VendorManifestFile is the name of the manifest of the vendored dependencies
+// in the charts/ directory. It starts with a dot so the chart loader ignores
+// it.
+const VendorManifestFile = ".vendor.yaml"
+
+// VendorManifest records where the dependencies unpacked in charts/ by
+// Manager.Vendor come from.
+type VendorManifest struct {
+	// Generated is the time the manifest was written.
+	Generated time.Time `json:"generated"`
+	// Dependencies are the vendored dependencies, sorted by name.
+	Dependencies []*VendoredDependency `json:"dependencies"`
+}
+
+// VendoredDependency is a dependency unpacked in charts/<name>/.
+type VendoredDependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository"`
+	// Digest is the digest of the chart archive the dependency was unpacked
+	// from.
+	Digest string `json:"digest"`
+	// Files is the digest of the unpacked files, to detect local changes.
+	Files string `json:"files"`
+}
+
+// LoadVendorManifest reads the vendor manifest of the chart directory. It
+// returns an empty manifest if there is none.
+func LoadVendorManifest(chartpath string) (*VendorManifest, error) {
+	data, err := os.ReadFile(filepath.Join(chartpath, "charts", VendorManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &VendorManifest{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	manifest := &VendorManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("cannot load %s: %w", VendorManifestFile, err)
+	}
+	return manifest, nil
+}
+
+// Get returns the vendored dependency with the given name, or nil.
+func (v *VendorManifest) Get(name string) *VendoredDependency {
+	for _, d := range v.Dependencies {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// Modified reports whether the files of the vendored dependency in the chart
+// directory differ from the ones it was vendored with.
+func (d *VendoredDependency) Modified(chartpath string) (bool, error) {
+	digest, err := filesDigest(filepath.Join(chartpath, "charts", d.Name))
+	if err != nil {
+		return false, err
+	}
+	return digest != d.Files, nil
+}
+
+// Vendor unpacks the dependencies of the chart into charts/<name>/ directories
+// which can be edited, instead of keeping them as archives, and records their
+// origin in the vendor manifest.
+//
+// The dependencies are first fetched like Build does. Vendored dependencies at
+// the locked version are left untouched, and ones with local changes are only
+// replaced if Overwrite is set.
+func (m *Manager) Vendor() error {
+	if err := m.Build(); err != nil {
+		return err
+	}
+
+	c, err := m.loadChartDir()
+	if err != nil {
+		return err
+	}
+	chartsDir := filepath.Join(m.ChartPath, "charts")
+	old, err := LoadVendorManifest(m.ChartPath)
+	if err != nil {
+		return err
+	}
+	archives, err := chartArchives(chartsDir)
+	if err != nil {
+		return err
+	}
+
+	manifest := &VendorManifest{}
+	vendored := map[string]bool{}
+	if c.Lock != nil {
+		for _, dep := range c.Lock.Dependencies {
+			// Dependencies without a repository already live in charts/.
+			if dep.Repository == "" {
+				continue
+			}
+			vendored[dep.Name] = true
+
+			archive, ok := archives[dep.Name]
+			if !ok {
+				// Build does not fetch dependencies already vendored at the
+				// locked version.
+				if prev := old.Get(dep.Name); prev != nil && prev.Version == dep.Version {
+					manifest.Dependencies = append(manifest.Dependencies, prev)
+					continue
+				}
+				return fmt.Errorf("no archive found in charts/ for dependency %s", dep.Name)
+			}
+
+			dir := filepath.Join(chartsDir, dep.Name)
+			if err := m.checkVendorDir(dir, old.Get(dep.Name)); err != nil {
+				return err
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+			if err := chartutil.ExpandFile(chartsDir, archive); err != nil {
+				return fmt.Errorf("cannot unpack %s: %w", archive, err)
+			}
+
+			digest, err := provenance.DigestFile(archive)
+			if err != nil {
+				return err
+			}
+			files, err := filesDigest(dir)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(archive); err != nil {
+				return err
+			}
+			manifest.Dependencies = append(manifest.Dependencies, &VendoredDependency{
+				Name:       dep.Name,
+				Version:    dep.Version,
+				Repository: dep.Repository,
+				Digest:     "sha256:" + digest,
+				Files:      files,
+			})
+			fmt.Fprintf(m.Out, "Vendored %s %s into charts/%s\n", dep.Name, dep.Version, dep.Name)
+		}
+	}
+
+	// Remove the directories of the dependencies no longer vendored, unless
+	// they were changed.
+	for _, prev := range old.Dependencies {
+		if vendored[prev.Name] {
+			continue
+		}
+		if modified, err := prev.Modified(m.ChartPath); err == nil && modified {
+			fmt.Fprintf(m.Out, "Keeping charts/%s which is no longer a dependency but has local changes\n", prev.Name)
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(chartsDir, prev.Name)); err != nil {
+			return err
+		}
+		fmt.Fprintf(m.Out, "Removed charts/%s which is no longer a dependency\n", prev.Name)
+	}
+
+	sort.Slice(manifest.Dependencies, func(i, j int) bool {
+		return manifest.Dependencies[i].Name < manifest.Dependencies[j].Name
+	})
+	manifest.Generated = time.Now()
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(chartsDir, VendorManifestFile), data, 0644)
+}
+
+// checkVendorDir fails if the directory of a dependency about to be vendored
+// has local changes, or was not vendored, unless Overwrite is set.
+func (m *Manager) checkVendorDir(dir string, prev *VendoredDependency) error {
+	if m.Overwrite {
+		return nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if prev == nil {
+		return fmt.Errorf("charts/%s already exists and was not vendored; remove it or overwrite it", filepath.Base(dir))
+	}
+	if modified, err := prev.Modified(m.ChartPath); err != nil {
+		return err
+	} else if modified {
+		return fmt.Errorf("charts/%s has local changes; revert them or overwrite them", prev.Name)
+	}
+	return nil
+}
+
+// isVendored reports whether the dependency is vendored at the given version
+// and does not need to be fetched.
+func isVendored(manifest *VendorManifest, chartsDir, name, version string) bool {
+	if manifest == nil {
+		return false
+	}
+	d := manifest.Get(name)
+	if d == nil || d.Version != version {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(chartsDir, name))
+	return err == nil && fi.IsDir()
+}
+
+// chartArchives returns the chart archives of the directory by chart name.
+func chartArchives(dir string) (map[string]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	if err != nil {
+		return nil, err
+	}
+	archives := map[string]string{}
+	for _, archive := range matches {
+		ch, err := loader.LoadFile(archive)
+		if err != nil {
+			continue
+		}
+		archives[ch.Name()] = archive
+	}
+	return archives, nil
+}
+
+// filesDigest returns a digest of the paths and contents of the files of a
+// directory.
+func filesDigest(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fh := sha256.New()
+		if _, err := io.Copy(fh, f); err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%x\n", filepath.ToSlash(rel), fh.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	chartutil "helm.sh/helm/v4/pkg/chart/v2/util"
+	"helm.sh/helm/v4/pkg/getter"
+	"helm.sh/helm/v4/pkg/repo/v1/repotest"
+)
+
+func TestVendor(t *testing.T) {
+	charts := t.TempDir()
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "dep", Version: v}}
+		_, err := chartutil.Save(ch, charts)
+		require.NoError(t, err)
+	}
+
+	srv := repotest.NewTempServer(t, repotest.WithChartSourceGlob(filepath.Join(charts, "*.tgz")))
+	defer srv.Stop()
+	require.NoError(t, srv.LinkIndices())
+
+	chartpath := filepath.Join(srv.Root(), "parent")
+	require.NoError(t, os.MkdirAll(chartpath, 0o755))
+	writeChart := func(constraint string) {
+		chartfile := "apiVersion: v2\nname: parent\nversion: 0.1.0\ndependencies:\n  - name: dep\n    version: " +
+			constraint + "\n    repository: " + srv.URL() + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(chartpath, "Chart.yaml"), []byte(chartfile), 0o644))
+		require.NoError(t, os.RemoveAll(filepath.Join(chartpath, "Chart.lock")))
+	}
+	vendor := func(overwrite bool) (string, error) {
+		out := &bytes.Buffer{}
+		m := &Manager{
+			ChartPath:        chartpath,
+			Out:              out,
+			Getters:          getter.Providers{getter.Provider{Schemes: []string{"http", "https"}, New: getter.NewHTTPGetter}},
+			RepositoryConfig: filepath.Join(srv.Root(), "repositories.yaml"),
+			RepositoryCache:  srv.Root(),
+			ContentCache:     t.TempDir(),
+			Overwrite:        overwrite,
+		}
+		err := m.Vendor()
+		return out.String(), err
+	}
+	depDir := filepath.Join(chartpath, "charts", "dep")
+	patch := filepath.Join(depDir, "values.yaml")
+
+	writeChart("~1.0.0")
+	out, err := vendor(false)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Vendored dep 1.0.0 into charts/dep")
+	assert.FileExists(t, filepath.Join(depDir, "Chart.yaml"))
+	assert.NoFileExists(t, filepath.Join(chartpath, "charts", "dep-1.0.0.tgz"))
+
+	manifest, err := LoadVendorManifest(chartpath)
+	require.NoError(t, err)
+	require.Len(t, manifest.Dependencies, 1)
+	vendored := manifest.Dependencies[0]
+	assert.Equal(t, "1.0.0", vendored.Version)
+	assert.Equal(t, srv.URL(), vendored.Repository)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", vendored.Digest)
+	modified, err := vendored.Modified(chartpath)
+	require.NoError(t, err)
+	assert.False(t, modified)
+
+	// Local changes are kept as long as the version does not change.
+	require.NoError(t, os.WriteFile(patch, []byte("patched: true\n"), 0o644))
+	out, err = vendor(false)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Dependency dep is vendored in charts/dep")
+	assert.FileExists(t, patch)
+	modified, err = vendored.Modified(chartpath)
+	require.NoError(t, err)
+	assert.True(t, modified)
+
+	// Moving to another version does not drop local changes without Overwrite.
+	writeChart("~1.1.0")
+	_, err = vendor(false)
+	assert.ErrorContains(t, err, "charts/dep has local changes")
+	assert.FileExists(t, patch)
+
+	out, err = vendor(true)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Vendored dep 1.1.0 into charts/dep")
+	assert.NoFileExists(t, patch)
+	manifest, err = LoadVendorManifest(chartpath)
+	require.NoError(t, err)
+	require.Len(t, manifest.Dependencies, 1)
+	assert.Equal(t, "1.1.0", manifest.Dependencies[0].Version)
+
+	// Dependencies which are no longer declared are removed.
+	chartfile := "apiVersion: v2\nname: parent\nversion: 0.1.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(chartpath, "Chart.yaml"), []byte(chartfile), 0o644))
+	require.NoError(t, os.RemoveAll(filepath.Join(chartpath, "Chart.lock")))
+	out, err = vendor(false)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Removed charts/dep which is no longer a dependency")
+	assert.NoDirExists(t, depDir)
+}
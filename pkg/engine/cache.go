@@ -0,0 +1,225 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"hash"
+	"maps"
+	"sync"
+	"text/template"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// TemplateCache keeps the templates parsed by the most recent render, so that
+// rendering an unchanged set of templates again skips parsing them.
+//
+// A cache should only be shared by engines with the same CustomTemplateFuncs.
+// It is safe for concurrent use. The zero value is ready to use.
+type TemplateCache struct {
+	mu   sync.Mutex
+	key  [sha256.Size]byte
+	tmpl *template.Template
+}
+
+// get returns a copy of the cached templates if they were parsed from the
+// template set identified by key.
+func (c *TemplateCache) get(key [sha256.Size]byte) *template.Template {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tmpl == nil || c.key != key {
+		return nil
+	}
+	t, err := c.tmpl.Clone()
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+func (c *TemplateCache) put(key [sha256.Size]byte, t *template.Template) {
+	clone, err := t.Clone()
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.key = key
+	c.tmpl = clone
+}
+
+// templateSetKey identifies a set of templates by their names and sources.
+func templateSetKey(tpls map[string]renderable, keys []string, strict, strictValues bool) [sha256.Size]byte {
+	h := sha256.New()
+	for _, b := range []bool{strict, strictValues} {
+		if b {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+	}
+	for _, filename := range keys {
+		h.Write([]byte(filename))
+		h.Write([]byte{0})
+		h.Write([]byte(tpls[filename].tpl))
+		h.Write([]byte{0})
+	}
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// DefaultRenderCacheEntries is the number of renders a RenderCache keeps when
+// its MaxEntries is not set.
+const DefaultRenderCacheEntries = 16
+
+// RenderCache keeps the output of renders, so that rendering a chart again
+// with the same values, for example to diff, lint and dry-run it in the same
+// process, returns the previous output without executing the templates. A
+// render is identified by the digest of the content of the chart, its
+// dependencies included, and of the values, so that any change to them renders
+// the chart again. The templates are parsed once too, as with a TemplateCache.
+//
+// As rendered output is reused, templates calling functions whose results
+// vary, such as randAlphaNum or now, render the same output each time. Renders
+// of engines able to look resources up in a cluster are never cached.
+//
+// A cache should only be shared by engines with the same CustomTemplateFuncs.
+// It is safe for concurrent use. The zero value is ready to use.
+type RenderCache struct {
+	// MaxEntries is the number of renders kept, the oldest being evicted
+	// first. Zero means DefaultRenderCacheEntries.
+	MaxEntries int
+
+	templates TemplateCache
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]map[string]string
+	order   [][sha256.Size]byte
+}
+
+// get returns a copy of the output of the render identified by key.
+func (c *RenderCache) get(key [sha256.Size]byte) (map[string]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out, ok := c.entries[key]
+	return maps.Clone(out), ok
+}
+
+func (c *RenderCache) put(key [sha256.Size]byte, out map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[[sha256.Size]byte]map[string]string)
+	}
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	limit := c.MaxEntries
+	if limit <= 0 {
+		limit = DefaultRenderCacheEntries
+	}
+	for len(c.order) >= limit {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = maps.Clone(out)
+	c.order = append(c.order, key)
+}
+
+// Len returns the number of renders in the cache.
+func (c *RenderCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// renderKey identifies the render of a chart with values by an engine. It
+// fails if the values cannot be digested.
+func (e Engine) renderKey(chrt ci.Charter, values common.Values) ([sha256.Size]byte, error) {
+	var key [sha256.Size]byte
+	h := sha256.New()
+	for _, b := range []bool{e.Strict, e.LintMode, e.EnableDNS, e.StrictValues} {
+		if b {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+	}
+	// Maps are encoded with sorted keys, making the encoding deterministic
+	vals, err := json.Marshal(values)
+	if err != nil {
+		return key, err
+	}
+	h.Write(vals)
+	h.Write([]byte{0})
+	policy, err := json.Marshal(e.FuncPolicy)
+	if err != nil {
+		return key, err
+	}
+	h.Write(policy)
+	h.Write([]byte{0})
+	context, err := json.Marshal(e.Context)
+	if err != nil {
+		return key, err
+	}
+	h.Write(context)
+	h.Write([]byte{0})
+	if err := chartDigest(h, chrt); err != nil {
+		return key, err
+	}
+	copy(key[:], h.Sum(nil))
+	return key, nil
+}
+
+// chartDigest writes the content of a chart and of its dependencies to h.
+func chartDigest(h hash.Hash, chrt ci.Charter) error {
+	accessor, err := ci.NewAccessor(chrt)
+	if err != nil {
+		return err
+	}
+	meta, err := json.Marshal(accessor.MetadataAsMap())
+	if err != nil {
+		return err
+	}
+	h.Write([]byte(accessor.ChartFullPath()))
+	h.Write([]byte{0})
+	h.Write(meta)
+	h.Write([]byte{0})
+	for _, files := range [][]*common.File{accessor.Templates(), accessor.Files()} {
+		for _, f := range files {
+			if f == nil {
+				continue
+			}
+			h.Write([]byte(f.Name))
+			h.Write([]byte{0})
+			h.Write(f.Data)
+			h.Write([]byte{0})
+		}
+		h.Write([]byte{1})
+	}
+	for _, dep := range accessor.Dependencies() {
+		if err := chartDigest(h, dep); err != nil {
+			return err
+		}
+	}
+	h.Write([]byte{2})
+	return nil
+}
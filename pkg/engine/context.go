@@ -0,0 +1,63 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// builtinObjects are the top-level objects Helm passes to every template.
+var builtinObjects = []string{"Values", "Release", "Chart", "Capabilities", "Files", "Template", "Subcharts"}
+
+// IsBuiltinObject reports whether name is one of the top-level objects Helm
+// passes to every template, such as Values, which the objects of
+// Engine.Context cannot replace.
+func IsBuiltinObject(name string) bool {
+	return slices.Contains(builtinObjects, name)
+}
+
+// validateContext checks that the names of the objects of the render context
+// can be referenced from templates and do not replace a built-in object.
+func validateContext(context map[string]interface{}) error {
+	for name := range context {
+		if IsBuiltinObject(name) {
+			return fmt.Errorf("render context object %q conflicts with a built-in object", name)
+		}
+		if !isIdentifier(name) {
+			return fmt.Errorf("render context object %q is not a valid template field name", name)
+		}
+	}
+	return nil
+}
+
+// isIdentifier reports whether name can be referenced as a field, as in
+// {{ .Platform }}.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
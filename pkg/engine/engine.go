@@ -17,6 +17,7 @@ limitations under the License.
 package engine
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -27,6 +28,7 @@ import (
 	"sort"
 	"strings"
 	"text/template"
+	"time"
 
 	"k8s.io/client-go/rest"
 
@@ -59,6 +61,46 @@ type Engine struct {
 	EnableDNS bool
 	// CustomTemplateFuncs is defined by users to provide custom template funcs
 	CustomTemplateFuncs template.FuncMap
+	// TemplateCache, if set, reuses parsed templates across renders of the
+	// same templates
+	TemplateCache *TemplateCache
+	// RenderCache, if set, reuses the output of renders of the same chart
+	// with the same values
+	RenderCache *RenderCache
+	// Parallelism is the number of templates rendered at the same time. Zero
+	// or one renders them one after the other. Templates rendered in parallel
+	// each get a copy of .Values, so changes a template makes to it, as with
+	// the set function, are not seen by the other templates.
+	Parallelism int
+	// TemplateTimeout, if set, is the time after which rendering a single
+	// template fails with ErrRenderTimeout
+	TemplateTimeout time.Duration
+	// RenderTimeout, if set, is the time after which rendering all the
+	// templates fails with ErrRenderTimeout
+	RenderTimeout time.Duration
+	// MaxOutputSize, if set, is the number of bytes the output of a template,
+	// or of all the templates together, can have before rendering fails with
+	// ErrOutputTooLarge. It also limits the output of include, tpl and repeat.
+	MaxOutputSize int
+	// FuncPolicy, if set, disables and restricts the template functions
+	// available to the charts rendered
+	FuncPolicy *FuncPolicy
+	// StrictValues makes rendering fail with ErrUndefinedValue when a
+	// template references a value that is not defined, as .Values.a.b,
+	// instead of rendering it empty. The values passed to default, hasKey,
+	// empty, coalesce and required may be undefined.
+	StrictValues bool
+	// Trace, if set, records the templates executed by each render, how long
+	// they took, the templates they included and the values they reference
+	Trace *RenderTrace
+	// Context holds additional top-level objects for the templates of every
+	// chart rendered, such as .Platform, letting platforms embedding Helm
+	// pass their own data to their charts. The names must be valid template
+	// field names other than those of the built-in objects, such as Values.
+	Context map[string]interface{}
+
+	// limits are those of the render in progress
+	limits *renderLimits
 }
 
 // New creates a new instance of Engine using the passed in rest config.
@@ -88,9 +130,59 @@ func New(config *rest.Config) Engine {
 // that section of the values will be passed into the "foo" chart. And if that
 // section contains a value named "bar", that value will be passed on to the
 // bar chart during render time.
+//
+// The manifests in the raw/ directory of each chart are added to the output
+// as they are, without being rendered. The rawFiles value of a chart can
+// limit them to the files matching a list of patterns.
+//
+// With a RenderCache, rendering a chart again with the same values returns the
+// output of the previous render.
 func (e Engine) Render(chrt ci.Charter, values common.Values) (map[string]string, error) {
-	tmap := allTemplates(chrt, values)
-	return e.render(tmap)
+	var key [sha256.Size]byte
+	store := false
+	if e.RenderCache != nil {
+		if e.TemplateCache == nil {
+			e.TemplateCache = &e.RenderCache.templates
+		}
+		// Lookups make the output depend on the cluster
+		if e.clientProvider == nil && e.Trace == nil {
+			var err error
+			key, err = e.renderKey(chrt, values)
+			if err != nil {
+				slog.Debug("not caching the render", "error", err)
+			} else if out, ok := e.RenderCache.get(key); ok {
+				return out, nil
+			} else {
+				store = true
+			}
+		}
+	}
+
+	if e.Trace != nil {
+		e.Trace.reset()
+		defer e.Trace.finish(time.Now())
+	}
+
+	if err := validateContext(e.Context); err != nil {
+		return map[string]string{}, err
+	}
+	tmap := allTemplates(chrt, values, e.Context)
+	if err := e.FuncPolicy.filterFiles(tmap); err != nil {
+		return map[string]string{}, err
+	}
+	rendered, err := e.render(tmap)
+	if err != nil {
+		return rendered, err
+	}
+	raw, err := allRawFiles(chrt, values)
+	if err != nil {
+		return map[string]string{}, fmt.Errorf("selecting raw files: %w", err)
+	}
+	maps.Copy(rendered, raw)
+	if store {
+		e.RenderCache.put(key, rendered)
+	}
+	return rendered, nil
 }
 
 // Render takes a chart, optional values, and value overrides, and attempts to
@@ -141,9 +233,12 @@ func warnWrap(warn string) string {
 
 // 'include' needs to be defined in the scope of a 'tpl' template as
 // well as regular file-loaded templates.
-func includeFun(t *template.Template, includedNames map[string]int) func(string, interface{}) (string, error) {
+func includeFun(t *template.Template, includedNames map[string]int, l *renderLimits, tr *tracer) func(string, interface{}) (string, error) {
 	return func(name string, data interface{}) (string, error) {
-		var buf strings.Builder
+		if err := l.aborted(); err != nil {
+			return "", err
+		}
+		buf := &limitedBuffer{name: name, l: l}
 		if v, ok := includedNames[name]; ok {
 			if v > recursionMaxNums {
 				return "", fmt.Errorf(
@@ -154,7 +249,9 @@ func includeFun(t *template.Template, includedNames map[string]int) func(string,
 		} else {
 			includedNames[name] = 1
 		}
-		err := t.ExecuteTemplate(&buf, name, data)
+		end := tr.begin(t, name)
+		err := t.ExecuteTemplate(buf, name, data)
+		end()
 		includedNames[name]--
 		return buf.String(), err
 	}
@@ -162,8 +259,11 @@ func includeFun(t *template.Template, includedNames map[string]int) func(string,
 
 // As does 'tpl', so that nested calls to 'tpl' see the templates
 // defined by their enclosing contexts.
-func tplFun(parent *template.Template, includedNames map[string]int, strict bool) func(string, interface{}) (string, error) {
+func tplFun(parent *template.Template, includedNames map[string]int, strict, strictVals bool, l *renderLimits, tr *tracer) func(string, interface{}) (string, error) {
 	return func(tpl string, vals interface{}) (string, error) {
+		if err := l.aborted(); err != nil {
+			return "", err
+		}
 		t, err := parent.Clone()
 		if err != nil {
 			return "", fmt.Errorf("cannot clone template: %w", err)
@@ -181,8 +281,8 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 		// Re-inject 'include' so that it can close over our clone of t;
 		// this lets any 'define's inside tpl be 'include'd.
 		t.Funcs(template.FuncMap{
-			"include": includeFun(t, includedNames),
-			"tpl":     tplFun(t, includedNames, strict),
+			"include": includeFun(t, includedNames, l, tr),
+			"tpl":     tplFun(t, includedNames, strict, strictVals, l, tr),
 		})
 
 		// We need a .New template, as template text which is just blanks
@@ -195,9 +295,12 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 		if err != nil {
 			return "", fmt.Errorf("cannot parse template %q: %w", tpl, err)
 		}
+		if strictVals && t.Tree != nil {
+			strictValuesNode(t.Tree.Root)
+		}
 
-		var buf strings.Builder
-		if err := t.Execute(&buf, vals); err != nil {
+		buf := &limitedBuffer{name: parent.Name(), l: l}
+		if err := t.Execute(buf, vals); err != nil {
 			return "", fmt.Errorf("error during tpl function execution for %q: %w", tpl, err)
 		}
 
@@ -207,13 +310,21 @@ func tplFun(parent *template.Template, includedNames map[string]int, strict bool
 }
 
 // initFunMap creates the Engine's FuncMap and adds context-specific functions.
-func (e Engine) initFunMap(t *template.Template) {
+// It returns the tracer recording the executions of the templates of t.
+func (e Engine) initFunMap(t *template.Template) *tracer {
 	funcMap := funcMap()
 	includedNames := make(map[string]int)
+	tr := e.newTracer()
 
 	// Add the template-rendering functions here so we can close over t.
-	funcMap["include"] = includeFun(t, includedNames)
-	funcMap["tpl"] = tplFun(t, includedNames, e.Strict)
+	funcMap["include"] = includeFun(t, includedNames, e.limits, tr)
+	funcMap["tpl"] = tplFun(t, includedNames, e.Strict, e.StrictValues, e.limits, tr)
+	if e.StrictValues {
+		funcMap[strictValueFunc] = strictValue
+	}
+	if e.limits != nil && e.limits.maxOutput > 0 {
+		funcMap["repeat"] = e.limits.limitedRepeat
+	}
 
 	// Add the `required` function here so we can use lintMode
 	funcMap["required"] = func(warn string, val interface{}) (interface{}, error) {
@@ -264,7 +375,10 @@ func (e Engine) initFunMap(t *template.Template) {
 	// Set custom template funcs
 	maps.Copy(funcMap, e.CustomTemplateFuncs)
 
+	e.FuncPolicy.apply(funcMap)
+
 	t.Funcs(funcMap)
+	return tr
 }
 
 // render takes a map of templates/values and renders them.
@@ -281,6 +395,87 @@ func (e Engine) render(tpls map[string]renderable) (rendered map[string]string,
 			err = fmt.Errorf("rendering template failed: %v", r)
 		}
 	}()
+
+	e.limits = e.newRenderLimits()
+
+	// We want to parse the templates in a predictable order. The order favors
+	// higher-level (in file system) templates over deeply nested templates.
+	keys := sortTemplates(tpls)
+
+	t, tr, err := e.parse(tpls, keys)
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	// Don't render partials. We don't care out the direct output of partials.
+	// They are only included from other templates.
+	files := make([]string, 0, len(keys))
+	for _, filename := range keys {
+		if !strings.HasPrefix(path.Base(filename), "_") {
+			files = append(files, filename)
+		}
+	}
+	if e.Parallelism > 1 && len(files) > 1 {
+		return e.renderParallel(t, tpls, files)
+	}
+
+	rendered = make(map[string]string, len(files))
+	for _, filename := range files {
+		out, err := executeTemplate(t, filename, tpls[filename].vals, tpls[filename].basePath, e.limits, tr)
+		if err != nil {
+			return map[string]string{}, err
+		}
+		rendered[filename] = out
+	}
+
+	return rendered, nil
+}
+
+// executeTemplate renders a template of the set t with vals, within the
+// limits l of the render, recording its execution with tr.
+func executeTemplate(t *template.Template, filename string, vals common.Values, basePath string, l *renderLimits, tr *tracer) (string, error) {
+	// At render time, add information about the template that is being rendered.
+	vals["Template"] = common.Values{"Name": filename, "BasePath": basePath}
+	buf := &limitedBuffer{name: filename, l: l}
+	exec := func() error {
+		defer tr.begin(t, filename)()
+		return t.ExecuteTemplate(buf, filename, vals)
+	}
+	var err error
+	if l == nil {
+		err = exec()
+	} else {
+		err = l.execute(filename, exec)
+	}
+	// Errors of templates stopped by the limits are only a consequence
+	if aborted := l.aborted(); aborted != nil {
+		return "", aborted
+	}
+	if err != nil {
+		return "", reformatExecErrorMsg(filename, err)
+	}
+	if err := l.add(buf.buf.Len()); err != nil {
+		return "", err
+	}
+
+	// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
+	// is set. Since missing=error will never get here, we do not need to handle
+	// the Strict case.
+	return strings.ReplaceAll(buf.String(), "<no value>", ""), nil
+}
+
+// parse parses the templates into a single template set, or copies the set
+// from the template cache if the templates are unchanged.
+func (e Engine) parse(tpls map[string]renderable, keys []string) (*template.Template, *tracer, error) {
+	var key [sha256.Size]byte
+	if e.TemplateCache != nil {
+		key = templateSetKey(tpls, keys, e.Strict, e.StrictValues)
+		if t := e.TemplateCache.get(key); t != nil {
+			// The copy still holds functions bound to the cached set.
+			return t, e.initFunMap(t), nil
+		}
+	}
+
 	t := template.New("gotpl")
 	if e.Strict {
 		t.Option("missingkey=error")
@@ -290,41 +485,22 @@ func (e Engine) render(tpls map[string]renderable) (rendered map[string]string,
 		t.Option("missingkey=zero")
 	}
 
-	e.initFunMap(t)
-
-	// We want to parse the templates in a predictable order. The order favors
-	// higher-level (in file system) templates over deeply nested templates.
-	keys := sortTemplates(tpls)
+	tr := e.initFunMap(t)
 
 	for _, filename := range keys {
 		r := tpls[filename]
 		if _, err := t.New(filename).Parse(r.tpl); err != nil {
-			return map[string]string{}, cleanupParseError(filename, err)
+			return nil, nil, cleanupParseError(filename, err)
 		}
 	}
-
-	rendered = make(map[string]string, len(keys))
-	for _, filename := range keys {
-		// Don't render partials. We don't care out the direct output of partials.
-		// They are only included from other templates.
-		if strings.HasPrefix(path.Base(filename), "_") {
-			continue
-		}
-		// At render time, add information about the template that is being rendered.
-		vals := tpls[filename].vals
-		vals["Template"] = common.Values{"Name": filename, "BasePath": tpls[filename].basePath}
-		var buf strings.Builder
-		if err := t.ExecuteTemplate(&buf, filename, vals); err != nil {
-			return map[string]string{}, reformatExecErrorMsg(filename, err)
-		}
-
-		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
-		// is set. Since missing=error will never get here, we do not need to handle
-		// the Strict case.
-		rendered[filename] = strings.ReplaceAll(buf.String(), "<no value>", "")
+	if e.StrictValues {
+		strictValues(t)
 	}
 
-	return rendered, nil
+	if e.TemplateCache != nil {
+		e.TemplateCache.put(key, t)
+	}
+	return t, tr, nil
 }
 
 func cleanupParseError(filename string, err error) error {
@@ -386,6 +562,14 @@ func reformatExecErrorMsg(filename string, err error) error {
 	if len(parts) >= 2 {
 		return fmt.Errorf("execution error at (%s): %s", string(location), parts[1])
 	}
+	if errors.Is(err, ErrUndefinedValue) {
+		// Report the reference to the undefined value alone
+		cause := err
+		for u := errors.Unwrap(cause); u != nil && u != ErrUndefinedValue; u = errors.Unwrap(cause) {
+			cause = u
+		}
+		return fmt.Errorf("execution error at (%s): %w", location, cause)
+	}
 	current := err
 	fileLocations := []TraceableError{}
 	for current != nil {
@@ -455,9 +639,9 @@ func (p byPathLen) Less(i, j int) bool {
 // allTemplates returns all templates for a chart and its dependencies.
 //
 // As it goes, it also prepares the values in a scope-sensitive manner.
-func allTemplates(c ci.Charter, vals common.Values) map[string]renderable {
+func allTemplates(c ci.Charter, vals common.Values, context map[string]interface{}) map[string]renderable {
 	templates := make(map[string]renderable)
-	recAllTpls(c, templates, vals)
+	recAllTpls(c, templates, vals, context)
 	return templates
 }
 
@@ -465,7 +649,7 @@ func allTemplates(c ci.Charter, vals common.Values) map[string]renderable {
 //
 // As it recurses, it also sets the values to be appropriate for the template
 // scope.
-func recAllTpls(c ci.Charter, templates map[string]renderable, values common.Values) map[string]interface{} {
+func recAllTpls(c ci.Charter, templates map[string]renderable, values common.Values, context map[string]interface{}) map[string]interface{} {
 	vals := values.AsMap()
 	subCharts := make(map[string]interface{})
 	accessor, err := ci.NewAccessor(c)
@@ -473,7 +657,6 @@ func recAllTpls(c ci.Charter, templates map[string]renderable, values common.Val
 		slog.Error("error accessing chart", "error", err)
 	}
 	chartMetaData := accessor.MetadataAsMap()
-	fmt.Printf("metadata: %v\n", chartMetaData)
 	chartMetaData["IsRoot"] = accessor.IsRoot()
 
 	next := map[string]interface{}{
@@ -484,6 +667,7 @@ func recAllTpls(c ci.Charter, templates map[string]renderable, values common.Val
 		"Values":       make(common.Values),
 		"Subcharts":    subCharts,
 	}
+	maps.Copy(next, context)
 
 	// If there is a {{.Values.ThisChart}} in the parent metadata,
 	// copy that into the {{.Values}} for this template.
@@ -496,7 +680,7 @@ func recAllTpls(c ci.Charter, templates map[string]renderable, values common.Val
 	for _, child := range accessor.Dependencies() {
 		// TODO: Handle error
 		sub, _ := ci.NewAccessor(child)
-		subCharts[sub.Name()] = recAllTpls(child, templates, next)
+		subCharts[sub.Name()] = recAllTpls(child, templates, next, context)
 	}
 
 	newParentID := accessor.ChartFullPath()
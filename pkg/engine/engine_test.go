@@ -19,12 +19,15 @@ package engine
 import (
 	"fmt"
 	"path"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -582,7 +585,7 @@ func TestAllTemplates(t *testing.T) {
 	}
 	dep1.AddDependency(dep2)
 
-	tpls := allTemplates(ch1, common.Values{})
+	tpls := allTemplates(ch1, common.Values{}, nil)
 	if len(tpls) != 5 {
 		t.Errorf("Expected 5 charts, got %d", len(tpls))
 	}
@@ -650,6 +653,66 @@ func TestRenderDependency(t *testing.T) {
 
 }
 
+func TestRenderRawFiles(t *testing.T) {
+	raw := "kind: ConfigMap\ndata:\n  key: {{ .Values.notRendered }}\n"
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "outerchart"},
+		Templates: []*common.File{
+			{Name: "templates/outer.yaml", Data: []byte("kind: Secret")},
+		},
+		Files: []*common.File{
+			{Name: "raw/big.yaml", Data: []byte(raw)},
+			{Name: "raw/crds/crd.json", Data: []byte(`{"kind": "CustomResourceDefinition"}`)},
+			{Name: "raw/README.md", Data: []byte("# not a manifest")},
+			{Name: "other/file.yaml", Data: []byte("kind: Other")},
+		},
+	}
+	ch.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "innerchart"},
+		Files: []*common.File{
+			{Name: "raw/a.yaml", Data: []byte("kind: A")},
+			{Name: "raw/b.yaml", Data: []byte("kind: B")},
+		},
+	})
+	ch.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "library", Type: "library"},
+		Files: []*common.File{
+			{Name: "raw/lib.yaml", Data: []byte("kind: Library")},
+		},
+	})
+
+	out, err := Render(ch, common.Values{"Values": common.Values{
+		"innerchart": map[string]interface{}{"rawFiles": []interface{}{"b.*"}},
+	}})
+	if err != nil {
+		t.Fatalf("failed to render chart: %s", err)
+	}
+	expect := map[string]string{
+		"outerchart/templates/outer.yaml":         "kind: Secret",
+		"outerchart/raw/big.yaml":                 raw,
+		"outerchart/raw/crds/crd.json":            `{"kind": "CustomResourceDefinition"}`,
+		"outerchart/charts/innerchart/raw/b.yaml": "kind: B",
+	}
+	if !reflect.DeepEqual(out, expect) {
+		t.Errorf("Expected %v, got %v", expect, out)
+	}
+
+	out, err = Render(ch, common.Values{"Values": common.Values{"rawFiles": []interface{}{}}})
+	if err != nil {
+		t.Fatalf("failed to render chart: %s", err)
+	}
+	if _, ok := out["outerchart/raw/big.yaml"]; ok {
+		t.Error("Expected an empty rawFiles list to exclude all raw files")
+	}
+
+	for _, rawFiles := range []interface{}{"*.yaml", []interface{}{"["}, []interface{}{1}} {
+		_, err := Render(ch, common.Values{"Values": common.Values{"rawFiles": rawFiles}})
+		if err == nil || !strings.Contains(err.Error(), "chart outerchart:") {
+			t.Errorf("Expected an error for rawFiles %v, got %v", rawFiles, err)
+		}
+	}
+}
+
 func TestRenderNestedValues(t *testing.T) {
 	innerpath := "templates/inner.tpl"
 	outerpath := "templates/outer.tpl"
@@ -1429,3 +1492,447 @@ func TestRenderCustomTemplateFuncs(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expected, rendered)
 	}
 }
+
+func TestRenderWithTemplateCache(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{define "greeting"}}hello {{.Values.name}}{{end}}`)},
+			{Name: "templates/test1", Data: []byte(`{{include "greeting" .}}`)},
+		},
+		Values: map[string]interface{}{},
+	}
+	render := func(e Engine, name string) string {
+		t.Helper()
+		v, err := util.CoalesceValues(c, map[string]interface{}{"Values": map[string]interface{}{"name": name}})
+		if err != nil {
+			t.Fatalf("Failed to coalesce values: %s", err)
+		}
+		out, err := e.Render(c, v)
+		if err != nil {
+			t.Fatalf("Failed to render templates: %s", err)
+		}
+		return out["moby/templates/test1"]
+	}
+
+	e := Engine{TemplateCache: &TemplateCache{}}
+	assert.Equal(t, "hello ishmael", render(e, "ishmael"))
+	assert.Equal(t, "hello ahab", render(e, "ahab"), "cached templates must be executed with the new values")
+
+	c.Templates[0].Data = []byte(`{{define "greeting"}}goodbye {{.Values.name}}{{end}}`)
+	assert.Equal(t, "goodbye ahab", render(e, "ahab"), "changed templates must be parsed again")
+}
+
+func TestRenderWithRenderCache(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/test1", Data: []byte(`{{.Values.name}} {{randAlphaNum 8}}`)},
+		},
+		Files: []*common.File{
+			{Name: "raw/cm.yaml", Data: []byte("kind: ConfigMap")},
+		},
+		Values: map[string]interface{}{},
+	}
+	render := func(e Engine, name string) map[string]string {
+		t.Helper()
+		v, err := util.CoalesceValues(c, map[string]interface{}{"Values": map[string]interface{}{"name": name}})
+		if err != nil {
+			t.Fatalf("Failed to coalesce values: %s", err)
+		}
+		out, err := e.Render(c, v)
+		if err != nil {
+			t.Fatalf("Failed to render templates: %s", err)
+		}
+		return out
+	}
+
+	cache := &RenderCache{MaxEntries: 2}
+	e := Engine{RenderCache: cache}
+	first := render(e, "ishmael")
+	assert.Equal(t, "kind: ConfigMap", first["moby/raw/cm.yaml"])
+	// The random string shows that the output is reused
+	assert.Equal(t, first, render(e, "ishmael"))
+	assert.Equal(t, first, render(Engine{RenderCache: cache}, "ishmael"), "the cache is shared by engines")
+	assert.Equal(t, 1, cache.Len())
+
+	// The output returned is a copy
+	first["moby/templates/test1"] = "changed"
+	assert.NotEqual(t, "changed", render(e, "ishmael")["moby/templates/test1"])
+
+	assert.True(t, strings.HasPrefix(render(e, "ahab")["moby/templates/test1"], "ahab "), "changed values must be rendered again")
+	assert.NotEqual(t, render(e, "ishmael"), render(Engine{RenderCache: cache, Strict: true}, "ishmael"), "engine settings are part of the key")
+	assert.Equal(t, 2, cache.Len(), "the oldest renders are evicted")
+
+	c.Files[0].Data = []byte("kind: Secret")
+	assert.Equal(t, "kind: Secret", render(e, "ishmael")["moby/raw/cm.yaml"], "changed files must be rendered again")
+
+	// Renders using a client are not cached
+	var provider ClientProvider = &testClientProvider{t: t}
+	client := Engine{RenderCache: cache, clientProvider: &provider}
+	assert.NotEqual(t, render(client, "queequeg"), render(client, "queequeg"))
+}
+
+func TestRenderParallel(t *testing.T) {
+	templates := []*common.File{
+		{Name: "templates/_helpers.tpl", Data: []byte(`{{define "name"}}{{.Template.Name}}{{end}}`)},
+	}
+	for i := range 50 {
+		templates = append(templates, &common.File{
+			Name: fmt.Sprintf("templates/t%02d.yaml", i),
+			Data: []byte(`{{include "name" .}} {{tpl "{{.Values.greeting}}" .}} {{.Template.BasePath}}`),
+		})
+	}
+	c := &chart.Chart{
+		Metadata:  &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: templates,
+		Values:    map[string]interface{}{},
+	}
+	v, err := util.CoalesceValues(c, map[string]interface{}{"Values": map[string]interface{}{"greeting": "hello"}})
+	require.NoError(t, err)
+
+	want, err := Engine{}.Render(c, v)
+	require.NoError(t, err)
+	got, err := Engine{Parallelism: 8}.Render(c, v)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, "moby/templates/t07.yaml hello moby/templates", got["moby/templates/t07.yaml"])
+
+	// The error is that of the first template failing, as when rendering
+	// the templates one after the other
+	c.Templates[20].Data = []byte(`{{fail "first"}}`)
+	c.Templates[40].Data = []byte(`{{fail "second"}}`)
+	_, want2 := Engine{}.Render(c, v)
+	require.Error(t, want2)
+	for range 5 {
+		_, err := Engine{Parallelism: 8}.Render(c, v)
+		assert.EqualError(t, err, want2.Error())
+	}
+}
+
+func TestRenderParallelSetValues(t *testing.T) {
+	var templates []*common.File
+	for i := range 50 {
+		templates = append(templates, &common.File{
+			Name: fmt.Sprintf("templates/t%02d.yaml", i),
+			Data: []byte(fmt.Sprintf(`{{- $_ := set .Values.nested "index" %d -}}{{- $_ := unset .Values "greeting" -}}{{ .Values.nested.index }} {{ len .Values }}`, i)),
+		})
+	}
+	c := &chart.Chart{
+		Metadata:  &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: templates,
+		Values:    map[string]interface{}{},
+	}
+	v, err := util.CoalesceValues(c, map[string]interface{}{"Values": map[string]interface{}{
+		"greeting": "hello",
+		"nested":   map[string]interface{}{"index": -1},
+	}})
+	require.NoError(t, err)
+
+	got, err := Engine{Parallelism: 8}.Render(c, v)
+	require.NoError(t, err)
+	for i := range 50 {
+		assert.Equal(t, fmt.Sprintf("%d 1", i), got[fmt.Sprintf("moby/templates/t%02d.yaml", i)])
+	}
+	// The values given to the engine are not modified
+	assert.Equal(t, map[string]interface{}{"greeting": "hello", "nested": map[string]interface{}{"index": -1}}, v["Values"])
+}
+
+func TestRenderLimits(t *testing.T) {
+	render := func(e Engine, templates map[string]string) (map[string]string, error) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+			Values:   map[string]interface{}{},
+		}
+		for name, data := range templates {
+			c.Templates = append(c.Templates, &common.File{Name: name, Data: []byte(data)})
+		}
+		v, err := util.CoalesceValues(c, map[string]interface{}{})
+		require.NoError(t, err)
+		return e.Render(c, v)
+	}
+	endless := `{{range until 100000}}{{range until 100000}}x{{end}}{{end}}`
+
+	tests := []struct {
+		name      string
+		engine    Engine
+		templates map[string]string
+		want      error
+		message   string
+	}{
+		{
+			name:      "within the limits",
+			engine:    Engine{TemplateTimeout: time.Minute, RenderTimeout: time.Minute, MaxOutputSize: 10},
+			templates: map[string]string{"templates/a": `{{repeat 5 "a"}}`, "templates/b": `{{include "c" .}}`, "templates/_c": `{{define "c"}}bbbbb{{end}}`},
+		},
+		{
+			name:      "template timeout",
+			engine:    Engine{TemplateTimeout: 50 * time.Millisecond},
+			templates: map[string]string{"templates/endless": endless},
+			want:      ErrRenderTimeout,
+			message:   "rendering timed out: moby/templates/endless took longer than 50ms",
+		},
+		{
+			name:      "render timeout",
+			engine:    Engine{TemplateTimeout: time.Minute, RenderTimeout: 50 * time.Millisecond},
+			templates: map[string]string{"templates/endless": `{{tpl "` + endless + `" .}}`},
+			want:      ErrRenderTimeout,
+			message:   "rendering timed out: rendering the templates took longer than 50ms",
+		},
+		{
+			name:      "parallel timeout",
+			engine:    Engine{TemplateTimeout: 50 * time.Millisecond, Parallelism: 4},
+			templates: map[string]string{"templates/a": endless, "templates/b": endless, "templates/c": "ok"},
+			want:      ErrRenderTimeout,
+		},
+		{
+			name:      "template too large",
+			engine:    Engine{MaxOutputSize: 1024},
+			templates: map[string]string{"templates/large": endless},
+			want:      ErrOutputTooLarge,
+			message:   "rendered output too large: moby/templates/large exceeds 1024 bytes",
+		},
+		{
+			name:      "include too large",
+			engine:    Engine{MaxOutputSize: 1024},
+			templates: map[string]string{"templates/large": `{{include "large" .}}`, "templates/_large": `{{define "large"}}` + endless + `{{end}}`},
+			want:      ErrOutputTooLarge,
+			message:   "rendered output too large: large exceeds 1024 bytes",
+		},
+		{
+			name:      "templates too large",
+			engine:    Engine{MaxOutputSize: 1024},
+			templates: map[string]string{"templates/a": `{{repeat 600 "a"}}`, "templates/b": `{{repeat 600 "b"}}`},
+			want:      ErrOutputTooLarge,
+			message:   "rendered output too large: the rendered templates exceed 1024 bytes",
+		},
+		{
+			name:      "repeat too large",
+			engine:    Engine{MaxOutputSize: 1024},
+			templates: map[string]string{"templates/huge": `{{repeat 1000000000000 "abc"}}`},
+			want:      ErrOutputTooLarge,
+			message:   "rendered output too large: repeat of 1000000000000 times 3 bytes exceeds 1024 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			out, err := render(tt.engine, tt.templates)
+			assert.Less(t, time.Since(start), 10*time.Second)
+			if tt.want == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "aaaaa", out["moby/templates/a"])
+				assert.Equal(t, "bbbbb", out["moby/templates/b"])
+				return
+			}
+			require.ErrorIs(t, err, tt.want)
+			if tt.message != "" {
+				assert.EqualError(t, err, tt.message)
+			}
+		})
+	}
+}
+
+func TestRenderWithFuncPolicy(t *testing.T) {
+	var provider ClientProvider = &testClientProvider{
+		t: t,
+		scheme: map[string]kindProps{
+			"v1/Pod": {
+				gvr:        schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+				namespaced: true,
+			},
+		},
+		objects: []runtime.Object{
+			makeUnstructured("v1", "Pod", "pod1", "default"),
+			makeUnstructured("v1", "Pod", "pod2", "ns1"),
+		},
+	}
+	policy := &FuncPolicy{
+		DisabledFuncs:    []string{"getHostByName", "randAlphaNum", "custom", "include"},
+		LookupNamespaces: []string{"ns1"},
+		Files:            []string{"config/*"},
+	}
+	render := func(tpl string) (string, error) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+			Templates: []*common.File{
+				{Name: "templates/test", Data: []byte(tpl)},
+				{Name: "templates/_helpers.tpl", Data: []byte(`{{define "name"}}moby{{end}}`)},
+			},
+			Files: []*common.File{
+				{Name: "config/a.txt", Data: []byte("a")},
+				{Name: "secret/key.pem", Data: []byte("key")},
+			},
+			Values: map[string]interface{}{},
+		}
+		sub := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "sub", Version: "1.2.3"},
+			Files:    []*common.File{{Name: "secret/sub.pem", Data: []byte("key")}},
+		}
+		c.AddDependency(sub)
+		v, err := util.CoalesceValues(c, map[string]interface{}{})
+		require.NoError(t, err)
+		e := Engine{
+			clientProvider:      &provider,
+			CustomTemplateFuncs: template.FuncMap{"custom": func() string { return "custom" }},
+			FuncPolicy:          policy,
+		}
+		out, err := e.Render(c, v)
+		return out["moby/templates/test"], err
+	}
+
+	out, err := render(`{{.Files.Get "config/a.txt"}}|{{.Files.Get "secret/key.pem"}}|{{.Subcharts.sub.Files.Get "secret/sub.pem"}}|{{len .Files}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "a|||1", out)
+
+	out, err = render(`{{(lookup "v1" "Pod" "ns1" "").items | len}} {{include "name" .}} {{tpl "{{include \"name\" .}}" .}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "1 moby moby", out, "include and tpl cannot be disabled")
+
+	for tpl, msg := range map[string]string{
+		`{{lookup "v1" "Pod" "default" "pod1"}}`: `lookup of Pod in namespace "default": not allowed by the template function policy`,
+		`{{lookup "v1" "Pod" "" ""}}`:            `lookup of Pod outside of a namespace: not allowed by the template function policy`,
+		`{{getHostByName "example.com"}}`:        "getHostByName: not allowed by the template function policy",
+		`{{randAlphaNum 5}}`:                     "randAlphaNum: not allowed by the template function policy",
+		`{{custom}}`:                             "custom: not allowed by the template function policy",
+	} {
+		_, err := render(tpl)
+		assert.ErrorContains(t, err, msg, tpl)
+	}
+
+	policy.Files = []string{"["}
+	_, err = render("")
+	assert.ErrorContains(t, err, `invalid file pattern "["`)
+}
+
+func TestRenderTrace(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{define "name"}}{{.Values.name}}{{end}}{{define "labels"}}app: {{include "name" .}}{{end}}`)},
+			{Name: "templates/cm.yaml", Data: []byte(`{{include "labels" .}} {{$.Values.image.tag}} {{(.Values.image).repository}}{{with .Values.extra}}{{.ignored}}{{end}}`)},
+			{Name: "templates/plain.yaml", Data: []byte(`{{tpl "{{include \"name\" .}}" .}}`)},
+		},
+		Values: map[string]interface{}{},
+	}
+	v, err := util.CoalesceValues(c, map[string]interface{}{"Values": map[string]interface{}{"name": "moby", "image": map[string]interface{}{"tag": "1"}}})
+	require.NoError(t, err)
+
+	for _, parallelism := range []int{0, 2} {
+		trace := &RenderTrace{}
+		e := Engine{Trace: trace, Parallelism: parallelism, RenderCache: &RenderCache{}}
+		for range 2 {
+			_, err := e.Render(c, v)
+			require.NoError(t, err)
+		}
+
+		require.Len(t, trace.Templates, 2, "the trace is reset by every render")
+		assert.Positive(t, trace.Duration)
+		cm, plain := trace.Templates[0], trace.Templates[1]
+		assert.Equal(t, "moby/templates/cm.yaml", cm.Name)
+		assert.Equal(t, []string{"extra", "image.repository", "image.tag"}, cm.ValuesPaths)
+		require.Len(t, cm.Includes, 1)
+		assert.Equal(t, "labels", cm.Includes[0].Name)
+		require.Len(t, cm.Includes[0].Includes, 1)
+		assert.Equal(t, "name", cm.Includes[0].Includes[0].Name)
+		assert.Equal(t, []string{"name"}, cm.Includes[0].Includes[0].ValuesPaths)
+		assert.GreaterOrEqual(t, cm.Duration, cm.Includes[0].Duration)
+
+		assert.Equal(t, "moby/templates/plain.yaml", plain.Name)
+		require.Len(t, plain.Includes, 1, "includes of tpl are traced")
+		assert.Equal(t, "name", plain.Includes[0].Name)
+
+		assert.Len(t, trace.Slowest(1), 1)
+		assert.Len(t, trace.Slowest(0), 2)
+	}
+}
+
+func TestRenderStrictValues(t *testing.T) {
+	render := func(e Engine, tpl string) (string, error) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+			Templates: []*common.File{
+				{Name: "templates/test", Data: []byte(tpl)},
+				{Name: "templates/_helpers.tpl", Data: []byte(`{{define "image"}}{{.Values.image.repository}}:{{.Values.image.tag}}{{end}}`)},
+			},
+			Values: map[string]interface{}{},
+		}
+		v, err := util.CoalesceValues(c, map[string]interface{}{"Values": map[string]interface{}{
+			"name":  "moby",
+			"empty": nil,
+			"image": map[string]interface{}{"repository": "nginx"},
+			"list":  []interface{}{"a", "b"},
+		}})
+		require.NoError(t, err)
+		out, err := e.Render(c, v)
+		return out["moby/templates/test"], err
+	}
+
+	for tpl, want := range map[string]string{
+		`{{.Values.name}} {{$.Values.name}}`:                                             "moby moby",
+		`{{.Values.missing | default "x"}} {{default "y" .Values.a}}`:                    "x y",
+		`{{hasKey .Values "missing"}} {{if hasKey .Values.image "tag"}}{{end}}`:          "false ",
+		`{{coalesce .Values.missing .Values.name}} {{empty .Values.missing}}`:            "moby true",
+		`{{range .Values.list}}{{.}}{{end}}{{with .Values.image}}{{.repository}}{{end}}`: "abnginx",
+		`{{.Values.empty}}`:            "",
+		`{{tpl "{{.Values.name}}" .}}`: "moby",
+	} {
+		out, err := render(Engine{StrictValues: true}, tpl)
+		if assert.NoError(t, err, tpl) {
+			assert.Equal(t, want, out, tpl)
+		}
+	}
+
+	for tpl, msg := range map[string]string{
+		`{{.Values.missing}}`:                       "undefined value .Values.missing: use default or hasKey for optional values",
+		`{{$.Values.image.tag}}`:                    "undefined value .Values.image.tag",
+		`{{if .Values.enabled}}{{end}}`:             "undefined value .Values.enabled",
+		`{{include "image" .}}`:                     "undefined value .Values.image.tag",
+		`{{tpl "{{.Values.missing}}" .}}`:           "undefined value .Values.missing",
+		`{{.Values.name.first}}`:                    "undefined value: .Values.name is not a map, so .Values.name.first is not defined",
+		`{{.Values.missing | quote | default "x"}}`: "undefined value .Values.missing",
+	} {
+		_, err := render(Engine{StrictValues: true}, tpl)
+		assert.ErrorIs(t, err, ErrUndefinedValue, tpl)
+		assert.ErrorContains(t, err, msg, tpl)
+	}
+
+	// Without StrictValues, undefined values are rendered empty
+	out, err := render(Engine{}, `{{.Values.missing}}`)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestRenderWithContext(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby", Version: "1.2.3"},
+		Templates: []*common.File{
+			{Name: "templates/test", Data: []byte(`{{.Platform.tenant}} {{include "region" .}}`)},
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{define "region"}}{{$.Platform.region}}{{end}}`)},
+		},
+		Values: map[string]interface{}{},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata:  &chart.Metadata{Name: "sub", Version: "1.2.3"},
+		Templates: []*common.File{{Name: "templates/test", Data: []byte(`{{.Platform.tenant}}`)}},
+	})
+	v, err := util.CoalesceValues(c, map[string]interface{}{})
+	require.NoError(t, err)
+
+	e := Engine{Context: map[string]interface{}{
+		"Platform": map[string]interface{}{"tenant": "acme", "region": "eu-west-1"},
+	}}
+	out, err := e.Render(c, v)
+	require.NoError(t, err)
+	assert.Equal(t, "acme eu-west-1", out["moby/templates/test"])
+	assert.Equal(t, "acme", out["moby/charts/sub/templates/test"])
+
+	for name, msg := range map[string]string{
+		"Values":      `render context object "Values" conflicts with a built-in object`,
+		"my-platform": `render context object "my-platform" is not a valid template field name`,
+	} {
+		e := Engine{Context: map[string]interface{}{name: "x"}}
+		_, err := e.Render(c, v)
+		assert.EqualError(t, err, msg)
+	}
+}
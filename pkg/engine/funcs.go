@@ -19,6 +19,7 @@ package engine
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"maps"
 	"strings"
 	"text/template"
@@ -54,6 +55,8 @@ func funcMap() template.FuncMap {
 		"toYaml":        toYAML,
 		"mustToYaml":    mustToYAML,
 		"toYamlPretty":  toYAMLPretty,
+		"toYamlSorted":  toYAMLSorted,
+		"toYamlIndent":  toYAMLIndent,
 		"fromYaml":      fromYAML,
 		"fromYamlArray": fromYAMLArray,
 		"toJson":        toJSON,
@@ -61,6 +64,9 @@ func funcMap() template.FuncMap {
 		"fromJson":      fromJSON,
 		"fromJsonArray": fromJSONArray,
 
+		"toRawJsonIndent":     toRawJSONIndent,
+		"mustToRawJsonIndent": mustToRawJSONIndent,
+
 		// This is a placeholder for the "include" function, which is
 		// late-bound to a template. By declaring it here, we preserve the
 		// integrity of the linter.
@@ -105,17 +111,96 @@ func mustToYAML(v interface{}) string {
 	return strings.TrimSuffix(string(data), "\n")
 }
 
+// toYAMLPretty takes an interface, marshals it to yaml indenting lists
+// within maps, and returns a string. Multi-line strings are written as
+// literal blocks. It will always return a string, even on marshal error
+// (empty string).
+//
+// This is designed to be called from a template.
 func toYAMLPretty(v interface{}) string {
-	var data bytes.Buffer
-	encoder := goYaml.NewEncoder(&data)
-	encoder.SetIndent(2)
-	err := encoder.Encode(v)
+	data, err := encodeYAML(v, 2)
+	if err != nil {
+		// Swallow errors inside of a template.
+		return ""
+	}
+	return data
+}
 
+// toYAMLSorted is toYAMLPretty, marshaling v to JSON first. The keys of
+// structs are then sorted like the keys of maps, and named after their json
+// tags, making the output stable whatever the type of v.
+//
+// This is designed to be called from a template.
+func toYAMLSorted(v interface{}) string {
+	data, err := json.Marshal(v)
 	if err != nil {
 		// Swallow errors inside of a template.
 		return ""
 	}
-	return strings.TrimSuffix(data.String(), "\n")
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return ""
+	}
+	return toYAMLPretty(jsonNumbers(generic))
+}
+
+// jsonNumbers replaces the json.Numbers of a decoded JSON value by integers,
+// or floats if they are not integers, so they are not marshaled as strings.
+func jsonNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = jsonNumbers(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = jsonNumbers(value)
+		}
+	}
+	return v
+}
+
+// toYAMLIndent is toYAMLPretty, indenting each level of the output by
+// spaces rather than 2.
+//
+// This is designed to be called from a template.
+func toYAMLIndent(spaces int, v interface{}) string {
+	data, err := encodeYAML(v, spaces)
+	if err != nil {
+		// Swallow errors inside of a template.
+		return ""
+	}
+	return data
+}
+
+// encodeYAML marshals v to yaml, indenting each level by spaces, without a
+// trailing newline.
+func encodeYAML(v interface{}, spaces int) (s string, err error) {
+	// The encoder panics on invalid indentations and some unsupported types.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("unable to marshal to yaml: %v", r)
+		}
+	}()
+
+	var data bytes.Buffer
+	encoder := goYaml.NewEncoder(&data)
+	encoder.SetIndent(spaces)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(data.String(), "\n"), nil
 }
 
 // fromYAML converts a YAML document into a map[string]interface{}.
@@ -203,6 +288,43 @@ func mustToJSON(v interface{}) string {
 	return string(data)
 }
 
+// toRawJSONIndent takes an interface, marshals it to json indenting each
+// level by spaces, without escaping HTML characters, and returns a string.
+// With 0 spaces, the output is compact. It will always return a string, even on marshal error (empty string).
+//
+// This is designed to be called from a template.
+func toRawJSONIndent(spaces int, v interface{}) string {
+	data, err := encodeRawJSON(v, spaces)
+	if err != nil {
+		// Swallow errors inside of a template.
+		return ""
+	}
+	return data
+}
+
+// mustToRawJSONIndent is toRawJSONIndent, panicking if there is an error.
+//
+// This is designed to be called from a template when need to ensure that the
+// output JSON is valid.
+func mustToRawJSONIndent(spaces int, v interface{}) string {
+	data, err := encodeRawJSON(v, spaces)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func encodeRawJSON(v interface{}, spaces int) (string, error) {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", strings.Repeat(" ", max(spaces, 0)))
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(data.String(), "\n"), nil
+}
+
 // fromJSON converts a JSON document into a map[string]interface{}.
 //
 // This is not a general-purpose JSON parser, and will not parse all valid
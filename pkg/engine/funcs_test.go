@@ -37,6 +37,29 @@ func TestFuncs(t *testing.T) {
 		tpl:    `{{ toYamlPretty . }}`,
 		expect: "baz:\n  - 1\n  - 2\n  - 3",
 		vars:   map[string]interface{}{"baz": []int{1, 2, 3}},
+	}, {
+		tpl:    `{{ toYamlPretty . }}`,
+		expect: "script: |-\n  set -e\n  echo done",
+		vars:   map[string]interface{}{"script": "set -e\necho done"},
+	}, {
+		tpl:    `{{ toYamlSorted . }}`,
+		expect: "alpha: 2\nzeta:\n  - 1.5\n  - b",
+		vars: struct {
+			Zeta  []interface{} `json:"zeta"`
+			Alpha int           `json:"alpha"`
+		}{Zeta: []interface{}{1.5, "b"}, Alpha: 2},
+	}, {
+		tpl:    `{{ toYamlIndent 4 . }}`,
+		expect: "foo:\n    bar:\n        - baz",
+		vars:   map[string]interface{}{"foo": map[string]interface{}{"bar": []string{"baz"}}},
+	}, {
+		tpl:    `{{ toRawJsonIndent 2 . }}`,
+		expect: "{\n  \"html\": \"<b>&</b>\"\n}",
+		vars:   map[string]interface{}{"html": "<b>&</b>"},
+	}, {
+		tpl:    `{{ mustToRawJsonIndent 0 . }}`,
+		expect: "[1,2]",
+		vars:   []int{1, 2},
 	}, {
 		tpl:    `{{ toToml . }}`,
 		expect: "foo = \"bar\"\n",
@@ -0,0 +1,185 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRenderTimeout is returned when rendering takes longer than the
+// TemplateTimeout or RenderTimeout of the engine.
+var ErrRenderTimeout = errors.New("rendering timed out")
+
+// ErrOutputTooLarge is returned when rendering produces more output than the
+// MaxOutputSize of the engine.
+var ErrOutputTooLarge = errors.New("rendered output too large")
+
+// renderLimits bounds the time taken and the output produced by a render.
+//
+// Once a limit is exceeded, the render is aborted: templates still running
+// stop at their next write or call to include, tpl or repeat.
+type renderLimits struct {
+	templateTimeout time.Duration
+	renderTimeout   time.Duration
+	// deadline is when the render times out, zero if it doesn't
+	deadline  time.Time
+	maxOutput int
+
+	mu    sync.Mutex
+	total int
+	err   error
+}
+
+// newRenderLimits returns the limits of a render starting now, or nil if the
+// engine sets none.
+func (e Engine) newRenderLimits() *renderLimits {
+	if e.TemplateTimeout <= 0 && e.RenderTimeout <= 0 && e.MaxOutputSize <= 0 {
+		return nil
+	}
+	l := &renderLimits{
+		templateTimeout: e.TemplateTimeout,
+		renderTimeout:   e.RenderTimeout,
+		maxOutput:       e.MaxOutputSize,
+	}
+	if e.RenderTimeout > 0 {
+		l.deadline = time.Now().Add(e.RenderTimeout)
+	}
+	return l
+}
+
+// abort aborts the render with err, unless it was already aborted, and
+// returns the error it was aborted with.
+func (l *renderLimits) abort(err error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil {
+		l.err = err
+	}
+	return l.err
+}
+
+// aborted returns the error the render was aborted with, if any.
+func (l *renderLimits) aborted() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// checkSize aborts the render if a single output of size bytes exceeds the
+// maximum output size.
+func (l *renderLimits) checkSize(name string, size int) error {
+	if l == nil || l.maxOutput <= 0 || size <= l.maxOutput {
+		return nil
+	}
+	return l.abort(fmt.Errorf("%w: %s exceeds %d bytes", ErrOutputTooLarge, name, l.maxOutput))
+}
+
+// add accounts for the output of a template, aborting the render if the
+// output of all the templates exceeds the maximum output size.
+func (l *renderLimits) add(size int) error {
+	if l == nil || l.maxOutput <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	l.total += size
+	total := l.total
+	l.mu.Unlock()
+	if total > l.maxOutput {
+		return l.abort(fmt.Errorf("%w: the rendered templates exceed %d bytes", ErrOutputTooLarge, l.maxOutput))
+	}
+	return nil
+}
+
+// execute runs exec, the execution of the template name, within
+// the deadlines of the render.
+//
+// A template still running at the deadline is abandoned. It stops at its next
+// write or call to include, tpl or repeat, but a loop doing neither keeps
+// running in the background.
+func (l *renderLimits) execute(name string, exec func() error) error {
+	deadline, perTemplate := l.deadline, false
+	if l.templateTimeout > 0 {
+		if d := time.Now().Add(l.templateTimeout); deadline.IsZero() || d.Before(deadline) {
+			deadline, perTemplate = d, true
+		}
+	}
+	if deadline.IsZero() {
+		return exec()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("rendering template failed: %v", r)
+			}
+		}()
+		done <- exec()
+	}()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		if perTemplate {
+			return l.abort(fmt.Errorf("%w: %s took longer than %s", ErrRenderTimeout, name, l.templateTimeout))
+		}
+		return l.abort(fmt.Errorf("%w: rendering the templates took longer than %s", ErrRenderTimeout, l.renderTimeout))
+	}
+}
+
+// limitedBuffer is the output of a template, include or tpl call, failing
+// writes once the render is aborted or the output exceeds the maximum size.
+type limitedBuffer struct {
+	name string
+	l    *renderLimits
+	buf  strings.Builder
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if err := b.l.aborted(); err != nil {
+		return 0, err
+	}
+	if err := b.l.checkSize(b.name, b.buf.Len()+len(p)); err != nil {
+		return 0, err
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// limitedRepeat is the repeat function, failing instead of allocating more
+// than the maximum output size.
+func (l *renderLimits) limitedRepeat(count int, str string) (string, error) {
+	if err := l.aborted(); err != nil {
+		return "", err
+	}
+	if count > 0 && int64(count)*int64(len(str)) > int64(l.maxOutput) {
+		return "", l.abort(fmt.Errorf("%w: repeat of %d times %d bytes exceeds %d bytes", ErrOutputTooLarge, count, len(str), l.maxOutput))
+	}
+	return strings.Repeat(str, count), nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/mitchellh/copystructure"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// renderParallel renders the files of the template set t using up to
+// Parallelism workers. Each worker executes its own copy of the set, so that
+// include and tpl are bound to it, and each file is rendered with its own
+// copy of the values, as they are given the name of the template being
+// rendered and templates may modify .Values with functions such as set.
+//
+// As when rendering the files one after the other, the error returned is that
+// of the first file, in order, failing to render.
+func (e Engine) renderParallel(t *template.Template, tpls map[string]renderable, files []string) (map[string]string, error) {
+	outs := make([]string, len(files))
+	errs := make([]error, len(files))
+
+	// failed is the index of the first file failing to render; the files
+	// after it are skipped
+	var failed, next atomic.Int64
+	failed.Store(int64(len(files)))
+	fail := func(i int) {
+		for {
+			f := failed.Load()
+			if int64(i) >= f || failed.CompareAndSwap(f, int64(i)) {
+				return
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for range min(e.Parallelism, len(files)) {
+		wt, err := t.Clone()
+		if err != nil {
+			return map[string]string{}, fmt.Errorf("cannot clone template: %w", err)
+		}
+		tr := e.initFunMap(wt)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(files) || int64(i) > failed.Load() || e.limits.aborted() != nil {
+					return
+				}
+				filename := files[i]
+				vals, err := copyRenderValues(tpls[filename].vals)
+				if err == nil {
+					outs[i], err = executeTemplateSafe(wt, filename, vals, tpls[filename].basePath, e.limits, tr)
+				}
+				if errs[i] = err; err != nil {
+					fail(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return map[string]string{}, err
+		}
+	}
+	rendered := make(map[string]string, len(files))
+	for i, filename := range files {
+		rendered[filename] = outs[i]
+	}
+	return rendered, nil
+}
+
+// copyRenderValues copies the values a template is rendered with. .Values is
+// copied deeply, the other values, which templates only read, are shared.
+func copyRenderValues(vals common.Values) (common.Values, error) {
+	out := maps.Clone(vals)
+	if v, ok := vals["Values"]; ok {
+		c, err := copystructure.Copy(v)
+		if err != nil {
+			return nil, fmt.Errorf("cannot copy values: %w", err)
+		}
+		out["Values"] = c
+	}
+	return out, nil
+}
+
+// executeTemplateSafe is executeTemplate, turning panics into errors as the
+// deferred recovery of render does not cover other goroutines. Fatal runtime
+// errors, such as concurrent map writes, cannot be recovered.
+func executeTemplateSafe(t *template.Template, filename string, vals common.Values, basePath string, l *renderLimits, tr *tracer) (out string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("rendering template failed: %v", r)
+		}
+	}()
+	return executeTemplate(t, filename, vals, basePath, l, tr)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"text/template"
+
+	"github.com/gobwas/glob"
+)
+
+// ErrNotAllowed is returned by template functions called against the
+// FuncPolicy of the engine.
+var ErrNotAllowed = errors.New("not allowed by the template function policy")
+
+// FuncPolicy restricts what the templates of a chart can do, so that charts
+// that are not trusted can be rendered safely, for example by a service
+// rendering the charts of several tenants.
+//
+// The zero value allows everything, as does a nil policy.
+type FuncPolicy struct {
+	// DisabledFuncs are the names of the template functions failing with
+	// ErrNotAllowed when called, such as "lookup" or "getHostByName". This
+	// includes the functions added with CustomTemplateFuncs. The include and
+	// tpl functions binding templates together cannot be disabled.
+	DisabledFuncs []string `json:"disabledFuncs,omitempty"`
+	// LookupNamespaces, if not empty, are the only namespaces lookup can read
+	// resources from. Lookups of cluster-scoped resources, or across all the
+	// namespaces, fail with ErrNotAllowed.
+	LookupNamespaces []string `json:"lookupNamespaces,omitempty"`
+	// Files, if not nil, are the glob patterns of the chart files templates
+	// can read through .Files, other files being hidden. An empty list hides
+	// all the files.
+	Files []string `json:"files,omitempty"`
+}
+
+// apply disables and restricts the functions of funcMap.
+func (p *FuncPolicy) apply(funcMap template.FuncMap) {
+	if p == nil {
+		return
+	}
+	if lookup, ok := funcMap["lookup"].(lookupFunc); ok && len(p.LookupNamespaces) > 0 {
+		funcMap["lookup"] = func(apiversion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+			if !slices.Contains(p.LookupNamespaces, namespace) {
+				if namespace == "" {
+					return map[string]interface{}{}, fmt.Errorf("lookup of %s outside of a namespace: %w", kind, ErrNotAllowed)
+				}
+				return map[string]interface{}{}, fmt.Errorf("lookup of %s in namespace %q: %w", kind, namespace, ErrNotAllowed)
+			}
+			return lookup(apiversion, kind, namespace, name)
+		}
+	}
+	for _, name := range p.DisabledFuncs {
+		if name == "include" || name == "tpl" {
+			slog.Warn("template function cannot be disabled", "function", name)
+			continue
+		}
+		funcMap[name] = func(...interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("%s: %w", name, ErrNotAllowed)
+		}
+	}
+}
+
+// filterFiles hides the files of the templates that do not match the Files
+// patterns of the policy.
+func (p *FuncPolicy) filterFiles(tpls map[string]renderable) error {
+	if p == nil || p.Files == nil {
+		return nil
+	}
+	patterns := make([]glob.Glob, 0, len(p.Files))
+	for _, pattern := range p.Files {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, g)
+	}
+
+	// The templates of a chart share their values, also reachable from
+	// those of its parent through .Subcharts
+	var filter func(vals map[string]interface{})
+	filter = func(vals map[string]interface{}) {
+		if all, ok := vals["Files"].(files); ok {
+			allowed := make(files, len(all))
+			for name, data := range all {
+				if slices.ContainsFunc(patterns, func(g glob.Glob) bool { return g.Match(name) }) {
+					allowed[name] = data
+				}
+			}
+			vals["Files"] = allowed
+		}
+		if subcharts, ok := vals["Subcharts"].(map[string]interface{}); ok {
+			for _, sub := range subcharts {
+				if sub, ok := sub.(map[string]interface{}); ok {
+					filter(sub)
+				}
+			}
+		}
+	}
+	for _, r := range tpls {
+		filter(r.vals)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	ci "helm.sh/helm/v4/pkg/chart"
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// RawDir is the chart directory whose manifests are included in the rendered
+// output verbatim, without template processing. It is meant for large static
+// manifests whose contents must never be interpreted.
+const RawDir = "raw/"
+
+// RawFilesKey is the values key of a chart listing the files of its raw/
+// directory to include, as path.Match patterns relative to raw/. All of them
+// are included if it is not set.
+const RawFilesKey = "rawFiles"
+
+// IsRawManifest reports whether the chart file is a raw manifest. Only YAML
+// and JSON files in raw/ are included in the rendered output.
+func IsRawManifest(name string) bool {
+	if !strings.HasPrefix(name, RawDir) {
+		return false
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// allRawFiles returns the raw manifests of a chart and its dependencies
+// selected by their values, keyed like rendered templates.
+func allRawFiles(c ci.Charter, vals common.Values) (map[string]string, error) {
+	raw := make(map[string]string)
+	scope, err := vals.Table("Values")
+	if err != nil {
+		scope = common.Values{}
+	}
+	return raw, recRawFiles(c, scope, raw)
+}
+
+// recRawFiles recurses through the raw manifests of a chart, scoping the
+// values to each subchart as the templates are.
+func recRawFiles(c ci.Charter, vals common.Values, raw map[string]string) error {
+	accessor, err := ci.NewAccessor(c)
+	if err != nil {
+		return err
+	}
+
+	// Like their templates, the files of library charts are never rendered.
+	if !accessor.IsLibraryChart() {
+		patterns, err := rawFilePatterns(vals)
+		if err != nil {
+			return fmt.Errorf("chart %s: %w", accessor.ChartFullPath(), err)
+		}
+		for _, f := range accessor.Files() {
+			if f == nil || !IsRawManifest(f.Name) {
+				continue
+			}
+			if patterns != nil && !matchRawFile(patterns, strings.TrimPrefix(f.Name, RawDir)) {
+				continue
+			}
+			raw[path.Join(accessor.ChartFullPath(), f.Name)] = string(f.Data)
+		}
+	}
+
+	for _, child := range accessor.Dependencies() {
+		sub, err := ci.NewAccessor(child)
+		if err != nil {
+			return err
+		}
+		subVals, err := vals.Table(sub.Name())
+		if err != nil {
+			subVals = common.Values{}
+		}
+		if err := recRawFiles(child, subVals, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rawFilePatterns returns the patterns of the rawFiles value, or nil if it is
+// not set.
+func rawFilePatterns(vals common.Values) ([]string, error) {
+	v, ok := vals[RawFilesKey]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of file patterns, got %T", RawFilesKey, v)
+	}
+	patterns := make([]string, 0, len(list))
+	for _, p := range list {
+		s, ok := p.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of file patterns, got %v", RawFilesKey, p)
+		}
+		if _, err := path.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %w", RawFilesKey, s, err)
+		}
+		patterns = append(patterns, s)
+	}
+	return patterns, nil
+}
+
+func matchRawFile(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"helm.sh/helm/v4/pkg/chart/common"
+)
+
+// ErrUndefinedValue is returned by renders with StrictValues referencing a
+// value that is not defined.
+var ErrUndefinedValue = errors.New("undefined value")
+
+// strictValueFunc is the function the references to values are rewritten to
+// call with StrictValues.
+const strictValueFunc = "helmStrictValue"
+
+// strictValuesAllowed are the functions whose arguments may reference values
+// that are not defined with StrictValues, as they handle them.
+var strictValuesAllowed = map[string]bool{
+	"default":  true,
+	"hasKey":   true,
+	"empty":    true,
+	"coalesce": true,
+	"required": true,
+}
+
+// strictValues rewrites the references to .Values.a.b and $.Values.a.b in
+// the templates of t to calls of strictValueFunc failing if a.b is not
+// defined, except for the arguments of the functions handling undefined
+// values, such as default.
+func strictValues(t *template.Template) {
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree != nil {
+			strictValuesNode(tmpl.Tree.Root)
+		}
+	}
+}
+
+func strictValuesNode(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			strictValuesNode(c)
+		}
+	case *parse.ActionNode:
+		strictValuesNode(n.Pipe)
+	case *parse.IfNode:
+		strictValuesBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		strictValuesBranch(&n.BranchNode)
+	case *parse.WithNode:
+		strictValuesBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		strictValuesNode(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			// .Values.a | default "b"
+			if i+1 < len(n.Cmds) && strictValuesAllows(n.Cmds[i+1]) {
+				continue
+			}
+			strictValuesNode(cmd)
+		}
+	case *parse.CommandNode:
+		if strictValuesAllows(n) {
+			return
+		}
+		for i, arg := range n.Args {
+			if path, base, ok := strictValuesRef(arg); ok {
+				n.Args[i] = strictValueCall(arg.Position(), base, path)
+			} else {
+				strictValuesNode(arg)
+			}
+		}
+	case *parse.ChainNode:
+		strictValuesNode(n.Node)
+	}
+}
+
+func strictValuesBranch(n *parse.BranchNode) {
+	strictValuesNode(n.Pipe)
+	strictValuesNode(n.List)
+	strictValuesNode(n.ElseList)
+}
+
+// strictValuesAllows returns true if cmd calls a function handling values
+// that are not defined.
+func strictValuesAllows(cmd *parse.CommandNode) bool {
+	if len(cmd.Args) == 0 {
+		return false
+	}
+	id, ok := cmd.Args[0].(*parse.IdentifierNode)
+	return ok && strictValuesAllowed[id.Ident]
+}
+
+// strictValuesRef returns the path of the values referenced by node, and the
+// node referencing .Values itself.
+func strictValuesRef(node parse.Node) (string, parse.Node, bool) {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "Values" {
+			return strings.Join(n.Ident[1:], "."), &parse.FieldNode{NodeType: parse.NodeField, Pos: n.Pos, Ident: []string{"Values"}}, true
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 2 && n.Ident[0] == "$" && n.Ident[1] == "Values" {
+			return strings.Join(n.Ident[2:], "."), &parse.VariableNode{NodeType: parse.NodeVariable, Pos: n.Pos, Ident: []string{"$", "Values"}}, true
+		}
+	}
+	return "", nil, false
+}
+
+// strictValueCall returns the node calling strictValueFunc with base and
+// path, as (helmStrictValue .Values "a.b").
+func strictValueCall(pos parse.Pos, base parse.Node, path string) parse.Node {
+	return &parse.PipeNode{
+		NodeType: parse.NodePipe,
+		Pos:      pos,
+		Cmds: []*parse.CommandNode{{
+			NodeType: parse.NodeCommand,
+			Pos:      pos,
+			Args: []parse.Node{
+				parse.NewIdentifier(strictValueFunc).SetPos(pos),
+				base,
+				&parse.StringNode{NodeType: parse.NodeString, Pos: pos, Quoted: strconv.Quote(path), Text: path},
+			},
+		}},
+	}
+}
+
+// strictValue returns the value at path in values, failing if it is not
+// defined.
+func strictValue(values interface{}, path string) (interface{}, error) {
+	// Outside of the top-level scope, .Values is not that of the chart
+	if values == nil {
+		return nil, nil
+	}
+	cur := values
+	keys := strings.Split(path, ".")
+	for i, key := range keys {
+		var m map[string]interface{}
+		switch v := cur.(type) {
+		case common.Values:
+			m = v
+		case map[string]interface{}:
+			m = v
+		default:
+			if i == 0 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%w: .Values.%s is not a map, so .Values.%s is not defined", ErrUndefinedValue, strings.Join(keys[:i], "."), path)
+		}
+		next, ok := m[key]
+		if !ok {
+			return nil, fmt.Errorf("%w .Values.%s: use default or hasKey for optional values", ErrUndefinedValue, path)
+		}
+		cur = next
+	}
+	return cur, nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// RenderTrace records how the templates of a chart were rendered. Set as the
+// Trace of an engine, it is reset and filled by every render.
+//
+// Renders are not looked up in the RenderCache of a tracing engine.
+type RenderTrace struct {
+	// Duration is the time the render took, parsing included.
+	Duration time.Duration `json:"duration"`
+	// Templates are the templates executed, by name.
+	Templates []*TemplateTrace `json:"templates"`
+
+	mu sync.Mutex
+	// paths are the values paths referenced by each template
+	paths map[string][]string
+}
+
+// TemplateTrace records the execution of a template, or of a template it
+// includes.
+type TemplateTrace struct {
+	Name string `json:"name"`
+	// Duration is the wall time of the execution, includes included.
+	Duration time.Duration `json:"duration"`
+	// ValuesPaths are the paths of the values referenced by the template
+	// itself, such as "image.tag" for .Values.image.tag. Values referenced
+	// relatively to another value, in a with or range block, are not listed.
+	ValuesPaths []string `json:"valuesPaths,omitempty"`
+	// Includes are the templates included, in call order.
+	Includes []*TemplateTrace `json:"includes,omitempty"`
+}
+
+// Slowest returns the n templates, or all of them if n is zero, that took
+// the longest to execute.
+func (r *RenderTrace) Slowest(n int) []*TemplateTrace {
+	slowest := slices.Clone(r.Templates)
+	slices.SortStableFunc(slowest, func(a, b *TemplateTrace) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	if n > 0 && n < len(slowest) {
+		slowest = slowest[:n]
+	}
+	return slowest
+}
+
+// reset prepares the trace for a new render.
+func (r *RenderTrace) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Duration = 0
+	r.Templates = nil
+	r.paths = make(map[string][]string)
+}
+
+// finish records the duration of the render, and sorts the templates.
+func (r *RenderTrace) finish(start time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Duration = time.Since(start)
+	slices.SortFunc(r.Templates, func(a, b *TemplateTrace) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// valuesPaths returns the values paths referenced by the template name of t.
+func (r *RenderTrace) valuesPaths(t *template.Template, name string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if paths, ok := r.paths[name]; ok {
+		return paths
+	}
+	var paths []string
+	if tmpl := t.Lookup(name); tmpl != nil && tmpl.Tree != nil {
+		paths = treeValuesPaths(tmpl.Tree.Root)
+	}
+	r.paths[name] = paths
+	return paths
+}
+
+// tracer records the executions of the templates of a template set, which
+// happen one at a time.
+type tracer struct {
+	trace *RenderTrace
+	// stack are the templates being executed, the outermost first
+	stack []*TemplateTrace
+}
+
+// newTracer returns a tracer recording the executions of a template set into
+// the trace of the engine, or nil if the engine does not trace.
+func (e Engine) newTracer() *tracer {
+	if e.Trace == nil {
+		return nil
+	}
+	return &tracer{trace: e.Trace}
+}
+
+// begin records the start of the execution of the template name of t,
+// returning a function recording its end.
+func (tr *tracer) begin(t *template.Template, name string) func() {
+	if tr == nil {
+		return func() {}
+	}
+	tt := &TemplateTrace{Name: name, ValuesPaths: tr.trace.valuesPaths(t, name)}
+	if len(tr.stack) == 0 {
+		tr.trace.mu.Lock()
+		tr.trace.Templates = append(tr.trace.Templates, tt)
+		tr.trace.mu.Unlock()
+	} else {
+		parent := tr.stack[len(tr.stack)-1]
+		parent.Includes = append(parent.Includes, tt)
+	}
+	tr.stack = append(tr.stack, tt)
+
+	start := time.Now()
+	return func() {
+		tt.Duration = time.Since(start)
+		tr.stack = tr.stack[:len(tr.stack)-1]
+	}
+}
+
+// treeValuesPaths returns the sorted paths of the values referenced by the
+// nodes under node, as .Values.a.b or $.Values.a.b.
+func treeValuesPaths(node parse.Node) []string {
+	seen := make(map[string]bool)
+	var walk func(parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.ChainNode:
+			if p, ok := valuesPath(n.Node); ok {
+				seen[strings.Join(append([]string{p}, n.Field...), ".")] = true
+			} else {
+				walk(n.Node)
+			}
+		case *parse.FieldNode, *parse.VariableNode:
+			if p, ok := valuesPath(n); ok && p != "" {
+				seen[p] = true
+			}
+		}
+	}
+	walk(node)
+
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// valuesPath returns the path of the values referenced by node, empty for
+// .Values itself, if node references values.
+func valuesPath(node parse.Node) (string, bool) {
+	var ident []string
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		ident = n.Ident
+	case *parse.VariableNode:
+		if len(n.Ident) == 0 || n.Ident[0] != "$" {
+			return "", false
+		}
+		ident = n.Ident[1:]
+	case *parse.PipeNode:
+		// (.Values.a).b
+		if len(n.Decl) == 0 && len(n.Cmds) == 1 && len(n.Cmds[0].Args) == 1 {
+			return valuesPath(n.Cmds[0].Args[0])
+		}
+		return "", false
+	default:
+		return "", false
+	}
+	if len(ident) == 0 || ident[0] != "Values" {
+		return "", false
+	}
+	return strings.Join(ident[1:], "."), true
+}
@@ -18,6 +18,7 @@ package getter
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
@@ -49,6 +50,7 @@ type getterOptions struct {
 	timeout               time.Duration
 	transport             *http.Transport
 	artifactType          string
+	ifModifiedSince       time.Time
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -145,13 +147,26 @@ func WithTransport(transport *http.Transport) Option {
 	}
 }
 
-// WithArtifactType sets the type of OCI artifact ("chart" or "plugin")
+// WithArtifactType sets the type of OCI artifact ("chart", "plugin" or "values")
 func WithArtifactType(artifactType string) Option {
 	return func(opts *getterOptions) {
 		opts.artifactType = artifactType
 	}
 }
 
+// WithIfModifiedSince makes the request conditional: getters which support it
+// return ErrNotModified if the content did not change since the given time.
+// The zero time makes the request unconditional.
+func WithIfModifiedSince(t time.Time) Option {
+	return func(opts *getterOptions) {
+		opts.ifModifiedSince = t
+	}
+}
+
+// ErrNotModified is returned by conditional requests when the content did not
+// change.
+var ErrNotModified = errors.New("not modified")
+
 // Getter is an interface to support GET to the specified URL.
 type Getter interface {
 	// Get file content by url string
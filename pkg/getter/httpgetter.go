@@ -55,6 +55,10 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		req.Header.Set("Accept", g.opts.acceptHeader)
 	}
 
+	if !g.opts.ifModifiedSince.IsZero() {
+		req.Header.Set("If-Modified-Since", g.opts.ifModifiedSince.UTC().Format(http.TimeFormat))
+	}
+
 	req.Header.Set("User-Agent", version.GetUserAgent())
 	if g.opts.userAgent != "" {
 		req.Header.Set("User-Agent", g.opts.userAgent)
@@ -90,6 +94,9 @@ func (g *HTTPGetter) get(href string) (*bytes.Buffer, error) {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified && !g.opts.ifModifiedSince.IsZero() {
+		return nil, ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to fetch %s : %s", href, resp.Status)
 	}
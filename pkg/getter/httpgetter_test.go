@@ -16,6 +16,7 @@ limitations under the License.
 package getter
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -678,3 +679,27 @@ func TestHTTPTransportOption(t *testing.T) {
 		t.Fatal("transport.TLSClientConfig should not be set")
 	}
 }
+
+func TestHTTPGetterIfModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "index.yaml", modified, strings.NewReader("apiVersion: v1"))
+	}))
+	defer srv.Close()
+
+	g, err := NewHTTPGetter(WithURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.Get(srv.URL, WithIfModifiedSince(modified.Add(time.Hour))); !errors.Is(err, ErrNotModified) {
+		t.Errorf("expected ErrNotModified, got %v", err)
+	}
+	if got, err := g.Get(srv.URL, WithIfModifiedSince(modified.Add(-time.Hour))); err != nil || got.String() != "apiVersion: v1" {
+		t.Errorf("expected the content, got %q, %v", got, err)
+	}
+	// The zero time makes the request unconditional again.
+	if got, err := g.Get(srv.URL, WithIfModifiedSince(time.Time{})); err != nil || got.String() != "apiVersion: v1" {
+		t.Errorf("expected the content, got %q, %v", got, err)
+	}
+}
@@ -67,6 +67,13 @@ func (g *OCIGetter) get(href string) (*bytes.Buffer, error) {
 	if g.opts.artifactType == "plugin" {
 		return g.getPlugin(client, ref)
 	}
+	if g.opts.artifactType == "values" {
+		data, err := client.PullValues(ref)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewBuffer(data), nil
+	}
 
 	// Default to chart behavior for backward compatibility
 	var pullOpts []registry.PullOption
@@ -58,6 +58,11 @@ Example:
 	# Match any file named ab.txt, ac.txt, or ad.txt
 	a[b-d].txt
 
+Ignore files may also be placed in subdirectories. As with .gitignore, their
+patterns only apply to the paths under that subdirectory, relative to it, so
+'/*.txt' in sub/.helmignore matches sub/a.txt but neither a.txt nor
+sub/dir/a.txt. ParseDir reads the ignore files of a directory tree.
+
 Notable differences from .gitignore:
   - The '**' syntax is not supported.
   - The globbing library is Go's 'filepath.Match', not fnmatch(3)
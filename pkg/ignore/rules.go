@@ -20,11 +20,16 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"helm.sh/helm/v4/internal/sympath"
 )
 
 // HelmIgnore default name of an ignorefile.
@@ -32,10 +37,18 @@ const HelmIgnore = ".helmignore"
 
 // Rules is a collection of path matching rules.
 //
-// Parse() and ParseFile() will construct and populate new Rules.
+// Parse(), ParseFile() and ParseDir() will construct and populate new Rules.
 // Empty() will create an immutable empty ruleset.
 type Rules struct {
 	patterns []*pattern
+	// nested are the rules of the ignore files of subdirectories.
+	nested []nestedRules
+}
+
+// nestedRules are the rules of the ignore file of the subdirectory dir.
+type nestedRules struct {
+	dir   string
+	rules *Rules
 }
 
 // Empty builds an empty ruleset.
@@ -60,6 +73,59 @@ func ParseFile(file string) (*Rules, error) {
 	return Parse(f)
 }
 
+// ParseDir parses the ignore file of the directory dir, if any, and those of
+// its subdirectories, and returns the combined *Rules.
+//
+// As with .gitignore, the rules of an ignore file in a subdirectory only
+// apply to the paths under that subdirectory, and are matched against the
+// paths relative to it: "/foo" in sub/.helmignore matches sub/foo. A path is
+// ignored if the rules of any of the ignore files applying to it ignore it.
+// The ignore files of ignored directories are not read.
+func ParseDir(dir string) (*Rules, error) {
+	r := Empty()
+	err := sympath.Walk(dir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if r.Ignore(rel, fi) {
+			return filepath.SkipDir
+		}
+
+		nested, err := ParseFile(filepath.Join(name, HelmIgnore))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path.Join(rel, HelmIgnore), err)
+		}
+		if rel == "." {
+			r.patterns = nested.patterns
+		} else {
+			r.AddDir(rel, nested)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddDir adds the rules of the ignore file of the subdirectory dir, given
+// relative to the directory of r with forward slashes. They only apply to the
+// paths under dir, which they are matched against relative to dir.
+func (r *Rules) AddDir(dir string, rules *Rules) {
+	r.nested = append(r.nested, nestedRules{dir: strings.Trim(dir, "/"), rules: rules})
+}
+
 // Parse parses a rules file
 func Parse(file io.Reader) (*Rules, error) {
 	r := &Rules{patterns: []*pattern{}}
@@ -99,6 +165,11 @@ func (r *Rules) Ignore(path string, fi os.FileInfo) bool {
 	if path == "." || path == "./" {
 		return false
 	}
+	for _, n := range r.nested {
+		if rel, ok := strings.CutPrefix(path, n.dir+"/"); ok && n.rules.Ignore(rel, fi) {
+			return true
+		}
+	}
 	for _, p := range r.patterns {
 		if p.match == nil {
 			slog.Info("this will be ignored no matcher supplied", "patterns", p.raw)
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -140,6 +141,86 @@ func TestIgnore(t *testing.T) {
 	}
 }
 
+func TestNestedRules(t *testing.T) {
+	r, err := parseString("tiller.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := parseString("/a.txt\nb.txt\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.AddDir("cargo", nested)
+
+	tests := []struct {
+		name   string
+		expect bool
+	}{
+		{"tiller.txt", true},
+		{"cargo/a.txt", true},
+		{"cargo/b.txt", true},
+		{"cargo/c.txt", false},
+		{"cargo", false},
+		// The nested rules do not apply outside of their directory.
+		{"a.txt", false},
+		{"mast/a.txt", false},
+		{"mast/b.txt", false},
+	}
+	for _, test := range tests {
+		fi, err := os.Stat(filepath.Join(testdata, test.name))
+		if err != nil {
+			t.Fatalf("Fixture missing: %s", err)
+		}
+		if r.Ignore(test.name, fi) != test.expect {
+			t.Errorf("Expected %q to be %v", test.name, test.expect)
+		}
+	}
+}
+
+func TestParseDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		HelmIgnore:                     "skipped/\n",
+		"a/" + HelmIgnore:              "/*.txt\n",
+		"a/b/" + HelmIgnore:            "*.yaml\n",
+		"a/b/c.yaml":                   "",
+		"skipped/" + HelmIgnore:        "**\n",
+		"skipped/nested/" + HelmIgnore: "**\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The invalid ignore files of ignored directories are not read.
+	r, err := ParseDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.patterns) != 1 || len(r.nested) != 2 {
+		t.Fatalf("Expected 1 pattern and 2 nested rulesets, got %d and %d", len(r.patterns), len(r.nested))
+	}
+	fi, err := os.Stat(filepath.Join(dir, "a", "b", "c.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Ignore("a/b/c.yaml", fi) {
+		t.Error("Expected a/b/c.yaml to be ignored")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "a", HelmIgnore), []byte("**\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseDir(dir); err == nil || !strings.HasPrefix(err.Error(), "a/.helmignore: ") {
+		t.Errorf("Expected an error naming a/.helmignore, got %v", err)
+	}
+}
+
 func TestAddDefaults(t *testing.T) {
 	r := Rules{}
 	r.AddDefaults()
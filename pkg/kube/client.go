@@ -210,6 +210,7 @@ type clientCreateOptions struct {
 	forceConflicts           bool
 	dryRun                   bool
 	fieldValidationDirective FieldValidationDirective
+	fieldManager             string
 }
 
 type ClientCreateOption func(*clientCreateOptions) error
@@ -254,6 +255,19 @@ func ClientCreateOptionFieldValidationDirective(fieldValidationDirective FieldVa
 	}
 }
 
+// ClientCreateOptionFieldManager sets the name of the field manager the
+// resources are created with. It defaults to ManagedFieldsManager, or the
+// name of the program if that is not set.
+func ClientCreateOptionFieldManager(fieldManager string) ClientCreateOption {
+	return func(o *clientCreateOptions) error {
+		if fieldManager != "" {
+			o.fieldManager = fieldManager
+		}
+
+		return nil
+	}
+}
+
 // Create creates Kubernetes resources specified in the resource list.
 func (c *Client) Create(resources ResourceList, options ...ClientCreateOption) (*Result, error) {
 	slog.Debug("creating resource(s)", "resources", len(resources))
@@ -261,6 +275,7 @@ func (c *Client) Create(resources ResourceList, options ...ClientCreateOption) (
 	createOptions := clientCreateOptions{
 		serverSideApply:          true, // Default to server-side apply
 		fieldValidationDirective: FieldValidationDirectiveStrict,
+		fieldManager:             getManagedFieldsManager(),
 	}
 
 	errs := make([]error, 0, len(options))
@@ -275,32 +290,54 @@ func (c *Client) Create(resources ResourceList, options ...ClientCreateOption) (
 		return nil, fmt.Errorf("invalid operation: force conflicts can only be used with server-side apply")
 	}
 
-	makeCreateApplyFunc := func() func(target *resource.Info) error {
-		if createOptions.serverSideApply {
-			slog.Debug("using server-side apply for resource creation", slog.Bool("forceConflicts", createOptions.forceConflicts), slog.Bool("dryRun", createOptions.dryRun), slog.String("fieldValidationDirective", string(createOptions.fieldValidationDirective)))
-			return func(target *resource.Info) error {
-				err := patchResourceServerSide(target, createOptions.dryRun, createOptions.forceConflicts, createOptions.fieldValidationDirective)
+	serverSideApplyFunc := func(target *resource.Info) error {
+		err := patchResourceServerSide(target, createOptions.fieldManager, createOptions.dryRun, createOptions.forceConflicts, createOptions.fieldValidationDirective)
 
-				logger := slog.With(
-					slog.String("namespace", target.Namespace),
-					slog.String("name", target.Name),
-					slog.String("gvk", target.Mapping.GroupVersionKind.String()))
-				if err != nil {
-					logger.Debug("Error patching resource", slog.Any("error", err))
-					return err
-				}
+		logger := slog.With(
+			slog.String("namespace", target.Namespace),
+			slog.String("name", target.Name),
+			slog.String("gvk", target.Mapping.GroupVersionKind.String()))
+		if err != nil {
+			logger.Debug("Error patching resource", slog.Any("error", err))
+			return err
+		}
 
-				logger.Debug("Patched resource")
+		logger.Debug("Patched resource")
 
-				return nil
-			}
+		return nil
+	}
+
+	createFunc := func(target *resource.Info) error {
+		return createResource(target, createOptions.fieldManager)
+	}
+
+	makeCreateApplyFunc := func() func(target *resource.Info) error {
+		if createOptions.serverSideApply {
+			slog.Debug("using server-side apply for resource creation", slog.Bool("forceConflicts", createOptions.forceConflicts), slog.Bool("dryRun", createOptions.dryRun), slog.String("fieldValidationDirective", string(createOptions.fieldValidationDirective)))
+			return serverSideApplyFunc
 		}
 
 		slog.Debug("using client-side apply for resource creation")
-		return createResource
+		return createFunc
 	}
 
-	if err := perform(resources, makeCreateApplyFunc()); err != nil {
+	if err := validateApplyAnnotations(resources); err != nil {
+		return nil, err
+	}
+
+	defaultFunc := makeCreateApplyFunc()
+	if err := perform(resources, func(target *resource.Info) error {
+		// Resources requesting server-side apply are created with it, so their
+		// fields are owned by Helm from the start. The other strategies only
+		// differ once the resource exists.
+		switch policy, _ := applyAnnotations(target); policy.strategy {
+		case ApplyStrategyServerSide:
+			return serverSideApplyFunc(target)
+		case ApplyStrategyReplace, ApplyStrategyMerge:
+			return createFunc(target)
+		}
+		return defaultFunc(target)
+	}); err != nil {
 		return nil, err
 	}
 	return &Result{Created: resources}, nil
@@ -502,7 +539,7 @@ func (c *Client) BuildTable(reader io.Reader, validate bool) (ResourceList, erro
 		transformRequests)
 }
 
-func (c *Client) update(originals, targets ResourceList, updateApplyFunc UpdateApplyFunc) (*Result, error) {
+func (c *Client) update(originals, targets ResourceList, fieldManager string, updateApplyFunc UpdateApplyFunc) (*Result, error) {
 	updateErrors := []error{}
 	res := &Result{}
 
@@ -512,7 +549,7 @@ func (c *Client) update(originals, targets ResourceList, updateApplyFunc UpdateA
 			return err
 		}
 
-		helper := resource.NewHelper(target.Client, target.Mapping).WithFieldManager(getManagedFieldsManager())
+		helper := resource.NewHelper(target.Client, target.Mapping).WithFieldManager(fieldManager)
 		if _, err := helper.Get(target.Namespace, target.Name); err != nil {
 			if !apierrors.IsNotFound(err) {
 				return fmt.Errorf("could not get information about the resource: %w", err)
@@ -522,7 +559,7 @@ func (c *Client) update(originals, targets ResourceList, updateApplyFunc UpdateA
 			res.Created = append(res.Created, target)
 
 			// Since the resource does not exist, create it.
-			if err := createResource(target); err != nil {
+			if err := createResource(target, fieldManager); err != nil {
 				return fmt.Errorf("failed to create resource: %w", err)
 			}
 
@@ -586,6 +623,8 @@ type clientUpdateOptions struct {
 	dryRun                        bool
 	fieldValidationDirective      FieldValidationDirective
 	upgradeClientSideFieldManager bool
+	forceRecreate                 []string
+	fieldManager                  string
 }
 
 type ClientUpdateOption func(*clientUpdateOptions) error
@@ -629,6 +668,22 @@ func ClientUpdateOptionForceReplace(forceReplace bool) ClientUpdateOption {
 	}
 }
 
+// ClientUpdateOptionForceRecreate deletes and recreates the given resources
+// when their update is rejected because of immutable fields. Resources are
+// given as "kind/name", with the kind matched case-insensitively.
+func ClientUpdateOptionForceRecreate(resources []string) ClientUpdateOption {
+	return func(o *clientUpdateOptions) error {
+		for _, r := range resources {
+			if _, _, err := parseRecreateSelector(r); err != nil {
+				return err
+			}
+		}
+		o.forceRecreate = resources
+
+		return nil
+	}
+}
+
 // ClientUpdateOptionDryRun requests the server to perform non-mutating operations only
 func ClientUpdateOptionDryRun(dryRun bool) ClientUpdateOption {
 	return func(o *clientUpdateOptions) error {
@@ -669,6 +724,20 @@ func ClientUpdateOptionUpgradeClientSideFieldManager(upgradeClientSideFieldManag
 	}
 }
 
+// ClientUpdateOptionFieldManager sets the name of the field manager the
+// resources are updated with, which owns the fields applied server-side. It
+// defaults to ManagedFieldsManager, or the name of the program if that is not
+// set.
+func ClientUpdateOptionFieldManager(fieldManager string) ClientUpdateOption {
+	return func(o *clientUpdateOptions) error {
+		if fieldManager != "" {
+			o.fieldManager = fieldManager
+		}
+
+		return nil
+	}
+}
+
 type UpdateApplyFunc func(original, target *resource.Info) error
 
 // Update takes the current list of objects and target list of objects and
@@ -684,6 +753,7 @@ func (c *Client) Update(originals, targets ResourceList, options ...ClientUpdate
 	updateOptions := clientUpdateOptions{
 		serverSideApply:          true, // Default to server-side apply
 		fieldValidationDirective: FieldValidationDirectiveStrict,
+		fieldManager:             getManagedFieldsManager(),
 	}
 
 	errs := make([]error, 0, len(options))
@@ -706,23 +776,58 @@ func (c *Client) Update(originals, targets ResourceList, options ...ClientUpdate
 		return nil, fmt.Errorf("invalid operation: cannot use server-side apply and force replace together")
 	}
 
+	replaceFunc := func(original, target *resource.Info) error {
+		if err := replaceResource(target, updateOptions.fieldManager, updateOptions.fieldValidationDirective); err != nil {
+			slog.Debug("error replacing the resource", "namespace", target.Namespace, "name", target.Name, "kind", target.Mapping.GroupVersionKind.Kind, slog.Any("error", err))
+			return err
+		}
+
+		originalObject := original.Object
+		kind := target.Mapping.GroupVersionKind.Kind
+		slog.Debug("replace succeeded", "name", original.Name, "initialKind", originalObject.GetObjectKind().GroupVersionKind().Kind, "kind", kind)
+
+		return nil
+	}
+
+	serverSideApplyFunc := func(original, target *resource.Info) error {
+
+		logger := slog.With(
+			slog.String("namespace", target.Namespace),
+			slog.String("name", target.Name),
+			slog.String("gvk", target.Mapping.GroupVersionKind.String()))
+
+		if updateOptions.upgradeClientSideFieldManager {
+			patched, err := upgradeClientSideFieldManager(original, updateOptions.fieldManager, updateOptions.dryRun, updateOptions.fieldValidationDirective)
+			if err != nil {
+				slog.Debug("Error patching resource to replace CSA field management", slog.Any("error", err))
+				return err
+			}
+
+			if patched {
+				logger.Debug("Upgraded object client-side field management with server-side apply field management")
+			}
+		}
+
+		if err := patchResourceServerSide(target, updateOptions.fieldManager, updateOptions.dryRun, updateOptions.forceConflicts, updateOptions.fieldValidationDirective); err != nil {
+			logger.Debug("Error patching resource", slog.Any("error", err))
+			return err
+		}
+
+		logger.Debug("Patched resource")
+
+		return nil
+	}
+
+	clientSideApplyFunc := func(original, target *resource.Info) error {
+		return patchResourceClientSide(original.Object, target, updateOptions.fieldManager, updateOptions.threeWayMergeForUnstructured)
+	}
+
 	makeUpdateApplyFunc := func() UpdateApplyFunc {
 		if updateOptions.forceReplace {
 			slog.Debug(
 				"using resource replace update strategy",
 				slog.String("fieldValidationDirective", string(updateOptions.fieldValidationDirective)))
-			return func(original, target *resource.Info) error {
-				if err := replaceResource(target, updateOptions.fieldValidationDirective); err != nil {
-					slog.Debug("error replacing the resource", "namespace", target.Namespace, "name", target.Name, "kind", target.Mapping.GroupVersionKind.Kind, slog.Any("error", err))
-					return err
-				}
-
-				originalObject := original.Object
-				kind := target.Mapping.GroupVersionKind.Kind
-				slog.Debug("replace succeeded", "name", original.Name, "initialKind", originalObject.GetObjectKind().GroupVersionKind().Kind, "kind", kind)
-
-				return nil
-			}
+			return replaceFunc
 		} else if updateOptions.serverSideApply {
 			slog.Debug(
 				"using server-side apply for resource update",
@@ -730,43 +835,57 @@ func (c *Client) Update(originals, targets ResourceList, options ...ClientUpdate
 				slog.Bool("dryRun", updateOptions.dryRun),
 				slog.String("fieldValidationDirective", string(updateOptions.fieldValidationDirective)),
 				slog.Bool("upgradeClientSideFieldManager", updateOptions.upgradeClientSideFieldManager))
-			return func(original, target *resource.Info) error {
-
-				logger := slog.With(
-					slog.String("namespace", target.Namespace),
-					slog.String("name", target.Name),
-					slog.String("gvk", target.Mapping.GroupVersionKind.String()))
-
-				if updateOptions.upgradeClientSideFieldManager {
-					patched, err := upgradeClientSideFieldManager(original, updateOptions.dryRun, updateOptions.fieldValidationDirective)
-					if err != nil {
-						slog.Debug("Error patching resource to replace CSA field management", slog.Any("error", err))
-						return err
-					}
-
-					if patched {
-						logger.Debug("Upgraded object client-side field management with server-side apply field management")
-					}
-				}
+			return serverSideApplyFunc
+		}
 
-				if err := patchResourceServerSide(target, updateOptions.dryRun, updateOptions.forceConflicts, updateOptions.fieldValidationDirective); err != nil {
-					logger.Debug("Error patching resource", slog.Any("error", err))
-					return err
-				}
+		slog.Debug("using client-side apply for resource update", slog.Bool("threeWayMergeForUnstructured", updateOptions.threeWayMergeForUnstructured))
+		return clientSideApplyFunc
+	}
 
-				logger.Debug("Patched resource")
+	if err := validateApplyAnnotations(targets); err != nil {
+		return nil, err
+	}
 
-				return nil
-			}
+	recreateFunc := func(target *resource.Info, policy applyPolicy) error {
+		if policy.strategy == ApplyStrategyServerSide || (policy.strategy == "" && updateOptions.serverSideApply && !updateOptions.forceReplace) {
+			return patchResourceServerSide(target, updateOptions.fieldManager, false, updateOptions.forceConflicts, updateOptions.fieldValidationDirective)
 		}
+		return createResource(target, updateOptions.fieldManager)
+	}
 
-		slog.Debug("using client-side apply for resource update", slog.Bool("threeWayMergeForUnstructured", updateOptions.threeWayMergeForUnstructured))
-		return func(original, target *resource.Info) error {
-			return patchResourceClientSide(original.Object, target, updateOptions.threeWayMergeForUnstructured)
+	defaultFunc := makeUpdateApplyFunc()
+	return c.update(originals, targets, updateOptions.fieldManager, func(original, target *resource.Info) error {
+		policy, _ := applyAnnotations(target)
+		if policy.noUpdate {
+			slog.Debug("skipping update due to annotation", "namespace", target.Namespace, "name", target.Name, "kind", target.Mapping.GroupVersionKind.Kind, "annotation", NoUpdateAnno)
+			return nil
+		}
+
+		updateApplyFunc := defaultFunc
+		switch policy.strategy {
+		case ApplyStrategyReplace:
+			updateApplyFunc = replaceFunc
+		case ApplyStrategyServerSide:
+			updateApplyFunc = serverSideApplyFunc
+		case ApplyStrategyMerge:
+			updateApplyFunc = clientSideApplyFunc
+		}
+		if policy.strategy != "" {
+			slog.Debug("using update strategy from annotation", "namespace", target.Namespace, "name", target.Name, "kind", target.Mapping.GroupVersionKind.Kind, "annotation", ApplyStrategyAnno, "value", policy.strategy)
 		}
-	}
 
-	return c.update(originals, targets, makeUpdateApplyFunc())
+		err := updateApplyFunc(original, target)
+		immutableErr := asImmutableFieldError(target, err)
+		if immutableErr == nil {
+			return err
+		}
+		if updateOptions.dryRun || !shouldRecreate(target, updateOptions.forceRecreate, policy.forceRecreate) {
+			return immutableErr
+		}
+		return recreateResource(target, func(target *resource.Info) error {
+			return recreateFunc(target, policy)
+		})
+	})
 }
 
 // Delete deletes Kubernetes resources specified in the resources list with
@@ -892,13 +1011,13 @@ func batchPerform(infos ResourceList, fn func(*resource.Info) error, errs chan<-
 
 var createMutex sync.Mutex
 
-func createResource(info *resource.Info) error {
+func createResource(info *resource.Info, fieldManager string) error {
 	return retry.RetryOnConflict(
 		retry.DefaultRetry,
 		func() error {
 			createMutex.Lock()
 			defer createMutex.Unlock()
-			obj, err := resource.NewHelper(info.Client, info.Mapping).WithFieldManager(getManagedFieldsManager()).Create(info.Namespace, true, info.Object)
+			obj, err := resource.NewHelper(info.Client, info.Mapping).WithFieldManager(fieldManager).Create(info.Namespace, true, info.Object)
 			if err != nil {
 				return err
 			}
@@ -980,11 +1099,11 @@ func createPatch(original runtime.Object, target *resource.Info, threeWayMergeFo
 	return patch, types.StrategicMergePatchType, err
 }
 
-func replaceResource(target *resource.Info, fieldValidationDirective FieldValidationDirective) error {
+func replaceResource(target *resource.Info, fieldManager string, fieldValidationDirective FieldValidationDirective) error {
 
 	helper := resource.NewHelper(target.Client, target.Mapping).
 		WithFieldValidation(string(fieldValidationDirective)).
-		WithFieldManager(getManagedFieldsManager())
+		WithFieldManager(fieldManager)
 
 	obj, err := helper.Replace(target.Namespace, target.Name, true, target.Object)
 	if err != nil {
@@ -999,7 +1118,7 @@ func replaceResource(target *resource.Info, fieldValidationDirective FieldValida
 
 }
 
-func patchResourceClientSide(original runtime.Object, target *resource.Info, threeWayMergeForUnstructured bool) error {
+func patchResourceClientSide(original runtime.Object, target *resource.Info, fieldManager string, threeWayMergeForUnstructured bool) error {
 
 	patch, patchType, err := createPatch(original, target, threeWayMergeForUnstructured)
 	if err != nil {
@@ -1019,7 +1138,7 @@ func patchResourceClientSide(original runtime.Object, target *resource.Info, thr
 
 	// send patch to server
 	slog.Debug("patching resource", "kind", kind, "name", target.Name, "namespace", target.Namespace)
-	helper := resource.NewHelper(target.Client, target.Mapping).WithFieldManager(getManagedFieldsManager())
+	helper := resource.NewHelper(target.Client, target.Mapping).WithFieldManager(fieldManager)
 	obj, err := helper.Patch(target.Namespace, target.Name, patchType, patch, nil)
 	if err != nil {
 		return fmt.Errorf("cannot patch %q with kind %s: %w", target.Name, kind, err)
@@ -1033,9 +1152,7 @@ func patchResourceClientSide(original runtime.Object, target *resource.Info, thr
 // upgradeClientSideFieldManager is simply a wrapper around csaupgrade.UpgradeManagedFields
 // that ugrade CSA managed fields to SSA apply
 // see: https://github.com/kubernetes/kubernetes/pull/112905
-func upgradeClientSideFieldManager(info *resource.Info, dryRun bool, fieldValidationDirective FieldValidationDirective) (bool, error) {
-
-	fieldManagerName := getManagedFieldsManager()
+func upgradeClientSideFieldManager(info *resource.Info, fieldManagerName string, dryRun bool, fieldValidationDirective FieldValidationDirective) (bool, error) {
 
 	patched := false
 	err := retry.RetryOnConflict(
@@ -1053,9 +1170,11 @@ func upgradeClientSideFieldManager(info *resource.Info, dryRun bool, fieldValida
 				WithFieldManager(fieldManagerName).
 				WithFieldValidation(string(fieldValidationDirective))
 
+			// The client-side fields may have been applied with the default
+			// field manager before another one was configured.
 			patchData, err := csaupgrade.UpgradeManagedFieldsPatch(
 				info.Object,
-				sets.New(fieldManagerName),
+				sets.New(fieldManagerName, getManagedFieldsManager()),
 				fieldManagerName)
 			if err != nil {
 				return fmt.Errorf("failed to upgrade managed fields for object %s/%s %s: %w", info.Namespace, info.Name, info.Mapping.GroupVersionKind.String(), err)
@@ -1088,12 +1207,12 @@ func upgradeClientSideFieldManager(info *resource.Info, dryRun bool, fieldValida
 }
 
 // Patch reource using server-side apply
-func patchResourceServerSide(target *resource.Info, dryRun bool, forceConflicts bool, fieldValidationDirective FieldValidationDirective) error {
+func patchResourceServerSide(target *resource.Info, fieldManager string, dryRun bool, forceConflicts bool, fieldValidationDirective FieldValidationDirective) error {
 	helper := resource.NewHelper(
 		target.Client,
 		target.Mapping).
 		DryRun(dryRun).
-		WithFieldManager(getManagedFieldsManager()).
+		WithFieldManager(fieldManager).
 		WithFieldValidation(string(fieldValidationDirective))
 
 	// Send the full object to be applied on the server side.
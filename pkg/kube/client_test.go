@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 	"sync"
 	"testing"
@@ -39,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	jsonserializer "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes"
@@ -488,6 +490,144 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateApplyStrategyAnnotations(t *testing.T) {
+	listOriginal := newPodList("starfish", "otter", "squid")
+	listTarget := newPodList("starfish", "otter", "squid")
+	listTarget.Items[0].Annotations = map[string]string{ApplyStrategyAnno: ApplyStrategyReplace}
+	listTarget.Items[1].Annotations = map[string]string{NoUpdateAnno: "true"}
+	listTarget.Items[2].Annotations = map[string]string{ApplyStrategyAnno: ApplyStrategyMerge}
+	for i := range listTarget.Items {
+		listTarget.Items[i].Spec.Containers[0].Ports = []v1.ContainerPort{{Name: "https", ContainerPort: 443}}
+	}
+
+	patchTypes := map[string]string{}
+	cb := func(_ []RequestResponseAction, req *http.Request) (*http.Response, error) {
+		name := path.Base(req.URL.Path)
+		for i, pod := range listOriginal.Items {
+			if pod.Name != name {
+				continue
+			}
+			switch req.Method {
+			case http.MethodGet:
+				return newResponse(http.StatusOK, &listOriginal.Items[i])
+			case http.MethodPut, http.MethodPatch:
+				patchTypes[name] = req.Method + " " + req.Header.Get("Content-Type")
+				return newResponse(http.StatusOK, &listTarget.Items[i])
+			}
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		return nil, nil
+	}
+
+	c := newTestClient(t)
+	client := NewRequestResponseLogClient(t, cb)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client:               fake.CreateHTTPClient(client.Do),
+	}
+
+	first, err := c.Build(objBody(&listOriginal), false)
+	require.NoError(t, err)
+	second, err := c.Build(objBody(&listTarget), false)
+	require.NoError(t, err)
+
+	result, err := c.Update(first, second, ClientUpdateOptionServerSideApply(true, false))
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, 3)
+
+	assert.Equal(t, map[string]string{
+		"starfish": http.MethodPut + " application/json",
+		"squid":    http.MethodPatch + " " + string(types.StrategicMergePatchType),
+	}, patchTypes)
+
+	listTarget.Items[0].Annotations[ApplyStrategyAnno] = "recreate"
+	invalid, err := c.Build(objBody(&listTarget), false)
+	require.NoError(t, err)
+	_, err = c.Update(first, invalid)
+	assert.ErrorContains(t, err, `Pod "starfish": invalid helm.sh/apply-strategy annotation "recreate"`)
+}
+
+func TestUpdateImmutableFields(t *testing.T) {
+	defer func(interval time.Duration) { recreatePollInterval = interval }(recreatePollInterval)
+	recreatePollInterval = time.Millisecond
+
+	listOriginal := newPodList("starfish", "otter", "squid")
+	listTarget := newPodList("starfish", "otter", "squid")
+	listTarget.Items[2].Annotations = map[string]string{ForceRecreateAnno: "true"}
+	for i := range listTarget.Items {
+		listTarget.Items[i].Spec.Containers[0].Image = "nginx:2"
+	}
+
+	immutable := func(name string) (*http.Response, error) {
+		status := apierrors.NewInvalid(schema.GroupKind{Kind: "Pod"}, name, field.ErrorList{
+			field.Invalid(field.NewPath("spec", "containers").Index(0).Child("image"), "nginx:2", "field is immutable"),
+		}).ErrStatus
+		return newResponse(http.StatusUnprocessableEntity, &status)
+	}
+
+	deleted := map[string]bool{}
+	var actions []string
+	cb := func(_ []RequestResponseAction, req *http.Request) (*http.Response, error) {
+		name := path.Base(req.URL.Path)
+		if req.Method == http.MethodPost {
+			name = "pods"
+		}
+		actions = append(actions, req.Method+" "+name)
+		for i, pod := range listOriginal.Items {
+			switch {
+			case req.Method == http.MethodPost:
+				return newResponse(http.StatusCreated, &listTarget.Items[0])
+			case pod.Name != name:
+				continue
+			case req.Method == http.MethodGet && deleted[name]:
+				return newResponse(http.StatusNotFound, notFoundBody())
+			case req.Method == http.MethodGet:
+				return newResponse(http.StatusOK, &listOriginal.Items[i])
+			case req.Method == http.MethodPatch:
+				return immutable(name)
+			case req.Method == http.MethodDelete:
+				deleted[name] = true
+				return newResponse(http.StatusOK, &listOriginal.Items[i])
+			}
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		return nil, nil
+	}
+
+	c := newTestClient(t)
+	client := NewRequestResponseLogClient(t, cb)
+	c.Factory.(*cmdtesting.TestFactory).UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: unstructuredSerializer,
+		Client:               fake.CreateHTTPClient(client.Do),
+	}
+
+	first, err := c.Build(objBody(&listOriginal), false)
+	require.NoError(t, err)
+	second, err := c.Build(objBody(&listTarget), false)
+	require.NoError(t, err)
+
+	_, err = c.Update(first, second,
+		ClientUpdateOptionServerSideApply(false, false),
+		ClientUpdateOptionForceRecreate([]string{"pod/starfish"}))
+	require.Error(t, err)
+
+	var immutableErr *ImmutableFieldError
+	require.ErrorAs(t, err, &immutableErr)
+	assert.Equal(t, "Pod", immutableErr.Kind)
+	assert.Equal(t, "otter", immutableErr.Name)
+	assert.Equal(t, []string{"spec.containers[0].image"}, immutableErr.Fields)
+	assert.True(t, apierrors.IsInvalid(err))
+
+	assert.Equal(t, []string{
+		"GET starfish", "GET starfish", "PATCH starfish", "DELETE starfish", "GET starfish", "POST pods",
+		"GET otter", "GET otter", "PATCH otter",
+		"GET squid", "GET squid", "PATCH squid", "DELETE squid", "GET squid", "POST pods",
+	}, actions)
+
+	_, err = c.Update(first, second, ClientUpdateOptionForceRecreate([]string{"starfish"}))
+	assert.ErrorContains(t, err, `invalid resource "starfish": must be of the form kind/name`)
+}
+
 func TestBuild(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -1419,7 +1559,7 @@ func TestReplaceResource(t *testing.T) {
 			require.Len(t, resourceList, 1)
 			info := resourceList[0]
 
-			err = replaceResource(info, FieldValidationDirectiveStrict)
+			err = replaceResource(info, getManagedFieldsManager(), FieldValidationDirectiveStrict)
 			if tc.ExpectedErrorContains != "" {
 				require.ErrorContains(t, err, tc.ExpectedErrorContains)
 			} else {
@@ -1574,7 +1714,7 @@ func TestPatchResourceClientSide(t *testing.T) {
 			original := resourceListOriginal[0]
 			target := resourceListTarget[0]
 
-			err = patchResourceClientSide(original.Object, target, tc.ThreeWayMergeForUnstructured)
+			err = patchResourceClientSide(original.Object, target, getManagedFieldsManager(), tc.ThreeWayMergeForUnstructured)
 			if tc.ExpectedErrorContains != "" {
 				require.ErrorContains(t, err, tc.ExpectedErrorContains)
 			} else {
@@ -1591,11 +1731,25 @@ func TestPatchResourceServerSide(t *testing.T) {
 		DryRun                   bool
 		ForceConflicts           bool
 		FieldValidationDirective FieldValidationDirective
+		FieldManager             string
 		Callback                 func(t *testing.T, tc testCase, previous []RequestResponseAction, req *http.Request) (*http.Response, error)
 		ExpectedErrorContains    string
 	}
 
 	testCases := map[string]testCase{
+		"field manager": {
+			Pods:                     newPodList("whale"),
+			FieldValidationDirective: FieldValidationDirectiveStrict,
+			FieldManager:             "platform-operator",
+			Callback: func(t *testing.T, tc testCase, _ []RequestResponseAction, req *http.Request) (*http.Response, error) {
+				t.Helper()
+
+				assert.Equal(t, "PATCH", req.Method)
+				assert.Equal(t, "platform-operator", req.URL.Query().Get("fieldManager"))
+
+				return newResponse(http.StatusOK, &tc.Pods.Items[0])
+			},
+		},
 		"normal": {
 			Pods:                     newPodList("whale"),
 			DryRun:                   false,
@@ -1736,7 +1890,11 @@ func TestPatchResourceServerSide(t *testing.T) {
 			require.Len(t, resourceList, 1)
 			info := resourceList[0]
 
-			err = patchResourceServerSide(info, tc.DryRun, tc.ForceConflicts, tc.FieldValidationDirective)
+			fieldManager := tc.FieldManager
+			if fieldManager == "" {
+				fieldManager = getManagedFieldsManager()
+			}
+			err = patchResourceServerSide(info, fieldManager, tc.DryRun, tc.ForceConflicts, tc.FieldValidationDirective)
 			if tc.ExpectedErrorContains != "" {
 				require.ErrorContains(t, err, tc.ExpectedErrorContains)
 			} else {
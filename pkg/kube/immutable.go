@@ -0,0 +1,173 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// immutableFieldMessages are the messages of the validation causes the API
+// server reports for fields that cannot be changed on an existing resource.
+var immutableFieldMessages = []string{
+	"field is immutable",
+	// PersistentVolumeClaim storage requests cannot shrink.
+	"can not be less than",
+	// StatefulSet only allows updates to a few fields of its spec.
+	"updates to statefulset spec for fields other than",
+}
+
+var (
+	// recreatePollInterval is the interval between checks for the deletion
+	// of a resource being recreated.
+	recreatePollInterval = time.Second
+	// recreateTimeout bounds the wait for the deletion of a resource being
+	// recreated.
+	recreateTimeout = 5 * time.Minute
+)
+
+// ImmutableFieldError is returned when the update of a resource is rejected
+// because it changes fields which cannot be changed once the resource exists,
+// such as the template of a Job or the clusterIP of a Service.
+//
+// Such resources can only be updated by deleting and recreating them, which
+// ClientUpdateOptionForceRecreate and the ForceRecreateAnno annotation allow.
+type ImmutableFieldError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Fields are the paths of the immutable fields, as reported by the API
+	// server.
+	Fields []string
+	Err    error
+}
+
+func (e *ImmutableFieldError) Error() string {
+	name := e.Name
+	if e.Namespace != "" {
+		name = e.Namespace + "/" + e.Name
+	}
+	return fmt.Sprintf("%s %q cannot be updated because of immutable fields %s: %v", e.Kind, name, strings.Join(e.Fields, ", "), e.Err)
+}
+
+func (e *ImmutableFieldError) Unwrap() error {
+	return e.Err
+}
+
+// asImmutableFieldError returns an ImmutableFieldError for the target if the
+// error reports changes to immutable fields, or nil otherwise.
+func asImmutableFieldError(target *resource.Info, err error) *ImmutableFieldError {
+	var status apierrors.APIStatus
+	if err == nil || !errors.As(err, &status) {
+		return nil
+	}
+	s := status.Status()
+	if s.Reason != metav1.StatusReasonInvalid || s.Details == nil {
+		return nil
+	}
+
+	var fields []string
+	for _, cause := range s.Details.Causes {
+		for _, msg := range immutableFieldMessages {
+			if strings.Contains(cause.Message, msg) {
+				fields = append(fields, cause.Field)
+				break
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ImmutableFieldError{
+		Kind:      target.Mapping.GroupVersionKind.Kind,
+		Namespace: target.Namespace,
+		Name:      target.Name,
+		Fields:    fields,
+		Err:       err,
+	}
+}
+
+// parseRecreateSelector checks a "kind/name" resource selector and returns
+// its parts.
+func parseRecreateSelector(selector string) (kind, name string, err error) {
+	kind, name, ok := strings.Cut(selector, "/")
+	if !ok || kind == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid resource %q: must be of the form kind/name", selector)
+	}
+	return kind, name, nil
+}
+
+// shouldRecreate reports whether the target is selected for recreation by a
+// "kind/name" selector or by the ForceRecreateAnno annotation. Kinds are
+// matched case-insensitively.
+func shouldRecreate(target *resource.Info, selectors []string, annotated bool) bool {
+	if annotated {
+		return true
+	}
+	for _, selector := range selectors {
+		kind, name, err := parseRecreateSelector(selector)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(kind, target.Mapping.GroupVersionKind.Kind) && name == target.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// recreateResource deletes the target, waits for it to be gone and creates it
+// again with the given function.
+func recreateResource(target *resource.Info, create func(*resource.Info) error) error {
+	kind := target.Mapping.GroupVersionKind.Kind
+	slog.Debug("recreating resource with immutable field changes", "namespace", target.Namespace, "name", target.Name, "kind", kind)
+
+	if err := deleteResource(target, metav1.DeletePropagationBackground); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s %q for recreation: %w", kind, target.Name, err)
+	}
+
+	helper := resource.NewHelper(target.Client, target.Mapping)
+	err := wait.PollUntilContextTimeout(context.Background(), recreatePollInterval, recreateTimeout, true, func(context.Context) (bool, error) {
+		_, err := helper.Get(target.Namespace, target.Name)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("timed out waiting for the deletion of %s %q for recreation: %w", kind, target.Name, err)
+	}
+
+	// A failed replace leaves the resource version of the live object on the
+	// target, which the API server refuses on creation.
+	if err := metadataAccessor.SetResourceVersion(target.Object, ""); err != nil {
+		return fmt.Errorf("failed to recreate %s %q: %w", kind, target.Name, err)
+	}
+	if err := create(target); err != nil {
+		return fmt.Errorf("failed to recreate %s %q: %w", kind, target.Name, err)
+	}
+	return nil
+}
@@ -117,7 +117,17 @@ type InterfaceResources interface {
 	BuildTable(reader io.Reader, validate bool) (ResourceList, error)
 }
 
+// InterfaceWaitProgress is introduced to avoid breaking backwards compatibility for Interface implementers.
+//
+// TODO Helm 4: Remove InterfaceWaitProgress and integrate its method(s) into the Interface.
+type InterfaceWaitProgress interface {
+	// GetWaiterWithProgress gets a Waiter reporting the rollout progress of
+	// Deployments and StatefulSets while waiting for them.
+	GetWaiterWithProgress(ws WaitStrategy, progress RolloutProgressFunc) (Waiter, error)
+}
+
 var _ Interface = (*Client)(nil)
 var _ InterfaceLogs = (*Client)(nil)
 var _ InterfaceDeletionPropagation = (*Client)(nil)
 var _ InterfaceResources = (*Client)(nil)
+var _ InterfaceWaitProgress = (*Client)(nil)
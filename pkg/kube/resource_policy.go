@@ -16,6 +16,13 @@ limitations under the License.
 
 package kube // import "helm.sh/helm/v4/pkg/kube"
 
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
 // ResourcePolicyAnno is the annotation name for a resource policy
 const ResourcePolicyAnno = "helm.sh/resource-policy"
 
@@ -25,3 +32,89 @@ const ResourcePolicyAnno = "helm.sh/resource-policy"
 //
 //	during an uninstallRelease action.
 const KeepPolicy = "keep"
+
+// ApplyStrategyAnno is the annotation name for the strategy updating a
+// resource, overriding the strategy of the operation for that resource.
+const ApplyStrategyAnno = "helm.sh/apply-strategy"
+
+const (
+	// ApplyStrategyReplace replaces the whole resource, dropping the fields
+	// set by other clients. It suits resources merge patching misbehaves
+	// with, such as Jobs or webhook configurations.
+	ApplyStrategyReplace = "replace"
+	// ApplyStrategyServerSide applies the resource server-side.
+	ApplyStrategyServerSide = "ssa"
+	// ApplyStrategyMerge patches the resource with a client-side three-way
+	// merge of the previous and the new manifests and the live state.
+	ApplyStrategyMerge = "merge"
+)
+
+// NoUpdateAnno is the annotation name for skipping the update of a resource.
+//
+// Resources with the annotation set to "true" are created if they do not
+// exist, and never updated afterwards.
+const NoUpdateAnno = "helm.sh/no-update"
+
+// ForceRecreateAnno is the annotation name for deleting and recreating a
+// resource when an update is rejected because of immutable fields.
+//
+// Resources with the annotation set to "true" are recreated instead of failing
+// the operation with an ImmutableFieldError.
+const ForceRecreateAnno = "helm.sh/force-recreate"
+
+// applyPolicy holds the values of the annotations changing how a resource is
+// updated.
+type applyPolicy struct {
+	strategy      string
+	noUpdate      bool
+	forceRecreate bool
+}
+
+// applyAnnotations returns the values of the apply strategy, no-update and
+// force-recreate annotations of the resource.
+func applyAnnotations(info *resource.Info) (applyPolicy, error) {
+	var policy applyPolicy
+	annotations, err := metadataAccessor.Annotations(info.Object)
+	if err != nil {
+		// Objects without metadata are applied with the default strategy.
+		return policy, nil
+	}
+
+	policy.strategy = annotations[ApplyStrategyAnno]
+	switch policy.strategy {
+	case "", ApplyStrategyReplace, ApplyStrategyServerSide, ApplyStrategyMerge:
+	default:
+		return applyPolicy{}, fmt.Errorf("invalid %s annotation %q: must be one of %s, %s or %s", ApplyStrategyAnno, policy.strategy, ApplyStrategyReplace, ApplyStrategyServerSide, ApplyStrategyMerge)
+	}
+
+	if policy.noUpdate, err = parseBoolAnnotation(annotations, NoUpdateAnno); err != nil {
+		return applyPolicy{}, err
+	}
+	if policy.forceRecreate, err = parseBoolAnnotation(annotations, ForceRecreateAnno); err != nil {
+		return applyPolicy{}, err
+	}
+	return policy, nil
+}
+
+func parseBoolAnnotation(annotations map[string]string, name string) (bool, error) {
+	v, ok := annotations[name]
+	if !ok {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s annotation %q: must be true or false", name, v)
+	}
+	return b, nil
+}
+
+// validateApplyAnnotations checks the apply strategy, no-update and
+// force-recreate annotations of the resources before any of them is changed.
+func validateApplyAnnotations(resources ResourceList) error {
+	for _, info := range resources {
+		if _, err := applyAnnotations(info); err != nil {
+			return fmt.Errorf("%s %q: %w", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "helm.sh/helm/v4/pkg/kube"
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// rolloutPollInterval is the interval between two checks of the rollout
+// progress while waiting.
+var rolloutPollInterval = 2 * time.Second
+
+// RolloutStatus is the progress of the rollout of a Deployment or a
+// StatefulSet.
+type RolloutStatus struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Replicas is the desired number of replicas.
+	Replicas  int32
+	Updated   int32
+	Ready     int32
+	Available int32
+	// Done is true once the rollout is complete.
+	Done bool
+	// Message describes the progress of the rollout, like
+	// `kubectl rollout status` does.
+	Message string
+	// Stalls lists why pods of the rollout do not become ready, from their
+	// status and from warning events, such as "ImagePullBackOff".
+	Stalls []string
+}
+
+func (s RolloutStatus) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s/%s: %s", strings.ToLower(s.Kind), s.Name, s.Message)
+	for _, stall := range s.Stalls {
+		fmt.Fprintf(&b, "\n  %s", stall)
+	}
+	return b.String()
+}
+
+func (s RolloutStatus) equal(o RolloutStatus) bool {
+	return s.Replicas == o.Replicas && s.Updated == o.Updated && s.Ready == o.Ready &&
+		s.Available == o.Available && s.Done == o.Done && s.Message == o.Message && slices.Equal(s.Stalls, o.Stalls)
+}
+
+// RolloutProgressFunc is called with the rollout progress of the Deployments
+// and StatefulSets being waited for, each time it changes.
+type RolloutProgressFunc func(RolloutStatus)
+
+// GetWaiterWithProgress returns a Waiter reporting the rollout progress of the
+// Deployments and StatefulSets it waits for to the given function. Calls are
+// never concurrent, and none happens after the wait returns.
+func (c *Client) GetWaiterWithProgress(ws WaitStrategy, progress RolloutProgressFunc) (Waiter, error) {
+	waiter, err := c.GetWaiter(ws)
+	if err != nil || progress == nil || ws == HookOnlyStrategy {
+		return waiter, err
+	}
+	kc, err := c.getKubeClient()
+	if err != nil {
+		return nil, err
+	}
+	return &progressWaiter{Waiter: waiter, client: kc, progress: progress}, nil
+}
+
+// progressWaiter reports the rollout progress while the wrapped Waiter waits
+// for resources to be ready.
+type progressWaiter struct {
+	Waiter
+	client   kubernetes.Interface
+	progress RolloutProgressFunc
+}
+
+func (w *progressWaiter) Wait(resources ResourceList, timeout time.Duration) error {
+	return w.report(resources, func() error { return w.Waiter.Wait(resources, timeout) })
+}
+
+func (w *progressWaiter) WaitWithJobs(resources ResourceList, timeout time.Duration) error {
+	return w.report(resources, func() error { return w.Waiter.WaitWithJobs(resources, timeout) })
+}
+
+// report polls the rollout progress of the workloads among the resources
+// until wait returns, and once more afterwards.
+func (w *progressWaiter) report(resources ResourceList, wait func() error) error {
+	var workloads []*rolloutWorkload
+	for _, info := range resources {
+		switch AsVersioned(info).(type) {
+		case *appsv1.Deployment, *appsv1.StatefulSet:
+			workloads = append(workloads, &rolloutWorkload{
+				kind:      info.Mapping.GroupVersionKind.Kind,
+				namespace: info.Namespace,
+				name:      info.Name,
+			})
+		}
+	}
+	if len(workloads) == 0 {
+		return wait()
+	}
+
+	since := time.Now()
+	poll := func(ctx context.Context) {
+		for _, wl := range workloads {
+			s, err := rolloutStatus(ctx, w.client, wl.kind, wl.namespace, wl.name, since)
+			if err != nil {
+				slog.Debug("unable to get rollout status", "namespace", wl.namespace, "name", wl.name, "kind", wl.kind, slog.Any("error", err))
+				continue
+			}
+			if wl.last != nil && wl.last.equal(s) {
+				continue
+			}
+			wl.last = &s
+			w.progress(s)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(rolloutPollInterval)
+		defer ticker.Stop()
+		for {
+			poll(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	err := wait()
+	cancel()
+	wg.Wait()
+	poll(context.Background())
+	return err
+}
+
+type rolloutWorkload struct {
+	kind      string
+	namespace string
+	name      string
+	last      *RolloutStatus
+}
+
+// rolloutStatus returns the rollout progress of a Deployment or StatefulSet,
+// with the stalls of its pods and the warning events newer than since.
+func rolloutStatus(ctx context.Context, client kubernetes.Interface, kind, namespace, name string, since time.Time) (RolloutStatus, error) {
+	s := RolloutStatus{Kind: kind, Namespace: namespace, Name: name}
+
+	var (
+		selector *metav1.LabelSelector
+		uid      types.UID
+	)
+	switch kind {
+	case "Deployment":
+		dep, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return s, err
+		}
+		deploymentRolloutStatus(&s, dep)
+		selector, uid = dep.Spec.Selector, dep.UID
+	case "StatefulSet":
+		sts, err := client.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return s, err
+		}
+		statefulSetRolloutStatus(&s, sts)
+		selector, uid = sts.Spec.Selector, sts.UID
+	default:
+		return s, fmt.Errorf("no rollout status for kind %s", kind)
+	}
+	if s.Done {
+		return s, nil
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return s, err
+	}
+	pods, err := getPods(ctx, client, namespace, sel.String())
+	if err != nil {
+		return s, err
+	}
+	uids := map[types.UID]string{uid: strings.ToLower(kind) + "/" + name}
+	for i := range pods {
+		uids[pods[i].UID] = "pod/" + pods[i].Name
+		s.Stalls = append(s.Stalls, podStalls(&pods[i])...)
+	}
+
+	events, err := client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+	if err != nil {
+		return s, err
+	}
+	s.Stalls = append(s.Stalls, warningEvents(events.Items, uids, since)...)
+	return s, nil
+}
+
+// deploymentRolloutStatus sets the progress of a Deployment, following
+// `kubectl rollout status`.
+func deploymentRolloutStatus(s *RolloutStatus, dep *appsv1.Deployment) {
+	s.Replicas = 1
+	if dep.Spec.Replicas != nil {
+		s.Replicas = *dep.Spec.Replicas
+	}
+	s.Updated = dep.Status.UpdatedReplicas
+	s.Ready = dep.Status.ReadyReplicas
+	s.Available = dep.Status.AvailableReplicas
+
+	if dep.Generation > dep.Status.ObservedGeneration {
+		s.Message = "waiting for deployment spec update to be observed"
+		return
+	}
+	for _, c := range dep.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			s.Message = "exceeded its progress deadline"
+			return
+		}
+	}
+	switch {
+	case s.Updated < s.Replicas:
+		s.Message = fmt.Sprintf("%d of %d new replicas updated, %d ready, %d available", s.Updated, s.Replicas, s.Ready, s.Available)
+	case dep.Status.Replicas > s.Updated:
+		s.Message = fmt.Sprintf("%d old replicas pending termination", dep.Status.Replicas-s.Updated)
+	case s.Available < s.Updated:
+		s.Message = fmt.Sprintf("%d of %d updated replicas available", s.Available, s.Updated)
+	default:
+		s.Done = true
+		s.Message = "successfully rolled out"
+	}
+}
+
+// statefulSetRolloutStatus sets the progress of a StatefulSet, following
+// `kubectl rollout status`.
+func statefulSetRolloutStatus(s *RolloutStatus, sts *appsv1.StatefulSet) {
+	s.Replicas = 1
+	if sts.Spec.Replicas != nil {
+		s.Replicas = *sts.Spec.Replicas
+	}
+	s.Updated = sts.Status.UpdatedReplicas
+	s.Ready = sts.Status.ReadyReplicas
+	s.Available = sts.Status.AvailableReplicas
+
+	if sts.Generation > sts.Status.ObservedGeneration {
+		s.Message = "waiting for statefulset spec update to be observed"
+		return
+	}
+	if s.Ready < s.Replicas {
+		s.Message = fmt.Sprintf("%d of %d pods ready", s.Ready, s.Replicas)
+		return
+	}
+	if sts.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType {
+		if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+			if want := s.Replicas - *ru.Partition; s.Updated < want {
+				s.Message = fmt.Sprintf("partitioned roll out: %d of %d new pods updated", s.Updated, want)
+				return
+			}
+			s.Done = true
+			s.Message = fmt.Sprintf("partitioned roll out complete: %d new pods updated", s.Updated)
+			return
+		}
+		if sts.Status.UpdateRevision != sts.Status.CurrentRevision {
+			s.Message = fmt.Sprintf("%d of %d pods at revision %s", s.Updated, s.Replicas, sts.Status.UpdateRevision)
+			return
+		}
+	}
+	s.Done = true
+	s.Message = "successfully rolled out"
+}
+
+// podStalls returns why a pod is not ready, from its scheduling condition
+// and the state of its containers.
+func podStalls(pod *corev1.Pod) []string {
+	var stalls []string
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			stalls = append(stalls, stallMessage("pod/"+pod.Name, c.Reason, c.Message))
+		}
+	}
+	statuses := append(slices.Clone(pod.Status.InitContainerStatuses), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		switch {
+		case cs.State.Waiting != nil:
+			switch cs.State.Waiting.Reason {
+			case "", "ContainerCreating", "PodInitializing":
+				// Not stalled, still starting.
+				continue
+			}
+			stalls = append(stalls, stallMessage("pod/"+pod.Name+" container "+cs.Name, cs.State.Waiting.Reason, cs.State.Waiting.Message))
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0:
+			stalls = append(stalls, stallMessage("pod/"+pod.Name+" container "+cs.Name, cs.State.Terminated.Reason, cs.State.Terminated.Message))
+		}
+	}
+	return stalls
+}
+
+// warningEvents returns the latest warning event of each reason involving the
+// given objects, newer than since, oldest first.
+func warningEvents(events []corev1.Event, objects map[types.UID]string, since time.Time) []string {
+	type key struct {
+		uid    types.UID
+		reason string
+	}
+	latest := map[key]corev1.Event{}
+	for _, e := range events {
+		if _, ok := objects[e.InvolvedObject.UID]; !ok || e.Type != corev1.EventTypeWarning || eventTime(e).Before(since) {
+			continue
+		}
+		k := key{e.InvolvedObject.UID, e.Reason}
+		if prev, ok := latest[k]; !ok || eventTime(prev).Before(eventTime(e)) {
+			latest[k] = e
+		}
+	}
+
+	sorted := make([]corev1.Event, 0, len(latest))
+	for _, e := range latest {
+		sorted = append(sorted, e)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if ti, tj := eventTime(sorted[i]), eventTime(sorted[j]); !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	stalls := make([]string, 0, len(sorted))
+	for _, e := range sorted {
+		stalls = append(stalls, stallMessage(objects[e.InvolvedObject.UID], e.Reason, e.Message))
+	}
+	return stalls
+}
+
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func stallMessage(object, reason, message string) string {
+	msg := object + ": " + reason
+	if message = strings.TrimSpace(message); message != "" {
+		msg += ": " + message
+	}
+	return msg
+}
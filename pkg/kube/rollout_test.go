@@ -0,0 +1,255 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  appsv1.DeploymentStatus
+		message string
+		done    bool
+	}{
+		{
+			name:    "updating",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 1, ReadyReplicas: 2, AvailableReplicas: 2},
+			message: "1 of 3 new replicas updated, 2 ready, 2 available",
+		},
+		{
+			name:    "terminating old replicas",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 4, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3},
+			message: "1 old replicas pending termination",
+		},
+		{
+			name:    "becoming available",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 2, AvailableReplicas: 2},
+			message: "2 of 3 updated replicas available",
+		},
+		{
+			name:    "stale",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 0},
+			message: "waiting for deployment spec update to be observed",
+		},
+		{
+			name: "deadline exceeded",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+			}},
+			message: "exceeded its progress deadline",
+		},
+		{
+			name:    "done",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3},
+			message: "successfully rolled out",
+			done:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: intToInt32(3)},
+				Status:     tt.status,
+			}
+			var s RolloutStatus
+			deploymentRolloutStatus(&s, dep)
+			assert.Equal(t, tt.message, s.Message)
+			assert.Equal(t, tt.done, s.Done)
+		})
+	}
+}
+
+func TestStatefulSetRolloutStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		partition *int32
+		status    appsv1.StatefulSetStatus
+		message   string
+		done      bool
+	}{
+		{
+			name:    "pods not ready",
+			status:  appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 1},
+			message: "1 of 3 pods ready",
+		},
+		{
+			name:    "rolling update",
+			status:  appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+			message: "1 of 3 pods at revision web-2",
+		},
+		{
+			name:      "partitioned",
+			partition: intToInt32(1),
+			status:    appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1, CurrentRevision: "web-1", UpdateRevision: "web-2"},
+			message:   "partitioned roll out: 1 of 2 new pods updated",
+		},
+		{
+			name:    "done",
+			status:  appsv1.StatefulSetStatus{ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 3, CurrentRevision: "web-2", UpdateRevision: "web-2"},
+			message: "successfully rolled out",
+			done:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Generation: 1},
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: intToInt32(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: tt.partition},
+					},
+				},
+				Status: tt.status,
+			}
+			var s RolloutStatus
+			statefulSetRolloutStatus(&s, sts)
+			assert.Equal(t, tt.message, s.Message)
+			assert.Equal(t, tt.done, s.Done)
+		})
+	}
+}
+
+func rolloutTestObjects(since time.Time) []runtime.Object {
+	labels := map[string]string{"app": "web"}
+	return []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "dep-uid", Generation: 1},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: intToInt32(2),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+			},
+			Status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-a", Namespace: "default", UID: "pod-a", Labels: labels},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "app",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: `Back-off pulling image "nginx:nope"`}},
+			}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-b", Namespace: "default", UID: "pod-b", Labels: labels},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable", Message: "0/1 nodes are available: 1 Insufficient cpu.",
+			}}},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-b.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{UID: "pod-b"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "FailedMount",
+			Message:        `secret "missing" not found`,
+			LastTimestamp:  metav1.NewTime(since.Add(time.Second)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-b.0", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{UID: "pod-b"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			LastTimestamp:  metav1.NewTime(since.Add(-time.Hour)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-a.0", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{UID: "pod-a"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Pulling",
+			LastTimestamp:  metav1.NewTime(since.Add(time.Second)),
+		},
+	}
+}
+
+func TestRolloutStatus(t *testing.T) {
+	since := time.Now()
+	client := k8sfake.NewClientset(rolloutTestObjects(since)...)
+
+	s, err := rolloutStatus(context.Background(), client, "Deployment", "default", "web", since)
+	require.NoError(t, err)
+	assert.Equal(t, "0 of 2 updated replicas available", s.Message)
+	assert.False(t, s.Done)
+	assert.Equal(t, []string{
+		`pod/web-a container app: ImagePullBackOff: Back-off pulling image "nginx:nope"`,
+		"pod/web-b: Unschedulable: 0/1 nodes are available: 1 Insufficient cpu.",
+		`pod/web-b: FailedMount: secret "missing" not found`,
+	}, s.Stalls)
+	assert.Equal(t, `deployment/web: 0 of 2 updated replicas available
+  pod/web-a container app: ImagePullBackOff: Back-off pulling image "nginx:nope"
+  pod/web-b: Unschedulable: 0/1 nodes are available: 1 Insufficient cpu.
+  pod/web-b: FailedMount: secret "missing" not found`, s.String())
+}
+
+type sleepWaiter struct {
+	Waiter
+	wait time.Duration
+}
+
+func (w *sleepWaiter) Wait(_ ResourceList, _ time.Duration) error {
+	time.Sleep(w.wait)
+	return nil
+}
+
+func TestProgressWaiter(t *testing.T) {
+	defer func(interval time.Duration) { rolloutPollInterval = interval }(rolloutPollInterval)
+	rolloutPollInterval = time.Millisecond
+
+	objects := rolloutTestObjects(time.Now())
+	client := k8sfake.NewClientset(objects...)
+
+	var reported []RolloutStatus
+	w := &progressWaiter{
+		Waiter:   &sleepWaiter{wait: 20 * time.Millisecond},
+		client:   client,
+		progress: func(s RolloutStatus) { reported = append(reported, s) },
+	}
+	resources := ResourceList{
+		&resource.Info{
+			Name:      "web",
+			Namespace: "default",
+			Object:    objects[0],
+			Mapping:   &meta.RESTMapping{GroupVersionKind: appsv1.SchemeGroupVersion.WithKind("Deployment")},
+		},
+		&resource.Info{
+			Name:      "web-a",
+			Namespace: "default",
+			Object:    objects[1],
+			Mapping:   &meta.RESTMapping{GroupVersionKind: corev1.SchemeGroupVersion.WithKind("Pod")},
+		},
+	}
+	require.NoError(t, w.Wait(resources, time.Minute))
+
+	// Unchanged statuses are reported once.
+	require.Len(t, reported, 1)
+	assert.Equal(t, "Deployment", reported[0].Kind)
+	assert.Equal(t, "web", reported[0].Name)
+	assert.Len(t, reported[0].Stalls, 3)
+}
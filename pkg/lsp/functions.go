@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsp
+
+import "fmt"
+
+// functionDoc documents a template function for hover.
+type functionDoc struct {
+	signature   string
+	description string
+}
+
+// functionDocs documents the Go template builtins, the functions Helm adds
+// and the most commonly used Sprig functions.
+var functionDocs = map[string]functionDoc{
+	// Go template builtins.
+	"and":      {"and x y ...", "Returns the first empty argument or the last argument."},
+	"or":       {"or x y ...", "Returns the first non-empty argument or the last argument."},
+	"not":      {"not x", "Returns the boolean negation of its single argument."},
+	"len":      {"len x", "Returns the length of a string, slice, map or channel."},
+	"index":    {"index x key ...", "Returns the element of x at the given indexes or map keys."},
+	"slice":    {"slice x start end", "Returns x sliced from start to end."},
+	"print":    {"print x ...", "Formats its arguments like fmt.Sprint."},
+	"printf":   {"printf format x ...", "Formats its arguments like fmt.Sprintf."},
+	"println":  {"println x ...", "Formats its arguments like fmt.Sprintln."},
+	"eq":       {"eq x y ...", "Reports whether x is equal to any of the other arguments."},
+	"ne":       {"ne x y", "Reports whether x is not equal to y."},
+	"lt":       {"lt x y", "Reports whether x is less than y."},
+	"le":       {"le x y", "Reports whether x is less than or equal to y."},
+	"gt":       {"gt x y", "Reports whether x is greater than y."},
+	"ge":       {"ge x y", "Reports whether x is greater than or equal to y."},
+	"call":     {"call fn x ...", "Calls the function fn with the remaining arguments."},
+	"html":     {"html x", "Returns the escaped HTML equivalent of x."},
+	"js":       {"js x", "Returns the escaped JavaScript equivalent of x."},
+	"urlquery": {"urlquery x", "Returns x escaped for embedding in a URL query."},
+
+	// Helm functions.
+	"include":             {"include name context", "Renders the named template with the given context and returns the result as a string, so it can be piped to other functions."},
+	"tpl":                 {"tpl template context", "Renders a string as a template with the given context."},
+	"required":            {"required message value", "Fails rendering with message if value is empty, and returns value otherwise."},
+	"lookup":              {"lookup apiVersion kind namespace name", "Looks up a resource in the cluster. Returns an empty map when rendering without a cluster, such as with 'helm template'."},
+	"toYaml":              {"toYaml value", "Encodes value as YAML. Errors are ignored and produce an empty string."},
+	"mustToYaml":          {"mustToYaml value", "Encodes value as YAML, failing rendering on errors."},
+	"toYamlPretty":        {"toYamlPretty value", "Encodes value as YAML, indenting lists within maps and writing multi-line strings as literal blocks."},
+	"toYamlSorted":        {"toYamlSorted value", "Like toYamlPretty, sorting the keys of structs like those of maps."},
+	"toYamlIndent":        {"toYamlIndent n value", "Like toYamlPretty, indenting each level by n spaces."},
+	"fromYaml":            {"fromYaml string", "Decodes a YAML document into a map. Errors are stored under the 'Error' key."},
+	"fromYamlArray":       {"fromYamlArray string", "Decodes a YAML array into a list."},
+	"toJson":              {"toJson value", "Encodes value as JSON. Errors are ignored and produce an empty string."},
+	"mustToJson":          {"mustToJson value", "Encodes value as JSON, failing rendering on errors."},
+	"toRawJsonIndent":     {"toRawJsonIndent n value", "Encodes value as JSON indented by n spaces, without escaping HTML characters."},
+	"mustToRawJsonIndent": {"mustToRawJsonIndent n value", "Like toRawJsonIndent, failing rendering on errors."},
+	"fromJson":            {"fromJson string", "Decodes a JSON object into a map. Errors are stored under the 'Error' key."},
+	"fromJsonArray":       {"fromJsonArray string", "Decodes a JSON array into a list."},
+	"toToml":              {"toToml value", "Encodes value as TOML."},
+	"fromToml":            {"fromToml string", "Decodes a TOML document into a map."},
+
+	// Sprig functions.
+	"default":        {"default default value", "Returns value if it is not empty, and default otherwise."},
+	"empty":          {"empty value", "Reports whether value is empty."},
+	"coalesce":       {"coalesce x ...", "Returns the first non-empty argument."},
+	"ternary":        {"ternary then else condition", "Returns then if condition is true, and else otherwise."},
+	"fail":           {"fail message", "Fails rendering with message."},
+	"quote":          {"quote x ...", "Wraps each argument in double quotes."},
+	"squote":         {"squote x ...", "Wraps each argument in single quotes."},
+	"indent":         {"indent n string", "Indents every line of string by n spaces."},
+	"nindent":        {"nindent n string", "Like indent, but starts with a newline."},
+	"trim":           {"trim string", "Removes leading and trailing whitespace."},
+	"trunc":          {"trunc n string", "Truncates string to n characters; a negative n keeps the last characters."},
+	"trimSuffix":     {"trimSuffix suffix string", "Removes suffix from the end of string."},
+	"trimPrefix":     {"trimPrefix prefix string", "Removes prefix from the start of string."},
+	"upper":          {"upper string", "Converts string to upper case."},
+	"lower":          {"lower string", "Converts string to lower case."},
+	"title":          {"title string", "Converts string to title case."},
+	"replace":        {"replace old new string", "Replaces all occurrences of old in string with new."},
+	"contains":       {"contains substring string", "Reports whether string contains substring."},
+	"hasPrefix":      {"hasPrefix prefix string", "Reports whether string starts with prefix."},
+	"hasSuffix":      {"hasSuffix suffix string", "Reports whether string ends with suffix."},
+	"join":           {"join separator list", "Joins the elements of list with separator."},
+	"split":          {"split separator string", "Splits string into a map with keys _0, _1, ..."},
+	"splitList":      {"splitList separator string", "Splits string into a list."},
+	"toString":       {"toString value", "Converts value to a string."},
+	"int":            {"int value", "Converts value to an int."},
+	"b64enc":         {"b64enc string", "Encodes string with base64."},
+	"b64dec":         {"b64dec string", "Decodes a base64 string."},
+	"sha256sum":      {"sha256sum string", "Returns the hex encoded SHA-256 digest of string."},
+	"list":           {"list x ...", "Returns a list of its arguments."},
+	"dict":           {"dict key value ...", "Returns a map of the given key and value pairs."},
+	"get":            {"get map key", "Returns the value of key in map, or an empty string."},
+	"set":            {"set map key value", "Sets key in map to value and returns the map."},
+	"hasKey":         {"hasKey map key", "Reports whether map contains key."},
+	"keys":           {"keys map ...", "Returns the keys of the maps."},
+	"pick":           {"pick map key ...", "Returns a map with only the given keys."},
+	"omit":           {"omit map key ...", "Returns a map without the given keys."},
+	"merge":          {"merge dst src ...", "Merges the src maps into dst; values in dst take precedence."},
+	"mergeOverwrite": {"mergeOverwrite dst src ...", "Merges the src maps into dst; values in src take precedence."},
+	"deepCopy":       {"deepCopy value", "Returns a deep copy of value."},
+	"first":          {"first list", "Returns the first element of list."},
+	"last":           {"last list", "Returns the last element of list."},
+	"append":         {"append list value", "Returns list with value appended."},
+	"has":            {"has value list", "Reports whether list contains value."},
+	"uniq":           {"uniq list", "Returns list without duplicates."},
+	"sortAlpha":      {"sortAlpha list", "Sorts a list of strings alphabetically."},
+	"until":          {"until n", "Returns the list of integers from 0 to n-1."},
+	"semverCompare":  {"semverCompare constraint version", "Reports whether version satisfies the semantic version constraint."},
+	"regexMatch":     {"regexMatch regex string", "Reports whether string matches regex."},
+	"randAlphaNum":   {"randAlphaNum n", "Returns a random alphanumeric string of length n. The output changes on every render."},
+	"now":            {"now", "Returns the current time."},
+	"date":           {"date layout time", "Formats time with the Go layout."},
+	"kindIs":         {"kindIs kind value", "Reports whether value is of the given kind, such as \"map\" or \"slice\"."},
+	"typeOf":         {"typeOf value", "Returns the Go type of value."},
+}
+
+// hoverFunction returns the documentation of a template function.
+func hoverFunction(name string) (string, bool) {
+	doc, ok := functionDocs[name]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("```\n%s\n```\n%s", doc.signature, doc.description), true
+}
@@ -0,0 +1,274 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package lsp implements a Language Server Protocol server for chart authoring.
+
+The server offers completion of .Values paths from a chart's values schema and
+default values, jumps to the definitions of named templates, documents
+template functions on hover, and publishes the findings of 'helm lint' as
+diagnostics whenever a file of an open chart is saved.
+*/
+package lsp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"helm.sh/helm/v4/internal/jsonrpc"
+	"helm.sh/helm/v4/internal/urlutil"
+	"helm.sh/helm/v4/internal/version"
+	"helm.sh/helm/v4/pkg/chart/v2/lint"
+)
+
+// Server is a language server for chart authoring.
+type Server struct {
+	linter *lint.Server
+
+	mu sync.Mutex
+	// docs holds the text of the open documents by path.
+	docs map[string]string
+	// charts is the set of chart directories being linted.
+	charts   map[string]bool
+	conn     *jsonrpc.Conn
+	shutdown bool
+}
+
+// NewServer creates a Server. Charts are linted with linter, or with a new
+// lint.Server if linter is nil.
+func NewServer(linter *lint.Server) *Server {
+	if linter == nil {
+		linter = lint.NewServer(nil)
+	}
+	return &Server{
+		linter: linter,
+		docs:   make(map[string]string),
+		charts: make(map[string]bool),
+	}
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until the client exits, r is exhausted or ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.conn = jsonrpc.NewConn(r, w)
+	s.linter.Attach(w)
+	return jsonrpc.Serve(ctx, s.conn, s.handle)
+}
+
+func (s *Server) handle(req *jsonrpc.Message) error {
+	s.mu.Lock()
+	shutdown := s.shutdown
+	s.mu.Unlock()
+	if shutdown && req.Method != "exit" {
+		return s.conn.Reply(req, nil, jsonrpc.Errorf(jsonrpc.CodeInvalidRequest, "server is shut down"))
+	}
+
+	var result interface{}
+	var err error
+	switch req.Method {
+	case "initialize":
+		result = s.initialize()
+	case "initialized":
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+	case "exit":
+		return jsonrpc.ErrStop
+	case "textDocument/didOpen":
+		var p didOpenParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			err = s.didOpen(p)
+		}
+	case "textDocument/didChange":
+		var p didChangeParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			s.didChange(p)
+		}
+	case "textDocument/didSave":
+		var p didSaveParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			err = s.linter.DidChange(urlutil.FilePath(p.TextDocument.URI))
+		}
+	case "textDocument/didClose":
+		var p didCloseParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			s.mu.Lock()
+			delete(s.docs, urlutil.FilePath(p.TextDocument.URI))
+			s.mu.Unlock()
+		}
+	case "textDocument/completion":
+		var p textDocumentPositionParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			result = s.completion(p)
+		}
+	case "textDocument/definition":
+		var p textDocumentPositionParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			result = s.definitionAt(p)
+		}
+	case "textDocument/hover":
+		var p textDocumentPositionParams
+		if err = jsonrpc.UnmarshalParams(req.Params, &p); err == nil {
+			result = s.hover(p)
+		}
+	default:
+		if req.IsNotification() {
+			// Notifications the server does not support, such as
+			// "$/cancelRequest", are ignored.
+			return nil
+		}
+		return s.conn.Reply(req, nil, jsonrpc.Errorf(jsonrpc.CodeMethodNotFound, "method %q not found", req.Method))
+	}
+
+	if err != nil {
+		if req.IsNotification() {
+			slog.Warn("language server notification failed", "method", req.Method, slog.Any("error", err))
+		}
+		err = jsonrpc.Errorf(jsonrpc.CodeInvalidParams, "%s", err)
+	}
+	return s.conn.Reply(req, result, err)
+}
+
+func (s *Server) initialize() initializeResult {
+	var result initializeResult
+	result.Capabilities.TextDocumentSync.OpenClose = true
+	result.Capabilities.TextDocumentSync.Change = 1
+	result.Capabilities.TextDocumentSync.Save = true
+	result.Capabilities.CompletionProvider.TriggerCharacters = []string{"."}
+	result.Capabilities.DefinitionProvider = true
+	result.Capabilities.HoverProvider = true
+	result.ServerInfo.Name = "helm"
+	result.ServerInfo.Version = version.GetVersion()
+	return result
+}
+
+// didOpen stores the document and starts linting its chart.
+func (s *Server) didOpen(p didOpenParams) error {
+	path := urlutil.FilePath(p.TextDocument.URI)
+	s.mu.Lock()
+	s.docs[path] = p.TextDocument.Text
+	s.mu.Unlock()
+
+	dir, ok := chartDir(path)
+	if !ok {
+		return nil
+	}
+	root := rootChartDir(dir)
+	s.mu.Lock()
+	tracked := s.charts[root]
+	s.charts[root] = true
+	s.mu.Unlock()
+	if tracked {
+		return nil
+	}
+	return s.linter.Open(lint.OpenParams{Chart: root})
+}
+
+// didChange replaces the text of the document. The server only asks for full
+// document changes, so the last change holds the whole text.
+func (s *Server) didChange(p didChangeParams) {
+	if len(p.ContentChanges) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs[urlutil.FilePath(p.TextDocument.URI)] = p.ContentChanges[len(p.ContentChanges)-1].Text
+}
+
+func (s *Server) completion(p textDocumentPositionParams) []CompletionItem {
+	path := urlutil.FilePath(p.TextDocument.URI)
+	text, ok := s.read(path)
+	if !ok {
+		return []CompletionItem{}
+	}
+	dir, ok := chartDir(path)
+	if !ok {
+		return []CompletionItem{}
+	}
+	items := s.completeValues(dir, text, offset(text, p.Position))
+	if items == nil {
+		return []CompletionItem{}
+	}
+	return items
+}
+
+// definitionAt returns the location of the named template called at the
+// position, or nil.
+func (s *Server) definitionAt(p textDocumentPositionParams) *Location {
+	path := urlutil.FilePath(p.TextDocument.URI)
+	text, ok := s.read(path)
+	if !ok {
+		return nil
+	}
+	name, ok := templateNameAt(text, offset(text, p.Position))
+	if !ok {
+		return nil
+	}
+	dir, ok := chartDir(path)
+	if !ok {
+		return nil
+	}
+	loc, ok := s.definition(rootChartDir(dir), name)
+	if !ok {
+		return nil
+	}
+	return &loc
+}
+
+// hover documents the template function at the position, or returns nil.
+func (s *Server) hover(p textDocumentPositionParams) *Hover {
+	text, ok := s.read(urlutil.FilePath(p.TextDocument.URI))
+	if !ok {
+		return nil
+	}
+	off := offset(text, p.Position)
+	if !inAction(text, off) {
+		return nil
+	}
+	word, start, end := wordAt(text, off)
+	// Fields such as .Values.toYaml are not functions.
+	if start > 0 && text[start-1] == '.' {
+		return nil
+	}
+	doc, ok := hoverFunction(word)
+	if !ok {
+		return nil
+	}
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: doc},
+		Range:    &Range{Start: position(text, start), End: position(text, end)},
+	}
+}
+
+// read returns the text of the open document at path, or the contents of the
+// file on disk.
+func (s *Server) read(path string) (string, bool) {
+	s.mu.Lock()
+	text, ok := s.docs[path]
+	s.mu.Unlock()
+	if ok {
+		return text, true
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
@@ -0,0 +1,245 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/jsonrpc"
+	"helm.sh/helm/v4/internal/urlutil"
+	"helm.sh/helm/v4/pkg/chart/v2/lint"
+)
+
+// writeChart writes a chart with a subchart to a temporary directory and
+// returns its path.
+func writeChart(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: authored\nversion: 0.1.0\n",
+		"values.yaml": "image:\n  repository: nginx\n  tag: \"1.25\"\nreplicas: 2\n",
+		"values.schema.json": `{"properties": {"image": {"type": "object", "properties": {
+			"pullPolicy": {"type": "string", "description": "When to pull the image."},
+			"tag": {"type": "string", "description": "The image tag."}}}}}`,
+		"templates/_helpers.tpl":        "{{/* The name. */}}\n{{- define \"authored.name\" -}}\n{{ .Chart.Name }}\n{{- end }}\n",
+		"templates/deployment.yaml":     "name: {{ include \"authored.name\" . }}\nimage: {{ .Values.image.t }}\n",
+		"charts/sub/Chart.yaml":         "apiVersion: v2\nname: sub\nversion: 0.1.0\n",
+		"charts/sub/templates/_sub.tpl": "{{ define \"sub.labels\" }}app: sub{{ end }}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestCompletion(t *testing.T) {
+	dir := writeChart(t)
+	s := NewServer(nil)
+	path := filepath.Join(dir, "templates", "deployment.yaml")
+
+	items := s.completion(textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: urlutil.FileURI(path)},
+		Position:     Position{Line: 1, Character: 25},
+	})
+	assert.Equal(t, []CompletionItem{
+		{Label: "tag", Kind: CompletionKindProperty, Detail: "string", Documentation: "The image tag."},
+	}, items)
+
+	// Open documents take precedence over the files on disk.
+	s.docs[path] = "{{ .Values. }}"
+	items = s.completion(textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: urlutil.FileURI(path)},
+		Position:     Position{Line: 0, Character: 11},
+	})
+	assert.Equal(t, []CompletionItem{
+		{Label: "image", Kind: CompletionKindProperty, Detail: "object"},
+		{Label: "replicas", Kind: CompletionKindProperty, Detail: "number", Documentation: "Default: 2"},
+	}, items)
+
+	// Outside of actions nothing is proposed.
+	s.docs[path] = ".Values."
+	assert.Empty(t, s.completion(textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: urlutil.FileURI(path)},
+		Position:     Position{Line: 0, Character: 8},
+	}))
+}
+
+func TestDefinition(t *testing.T) {
+	dir := writeChart(t)
+	s := NewServer(nil)
+
+	loc := s.definitionAt(textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: urlutil.FileURI(filepath.Join(dir, "templates", "deployment.yaml"))},
+		Position:     Position{Line: 0, Character: 22},
+	})
+	require.NotNil(t, loc)
+	assert.Equal(t, Location{
+		URI:   urlutil.FileURI(filepath.Join(dir, "templates", "_helpers.tpl")),
+		Range: Range{Start: Position{Line: 1}, End: Position{Line: 1}},
+	}, *loc)
+
+	// Templates defined in subcharts are found from the parent chart.
+	sub, ok := NewServer(nil).definition(dir, "sub.labels")
+	require.True(t, ok)
+	assert.Equal(t, urlutil.FileURI(filepath.Join(dir, "charts", "sub", "templates", "_sub.tpl")), sub.URI)
+
+	_, ok = s.definition(dir, "missing")
+	assert.False(t, ok)
+}
+
+func TestHover(t *testing.T) {
+	dir := writeChart(t)
+	s := NewServer(nil)
+	uri := urlutil.FileURI(filepath.Join(dir, "templates", "deployment.yaml"))
+
+	hover := s.hover(textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Position:     Position{Line: 0, Character: 11},
+	})
+	require.NotNil(t, hover)
+	assert.Equal(t, "markdown", hover.Contents.Kind)
+	assert.Contains(t, hover.Contents.Value, "include name context")
+	assert.Equal(t, &Range{Start: Position{Line: 0, Character: 9}, End: Position{Line: 0, Character: 16}}, hover.Range)
+
+	// Fields are not functions.
+	assert.Nil(t, s.hover(textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Position:     Position{Line: 1, Character: 21},
+	}))
+}
+
+// client drives a Server over pipes, like an editor does.
+type client struct {
+	t   *testing.T
+	in  *io.PipeWriter
+	out *jsonrpc.Conn
+	id  int
+}
+
+func (c *client) send(method string, params interface{}, notification bool) int {
+	c.t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if !notification {
+		c.id++
+		msg["id"] = c.id
+	}
+	data, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+	_, err = fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	require.NoError(c.t, err)
+	return c.id
+}
+
+// until reads messages until the response to id and returns it, along with
+// the diagnostics published before it by URI.
+func (c *client) until(id int) (*jsonrpc.Message, map[string][]lint.Diagnostic) {
+	c.t.Helper()
+	published := make(map[string][]lint.Diagnostic)
+	for {
+		msg, err := c.out.Read()
+		require.NoError(c.t, err)
+		if msg.Method == lint.MethodPublishDiagnostics {
+			var params lint.PublishDiagnosticsParams
+			require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+			published[params.URI] = params.Diagnostics
+			continue
+		}
+		if string(msg.ID) == strconv.Itoa(id) {
+			return msg, published
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	dir := writeChart(t)
+	broken := filepath.Join(dir, "templates", "broken.yaml")
+	require.NoError(t, os.WriteFile(broken, []byte("{{ .Values.missing.field }"), 0644))
+	brokenURI := urlutil.FileURI(broken)
+	deployment := urlutil.FileURI(filepath.Join(dir, "templates", "deployment.yaml"))
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer(nil).Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	c := &client{t: t, in: inW, out: jsonrpc.NewConn(outR, io.Discard)}
+
+	resp, _ := c.until(c.send("initialize", map[string]interface{}{}, false))
+	require.Nil(t, resp.Error)
+	var init initializeResult
+	require.NoError(t, json.Unmarshal(resp.Result, &init))
+	assert.Equal(t, "helm", init.ServerInfo.Name)
+	assert.True(t, init.Capabilities.HoverProvider)
+	c.send("initialized", struct{}{}, true)
+
+	// Opening a file lints its chart.
+	c.send("textDocument/didOpen", didOpenParams{TextDocument: textDocumentItem{
+		URI:  deployment,
+		Text: "name: {{ include \"authored.name\" . }}\nimage: {{ .Values.image.p }}\n",
+	}}, true)
+	resp, published := c.until(c.send("textDocument/completion", textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: deployment},
+		Position:     Position{Line: 1, Character: 25},
+	}, false))
+	require.Nil(t, resp.Error)
+	require.Len(t, published[brokenURI], 1, "got %v", published)
+	var items []CompletionItem
+	require.NoError(t, json.Unmarshal(resp.Result, &items))
+	require.Len(t, items, 1)
+	assert.Equal(t, "pullPolicy", items[0].Label)
+
+	// Saving a fixed file clears its diagnostics.
+	require.NoError(t, os.WriteFile(broken, []byte("# fixed\n"), 0644))
+	c.send("textDocument/didSave", didSaveParams{TextDocument: textDocumentIdentifier{URI: brokenURI}}, true)
+	resp, published = c.until(c.send("textDocument/definition", textDocumentPositionParams{
+		TextDocument: textDocumentIdentifier{URI: deployment},
+		Position:     Position{Line: 0, Character: 22},
+	}, false))
+	require.Nil(t, resp.Error)
+	diags, ok := published[brokenURI]
+	assert.True(t, ok, "expected the diagnostics of the fixed template to be cleared")
+	assert.Empty(t, diags)
+	var loc Location
+	require.NoError(t, json.Unmarshal(resp.Result, &loc))
+	assert.Equal(t, urlutil.FileURI(filepath.Join(dir, "templates", "_helpers.tpl")), loc.URI)
+
+	resp, _ = c.until(c.send("textDocument/formatting", struct{}{}, false))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.CodeMethodNotFound, resp.Error.Code)
+
+	resp, _ = c.until(c.send("shutdown", nil, false))
+	require.Nil(t, resp.Error)
+	resp, _ = c.until(c.send("textDocument/hover", textDocumentPositionParams{TextDocument: textDocumentIdentifier{URI: deployment}}, false))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, jsonrpc.CodeInvalidRequest, resp.Error.Code)
+	c.send("exit", nil, true)
+	require.NoError(t, <-done)
+}
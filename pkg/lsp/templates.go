@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsp
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"helm.sh/helm/v4/internal/urlutil"
+)
+
+// templateCall matches the template name in an include or template call.
+var templateCall = regexp.MustCompile(`\b(?:include|template)\s+"([^"]*)"`)
+
+// templateNameAt returns the name of the template called at the byte offset
+// off in text, if the offset is on the quoted name of an include or template
+// call.
+func templateNameAt(text string, off int) (string, bool) {
+	lineStart := strings.LastIndexByte(text[:off], '\n') + 1
+	lineEnd := strings.IndexByte(text[off:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(text)
+	} else {
+		lineEnd += off
+	}
+	line := text[lineStart:lineEnd]
+	for _, m := range templateCall.FindAllStringSubmatchIndex(line, -1) {
+		// The name including its quotes.
+		if start, end := lineStart+m[2]-1, lineStart+m[3]+1; off >= start && off <= end {
+			return line[m[2]:m[3]], true
+		}
+	}
+	return "", false
+}
+
+// definition finds where the template name is defined among the templates
+// of the chart tree rooted at rootDir. Templates are global across a chart and
+// its subcharts, so all of them are searched.
+func (s *Server) definition(rootDir, name string) (Location, bool) {
+	for _, path := range templateFiles(rootDir) {
+		text, ok := s.read(path)
+		if !ok {
+			continue
+		}
+		if off, ok := defineOffset(path, text, name); ok {
+			pos := position(text, off)
+			return Location{URI: urlutil.FileURI(path), Range: Range{Start: pos, End: pos}}, true
+		}
+	}
+	return Location{}, false
+}
+
+// defineOffset returns the byte offset of the define action of the template
+// name in text, as parsed by the template parser.
+func defineOffset(path, text, name string) (int, bool) {
+	tree := parse.New(path)
+	tree.Mode = parse.SkipFuncCheck | parse.ParseComments
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(text, "{{", "}}", trees); err != nil {
+		return 0, false
+	}
+	t, ok := trees[name]
+	if !ok || t.Root == nil || t.ParseName != path || name == path {
+		return 0, false
+	}
+	// The body starts after the define action; point at the action itself.
+	body := int(t.Root.Pos)
+	if body > len(text) {
+		return 0, false
+	}
+	if i := strings.LastIndex(text[:body], "{{"); i >= 0 {
+		return i, true
+	}
+	return body, true
+}
+
+// templateFiles returns the files in all templates directories of the chart
+// tree rooted at rootDir, sorted so that the templates of a chart come before
+// those of its subcharts.
+func templateFiles(rootDir string) []string {
+	var files []string
+	filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if name := d.Name(); path != rootDir && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return nil
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if slices.Contains(parts[:len(parts)-1], "templates") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i], string(filepath.Separator)) < strings.Count(files[j], string(filepath.Separator))
+	})
+	return files
+}
+
+// chartDir returns the directory of the chart containing path, the nearest
+// parent directory with a Chart.yaml.
+func chartDir(path string) (string, bool) {
+	dir := filepath.Dir(path)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "Chart.yaml")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// rootChartDir returns the top-level chart of the chart in dir, following
+// charts/ directories up as long as they belong to a chart.
+func rootChartDir(dir string) string {
+	for filepath.Base(filepath.Dir(dir)) == "charts" {
+		parent := filepath.Dir(filepath.Dir(dir))
+		if _, err := os.Stat(filepath.Join(parent, "Chart.yaml")); err != nil {
+			break
+		}
+		dir = parent
+	}
+	return dir
+}
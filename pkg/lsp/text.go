@@ -0,0 +1,98 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsp
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// offset returns the byte offset of pos in text, clamped to the text.
+func offset(text string, pos Position) int {
+	off := 0
+	for line := 0; line < pos.Line; line++ {
+		i := strings.IndexByte(text[off:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		off += i + 1
+	}
+	end := strings.IndexByte(text[off:], '\n')
+	if end < 0 {
+		end = len(text) - off
+	}
+	lineText := text[off : off+end]
+
+	units := 0
+	for i, r := range lineText {
+		if units >= pos.Character {
+			return off + i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return off + len(lineText)
+}
+
+// position returns the position of the byte offset off in text.
+func position(text string, off int) Position {
+	if off > len(text) {
+		off = len(text)
+	}
+	before := text[:off]
+	line := strings.Count(before, "\n")
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	units := 0
+	for _, r := range before[lineStart:] {
+		units += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: units}
+}
+
+// inAction reports whether the byte offset off in text is inside a template
+// action, between "{{" and "}}".
+func inAction(text string, off int) bool {
+	open := strings.LastIndex(text[:off], "{{")
+	return open >= 0 && open >= strings.LastIndex(text[:off], "}}")
+}
+
+// wordAt returns the identifier around the byte offset off in text and its
+// byte range.
+func wordAt(text string, off int) (string, int, int) {
+	start := off
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:start])
+		if !isIdentRune(r) {
+			break
+		}
+		start -= size
+	}
+	end := off
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !isIdentRune(r) {
+			break
+		}
+		end += size
+	}
+	return text[start:end], start, end
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
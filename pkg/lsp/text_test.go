@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetPosition(t *testing.T) {
+	text := "a: b\nnäme: 😀x\nlast"
+	tests := []struct {
+		pos Position
+		off int
+	}{
+		{Position{Line: 0, Character: 0}, 0},
+		{Position{Line: 0, Character: 3}, 3},
+		{Position{Line: 1, Character: 2}, 8},
+		// The emoji is two UTF-16 code units and four bytes.
+		{Position{Line: 1, Character: 8}, 16},
+		{Position{Line: 2, Character: 4}, 22},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.off, offset(text, tt.pos), "offset of %v", tt.pos)
+		assert.Equal(t, tt.pos, position(text, tt.off), "position of %d", tt.off)
+	}
+
+	// Positions past the end of a line or the text are clamped.
+	assert.Equal(t, 4, offset(text, Position{Line: 0, Character: 40}))
+	assert.Equal(t, len(text), offset(text, Position{Line: 9}))
+}
+
+func TestInActionWordAt(t *testing.T) {
+	text := "name: {{ include \"x\" . }} {{ toYaml"
+	assert.False(t, inAction(text, 3))
+	assert.True(t, inAction(text, 12))
+	assert.False(t, inAction(text, 26))
+	assert.True(t, inAction(text, len(text)))
+
+	word, start, end := wordAt(text, 12)
+	assert.Equal(t, "include", word)
+	assert.Equal(t, 9, start)
+	assert.Equal(t, 16, end)
+}
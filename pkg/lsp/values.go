@@ -0,0 +1,123 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/chart/v2/util"
+)
+
+// valuesPath matches a .Values path being typed, such as ".Values.image.ta",
+// at the end of the text before the cursor.
+var valuesPath = regexp.MustCompile(`\.Values((?:\.[A-Za-z0-9_]+)*)\.([A-Za-z0-9_]*)$`)
+
+// completeValues proposes the keys of the values path before the byte offset
+// off in text, taken from the chart's values schema and default values.
+func (s *Server) completeValues(chartDir, text string, off int) []CompletionItem {
+	m := valuesPath.FindStringSubmatch(text[:off])
+	if m == nil || !inAction(text, off) {
+		return nil
+	}
+	parent := strings.TrimPrefix(m[1], ".")
+	prefix := m[2]
+
+	var schema []byte
+	if data, ok := s.read(filepath.Join(chartDir, util.SchemafileName)); ok {
+		schema = []byte(data)
+	}
+	values := s.readYAML(filepath.Join(chartDir, util.ValuesfileName))
+	fields, err := util.ValuesFields(schema, values)
+	if err != nil {
+		// The schema may be invalid while it is edited.
+		if fields, err = util.ValuesFields(nil, values); err != nil {
+			return nil
+		}
+	}
+
+	var out []CompletionItem
+	for _, f := range fields {
+		key, ok := childKey(parent, f.Path)
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		out = append(out, CompletionItem{
+			Label:         key,
+			Kind:          CompletionKindProperty,
+			Detail:        strings.Join(f.Type, " | "),
+			Documentation: fieldDoc(f),
+		})
+	}
+	return out
+}
+
+// childKey returns the key of the value at path if it is a direct child of
+// the map at parent.
+func childKey(parent, path string) (string, bool) {
+	if parent != "" {
+		var ok bool
+		if path, ok = strings.CutPrefix(path, parent+"."); !ok {
+			return "", false
+		}
+	}
+	if path == "" || strings.ContainsAny(path, ".[") {
+		return "", false
+	}
+	return path, true
+}
+
+// fieldDoc documents a value with its description, or its default if it is
+// a scalar, and its allowed values.
+func fieldDoc(f util.ValuesField) string {
+	doc := f.Description
+	if doc == "" {
+		switch f.Default.(type) {
+		case map[string]interface{}, []interface{}, nil:
+		default:
+			doc = fmt.Sprintf("Default: %v", f.Default)
+		}
+	}
+	if len(f.Enum) > 0 {
+		allowed := make([]string, 0, len(f.Enum))
+		for _, v := range f.Enum {
+			allowed = append(allowed, fmt.Sprint(v))
+		}
+		if doc != "" {
+			doc += "\n\n"
+		}
+		doc += "Allowed: " + strings.Join(allowed, ", ")
+	}
+	return doc
+}
+
+// readYAML decodes a YAML object from the open document or file at path.
+func (s *Server) readYAML(path string) map[string]interface{} {
+	data, ok := s.read(path)
+	if !ok {
+		return nil
+	}
+	var out map[string]interface{}
+	if yaml.Unmarshal([]byte(data), &out) != nil {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrenderer
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// NewExec creates a PostRenderer that runs a command, which reads the
+// manifests on its standard input and writes the modified manifests to its
+// standard output. The command is looked up in the PATH unless it is a path.
+func NewExec(command string, args ...string) (PostRenderer, error) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find post-renderer command %q: %w", command, err)
+	}
+	return &execRenderer{path: path, args: args}, nil
+}
+
+type execRenderer struct {
+	path string
+	args []string
+}
+
+// Run implements PostRenderer by running the command.
+func (r *execRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	cmd := exec.Command(r.path, r.args...)
+	cmd.Stdin = renderedManifests
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("post-renderer %q failed: %w: %s", r.path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// If the command returned almost nothing, it's likely that it didn't
+	// successfully render anything
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil, fmt.Errorf("post-renderer %q produced empty output", r.path)
+	}
+	return &stdout, nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrenderer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	// KustomizeName is the name of the built-in kustomize post-renderer, as
+	// given to --post-renderer.
+	KustomizeName = "kustomize"
+	// KustomizeInputFile is the file the rendered manifests are made
+	// available as in the kustomize overlay, which lists it in its resources.
+	// It is the file the usual wrapper scripts write the manifests to, so
+	// their overlays can be used unchanged.
+	KustomizeInputFile = "all.yaml"
+)
+
+// NewKustomize creates a PostRenderer that builds a kustomize overlay on top of
+// the rendered manifests. The overlay is the current directory if dir is empty.
+//
+// The manifests are not written to the overlay; they are only visible to
+// kustomize, as the file KustomizeInputFile of the overlay directory.
+func NewKustomize(dir string) (PostRenderer, error) {
+	if dir == "" {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	abs, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find kustomize overlay %q: %w", dir, err)
+	}
+	if fi, err := os.Stat(abs); err != nil || !fi.IsDir() {
+		return nil, fmt.Errorf("kustomize overlay %q is not a directory", dir)
+	}
+	return &kustomizeRenderer{dir: abs}, nil
+}
+
+type kustomizeRenderer struct {
+	dir string
+}
+
+// Run implements PostRenderer by building the overlay.
+func (r *kustomizeRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	fSys := &inputFileSystem{
+		FileSystem: filesys.MakeFsOnDisk(),
+		path:       filepath.Join(r.dir, KustomizeInputFile),
+		data:       renderedManifests.Bytes(),
+	}
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, r.dir)
+	if err != nil {
+		return nil, fmt.Errorf("kustomize post-renderer failed to build %s: %w", r.dir, err)
+	}
+	out, err := resources.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, fmt.Errorf("kustomize post-renderer produced empty output for %s", r.dir)
+	}
+	return bytes.NewBuffer(out), nil
+}
+
+// inputFileSystem is the file system on disk with one more file, holding the
+// rendered manifests.
+type inputFileSystem struct {
+	filesys.FileSystem
+	path string
+	data []byte
+}
+
+func (f *inputFileSystem) isInput(path string) bool {
+	abs, err := filepath.Abs(path)
+	return err == nil && abs == f.path
+}
+
+func (f *inputFileSystem) CleanedAbs(path string) (filesys.ConfirmedDir, string, error) {
+	if f.isInput(path) {
+		return filesys.ConfirmedDir(filepath.Dir(f.path)), filepath.Base(f.path), nil
+	}
+	return f.FileSystem.CleanedAbs(path)
+}
+
+func (f *inputFileSystem) Exists(path string) bool {
+	return f.isInput(path) || f.FileSystem.Exists(path)
+}
+
+func (f *inputFileSystem) IsDir(path string) bool {
+	return !f.isInput(path) && f.FileSystem.IsDir(path)
+}
+
+func (f *inputFileSystem) ReadFile(path string) ([]byte, error) {
+	if f.isInput(path) {
+		return bytes.Clone(f.data), nil
+	}
+	return f.FileSystem.ReadFile(path)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrenderer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+const kustomizeManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  mode: dev
+`
+
+func writeOverlay(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base")
+	overlay := filepath.Join(dir, "overlay", "prod")
+	require.NoError(t, os.MkdirAll(base, 0755))
+	require.NoError(t, os.MkdirAll(overlay, 0755))
+
+	files := map[string]string{
+		filepath.Join(base, "kustomization.yaml"): "resources:\n- secret.yaml\n",
+		filepath.Join(base, "secret.yaml"):        "apiVersion: v1\nkind: Secret\nmetadata:\n  name: token\n",
+		filepath.Join(overlay, "kustomization.yaml"): `resources:
+- all.yaml
+- ../../base
+namePrefix: prod-
+patches:
+- patch: |-
+    - op: replace
+      path: /data/mode
+      value: prod
+  target:
+    kind: ConfigMap
+    name: settings
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(name, []byte(content), 0644))
+	}
+	return overlay
+}
+
+func TestKustomize(t *testing.T) {
+	overlay := writeOverlay(t)
+	r, err := NewKustomize(overlay)
+	require.NoError(t, err)
+
+	out, err := r.Run(bytes.NewBufferString(kustomizeManifests))
+	require.NoError(t, err)
+	expected := `apiVersion: v1
+data:
+  mode: prod
+kind: ConfigMap
+metadata:
+  name: prod-settings
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: prod-token
+`
+	assert.Equal(t, expected, out.String())
+
+	// The manifests are not written to the overlay.
+	_, err = os.Stat(filepath.Join(overlay, KustomizeInputFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestKustomizeErrors(t *testing.T) {
+	_, err := NewKustomize(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	// A directory without a kustomization cannot be built.
+	r, err := NewKustomize(t.TempDir())
+	require.NoError(t, err)
+	_, err = r.Run(bytes.NewBufferString(kustomizeManifests))
+	assert.Error(t, err)
+}
+
+func TestNewKustomize(t *testing.T) {
+	s := cli.New()
+	s.PluginsDirectory = "testdata/plugins"
+	overlay := writeOverlay(t)
+
+	r, err := New(s, KustomizeName, overlay)
+	require.NoError(t, err)
+	assert.IsType(t, &kustomizeRenderer{}, r)
+
+	_, err = New(s, KustomizeName, overlay, "extra")
+	assert.Error(t, err)
+
+	_, err = New(s, "not-a-plugin", overlay)
+	assert.Error(t, err)
+
+	// An installed kustomize plugin that fails to load is not replaced by
+	// the built-in post-renderer.
+	s.PluginsDirectory = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(s.PluginsDirectory, KustomizeName), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(s.PluginsDirectory, KustomizeName, "plugin.yaml"), []byte("name: [kustomize\n"), 0644))
+	_, err = New(s, KustomizeName, overlay)
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrenderer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+// Stage is a named step of a Pipeline.
+type Stage struct {
+	// Name identifies the stage in errors and in the names of the captured
+	// manifests.
+	Name     string
+	Renderer PostRenderer
+}
+
+// Pipeline is a PostRenderer that runs an ordered chain of post-renderers,
+// each one receiving the output of the one before.
+type Pipeline struct {
+	Stages []Stage
+	// CaptureDir, if set, is a directory the input and output of every stage
+	// are written to, for debugging. The files are named after the position
+	// and name of the stage, as in "01-labels.input.yaml".
+	CaptureDir string
+}
+
+// Run implements PostRenderer by running the stages in order.
+func (p *Pipeline) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	if p.CaptureDir != "" {
+		if err := os.MkdirAll(p.CaptureDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	manifests := renderedManifests
+	for i, s := range p.Stages {
+		if err := p.capture(i, s, "input", manifests); err != nil {
+			return nil, err
+		}
+		// The stage may consume its input, so it is given a copy.
+		out, err := s.Renderer.Run(bytes.NewBuffer(bytes.Clone(manifests.Bytes())))
+		if err != nil {
+			return nil, fmt.Errorf("post-render stage %d (%s): %w", i+1, s.Name, err)
+		}
+		if out == nil {
+			return nil, fmt.Errorf("post-render stage %d (%s) produced no output", i+1, s.Name)
+		}
+		if err := p.capture(i, s, "output", out); err != nil {
+			return nil, err
+		}
+		manifests = out
+	}
+	return manifests, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// capture writes the input or output of a stage to CaptureDir, if set.
+func (p *Pipeline) capture(i int, s Stage, kind string, manifests *bytes.Buffer) error {
+	if p.CaptureDir == "" {
+		return nil
+	}
+	name := fmt.Sprintf("%02d-%s.%s.yaml", i+1, unsafeFileChars.ReplaceAllString(s.Name, "_"), kind)
+	return os.WriteFile(filepath.Join(p.CaptureDir, name), manifests.Bytes(), 0644)
+}
+
+// StageConfig configures a stage of a pipeline file. Exactly one of Plugin and
+// Exec must be set.
+type StageConfig struct {
+	// Name identifies the stage. It defaults to the plugin or command.
+	Name string `json:"name,omitempty"`
+	// Plugin is the name of a postrenderer plugin to run, or "kustomize" for
+	// the built-in kustomize post-renderer.
+	Plugin string `json:"plugin,omitempty"`
+	// Exec is a command to run, which reads the manifests on its standard
+	// input and writes them to its standard output.
+	Exec string `json:"exec,omitempty"`
+	// Args are the arguments of the plugin or command.
+	Args []string `json:"args,omitempty"`
+}
+
+// PipelineConfig is the content of a pipeline file, as given to
+// --post-renderer-pipeline.
+//
+//	stages:
+//	- plugin: add-labels
+//	  args: ["team=payments"]
+//	- name: strip-status
+//	  exec: ./bin/strip-status
+type PipelineConfig struct {
+	Stages []StageConfig `json:"stages"`
+}
+
+// LoadPipelineFile reads a pipeline file and creates the post-renderers of
+// its stages. Relative commands of exec stages are resolved from the
+// directory of the file.
+func LoadPipelineFile(settings *cli.EnvSettings, filename string) (*Pipeline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var config PipelineConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid post-render pipeline file %s: %w", filename, err)
+	}
+	if len(config.Stages) == 0 {
+		return nil, fmt.Errorf("post-render pipeline file %s has no stages", filename)
+	}
+
+	p := &Pipeline{}
+	for i, sc := range config.Stages {
+		if sc.Exec != "" && !filepath.IsAbs(sc.Exec) && filepath.Base(sc.Exec) != sc.Exec {
+			sc.Exec = filepath.Join(filepath.Dir(filename), sc.Exec)
+		}
+		s, err := NewStage(settings, sc)
+		if err != nil {
+			return nil, fmt.Errorf("post-render pipeline file %s, stage %d: %w", filename, i+1, err)
+		}
+		p.Stages = append(p.Stages, s)
+	}
+	return p, nil
+}
+
+// NewStage creates the post-renderer of a stage.
+func NewStage(settings *cli.EnvSettings, sc StageConfig) (Stage, error) {
+	var r PostRenderer
+	var err error
+	name := sc.Name
+	switch {
+	case sc.Plugin != "" && sc.Exec != "":
+		return Stage{}, errors.New("only one of plugin and exec can be set")
+	case sc.Plugin != "":
+		r, err = New(settings, sc.Plugin, sc.Args...)
+		if name == "" {
+			name = sc.Plugin
+		}
+	case sc.Exec != "":
+		r, err = NewExec(sc.Exec, sc.Args...)
+		if name == "" {
+			name = filepath.Base(sc.Exec)
+		}
+	default:
+		return Stage{}, errors.New("one of plugin and exec must be set")
+	}
+	if err != nil {
+		return Stage{}, err
+	}
+	return Stage{Name: name, Renderer: r}, nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrenderer
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+)
+
+// appendRenderer appends a suffix to the manifests.
+type appendRenderer string
+
+func (r appendRenderer) Run(in *bytes.Buffer) (*bytes.Buffer, error) {
+	return bytes.NewBufferString(in.String() + string(r)), nil
+}
+
+type failingRenderer struct{}
+
+func (failingRenderer) Run(*bytes.Buffer) (*bytes.Buffer, error) {
+	return nil, errors.New("boom")
+}
+
+func TestPipelineRun(t *testing.T) {
+	dir := t.TempDir()
+	p := &Pipeline{
+		Stages: []Stage{
+			{Name: "first", Renderer: appendRenderer("-a")},
+			{Name: "second/stage", Renderer: appendRenderer("-b")},
+		},
+		CaptureDir: dir,
+	}
+
+	out, err := p.Run(bytes.NewBufferString("manifest"))
+	require.NoError(t, err)
+	assert.Equal(t, "manifest-a-b", out.String())
+
+	captured := map[string]string{
+		"01-first.input.yaml":         "manifest",
+		"01-first.output.yaml":        "manifest-a",
+		"02-second_stage.input.yaml":  "manifest-a",
+		"02-second_stage.output.yaml": "manifest-a-b",
+	}
+	for name, expected := range captured {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data), name)
+	}
+}
+
+func TestPipelineRunError(t *testing.T) {
+	p := &Pipeline{Stages: []Stage{
+		{Name: "first", Renderer: appendRenderer("-a")},
+		{Name: "broken", Renderer: failingRenderer{}},
+	}}
+	_, err := p.Run(bytes.NewBufferString("manifest"))
+	require.EqualError(t, err, "post-render stage 2 (broken): boom")
+}
+
+func TestExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping on windows")
+	}
+	r, err := NewExec("sed", "s/FOOTEST/BARTEST/g")
+	require.NoError(t, err)
+	out, err := r.Run(bytes.NewBufferString("FOOTEST"))
+	require.NoError(t, err)
+	assert.Equal(t, "BARTEST", out.String())
+
+	_, err = NewExec("helm-post-renderer-that-does-not-exist")
+	assert.Error(t, err)
+
+	r, err = NewExec("sh", "-c", "echo failed >&2; exit 1")
+	require.NoError(t, err)
+	_, err = r.Run(bytes.NewBufferString("FOOTEST"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed")
+}
+
+func TestLoadPipelineFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// the plugin uses a basic sed example, so skip this test on windows
+		t.Skip("skipping on windows")
+	}
+	s := cli.New()
+	s.PluginsDirectory = "testdata/plugins"
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nsed s/BARTEST/BAZTEST/g\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "baz.sh"), []byte(script), 0755))
+	file := filepath.Join(dir, "pipeline.yaml")
+	config := `stages:
+- plugin: postrenderer-v1
+- name: baz
+  exec: ./baz.sh
+`
+	require.NoError(t, os.WriteFile(file, []byte(config), 0644))
+
+	p, err := LoadPipelineFile(s, file)
+	require.NoError(t, err)
+	require.Len(t, p.Stages, 2)
+	assert.Equal(t, "postrenderer-v1", p.Stages[0].Name)
+	assert.Equal(t, "baz", p.Stages[1].Name)
+
+	out, err := p.Run(bytes.NewBufferString("FOOTEST"))
+	require.NoError(t, err)
+	assert.Equal(t, "BAZTEST", strings.TrimSpace(out.String()))
+
+	for config, expected := range map[string]string{
+		"stages: []\n":                                       "has no stages",
+		"stages:\n- args: [a]\n":                             "one of plugin and exec must be set",
+		"stages:\n- plugin: a\n  exec: b\n":                  "only one of plugin and exec can be set",
+		"stages:\n- plugin: postrenderer-v1\n  unknown: x\n": "invalid post-render pipeline file",
+	} {
+		require.NoError(t, os.WriteFile(file, []byte(config), 0644))
+		_, err := LoadPipelineFile(s, file)
+		require.Error(t, err, config)
+		assert.Contains(t, err.Error(), expected, config)
+	}
+}
@@ -16,6 +16,7 @@ package postrenderer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 
@@ -34,6 +35,26 @@ type PostRenderer interface {
 	Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error)
 }
 
+// New creates the PostRenderer given by name to --post-renderer: the plugin of
+// that name, or the built-in kustomize post-renderer if the name is
+// KustomizeName and no plugin of that name is installed. The only argument of
+// the kustomize post-renderer is the directory of the overlay.
+func New(settings *cli.EnvSettings, name string, args ...string) (PostRenderer, error) {
+	pr, err := NewPostRendererPlugin(settings, name, args...)
+	var notFound plugin.NotFoundError
+	if err == nil || name != KustomizeName || !errors.As(err, &notFound) {
+		return pr, err
+	}
+	switch len(args) {
+	case 0:
+		return NewKustomize("")
+	case 1:
+		return NewKustomize(args[0])
+	default:
+		return nil, fmt.Errorf("the %s post-renderer takes the directory of an overlay as its only argument, got %d arguments", KustomizeName, len(args))
+	}
+}
+
 // NewPostRendererPlugin creates a PostRenderer that uses the plugin's Runtime
 func NewPostRendererPlugin(settings *cli.EnvSettings, pluginName string, args ...string) (PostRenderer, error) {
 	descriptor := plugin.Descriptor{
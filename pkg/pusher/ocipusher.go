@@ -77,6 +77,9 @@ func (pusher *OCIPusher) push(chartRef, href string) error {
 	}
 
 	var pushOpts []registry.PushOption
+	if pusher.opts.progress != nil {
+		pushOpts = append(pushOpts, registry.PushOptProgress(pusher.opts.progress))
+	}
 	provRef := fmt.Sprintf("%s.prov", chartRef)
 	if _, err := os.Stat(provRef); err == nil {
 		provBytes, err := os.ReadFile(provRef)
@@ -34,6 +34,7 @@ type options struct {
 	caFile                string
 	insecureSkipTLSverify bool
 	plainHTTP             bool
+	progress              registry.PushProgressFunc
 }
 
 // Option allows specifying various settings configurable by the user for overriding the defaults
@@ -69,6 +70,13 @@ func WithPlainHTTP(plainHTTP bool) Option {
 	}
 }
 
+// WithProgress sets the function called as the content of a chart is uploaded.
+func WithProgress(progress registry.PushProgressFunc) Option {
+	return func(opts *options) {
+		opts.progress = progress
+	}
+}
+
 // Pusher is an interface to support upload to the specified URL.
 type Pusher interface {
 	// Push file content by url string
@@ -0,0 +1,41 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+// Rollback bundle media types
+const (
+	// RollbackBundleConfigMediaType is the media type of the config of a
+	// rollback bundle manifest.
+	RollbackBundleConfigMediaType = "application/vnd.cncf.helm.rollback-bundle.config.v1+json"
+
+	// RollbackBundleLayerMediaType is the media type of rollback bundle content.
+	RollbackBundleLayerMediaType = "application/vnd.cncf.helm.rollback-bundle.v1+gzip"
+)
+
+// PushRollbackBundle uploads a rollback bundle to a registry. The config
+// describes the bundle and is stored in the manifest config, so that it can
+// be inspected without downloading the bundle. It returns the digest of the
+// manifest.
+func (c *Client) PushRollbackBundle(data, config []byte, ref string) (string, error) {
+	return c.pushSingleLayer(data, RollbackBundleLayerMediaType, config, RollbackBundleConfigMediaType, ref)
+}
+
+// PullRollbackBundle downloads the content of a rollback bundle from a
+// registry.
+func (c *Client) PullRollbackBundle(ref string) ([]byte, error) {
+	return c.pullSingleLayer(ref, RollbackBundleLayerMediaType, RollbackBundleConfigMediaType)
+}
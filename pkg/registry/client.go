@@ -29,8 +29,10 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/opencontainers/go-digest"
 	"github.com/opencontainers/image-spec/specs-go"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
@@ -626,6 +628,9 @@ type (
 		provData     []byte
 		strictMode   bool
 		creationTime string
+		chunkSize    int64
+		retries      int
+		progress     PushProgressFunc
 	}
 )
 
@@ -638,10 +643,21 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 
 	operation := &pushOperation{
 		strictMode: true, // By default, enable strict mode
+		chunkSize:  DefaultPushChunkSize,
+		retries:    DefaultPushRetries,
 	}
 	for _, option := range options {
 		option(operation)
 	}
+	if progress := operation.progress; progress != nil {
+		// The config and layers are copied concurrently.
+		var mu sync.Mutex
+		operation.progress = func(p PushProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress(p)
+		}
+	}
 	meta, err := extractChartMeta(data)
 	if err != nil {
 		return nil, err
@@ -702,7 +718,48 @@ func (c *Client) Push(data []byte, ref string, options ...PushOption) (*PushResu
 	repository.PlainHTTP = c.plainHTTP
 	repository.Client = c.authorizer
 
-	manifestDescriptor, err = oras.ExtendedCopy(ctx, memoryStore, parsedRef.String(), repository, parsedRef.String(), oras.DefaultExtendedCopyOptions)
+	// Layers larger than a chunk are uploaded first, in chunks so that the
+	// upload can resume after a failure, and are then skipped by the copy.
+	contents := map[digest.Digest][]byte{chartDescriptor.Digest: data}
+	if operation.provData != nil {
+		contents[provDescriptor.Digest] = operation.provData
+	}
+	for _, layer := range layers {
+		if operation.chunkSize <= 0 || layer.Size <= operation.chunkSize {
+			continue
+		}
+		exists, err := repository.Exists(ctx, layer)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if err := c.pushBlobChunked(ctx, parsedRef, layer, contents[layer.Digest], operation); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	copyOptions := oras.DefaultExtendedCopyOptions
+	if operation.progress != nil {
+		report := func(_ context.Context, desc ocispec.Descriptor) error {
+			operation.progress(PushProgress{
+				Digest:    desc.Digest.String(),
+				MediaType: desc.MediaType,
+				Uploaded:  desc.Size,
+				Total:     desc.Size,
+			})
+			return nil
+		}
+		copyOptions.PostCopy = report
+		copyOptions.OnCopySkipped = report
+	}
+	err = retryPush(operation.retries, func(err error) {
+		fmt.Fprintf(c.out, "Retrying the push of %s: %s\n", parsedRef.String(), err)
+	}, func() error {
+		var err error
+		manifestDescriptor, err = oras.ExtendedCopy(ctx, memoryStore, parsedRef.String(), repository, parsedRef.String(), copyOptions)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +73,40 @@ func (suite *HTTPRegistryClientTestSuite) Test_4_ManInTheMiddle() {
 	suite.True(errors.Is(err, content.ErrMismatchedDigest))
 }
 
+func (suite *HTTPRegistryClientTestSuite) Test_5_RollbackBundle() {
+	ref := fmt.Sprintf("%s/testrepo/bundles/myrelease:v3", suite.DockerRegistryHost)
+	data := []byte("bundle content")
+
+	digest, err := suite.RegistryClient.PushRollbackBundle(data, []byte(`{"name":"myrelease","revision":3}`), ref)
+	suite.Nil(err, "no error pushing rollback bundle")
+	suite.NotEmpty(digest)
+
+	pulled, err := suite.RegistryClient.PullRollbackBundle(ref)
+	suite.Nil(err, "no error pulling rollback bundle")
+	suite.Equal(data, pulled)
+
+	// Charts are not rollback bundles.
+	_, err = suite.RegistryClient.PullRollbackBundle(fmt.Sprintf("%s/testrepo/local-subchart:0.1.0", suite.DockerRegistryHost))
+	suite.NotNil(err, "error pulling a chart as a rollback bundle")
+}
+
+func (suite *HTTPRegistryClientTestSuite) Test_6_Values() {
+	ref := fmt.Sprintf("%s/testrepo/values/production:v1", suite.DockerRegistryHost)
+	data := []byte("replicaCount: 3\n")
+
+	digest, err := suite.RegistryClient.PushValues(data, ref)
+	suite.Nil(err, "no error pushing values")
+	suite.NotEmpty(digest)
+
+	pulled, err := suite.RegistryClient.PullValues(ref)
+	suite.Nil(err, "no error pulling values")
+	suite.Equal(data, pulled)
+
+	// Charts are not values files.
+	_, err = suite.RegistryClient.PullValues(fmt.Sprintf("%s/testrepo/local-subchart:0.1.0", suite.DockerRegistryHost))
+	suite.NotNil(err, "error pulling a chart as values")
+}
+
 func TestHTTPRegistryClientTestSuite(t *testing.T) {
 	suite.Run(t, new(HTTPRegistryClientTestSuite))
 }
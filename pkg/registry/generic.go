@@ -18,6 +18,7 @@ package registry
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"sort"
@@ -160,3 +161,63 @@ func (c *GenericClient) PullGeneric(ref string, options GenericPullOptions) (*Ge
 func (c *GenericClient) GetDescriptorData(store *memory.Store, desc ocispec.Descriptor) ([]byte, error) {
 	return content.FetchAll(context.Background(), store, desc)
 }
+
+// pushSingleLayer uploads an artifact made of a single layer of data to a
+// registry, with config as the manifest config. It returns the digest of the
+// manifest.
+func (c *Client) pushSingleLayer(data []byte, layerMediaType string, config []byte, configMediaType string, ref string) (string, error) {
+	parsedRef, err := newReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	memoryStore := memory.New()
+	layer, err := oras.PushBytes(ctx, memoryStore, layerMediaType, data)
+	if err != nil {
+		return "", err
+	}
+	configDescriptor, err := oras.PushBytes(ctx, memoryStore, configMediaType, config)
+	if err != nil {
+		return "", err
+	}
+	if _, err := c.tagManifest(ctx, memoryStore, configDescriptor, []ocispec.Descriptor{layer}, nil, parsedRef); err != nil {
+		return "", err
+	}
+
+	repository, err := remote.NewRepository(parsedRef.String())
+	if err != nil {
+		return "", err
+	}
+	repository.PlainHTTP = c.plainHTTP
+	repository.Client = c.authorizer
+
+	manifest, err := oras.ExtendedCopy(ctx, memoryStore, parsedRef.String(), repository, parsedRef.String(), oras.DefaultExtendedCopyOptions)
+	if err != nil {
+		return "", err
+	}
+	return manifest.Digest.String(), nil
+}
+
+// pullSingleLayer downloads the layer of an artifact pushed with
+// pushSingleLayer.
+func (c *Client) pullSingleLayer(ref, layerMediaType, configMediaType string) ([]byte, error) {
+	genericClient := c.Generic()
+	result, err := genericClient.PullGeneric(ref, GenericPullOptions{
+		AllowedMediaTypes: []string{
+			ocispec.MediaTypeImageManifest,
+			configMediaType,
+			layerMediaType,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, desc := range result.Descriptors {
+		if desc.MediaType == layerMediaType {
+			return genericClient.GetDescriptorData(result.MemoryStore, desc)
+		}
+	}
+	return nil, fmt.Errorf("manifest does not contain a layer with mediatype %s", layerMediaType)
+}
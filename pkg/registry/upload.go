@@ -0,0 +1,338 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry // import "helm.sh/helm/v4/pkg/registry"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// DefaultPushChunkSize is the size of the chunks the layers of a chart
+	// larger than it are uploaded in.
+	DefaultPushChunkSize int64 = 16 << 20
+
+	// DefaultPushRetries is the number of times a failed upload is retried.
+	DefaultPushRetries = 3
+)
+
+// pushRetryBackoff is the time to wait before the first retry of a failed
+// upload. It doubles with each retry.
+var pushRetryBackoff = time.Second
+
+// PushProgress reports the progress of the upload of a layer or of the
+// config of a chart.
+type PushProgress struct {
+	// Digest is the digest of the content being uploaded
+	Digest string
+	// MediaType is the media type of the content being uploaded
+	MediaType string
+	// Uploaded is the number of bytes the registry has received
+	Uploaded int64
+	// Total is the size of the content
+	Total int64
+}
+
+// PushProgressFunc is called as the content of a chart is uploaded. The calls
+// are serialized.
+type PushProgressFunc func(PushProgress)
+
+// PushOptChunkSize returns a function that sets the size of the chunks the
+// layers larger than it are uploaded in. A size of zero or less disables
+// chunked uploads.
+func PushOptChunkSize(size int64) PushOption {
+	return func(operation *pushOperation) {
+		operation.chunkSize = size
+	}
+}
+
+// PushOptRetries returns a function that sets the number of times a failed
+// upload is retried. Chunked uploads resume where the registry stopped
+// receiving them.
+func PushOptRetries(retries int) PushOption {
+	return func(operation *pushOperation) {
+		operation.retries = retries
+	}
+}
+
+// PushOptProgress returns a function that sets the function called as the
+// content of a chart is uploaded.
+func PushOptProgress(progress PushProgressFunc) PushOption {
+	return func(operation *pushOperation) {
+		operation.progress = progress
+	}
+}
+
+// errUploadUnknown is returned when the registry no longer knows an upload,
+// which must then be restarted.
+var errUploadUnknown = errors.New("upload unknown to the registry")
+
+// blobUpload uploads a blob to a repository in chunks, following the chunked
+// upload flow of the OCI distribution specification.
+type blobUpload struct {
+	client    *Client
+	ref       reference
+	desc      ocispec.Descriptor
+	data      []byte
+	chunkSize int64
+	progress  PushProgressFunc
+
+	// location is the URL of the upload session
+	location *url.URL
+	// offset is the number of bytes the registry has received
+	offset int64
+}
+
+// pushBlobChunked uploads a blob in chunks, retrying failed requests and
+// resuming the upload from the last byte the registry received.
+func (c *Client) pushBlobChunked(ctx context.Context, ref reference, desc ocispec.Descriptor, data []byte, operation *pushOperation) error {
+	u := &blobUpload{
+		client:    c,
+		ref:       ref,
+		desc:      desc,
+		data:      data,
+		chunkSize: operation.chunkSize,
+		progress:  operation.progress,
+	}
+	return retryPush(operation.retries, func(err error) {
+		fmt.Fprintf(c.out, "Retrying the upload of %s: %s\n", desc.Digest, err)
+	}, func() error {
+		return u.push(ctx)
+	})
+}
+
+// retryPush calls push until it succeeds or has been retried retries times,
+// waiting longer after each failure.
+func retryPush(retries int, onRetry func(error), push func() error) error {
+	backoff := pushRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := push()
+		if err == nil || attempt >= retries || !retryablePushError(err) {
+			return err
+		}
+		onRetry(err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryablePushError reports whether a push that failed with err may succeed
+// when retried. Registries that cannot be reached at all are not retried.
+func retryablePushError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// push resumes the upload, starting a new one if there is none.
+func (u *blobUpload) push(ctx context.Context) error {
+	if u.location != nil {
+		err := u.status(ctx)
+		if errors.Is(err, errUploadUnknown) {
+			u.location, u.offset = nil, 0
+		} else if err != nil {
+			return err
+		}
+	}
+	if u.location == nil {
+		if err := u.start(ctx); err != nil {
+			return err
+		}
+	}
+
+	size := int64(len(u.data))
+	for u.offset < size {
+		end := min(u.offset+u.chunkSize, size)
+		if err := u.patch(ctx, u.offset, end); err != nil {
+			return err
+		}
+		u.report()
+	}
+	return u.finish(ctx)
+}
+
+// start starts an upload session.
+func (u *blobUpload) start(ctx context.Context) error {
+	scheme := "https"
+	if u.client.plainHTTP {
+		scheme = "http"
+	}
+	base := &url.URL{Scheme: scheme, Host: u.ref.Registry, Path: fmt.Sprintf("/v2/%s/blobs/uploads/", u.ref.Repository)}
+	resp, err := u.do(ctx, http.MethodPost, base, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return uploadError(resp)
+	}
+	u.offset = 0
+	return u.updateLocation(base, resp)
+}
+
+// patch uploads the bytes of the blob from start to end.
+func (u *blobUpload) patch(ctx context.Context, start, end int64) error {
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Range", fmt.Sprintf("%d-%d", start, end-1))
+	resp, err := u.do(ctx, http.MethodPatch, u.location, header, u.data[start:end])
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+	case http.StatusNotFound:
+		return errUploadUnknown
+	default:
+		return uploadError(resp)
+	}
+	if err := u.updateLocation(u.location, resp); err != nil {
+		return err
+	}
+	if last, ok := uploadRange(resp); ok {
+		u.offset = last + 1
+	} else {
+		u.offset = end
+	}
+	return nil
+}
+
+// status updates the offset of the upload from the registry.
+func (u *blobUpload) status(ctx context.Context) error {
+	resp, err := u.do(ctx, http.MethodGet, u.location, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+	case http.StatusNotFound:
+		return errUploadUnknown
+	default:
+		return uploadError(resp)
+	}
+	if err := u.updateLocation(u.location, resp); err != nil {
+		return err
+	}
+	u.offset = 0
+	if last, ok := uploadRange(resp); ok {
+		u.offset = last + 1
+	}
+	return nil
+}
+
+// finish completes the upload.
+func (u *blobUpload) finish(ctx context.Context) error {
+	loc := *u.location
+	q := loc.Query()
+	q.Set("digest", u.desc.Digest.String())
+	loc.RawQuery = q.Encode()
+	resp, err := u.do(ctx, http.MethodPut, &loc, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusNotFound:
+		return errUploadUnknown
+	default:
+		return uploadError(resp)
+	}
+	if dgst := resp.Header.Get("Docker-Content-Digest"); dgst != "" && digest.Digest(dgst) != u.desc.Digest {
+		return fmt.Errorf("registry returned digest %s for the upload of %s", dgst, u.desc.Digest)
+	}
+	return nil
+}
+
+func (u *blobUpload) do(ctx context.Context, method string, loc *url.URL, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, loc.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.ContentLength = int64(len(body))
+	return u.client.authorizer.Do(req)
+}
+
+// updateLocation updates the URL of the upload session from the Location
+// header of a response, which may be relative to the URL of the request.
+func (u *blobUpload) updateLocation(base *url.URL, resp *http.Response) error {
+	loc := resp.Header.Get("Location")
+	if loc == "" {
+		if u.location == nil {
+			return errors.New("registry did not return the location of the upload")
+		}
+		return nil
+	}
+	l, err := base.Parse(loc)
+	if err != nil {
+		return fmt.Errorf("invalid upload location %q: %w", loc, err)
+	}
+	u.location = l
+	return nil
+}
+
+func (u *blobUpload) report() {
+	if u.progress != nil {
+		u.progress(PushProgress{
+			Digest:    u.desc.Digest.String(),
+			MediaType: u.desc.MediaType,
+			Uploaded:  u.offset,
+			Total:     u.desc.Size,
+		})
+	}
+}
+
+// uploadRange returns the index of the last byte received by the registry
+// from the Range header of a response, as in "0-1023". Registries return
+// "0-0" for empty uploads too, so it is taken to mean that nothing was
+// received.
+func uploadRange(resp *http.Response) (int64, bool) {
+	r := strings.TrimPrefix(resp.Header.Get("Range"), "bytes=")
+	_, last, ok := strings.Cut(r, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || n == 0 {
+		return -1, err == nil
+	}
+	return n, true
+}
+
+func uploadError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s %s: unexpected status %s: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,226 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadRegistry is a registry implementing the parts of the distribution
+// API used to push charts. The second chunk of the first chunked upload is
+// only partly received.
+type uploadRegistry struct {
+	mu        sync.Mutex
+	blobs     map[digest.Digest][]byte
+	manifests map[string][]byte
+	uploads   map[string]*bytes.Buffer
+	patches   int
+	failed    bool
+}
+
+func (r *uploadRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, p, _ := strings.Cut(req.URL.Path, "/charts/big/")
+	body, _ := io.ReadAll(req.Body)
+	switch {
+	case req.Method == http.MethodPost && p == "blobs/uploads/":
+		id := fmt.Sprint(len(r.uploads))
+		r.uploads[id] = new(bytes.Buffer)
+		w.Header().Set("Location", "/v2/charts/big/blobs/uploads/"+id)
+		w.Header().Set("Range", "0-0")
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasPrefix(p, "blobs/uploads/"):
+		id := strings.TrimPrefix(p, "blobs/uploads/")
+		buf, ok := r.uploads[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch req.Method {
+		case http.MethodPatch:
+			var start int
+			fmt.Sscanf(req.Header.Get("Content-Range"), "%d-", &start)
+			if start != buf.Len() {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			r.patches++
+			if r.patches == 2 && !r.failed {
+				r.failed = true
+				buf.Write(body[:len(body)/2])
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			buf.Write(body)
+			w.Header().Set("Location", req.URL.Path)
+			w.Header().Set("Range", fmt.Sprintf("0-%d", buf.Len()-1))
+			w.WriteHeader(http.StatusAccepted)
+		case http.MethodGet:
+			w.Header().Set("Range", fmt.Sprintf("0-%d", max(buf.Len()-1, 0)))
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodPut:
+			buf.Write(body)
+			dgst := digest.Digest(req.URL.Query().Get("digest"))
+			if digest.FromBytes(buf.Bytes()) != dgst {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			r.blobs[dgst] = buf.Bytes()
+			delete(r.uploads, id)
+			w.Header().Set("Docker-Content-Digest", dgst.String())
+			w.WriteHeader(http.StatusCreated)
+		}
+	case strings.HasPrefix(p, "blobs/"):
+		b, ok := r.blobs[digest.Digest(strings.TrimPrefix(p, "blobs/"))]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(b)))
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(b).String())
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(p, "manifests/"):
+		ref := strings.TrimPrefix(p, "manifests/")
+		if req.Method == http.MethodPut {
+			r.manifests[ref] = body
+			r.manifests[digest.FromBytes(body).String()] = body
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(body).String())
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		m, ok := r.manifests[ref]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		w.Header().Set("Content-Length", fmt.Sprint(len(m)))
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(m).String())
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(m)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestPushChunkedResume(t *testing.T) {
+	backoff := pushRetryBackoff
+	pushRetryBackoff = time.Millisecond
+	defer func() { pushRetryBackoff = backoff }()
+
+	reg := &uploadRegistry{
+		blobs:     map[digest.Digest][]byte{},
+		manifests: map[string][]byte{},
+		uploads:   map[string]*bytes.Buffer{},
+	}
+	srv := httptest.NewServer(reg)
+	defer srv.Close()
+
+	// Random data does not compress, making the archive larger than a chunk
+	data := make([]byte, 256<<10)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	chartData := chartArchive(t, map[string][]byte{
+		"big/Chart.yaml": []byte("apiVersion: v2\nname: big\nversion: 0.1.0\n"),
+		"big/data.bin":   data,
+	})
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	client, err := NewClient(
+		ClientOptPlainHTTP(),
+		ClientOptWriter(&out),
+		ClientOptCredentialsFile(filepath.Join(dir, "config.json")),
+	)
+	require.NoError(t, err)
+
+	var progress []PushProgress
+	ref := strings.TrimPrefix(srv.URL, "http://") + "/charts/big:0.1.0"
+	result, err := client.Push(chartData, ref,
+		PushOptChunkSize(64<<10),
+		PushOptProgress(func(p PushProgress) { progress = append(progress, p) }),
+	)
+	require.NoError(t, err, out.String())
+
+	assert.True(t, reg.failed)
+	assert.Contains(t, out.String(), "Retrying the upload of "+result.Chart.Digest)
+	assert.Equal(t, chartData, reg.blobs[digest.Digest(result.Chart.Digest)])
+	assert.Contains(t, reg.manifests, "0.1.0")
+
+	var chartProgress []int64
+	for _, p := range progress {
+		if p.Digest == result.Chart.Digest {
+			assert.Equal(t, int64(len(chartData)), p.Total)
+			chartProgress = append(chartProgress, p.Uploaded)
+		}
+	}
+	require.NotEmpty(t, chartProgress)
+	assert.Equal(t, int64(len(chartData)), chartProgress[len(chartProgress)-1])
+	assert.IsNonDecreasing(t, chartProgress)
+}
+
+func TestUploadRange(t *testing.T) {
+	for header, want := range map[string]int64{
+		"0-1023":       1023,
+		"bytes=0-1023": 1023,
+		"0-0":          -1,
+	} {
+		last, ok := uploadRange(&http.Response{Header: http.Header{"Range": {header}}})
+		assert.True(t, ok, header)
+		assert.Equal(t, want, last, header)
+	}
+	_, ok := uploadRange(&http.Response{Header: http.Header{}})
+	assert.False(t, ok)
+}
+
+// chartArchive returns a gzipped tar archive of the files. It is written here
+// rather than with chartutil.Save, which would be an import cycle.
+func chartArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,39 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+// Values media types
+const (
+	// ValuesConfigMediaType is the media type of the config of a values file
+	// manifest.
+	ValuesConfigMediaType = "application/vnd.cncf.helm.values.config.v1+json"
+
+	// ValuesLayerMediaType is the media type of values file content.
+	ValuesLayerMediaType = "application/vnd.cncf.helm.values.v1+yaml"
+)
+
+// PushValues uploads a values file to a registry, so that it can be shared
+// between releases and given to -f/--values as an oci:// URL. It returns the
+// digest of the manifest.
+func (c *Client) PushValues(data []byte, ref string) (string, error) {
+	return c.pushSingleLayer(data, ValuesLayerMediaType, []byte("{}"), ValuesConfigMediaType, ref)
+}
+
+// PullValues downloads a values file from a registry.
+func (c *Client) PullValues(ref string) ([]byte, error) {
+	return c.pullSingleLayer(ref, ValuesLayerMediaType, ValuesConfigMediaType)
+}
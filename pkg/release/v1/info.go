@@ -33,6 +33,9 @@ type Info struct {
 	Description string `json:"description,omitempty"`
 	// Status is the current state of the release
 	Status Status `json:"status,omitempty"`
+	// CustomStatus is a status set by an external orchestrator on top of
+	// Status. See StateMachine.
+	CustomStatus Status `json:"custom_status,omitempty"`
 	// Contains the rendered templates/NOTES.txt if available
 	Notes string `json:"notes,omitempty"`
 	// Contains the deployed resources information
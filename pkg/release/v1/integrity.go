@@ -0,0 +1,44 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Integrity holds the SHA-256 digests of the stored parts of a release,
+// recorded when the release is created, so that 'helm verify-release' can
+// detect a release that was corrupted or tampered with in storage.
+//
+// Digests are written as "sha256:" followed by the hex-encoded sum.
+type Integrity struct {
+	// Chart is the digest of the JSON encoding of the chart.
+	Chart string `json:"chart,omitempty"`
+	// ChartTree is the digest of the JSON encodings of the chart and,
+	// recursively, of its dependencies, taken before the dependencies are
+	// processed. Dependencies are not stored with the release, so it cannot
+	// be verified, but it lets an upgrade detect a change to a subchart.
+	ChartTree string `json:"chart_tree,omitempty"`
+	// Values is the digest of the JSON encoding of the config.
+	Values string `json:"values,omitempty"`
+	// Manifest is the digest of the rendered manifest.
+	Manifest string `json:"manifest,omitempty"`
+	// Hooks is the digest of the paths and manifests of the hooks.
+	Hooks string `json:"hooks,omitempty"`
+	// ChartSource is the repository chart or OCI reference the chart was
+	// pulled from. It is empty for charts loaded from the local filesystem.
+	ChartSource string `json:"chart_source,omitempty"`
+	// ChartArchive is the digest of the chart archive pulled from
+	// ChartSource.
+	ChartArchive string `json:"chart_archive,omitempty"`
+}
@@ -26,6 +26,11 @@ const ApplyMethodServerSideApply ApplyMethod = "ssa"
 
 // Release describes a deployment of a chart, together with the chart
 // and the variables used to deploy that chart.
+//
+// The JSON encoding of a release is what the storage drivers persist and what
+// 'helm get' and 'helm status' print, so existing JSON field names must never
+// change. New fields must be optional and tagged with omitempty, so that older
+// releases keep decoding.
 type Release struct {
 	// Name is the name of the release
 	Name string `json:"name,omitempty"`
@@ -36,6 +41,9 @@ type Release struct {
 	// Config is the set of extra Values added to the chart.
 	// These values override the default values inside of the chart.
 	Config map[string]interface{} `json:"config,omitempty"`
+	// ConfigSources maps the dot-separated paths of the values in Config to
+	// the values file or flag that set them, when they were recorded.
+	ConfigSources map[string]string `json:"config_sources,omitempty"`
 	// Manifest is the string representation of the rendered template.
 	Manifest string `json:"manifest,omitempty"`
 	// Hooks are all of the hooks declared for this release.
@@ -50,6 +58,13 @@ type Release struct {
 	// ApplyMethod stores whether server-side or client-side apply was used for the release
 	// Unset (empty string) should be treated as the default of client-side apply
 	ApplyMethod string `json:"apply_method,omitempty"` // "ssa" | "csa"
+	// SharedRevision is the revision this revision shares an identical chart
+	// and manifest with. Only that revision stores them; pkg/storage restores
+	// them when reading this one.
+	SharedRevision int `json:"shared_revision,omitempty"`
+	// Integrity holds the digests of the chart, config, manifest and hooks of
+	// the release, for releases created since they are recorded.
+	Integrity *Integrity `json:"integrity,omitempty"`
 }
 
 // SetStatus is a helper for setting the status on a release.
@@ -0,0 +1,83 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/internal/test"
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	helmtime "helm.sh/helm/v4/pkg/time"
+)
+
+// TestReleaseJSON guards the JSON encoding of releases, which is persisted by
+// the storage drivers and consumed by tools outside of Helm.
+func TestReleaseJSON(t *testing.T) {
+	deployed := helmtime.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	rel := &Release{
+		Name:      "angry-panda",
+		Namespace: "default",
+		Version:   2,
+		Info: &Info{
+			FirstDeployed: deployed,
+			LastDeployed:  deployed,
+			Description:   "Upgrade complete",
+			Status:        StatusDeployed,
+			CustomStatus:  "canary",
+			Notes:         "Thank you",
+		},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{
+				APIVersion: chart.APIVersionV2,
+				Name:       "hello",
+				Version:    "0.1.0",
+			},
+		},
+		Config:   map[string]interface{}{"name": "value"},
+		Manifest: "apiVersion: v1\nkind: ConfigMap\n",
+		Hooks: []*Hook{{
+			Name:           "test-cm",
+			Kind:           "ConfigMap",
+			Path:           "test-cm",
+			Manifest:       "MANIFEST",
+			Events:         []HookEvent{HookPreInstall},
+			Weight:         1,
+			DeletePolicies: []HookDeletePolicy{HookSucceeded},
+			LastRun: HookExecution{
+				StartedAt:   deployed,
+				CompletedAt: deployed,
+				Phase:       HookPhaseSucceeded,
+			},
+		}},
+		ApplyMethod: string(ApplyMethodServerSideApply),
+		Labels:      map[string]string{"not": "encoded"},
+	}
+
+	data, err := json.MarshalIndent(rel, "", "  ")
+	require.NoError(t, err)
+	test.AssertGoldenString(t, string(data)+"\n", "release.json")
+
+	var decoded Release
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	rel.Labels = nil
+	assert.Equal(t, rel, &decoded)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrInvalidTransition is returned when a status change is not allowed by the
+// state machine.
+var ErrInvalidTransition = errors.New("invalid release status transition")
+
+// transitions lists the status changes Helm itself performs. The empty status
+// is the status of a release that has not been stored yet.
+var transitions = map[Status][]Status{
+	"":                    {StatusUnknown, StatusPendingInstall, StatusPendingUpgrade, StatusPendingRollback, StatusFailed},
+	StatusUnknown:         {StatusPendingInstall, StatusPendingUpgrade, StatusPendingRollback, StatusFailed},
+	StatusPendingInstall:  {StatusDeployed, StatusFailed, StatusUninstalling},
+	StatusPendingUpgrade:  {StatusDeployed, StatusFailed, StatusUninstalling},
+	StatusPendingRollback: {StatusDeployed, StatusFailed, StatusUninstalling},
+	StatusDeployed:        {StatusSuperseded, StatusUninstalling},
+	StatusFailed:          {StatusSuperseded, StatusUninstalling},
+	StatusSuperseded:      {StatusUninstalling},
+	StatusUninstalling:    {StatusUninstalled},
+	StatusUninstalled:     {StatusSuperseded},
+}
+
+// IsBuiltin reports whether the status is one of the statuses defined by Helm.
+func (x Status) IsBuiltin() bool {
+	_, ok := transitions[x]
+	return ok && x != ""
+}
+
+// StateMachine describes the release statuses and the transitions between them.
+//
+// Helm's own statuses and transitions are built in and cannot be changed.
+// External orchestrators may register custom statuses, such as "canary" or
+// "paused", on top of them. A custom status is stored in Info.CustomStatus
+// next to the built-in status and never replaces it, so Helm's own logic is
+// unaffected by it. A custom status only applies while the release is in one
+// of the built-in statuses it was registered for.
+//
+// A nil *StateMachine knows the built-in statuses only. A StateMachine is safe
+// for concurrent use.
+type StateMachine struct {
+	mu     sync.RWMutex
+	custom map[Status][]Status
+}
+
+// NewStateMachine creates a state machine with the built-in statuses.
+func NewStateMachine() *StateMachine {
+	return &StateMachine{custom: make(map[Status][]Status)}
+}
+
+// Transitions returns the built-in statuses a release in the given status may
+// move to.
+func (m *StateMachine) Transitions(from Status) []Status {
+	return slices.Clone(transitions[from])
+}
+
+// CanTransition reports whether a release may move between two built-in statuses.
+func (m *StateMachine) CanTransition(from, to Status) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+// Transition moves the release to a built-in status, or returns
+// ErrInvalidTransition if the state machine does not allow it.
+func (m *StateMachine) Transition(rel *Release, to Status, msg string) error {
+	if !m.CanTransition(rel.Info.Status, to) {
+		return fmt.Errorf("%w: %q to %q", ErrInvalidTransition, rel.Info.Status, to)
+	}
+	rel.SetStatus(to, msg)
+	return nil
+}
+
+// RegisterStatus registers a custom status that may be set on releases in any
+// of the given built-in statuses.
+func (m *StateMachine) RegisterStatus(status Status, on ...Status) error {
+	if status == "" {
+		return errors.New("custom status must not be empty")
+	}
+	if _, ok := transitions[status]; ok {
+		return fmt.Errorf("status %q is built in and cannot be registered", status)
+	}
+	if len(on) == 0 {
+		return fmt.Errorf("custom status %q must apply to at least one built-in status", status)
+	}
+	for _, s := range on {
+		if !s.IsBuiltin() {
+			return fmt.Errorf("custom status %q cannot apply to %q: not a built-in status", status, s)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.custom[status] = slices.Clone(on)
+	return nil
+}
+
+// IsCustom reports whether the status is a registered custom status.
+func (m *StateMachine) IsCustom(status Status) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.custom[status]
+	return ok
+}
+
+// SetCustomStatus sets the custom status of the release, or clears it if status
+// is empty. It returns ErrInvalidTransition if the status is not registered
+// for the release's built-in status.
+func (m *StateMachine) SetCustomStatus(rel *Release, status Status) error {
+	if status != "" && !m.allows(status, rel.Info.Status) {
+		return fmt.Errorf("%w: custom status %q cannot be set on a %q release", ErrInvalidTransition, status, rel.Info.Status)
+	}
+	rel.Info.CustomStatus = status
+	return nil
+}
+
+// CustomStatus returns the custom status of the release, or an empty status if
+// it has none or the custom status does not apply to its built-in status.
+func (m *StateMachine) CustomStatus(rel *Release) Status {
+	if rel.Info == nil || !m.allows(rel.Info.CustomStatus, rel.Info.Status) {
+		return ""
+	}
+	return rel.Info.CustomStatus
+}
+
+func (m *StateMachine) allows(custom, builtin Status) bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Contains(m.custom[custom], builtin)
+}
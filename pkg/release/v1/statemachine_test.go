@@ -0,0 +1,78 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateMachineTransition(t *testing.T) {
+	var m *StateMachine
+
+	rel := &Release{Info: &Info{Status: StatusPendingUpgrade}}
+	assert.NoError(t, m.Transition(rel, StatusDeployed, "Upgrade complete"))
+	assert.Equal(t, StatusDeployed, rel.Info.Status)
+	assert.Equal(t, "Upgrade complete", rel.Info.Description)
+
+	err := m.Transition(rel, StatusPendingInstall, "")
+	assert.True(t, errors.Is(err, ErrInvalidTransition), "got %v", err)
+	assert.Equal(t, StatusDeployed, rel.Info.Status)
+
+	assert.ElementsMatch(t, []Status{StatusSuperseded, StatusUninstalling}, m.Transitions(StatusDeployed))
+}
+
+func TestStateMachineRegisterStatus(t *testing.T) {
+	m := NewStateMachine()
+
+	assert.Error(t, m.RegisterStatus(""))
+	assert.Error(t, m.RegisterStatus(StatusDeployed, StatusFailed), "built-in statuses cannot be registered")
+	assert.Error(t, m.RegisterStatus("paused"), "custom statuses must apply to a built-in status")
+	assert.Error(t, m.RegisterStatus("paused", "canary"), "custom statuses must apply to built-in statuses only")
+
+	assert.NoError(t, m.RegisterStatus("paused", StatusDeployed, StatusFailed))
+	assert.True(t, m.IsCustom("paused"))
+	assert.False(t, m.IsCustom(StatusDeployed))
+	assert.False(t, Status("paused").IsBuiltin())
+	assert.True(t, StatusDeployed.IsBuiltin())
+
+	// Custom statuses are not part of the built-in transitions.
+	assert.False(t, m.CanTransition(StatusDeployed, "paused"))
+}
+
+func TestStateMachineCustomStatus(t *testing.T) {
+	m := NewStateMachine()
+	assert.NoError(t, m.RegisterStatus("canary", StatusDeployed))
+
+	rel := &Release{Info: &Info{Status: StatusDeployed}}
+	assert.NoError(t, m.SetCustomStatus(rel, "canary"))
+	assert.Equal(t, Status("canary"), m.CustomStatus(rel))
+
+	rel.Info.Status = StatusSuperseded
+	assert.Equal(t, Status(""), m.CustomStatus(rel), "custom status must not apply to other built-in statuses")
+	err := m.SetCustomStatus(rel, "canary")
+	assert.True(t, errors.Is(err, ErrInvalidTransition), "got %v", err)
+
+	assert.NoError(t, m.SetCustomStatus(rel, ""))
+	assert.Equal(t, Status(""), rel.Info.CustomStatus)
+
+	var unset *StateMachine
+	rel.Info.Status = StatusDeployed
+	assert.Error(t, unset.SetCustomStatus(rel, "canary"), "a nil state machine knows no custom statuses")
+}
@@ -20,6 +20,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -27,6 +28,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"helm.sh/helm/v4/pkg/getter"
 	"helm.sh/helm/v4/pkg/helmpath"
@@ -75,6 +77,28 @@ func NewChartRepository(cfg *Entry, getters getter.Providers) (*ChartRepository,
 
 // DownloadIndexFile fetches the index from a repository.
 func (r *ChartRepository) DownloadIndexFile() (string, error) {
+	return r.downloadIndexFile(time.Time{})
+}
+
+// RefreshIndexFile fetches the index from a repository like DownloadIndexFile,
+// unless the repository reports with a conditional request that its index did
+// not change since the cached copy was downloaded. It reports whether a new
+// index was downloaded.
+func (r *ChartRepository) RefreshIndexFile() (string, bool, error) {
+	fname := filepath.Join(r.CachePath, helmpath.CacheIndexFile(r.Config.Name))
+	var since time.Time
+	if fi, err := os.Stat(fname); err == nil {
+		since = fi.ModTime()
+	}
+	if _, err := r.downloadIndexFile(since); errors.Is(err, getter.ErrNotModified) {
+		return fname, false, nil
+	} else if err != nil {
+		return fname, false, err
+	}
+	return fname, true, nil
+}
+
+func (r *ChartRepository) downloadIndexFile(since time.Time) (string, error) {
 	indexURL, err := ResolveReferenceURL(r.Config.URL, "index.yaml")
 	if err != nil {
 		return "", err
@@ -86,6 +110,7 @@ func (r *ChartRepository) DownloadIndexFile() (string, error) {
 		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
 		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
 		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
+		getter.WithIfModifiedSince(since),
 	)
 	if err != nil {
 		return "", err
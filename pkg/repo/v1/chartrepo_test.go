@@ -24,6 +24,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -91,6 +92,45 @@ func TestIndexCustomSchemeDownload(t *testing.T) {
 	}
 }
 
+func TestRefreshIndexFile(t *testing.T) {
+	fileBytes, err := os.ReadFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var modified atomic.Int64
+	modified.Store(time.Now().Add(-time.Hour).Unix())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "index.yaml", time.Unix(modified.Load(), 0), bytes.NewReader(fileBytes))
+	}))
+	defer srv.Close()
+
+	r, err := NewChartRepository(&Entry{Name: "test", URL: srv.URL}, getter.All(&cli.EnvSettings{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.CachePath = t.TempDir()
+
+	// Without a cached index, the index is downloaded.
+	fname, updated, err := r.RefreshIndexFile()
+	if err != nil || !updated {
+		t.Fatalf("expected the index to be downloaded, got %t, %v", updated, err)
+	}
+	if _, err := os.Stat(fname); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cached index is newer than the one of the repository.
+	if _, updated, err := r.RefreshIndexFile(); err != nil || updated {
+		t.Fatalf("expected the index not to be downloaded, got %t, %v", updated, err)
+	}
+
+	// The repository index changed after the cached one was downloaded.
+	modified.Store(time.Now().Add(time.Hour).Unix())
+	if _, updated, err := r.RefreshIndexFile(); err != nil || !updated {
+		t.Fatalf("expected the index to be downloaded, got %t, %v", updated, err)
+	}
+}
+
 // startLocalServerForTests Start the local helm server
 func startLocalServerForTests(handler http.Handler) (*httptest.Server, error) {
 	if handler == nil {
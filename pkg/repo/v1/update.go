@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrUpdateSkipped is the error of the repositories UpdateIndexes did not
+// update because another repository failed to update first.
+var ErrUpdateSkipped = errors.New("update skipped after another repository failed to update")
+
+// UpdateResult is the outcome of updating the index of a chart repository.
+type UpdateResult struct {
+	Repository *ChartRepository
+	// IndexFile is the path of the downloaded index file.
+	IndexFile string
+	// Duration is the time it took to download the index file.
+	Duration time.Duration
+	// Err is the error updating the repository, or nil if it was updated.
+	Err error
+}
+
+// UpdateOptions configures UpdateIndexes.
+type UpdateOptions struct {
+	// Parallelism is the maximum number of repositories updated at the same
+	// time. All the repositories are updated at once if it is not positive.
+	Parallelism int
+	// FailFast stops updating repositories after the first failure. The
+	// repositories not updated yet fail with ErrUpdateSkipped.
+	FailFast bool
+	// Progress, if set, is called with the result of every repository as
+	// soon as it is known. Calls are never concurrent.
+	Progress func(UpdateResult)
+}
+
+// UpdateIndexes downloads the index files of the repositories concurrently.
+// The results are in the order of the repositories.
+func UpdateIndexes(repos []*ChartRepository, opts UpdateOptions) []UpdateResult {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 || parallelism > len(repos) {
+		parallelism = len(repos)
+	}
+
+	results := make([]UpdateResult, len(repos))
+	var (
+		wg       sync.WaitGroup
+		progress sync.Mutex
+		failed   atomic.Bool
+	)
+	sem := make(chan struct{}, parallelism)
+	for i, r := range repos {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			res := UpdateResult{Repository: r}
+			if opts.FailFast && failed.Load() {
+				res.Err = ErrUpdateSkipped
+			} else {
+				start := time.Now()
+				res.IndexFile, res.Err = r.DownloadIndexFile()
+				res.Duration = time.Since(start)
+				if res.Err != nil {
+					failed.Store(true)
+				}
+			}
+			results[i] = res
+
+			if opts.Progress != nil {
+				progress.Lock()
+				defer progress.Unlock()
+				opts.Progress(res)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
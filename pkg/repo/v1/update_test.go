@@ -0,0 +1,117 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// updateGetter serves an empty index, except for URLs containing "broken".
+type updateGetter struct {
+	running, maxRunning atomic.Int32
+}
+
+func (g *updateGetter) Get(href string, _ ...getter.Option) (*bytes.Buffer, error) {
+	n := g.running.Add(1)
+	defer g.running.Add(-1)
+	for {
+		m := g.maxRunning.Load()
+		if n <= m || g.maxRunning.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if strings.Contains(href, "broken") {
+		return nil, errors.New("broken repository")
+	}
+	index, err := yaml.Marshal(&IndexFile{APIVersion: "v1", Generated: time.Now()})
+	return bytes.NewBuffer(index), err
+}
+
+func newUpdateRepos(t *testing.T, g getter.Getter, urls ...string) []*ChartRepository {
+	t.Helper()
+	providers := getter.Providers{{
+		Schemes: []string{"test"},
+		New:     func(_ ...getter.Option) (getter.Getter, error) { return g, nil },
+	}}
+	var repos []*ChartRepository
+	for i, u := range urls {
+		r, err := NewChartRepository(&Entry{Name: string(rune('a' + i)), URL: u}, providers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.CachePath = t.TempDir()
+		repos = append(repos, r)
+	}
+	return repos
+}
+
+func TestUpdateIndexes(t *testing.T) {
+	g := &updateGetter{}
+	repos := newUpdateRepos(t, g, "test://one", "test://broken", "test://three", "test://four")
+
+	var progress []string
+	results := UpdateIndexes(repos, UpdateOptions{
+		Parallelism: 2,
+		Progress: func(res UpdateResult) {
+			progress = append(progress, res.Repository.Config.Name)
+		},
+	})
+
+	if len(results) != 4 || len(progress) != 4 {
+		t.Fatalf("Expected 4 results and progress reports, got %d and %d", len(results), len(progress))
+	}
+	for i, res := range results {
+		if res.Repository != repos[i] {
+			t.Errorf("Expected result %d to be for repository %s", i, repos[i].Config.Name)
+		}
+		if (res.Err != nil) != (i == 1) {
+			t.Errorf("Unexpected error for repository %s: %v", res.Repository.Config.Name, res.Err)
+		}
+		if res.Err == nil && (res.IndexFile == "" || res.Duration <= 0) {
+			t.Errorf("Expected an index file and a duration for repository %s, got %+v", res.Repository.Config.Name, res)
+		}
+	}
+	if n := g.maxRunning.Load(); n > 2 {
+		t.Errorf("Expected at most 2 concurrent updates, got %d", n)
+	}
+}
+
+func TestUpdateIndexesFailFast(t *testing.T) {
+	repos := newUpdateRepos(t, &updateGetter{}, "test://broken", "test://two", "test://three")
+
+	results := UpdateIndexes(repos, UpdateOptions{Parallelism: 1, FailFast: true})
+
+	if results[0].Err == nil || errors.Is(results[0].Err, ErrUpdateSkipped) {
+		t.Errorf("Expected the first repository to fail, got %v", results[0].Err)
+	}
+	for _, res := range results[1:] {
+		if !errors.Is(res.Err, ErrUpdateSkipped) {
+			t.Errorf("Expected repository %s to be skipped, got %v", res.Repository.Config.Name, res.Err)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo // import "helm.sh/helm/v4/pkg/repo/v1"
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+
+	chart "helm.sh/helm/v4/pkg/chart/v2"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+// DefaultVerifyParallelism is the number of charts downloaded at the same time
+// when verifying a repository.
+const DefaultVerifyParallelism = 4
+
+// The checks of the verification of a repository.
+const (
+	// CheckIndex checks that the index and its entries are valid
+	CheckIndex = "index"
+	// CheckDuplicate checks that no version of a chart is listed twice
+	CheckDuplicate = "duplicate"
+	// CheckURL checks that the charts can be downloaded
+	CheckURL = "url"
+	// CheckDigest checks that the charts match the digests of the index
+	CheckDigest = "digest"
+	// CheckProvenance checks that the charts have provenance files
+	CheckProvenance = "provenance"
+)
+
+// Severity is the severity of an issue found by the verification of a
+// repository.
+type Severity string
+
+// The severities of issues.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// VerifyOptions are the options of the verification of a repository.
+type VerifyOptions struct {
+	// Parallelism is the number of charts downloaded at the same time. Zero
+	// means DefaultVerifyParallelism.
+	Parallelism int
+	// SkipDownloads only verifies the index, without downloading the charts
+	// and their provenance files.
+	SkipDownloads bool
+	// RequireProvenance reports charts without provenance files as errors
+	// instead of warnings.
+	RequireProvenance bool
+}
+
+// VerifyIssue is an issue found by the verification of a repository.
+type VerifyIssue struct {
+	Chart    string   `json:"chart,omitempty"`
+	Version  string   `json:"version,omitempty"`
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// VerifyReport is the result of the verification of a repository.
+type VerifyReport struct {
+	Repository string        `json:"repository"`
+	URL        string        `json:"url"`
+	Charts     int           `json:"charts"`
+	Versions   int           `json:"versions"`
+	Issues     []VerifyIssue `json:"issues"`
+}
+
+// Count returns the number of issues of a severity.
+func (r *VerifyReport) Count(severity Severity) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Verify checks a repository end to end: the index must be valid, list each
+// version of a chart once, and every chart must download from its URLs and
+// match its digest. Charts without provenance files are reported too.
+//
+// Issues with the repository are listed in the report; an error is only
+// returned when the index cannot be downloaded.
+func (r *ChartRepository) Verify(opts VerifyOptions) (*VerifyReport, error) {
+	report := &VerifyReport{
+		Repository: r.Config.Name,
+		URL:        r.Config.URL,
+		Issues:     []VerifyIssue{},
+	}
+	var mu sync.Mutex
+	issue := func(cv *ChartVersion, name, check string, severity Severity, format string, a ...interface{}) {
+		i := VerifyIssue{Chart: name, Check: check, Severity: severity, Message: fmt.Sprintf(format, a...)}
+		if cv != nil && cv.Metadata != nil {
+			i.Version = cv.Version
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		report.Issues = append(report.Issues, i)
+	}
+
+	indexURL, err := ResolveReferenceURL(r.Config.URL, "index.yaml")
+	if err != nil {
+		return nil, err
+	}
+	data, err := r.get(indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("could not download the index of %s: %w", r.Config.URL, err)
+	}
+
+	idx := &IndexFile{}
+	if data.Len() == 0 {
+		issue(nil, "", CheckIndex, SeverityError, "the index is empty")
+		return report, nil
+	}
+	if err := jsonOrYamlUnmarshal(data.Bytes(), idx); err != nil {
+		issue(nil, "", CheckIndex, SeverityError, "the index is invalid: %s", err)
+		return report, nil
+	}
+	switch idx.APIVersion {
+	case APIVersionV1:
+	case "":
+		issue(nil, "", CheckIndex, SeverityError, "the index has no apiVersion")
+	default:
+		issue(nil, "", CheckIndex, SeverityError, "the index has an unsupported apiVersion %q", idx.APIVersion)
+	}
+
+	var downloads []*ChartVersion
+	names := slices.Sorted(maps.Keys(idx.Entries))
+	report.Charts = len(names)
+	for _, name := range names {
+		seen := map[string]string{}
+		for _, cv := range idx.Entries[name] {
+			if cv == nil || cv.Metadata == nil {
+				issue(nil, name, CheckIndex, SeverityError, "the index has an empty entry")
+				continue
+			}
+			report.Versions++
+			if cv.Name != name {
+				issue(cv, name, CheckIndex, SeverityError, "the entry is for the chart %q", cv.Name)
+			}
+			if cv.APIVersion == "" {
+				cv.APIVersion = chart.APIVersionV1
+			}
+			if err := cv.Validate(); err != nil {
+				severity := SeverityError
+				if ignoreSkippableChartValidationError(err) == nil {
+					severity = SeverityWarning
+				}
+				issue(cv, name, CheckIndex, severity, "%s", err)
+			}
+
+			// Versions differing only in their formatting, as 1.0 and
+			// 1.0.0, are the same version
+			key := cv.Version
+			if v, err := semver.NewVersion(cv.Version); err == nil {
+				key = v.String()
+			}
+			if other, ok := seen[key]; ok {
+				issue(cv, name, CheckDuplicate, SeverityError, "the version is listed more than once (as %s)", other)
+			}
+			seen[key] = cv.Version
+
+			if len(cv.URLs) == 0 {
+				issue(cv, name, CheckURL, SeverityError, "the entry has no URLs")
+				continue
+			}
+			if cv.Digest == "" {
+				issue(cv, name, CheckDigest, SeverityWarning, "the entry has no digest")
+			}
+			downloads = append(downloads, cv)
+		}
+	}
+
+	if !opts.SkipDownloads {
+		r.verifyDownloads(downloads, opts, issue)
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Chart != b.Chart {
+			return a.Chart < b.Chart
+		}
+		return a.Version < b.Version
+	})
+	return report, nil
+}
+
+// verifyDownloads downloads the charts from each of their URLs, and their
+// provenance files from the first one, reporting the issues found.
+func (r *ChartRepository) verifyDownloads(cvs []*ChartVersion, opts VerifyOptions, issue func(*ChartVersion, string, string, Severity, string, ...interface{})) {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultVerifyParallelism
+	}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, cv := range cvs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			for i, u := range cv.URLs {
+				chartURL, err := ResolveReferenceURL(r.Config.URL, u)
+				if err != nil {
+					issue(cv, cv.Name, CheckURL, SeverityError, "invalid URL %q: %s", u, err)
+					continue
+				}
+				data, err := r.get(chartURL)
+				if err != nil {
+					issue(cv, cv.Name, CheckURL, SeverityError, "could not download %s: %s", chartURL, err)
+					continue
+				}
+				sum := sha256.Sum256(data.Bytes())
+				if digest := hex.EncodeToString(sum[:]); cv.Digest != "" && !strings.EqualFold(cv.Digest, digest) {
+					issue(cv, cv.Name, CheckDigest, SeverityError, "%s has the digest %s, the index has %s", chartURL, digest, cv.Digest)
+				}
+				if i > 0 {
+					continue
+				}
+				if _, err := r.get(chartURL + ".prov"); err != nil {
+					severity := SeverityWarning
+					if opts.RequireProvenance {
+						severity = SeverityError
+					}
+					issue(cv, cv.Name, CheckProvenance, severity, "no provenance file at %s.prov", chartURL)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// get downloads a file of the repository with its credentials.
+func (r *ChartRepository) get(u string) (*bytes.Buffer, error) {
+	return r.Client.Get(u,
+		getter.WithURL(r.Config.URL),
+		getter.WithInsecureSkipVerifyTLS(r.Config.InsecureSkipTLSverify),
+		getter.WithTLSClientConfig(r.Config.CertFile, r.Config.KeyFile, r.Config.CAFile),
+		getter.WithBasicAuth(r.Config.Username, r.Config.Password),
+		getter.WithPassCredentialsAll(r.Config.PassCredentialsAll),
+	)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"helm.sh/helm/v4/pkg/cli"
+	"helm.sh/helm/v4/pkg/getter"
+)
+
+func TestVerify(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"frobnitz-1.2.3.tgz", "sprocket-1.1.0.tgz", "sprocket-1.2.0.tgz"} {
+		data, err := os.ReadFile(filepath.Join("testdata/repository", f))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), data, 0644))
+	}
+	// Only frobnitz is signed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "frobnitz-1.2.3.tgz.prov"), []byte("signed"), 0644))
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	idx, err := IndexDirectory(dir, srv.URL)
+	require.NoError(t, err)
+	old, err := idx.Get("sprocket", "1.1.0")
+	require.NoError(t, err)
+	latest, err := idx.Get("sprocket", "1.2.0")
+	require.NoError(t, err)
+	// 1.1.0 is listed twice, with the wrong digest and a missing URL
+	dup := *old
+	dup.Digest = "0000"
+	dup.URLs = []string{"missing-1.1.0.tgz"}
+	idx.Entries["sprocket"] = append(idx.Entries["sprocket"], &dup)
+	latest.Digest = ""
+	require.NoError(t, idx.WriteFile(filepath.Join(dir, "index.yaml"), 0644))
+
+	r, err := NewChartRepository(&Entry{Name: "test", URL: srv.URL}, getter.All(&cli.EnvSettings{}))
+	require.NoError(t, err)
+
+	report, err := r.Verify(VerifyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Charts)
+	assert.Equal(t, 4, report.Versions)
+
+	type check struct {
+		version, check string
+		severity       Severity
+	}
+	var got []check
+	for _, i := range report.Issues {
+		if i.Chart == "sprocket" {
+			got = append(got, check{i.Version, i.Check, i.Severity})
+		} else {
+			t.Errorf("unexpected issue for %s: %s", i.Chart, i.Message)
+		}
+	}
+	assert.ElementsMatch(t, []check{
+		{"1.1.0", CheckDuplicate, SeverityError},
+		{"1.1.0", CheckURL, SeverityError},
+		{"1.1.0", CheckProvenance, SeverityWarning},
+		{"1.2.0", CheckDigest, SeverityWarning},
+		{"1.2.0", CheckProvenance, SeverityWarning},
+	}, got)
+	assert.Equal(t, 2, report.Count(SeverityError))
+
+	report, err = r.Verify(VerifyOptions{RequireProvenance: true})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Count(SeverityError))
+
+	// Without downloads, only the index is checked
+	report, err = r.Verify(VerifyOptions{SkipDownloads: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Count(SeverityError))
+	assert.Equal(t, 1, report.Count(SeverityWarning))
+}
+
+func TestVerifyDigestMismatch(t *testing.T) {
+	dir := t.TempDir()
+	data, err := os.ReadFile("testdata/repository/frobnitz-1.2.3.tgz")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "frobnitz-1.2.3.tgz"), data, 0644))
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	idx, err := IndexDirectory(dir, srv.URL)
+	require.NoError(t, err)
+	idx.Entries["frobnitz"][0].Digest = "0000"
+	require.NoError(t, idx.WriteFile(filepath.Join(dir, "index.yaml"), 0644))
+
+	r, err := NewChartRepository(&Entry{Name: "test", URL: srv.URL}, getter.All(&cli.EnvSettings{}))
+	require.NoError(t, err)
+	report, err := r.Verify(VerifyOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Count(SeverityError), report.Issues)
+	assert.Equal(t, CheckDigest, report.Issues[0].Check)
+	assert.Contains(t, report.Issues[0].Message, "the index has 0000")
+}
+
+func TestVerifyInvalidIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("entries:\n  foo: [{name: foo, version: 1.0.0, unknown: field}]\n"))
+	}))
+	defer srv.Close()
+
+	r, err := NewChartRepository(&Entry{Name: "test", URL: srv.URL}, getter.All(&cli.EnvSettings{}))
+	require.NoError(t, err)
+	report, err := r.Verify(VerifyOptions{})
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, CheckIndex, report.Issues[0].Check)
+	assert.Contains(t, report.Issues[0].Message, "the index is invalid")
+}
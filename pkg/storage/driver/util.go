@@ -53,6 +53,16 @@ func encodeRelease(rls *rspb.Release) (string, error) {
 	return b64.EncodeToString(buf.Bytes()), nil
 }
 
+// EncodedSize returns the size in bytes of the payload the Secrets,
+// ConfigMaps and SQL drivers store for a release.
+func EncodedSize(rls *rspb.Release) (int, error) {
+	data, err := encodeRelease(rls)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
 // decodeRelease decodes the bytes of data into a release
 // type. Data must contain a base64 encoded gzipped string of a
 // valid release, otherwise an error is returned.
@@ -0,0 +1,108 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+)
+
+// A revision with a SharedRevision shares its chart and manifest with that
+// revision, which is the only one storing them. Storage strips the shared
+// fields before writing a revision, and restores them when reading, so callers
+// always see complete releases.
+
+// compact returns the release as it is written to the driver.
+func compact(rls *rspb.Release) *rspb.Release {
+	if rls.SharedRevision == 0 {
+		return rls
+	}
+	r := *rls
+	r.Chart = nil
+	r.Manifest = ""
+	return &r
+}
+
+// resolve restores the shared fields of the releases read from the driver.
+// Revisions shared within ls are not read again.
+func (s *Storage) resolve(ls ...*rspb.Release) ([]*rspb.Release, error) {
+	type key struct {
+		name    string
+		version int
+	}
+	loaded := make(map[key]*rspb.Release, len(ls))
+	for _, rls := range ls {
+		if rls.SharedRevision == 0 {
+			loaded[key{rls.Name, rls.Version}] = rls
+		}
+	}
+
+	out := make([]*rspb.Release, len(ls))
+	for i, rls := range ls {
+		out[i] = rls
+		if rls.SharedRevision == 0 {
+			continue
+		}
+		k := key{rls.Name, rls.SharedRevision}
+		shared, ok := loaded[k]
+		if !ok {
+			var err error
+			shared, err = s.Driver.Get(makeKey(rls.Name, rls.SharedRevision))
+			if err != nil {
+				return nil, fmt.Errorf("release %s revision %d: reading the chart and manifest of revision %d: %w", rls.Name, rls.Version, rls.SharedRevision, err)
+			}
+			loaded[k] = shared
+		}
+		r := *rls
+		r.Chart = shared.Chart
+		r.Manifest = shared.Manifest
+		out[i] = &r
+	}
+	return out, nil
+}
+
+// unshare copies the chart and manifest of the revision into the revisions of
+// the history h sharing them, so that it can be deleted. The oldest of them
+// stores the copy and the others share it instead.
+func (s *Storage) unshare(name string, version int, h []*rspb.Release) error {
+	var sharing []*rspb.Release
+	for _, rls := range h {
+		if rls.SharedRevision == version {
+			sharing = append(sharing, rls)
+		}
+	}
+	if len(sharing) == 0 {
+		return nil
+	}
+	sort.Slice(sharing, func(i, j int) bool { return sharing[i].Version < sharing[j].Version })
+
+	slog.Debug("unsharing release revision", "key", makeKey(name, version), "count", len(sharing))
+	owner := sharing[0].Version
+	for _, rls := range sharing {
+		rls.SharedRevision = 0
+		if rls.Version != owner {
+			rls.SharedRevision = owner
+		}
+		if err := s.Update(rls); err != nil {
+			return err
+		}
+	}
+	return nil
+}
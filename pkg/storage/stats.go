@@ -0,0 +1,167 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"cmp"
+	"slices"
+
+	rspb "helm.sh/helm/v4/pkg/release/v1"
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+// LargestReleases is the number of releases listed by Stats as the largest.
+var LargestReleases = 5
+
+// RevisionBuckets are the upper bounds of the ranges of revision counts the
+// releases of a namespace are distributed into by Stats. Releases with more
+// revisions than the last bound fall in a last, open range.
+var RevisionBuckets = []int{1, 5, 10, 25, 50}
+
+// Stats describes the releases held by a storage.
+type Stats struct {
+	// Driver is the name of the storage driver.
+	Driver string `json:"driver"`
+	// Releases is the number of releases.
+	Releases int `json:"releases"`
+	// Revisions is the number of stored revisions of the releases.
+	Revisions int `json:"revisions"`
+	// Size is the size in bytes of the payload of the stored revisions.
+	Size int64 `json:"size"`
+	// Largest are the releases with the largest payloads, largest first.
+	Largest []ReleaseStats `json:"largest"`
+	// Namespaces are the statistics of each namespace, sorted by name.
+	Namespaces []NamespaceStats `json:"namespaces"`
+}
+
+// ReleaseStats describes the stored revisions of a release.
+type ReleaseStats struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revisions int    `json:"revisions"`
+	Size      int64  `json:"size"`
+}
+
+// NamespaceStats describes the releases of a namespace.
+type NamespaceStats struct {
+	Namespace string `json:"namespace"`
+	Releases  int    `json:"releases"`
+	Revisions int    `json:"revisions"`
+	Size      int64  `json:"size"`
+	// MaxRevisions is the largest number of revisions of a release.
+	MaxRevisions int `json:"max_revisions"`
+	// Distribution counts the releases by number of revisions, in the
+	// ranges set by RevisionBuckets.
+	Distribution []RevisionBucket `json:"distribution"`
+}
+
+// RevisionBucket counts the releases with a number of revisions in a range.
+type RevisionBucket struct {
+	// Min and Max bound the range of revisions. Max is 0 for the last,
+	// open range.
+	Min      int `json:"min"`
+	Max      int `json:"max"`
+	Releases int `json:"releases"`
+}
+
+// Stats returns statistics on the releases held by the storage, helping to
+// spot the releases whose history grows too large.
+//
+// Sizes are those of the payloads as stored by the Secrets, ConfigMaps and
+// SQL drivers, after the revisions sharing their chart and manifest with an
+// earlier one are compacted.
+func (s *Storage) Stats() (*Stats, error) {
+	ls, err := s.Driver.List(func(*rspb.Release) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct{ namespace, name string }
+	releases := map[key]*ReleaseStats{}
+	for _, rls := range ls {
+		size, err := driver.EncodedSize(rls)
+		if err != nil {
+			return nil, err
+		}
+		k := key{rls.Namespace, rls.Name}
+		r, ok := releases[k]
+		if !ok {
+			r = &ReleaseStats{Name: rls.Name, Namespace: rls.Namespace}
+			releases[k] = r
+		}
+		r.Revisions++
+		r.Size += int64(size)
+	}
+
+	stats := &Stats{Driver: s.Name(), Largest: []ReleaseStats{}, Namespaces: []NamespaceStats{}}
+	namespaces := map[string]*NamespaceStats{}
+	for _, r := range releases {
+		stats.Releases++
+		stats.Revisions += r.Revisions
+		stats.Size += r.Size
+		stats.Largest = append(stats.Largest, *r)
+
+		ns, ok := namespaces[r.Namespace]
+		if !ok {
+			ns = &NamespaceStats{Namespace: r.Namespace, Distribution: newDistribution()}
+			namespaces[r.Namespace] = ns
+		}
+		ns.Releases++
+		ns.Revisions += r.Revisions
+		ns.Size += r.Size
+		ns.MaxRevisions = max(ns.MaxRevisions, r.Revisions)
+		ns.Distribution[bucket(r.Revisions)].Releases++
+	}
+
+	slices.SortFunc(stats.Largest, func(a, b ReleaseStats) int {
+		return cmp.Or(
+			cmp.Compare(b.Size, a.Size),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	if len(stats.Largest) > LargestReleases {
+		stats.Largest = stats.Largest[:LargestReleases]
+	}
+	for _, ns := range namespaces {
+		stats.Namespaces = append(stats.Namespaces, *ns)
+	}
+	slices.SortFunc(stats.Namespaces, func(a, b NamespaceStats) int {
+		return cmp.Compare(a.Namespace, b.Namespace)
+	})
+	return stats, nil
+}
+
+func newDistribution() []RevisionBucket {
+	buckets := make([]RevisionBucket, 0, len(RevisionBuckets)+1)
+	lower := 1
+	for _, upper := range RevisionBuckets {
+		buckets = append(buckets, RevisionBucket{Min: lower, Max: upper})
+		lower = upper + 1
+	}
+	return append(buckets, RevisionBucket{Min: lower})
+}
+
+// bucket returns the index of the range of RevisionBuckets holding revisions.
+func bucket(revisions int) int {
+	for i, upper := range RevisionBuckets {
+		if revisions <= upper {
+			return i
+		}
+	}
+	return len(RevisionBuckets)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "helm.sh/helm/v4/pkg/storage"
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v4/pkg/storage/driver"
+)
+
+func TestStorageStats(t *testing.T) {
+	mem := driver.NewMemory()
+	storage := Init(mem)
+
+	var manifest strings.Builder
+	for i := range 512 {
+		fmt.Fprintf(&manifest, "key%d: %x\n", i, i*7919)
+	}
+	releases := []ReleaseTestData{
+		{Name: "small", Version: 1, Namespace: "default"},
+		{Name: "big", Version: 1, Namespace: "default", Manifest: manifest.String()},
+		{Name: "big", Version: 2, Namespace: "default", Manifest: "kind: Secret\n" + manifest.String()},
+		{Name: "other", Version: 1, Namespace: "apps"},
+	}
+	for i := 1; i <= 6; i++ {
+		releases = append(releases, ReleaseTestData{Name: "busy", Version: i, Namespace: "apps"})
+	}
+	for _, tt := range releases {
+		assertErrNil(t.Fatal, storage.Create(tt.ToRelease()), "StoreRelease")
+	}
+
+	// List the releases of all the namespaces
+	mem.SetNamespace("")
+
+	stats, err := storage.Stats()
+	assertErrNil(t.Fatal, err, "Stats")
+
+	if stats.Driver != driver.MemoryDriverName || stats.Releases != 4 || stats.Revisions != 10 {
+		t.Errorf("Expected 4 releases with 10 revisions in memory, got %d releases with %d revisions in %s", stats.Releases, stats.Revisions, stats.Driver)
+	}
+
+	var total int64
+	for _, ns := range stats.Namespaces {
+		total += ns.Size
+	}
+	if stats.Size == 0 || stats.Size != total {
+		t.Errorf("Expected the size %d to be the sum of the sizes of the namespaces %d", stats.Size, total)
+	}
+
+	if len(stats.Largest) != 4 || stats.Largest[0].Name != "big" || stats.Largest[0].Revisions != 2 {
+		t.Errorf("Expected the big release to be the largest, got %+v", stats.Largest)
+	}
+
+	if len(stats.Namespaces) != 2 || stats.Namespaces[0].Namespace != "apps" || stats.Namespaces[1].Namespace != "default" {
+		t.Fatalf("Expected the apps and default namespaces, got %+v", stats.Namespaces)
+	}
+	apps := stats.Namespaces[0]
+	if apps.Releases != 2 || apps.Revisions != 7 || apps.MaxRevisions != 6 {
+		t.Errorf("Expected 2 releases with 7 revisions, 6 at most, in apps, got %+v", apps)
+	}
+	want := []int{1, 0, 1, 0, 0, 0}
+	if len(apps.Distribution) != len(want) {
+		t.Fatalf("Expected %d revision buckets, got %+v", len(want), apps.Distribution)
+	}
+	for i, b := range apps.Distribution {
+		if b.Releases != want[i] {
+			t.Errorf("Expected %d releases with %d to %d revisions, got %d", want[i], b.Min, b.Max, b.Releases)
+		}
+	}
+}
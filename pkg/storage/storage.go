@@ -42,6 +42,17 @@ type Storage struct {
 	// be retained, including the most recent release. Values of 0 or less are
 	// ignored (meaning no limits are imposed).
 	MaxHistory int
+
+	// MaxFailedHistory specifies the maximum number of failed and
+	// pending-rollback revisions that will be retained. These are pruned,
+	// oldest first, before the revisions counted by MaxHistory, so that
+	// repeated failures do not push the successful revisions out of the
+	// history. Values of 0 or less are ignored.
+	MaxFailedHistory int
+
+	// StateMachine knows the custom statuses that may be set on releases. If
+	// nil, only the built-in statuses are known.
+	StateMachine *rspb.StateMachine
 }
 
 // Get retrieves the release from storage. An error is returned
@@ -49,7 +60,35 @@ type Storage struct {
 // release identified by the key, version pair does not exist.
 func (s *Storage) Get(name string, version int) (*rspb.Release, error) {
 	slog.Debug("getting release", "key", makeKey(name, version))
-	return s.Driver.Get(makeKey(name, version))
+	rls, err := s.Driver.Get(makeKey(name, version))
+	if err != nil {
+		return nil, err
+	}
+	ls, err := s.resolve(rls)
+	if err != nil {
+		return nil, err
+	}
+	return ls[0], nil
+}
+
+// List returns the releases accepted by filter. An error is returned if the
+// storage backend fails to retrieve the releases.
+func (s *Storage) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	ls, err := s.Driver.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(ls...)
+}
+
+// Query returns the releases matching the labels. An error is returned if the
+// storage backend fails to retrieve the releases, or none match.
+func (s *Storage) Query(labels map[string]string) ([]*rspb.Release, error) {
+	ls, err := s.Driver.Query(labels)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(ls...)
 }
 
 // Create creates a new storage entry holding the release. An
@@ -57,6 +96,12 @@ func (s *Storage) Get(name string, version int) (*rspb.Release, error) {
 // release, or a release with an identical key already exists.
 func (s *Storage) Create(rls *rspb.Release) error {
 	slog.Debug("creating release", "key", makeKey(rls.Name, rls.Version))
+	if s.MaxFailedHistory > 0 {
+		if err := s.removeFailed(rls.Name, s.MaxFailedHistory); err != nil &&
+			!errors.Is(err, driver.ErrReleaseNotFound) {
+			return err
+		}
+	}
 	if s.MaxHistory > 0 {
 		// Want to make space for one more release.
 		if err := s.removeLeastRecent(rls.Name, s.MaxHistory-1); err != nil &&
@@ -64,7 +109,7 @@ func (s *Storage) Create(rls *rspb.Release) error {
 			return err
 		}
 	}
-	return s.Driver.Create(makeKey(rls.Name, rls.Version), rls)
+	return s.Driver.Create(makeKey(rls.Name, rls.Version), compact(rls))
 }
 
 // Update updates the release in storage. An error is returned if the
@@ -72,15 +117,45 @@ func (s *Storage) Create(rls *rspb.Release) error {
 // does not exist.
 func (s *Storage) Update(rls *rspb.Release) error {
 	slog.Debug("updating release", "key", makeKey(rls.Name, rls.Version))
-	return s.Driver.Update(makeKey(rls.Name, rls.Version), rls)
+	return s.Driver.Update(makeKey(rls.Name, rls.Version), compact(rls))
 }
 
 // Delete deletes the release from storage. An error is returned if
 // the storage backend fails to delete the release or if the release
 // does not exist.
+//
+// Revisions sharing the chart and manifest of the release get a copy of them
+// first.
 func (s *Storage) Delete(name string, version int) (*rspb.Release, error) {
+	h, err := s.History(name)
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, err
+	}
+	return s.DeleteFromHistory(name, version, h)
+}
+
+// DeleteFromHistory is Delete for callers holding h, the history of the
+// release as returned by History, so it is not read again. The revisions of h
+// sharing the chart and manifest of the deleted revision are updated in place.
+func (s *Storage) DeleteFromHistory(name string, version int, h []*rspb.Release) (*rspb.Release, error) {
 	slog.Debug("deleting release", "key", makeKey(name, version))
-	return s.Driver.Delete(makeKey(name, version))
+	if err := s.unshare(name, version, h); err != nil {
+		return nil, err
+	}
+	rls, err := s.Driver.Delete(makeKey(name, version))
+	if err != nil {
+		return nil, err
+	}
+	if rls.SharedRevision == 0 {
+		return rls, nil
+	}
+	ls, err := s.resolve(rls)
+	if err != nil {
+		// The revision is deleted, only its chart and manifest are missing.
+		slog.Warn("deleted release without its shared chart and manifest", "key", makeKey(name, version), slog.Any("error", err))
+		return rls, nil
+	}
+	return ls[0], nil
 }
 
 // ListReleases returns all releases from storage. An error is returned if the
@@ -146,6 +221,31 @@ func (s *Storage) DeployedAll(name string) ([]*rspb.Release, error) {
 	return nil, err
 }
 
+// SetCustomStatus sets the custom status of a release revision, or clears it
+// if status is empty. The status must be registered with the state machine for
+// the built-in status of the revision.
+func (s *Storage) SetCustomStatus(name string, version int, status rspb.Status) error {
+	slog.Debug("setting custom release status", "key", makeKey(name, version), "status", status)
+	rls, err := s.Get(name, version)
+	if err != nil {
+		return err
+	}
+	if err := s.StateMachine.SetCustomStatus(rls, status); err != nil {
+		return err
+	}
+	return s.Update(rls)
+}
+
+// ListCustomStatus returns all releases whose custom status is status and
+// applies to their built-in status. An error is returned if the storage
+// backend fails to retrieve the releases.
+func (s *Storage) ListCustomStatus(status rspb.Status) ([]*rspb.Release, error) {
+	slog.Debug("listing releases with custom status", "status", status)
+	return s.List(func(rls *rspb.Release) bool {
+		return s.StateMachine.CustomStatus(rls) == status
+	})
+}
+
 // History returns the revision history for the release with the provided name, or
 // returns driver.ErrReleaseNotFound if no such release name exists.
 func (s *Storage) History(name string) ([]*rspb.Release, error) {
@@ -194,12 +294,42 @@ func (s *Storage) removeLeastRecent(name string, maximum int) error {
 		}
 	}
 
+	return s.prune(name, toDelete, h)
+}
+
+// removeFailed removes the oldest failed and pending-rollback revisions from
+// history until their number does not exceed maximum.
+func (s *Storage) removeFailed(name string, maximum int) error {
+	h, err := s.History(name)
+	if err != nil {
+		return err
+	}
+
+	// We want oldest to newest
+	relutil.SortByRevision(h)
+
+	var failed []*rspb.Release
+	for _, rel := range h {
+		if rel.Info == nil {
+			continue
+		}
+		if rel.Info.Status == rspb.StatusFailed || rel.Info.Status == rspb.StatusPendingRollback {
+			failed = append(failed, rel)
+		}
+	}
+	if len(failed) <= maximum {
+		return nil
+	}
+	return s.prune(name, failed[:len(failed)-maximum], h)
+}
+
+// prune deletes the revisions of a release with the history h.
+func (s *Storage) prune(name string, toDelete, h []*rspb.Release) error {
 	// Delete as many as possible. In the case of API throughput limitations,
 	// multiple invocations of this function will eventually delete them all.
 	errs := []error{}
 	for _, rel := range toDelete {
-		err = s.deleteReleaseVersion(name, rel.Version)
-		if err != nil {
+		if err := s.deleteReleaseVersion(name, rel.Version, h); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -215,9 +345,9 @@ func (s *Storage) removeLeastRecent(name string, maximum int) error {
 	}
 }
 
-func (s *Storage) deleteReleaseVersion(name string, version int) error {
+func (s *Storage) deleteReleaseVersion(name string, version int, h []*rspb.Release) error {
 	key := makeKey(name, version)
-	_, err := s.Delete(name, version)
+	_, err := s.DeleteFromHistory(name, version, h)
 	if err != nil {
 		slog.Debug("error pruning release", "key", key, slog.Any("error", err))
 		return err
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	rspb "helm.sh/helm/v4/pkg/release/v1"
+	relutil "helm.sh/helm/v4/pkg/release/v1/util"
 	"helm.sh/helm/v4/pkg/storage/driver"
 )
 
@@ -390,6 +391,44 @@ func TestStorageRemoveLeastRecent(t *testing.T) {
 	}
 }
 
+func TestStorageRemoveFailed(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	storage.MaxHistory = 5
+	storage.MaxFailedHistory = 1
+
+	const name = "angry-bird"
+
+	statuses := []rspb.Status{
+		rspb.StatusSuperseded,
+		rspb.StatusFailed,
+		rspb.StatusSuperseded,
+		rspb.StatusPendingRollback,
+		rspb.StatusFailed,
+		rspb.StatusDeployed,
+		rspb.StatusFailed,
+	}
+	for i, status := range statuses {
+		rls := ReleaseTestData{Name: name, Version: i + 1, Status: status}.ToRelease()
+		assertErrNil(t.Fatal, storage.Create(rls), fmt.Sprintf("Storing release 'angry-bird' (v%d)", i+1))
+	}
+
+	hist, err := storage.History(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relutil.SortByRevision(hist)
+
+	// The failed revisions go first, keeping the successful revisions 1 and 3
+	// within the history. Revision 7 was created after the last pruning.
+	var got []int
+	for _, item := range hist {
+		got = append(got, item.Version)
+	}
+	if expect := []int{1, 3, 5, 6, 7}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected revisions %v, got %v", expect, got)
+	}
+}
+
 func TestStorageDoNotDeleteDeployed(t *testing.T) {
 	storage := Init(driver.NewMemory())
 	storage.MaxHistory = 3
@@ -531,6 +570,146 @@ func TestUpgradeInitiallyFailedReleaseWithHistoryLimit(t *testing.T) {
 	}
 }
 
+func TestStorageCustomStatus(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	storage.StateMachine = rspb.NewStateMachine()
+	assertErrNil(t.Fatal, storage.StateMachine.RegisterStatus("canary", rspb.StatusDeployed), "Registering custom status")
+
+	const name = "angry-bird"
+	assertErrNil(t.Fatal, storage.Create(ReleaseTestData{Name: name, Version: 1, Status: rspb.StatusSuperseded}.ToRelease()), "Storing release 'angry-bird' (v1)")
+	assertErrNil(t.Fatal, storage.Create(ReleaseTestData{Name: name, Version: 2, Status: rspb.StatusDeployed}.ToRelease()), "Storing release 'angry-bird' (v2)")
+
+	if err := storage.SetCustomStatus(name, 1, "canary"); !errors.Is(err, rspb.ErrInvalidTransition) {
+		t.Fatalf("Expected ErrInvalidTransition for a superseded release, got %v", err)
+	}
+	if err := storage.SetCustomStatus(name, 2, "unregistered"); !errors.Is(err, rspb.ErrInvalidTransition) {
+		t.Fatalf("Expected ErrInvalidTransition for an unregistered status, got %v", err)
+	}
+	assertErrNil(t.Fatal, storage.SetCustomStatus(name, 2, "canary"), "Setting custom status")
+
+	ls, err := storage.ListCustomStatus("canary")
+	assertErrNil(t.Fatal, err, "Listing releases with custom status")
+	if len(ls) != 1 || ls[0].Version != 2 {
+		t.Fatalf("Expected revision 2 to be listed, got %v", ls)
+	}
+
+	// The custom status must not hide the release from Helm's own queries.
+	rls, err := storage.Deployed(name)
+	assertErrNil(t.Fatal, err, "Querying deployed release")
+	if rls.Version != 2 || rls.Info.Status != rspb.StatusDeployed {
+		t.Fatalf("Expected deployed revision 2, got revision %d in status %q", rls.Version, rls.Info.Status)
+	}
+
+	// Once Helm supersedes the release, the custom status no longer applies.
+	rls.Info.Status = rspb.StatusSuperseded
+	assertErrNil(t.Fatal, storage.Update(rls), "Superseding release")
+	ls, err = storage.ListCustomStatus("canary")
+	assertErrNil(t.Fatal, err, "Listing releases with custom status")
+	if len(ls) != 0 {
+		t.Fatalf("Expected no releases to be listed, got %d", len(ls))
+	}
+
+	assertErrNil(t.Fatal, storage.SetCustomStatus(name, 2, ""), "Clearing custom status")
+}
+
+func TestStorageSharedRevision(t *testing.T) {
+	d := driver.NewMemory()
+	storage := Init(d)
+
+	const name = "angry-beaver"
+	manifest := "kind: ConfigMap"
+	for version := 1; version <= 3; version++ {
+		rls := ReleaseTestData{Name: name, Version: version, Manifest: manifest, Status: rspb.StatusSuperseded}.ToRelease()
+		if version > 1 {
+			rls.SharedRevision = 1
+		}
+		assertErrNil(t.Fatal, storage.Create(rls), "StoreRelease")
+	}
+
+	stored, err := d.Get(makeKey(name, 2))
+	assertErrNil(t.Fatal, err, "QueryRelease")
+	if stored.Manifest != "" || stored.SharedRevision != 1 {
+		t.Errorf("Expected revision 2 to be stored without its manifest, got %+v", stored)
+	}
+
+	rls, err := storage.Get(name, 2)
+	assertErrNil(t.Fatal, err, "QueryRelease")
+	if rls.Manifest != manifest || rls.SharedRevision != 1 {
+		t.Errorf("Expected the manifest of revision 2 to be restored, got %+v", rls)
+	}
+	h, err := storage.History(name)
+	assertErrNil(t.Fatal, err, "History")
+	for _, rls := range h {
+		if rls.Manifest != manifest {
+			t.Errorf("Expected the manifest of revision %d to be restored, got %q", rls.Version, rls.Manifest)
+		}
+	}
+
+	// Deleting the shared revision moves the manifest to the next one.
+	_, err = storage.Delete(name, 1)
+	assertErrNil(t.Fatal, err, "DeleteRelease")
+	for version, shared := range map[int]int{2: 0, 3: 2} {
+		stored, err := d.Get(makeKey(name, version))
+		assertErrNil(t.Fatal, err, "QueryRelease")
+		if stored.SharedRevision != shared || (stored.Manifest == "") != (shared != 0) {
+			t.Errorf("Unexpected stored revision %d: %+v", version, stored)
+		}
+		rls, err := storage.Get(name, version)
+		assertErrNil(t.Fatal, err, "QueryRelease")
+		if rls.Manifest != manifest {
+			t.Errorf("Expected the manifest of revision %d to be restored, got %q", version, rls.Manifest)
+		}
+	}
+
+	// A revision whose shared revision is missing cannot be read.
+	assertErrNil(t.Fatal, d.Create(makeKey(name, 4), &rspb.Release{Name: name, Version: 4, SharedRevision: 1, Info: &rspb.Info{}}), "StoreRelease")
+	if _, err := storage.Get(name, 4); err == nil {
+		t.Error("Expected an error for a missing shared revision")
+	}
+
+	// It can still be deleted.
+	rls, err = storage.Delete(name, 4)
+	assertErrNil(t.Fatal, err, "DeleteRelease")
+	if rls.Version != 4 {
+		t.Errorf("Expected revision 4 to be deleted, got %d", rls.Version)
+	}
+}
+
+func TestStorageSharedRevisionPrune(t *testing.T) {
+	d := driver.NewMemory()
+	storage := Init(d)
+
+	const name = "angry-beaver"
+	manifest := "kind: ConfigMap"
+	for version := 1; version <= 4; version++ {
+		rls := ReleaseTestData{Name: name, Version: version, Manifest: manifest, Status: rspb.StatusSuperseded}.ToRelease()
+		if version > 1 {
+			rls.SharedRevision = 1
+		}
+		assertErrNil(t.Fatal, storage.Create(rls), "StoreRelease")
+	}
+
+	// Pruning revisions 1 and 2 moves the manifest to revision 3 first.
+	assertErrNil(t.Fatal, storage.removeLeastRecent(name, 2), "PruneReleases")
+	h, err := storage.History(name)
+	assertErrNil(t.Fatal, err, "History")
+	if len(h) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(h))
+	}
+	for version, shared := range map[int]int{3: 0, 4: 3} {
+		stored, err := d.Get(makeKey(name, version))
+		assertErrNil(t.Fatal, err, "QueryRelease")
+		if stored.SharedRevision != shared || (stored.Manifest == "") != (shared != 0) {
+			t.Errorf("Unexpected stored revision %d: %+v", version, stored)
+		}
+		rls, err := storage.Get(name, version)
+		assertErrNil(t.Fatal, err, "QueryRelease")
+		if rls.Manifest != manifest {
+			t.Errorf("Expected the manifest of revision %d to be restored, got %q", version, rls.Manifest)
+		}
+	}
+}
+
 type ReleaseTestData struct {
 	Name      string
 	Version   int
@@ -27,6 +27,10 @@ The above is equivalent to the YAML document
 	topname:
 	  subname: value
 
+A value can be cast explicitly with an inline tag, as in
+
+	replicas=!!int 3,ratio=!!float 0.5,enabled=!!bool true,tag=!!str 1.10
+
 This package provides a parser and utilities for converting the strvals format
 to other formats.
 */
@@ -0,0 +1,112 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strvals
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// globChars are the characters making a key segment a wildcard.
+const globChars = "*?"
+
+// ParseGlobInto parses a strvals line whose key segments may be wildcards and
+// merges the result into dest.
+//
+// A segment holding '*' or '?', as in "workers.*.resources.limits.cpu=500m",
+// matches the keys of the table at the same path of existing, following the
+// syntax of path.Match, and the value is set in dest for each of them. A
+// wildcard followed by more segments only matches the keys holding tables,
+// and a wildcard matching no key sets nothing. Wildcards never match the items
+// of lists.
+//
+// Values are typed as with ParseInto, and existing is not modified.
+func ParseGlobInto(s string, existing, dest map[string]interface{}) error {
+	pattern, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	return expandGlob(pattern, existing, dest)
+}
+
+func expandGlob(pattern, existing, dest map[string]interface{}) error {
+	for k, v := range pattern {
+		keys := []string{k}
+		glob := strings.ContainsAny(k, globChars)
+		if glob {
+			var err error
+			if keys, err = matchKeys(k, existing); err != nil {
+				return err
+			}
+		}
+		for _, key := range keys {
+			inner, ok := v.(map[string]interface{})
+			if !ok {
+				set(dest, key, copyVal(v))
+				continue
+			}
+			ex, isTable := existing[key].(map[string]interface{})
+			if glob && !isTable {
+				// A wildcard followed by more segments only matches tables.
+				continue
+			}
+			d, ok := dest[key].(map[string]interface{})
+			if !ok {
+				d = map[string]interface{}{}
+			}
+			if err := expandGlob(inner, ex, d); err != nil {
+				return err
+			}
+			if len(d) != 0 {
+				set(dest, key, d)
+			}
+		}
+	}
+	return nil
+}
+
+// matchKeys returns the keys of table matching pattern, sorted.
+func matchKeys(pattern string, table map[string]interface{}) ([]string, error) {
+	var keys []string
+	for key := range table {
+		ok, err := path.Match(pattern, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid wildcard %q: %w", pattern, err)
+		}
+		if ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// copyVal copies the lists of a parsed value, which may be set under several
+// keys.
+func copyVal(v interface{}) interface{} {
+	list, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	out := make([]interface{}, len(list))
+	for i, item := range list {
+		out[i] = copyVal(item)
+	}
+	return out
+}
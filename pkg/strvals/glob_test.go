@@ -0,0 +1,100 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strvals
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGlobInto(t *testing.T) {
+	existing := map[string]interface{}{
+		"replicas": int64(1),
+		"workers": map[string]interface{}{
+			"api":   map[string]interface{}{"cpu": "100m"},
+			"batch": map[string]interface{}{"cpu": "200m"},
+			"count": int64(2),
+		},
+		"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+	}
+
+	tests := []struct {
+		str    string
+		dest   map[string]interface{}
+		expect map[string]interface{}
+		err    bool
+	}{
+		{
+			str: "workers.*.resources.limits.cpu=500m",
+			expect: map[string]interface{}{
+				"workers": map[string]interface{}{
+					"api":   map[string]interface{}{"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}}},
+					"batch": map[string]interface{}{"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}}},
+				},
+			},
+		},
+		{
+			str: "workers.*=0",
+			expect: map[string]interface{}{
+				"workers": map[string]interface{}{"api": int64(0), "batch": int64(0), "count": int64(0)},
+			},
+		},
+		{
+			str:  "workers.b*.cpu=1,workers.api.args={a,b}",
+			dest: map[string]interface{}{"workers": map[string]interface{}{"batch": map[string]interface{}{"memory": "1Gi"}}},
+			expect: map[string]interface{}{
+				"workers": map[string]interface{}{
+					"api":   map[string]interface{}{"args": []interface{}{"a", "b"}},
+					"batch": map[string]interface{}{"cpu": int64(1), "memory": "1Gi"},
+				},
+			},
+		},
+		{
+			str:    "*.enabled=true",
+			expect: map[string]interface{}{"workers": map[string]interface{}{"enabled": true}},
+		},
+		{
+			str:    "missing.*.cpu=1",
+			expect: map[string]interface{}{},
+		},
+		{
+			str: "workers.*",
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		dest := tt.dest
+		if dest == nil {
+			dest = map[string]interface{}{}
+		}
+		err := ParseGlobInto(tt.str, existing, dest)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.str)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.str, err)
+			continue
+		}
+		if !reflect.DeepEqual(dest, tt.expect) {
+			t.Errorf("%s: expected %#v, got %#v", tt.str, tt.expect, dest)
+		}
+	}
+}
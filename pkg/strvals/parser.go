@@ -133,15 +133,22 @@ type RunesValueReader func([]rune) (interface{}, error)
 //
 // where sc is the source of the original data being parsed
 // where data is the final parsed data from the parses with correct types
+// where mark is the number of bytes left in sc when the last value started
 type parser struct {
 	sc        *bytes.Buffer
 	data      map[string]interface{}
 	reader    RunesValueReader
 	isjsonval bool
+	mark      int
 }
 
 func newParser(sc *bytes.Buffer, data map[string]interface{}, stringBool bool) *parser {
 	stringConverter := func(rs []rune) (interface{}, error) {
+		if !stringBool {
+			if v, ok, err := taggedVal(rs); ok {
+				return v, err
+			}
+		}
 		return typedVal(rs, stringBool), nil
 	}
 	return &parser{sc: sc, data: data, reader: stringConverter}
@@ -458,6 +465,7 @@ func (t *parser) emptyVal() (bool, error) {
 }
 
 func (t *parser) val() ([]rune, error) {
+	t.mark = t.sc.Len()
 	stop := runeSet([]rune{','})
 	v, _, err := runesUntil(t.sc, stop)
 	return v, err
@@ -477,6 +485,7 @@ func (t *parser) valList() ([]interface{}, error) {
 	list := []interface{}{}
 	stop := runeSet([]rune{',', '}'})
 	for {
+		t.mark = t.sc.Len()
 		switch rs, last, err := runesUntil(t.sc, stop); {
 		case err != nil:
 			if err == io.EOF {
@@ -0,0 +1,175 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strvals
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Type is the type a value of a strvals line is cast to.
+type Type string
+
+const (
+	// TypeAuto infers the type of the values the way Parse does.
+	TypeAuto Type = ""
+	// TypeString keeps the values as strings.
+	TypeString Type = "str"
+	// TypeInt casts the values to int64.
+	TypeInt Type = "int"
+	// TypeFloat casts the values to float64.
+	TypeFloat Type = "float"
+	// TypeBool casts the values to bool.
+	TypeBool Type = "bool"
+	// TypeNull only accepts empty, "~" or "null" values, set to nil.
+	TypeNull Type = "null"
+)
+
+// castTag introduces the inline cast of a value, as in "key=!!int 42".
+const castTag = "!!"
+
+// ParseError is an error found at a position of a strvals line.
+type ParseError struct {
+	// Line is the strvals line being parsed.
+	Line string
+	// Column is the 1-based position, in runes, of the error in Line.
+	Column int
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at column %d: %s", e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// castError indicates that a value could not be cast to a type.
+type castError struct {
+	val string
+	typ Type
+	err error
+}
+
+func (e *castError) Error() string {
+	return fmt.Sprintf("cannot cast %q to %s", e.val, e.typ)
+}
+
+func (e *castError) Unwrap() error {
+	return e.err
+}
+
+// ParseTyped parses a set line, casting its values to typ.
+//
+// Errors are returned as a *ParseError pointing at the value that could not
+// be cast, or at the place the parser stopped.
+func ParseTyped(s string, typ Type) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	err := ParseIntoTyped(s, vals, typ)
+	return vals, err
+}
+
+// ParseIntoTyped parses a strvals line, casting its values to typ, and merges
+// the result into dest.
+//
+// With TypeAuto the values are inferred as with ParseInto, including their
+// inline casts. Errors are returned as a *ParseError.
+func ParseIntoTyped(s string, dest map[string]interface{}, typ Type) error {
+	if !typ.valid() && typ != TypeAuto {
+		return fmt.Errorf("unknown type %q", typ)
+	}
+	scanner := bytes.NewBufferString(s)
+	var t *parser
+	if typ == TypeAuto {
+		t = newParser(scanner, dest, false)
+	} else {
+		t = &parser{sc: scanner, data: dest, reader: func(rs []rune) (interface{}, error) {
+			return castVal(string(rs), typ)
+		}}
+	}
+	err := t.parse()
+	if err == nil {
+		return nil
+	}
+	offset := len(s) - t.sc.Len()
+	if ce := (*castError)(nil); errors.As(err, &ce) {
+		offset = len(s) - t.mark
+	}
+	return &ParseError{
+		Line:   s,
+		Column: utf8.RuneCountInString(s[:offset]) + 1,
+		Err:    err,
+	}
+}
+
+func (typ Type) valid() bool {
+	switch typ {
+	case TypeString, TypeInt, TypeFloat, TypeBool, TypeNull:
+		return true
+	}
+	return false
+}
+
+// taggedVal casts a value starting with an inline cast such as "!!int 42".
+//
+// It reports false if the value has no cast, or an unknown one, leaving it to
+// be inferred.
+func taggedVal(rs []rune) (interface{}, bool, error) {
+	val := string(rs)
+	if !strings.HasPrefix(val, castTag) {
+		return nil, false, nil
+	}
+	tag, v, _ := strings.Cut(val[len(castTag):], " ")
+	typ := Type(tag)
+	if !typ.valid() {
+		return nil, false, nil
+	}
+	cv, err := castVal(v, typ)
+	return cv, true, err
+}
+
+func castVal(v string, typ Type) (interface{}, error) {
+	var (
+		cv  interface{}
+		err error
+	)
+	switch typ {
+	case TypeString:
+		return v, nil
+	case TypeInt:
+		cv, err = strconv.ParseInt(v, 10, 64)
+	case TypeFloat:
+		cv, err = strconv.ParseFloat(v, 64)
+	case TypeBool:
+		cv, err = strconv.ParseBool(v)
+	case TypeNull:
+		if v == "" || v == "~" || strings.EqualFold(v, "null") {
+			return nil, nil
+		}
+		err = errors.New("not null")
+	}
+	if err != nil {
+		return nil, &castError{val: v, typ: typ, err: err}
+	}
+	return cv, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright The Helm Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strvals
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTyped(t *testing.T) {
+	tests := []struct {
+		str    string
+		typ    Type
+		expect map[string]interface{}
+		column int
+	}{
+		{
+			str:    "a=42,b.c=-1",
+			typ:    TypeInt,
+			expect: map[string]interface{}{"a": int64(42), "b": map[string]interface{}{"c": int64(-1)}},
+		},
+		{
+			str:    "a=042",
+			typ:    TypeInt,
+			expect: map[string]interface{}{"a": int64(42)},
+		},
+		{
+			str:    "a={1,2}",
+			typ:    TypeInt,
+			expect: map[string]interface{}{"a": []interface{}{int64(1), int64(2)}},
+		},
+		{
+			str:    "a=1.5,b=2",
+			typ:    TypeFloat,
+			expect: map[string]interface{}{"a": 1.5, "b": 2.0},
+		},
+		{
+			str:    "a=true,b=FALSE,c=1",
+			typ:    TypeBool,
+			expect: map[string]interface{}{"a": true, "b": false, "c": true},
+		},
+		{
+			str:    "a=true,b=007",
+			typ:    TypeString,
+			expect: map[string]interface{}{"a": "true", "b": "007"},
+		},
+		{
+			str:    "a=!!int 42,b=!!str 42,c=!!float 1,d=!!bool false,e=!!null,f=!!map x",
+			typ:    TypeAuto,
+			expect: map[string]interface{}{"a": int64(42), "b": "42", "c": 1.0, "d": false, "e": nil, "f": "!!map x"},
+		},
+		{
+			str:    "a=1,b=x",
+			typ:    TypeInt,
+			column: 7,
+		},
+		{
+			str:    "a={1,x}",
+			typ:    TypeInt,
+			column: 6,
+		},
+		{
+			str:    "ключ=1,b=1.5x",
+			typ:    TypeFloat,
+			column: 10,
+		},
+		{
+			str:    "a=1,b=!!int 4.2",
+			typ:    TypeAuto,
+			column: 7,
+		},
+		{
+			str:    "a=1,b",
+			typ:    TypeInt,
+			column: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTyped(tt.str, tt.typ)
+		if tt.column != 0 {
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Errorf("%s: expected a parse error, got %v", tt.str, err)
+				continue
+			}
+			if perr.Column != tt.column {
+				t.Errorf("%s: expected error at column %d, got %d: %s", tt.str, tt.column, perr.Column, perr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.str, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("%s: expected %#v, got %#v", tt.str, tt.expect, got)
+		}
+	}
+}
+
+func TestParseIntoTypedUnknownType(t *testing.T) {
+	if err := ParseIntoTyped("a=1", map[string]interface{}{}, Type("map")); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
+
+func TestParseInlineCastError(t *testing.T) {
+	if _, err := Parse("a=!!bool maybe"); err == nil {
+		t.Error("expected an error for an invalid inline cast")
+	}
+}